@@ -0,0 +1,139 @@
+// Package interplugin is the contract of the inter-plugin API of Matterpoll, which lets other plugins create polls
+// and get their results. Plugins send the requests with Client, which uses the PluginHTTP method of the plugin API.
+//
+// The paths, the types and their json tags are a public contract and must not be changed incompatibly.
+// Adding new fields is fine.
+package interplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// PluginID is the ID of the Matterpoll plugin, which receives the requests.
+const PluginID = "com.github.matterpoll.matterpoll"
+
+// CreatePollPath is the path of the endpoint that creates polls. It accepts a CreatePollRequest via POST
+// and responds with a CreatePollResponse.
+const CreatePollPath = "/inter-plugin/v1/polls"
+
+// ResultsPath returns the path of the endpoint that returns the Results of a poll via GET.
+func ResultsPath(pollID string) string {
+	return "/inter-plugin/v1/polls/" + pollID + "/results"
+}
+
+// CreatePollRequest is the body of a request to create a poll.
+type CreatePollRequest struct {
+	// UserID is the ID of the user who creates the poll. The user must be allowed to post in the channel.
+	UserID    string `json:"user_id"`
+	ChannelID string `json:"channel_id"`
+	// RootID is the ID of the post the poll is posted as reply to. It's empty to post the poll in the channel.
+	RootID        string   `json:"root_id,omitempty"`
+	Question      string   `json:"question"`
+	AnswerOptions []string `json:"answer_options"`
+	// Settings uses the same format as the slash command, but without the leading dashes, e.g. "votes=2".
+	Settings []string `json:"settings,omitempty"`
+	// ResultsCallbackPath is a path of the requesting plugin, e.g. "/poll-ended". If it's set, the Results of the poll
+	// are sent to it via POST once the poll has ended.
+	ResultsCallbackPath string `json:"results_callback_path,omitempty"`
+}
+
+// CreatePollResponse is the response to a successful CreatePollRequest.
+type CreatePollResponse struct {
+	PollID string `json:"poll_id"`
+	// PostID is empty for scheduled polls, which don't have a post yet.
+	PostID string `json:"post_id"`
+}
+
+// Results are the results of a poll. They're the same as the ones sent by the results webhook.
+type Results struct {
+	PollID    string `json:"poll_id"`
+	PostID    string `json:"post_id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	Question  string `json:"question"`
+	Creator   string `json:"creator"`
+	// CreatedAt and EndedAt are in milliseconds. EndedAt is zero while the poll is running.
+	CreatedAt int64 `json:"created_at"`
+	EndedAt   int64 `json:"ended_at,omitempty"`
+	// Voters is the number of users who voted. It's zero if the results are hidden.
+	Voters  int             `json:"voters,omitempty"`
+	Options []*OptionResult `json:"options"`
+}
+
+// OptionResult is the result of an answer option.
+type OptionResult struct {
+	Answer string `json:"answer"`
+	// Votes is nil if the results are hidden.
+	Votes *int `json:"votes,omitempty"`
+	// Voters contains user IDs. It's empty if the voters are hidden or nobody voted for the answer option.
+	Voters []string `json:"voters,omitempty"`
+}
+
+// PluginAPI is the part of the plugin API Client needs.
+type PluginAPI interface {
+	PluginHTTP(request *http.Request) *http.Response
+}
+
+// Client sends requests to the inter-plugin API of Matterpoll.
+type Client struct {
+	api PluginAPI
+}
+
+// NewClient returns a client that sends requests via the plugin API of the calling plugin.
+func NewClient(api PluginAPI) *Client {
+	return &Client{api: api}
+}
+
+// CreatePoll creates a poll and returns its IDs.
+func (c *Client) CreatePoll(request *CreatePollRequest) (*CreatePollResponse, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal request")
+	}
+
+	var response CreatePollResponse
+	if err := c.do(http.MethodPost, CreatePollPath, b, http.StatusCreated, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetResults returns the current results of a poll. Polls that have been deleted after they ended can't be found.
+func (c *Client) GetResults(pollID string) (*Results, error) {
+	var results Results
+	if err := c.do(http.MethodGet, ResultsPath(pollID), nil, http.StatusOK, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// do sends a request to path and decodes the response into v.
+// An error containing the response body is returned if the status code isn't the expected one.
+func (c *Client) do(method, path string, body []byte, expectedStatus int, v interface{}) error {
+	r, err := http.NewRequest(method, "/"+PluginID+path, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	resp := c.api.PluginHTTP(r)
+	if resp == nil {
+		return errors.New("Matterpoll didn't respond, it may be disabled")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		message, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("Matterpoll responded with status code %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+	return nil
+}
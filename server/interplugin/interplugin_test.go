@@ -0,0 +1,114 @@
+package interplugin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/interplugin"
+)
+
+// pluginAPI sends the requests to handler instead of another plugin.
+type pluginAPI struct {
+	handler func(r *http.Request) *http.Response
+}
+
+func (api *pluginAPI) PluginHTTP(r *http.Request) *http.Response {
+	return api.handler(r)
+}
+
+func response(statusCode int, body string) *http.Response {
+	return &http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+}
+
+func TestClientCreatePoll(t *testing.T) {
+	request := &interplugin.CreatePollRequest{
+		UserID:              "userID1",
+		ChannelID:           "channelID1",
+		Question:            "Question",
+		AnswerOptions:       []string{"Yes", "No"},
+		Settings:            []string{"anonymous"},
+		ResultsCallbackPath: "/poll-ended",
+	}
+
+	t.Run("poll is created", func(t *testing.T) {
+		var received interplugin.CreatePollRequest
+		client := interplugin.NewClient(&pluginAPI{handler: func(r *http.Request) *http.Response {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/com.github.matterpoll.matterpoll/inter-plugin/v1/polls", r.URL.Path)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			return response(http.StatusCreated, `{"poll_id":"pollID1","post_id":"postID1"}`)
+		}})
+
+		resp, err := client.CreatePoll(request)
+		require.NoError(t, err)
+		assert.Equal(t, &interplugin.CreatePollResponse{PollID: "pollID1", PostID: "postID1"}, resp)
+		assert.Equal(t, *request, received)
+	})
+	t.Run("Matterpoll rejects the request", func(t *testing.T) {
+		client := interplugin.NewClient(&pluginAPI{handler: func(r *http.Request) *http.Response {
+			return response(http.StatusBadRequest, "Duplicate option: Yes\n")
+		}})
+
+		resp, err := client.CreatePoll(request)
+		require.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, "Matterpoll responded with status code 400: Duplicate option: Yes", err.Error())
+	})
+	t.Run("Matterpoll is disabled", func(t *testing.T) {
+		client := interplugin.NewClient(&pluginAPI{handler: func(r *http.Request) *http.Response { return nil }})
+
+		resp, err := client.CreatePoll(request)
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestClientGetResults(t *testing.T) {
+	t.Run("results are returned", func(t *testing.T) {
+		client := interplugin.NewClient(&pluginAPI{handler: func(r *http.Request) *http.Response {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/com.github.matterpoll.matterpoll/inter-plugin/v1/polls/pollID1/results", r.URL.Path)
+			return response(http.StatusOK, `{"poll_id":"pollID1","question":"Question","creator":"userID1","created_at":1234567890,"voters":1,"options":[{"answer":"Yes","votes":1,"voters":["userID2"]},{"answer":"No","votes":0}]}`)
+		}})
+
+		results, err := client.GetResults("pollID1")
+		require.NoError(t, err)
+		one, zero := 1, 0
+		assert.Equal(t, &interplugin.Results{
+			PollID:    "pollID1",
+			Question:  "Question",
+			Creator:   "userID1",
+			CreatedAt: 1234567890,
+			Voters:    1,
+			Options: []*interplugin.OptionResult{
+				{Answer: "Yes", Votes: &one, Voters: []string{"userID2"}},
+				{Answer: "No", Votes: &zero},
+			},
+		}, results)
+	})
+	t.Run("poll not found", func(t *testing.T) {
+		client := interplugin.NewClient(&pluginAPI{handler: func(r *http.Request) *http.Response {
+			return response(http.StatusNotFound, "poll not found\n")
+		}})
+
+		results, err := client.GetResults("pollID1")
+		assert.Error(t, err)
+		assert.Nil(t, results)
+	})
+	t.Run("invalid response", func(t *testing.T) {
+		client := interplugin.NewClient(&pluginAPI{handler: func(r *http.Request) *http.Response {
+			return response(http.StatusOK, "{")
+		}})
+
+		results, err := client.GetResults("pollID1")
+		assert.Error(t, err)
+		assert.Nil(t, results)
+	})
+}
@@ -0,0 +1,168 @@
+// Package metrics collects usage and performance metrics of the plugin and writes them in the
+// Prometheus text exposition format, so they can be scraped by a Prometheus server.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Namespace is the prefix of the names of all metrics.
+const Namespace = "matterpoll"
+
+// DurationBuckets are the upper bounds in seconds of the buckets of the vote request duration histogram.
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics stores the metrics of the plugin. It's safe for concurrent use.
+// A nil *Metrics discards all observations, so the plugin works without metrics.
+// The metrics are kept in memory, hence every server of a cluster exports the metrics of its own requests.
+type Metrics struct {
+	lock sync.Mutex
+
+	pollsCreated uint64
+	votesCast    uint64
+	pollsEnded   uint64
+	// voteDurations is the vote request duration histogram, keyed by the name of the handler.
+	voteDurations map[string]*histogram
+}
+
+type histogram struct {
+	// counts contains the number of observations per bucket of DurationBuckets. They aren't cumulative.
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// New returns empty metrics.
+func New() *Metrics {
+	return &Metrics{
+		voteDurations: map[string]*histogram{},
+	}
+}
+
+// IncPollsCreated counts a poll that has been created.
+func (m *Metrics) IncPollsCreated() {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.pollsCreated++
+}
+
+// IncVotesCast counts a vote that has been cast.
+func (m *Metrics) IncVotesCast() {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.votesCast++
+}
+
+// IncPollsEnded counts a poll that has ended.
+func (m *Metrics) IncPollsEnded() {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.pollsEnded++
+}
+
+// ObserveVoteDuration records the time it took a handler to process a vote request.
+func (m *Metrics) ObserveVoteDuration(handler string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	h, ok := m.voteDurations[handler]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(DurationBuckets))}
+		m.voteDurations[handler] = h
+	}
+	seconds := d.Seconds()
+	for i, bound := range DurationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format to w.
+// activePolls is the number of running polls, which is exported as gauge.
+func (m *Metrics) WriteTo(w io.Writer, activePolls int) error {
+	if m == nil {
+		m = New()
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	e := &exposition{w: w}
+	e.metric("polls_created_total", "counter", "Number of polls that have been created.")
+	e.sample("polls_created_total", "", strconv.FormatUint(m.pollsCreated, 10))
+	e.metric("votes_cast_total", "counter", "Number of votes that have been cast.")
+	e.sample("votes_cast_total", "", strconv.FormatUint(m.votesCast, 10))
+	e.metric("polls_ended_total", "counter", "Number of polls that have ended.")
+	e.sample("polls_ended_total", "", strconv.FormatUint(m.pollsEnded, 10))
+	e.metric("active_polls", "gauge", "Number of polls that are running.")
+	e.sample("active_polls", "", strconv.Itoa(activePolls))
+
+	e.metric("vote_request_duration_seconds", "histogram", "Time it took to handle vote requests in seconds.")
+	handlers := make([]string, 0, len(m.voteDurations))
+	for handler := range m.voteDurations {
+		handlers = append(handlers, handler)
+	}
+	sort.Strings(handlers)
+	for _, handler := range handlers {
+		h := m.voteDurations[handler]
+		label := fmt.Sprintf("handler=%q", handler)
+		var cumulative uint64
+		for i, bound := range DurationBuckets {
+			cumulative += h.counts[i]
+			e.sample("vote_request_duration_seconds_bucket", fmt.Sprintf("%s,le=%q", label, formatFloat(bound)), strconv.FormatUint(cumulative, 10))
+		}
+		e.sample("vote_request_duration_seconds_bucket", label+`,le="+Inf"`, strconv.FormatUint(h.count, 10))
+		e.sample("vote_request_duration_seconds_sum", label, formatFloat(h.sum))
+		e.sample("vote_request_duration_seconds_count", label, strconv.FormatUint(h.count, 10))
+	}
+	return e.err
+}
+
+// exposition writes lines of the text exposition format and keeps the first error that occurred.
+type exposition struct {
+	w   io.Writer
+	err error
+}
+
+func (e *exposition) metric(name, metricType, help string) {
+	e.printf("# HELP %s_%s %s\n", Namespace, name, help)
+	e.printf("# TYPE %s_%s %s\n", Namespace, name, metricType)
+}
+
+func (e *exposition) sample(name, labels, value string) {
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	e.printf("%s_%s%s %s\n", Namespace, name, labels, value)
+}
+
+func (e *exposition) printf(format string, a ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, a...)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
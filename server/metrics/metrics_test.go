@@ -0,0 +1,73 @@
+package metrics_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/metrics"
+)
+
+func TestMetricsWriteTo(t *testing.T) {
+	t.Run("counters and gauge", func(t *testing.T) {
+		m := metrics.New()
+		m.IncPollsCreated()
+		m.IncPollsCreated()
+		m.IncVotesCast()
+		m.IncPollsEnded()
+
+		var b bytes.Buffer
+		require.Nil(t, m.WriteTo(&b, 3))
+		assert.Equal(t, strings.Join([]string{
+			"# HELP matterpoll_polls_created_total Number of polls that have been created.",
+			"# TYPE matterpoll_polls_created_total counter",
+			"matterpoll_polls_created_total 2",
+			"# HELP matterpoll_votes_cast_total Number of votes that have been cast.",
+			"# TYPE matterpoll_votes_cast_total counter",
+			"matterpoll_votes_cast_total 1",
+			"# HELP matterpoll_polls_ended_total Number of polls that have ended.",
+			"# TYPE matterpoll_polls_ended_total counter",
+			"matterpoll_polls_ended_total 1",
+			"# HELP matterpoll_active_polls Number of polls that are running.",
+			"# TYPE matterpoll_active_polls gauge",
+			"matterpoll_active_polls 3",
+			"# HELP matterpoll_vote_request_duration_seconds Time it took to handle vote requests in seconds.",
+			"# TYPE matterpoll_vote_request_duration_seconds histogram",
+			"",
+		}, "\n"), b.String())
+	})
+	t.Run("histogram", func(t *testing.T) {
+		m := metrics.New()
+		m.ObserveVoteDuration("vote", 20*time.Millisecond)
+		m.ObserveVoteDuration("vote", 3*time.Second)
+		m.ObserveVoteDuration("vote", time.Minute)
+
+		var b bytes.Buffer
+		require.Nil(t, m.WriteTo(&b, 0))
+		for _, line := range []string{
+			`matterpoll_vote_request_duration_seconds_bucket{handler="vote",le="0.01"} 0`,
+			`matterpoll_vote_request_duration_seconds_bucket{handler="vote",le="0.025"} 1`,
+			`matterpoll_vote_request_duration_seconds_bucket{handler="vote",le="2.5"} 1`,
+			`matterpoll_vote_request_duration_seconds_bucket{handler="vote",le="5"} 2`,
+			`matterpoll_vote_request_duration_seconds_bucket{handler="vote",le="10"} 2`,
+			`matterpoll_vote_request_duration_seconds_bucket{handler="vote",le="+Inf"} 3`,
+			`matterpoll_vote_request_duration_seconds_sum{handler="vote"} 63.02`,
+			`matterpoll_vote_request_duration_seconds_count{handler="vote"} 3`,
+		} {
+			assert.Contains(t, b.String(), line+"\n")
+		}
+	})
+	t.Run("nil metrics", func(t *testing.T) {
+		var m *metrics.Metrics
+		m.IncPollsCreated()
+		m.ObserveVoteDuration("vote", time.Second)
+
+		var b bytes.Buffer
+		require.Nil(t, m.WriteTo(&b, 1))
+		assert.Contains(t, b.String(), "matterpoll_polls_created_total 0\n")
+	})
+}
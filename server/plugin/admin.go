@@ -0,0 +1,340 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+var (
+	commandAdminUsage = &i18n.Message{
+		ID:    "command.admin.usage",
+		Other: "Use `/{{.Trigger}} admin list` to list all running polls, `/{{.Trigger}} admin end <Poll ID>` to end one, `/{{.Trigger}} admin delete <Poll ID>` to delete one, `/{{.Trigger}} admin audit <Poll ID>` to see when its votes were cast and `/{{.Trigger}} admin prune` to delete polls that aren't needed anymore.",
+	}
+	commandAdminInvalidPermission = &i18n.Message{
+		ID:    "command.admin.invalidPermission",
+		Other: "Only System Admins are allowed to manage all polls.",
+	}
+	commandAdminListEmpty = &i18n.Message{
+		ID:    "command.admin.list.empty",
+		Other: "There are no running polls.",
+	}
+	commandAdminListHeader = &i18n.Message{
+		ID:    "command.admin.list.header",
+		Other: "Running polls:",
+	}
+	commandAdminListEntry = &i18n.Message{
+		ID:    "command.admin.list.entry",
+		One:   "- `{{.ID}}`: **{{.Question}}** by {{.Creator}} in {{.Channel}}, created {{.Age}} ago, {{.Voters}} voter",
+		Other: "- `{{.ID}}`: **{{.Question}}** by {{.Creator}} in {{.Channel}}, created {{.Age}} ago, {{.Voters}} voters",
+	}
+	commandAdminListDeactivatedUser = &i18n.Message{
+		ID:    "command.admin.list.deactivatedUser",
+		Other: "@{{.Username}} (deactivated)",
+	}
+	commandAdminListUnknown = &i18n.Message{
+		ID:    "command.admin.list.unknown",
+		Other: "unknown",
+	}
+	commandAdminEndSuccess = &i18n.Message{
+		ID:    "command.admin.end.success",
+		Other: "The poll **{{.Question}}** has been ended.",
+	}
+	commandAdminDeleteSuccess = &i18n.Message{
+		ID:    "command.admin.delete.success",
+		Other: "The poll **{{.Question}}** has been deleted.",
+	}
+	commandAdminAuditEmpty = &i18n.Message{
+		ID:    "command.admin.audit.empty",
+		Other: "No votes have been recorded for the poll **{{.Question}}**.",
+	}
+	commandAdminAuditHeader = &i18n.Message{
+		ID:    "command.admin.audit.header",
+		Other: "Votes of the poll **{{.Question}}** (times in UTC):",
+	}
+	commandAdminAuditEntry = &i18n.Message{
+		ID:    "command.admin.audit.entry",
+		Other: "- {{.Time}}: {{.Voter}} voted for **{{.Answer}}**",
+	}
+	commandAdminAuditRemovedEntry = &i18n.Message{
+		ID:    "command.admin.audit.removedEntry",
+		Other: "- {{.Time}}: {{.Voter}} voted for **{{.Answer}}**, removed at {{.RemovedAt}}",
+	}
+	commandAdminAuditAnonymousVoter = &i18n.Message{
+		ID:    "command.admin.audit.anonymousVoter",
+		Other: "Anonymous voter {{.Number}}",
+	}
+	commandAdminAuditSecret = &i18n.Message{
+		ID:    "command.admin.audit.secret",
+		Other: "The votes of the secret poll **{{.Question}}** can only be audited after it has ended.",
+	}
+	commandAdminPruneSuccess = &i18n.Message{
+		ID:    "command.admin.prune.success",
+		Other: "Deleted {{.Ended}} ended polls and {{.Orphaned}} polls whose post has been deleted.",
+	}
+	commandErrorAdminPollNotFound = &i18n.Message{
+		ID:    "command.error.adminPollNotFound",
+		Other: "The running poll {{.ID}} could not be found.",
+	}
+)
+
+// executeAdminCommand lists, ends, deletes, audits or prunes the polls of all users and returns the response message.
+// Only System Admins are allowed to use it.
+func (p *MatterpollPlugin) executeAdminCommand(args []string, userID, trigger string, userLocalizer *i18n.Localizer) string {
+	isSystemAdmin, appErr := p.isSystemAdmin(userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to check permission", "userID", userID, "error", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if !isSystemAdmin {
+		return p.LocalizeDefaultMessage(userLocalizer, commandAdminInvalidPermission)
+	}
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		return p.listAllPolls(userLocalizer)
+	case len(args) == 2 && args[0] == "end":
+		return p.adminEndPoll(args[1], userID, userLocalizer)
+	case len(args) == 2 && args[0] == "delete":
+		return p.adminDeletePoll(args[1], userID, userLocalizer)
+	case len(args) == 2 && args[0] == "audit":
+		return p.adminAuditPoll(args[1], userLocalizer)
+	case len(args) == 1 && args[0] == "prune":
+		return p.adminPruneStore(userLocalizer)
+	default:
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandAdminUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		})
+	}
+}
+
+// listAllPolls returns a message listing all running polls, oldest first.
+func (p *MatterpollPlugin) listAllPolls(userLocalizer *i18n.Localizer) string {
+	ids, err := p.Store.Poll().ListIDs()
+	if err != nil {
+		p.API.LogWarn("failed to list polls", "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	var polls []*poll.Poll
+	for _, id := range ids {
+		poll, err := p.getPoll(id)
+		if err != nil {
+			p.API.LogWarn("failed to get poll", "pollID", id, "error", err.Error())
+			continue
+		}
+		if !poll.HasEnded() {
+			polls = append(polls, poll)
+		}
+	}
+	if len(polls) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandAdminListEmpty)
+	}
+
+	sort.Slice(polls, func(i, j int) bool { return polls[i].CreatedAt < polls[j].CreatedAt })
+	now := model.GetMillis()
+	lines := []string{p.LocalizeDefaultMessage(userLocalizer, commandAdminListHeader)}
+	for _, poll := range polls {
+		voters := poll.VoterCount()
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandAdminListEntry,
+			TemplateData: map[string]interface{}{
+				"ID":       poll.ID,
+				"Question": poll.Question,
+				"Creator":  p.adminCreatorName(poll.Creator, userLocalizer),
+				"Channel":  p.adminChannelName(poll, userLocalizer),
+				"Age":      formatAge(now - poll.CreatedAt),
+				"Voters":   voters,
+			},
+			PluralCount: voters,
+		}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// adminCreatorName returns the username of the creator of a poll and marks deactivated users.
+func (p *MatterpollPlugin) adminCreatorName(creatorID string, userLocalizer *i18n.Localizer) string {
+	user, appErr := p.API.GetUser(creatorID)
+	if appErr != nil {
+		return p.LocalizeDefaultMessage(userLocalizer, commandAdminListUnknown)
+	}
+	if user.DeleteAt != 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandAdminListDeactivatedUser,
+			TemplateData:   map[string]interface{}{"Username": user.Username},
+		})
+	}
+	return "@" + user.Username
+}
+
+// adminChannelName returns the name of the channel a poll was posted in.
+func (p *MatterpollPlugin) adminChannelName(pl *poll.Poll, userLocalizer *i18n.Localizer) string {
+	channelID, err := p.pollChannelID(pl)
+	if err != nil {
+		return p.LocalizeDefaultMessage(userLocalizer, commandAdminListUnknown)
+	}
+	channel, appErr := p.API.GetChannel(channelID)
+	if appErr != nil {
+		return p.LocalizeDefaultMessage(userLocalizer, commandAdminListUnknown)
+	}
+	return "~" + channel.Name
+}
+
+// adminEndPoll ends a running poll of any user and returns the response message.
+// Unlike the creator, System Admins can end polls with hold-for-quorum before the quorum is reached.
+func (p *MatterpollPlugin) adminEndPoll(pollID, userID string, userLocalizer *i18n.Localizer) string {
+	poll, err := p.getPoll(pollID)
+	if err != nil || poll.HasEnded() {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAdminPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	if err := p.endPoll(poll, userID); err != nil {
+		p.API.LogWarn("failed to end poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandAdminEndSuccess,
+		TemplateData:   map[string]interface{}{"Question": poll.Question},
+	})
+}
+
+// adminDeletePoll deletes a poll of any user together with its post and returns the response message.
+func (p *MatterpollPlugin) adminDeletePoll(pollID, userID string, userLocalizer *i18n.Localizer) string {
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAdminPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	// The record is created and the channel looked up first, since the channel of the poll can't be looked up
+	// once its post is deleted
+	record := p.newAuditRecord(auditEventPollDeleted, userID, "", poll)
+	channelID, channelErr := p.pollChannelID(poll)
+	if poll.PostID != "" {
+		if appErr := p.API.DeletePost(poll.PostID); appErr != nil {
+			p.API.LogWarn("failed to delete post", "pollID", pollID, "error", appErr.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+		}
+	}
+	p.deleteBroadcastPosts(poll)
+	if err := p.Store.Poll().Delete(poll); err != nil {
+		p.API.LogWarn("failed to delete poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if channelErr == nil {
+		p.unarchivePoll(poll, channelID)
+	}
+	p.saveAuditRecord(record)
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandAdminDeleteSuccess,
+		TemplateData:   map[string]interface{}{"Question": poll.Question},
+	})
+}
+
+// adminAuditPoll returns a message listing the vote log of a poll of any user, i.e. when every vote was cast and removed.
+// The voters of anonymous polls are numbered instead of named, and secret polls can only be audited after they have ended.
+func (p *MatterpollPlugin) adminAuditPoll(pollID string, userLocalizer *i18n.Localizer) string {
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAdminPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+	if poll.HidesResults() {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandAdminAuditSecret,
+			TemplateData:   map[string]interface{}{"Question": poll.Question},
+		})
+	}
+	if len(poll.Votes) == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandAdminAuditEmpty,
+			TemplateData:   map[string]interface{}{"Question": poll.Question},
+		})
+	}
+
+	voters := map[string]string{}
+	voterName := func(userID string) string {
+		if name, ok := voters[userID]; ok {
+			return name
+		}
+		var name string
+		if poll.HidesVoters() {
+			name = p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandAdminAuditAnonymousVoter,
+				TemplateData:   map[string]interface{}{"Number": len(voters) + 1},
+			})
+		} else if displayName, appErr := p.ConvertUserIDToDisplayName(userID); appErr == nil {
+			name = displayName
+		} else {
+			name = p.LocalizeDefaultMessage(userLocalizer, commandAdminListUnknown)
+		}
+		voters[userID] = name
+		return name
+	}
+
+	lines := []string{p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandAdminAuditHeader,
+		TemplateData:   map[string]interface{}{"Question": poll.Question},
+	})}
+	for _, v := range poll.Votes {
+		answer := p.LocalizeDefaultMessage(userLocalizer, commandAdminListUnknown)
+		if v.Answer >= 0 && v.Answer < len(poll.AnswerOptions) {
+			answer = poll.AnswerOptions[v.Answer].Answer
+		}
+		data := map[string]interface{}{
+			"Time":   formatTime(v.CreatedAt),
+			"Voter":  voterName(v.UserID),
+			"Answer": answer,
+		}
+		message := commandAdminAuditEntry
+		if v.RemovedAt != 0 {
+			data["RemovedAt"] = formatTime(v.RemovedAt)
+			message = commandAdminAuditRemovedEntry
+		}
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: message,
+			TemplateData:   data,
+		}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// adminPruneStore deletes all polls that aren't needed anymore and returns the response message.
+func (p *MatterpollPlugin) adminPruneStore(userLocalizer *i18n.Localizer) string {
+	result, err := p.pruneStore()
+	if err != nil {
+		p.API.LogWarn("failed to prune store", "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandAdminPruneSuccess,
+		TemplateData:   map[string]interface{}{"Ended": result.Ended, "Orphaned": result.Orphaned},
+	})
+}
+
+// formatAge formats a duration in milliseconds as whole days, hours or minutes, e.g. "3d".
+func formatAge(millis int64) string {
+	d := time.Duration(millis) * time.Millisecond
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
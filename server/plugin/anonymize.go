@@ -0,0 +1,59 @@
+package plugin
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// voterHashKeyLength is the length of a generated voter hash key.
+const voterHashKeyLength = 32
+
+// ensureVoterHashKey generates the key the voters of anonymous polls are hashed with and saves it to the plugin
+// configuration, if it isn't set yet.
+func (p *MatterpollPlugin) ensureVoterHashKey() error {
+	configuration := p.getConfiguration()
+	if configuration.VoterHashKey != "" {
+		return nil
+	}
+
+	newConfiguration := *configuration
+	newConfiguration.VoterHashKey = model.NewRandomString(voterHashKeyLength)
+
+	b, err := json.Marshal(newConfiguration)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode configuration")
+	}
+	var configMap map[string]interface{}
+	if err := json.Unmarshal(b, &configMap); err != nil {
+		return errors.Wrap(err, "failed to decode configuration")
+	}
+	if appErr := p.API.SavePluginConfig(configMap); appErr != nil {
+		return errors.Wrap(appErr, "failed to save configuration")
+	}
+
+	p.setConfiguration(&newConfiguration)
+	return nil
+}
+
+// hashAnonymousVoters hashes the voters of all anonymous polls that were stored before voters got hashed.
+// Polls that can't be migrated are logged and skipped.
+func (p *MatterpollPlugin) hashAnonymousVoters() {
+	pollIDs, err := p.Store.Poll().ListIDs()
+	if err != nil {
+		p.API.LogWarn("Failed to list polls", "error", err.Error())
+		return
+	}
+
+	for _, pollID := range pollIDs {
+		_, err := p.updatePoll(pollID, func(poll *poll.Poll) (bool, error) {
+			return poll.HashVoters(), nil
+		})
+		if err != nil {
+			p.API.LogWarn("Failed to hash voters of poll", "pollID", pollID, "error", err.Error())
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -16,6 +18,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
 )
 
 const (
@@ -23,8 +26,41 @@ const (
 
 	addOptionKey = "answerOption"
 	questionKey  = "question"
+	commentKey   = "comment"
+
+	// createPollDialogOptions is the number of answer option fields in the create poll dialog.
+	// Interactive dialogs can't add fields dynamically, hence all fields beyond poll.MinAnswerOptions are optional.
+	createPollDialogOptions = 5
+
+	// websocketEventVote is the websocket event that is sent to a channel when the votes of a poll changed.
+	// Clients receive it as custom_<plugin ID>_vote.
+	websocketEventVote = "vote"
+	// websocketEventEnded is the websocket event that is sent to a channel when a poll has ended.
+	websocketEventEnded = "ended"
 )
 
+// createPollRequest is the body of a request to create a poll via the REST API.
+type createPollRequest struct {
+	ChannelID     string   `json:"channel_id"`
+	RootID        string   `json:"root_id,omitempty"`
+	Question      string   `json:"question"`
+	AnswerOptions []string `json:"answer_options"`
+	// Settings uses the same format as the slash command, but without the leading dashes, e.g. "votes=2".
+	Settings []string `json:"settings,omitempty"`
+}
+
+// createPollResponse is the response to a successful createPollRequest.
+type createPollResponse struct {
+	PollID string `json:"poll_id"`
+	PostID string `json:"post_id"`
+}
+
+// configurationResponse is the part of the configuration the webapp reads, see handlePluginConfiguration.
+// Every user can request it, so it must only contain settings that aren't secret.
+type configurationResponse struct {
+	ExperimentalUI bool `json:"experimentalui"`
+}
+
 type (
 	postActionHandler   func(map[string]string, *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error)
 	submitDialogHandler func(map[string]string, *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error)
@@ -39,23 +75,93 @@ var (
 		ID:    "response.vote.updated",
 		Other: "Your vote has been updated.",
 	}
+	responseVoteRemoved = &i18n.Message{
+		ID:    "response.vote.removed",
+		Other: "Your vote has been removed.",
+	}
+	responseVoteMultiRemoved = &i18n.Message{
+		ID:    "response.vote.multi.removed",
+		One:   "Your vote has been removed. You have {{.Remains}} vote left.",
+		Few:   "Your vote has been removed. You have {{.Remains}} votes left.",
+		Many:  "Your vote has been removed. You have {{.Remains}} votes left.",
+		Other: "Your vote has been removed. You have {{.Remains}} votes left.",
+	}
+	responseVoteMultiRemovedWithVotes = &i18n.Message{
+		ID:    "response.vote.multi.removedWithVotes",
+		One:   "Your vote has been removed. You voted for {{.Votes}} and have {{.Remains}} vote left.",
+		Few:   "Your vote has been removed. You voted for {{.Votes}} and have {{.Remains}} votes left.",
+		Many:  "Your vote has been removed. You voted for {{.Votes}} and have {{.Remains}} votes left.",
+		Other: "Your vote has been removed. You voted for {{.Votes}} and have {{.Remains}} votes left.",
+	}
+	responseVoteMultiCounted = &i18n.Message{
+		ID:    "response.vote.multi.counted",
+		One:   "Your vote has been counted. You voted for {{.Votes}} and have {{.Remains}} vote left.",
+		Few:   "Your vote has been counted. You voted for {{.Votes}} and have {{.Remains}} votes left.",
+		Many:  "Your vote has been counted. You voted for {{.Votes}} and have {{.Remains}} votes left.",
+		Other: "Your vote has been counted. You voted for {{.Votes}} and have {{.Remains}} votes left.",
+	}
+	responseVoteUnlimitedCounted = &i18n.Message{
+		ID:    "response.vote.unlimited.counted",
+		Other: "Your vote has been counted. You voted for {{.Votes}}.",
+	}
+	responseVoteUnlimitedRemoved = &i18n.Message{
+		ID:    "response.vote.unlimited.removed",
+		Other: "Your vote has been removed. You voted for {{.Votes}}.",
+	}
+	responseVoteNotChannelMember = &i18n.Message{
+		ID:    "response.vote.notChannelMember",
+		Other: "Only members of this channel are eligible to vote in this poll.",
+	}
 	responseAddOptionSuccess = &i18n.Message{
 		ID:    "response.addOption.success",
 		Other: "Successfully added the option.",
 	}
+	responseAddOtherSuccess = &i18n.Message{
+		ID:    "response.addOther.success",
+		Other: "Your answer has been added and your vote has been counted.",
+	}
+	responseAddOtherNotAllowed = &i18n.Message{
+		ID:    "response.addOther.notAllowed",
+		Other: "This poll doesn't allow other answers.",
+	}
+	responseCommentSaved = &i18n.Message{
+		ID:    "response.comment.saved",
+		Other: "Your comment has been saved.",
+	}
+	responseCommentRemoved = &i18n.Message{
+		ID:    "response.comment.removed",
+		Other: "Your comment has been removed.",
+	}
 	responseAddOptionInvalidPermission = &i18n.Message{
 		ID:    "response.addOption.invalidPermission",
 		Other: "Only the creator of a poll and System Admins are allowed to add options.",
 	}
 
+	responseEditPollSuccess = &i18n.Message{
+		ID:    "response.editPoll.success",
+		Other: "Successfully updated the poll.",
+	}
+	responseEditPollInvalidPermission = &i18n.Message{
+		ID:    "response.editPoll.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to edit it.",
+	}
+
 	responseEndPollSuccessfully = &i18n.Message{
 		ID:    "response.endPoll.successfully",
 		Other: "The poll **{{.Question}}** has ended and the original post has been updated. You can jump to it by pressing [here]({{.Link}}).",
 	}
+	responseEndPollReopenHint = &i18n.Message{
+		ID:    "response.endPoll.reopenHint",
+		Other: "You can re-open the poll within {{.Minutes}} minutes using `/{{.Trigger}} reopen {{.ID}}`.",
+	}
 	responseEndPollInvalidPermission = &i18n.Message{
 		ID:    "response.endPoll.invalidPermission",
 		Other: "Only the creator of a poll and System Admins are allowed to end it.",
 	}
+	responseEndPollQuorumNotMet = &i18n.Message{
+		ID:    "response.endPoll.quorumNotMet",
+		Other: "This poll can't be ended before its quorum is reached.",
+	}
 
 	responseDeletePollSuccess = &i18n.Message{
 		ID:    "response.deletePoll.success",
@@ -65,6 +171,19 @@ var (
 		ID:    "response.deletePoll.invalidPermission",
 		Other: "Only the creator of a poll and System Admins are allowed to delete it.",
 	}
+
+	responseShowVotersSuccess = &i18n.Message{
+		ID:    "response.showVoters.success",
+		Other: "Only you can see who voted for what:\n{{.Results}}",
+	}
+	responseShowVotersInvalidPermission = &i18n.Message{
+		ID:    "response.showVoters.invalidPermission",
+		Other: "Only the creator of a semi-anonymous poll is allowed to see who voted for what.",
+	}
+	responseShowVotersHidden = &i18n.Message{
+		ID:    "response.showVoters.hidden",
+		Other: "Who voted for what can't be shown before a secret poll ends.",
+	}
 )
 
 // InitAPI initializes the REST API
@@ -72,22 +191,42 @@ func (p *MatterpollPlugin) InitAPI() *mux.Router {
 	r := mux.NewRouter()
 	r.HandleFunc("/", p.handleInfo).Methods(http.MethodGet)
 	r.HandleFunc("/"+iconFilename, p.handleLogo).Methods(http.MethodGet)
+	r.HandleFunc("/metrics", p.handleMetrics).Methods(http.MethodGet)
 
 	apiV1 := r.PathPrefix("/api/v1").Subrouter()
 	apiV1.Use(checkAuthenticity)
 	apiV1.HandleFunc("/configuration", p.handlePluginConfiguration).Methods(http.MethodGet)
+	apiV1.HandleFunc("/audit", p.handleAuditLog).Methods(http.MethodGet)
 
+	apiV1.HandleFunc("/polls", p.handleCreatePollRequest).Methods(http.MethodPost)
 	apiV1.HandleFunc("/polls/create", p.handleSubmitDialogRequest(p.handleCreatePoll)).Methods(http.MethodPost)
+	apiV1.HandleFunc("/polls/{id:[a-z0-9]+}", p.handlePollSummary).Methods(http.MethodGet)
 	pollRouter := apiV1.PathPrefix("/polls/{id:[a-z0-9]+}").Subrouter()
-	pollRouter.HandleFunc("/vote/{optionNumber:[0-9]+}", p.handlePostActionIntegrationRequest(p.handleVote)).Methods(http.MethodPost)
-	pollRouter.HandleFunc("/votes/reset", p.handlePostActionIntegrationRequest(p.handleResetVotes)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/{optionNumber:[0-9]+}", p.instrumentVoteHandler("vote", p.handlePostActionIntegrationRequest(p.handleVote))).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/{optionNumber:[0-9]+}/comment", p.instrumentVoteHandler("vote_comment", p.handleSubmitDialogRequest(p.handleVoteWithComment))).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/menu", p.instrumentVoteHandler("vote_menu", p.handlePostActionIntegrationRequest(p.handleVoteMenu))).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/votes/reset", p.instrumentVoteHandler("reset_votes", p.handlePostActionIntegrationRequest(p.handleResetVotes))).Methods(http.MethodPost)
 	pollRouter.HandleFunc("/option/add/request", p.handlePostActionIntegrationRequest(p.handleAddOption)).Methods(http.MethodPost)
 	pollRouter.HandleFunc("/option/add", p.handleSubmitDialogRequest(p.handleAddOptionConfirm)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/option/pending/{optionID:[a-z0-9]+}/approve", p.handleDirectPostActionRequest(p.handleApproveOption)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/option/pending/{optionID:[a-z0-9]+}/reject", p.handleDirectPostActionRequest(p.handleRejectOption)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/option/other/request", p.handlePostActionIntegrationRequest(p.handleAddOther)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/option/other", p.handleSubmitDialogRequest(p.handleAddOtherConfirm)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/comment/request", p.handlePostActionIntegrationRequest(p.handleComment)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/comment", p.handleSubmitDialogRequest(p.handleCommentConfirm)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/edit", p.handlePostActionIntegrationRequest(p.handleEditPoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/edit/confirm", p.handleSubmitDialogRequest(p.handleEditPollConfirm)).Methods(http.MethodPost)
 	pollRouter.HandleFunc("/end", p.handlePostActionIntegrationRequest(p.handleEndPoll)).Methods(http.MethodPost)
 	pollRouter.HandleFunc("/end/confirm", p.handleSubmitDialogRequest(p.handleEndPollConfirm)).Methods(http.MethodPost)
 	pollRouter.HandleFunc("/delete", p.handlePostActionIntegrationRequest(p.handleDeletePoll)).Methods(http.MethodPost)
 	pollRouter.HandleFunc("/delete/confirm", p.handleSubmitDialogRequest(p.handleDeletePollConfirm)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/export", p.handlePostActionIntegrationRequest(p.handleExportResults)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/remind", p.handlePostActionIntegrationRequest(p.handleRemindNonVoters)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/nonvoters", p.handlePostActionIntegrationRequest(p.handleShowNonVoters)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/voters", p.handlePostActionIntegrationRequest(p.handleShowVoters)).Methods(http.MethodPost)
 	pollRouter.HandleFunc("/metadata", p.handlePollMetadata).Methods(http.MethodGet)
+
+	p.initInterPluginAPI(r)
 	return r
 }
 
@@ -114,15 +253,75 @@ func (p *MatterpollPlugin) handleLogo(w http.ResponseWriter, r *http.Request) {
 
 func (p *MatterpollPlugin) handlePluginConfiguration(w http.ResponseWriter, r *http.Request) {
 	configuration := p.getConfiguration()
+	response := &configurationResponse{
+		ExperimentalUI: configuration.ExperimentalUI,
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	err := json.NewEncoder(w).Encode(configuration)
+	err := json.NewEncoder(w).Encode(response)
 	if err != nil {
 		p.API.LogWarn("failed to write configuration response.", "error", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
+// handleMetrics writes the metrics of the plugin in the Prometheus text exposition format.
+// The endpoint is disabled unless a MetricsToken is configured, which has to be sent as bearer token.
+// It's not part of the authenticated API, so Prometheus can scrape it without a Mattermost session.
+func (p *MatterpollPlugin) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	token := p.getConfiguration().MetricsToken
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+		http.Error(w, "not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	activePolls, err := p.countActivePolls()
+	if err != nil {
+		p.API.LogWarn("failed to count active polls", "error", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := p.metrics.WriteTo(w, activePolls); err != nil {
+		p.API.LogWarn("failed to write metrics response", "error", err.Error())
+	}
+}
+
+// countActivePolls returns the number of running polls. Polls that have ended, but can still be re-opened, aren't counted.
+func (p *MatterpollPlugin) countActivePolls() (int, error) {
+	ids, err := p.Store.Poll().ListIDs()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list polls")
+	}
+
+	active := 0
+	for _, id := range ids {
+		poll, err := p.getPoll(id)
+		if err != nil {
+			p.API.LogWarn("failed to get poll", "pollID", id, "error", err.Error())
+			continue
+		}
+		if !poll.HasEnded() {
+			active++
+		}
+	}
+	return active, nil
+}
+
+// instrumentVoteHandler records how long next takes to handle a vote request in the metrics, labeled with name.
+func (p *MatterpollPlugin) instrumentVoteHandler(name string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next(w, r)
+		p.metrics.ObserveVoteDuration(name, time.Since(start))
+	}
+}
+
 func checkAuthenticity(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("Mattermost-User-ID") == "" {
@@ -150,14 +349,14 @@ func (p *MatterpollPlugin) handlePostActionIntegrationRequest(handler postAction
 
 		vars := mux.Vars(r)
 		pollID := vars["id"]
-		poll, err := p.Store.Poll().Get(pollID)
+		poll, err := p.getPoll(pollID)
 		if err != nil {
 			http.Error(w, "failed to get poll", http.StatusInternalServerError)
 			return
 		}
 
 		var rootID string
-		postID := poll.PostID
+		postID := poll.PostIDInChannel(request.ChannelId)
 		if postID != "" {
 			post, appEerr := p.API.GetPost(postID)
 			if appEerr != nil {
@@ -182,28 +381,67 @@ func (p *MatterpollPlugin) handlePostActionIntegrationRequest(handler postAction
 			return
 		}
 
-		userLocalizer := p.getUserLocalizer(request.UserId)
-
-		lc, update, err := handler(mux.Vars(r), request)
-		if err != nil {
-			p.API.LogWarn("failed to handle PostActionIntegrationRequest", "error", err.Error())
+		if len(poll.Broadcasts) > 0 {
+			handler = p.syncBroadcastPosts(poll, handler)
 		}
+		p.respondToPostAction(w, request, rootID, handler, mux.Vars(r))
+	}
+}
 
-		if lc != nil {
-			p.SendEphemeralPost(request.ChannelId, request.UserId, rootID, p.LocalizeWithConfig(userLocalizer, lc))
+// handleDirectPostActionRequest handles post actions of the direct messages the bot sends to a user, e.g. to approve a suggested option.
+// Unlike handlePostActionIntegrationRequest, it requires the action to be triggered in the direct channel of the user and the bot.
+func (p *MatterpollPlugin) handleDirectPostActionRequest(handler postActionHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		request := model.PostActionIntegrationRequestFromJson(r.Body)
+		if request == nil {
+			p.API.LogWarn("failed to decode PostActionIntegrationRequest")
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
 		}
 
-		response := &model.PostActionIntegrationResponse{}
-		if update != nil {
-			response.Update = update
+		if request.UserId != r.Header.Get("Mattermost-User-ID") {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		err = json.NewEncoder(w).Encode(response)
-		if err != nil {
-			p.API.LogWarn("failed to write PostActionIntegrationResponse", "error", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
+		channel, appErr := p.API.GetDirectChannel(request.UserId, p.botUserID)
+		if appErr != nil {
+			http.Error(w, "failed to get direct channel", http.StatusInternalServerError)
+			return
+		}
+		if request.ChannelId != channel.Id {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
 		}
+
+		p.respondToPostAction(w, request, "", handler, mux.Vars(r))
+	}
+}
+
+// respondToPostAction calls the handler of a post action and sends its response.
+// The message of the handler is sent to the user as ephemeral post in the thread of rootID.
+func (p *MatterpollPlugin) respondToPostAction(w http.ResponseWriter, request *model.PostActionIntegrationRequest, rootID string, handler postActionHandler, vars map[string]string) {
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	lc, update, err := handler(vars, request)
+	if err != nil {
+		p.API.LogWarn("failed to handle PostActionIntegrationRequest", "error", err.Error())
+	}
+
+	if lc != nil {
+		p.SendEphemeralPost(request.ChannelId, request.UserId, rootID, p.LocalizeWithConfig(userLocalizer, lc))
+	}
+
+	response := &model.PostActionIntegrationResponse{}
+	if update != nil {
+		response.Update = update
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		p.API.LogWarn("failed to write PostActionIntegrationResponse", "error", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
@@ -226,13 +464,13 @@ func (p *MatterpollPlugin) handleSubmitDialogRequest(handler submitDialogHandler
 		vars := mux.Vars(r)
 		pollID := vars["id"]
 		if pollID != "" {
-			poll, err := p.Store.Poll().Get(pollID)
+			poll, err := p.getPoll(pollID)
 			if err != nil {
 				http.Error(w, "failed to get poll", http.StatusInternalServerError)
 				return
 			}
 
-			postID := poll.PostID
+			postID := poll.PostIDInChannel(request.ChannelId)
 			if postID != "" {
 				post, appEerr := p.API.GetPost(postID)
 				if appEerr != nil {
@@ -280,7 +518,6 @@ func (p *MatterpollPlugin) handleSubmitDialogRequest(handler submitDialogHandler
 }
 
 func (p *MatterpollPlugin) handleCreatePoll(_ map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
-	publicLocalizer := p.getServerLocalizer()
 	creatorID := request.UserId
 
 	question, ok := request.Submission[questionKey].(string)
@@ -289,27 +526,32 @@ func (p *MatterpollPlugin) handleCreatePoll(_ map[string]string, request *model.
 	}
 
 	var answerOptions []string
-	o1, ok := request.Submission["option1"].(string)
-	if !ok {
-		return commandErrorGeneric, nil, errors.Errorf("failed to get option1 key. Value is: %v", request.Submission["option1"])
-	}
-	answerOptions = append(answerOptions, o1)
-
-	o2, ok := request.Submission["option2"].(string)
-	if !ok {
-		return commandErrorGeneric, nil, errors.Errorf("failed to get option2 key. Value is: %v", request.Submission["option2"])
-	}
-	answerOptions = append(answerOptions, o2)
-
-	o3, ok := request.Submission["option3"].(string)
-	if ok && o3 != "" {
-		answerOptions = append(answerOptions, o3)
+	for i := 1; i <= createPollDialogOptions; i++ {
+		key := fmt.Sprintf("option%v", i)
+		o, ok := request.Submission[key].(string)
+		if i <= poll.MinAnswerOptions && !ok {
+			return commandErrorGeneric, nil, errors.Errorf("failed to get %s key. Value is: %v", key, request.Submission[key])
+		}
+		if ok && o != "" {
+			answerOptions = append(answerOptions, o)
+		}
 	}
 
 	userLocalizer := p.getUserLocalizer(creatorID)
 
 	settings := poll.NewSettingsFromSubmission(request.Submission)
-	poll, errMsg := poll.NewPoll(creatorID, question, answerOptions, settings)
+	poll, errMsg := poll.NewPollWithLimits(creatorID, question, answerOptions, settings, p.getConfiguration().pollLimits())
+	if errMsg != nil {
+		response := &model.SubmitDialogResponse{
+			Error: p.LocalizeErrorMessage(userLocalizer, errMsg),
+		}
+		return nil, response, nil
+	}
+
+	errMsg, err := p.allowPollCreation(creatorID)
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
 	if errMsg != nil {
 		response := &model.SubmitDialogResponse{
 			Error: p.LocalizeErrorMessage(userLocalizer, errMsg),
@@ -322,7 +564,7 @@ func (p *MatterpollPlugin) handleCreatePoll(_ map[string]string, request *model.
 		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
 	}
 
-	actions := poll.ToPostActions(publicLocalizer, manifest.Id, displayName)
+	actions := poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName)
 	post := &model.Post{
 		UserId:    p.botUserID,
 		ChannelId: request.ChannelId,
@@ -340,65 +582,190 @@ func (p *MatterpollPlugin) handleCreatePoll(_ map[string]string, request *model.
 	}
 
 	poll.PostID = rPost.Id
+	poll.ChannelID = request.ChannelId
+	poll.RootID = request.CallbackId
 
 	if err := p.Store.Poll().Insert(poll); err != nil {
 		return commandErrorGeneric, nil, errors.Wrap(err, "failed to save poll")
 	}
+	p.metrics.IncPollsCreated()
+	p.saveAuditRecord(p.newAuditRecord(auditEventPollCreated, creatorID, request.ChannelId, poll))
 
 	return nil, nil, nil
 }
 
-func (p *MatterpollPlugin) handleVote(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
-	pollID := vars["id"]
-	optionNumber, _ := strconv.Atoi(vars["optionNumber"])
-	userID := request.UserId
+// handleCreatePollRequest creates a poll from a createPollRequest and posts it as the bot.
+// This allows integrations to create polls without using the slash command.
+func (p *MatterpollPlugin) handleCreatePollRequest(w http.ResponseWriter, r *http.Request) {
+	creatorID := r.Header.Get("Mattermost-User-ID")
+
+	var request createPollRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	newPoll := p.createPollFromRequest(w, creatorID, &request, nil)
+	if newPoll == nil {
+		return
+	}
 
-	poll, err := p.Store.Poll().Get(pollID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(createPollResponse{PollID: newPoll.ID, PostID: newPoll.PostID}); err != nil {
+		p.API.LogWarn("failed to write response", "error", err.Error())
+	}
+}
+
+// createPollFromRequest creates a poll from a createPollRequest on behalf of a user and posts it as the bot.
+// callback is the endpoint of another plugin that subscribed to the results of the poll. It's nil if there is none.
+// If the poll can't be created, an error response is written and nil is returned.
+func (p *MatterpollPlugin) createPollFromRequest(w http.ResponseWriter, creatorID string, request *createPollRequest, callback *poll.ResultsCallback) *poll.Poll {
+	if request.ChannelID == "" {
+		http.Error(w, "missing channel_id", http.StatusBadRequest)
+		return nil
+	}
+
+	if !p.API.HasPermissionToChannel(creatorID, request.ChannelID, model.PERMISSION_CREATE_POST) {
+		http.Error(w, "not authorized", http.StatusForbidden)
+		return nil
+	}
+
+	configuration := p.getConfiguration()
+	newPoll, errMsg := poll.NewPollWithDefaults(creatorID, request.Question, request.AnswerOptions, request.Settings, configuration.pollDefaults(), configuration.pollLimits())
+	if errMsg == nil {
+		errMsg = p.resolveSettings(newPoll, request.ChannelID)
+	}
+	if errMsg != nil {
+		http.Error(w, p.LocalizeErrorMessage(p.getUserLocalizer(creatorID), errMsg), errMsg.HTTPStatus())
+		return nil
+	}
+	newPoll.ResultsCallback = callback
+
+	errMsg, err := p.allowPollCreation(creatorID)
 	if err != nil {
-		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
+		p.API.LogWarn("failed to check rate limit", "error", err.Error())
+		http.Error(w, "failed to create poll", http.StatusInternalServerError)
+		return nil
+	}
+	if errMsg != nil {
+		http.Error(w, p.LocalizeErrorMessage(p.getUserLocalizer(creatorID), errMsg), errMsg.HTTPStatus())
+		return nil
 	}
 
-	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
-	if appErr != nil {
-		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	if newPoll.Settings.ScheduledAt > 0 {
+		// The poll doesn't have a post until it gets posted
+		if err := p.Store.ScheduledPoll().Insert(poll.NewScheduledPoll(newPoll, request.ChannelID, request.RootID)); err != nil {
+			p.API.LogWarn("failed to save scheduled poll", "error", err.Error())
+			http.Error(w, "failed to create poll", http.StatusInternalServerError)
+			return nil
+		}
+	} else if err := p.postPoll(newPoll, request.ChannelID, request.RootID); err != nil {
+		p.API.LogWarn("failed to create poll", "error", err.Error())
+		http.Error(w, "failed to create poll", http.StatusInternalServerError)
+		return nil
 	}
+	return newPoll
+}
 
-	prev := poll.Copy()
-	previouslyVoted := poll.HasVoted(userID)
-	msg, err := poll.UpdateVote(userID, optionNumber)
-	if msg != nil {
-		return &i18n.LocalizeConfig{DefaultMessage: msg}, nil, nil
+// maxUpdateAttempts is the number of times updatePoll tries to save a poll that got modified concurrently.
+const maxUpdateAttempts = 3
+
+// updatePoll gets a poll, applies mutate to it and saves it, if mutate returns true.
+// If the poll got modified concurrently, e.g. by a vote on another node of a cluster, it's read again
+// and mutate is applied to the fresh poll, up to maxUpdateAttempts times. An error of mutate is returned as is.
+func (p *MatterpollPlugin) updatePoll(pollID string, mutate func(*poll.Poll) (bool, error)) (*poll.Poll, error) {
+	for attempt := 1; ; attempt++ {
+		poll, err := p.getPoll(pollID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get poll")
+		}
+
+		prev := poll.Copy()
+		save, err := mutate(poll)
+		if err != nil || !save {
+			return poll, err
+		}
+
+		err = p.Store.Poll().Update(prev, poll)
+		if err == nil {
+			return poll, nil
+		}
+		if errors.Cause(err) != store.ErrConflict || attempt == maxUpdateAttempts {
+			return nil, errors.Wrap(err, "failed to save poll")
+		}
+	}
+}
+
+// checkChannelVoter returns a *poll.VoteError if only members of the channel may vote in a poll
+// and the user isn't one of them.
+func (p *MatterpollPlugin) checkChannelVoter(pl *poll.Poll, channelID, userID string) error {
+	if pl.Settings.Voters != poll.VotersChannel {
+		return nil
+	}
+	if _, appErr := p.API.GetChannelMember(channelID, userID); appErr != nil {
+		return &poll.VoteError{
+			Err:          poll.ErrNotAllowed,
+			ErrorMessage: &poll.ErrorMessage{Message: responseVoteNotChannelMember},
+		}
 	}
+	return nil
+}
+
+func (p *MatterpollPlugin) handleVote(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+	optionNumber, _ := strconv.Atoi(vars["optionNumber"])
+	userID := request.UserId
+
+	var displayName string
+	var previouslyVoted, removed, closed bool
+	pl, err := p.updatePoll(pollID, func(pl *poll.Poll) (bool, error) {
+		if displayName == "" {
+			var appErr *model.AppError
+			if displayName, appErr = p.ConvertCreatorIDToDisplayName(pl.Creator); appErr != nil {
+				return false, errors.Wrap(appErr, "failed to get display name for creator")
+			}
+		}
+
+		if err := p.checkChannelVoter(pl, request.ChannelId, userID); err != nil {
+			return false, err
+		}
+
+		previouslyVoted = pl.HasVoted(userID)
+		var err error
+		if removed, err = pl.ToggleVote(userID, optionNumber); err != nil {
+			return false, err
+		}
+		// A poll that got closed is only saved if it can be re-opened. Otherwise it's deleted by endPollOnQuorum.
+		closed = pl.MaybeAutoClose()
+		return !closed || p.getConfiguration().endedPollLifetime() > 0, nil
+	})
 	if err != nil {
+		if errors.Is(err, poll.ErrCommentRequired) {
+			return p.openVoteCommentDialog(pl, optionNumber, request)
+		}
+		if lc := localizeConfigFromVoteError(err); lc != nil {
+			return lc, nil, nil
+		}
 		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to update poll")
 	}
+	if !removed {
+		p.metrics.IncVotesCast()
+	}
 
-	if err = p.Store.Poll().Update(prev, poll); err != nil {
-		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to save poll")
+	if closed {
+		return p.endPollOnQuorum(pl, displayName, request)
 	}
 
-	go p.publishPollMetadata(poll, userID)
+	go p.publishPollMetadata(pl, userID)
+	p.publishPollResults(websocketEventVote, pl, request.ChannelId)
 
 	post := &model.Post{}
-	publicLocalizer := p.getServerLocalizer()
-	model.ParseSlackAttachment(post, poll.ToPostActions(publicLocalizer, manifest.Id, displayName))
-	post.AddProp("poll_id", poll.ID)
+	model.ParseSlackAttachment(post, pl.ToPostActions(p.getPollLocalizer(pl), manifest.Id, displayName))
+	post.AddProp("poll_id", pl.ID)
 
-	if poll.IsMultiVote() {
-		// Multi Answer Mode
-		votedAnswers := poll.GetVotedAnswers(userID)
-		remains := poll.Settings.MaxVotes - len(votedAnswers)
-		return &i18n.LocalizeConfig{
-			DefaultMessage: &i18n.Message{
-				ID:    "response.vote.multi.updated",
-				One:   "Your vote has been counted. You have {{.Remains}} vote left.",
-				Few:   "Your vote has been counted. You have {{.Remains}} votes left.",
-				Many:  "Your vote has been counted. You have {{.Remains}} votes left.",
-				Other: "Your vote has been counted. You have {{.Remains}} votes left.",
-			},
-			TemplateData: map[string]interface{}{"Remains": remains},
-			PluralCount:  remains,
-		}, post, nil
+	if pl.Settings.HasUnlimitedVotes() || pl.IsMultiVote() {
+		return multiVoteLocalizeConfig(pl, userID, removed), post, nil
 	}
 
 	// Single Answer Mode
@@ -408,6 +775,109 @@ func (p *MatterpollPlugin) handleVote(vars map[string]string, request *model.Pos
 	return &i18n.LocalizeConfig{DefaultMessage: responseVoteCounted}, post, nil
 }
 
+// multiVoteLocalizeConfig returns the response to a vote in a poll that allows to vote for several answer options.
+// It lists the answer options the user has voted for and, if the number of votes is limited, how many votes are left.
+func multiVoteLocalizeConfig(pl *poll.Poll, userID string, removed bool) *i18n.LocalizeConfig {
+	votes := pl.VotedAnswersText(userID)
+	if pl.Settings.HasUnlimitedVotes() {
+		switch {
+		case removed && votes == "":
+			return &i18n.LocalizeConfig{DefaultMessage: responseVoteRemoved}
+		case removed:
+			return &i18n.LocalizeConfig{
+				DefaultMessage: responseVoteUnlimitedRemoved,
+				TemplateData:   map[string]interface{}{"Votes": votes},
+			}
+		default:
+			return &i18n.LocalizeConfig{
+				DefaultMessage: responseVoteUnlimitedCounted,
+				TemplateData:   map[string]interface{}{"Votes": votes},
+			}
+		}
+	}
+
+	remains := pl.Settings.MaxVotes - len(pl.GetVotedAnswers(userID))
+	message := responseVoteMultiCounted
+	switch {
+	case removed && votes == "":
+		message = responseVoteMultiRemoved
+	case removed:
+		message = responseVoteMultiRemovedWithVotes
+	}
+	return &i18n.LocalizeConfig{
+		DefaultMessage: message,
+		TemplateData:   map[string]interface{}{"Votes": votes, "Remains": remains},
+		PluralCount:    remains,
+	}
+}
+
+// handleVoteMenu handles a vote from the select menu of a poll with more answer options than poll.MaxOptionButtons.
+// The number of the answer option is the value of the selected option.
+func (p *MatterpollPlugin) handleVoteMenu(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	selected, _ := request.Context["selected_option"].(string)
+	if _, err := strconv.Atoi(selected); err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Errorf("invalid selected option: %q", selected)
+	}
+
+	vars["optionNumber"] = selected
+	return p.handleVote(vars, request)
+}
+
+// endPollOnQuorum ends a poll that got closed because its quorum was reached.
+func (p *MatterpollPlugin) endPollOnQuorum(poll *poll.Poll, displayName string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	if err := p.readAttributeWeights(poll); err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, err
+	}
+
+	post, appErr := poll.ToEndPollPost(p.getPollLocalizer(poll), displayName, p.ConvertUserIDToDisplayName)
+	if appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to get convert to end poll post")
+	}
+
+	if err := p.storeEndedPoll(poll, request.ChannelId); err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, err
+	}
+	// The poll was closed by the plugin, the user only cast the vote that reached the quorum
+	p.saveAuditRecord(p.newAuditRecord(auditEventPollEnded, "", request.ChannelId, poll))
+
+	postID := poll.PostID
+	if postID == "" {
+		// Legacy check if polls created without a postID
+		postID = request.PostId
+	}
+	p.postEndPollAnnouncement(request.ChannelId, postID, poll, post)
+	p.publishPollEnded(poll, request.ChannelId)
+
+	return &i18n.LocalizeConfig{DefaultMessage: responseVoteCounted}, post, nil
+}
+
+// localizeConfigFromVoteError returns the message of a vote error that should be shown to the user.
+// It returns nil if the error doesn't contain such a message.
+func localizeConfigFromVoteError(err error) *i18n.LocalizeConfig {
+	errMsg := errorMessageFromVoteError(err)
+	if errMsg == nil {
+		return nil
+	}
+	return &i18n.LocalizeConfig{
+		DefaultMessage: errMsg.Message,
+		TemplateData:   errMsg.Data,
+	}
+}
+
+// errorMessageFromVoteError returns the message for the user of a *poll.VoteError or of a *poll.ErrorMessage
+// caused by the user. It returns nil if err contains neither or has no message for the user.
+func errorMessageFromVoteError(err error) *poll.ErrorMessage {
+	var voteErr *poll.VoteError
+	if errors.As(err, &voteErr) {
+		return voteErr.ErrorMessage
+	}
+	var errMsg *poll.ErrorMessage
+	if errors.As(err, &errMsg) && !errMsg.IsInternal() {
+		return errMsg
+	}
+	return nil
+}
+
 func (p *MatterpollPlugin) publishPollMetadata(poll *poll.Poll, userID string) {
 	canManagePoll, appErr := p.CanManagePoll(poll, userID)
 	if appErr != nil {
@@ -418,21 +888,71 @@ func (p *MatterpollPlugin) publishPollMetadata(poll *poll.Poll, userID string) {
 	p.API.PublishWebSocketEvent("has_voted", metadata.ToMap(), &model.WebsocketBroadcast{UserId: userID})
 }
 
+// publishPollResults sends the results of a poll to all members of its channel
+// and of the other channels it has been broadcast to.
+func (p *MatterpollPlugin) publishPollResults(event string, poll *poll.Poll, channelID string) {
+	results := poll.GetResults().ToMap()
+	p.API.PublishWebSocketEvent(event, results, &model.WebsocketBroadcast{ChannelId: channelID})
+	if len(poll.Broadcasts) == 0 {
+		return
+	}
+	for _, b := range poll.Posts() {
+		if b.ChannelID != "" && b.ChannelID != channelID {
+			p.API.PublishWebSocketEvent(event, results, &model.WebsocketBroadcast{ChannelId: b.ChannelID})
+		}
+	}
+}
+
+// publishPollEnded sends the final results of a poll to all members of its channel,
+// to the plugin that subscribed to them and to the board of its board setting, if any.
+// Ended polls may be deleted without being marked as ended, hence a copy is marked first.
+func (p *MatterpollPlugin) publishPollEnded(poll *poll.Poll, channelID string) {
+	ended := poll.Copy()
+	ended.End()
+	p.publishPollResults(websocketEventEnded, ended, channelID)
+
+	if ended.ResultsCallback != nil {
+		// The subscribed plugin may be slow, ending the poll shouldn't wait for it
+		go func() {
+			if err := p.sendResultsCallback(ended); err != nil {
+				p.API.LogWarn("Failed to send results to plugin", "pollID", ended.ID, "error", err.Error())
+			}
+		}()
+	}
+	if ended.Settings.Board != "" {
+		go func() {
+			if err := p.sendResultsToBoard(ended); err != nil {
+				p.API.LogWarn("Failed to send results to board", "pollID", ended.ID, "error", err.Error())
+			}
+		}()
+	}
+}
+
 func (p *MatterpollPlugin) handleResetVotes(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
 	pollID := vars["id"]
 	userID := request.UserId
 
-	poll, err := p.Store.Poll().Get(pollID)
-	if err != nil {
-		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
-	}
+	var displayName string
+	var votedAnswers []string
+	poll, err := p.updatePoll(pollID, func(poll *poll.Poll) (bool, error) {
+		if displayName == "" {
+			var appErr *model.AppError
+			if displayName, appErr = p.ConvertCreatorIDToDisplayName(poll.Creator); appErr != nil {
+				return false, errors.Wrap(appErr, "failed to get display name for creator")
+			}
+		}
 
-	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
-	if appErr != nil {
-		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to get display name for creator")
+		votedAnswers = poll.GetVotedAnswers(userID)
+		if len(votedAnswers) == 0 {
+			return false, nil
+		}
+		poll.ResetVotes(userID)
+		return true, nil
+	})
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to update poll")
 	}
 
-	votedAnswers := poll.GetVotedAnswers(userID)
 	if len(votedAnswers) == 0 {
 		return &i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
 			ID:    "response.resetVotes.noVotes",
@@ -440,19 +960,11 @@ func (p *MatterpollPlugin) handleResetVotes(vars map[string]string, request *mod
 		}}, nil, nil
 	}
 
-	prev := poll.Copy()
-
-	poll.ResetVotes(userID)
-
-	if err = p.Store.Poll().Update(prev, poll); err != nil {
-		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to save poll")
-	}
-
 	go p.publishPollMetadata(poll, userID)
+	p.publishPollResults(websocketEventVote, poll, request.ChannelId)
 
 	post := &model.Post{}
-	publicLocalizer := p.getServerLocalizer()
-	model.ParseSlackAttachment(post, poll.ToPostActions(publicLocalizer, manifest.Id, displayName))
+	model.ParseSlackAttachment(post, poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName))
 	post.AddProp("poll_id", poll.ID)
 
 	return &i18n.LocalizeConfig{
@@ -468,7 +980,7 @@ func (p *MatterpollPlugin) handleAddOption(vars map[string]string, request *mode
 	pollID := vars["id"]
 	userLocalizer := p.getUserLocalizer(request.UserId)
 
-	poll, err := p.Store.Poll().Get(pollID)
+	poll, err := p.getPoll(pollID)
 	if err != nil {
 		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
 	}
@@ -519,7 +1031,7 @@ func (p *MatterpollPlugin) handleAddOption(vars map[string]string, request *mode
 func (p *MatterpollPlugin) handleAddOptionConfirm(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
 	pollID := vars["id"]
 
-	poll, err := p.Store.Poll().Get(pollID)
+	poll, err := p.getPoll(pollID)
 	if err != nil {
 		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
 	}
@@ -547,6 +1059,29 @@ func (p *MatterpollPlugin) handleAddOptionConfirm(vars map[string]string, reques
 		return commandErrorGeneric, nil, errors.Errorf("failed to get submission key: %s", addOptionKey)
 	}
 
+	errMsg, err := p.checkAddedOptionLimit(poll, request.UserId)
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
+	if errMsg != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				addOptionKey: p.LocalizeErrorMessage(p.getUserLocalizer(request.UserId), errMsg),
+			},
+		}
+		return nil, response, nil
+	}
+
+	if poll.Settings.ApproveOptions {
+		canManagePoll, appErr := p.CanManagePoll(poll, request.UserId)
+		if appErr != nil {
+			return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+		}
+		if !canManagePoll {
+			return p.suggestAnswerOption(pollID, request.UserId, answerOption)
+		}
+	}
+
 	prev := poll.Copy()
 	userLocalizer := p.getUserLocalizer(poll.Creator)
 
@@ -558,25 +1093,602 @@ func (p *MatterpollPlugin) handleAddOptionConfirm(vars map[string]string, reques
 		}
 		return nil, response, nil
 	}
+	p.recordAddedOption(poll, request.UserId)
 
-	publicLocalizer := p.getServerLocalizer()
-	model.ParseSlackAttachment(post, poll.ToPostActions(publicLocalizer, manifest.Id, displayName))
+	model.ParseSlackAttachment(post, poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName))
 	if _, appErr = p.API.UpdatePost(post); appErr != nil {
 		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
 	}
+	p.updateBroadcastPosts(poll, post, post.Id)
 
 	if err = p.Store.Poll().Update(prev, poll); err != nil {
 		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get save poll")
 	}
+	p.auditOptionAdded(request.UserId, post.ChannelId, poll, poll.AnswerOptions[len(poll.AnswerOptions)-1].Answer)
+
+	if emoji := poll.ReactionEmoji(len(poll.AnswerOptions) - 1); emoji != "" {
+		if err = p.addPollReaction(post.Id, emoji); err != nil {
+			return commandErrorGeneric, nil, err
+		}
+	}
 
 	return responseAddOptionSuccess, nil, nil
 }
 
-func (p *MatterpollPlugin) handleEndPoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+// handleApproveOption adds an answer option a user suggested to a poll.
+// It's triggered from the direct message the creator got about the suggestion, which gets replaced by the decision.
+func (p *MatterpollPlugin) handleApproveOption(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+	optionID := vars["optionID"]
+
+	var lc *i18n.LocalizeConfig
+	var option *poll.PendingOption
+	poll, err := p.updatePoll(pollID, func(pl *poll.Poll) (bool, error) {
+		var err error
+		if lc, err = p.checkPendingOptionDecision(pl, request.UserId); lc != nil || err != nil {
+			return false, err
+		}
+
+		var errMsg *poll.ErrorMessage
+		option, errMsg = pl.ApprovePendingOption(optionID)
+		if option == nil {
+			lc = &i18n.LocalizeConfig{DefaultMessage: responsePendingOptionNotFound}
+		} else if errMsg != nil {
+			lc = &i18n.LocalizeConfig{DefaultMessage: errMsg.Message, TemplateData: errMsg.Data}
+		}
+		return lc == nil, nil
+	})
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to update poll")
+	}
+	if lc != nil {
+		return lc, nil, nil
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
+	if appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+	post, appErr := p.API.GetPost(poll.PostID)
+	if appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to get post")
+	}
+	model.ParseSlackAttachment(post, poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName))
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to update post")
+	}
+	p.updateBroadcastPosts(poll, post, post.Id)
+
+	if emoji := poll.ReactionEmoji(len(poll.AnswerOptions) - 1); emoji != "" {
+		if err = p.addPollReaction(post.Id, emoji); err != nil {
+			return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, err
+		}
+	}
+
+	p.auditOptionAdded(request.UserId, post.ChannelId, poll, option.Answer)
+	p.notifySuggester(poll, option, suggestionApprovedNotice)
+	return nil, p.suggestionDecisionPost(poll, option, p.getUserLocalizer(request.UserId), suggestionApproved), nil
+}
+
+// handleRejectOption discards an answer option a user suggested for a poll.
+// It's triggered from the direct message the creator got about the suggestion, which gets replaced by the decision.
+func (p *MatterpollPlugin) handleRejectOption(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+	optionID := vars["optionID"]
+
+	var lc *i18n.LocalizeConfig
+	var option *poll.PendingOption
+	poll, err := p.updatePoll(pollID, func(pl *poll.Poll) (bool, error) {
+		var err error
+		if lc, err = p.checkPendingOptionDecision(pl, request.UserId); lc != nil || err != nil {
+			return false, err
+		}
+
+		if option = pl.RejectPendingOption(optionID); option == nil {
+			lc = &i18n.LocalizeConfig{DefaultMessage: responsePendingOptionNotFound}
+		}
+		return lc == nil, nil
+	})
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to update poll")
+	}
+	if lc != nil {
+		return lc, nil, nil
+	}
+
+	p.notifySuggester(poll, option, suggestionRejectedNotice)
+	return nil, p.suggestionDecisionPost(poll, option, p.getUserLocalizer(request.UserId), suggestionRejected), nil
+}
+
+func (p *MatterpollPlugin) handleAddOther(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
+	}
+	if !poll.Settings.AllowOther {
+		return &i18n.LocalizeConfig{DefaultMessage: responseAddOtherNotAllowed}, nil, nil
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/option/other", manifest.Id, pollID),
+		Dialog: model.Dialog{
+			Title: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.addOther.title",
+				Other: "Other Answer",
+			}),
+			IconURL:    fmt.Sprintf(responseIconURL, siteURL, manifest.Id),
+			CallbackId: request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.addOther.submitLabel",
+				Other: "Vote",
+			}),
+			Elements: []model.DialogElement{{
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+					ID:    "dialog.addOther.element.displayName",
+					Other: "Answer",
+				}),
+				Name:    addOptionKey,
+				Type:    "text",
+				SubType: "text",
+			}},
+		},
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to open other answer dialog")
+	}
+	return nil, nil, nil
+}
+
+// handleAddOtherConfirm adds the answer a user wrote in as a new answer option of a poll and votes for it.
+// Answers that can't be added or voted for are shown as error of the dialog.
+func (p *MatterpollPlugin) handleAddOtherConfirm(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	pollID := vars["id"]
+	userID := request.UserId
+
+	answer, ok := request.Submission[addOptionKey].(string)
+	if !ok {
+		return commandErrorGeneric, nil, errors.Errorf("failed to get submission key: %s", addOptionKey)
+	}
+
+	var added, closed bool
+	poll, err := p.updatePoll(pollID, func(pl *poll.Poll) (bool, error) {
+		if !pl.Settings.AllowOther {
+			return false, &poll.VoteError{
+				Err:          poll.ErrNotAllowed,
+				ErrorMessage: &poll.ErrorMessage{Message: responseAddOtherNotAllowed},
+			}
+		}
+		if err := p.checkChannelVoter(pl, request.ChannelId, userID); err != nil {
+			return false, err
+		}
+
+		options := len(pl.AnswerOptions)
+		if err := pl.AddOtherAnswer(userID, answer); err != nil {
+			return false, err
+		}
+		added = len(pl.AnswerOptions) > options
+		if added {
+			errMsg, err := p.checkAddedOptionLimit(pl, userID)
+			if err != nil {
+				return false, err
+			}
+			if errMsg != nil {
+				return false, &poll.VoteError{Err: poll.ErrNotAllowed, ErrorMessage: errMsg}
+			}
+			p.recordAddedOption(pl, userID)
+		}
+		// A poll that got closed is only saved if it can be re-opened. Otherwise it's deleted by endPoll.
+		closed = pl.MaybeAutoClose()
+		return !closed || p.getConfiguration().endedPollLifetime() > 0, nil
+	})
+	if err != nil {
+		if errMsg := errorMessageFromVoteError(err); errMsg != nil {
+			response := &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					addOptionKey: p.LocalizeErrorMessage(p.getUserLocalizer(userID), errMsg),
+				},
+			}
+			return nil, response, nil
+		}
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	}
+	p.metrics.IncVotesCast()
+	if added {
+		// The author of an other answer also voted for it, which mustn't be revealed for anonymous polls
+		actorID := userID
+		if poll.Settings.Anonymous {
+			actorID = ""
+		}
+		p.auditOptionAdded(actorID, request.ChannelId, poll, poll.AnswerOptions[len(poll.AnswerOptions)-1].Answer)
+	}
+
+	if closed {
+		if err := p.endPoll(poll, ""); err != nil {
+			return commandErrorGeneric, nil, errors.Wrap(err, "failed to end poll")
+		}
+		return responseAddOtherSuccess, nil, nil
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+	post, appErr := p.API.GetPost(poll.PostID)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	}
+	model.ParseSlackAttachment(post, poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName))
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	}
+	p.updateBroadcastPosts(poll, post, post.Id)
+
+	if emoji := poll.ReactionEmoji(len(poll.AnswerOptions) - 1); added && emoji != "" {
+		if err = p.addPollReaction(post.Id, emoji); err != nil {
+			return commandErrorGeneric, nil, err
+		}
+	}
+
+	go p.publishPollMetadata(poll, userID)
+	p.publishPollResults(websocketEventVote, poll, post.ChannelId)
+	return responseAddOtherSuccess, nil, nil
+}
+
+// handleComment opens a dialog in which a voter can explain their vote, see poll.Settings.Comments.
+// It contains the earlier comment of the voter, if any.
+func (p *MatterpollPlugin) handleComment(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
 	pollID := vars["id"]
 	userLocalizer := p.getUserLocalizer(request.UserId)
 
-	poll, err := p.Store.Poll().Get(pollID)
+	pl, err := p.getPoll(pollID)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
+	}
+	if err := pl.CanComment(request.UserId); err != nil {
+		if lc := localizeConfigFromVoteError(err); lc != nil {
+			return lc, nil, nil
+		}
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, err
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/comment", manifest.Id, pollID),
+		Dialog: model.Dialog{
+			Title: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.comment.title",
+				Other: "Comment",
+			}),
+			IconURL:    fmt.Sprintf(responseIconURL, siteURL, manifest.Id),
+			CallbackId: request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.comment.submitLabel",
+				Other: "Save",
+			}),
+			Elements: []model.DialogElement{{
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+					ID:    "dialog.comment.element.displayName",
+					Other: "Why did you vote like this?",
+				}),
+				Name:      commentKey,
+				Type:      "textarea",
+				Default:   pl.GetComment(request.UserId),
+				MaxLength: poll.MaxCommentLength,
+				Optional:  true,
+				HelpText: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+					ID:    "dialog.comment.element.helpText",
+					Other: "The comment is listed in the results when the poll ends. Leave it empty to remove your comment.",
+				}),
+			}},
+		},
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to open comment dialog")
+	}
+	return nil, nil, nil
+}
+
+// handleCommentConfirm stores the comment a voter gave for their vote.
+// Comments that can't be stored are shown as error of the dialog.
+func (p *MatterpollPlugin) handleCommentConfirm(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	pollID := vars["id"]
+	userID := request.UserId
+
+	// The element is optional, hence an empty comment isn't submitted at all
+	text, _ := request.Submission[commentKey].(string)
+
+	_, err := p.updatePoll(pollID, func(pl *poll.Poll) (bool, error) {
+		version := pl.Version
+		if err := pl.SetComment(userID, text); err != nil {
+			return false, err
+		}
+		return pl.Version != version, nil
+	})
+	if err != nil {
+		if errMsg := errorMessageFromVoteError(err); errMsg != nil {
+			response := &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					commentKey: p.LocalizeErrorMessage(p.getUserLocalizer(userID), errMsg),
+				},
+			}
+			return nil, response, nil
+		}
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return responseCommentRemoved, nil, nil
+	}
+	return responseCommentSaved, nil, nil
+}
+
+// openVoteCommentDialog opens a dialog in which a user explains a vote for an answer option that requires a comment,
+// see poll.Settings.RequireComment. The vote is only counted when the dialog is submitted.
+func (p *MatterpollPlugin) openVoteCommentDialog(pl *poll.Poll, optionNumber int, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/vote/%d/comment", manifest.Id, pl.ID, optionNumber),
+		Dialog: model.Dialog{
+			Title: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.voteComment.title",
+				Other: "Comment",
+			}),
+			IconURL:    fmt.Sprintf(responseIconURL, siteURL, manifest.Id),
+			CallbackId: request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.voteComment.submitLabel",
+				Other: "Vote",
+			}),
+			Elements: []model.DialogElement{{
+				DisplayName: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+					DefaultMessage: &i18n.Message{
+						ID:    "dialog.voteComment.element.displayName",
+						Other: `Why do you vote for "{{.Answer}}"?`,
+					},
+					TemplateData: map[string]interface{}{"Answer": pl.AnswerOptions[optionNumber].Answer},
+				}),
+				Name:      commentKey,
+				Type:      "textarea",
+				Default:   pl.GetComment(request.UserId),
+				MaxLength: poll.MaxCommentLength,
+				HelpText: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+					ID:    "dialog.voteComment.element.helpText",
+					Other: "A vote for this option needs a comment. It is listed in the results when the poll ends.",
+				}),
+			}},
+		},
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to open vote comment dialog")
+	}
+	return nil, nil, nil
+}
+
+// handleVoteWithComment votes for an answer option that requires a comment and stores the comment the user gave.
+// Votes that can't be counted are shown as error of the dialog.
+func (p *MatterpollPlugin) handleVoteWithComment(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	pollID := vars["id"]
+	optionNumber, _ := strconv.Atoi(vars["optionNumber"])
+	userID := request.UserId
+
+	text, ok := request.Submission[commentKey].(string)
+	if !ok {
+		return commandErrorGeneric, nil, errors.Errorf("failed to get submission key: %s", commentKey)
+	}
+
+	var closed bool
+	poll, err := p.updatePoll(pollID, func(pl *poll.Poll) (bool, error) {
+		if err := p.checkChannelVoter(pl, request.ChannelId, userID); err != nil {
+			return false, err
+		}
+		if err := pl.VoteWithComment(userID, optionNumber, text); err != nil {
+			return false, err
+		}
+		// A poll that got closed is only saved if it can be re-opened. Otherwise it's deleted by endPoll.
+		closed = pl.MaybeAutoClose()
+		return !closed || p.getConfiguration().endedPollLifetime() > 0, nil
+	})
+	if err != nil {
+		if errMsg := errorMessageFromVoteError(err); errMsg != nil {
+			response := &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					commentKey: p.LocalizeErrorMessage(p.getUserLocalizer(userID), errMsg),
+				},
+			}
+			return nil, response, nil
+		}
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	}
+	p.metrics.IncVotesCast()
+
+	if closed {
+		if err := p.endPoll(poll, ""); err != nil {
+			return commandErrorGeneric, nil, errors.Wrap(err, "failed to end poll")
+		}
+		return responseVoteCounted, nil, nil
+	}
+
+	if err := p.restorePollPost(poll); err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll post")
+	}
+	go p.publishPollMetadata(poll, userID)
+	p.publishPollResults(websocketEventVote, poll, request.ChannelId)
+	return responseVoteCounted, nil, nil
+}
+
+func (p *MatterpollPlugin) handleEditPoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, request.UserId)
+	if appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !canManagePoll {
+		return &i18n.LocalizeConfig{DefaultMessage: responseEditPollInvalidPermission}, nil, nil
+	}
+
+	elements := []model.DialogElement{{
+		DisplayName: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+			ID:    "dialog.editPoll.question",
+			Other: "Question",
+		}),
+		Name:    questionKey,
+		Type:    "text",
+		SubType: "text",
+		Default: poll.Question,
+	}}
+	for i, o := range poll.AnswerOptions {
+		// Deleted answer options can't be edited, but they keep their index
+		if o.Deleted {
+			continue
+		}
+		elements = append(elements, model.DialogElement{
+			DisplayName: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: &i18n.Message{
+					ID:    "dialog.editPoll.option",
+					Other: "Option {{ .Number }}",
+				},
+				TemplateData: map[string]interface{}{
+					"Number": i + 1,
+				}}),
+			Name:    editOptionKey(i),
+			Type:    "text",
+			SubType: "text",
+			Default: o.InputText(),
+			HelpText: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.editPoll.option.helpText",
+				Other: "Leave empty to delete the option. Its votes are kept.",
+			}),
+			Optional: true,
+		})
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/edit/confirm", manifest.Id, pollID),
+		Dialog: model.Dialog{
+			Title: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.editPoll.title",
+				Other: "Edit Poll",
+			}),
+			IconURL:    fmt.Sprintf(responseIconURL, siteURL, manifest.Id),
+			CallbackId: request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, &i18n.Message{
+				ID:    "dialog.editPoll.submitLabel",
+				Other: "Save",
+			}),
+			Elements: elements,
+			// The number of answer options is passed on, so that options added in the meantime aren't deleted
+			State: strconv.Itoa(len(poll.AnswerOptions)),
+		},
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to open edit poll dialog")
+	}
+	return nil, nil, nil
+}
+
+func (p *MatterpollPlugin) handleEditPollConfirm(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	pollID := vars["id"]
+
+	question, ok := request.Submission[questionKey].(string)
+	if !ok {
+		return commandErrorGeneric, nil, errors.Errorf("failed to get question key. Value is: %v", request.Submission[questionKey])
+	}
+	editedOptions, err := strconv.Atoi(request.State)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to parse dialog state")
+	}
+
+	var displayName string
+	var errMsg *poll.ErrorMessage
+	poll, err := p.updatePoll(pollID, func(poll *poll.Poll) (bool, error) {
+		if displayName == "" {
+			var appErr *model.AppError
+			if displayName, appErr = p.ConvertCreatorIDToDisplayName(poll.Creator); appErr != nil {
+				return false, errors.Wrap(appErr, "failed to get display name for creator")
+			}
+		}
+
+		answers := make([]string, len(poll.AnswerOptions))
+		for i, o := range poll.AnswerOptions {
+			if o.Deleted {
+				continue
+			}
+			if i >= editedOptions {
+				// The answer option was added after the dialog has been opened
+				answers[i] = o.InputText()
+				continue
+			}
+			// Empty optional fields might not be submitted at all
+			answers[i], _ = request.Submission[editOptionKey(i)].(string)
+		}
+
+		errMsg = poll.Update(question, answers)
+		return errMsg == nil, nil
+	})
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
+	if errMsg != nil {
+		response := &model.SubmitDialogResponse{
+			Error: p.LocalizeErrorMessage(p.getUserLocalizer(request.UserId), errMsg),
+		}
+		return nil, response, nil
+	}
+
+	var postID string
+	if poll.PostID != "" {
+		postID = poll.PostID
+	} else {
+		// Legacy check if polls created without a postID
+		postID = request.CallbackId
+	}
+
+	post, appErr := p.API.GetPost(postID)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	}
+
+	model.ParseSlackAttachment(post, poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName))
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	}
+	p.updateBroadcastPosts(poll, post, post.Id)
+
+	return responseEditPollSuccess, nil, nil
+}
+
+// editOptionKey returns the name of the field in the edit poll dialog for the answer option at index.
+func editOptionKey(index int) string {
+	return fmt.Sprintf("option%v", index+1)
+}
+
+func (p *MatterpollPlugin) handleEndPoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	poll, err := p.getPoll(pollID)
 	if err != nil {
 		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
 	}
@@ -588,6 +1700,9 @@ func (p *MatterpollPlugin) handleEndPoll(vars map[string]string, request *model.
 	if !canManagePoll {
 		return &i18n.LocalizeConfig{DefaultMessage: responseEndPollInvalidPermission}, nil, nil
 	}
+	if poll.EndPrevented() {
+		return &i18n.LocalizeConfig{DefaultMessage: responseEndPollQuorumNotMet}, nil, nil
+	}
 
 	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
 	dialog := model.OpenDialogRequest{
@@ -616,17 +1731,24 @@ func (p *MatterpollPlugin) handleEndPoll(vars map[string]string, request *model.
 func (p *MatterpollPlugin) handleEndPollConfirm(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
 	pollID := vars["id"]
 
-	poll, err := p.Store.Poll().Get(pollID)
+	poll, err := p.getPoll(pollID)
 	if err != nil {
 		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
 	}
+	// Votes may have been reset since the dialog was opened
+	if poll.EndPrevented() {
+		return responseEndPollQuorumNotMet, nil, nil
+	}
 
 	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
 	if appErr != nil {
 		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
 	}
+	if err := p.readAttributeWeights(poll); err != nil {
+		return commandErrorGeneric, nil, err
+	}
 
-	post, appErr := poll.ToEndPollPost(p.getServerLocalizer(), displayName, p.ConvertUserIDToDisplayName)
+	post, appErr := poll.ToEndPollPost(p.getPollLocalizer(poll), displayName, p.ConvertUserIDToDisplayName)
 	if appErr != nil {
 		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get convert to end poll post")
 	}
@@ -643,29 +1765,92 @@ func (p *MatterpollPlugin) handleEndPollConfirm(vars map[string]string, request
 	if _, appErr = p.API.UpdatePost(post); appErr != nil {
 		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
 	}
+	p.updateBroadcastPosts(poll, post, post.Id)
 
-	if err := p.Store.Poll().Delete(poll); err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(err, "failed to delete poll")
+	if err := p.storeEndedPoll(poll, request.ChannelId); err != nil {
+		return commandErrorGeneric, nil, err
 	}
+	p.saveAuditRecord(p.newAuditRecord(auditEventPollEnded, request.UserId, request.ChannelId, poll))
 
-	p.postEndPollAnnouncement(request.ChannelId, post.Id, poll.Question)
+	p.postEndPollAnnouncement(request.ChannelId, post.Id, poll, post)
+	p.publishPollEnded(poll, request.ChannelId)
+
+	if gracePeriod := p.getConfiguration().reopenGracePeriod(); gracePeriod > 0 {
+		p.SendEphemeralPost(request.ChannelId, request.UserId, "", p.LocalizeWithConfig(p.getUserLocalizer(request.UserId), &i18n.LocalizeConfig{
+			DefaultMessage: responseEndPollReopenHint,
+			TemplateData: map[string]interface{}{
+				"Minutes": int(gracePeriod / time.Minute),
+				"Trigger": p.getConfiguration().Trigger,
+				"ID":      poll.ID,
+			},
+		}))
+	}
 
 	return nil, nil, nil
 }
 
-func (p *MatterpollPlugin) postEndPollAnnouncement(channelID, postID, question string) {
+// storeEndedPoll removes a poll, that has ended, from the store and archives its outcome. If a grace period for
+// re-opening polls or a retention period is configured, the poll is kept as ended instead and gets deleted by
+// endExpiredPolls once both have passed.
+func (p *MatterpollPlugin) storeEndedPoll(poll *poll.Poll, channelID string) error {
+	if p.getConfiguration().endedPollLifetime() <= 0 {
+		if err := p.Store.Poll().Delete(poll); err != nil {
+			return errors.Wrap(err, "failed to delete poll")
+		}
+		p.archivePoll(poll, channelID)
+		p.metrics.IncPollsEnded()
+		return nil
+	}
+
+	if !poll.HasEnded() {
+		prev := poll.Copy()
+		poll.End()
+		if err := p.Store.Poll().Update(prev, poll); err != nil {
+			return errors.Wrap(err, "failed to save ended poll")
+		}
+	}
+	// Otherwise the poll was already saved when it got closed, e.g. by MaybeAutoClose
+	p.archivePoll(poll, channelID)
+	p.metrics.IncPollsEnded()
+	return nil
+}
+
+// postEndPollAnnouncement replies to the post of a poll that has ended. If the results should be posted in the thread,
+// the reply also contains results, the end poll post, and gets pinned to the channel. The chart of the results and the
+// meeting invite are attached to the reply, if the poll has them.
+func (p *MatterpollPlugin) postEndPollAnnouncement(channelID, postID string, poll *poll.Poll, results *model.Post) {
 	endPost := &model.Post{
 		UserId:    p.botUserID,
 		ChannelId: channelID,
 		RootId:    postID,
-		Message: p.LocalizeWithConfig(p.getServerLocalizer(), &i18n.LocalizeConfig{
+		Message: p.LocalizeWithConfig(p.getPollLocalizer(poll), &i18n.LocalizeConfig{
 			DefaultMessage: responseEndPollSuccessfully,
 			TemplateData: map[string]interface{}{
-				"Question": question,
+				"Question": poll.Question,
 				"Link":     fmt.Sprintf("%s/_redirect/pl/%s", *p.ServerConfig.ServiceSettings.SiteURL, postID),
 			}}),
 		Type: model.POST_DEFAULT,
 	}
+	if poll.Settings.ThreadResults || p.getConfiguration().ThreadResults {
+		model.ParseSlackAttachment(endPost, results.Attachments())
+		endPost.IsPinned = true
+	}
+	if poll.Settings.Chart != "" {
+		fileID, err := p.uploadResultsChart(poll, channelID)
+		if err != nil {
+			p.API.LogWarn("Failed to upload the results chart", "pollID", poll.ID, "error", err.Error())
+		} else if fileID != "" {
+			endPost.FileIds = append(endPost.FileIds, fileID)
+		}
+	}
+	if poll.Settings.Invite {
+		fileID, err := p.uploadMeetingInvite(poll, channelID)
+		if err != nil {
+			p.API.LogWarn("Failed to upload the meeting invite", "pollID", poll.ID, "error", err.Error())
+		} else if fileID != "" {
+			endPost.FileIds = append(endPost.FileIds, fileID)
+		}
+	}
 
 	if _, err := p.API.CreatePost(endPost); err != nil {
 		p.API.LogWarn("Failed to post the end poll announcement", "details", "failed to CreatePost", "error", err.Error())
@@ -676,7 +1861,7 @@ func (p *MatterpollPlugin) handleDeletePoll(vars map[string]string, request *mod
 	pollID := vars["id"]
 	userLocalizer := p.getUserLocalizer(request.UserId)
 
-	poll, err := p.Store.Poll().Get(pollID)
+	poll, err := p.getPoll(pollID)
 	if err != nil {
 		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
 	}
@@ -717,7 +1902,7 @@ func (p *MatterpollPlugin) handleDeletePoll(vars map[string]string, request *mod
 func (p *MatterpollPlugin) handleDeletePollConfirm(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
 	pollID := vars["id"]
 
-	poll, err := p.Store.Poll().Get(pollID)
+	poll, err := p.getPoll(pollID)
 	if err != nil {
 		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
 	}
@@ -733,20 +1918,148 @@ func (p *MatterpollPlugin) handleDeletePollConfirm(vars map[string]string, reque
 	if appErr := p.API.DeletePost(postID); appErr != nil {
 		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to delete post")
 	}
+	p.deleteBroadcastPosts(poll)
 
 	if err := p.Store.Poll().Delete(poll); err != nil {
 		return commandErrorGeneric, nil, errors.Wrap(err, "failed to delete poll")
 	}
+	p.unarchivePoll(poll, request.ChannelId)
+	p.saveAuditRecord(p.newAuditRecord(auditEventPollDeleted, request.UserId, request.ChannelId, poll))
 
 	return responseDeletePollSuccess, nil, nil
 }
 
+func (p *MatterpollPlugin) handleExportResults(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, request.UserId)
+	if appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !canManagePoll {
+		return &i18n.LocalizeConfig{DefaultMessage: responseExportResultsInvalidPermission}, nil, nil
+	}
+
+	if err := p.sendResultsCSV(poll, request.UserId); err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to send results")
+	}
+	return &i18n.LocalizeConfig{DefaultMessage: responseExportResultsSuccess}, nil, nil
+}
+
+func (p *MatterpollPlugin) handleRemindNonVoters(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, request.UserId)
+	if appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !canManagePoll {
+		return &i18n.LocalizeConfig{DefaultMessage: responseRemindNonVotersInvalidPermission}, nil, nil
+	}
+	if poll.HasEnded() {
+		return &i18n.LocalizeConfig{DefaultMessage: responseRemindNonVotersEnded}, nil, nil
+	}
+
+	reminded, err := p.remindNonVoters(poll, request.ChannelId)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to remind users")
+	}
+	return remindNonVotersResponse(poll, reminded), nil, nil
+}
+
+func (p *MatterpollPlugin) handleShowNonVoters(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, request.UserId)
+	if appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !canManagePoll {
+		return &i18n.LocalizeConfig{DefaultMessage: responseShowNonVotersInvalidPermission}, nil, nil
+	}
+	if poll.HidesVoters() {
+		return &i18n.LocalizeConfig{DefaultMessage: responseShowNonVotersHidden}, nil, nil
+	}
+
+	nonVoters, eligible, err := p.channelParticipation(poll, request.ChannelId)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get users who haven't voted")
+	}
+	return showNonVotersResponse(nonVoters, eligible), nil, nil
+}
+
+// handleShowVoters shows the creator of a semi-anonymous poll who voted for what.
+// Unlike the other actions for managing a poll, System Admins aren't allowed to use it.
+func (p *MatterpollPlugin) handleShowVoters(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+	pollID := vars["id"]
+
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	if !poll.Settings.SemiAnonymous || poll.Creator != request.UserId {
+		return &i18n.LocalizeConfig{DefaultMessage: responseShowVotersInvalidPermission}, nil, nil
+	}
+	if !poll.ShowsVotersTo(request.UserId) {
+		return &i18n.LocalizeConfig{DefaultMessage: responseShowVotersHidden}, nil, nil
+	}
+
+	results, appErr := poll.MarkdownResultsForUser(p.getUserLocalizer(request.UserId), p.ConvertUserIDToDisplayName, request.UserId)
+	if appErr != nil {
+		return &i18n.LocalizeConfig{DefaultMessage: commandErrorGeneric}, nil, errors.Wrap(appErr, "failed to render results")
+	}
+	return &i18n.LocalizeConfig{
+		DefaultMessage: responseShowVotersSuccess,
+		TemplateData:   map[string]interface{}{"Results": results},
+	}, nil, nil
+}
+
+// handlePollSummary writes the question, the answer options and the results of a poll as JSON, e.g. for dashboards
+// that use a personal access token. Only users who may read a channel the poll is posted in can get it.
+// Polls they can't see are reported as not found, so that they don't learn that these exist.
+func (p *MatterpollPlugin) handlePollSummary(w http.ResponseWriter, r *http.Request) {
+	pollID := mux.Vars(r)["id"]
+	userID := r.Header.Get("Mattermost-User-Id")
+
+	poll, err := p.getPoll(pollID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		p.API.LogWarn("failed to get poll", "pollID", pollID, "error", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if err != nil || poll.PostID == "" || p.readableChannelID(poll, "", userID) == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(poll.GetSummary(userID)); err != nil {
+		p.API.LogWarn("failed to write response", "error", err.Error())
+	}
+}
+
 func (p *MatterpollPlugin) handlePollMetadata(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	pollID := vars["id"]
 	userID := r.Header.Get("Mattermost-User-Id")
 
-	poll, err := p.Store.Poll().Get(pollID)
+	poll, err := p.getPoll(pollID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		p.API.LogWarn("failed to get poll", "error", err.Error())
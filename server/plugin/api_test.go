@@ -9,16 +9,20 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"bou.ke/monkey"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 )
@@ -144,6 +148,13 @@ func TestHandlePluginConfiguration(t *testing.T) {
 			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
 			defer api.AssertExpectations(t)
 			p := setupTestPlugin(t, api, &mockstore.Store{})
+			p.setConfiguration(&configuration{
+				Trigger:           "poll",
+				ExperimentalUI:    true,
+				VoterHashKey:      "voterHashKey",
+				MetricsToken:      "metricsToken",
+				ResultsWebhookURL: "https://example.com/results",
+			})
 
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, "/api/v1/configuration", nil)
@@ -162,13 +173,96 @@ func TestHandlePluginConfiguration(t *testing.T) {
 				assert.Equal([]byte{}, bodyBytes)
 				assert.Equal(http.Header{}, result.Header)
 			} else {
-				assert.NotNil(bodyBytes)
+				assert.JSONEq(`{"experimentalui": true}`, string(bodyBytes))
+				assert.NotContains(string(bodyBytes), "voterHashKey")
+				assert.NotContains(string(bodyBytes), "metricsToken")
+				assert.NotContains(string(bodyBytes), "example.com")
 				assert.Contains([]string{"application/json"}, result.Header.Get("Content-Type"))
 			}
 		})
 	}
 }
 
+func TestHandleMetrics(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		MetricsToken       string
+		Authorization      string
+		ExpectedStatusCode int
+		ExpectedBody       []string
+	}{
+		"Valid token": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				ended := testutils.GetPoll()
+				ended.EndedAt = 1234567890
+				store.PollStore.On("ListIDs").Return([]string{"pollID1", "pollID2", "pollID3"}, nil)
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Get", "pollID2").Return(ended, nil)
+				store.PollStore.On("Get", "pollID3").Return(testutils.GetPoll(), nil)
+				return store
+			},
+			MetricsToken:       "token",
+			Authorization:      "Bearer token",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody: []string{
+				"matterpoll_polls_created_total 1\n",
+				"matterpoll_votes_cast_total 2\n",
+				"matterpoll_active_polls 2\n",
+			},
+		},
+		"Invalid token": {
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			MetricsToken:       "token",
+			Authorization:      "Bearer wrong",
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		"Metrics disabled": {
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Authorization:      "Bearer ",
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		"Failed to list polls": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return(nil, &model.AppError{})
+				return store
+			},
+			MetricsToken:       "token",
+			Authorization:      "Bearer token",
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.setConfiguration(&configuration{Trigger: "poll", MetricsToken: test.MetricsToken})
+			p.metrics.IncPollsCreated()
+			p.metrics.IncVotesCast()
+			p.metrics.IncVotesCast()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			r.Header.Add("Authorization", test.Authorization)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+
+			bodyBytes, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			for _, line := range test.ExpectedBody {
+				assert.Contains(t, string(bodyBytes), line)
+			}
+		})
+	}
+}
+
 func TestHandleCreatePoll(t *testing.T) {
 	t.Run("not-authorized", func(t *testing.T) {
 		api := &plugintest.API{}
@@ -189,9 +283,17 @@ func TestHandleCreatePoll(t *testing.T) {
 	})
 
 	expectedPoll := testutils.GetPoll()
+	expectedPoll.ModifiedAt = expectedPoll.CreatedAt
 	userID := expectedPoll.Creator
 	channelID := model.NewId()
 	rootID := model.NewId()
+	// New polls store the channel and the thread they were posted in
+	inChannel := func(pl *poll.Poll) *poll.Poll {
+		pl = pl.Copy()
+		pl.ChannelID = channelID
+		pl.RootID = rootID
+		return pl
+	}
 	expectedPost := &model.Post{
 		UserId:    testutils.GetBotUserID(),
 		ChannelId: channelID,
@@ -205,6 +307,7 @@ func TestHandleCreatePoll(t *testing.T) {
 
 	pollWithTwoOptions := testutils.GetPoll()
 	pollWithTwoOptions.AnswerOptions = pollWithTwoOptions.AnswerOptions[0:2]
+	pollWithTwoOptions.ModifiedAt = pollWithTwoOptions.CreatedAt
 	expectedPostTwoOptions := &model.Post{
 		UserId:    testutils.GetBotUserID(),
 		ChannelId: channelID,
@@ -216,7 +319,22 @@ func TestHandleCreatePoll(t *testing.T) {
 	}
 	model.ParseSlackAttachment(expectedPostTwoOptions, pollWithTwoOptions.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
 
+	pollWithFourOptions := testutils.GetPoll()
+	pollWithFourOptions.AnswerOptions = append(pollWithFourOptions.AnswerOptions[0:2], &poll.AnswerOption{Answer: "Answer 4"}, &poll.AnswerOption{Answer: "Answer 5"})
+	pollWithFourOptions.ModifiedAt = pollWithFourOptions.CreatedAt
+	expectedPostFourOptions := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: channelID,
+		RootId:    rootID,
+		Type:      MatterpollPostType,
+		Props: model.StringInterface{
+			"poll_id": testutils.GetPollID(),
+		},
+	}
+	model.ParseSlackAttachment(expectedPostFourOptions, pollWithFourOptions.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
+
 	pollWithSettings := testutils.GetPollWithSettings(poll.Settings{Progress: true, Anonymous: true, PublicAddOption: true, MaxVotes: 3})
+	pollWithSettings.ModifiedAt = pollWithSettings.CreatedAt
 	expectedPostWithSettings := &model.Post{
 		UserId:    testutils.GetBotUserID(),
 		ChannelId: channelID,
@@ -248,7 +366,7 @@ func TestHandleCreatePoll(t *testing.T) {
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Insert", pollWithTwoOptions).Return(nil)
+				store.PollStore.On("Insert", inChannel(pollWithTwoOptions)).Return(nil)
 				return store
 			},
 			Request: &model.SubmitDialogRequest{
@@ -277,7 +395,7 @@ func TestHandleCreatePoll(t *testing.T) {
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Insert", expectedPoll).Return(nil)
+				store.PollStore.On("Insert", inChannel(expectedPoll)).Return(nil)
 				return store
 			},
 			Request: &model.SubmitDialogRequest{
@@ -295,6 +413,38 @@ func TestHandleCreatePoll(t *testing.T) {
 			ExpectedResponse:   nil,
 			ExpectedMsg:        "",
 		},
+		"Valid request, empty optional option": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+
+				rPost := expectedPostFourOptions.Clone()
+				rPost.Id = "postID1"
+				api.On("CreatePost", expectedPostFourOptions).Return(rPost, nil)
+
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Insert", inChannel(pollWithFourOptions)).Return(nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: rootID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					"question": pollWithFourOptions.Question,
+					"option1":  "Answer 1",
+					"option2":  "Answer 2",
+					"option3":  "",
+					"option4":  "Answer 4",
+					"option5":  "Answer 5",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+			ExpectedMsg:        "",
+		},
 		"Valid request with settings": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_READ_CHANNEL).Return(true)
@@ -307,7 +457,7 @@ func TestHandleCreatePoll(t *testing.T) {
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Insert", pollWithSettings).Return(nil)
+				store.PollStore.On("Insert", inChannel(pollWithSettings)).Return(nil)
 				return store
 			},
 			Request: &model.SubmitDialogRequest{
@@ -445,7 +595,7 @@ func TestHandleCreatePoll(t *testing.T) {
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Insert", pollWithTwoOptions).Return(errors.New(""))
+				store.PollStore.On("Insert", inChannel(pollWithTwoOptions)).Return(errors.New(""))
 				return store
 			},
 			Request: &model.SubmitDialogRequest{
@@ -561,7 +711,220 @@ func TestHandleCreatePoll(t *testing.T) {
 	}
 }
 
+func TestHandleCreatePollRequest(t *testing.T) {
+	userID := testutils.GetPoll().Creator
+	channelID := model.NewId()
+	rootID := model.NewId()
+
+	expectedPoll := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 2})
+	expectedPoll.ModifiedAt = expectedPoll.CreatedAt
+	rPoll := expectedPoll.Copy()
+	rPoll.PostID = "postID1"
+	rPoll.ChannelID = channelID
+	rPoll.RootID = rootID
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: channelID,
+		RootId:    rootID,
+		Type:      MatterpollPostType,
+		Props: model.StringInterface{
+			"poll_id": testutils.GetPollID(),
+		},
+	}
+	model.ParseSlackAttachment(expectedPost, expectedPoll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
+
+	validRequest := &createPollRequest{
+		ChannelID:     channelID,
+		RootID:        rootID,
+		Question:      expectedPoll.Question,
+		AnswerOptions: []string{"Answer 1", "Answer 2", "Answer 3"},
+		Settings:      []string{"anonymous", "votes=2"},
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Body               string
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+
+				rPost := expectedPost.Clone()
+				rPost.Id = "postID1"
+				api.On("CreatePost", expectedPost).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Insert", rPoll).Return(nil)
+				return store
+			},
+			Body:               string(mustMarshal(t, validRequest)),
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedBody:       `{"poll_id":"` + testutils.GetPollID() + `","post_id":"postID1"}` + "\n",
+		},
+		"Invalid request, malformed body": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Body:               "{",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "invalid request\n",
+		},
+		"Invalid request, missing channel": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Body:               `{"question":"Question","answer_options":["Yes","No"]}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "missing channel_id\n",
+		},
+		"Invalid request, without permission to post": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(false)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Body:               string(mustMarshal(t, validRequest)),
+			ExpectedStatusCode: http.StatusForbidden,
+			ExpectedBody:       "not authorized\n",
+		},
+		"Invalid request, duplicate option": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Body:               `{"channel_id":"` + channelID + `","question":"Question","answer_options":["abc","abc"]}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "Duplicate option: abc\n",
+		},
+		"Invalid request, unknown setting": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Body:               `{"channel_id":"` + channelID + `","question":"Question","answer_options":["Yes","No"],"settings":["unknown"]}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "Unrecognized poll setting: unknown\n",
+		},
+		"Valid request, CreatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", expectedPost).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Body:               string(mustMarshal(t, validRequest)),
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to create poll\n",
+		},
+		"Valid request, Insert fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+
+				rPost := expectedPost.Clone()
+				rPost.Id = "postID1"
+				api.On("CreatePost", expectedPost).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Insert", rPoll).Return(errors.New(""))
+				return store
+			},
+			Body:               string(mustMarshal(t, validRequest)),
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to create poll\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			patch2 := monkey.Patch(model.NewId, testutils.GetPollID)
+			defer patch1.Unpatch()
+			defer patch2.Unpatch()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", bytes.NewReader([]byte(test.Body)))
+			r.Header.Add("Mattermost-User-ID", userID)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			body, err := ioutil.ReadAll(result.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(t, test.ExpectedBody, string(body))
+		})
+	}
+
+	t.Run("rate limit reached", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(true)
+		api.On("GetUser", userID).Return(&model.User{}, nil)
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.RateLimitStore.On("Take", "polls_"+userID, 1, time.Hour).Return(false, nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+		p.setConfiguration(&configuration{Trigger: "poll", MaxPollsPerHour: "1"})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", bytes.NewReader(mustMarshal(t, validRequest)))
+		r.Header.Add("Mattermost-User-ID", userID)
+		p.ServeHTTP(nil, w, r)
+		result := w.Result()
+		defer result.Body.Close()
+		body, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusTooManyRequests, result.StatusCode)
+		assert.Equal(t, "You can only create 1 polls per hour. Please try again later.\n", string(body))
+	})
+
+	t.Run("not-authorized", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", bytes.NewReader(mustMarshal(t, validRequest)))
+		p.ServeHTTP(nil, w, r)
+		result := w.Result()
+		defer result.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+	})
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return b
+}
+
 func TestHandleVote(t *testing.T) {
+	// Votes and new options update the modification time of a poll
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
 	t.Run("not-authorized", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
@@ -586,58 +949,77 @@ func TestHandleVote(t *testing.T) {
 
 	poll1In := testutils.GetPoll()
 	poll1Out := poll1In.Copy()
-	msg, err := poll1Out.UpdateVote("userID1", 0)
-	require.Nil(t, msg)
+	err := poll1Out.UpdateVote("userID1", 0)
 	require.Nil(t, err)
 	expectedPost1 := &model.Post{}
 	model.ParseSlackAttachment(expectedPost1, poll1Out.ToPostActions(localizer, manifest.Id, "John Doe"))
 
 	poll2In := testutils.GetPoll()
-	msg, err = poll2In.UpdateVote("userID1", 0)
-	require.Nil(t, msg)
+	err = poll2In.UpdateVote("userID1", 0)
 	require.Nil(t, err)
 	poll2Out := poll2In.Copy()
-	msg, err = poll2Out.UpdateVote("userID1", 1)
-	require.Nil(t, msg)
+	err = poll2Out.UpdateVote("userID1", 1)
 	require.Nil(t, err)
 	expectedPost2 := &model.Post{}
 	model.ParseSlackAttachment(expectedPost2, poll2Out.ToPostActions(localizer, manifest.Id, "John Doe"))
 
 	poll3In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2})
 	poll3Out := poll3In.Copy()
-	msg, err = poll3Out.UpdateVote("userID2", 0)
-	require.Nil(t, msg)
+	err = poll3Out.UpdateVote("userID2", 0)
 	require.Nil(t, err)
 	expectedPost3 := &model.Post{}
 	model.ParseSlackAttachment(expectedPost3, poll3Out.ToPostActions(localizer, manifest.Id, "John Doe"))
 
 	poll4In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2})
-	msg, err = poll4In.UpdateVote("userID1", 0)
-	require.Nil(t, msg)
+	err = poll4In.UpdateVote("userID1", 0)
 	require.Nil(t, err)
 	poll4Out := poll4In.Copy()
-	msg, err = poll4Out.UpdateVote("userID1", 1)
-	require.Nil(t, msg)
+	err = poll4Out.UpdateVote("userID1", 1)
 	require.Nil(t, err)
 	expectedPost4 := &model.Post{}
 	model.ParseSlackAttachment(expectedPost4, poll4Out.ToPostActions(localizer, manifest.Id, "John Doe"))
 
 	poll5In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2})
-	msg, err = poll5In.UpdateVote("userID1", 0)
-	require.Nil(t, msg)
+	err = poll5In.UpdateVote("userID1", 0)
 	require.Nil(t, err)
-	msg, err = poll5In.UpdateVote("userID1", 1)
-	require.Nil(t, msg)
+	err = poll5In.UpdateVote("userID1", 1)
 	require.Nil(t, err)
 
 	poll6In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2})
 	poll6Out := poll6In.Copy()
-	msg, err = poll6Out.UpdateVote("userID2", 1)
-	require.Nil(t, msg)
+	err = poll6Out.UpdateVote("userID2", 1)
 	require.Nil(t, err)
 	expectedPost6 := &model.Post{}
 	model.ParseSlackAttachment(expectedPost6, poll6Out.ToPostActions(localizer, manifest.Id, "John Doe"))
 
+	poll7In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quorum: 1, CloseOnQuorum: true})
+	poll7Out := poll7In.Copy()
+	err = poll7Out.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	expectedPost7, appErr := poll7Out.ToEndPollPost(localizer, "John Doe", func(string) (string, *model.AppError) { return "@user1", nil })
+	require.Nil(t, appErr)
+
+	poll8In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2})
+	err = poll8In.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	poll8Out := poll8In.Copy()
+	removed, err := poll8Out.ToggleVote("userID1", 0)
+	require.Nil(t, err)
+	require.True(t, removed)
+	expectedPost8 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost8, poll8Out.ToPostActions(localizer, manifest.Id, "John Doe"))
+
+	// Another user voted between reading and saving the poll
+	errConflict := store.ErrConflict
+	pollConcurrentIn := testutils.GetPoll()
+	err = pollConcurrentIn.UpdateVote("userID2", 1)
+	require.Nil(t, err)
+	pollConcurrentOut := pollConcurrentIn.Copy()
+	err = pollConcurrentOut.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	expectedPostConcurrent := &model.Post{}
+	model.ParseSlackAttachment(expectedPostConcurrent, pollConcurrentOut.ToPostActions(localizer, manifest.Id, "John Doe"))
+
 	post := &model.Post{
 		ChannelId: "channelID1",
 	}
@@ -650,6 +1032,7 @@ func TestHandleVote(t *testing.T) {
 		ExpectedStatusCode int
 		ExpectedResponse   *model.PostActionIntegrationResponse
 		ExpectedMsg        string
+		ReopenGracePeriod  string
 	}{
 		"Valid request with no votes": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -663,6 +1046,7 @@ func TestHandleVote(t *testing.T) {
 					"can_manage_poll":           true,
 					"setting_public_add_option": false,
 				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, poll1Out.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
@@ -676,6 +1060,51 @@ func TestHandleVote(t *testing.T) {
 			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost1},
 			ExpectedMsg:        "Your vote has been counted.",
 		},
+		"Valid request, quorum reached with close-on-quorum": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll7In.Copy(), nil)
+				store.PollStore.On("Delete", mock.AnythingOfType("*poll.Poll")).Return(nil)
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost7},
+			ExpectedMsg:        "Your vote has been counted.",
+		},
+		"Valid request, quorum reached with close-on-quorum, poll is kept for re-opening": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll7In.Copy(), nil)
+				store.PollStore.On("Update", poll7In, mock.MatchedBy(func(p *poll.Poll) bool {
+					return p.HasEnded()
+				})).Return(nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost7},
+			ExpectedMsg:        "Your vote has been counted.",
+			ReopenGracePeriod:  "10",
+		},
 		"Valid request with no votes, poll without postID": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
@@ -687,6 +1116,7 @@ func TestHandleVote(t *testing.T) {
 					"can_manage_poll":           true,
 					"setting_public_add_option": false,
 				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
@@ -717,6 +1147,7 @@ func TestHandleVote(t *testing.T) {
 					"voted_answers":             []string{"Answer 1"},
 					"setting_public_add_option": false,
 				}, &model.WebsocketBroadcast{UserId: "userID2"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
@@ -728,7 +1159,7 @@ func TestHandleVote(t *testing.T) {
 			VoteIndex:          0,
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost3},
-			ExpectedMsg:        "Your vote has been counted. You have 1 vote left.",
+			ExpectedMsg:        "Your vote has been counted. You voted for :one: Answer 1 and have 1 vote left.",
 		},
 		"Valid request, with multi setting, second vote": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -742,6 +1173,7 @@ func TestHandleVote(t *testing.T) {
 					"voted_answers":             []string{"Answer 1", "Answer 2"},
 					"setting_public_add_option": false,
 				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
@@ -753,16 +1185,42 @@ func TestHandleVote(t *testing.T) {
 			VoteIndex:          1,
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost4},
-			ExpectedMsg:        "Your vote has been counted. You have 0 votes left.",
+			ExpectedMsg:        "Your vote has been counted. You voted for :one: Answer 1, :two: Answer 2 and have 0 votes left.",
 		},
-		"Valid request, with multi setting, over the max": {
+		"Valid request, with multi setting, remove vote": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetPost", "postID1").Return(post, nil)
 				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
 				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
-				return api
-			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				api.On("PublishWebSocketEvent", "has_voted", map[string]interface{}{
+					"can_manage_poll":           true,
+					"poll_id":                   testutils.GetPollID(),
+					"user_id":                   "userID1",
+					"voted_answers":             []string{},
+					"setting_public_add_option": false,
+				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll8In.Copy(), nil)
+				store.PollStore.On("Update", poll8In, poll8Out).Return(nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost8},
+			ExpectedMsg:        "Your vote has been removed. You have 2 votes left.",
+		},
+		"Valid request, with multi setting, over the max": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(poll5In.Copy(), nil)
 				return store
 			},
@@ -770,7 +1228,7 @@ func TestHandleVote(t *testing.T) {
 			VoteIndex:          2,
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{},
-			ExpectedMsg:        "You could't vote for this option, because you don't have any votes left. Use the reset button to reset your votes.",
+			ExpectedMsg:        "You couldn't vote for this option, because you don't have any votes left. You've voted for [Answer 1, Answer 2]. Use the reset button to reset your votes.",
 		},
 		"Valid request with vote": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -784,6 +1242,7 @@ func TestHandleVote(t *testing.T) {
 					"can_manage_poll":           true,
 					"setting_public_add_option": false,
 				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
@@ -807,8 +1266,7 @@ func TestHandleVote(t *testing.T) {
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				pollIn := testutils.GetPoll()
 				pollOut := pollIn.Copy()
-				msg, err := pollOut.UpdateVote("userID1", 0)
-				require.Nil(t, msg)
+				err := pollOut.UpdateVote("userID1", 0)
 				require.Nil(t, err)
 
 				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
@@ -821,6 +1279,55 @@ func TestHandleVote(t *testing.T) {
 			ExpectedResponse:   &model.PostActionIntegrationResponse{},
 			ExpectedMsg:        "Something went wrong. Please try again later.",
 		},
+		"Valid request, poll modified concurrently": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", "has_voted", map[string]interface{}{
+					"voted_answers":             []string{"Answer 1"},
+					"poll_id":                   testutils.GetPollID(),
+					"user_id":                   "userID1",
+					"can_manage_poll":           true,
+					"setting_public_add_option": false,
+				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll1In.Copy(), nil).Twice()
+				store.PollStore.On("Update", poll1In, poll1Out).Return(errConflict).Once()
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollConcurrentIn.Copy(), nil).Once()
+				store.PollStore.On("Update", pollConcurrentIn, pollConcurrentOut).Return(nil).Once()
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPostConcurrent},
+			ExpectedMsg:        "Your vote has been counted.",
+		},
+		"Valid request, poll modified concurrently too often": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				// The poll is read once more to check the permission
+				for i := 0; i <= maxUpdateAttempts; i++ {
+					store.PollStore.On("Get", testutils.GetPollID()).Return(poll1In.Copy(), nil).Once()
+				}
+				store.PollStore.On("Update", poll1In, poll1Out).Return(errConflict).Times(maxUpdateAttempts)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+			ExpectedMsg:        "Something went wrong. Please try again later.",
+		},
 		"Valid request with vote, CanManagePoll fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetPost", "postID1").Return(post, nil)
@@ -835,6 +1342,7 @@ func TestHandleVote(t *testing.T) {
 					"can_manage_poll":           false,
 					"setting_public_add_option": false,
 				}, &model.WebsocketBroadcast{UserId: "userID2"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
@@ -846,7 +1354,27 @@ func TestHandleVote(t *testing.T) {
 			VoteIndex:          1,
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost6},
-			ExpectedMsg:        "Your vote has been counted. You have 1 vote left.",
+			ExpectedMsg:        "Your vote has been counted. You voted for :two: Answer 2 and have 1 vote left.",
+		},
+		"Valid request, voters restricted to channel, user is not a member": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{FirstName: "Jane", LastName: "Doe"}, nil)
+				api.On("GetChannelMember", "channelID1", "userID2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pollIn := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Voters: poll.VotersChannel})
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn, nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", ChannelId: "channelID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+			ExpectedMsg:        "Only members of this channel are eligible to vote in this poll.",
 		},
 		"Invalid index": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -965,6 +1493,7 @@ func TestHandleVote(t *testing.T) {
 			defer store.AssertExpectations(t)
 
 			p := setupTestPlugin(t, api, store)
+			p.configuration.ReopenGracePeriod = test.ReopenGracePeriod
 
 			w := httptest.NewRecorder()
 			url := fmt.Sprintf("/api/v1/polls/%s/vote/%d", testutils.GetPollID(), test.VoteIndex)
@@ -999,7 +1528,191 @@ func TestHandleVote(t *testing.T) {
 	}
 }
 
+func TestMultiVoteLocalizeConfig(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings    poll.Settings
+		UserID      string
+		Removed     bool
+		ExpectedMsg string
+	}{
+		"multi, counted": {
+			Settings:    poll.Settings{MaxVotes: 3},
+			UserID:      "userID1",
+			ExpectedMsg: "Your vote has been counted. You voted for :one: Answer 1 and have 2 votes left.",
+		},
+		"multi, removed with votes left": {
+			Settings:    poll.Settings{MaxVotes: 3},
+			UserID:      "userID1",
+			Removed:     true,
+			ExpectedMsg: "Your vote has been removed. You voted for :one: Answer 1 and have 2 votes left.",
+		},
+		"multi, last vote removed": {
+			Settings:    poll.Settings{MaxVotes: 3},
+			UserID:      "userID5",
+			Removed:     true,
+			ExpectedMsg: "Your vote has been removed. You have 3 votes left.",
+		},
+		"unlimited, counted": {
+			Settings:    poll.Settings{MaxVotes: poll.UnlimitedVotes},
+			UserID:      "userID4",
+			ExpectedMsg: "Your vote has been counted. You voted for :two: Answer 2.",
+		},
+		"unlimited, removed with votes left": {
+			Settings:    poll.Settings{MaxVotes: poll.UnlimitedVotes},
+			UserID:      "userID4",
+			Removed:     true,
+			ExpectedMsg: "Your vote has been removed. You voted for :two: Answer 2.",
+		},
+		"unlimited, last vote removed": {
+			Settings:    poll.Settings{MaxVotes: poll.UnlimitedVotes},
+			UserID:      "userID5",
+			Removed:     true,
+			ExpectedMsg: "Your vote has been removed.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+			pl := testutils.GetPollWithVotesAndSettings(test.Settings)
+
+			lc := multiVoteLocalizeConfig(pl, test.UserID, test.Removed)
+			assert.Equal(t, test.ExpectedMsg, p.LocalizeWithConfig(p.getServerLocalizer(), lc))
+		})
+	}
+	t.Run("translated", func(t *testing.T) {
+		path, err := filepath.Abs("../..")
+		require.Nil(t, err)
+		api := &plugintest.API{}
+		api.On("GetBundlePath").Return(path, nil)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		p.bundle, err = p.initBundle()
+		require.Nil(t, err)
+		pl := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 3})
+
+		lc := multiVoteLocalizeConfig(pl, "userID1", false)
+		assert.Equal(t, "Deine Stimme wurde gezählt. Du hast 2 verbleibende Stimmen.", p.LocalizeWithConfig(i18n.NewLocalizer(p.bundle, "de"), lc))
+	})
+}
+
+func TestErrorMessageFromVoteError(t *testing.T) {
+	userErrMsg := &poll.ErrorMessage{Message: responseVoteNotChannelMember}
+
+	assert.Equal(t, userErrMsg, errorMessageFromVoteError(&poll.VoteError{Err: poll.ErrNotAllowed, ErrorMessage: userErrMsg}))
+	assert.Nil(t, errorMessageFromVoteError(&poll.VoteError{Err: poll.ErrInvalidIndex}))
+	assert.Equal(t, userErrMsg, errorMessageFromVoteError(userErrMsg))
+	assert.Nil(t, errorMessageFromVoteError(poll.NewInternalErrorMessage(commandErrorGeneric, errors.New("failed"))))
+	assert.Nil(t, errorMessageFromVoteError(errors.New("failed")))
+}
+
+func TestHandleVoteMenu(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	pollIn := testutils.GetPoll()
+	pollOut := pollIn.Copy()
+	require.Nil(t, pollOut.UpdateVote("userID1", 2))
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
+
+	post := &model.Post{Id: "postID1", ChannelId: "channelID1"}
+
+	for name, test := range map[string]struct {
+		SetupAPI       func(*plugintest.API) *plugintest.API
+		SetupStore     func(*mockstore.Store) *mockstore.Store
+		SelectedOption interface{}
+		ExpectedMsg    string
+		ExpectedUpdate *model.Post
+	}{
+		"Valid selection": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("PublishWebSocketEvent", "has_voted", mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{UserId: "userID1"}).Return().Maybe()
+				api.On("PublishWebSocketEvent", websocketEventVote, pollOut.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			SelectedOption: "2",
+			ExpectedMsg:    "Your vote has been counted.",
+			ExpectedUpdate: expectedPost,
+		},
+		"Invalid selection": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			SelectedOption: "Answer 3",
+			ExpectedMsg:    "Something went wrong. Please try again later.",
+		},
+		"No selection": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			SelectedOption: nil,
+			ExpectedMsg:    "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetPost", "postID1").Return(post, nil)
+			api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+			api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+			api.On("SendEphemeralPost", "userID1", &model.Post{
+				ChannelId: "channelID1",
+				RootId:    "postID1",
+				UserId:    testutils.GetBotUserID(),
+				Message:   test.ExpectedMsg,
+			}).Return(nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				Context:   map[string]interface{}{"selected_option": test.SelectedOption},
+			}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/vote/menu", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", "userID1")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			require.NotNil(t, response)
+			if test.ExpectedUpdate != nil {
+				require.NotNil(t, response.Update)
+				assert.Equal(t, test.ExpectedUpdate.Attachments(), response.Update.Attachments())
+			} else {
+				assert.Nil(t, response.Update)
+			}
+		})
+	}
+}
+
 func TestHandleResetVotes(t *testing.T) {
+	// Votes and new options update the modification time of a poll
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
 	t.Run("not-authorized", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
@@ -1024,34 +1737,43 @@ func TestHandleResetVotes(t *testing.T) {
 		ID:      testutils.GetPollID(),
 		Creator: "userID1",
 		AnswerOptions: []*poll.AnswerOption{
-			{Answer: "Answer 1", Voter: []string{}},
-			{Answer: "Answer 2", Voter: []string{}},
-			{Answer: "Answer 3", Voter: []string{}},
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+			{Answer: "Answer 3"},
 		},
 		Settings: poll.Settings{MaxVotes: 3},
 	}
 
 	poll2WithVotes := poll.Copy()
-	msg, err := poll2WithVotes.UpdateVote("userID1", 0)
-	require.Nil(t, msg)
+	err := poll2WithVotes.UpdateVote("userID1", 0)
 	require.Nil(t, err)
 
 	poll3WithVotes := poll.Copy()
-	msg, err = poll3WithVotes.UpdateVote("userID1", 0)
-	require.Nil(t, msg)
+	err = poll3WithVotes.UpdateVote("userID1", 0)
 	require.Nil(t, err)
-	msg, err = poll3WithVotes.UpdateVote("userID1", 1)
-	require.Nil(t, msg)
+	err = poll3WithVotes.UpdateVote("userID1", 1)
 	require.Nil(t, err)
-	msg, err = poll3WithVotes.UpdateVote("userID1", 2)
-	require.Nil(t, msg)
+	err = poll3WithVotes.UpdateVote("userID1", 2)
 	require.Nil(t, err)
 
 	poll4WithVotes := poll.Copy()
-	msg, err = poll4WithVotes.UpdateVote("userID1", 0)
-	require.Nil(t, msg)
+	err = poll4WithVotes.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+
+	poll5WithVotes := poll.Copy()
+	poll5WithVotes.Settings.MaxVotes = 1
+	err = poll5WithVotes.UpdateVote("userID1", 1)
 	require.Nil(t, err)
 
+	poll2Reset := poll2WithVotes.Copy()
+	poll2Reset.ResetVotes("userID1")
+	poll3Reset := poll3WithVotes.Copy()
+	poll3Reset.ResetVotes("userID1")
+	poll4Reset := poll4WithVotes.Copy()
+	poll4Reset.ResetVotes("userID1")
+	poll5Reset := poll5WithVotes.Copy()
+	poll5Reset.ResetVotes("userID1")
+
 	for name, test := range map[string]struct {
 		SetupAPI           func(*plugintest.API) *plugintest.API
 		SetupStore         func(*mockstore.Store) *mockstore.Store
@@ -1086,11 +1808,12 @@ func TestHandleResetVotes(t *testing.T) {
 					"voted_answers":             []string{},
 					"setting_public_add_option": false,
 				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(poll2WithVotes.Copy(), nil)
-				store.PollStore.On("Update", poll2WithVotes, poll).Return(nil)
+				store.PollStore.On("Update", poll2WithVotes, poll2Reset).Return(nil)
 				return store
 			},
 			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
@@ -1109,11 +1832,12 @@ func TestHandleResetVotes(t *testing.T) {
 					"voted_answers":             []string{},
 					"setting_public_add_option": false,
 				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(poll3WithVotes.Copy(), nil)
-				store.PollStore.On("Update", poll3WithVotes, poll).Return(nil)
+				store.PollStore.On("Update", poll3WithVotes, poll3Reset).Return(nil)
 				return store
 			},
 			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
@@ -1121,6 +1845,30 @@ func TestHandleResetVotes(t *testing.T) {
 			ExpectedResponse:   &model.PostActionIntegrationResponse{},
 			ExpectedMsg:        "All votes are cleared. Your previous votes were [Answer 1, Answer 2, Answer 3].",
 		},
+		"Valid request, reset vote in single vote mode": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", "has_voted", map[string]interface{}{
+					"can_manage_poll":           true,
+					"poll_id":                   testutils.GetPollID(),
+					"user_id":                   "userID1",
+					"voted_answers":             []string{},
+					"setting_public_add_option": false,
+				}, &model.WebsocketBroadcast{UserId: "userID1"}).Return()
+				api.On("PublishWebSocketEvent", websocketEventVote, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll5WithVotes.Copy(), nil)
+				store.PollStore.On("Update", poll5WithVotes, poll5Reset).Return(nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+			ExpectedMsg:        "All votes are cleared. Your previous votes were [Answer 2].",
+		},
 		"Failed to get poll": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				return api
@@ -1172,7 +1920,7 @@ func TestHandleResetVotes(t *testing.T) {
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(poll4WithVotes.Copy(), nil)
-				store.PollStore.On("Update", poll4WithVotes, poll).Return(&model.AppError{})
+				store.PollStore.On("Update", poll4WithVotes, poll4Reset).Return(&model.AppError{})
 				return store
 			},
 			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
@@ -1538,6 +2286,10 @@ func TestHandleAddOption(t *testing.T) {
 }
 
 func TestHandleAddOptionConfirm(t *testing.T) {
+	// Votes and new options update the modification time of a poll
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
 	t.Run("not-authorized", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
@@ -1635,6 +2387,42 @@ func TestHandleAddOptionConfirm(t *testing.T) {
 			ExpectedResponse:   nil,
 			ExpectedMsg:        "Successfully added the option.",
 		},
+		"Valid request, option suggested by another user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", postID).Return(expectedPost1, nil)
+				api.On("HasPermissionToChannel", "userID2", channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				api.On("GetDirectChannel", userID, testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID"}, nil)
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					attachments := post.Attachments()
+					return post.ChannelId == "dmChannelID" && len(attachments) == 1 && len(attachments[0].Actions) == 2 &&
+						attachments[0].Text == fmt.Sprintf("@user2 suggested the option **New Option** for your poll **Question**: %s/_redirect/pl/%s", testutils.GetSiteURL(), postID)
+				})).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pl := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+				pl.PostID = postID
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pl, nil)
+				store.PollStore.On("Update", mock.Anything, mock.MatchedBy(func(p *poll.Poll) bool {
+					return len(p.AnswerOptions) == 3 && len(p.PendingOptions) == 1 &&
+						p.PendingOptions[0].Answer == "New Option" && p.PendingOptions[0].SuggestedBy == "userID2"
+				})).Return(nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     "userID2",
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					"answerOption": "New Option",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+			ExpectedMsg:        "Your option has been suggested. It will be added once the creator of the poll approves it.",
+		},
 		"Valid request, GetUser fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetPost", postID).Return(expectedPost1, nil)
@@ -1902,136 +2690,1879 @@ func TestHandleAddOptionConfirm(t *testing.T) {
 			}
 		})
 	}
-}
 
-func TestHandleEndPoll(t *testing.T) {
-	t.Run("not-authorized", func(t *testing.T) {
+	t.Run("added option limit reached", func(t *testing.T) {
+		pl := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true})
+		pl.PostID = postID
+		pl.AddedOptions = map[string]int{"userID2": 1}
+
 		api := &plugintest.API{}
 		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		api.On("GetPost", postID).Return(expectedPost1, nil)
+		api.On("HasPermissionToChannel", "userID2", channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+		api.On("GetUser", pl.Creator).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
 		defer api.AssertExpectations(t)
-		p := setupTestPlugin(t, api, &mockstore.Store{})
-		request := &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"}
-
+		store := &mockstore.Store{}
+		store.PollStore.On("Get", testutils.GetPollID()).Return(pl, nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+		p.setConfiguration(&configuration{Trigger: "poll", MaxAddedOptionsPerUser: "1"})
+
+		request := &model.SubmitDialogRequest{
+			UserId:     "userID2",
+			CallbackId: postID,
+			ChannelId:  channelID,
+			Submission: map[string]interface{}{
+				"answerOption": "New Option",
+			},
+		}
 		w := httptest.NewRecorder()
-		url := fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID())
-		body := bytes.NewReader(request.ToJson())
-		r := httptest.NewRequest(http.MethodPost, url, body)
+		url := fmt.Sprintf("/api/v1/polls/%s/option/add", testutils.GetPollID())
+		r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+		r.Header.Add("Mattermost-User-ID", "userID2")
 		p.ServeHTTP(nil, w, r)
+		result := w.Result()
+		defer result.Body.Close()
+
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, &model.SubmitDialogResponse{
+			Errors: map[string]string{"answerOption": "You can only add 1 options to this poll."},
+		}, model.SubmitDialogResponseFromJson(result.Body))
+	})
+	t.Run("added option is counted", func(t *testing.T) {
+		pl := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true})
+		pl.PostID = postID
+		plOut := pl.Copy()
+		require.Nil(t, plOut.AddAnswerOption("New Option"))
+		plOut.AddedOptions = map[string]int{"userID2": 1}
+		post := &model.Post{ChannelId: channelID}
+		model.ParseSlackAttachment(post, plOut.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
 
+		api := &plugintest.API{}
+		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		api.On("GetPost", postID).Return(&model.Post{ChannelId: channelID}, nil)
+		api.On("HasPermissionToChannel", "userID2", channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+		api.On("GetUser", pl.Creator).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{}, nil)
+		api.On("UpdatePost", post).Return(post, nil)
+		api.On("SendEphemeralPost", "userID2", mock.AnythingOfType("*model.Post")).Return(nil)
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.PollStore.On("Get", testutils.GetPollID()).Return(pl.Copy(), nil)
+		store.PollStore.On("Update", pl, plOut).Return(nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+		p.setConfiguration(&configuration{Trigger: "poll", MaxAddedOptionsPerUser: "1"})
+
+		request := &model.SubmitDialogRequest{
+			UserId:     "userID2",
+			CallbackId: postID,
+			ChannelId:  channelID,
+			Submission: map[string]interface{}{
+				"answerOption": "New Option",
+			},
+		}
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("/api/v1/polls/%s/option/add", testutils.GetPollID())
+		r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+		r.Header.Add("Mattermost-User-ID", "userID2")
+		p.ServeHTTP(nil, w, r)
 		result := w.Result()
-		require.NotNil(t, result)
 		defer result.Body.Close()
 
-		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
 	})
+}
 
-	triggerID := model.NewId()
-	dialog := model.OpenDialogRequest{
-		TriggerId: triggerID,
-		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/end/confirm", manifest.Id, testutils.GetPollID()),
-		Dialog: model.Dialog{
-			Title:       "Confirm Poll End",
-			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.Id),
-			CallbackId:  "postID1",
-			SubmitLabel: "End",
-		},
-	}
+func TestHandleApproveOption(t *testing.T) {
+	// Approved options update the modification time of a poll
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
 
-	post := &model.Post{
-		ChannelId: "channelID1",
-	}
+	pollIn := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+	pollIn.PendingOptions = []*poll.PendingOption{{ID: "optionid1", Answer: "New Option", SuggestedBy: "userID2"}}
+	pollOut := pollIn.Copy()
+	_, errMsg := pollOut.ApprovePendingOption("optionid1")
+	require.Nil(t, errMsg)
+	expectedPost := &model.Post{ChannelId: "channelID1"}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
+
+	endedPoll := pollIn.Copy()
+	endedPoll.EndedAt = 1234567899
+	duplicatePoll := pollIn.Copy()
+	duplicatePoll.PendingOptions[0].Answer = "Answer 1"
 
 	for name, test := range map[string]struct {
 		SetupAPI           func(*plugintest.API) *plugintest.API
 		SetupStore         func(*mockstore.Store) *mockstore.Store
-		Request            *model.PostActionIntegrationRequest
+		UserID             string
+		ChannelID          string
 		ExpectedStatusCode int
 		ExpectedMsg        string
+		ExpectedUpdate     string
 	}{
-		"Valid request with no votes": {
+		"Valid request": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetPost", "postID1").Return(post, nil)
-				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
-				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-				api.On("OpenInteractiveDialog", dialog).Return(nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID1"}, nil)
+				api.On("GetDirectChannel", "userID2", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID2"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "dmChannelID2",
+					Message:   fmt.Sprintf("The option **New Option** you suggested for the poll **Question** has been added: %s/_redirect/pl/postID1", testutils.GetSiteURL()),
+				}).Return(&model.Post{}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			UserID:             "userID1",
+			ChannelID:          "dmChannelID1",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedUpdate:     "You approved the option **New Option** for your poll **Question**.",
+		},
+		"Valid request, option can't be added": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(duplicatePoll.Copy(), nil)
+				return store
+			},
+			UserID:             "userID1",
+			ChannelID:          "dmChannelID1",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "Duplicate option: Answer 1",
+		},
+		"Valid request, option already decided": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true}), nil)
+				return store
+			},
+			UserID:             "userID1",
+			ChannelID:          "dmChannelID1",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "This option has already been approved or rejected.",
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+				return store
+			},
+			UserID:             "userID1",
+			ChannelID:          "dmChannelID1",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "The poll has already ended.",
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID2", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID2"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			UserID:             "userID2",
+			ChannelID:          "dmChannelID2",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "Only the creator of a poll and System Admins are allowed to approve or reject options.",
+		},
+		"Invalid request, not triggered in the direct channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID1"}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:             "userID1",
+			ChannelID:          "channelID1",
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		"Invalid request, GetDirectChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:             "userID1",
+			ChannelID:          "dmChannelID1",
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			if test.ExpectedMsg != "" {
+				api.On("SendEphemeralPost", test.UserID, &model.Post{
+					ChannelId: test.ChannelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, ChannelId: test.ChannelID, PostId: "dmPostID"}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/option/pending/optionid1/approve", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", test.UserID)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			if test.ExpectedStatusCode == http.StatusOK {
+				var response model.PostActionIntegrationResponse
+				require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+				if test.ExpectedUpdate != "" {
+					require.NotNil(t, response.Update)
+					assert.Equal(t, test.ExpectedUpdate, response.Update.Message)
+				} else {
+					assert.Nil(t, response.Update)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRejectOption(t *testing.T) {
+	// Rejected options update the modification time of a poll
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	pollIn := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+	pollIn.PendingOptions = []*poll.PendingOption{{ID: "optionid1", Answer: "New Option", SuggestedBy: "userID2"}}
+	pollOut := pollIn.Copy()
+	require.NotNil(t, pollOut.RejectPendingOption("optionid1"))
+
+	for name, test := range map[string]struct {
+		SetupAPI       func(*plugintest.API) *plugintest.API
+		SetupStore     func(*mockstore.Store) *mockstore.Store
+		ExpectedMsg    string
+		ExpectedUpdate string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID2", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID2"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "dmChannelID2",
+					Message:   "The option **New Option** you suggested for the poll **Question** has been rejected.",
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			ExpectedUpdate: "You rejected the option **New Option** for your poll **Question**.",
+		},
+		"Valid request, notifying the user fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID2", testutils.GetBotUserID()).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			ExpectedUpdate: "You rejected the option **New Option** for your poll **Question**.",
+		},
+		"Valid request, option already decided": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollOut.Copy(), nil)
+				return store
+			},
+			ExpectedMsg: "This option has already been approved or rejected.",
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				store.PollStore.On("Update", pollIn, pollOut).Return(errors.New(""))
+				return store
+			},
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return().Maybe()
+			api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID1"}, nil)
+			api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+			if test.ExpectedMsg != "" {
+				api.On("SendEphemeralPost", "userID1", &model.Post{
+					ChannelId: "dmChannelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "dmChannelID1", PostId: "dmPostID"}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/option/pending/optionid1/reject", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", "userID1")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			var response model.PostActionIntegrationResponse
+			require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+			if test.ExpectedUpdate != "" {
+				require.NotNil(t, response.Update)
+				assert.Equal(t, test.ExpectedUpdate, response.Update.Message)
+			} else {
+				assert.Nil(t, response.Update)
+			}
+		})
+	}
+}
+
+func TestHandleAddOther(t *testing.T) {
+	triggerID := model.NewId()
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	dialogRequest := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/option/other", manifest.Id, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:       "Other Answer",
+			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.Id),
+			CallbackId:  "postID1",
+			SubmitLabel: "Vote",
+			Elements: []model.DialogElement{{
+				DisplayName: "Answer",
+				Name:        "answerOption",
+				Type:        "text",
+				SubType:     "text",
+			}},
+		},
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		Poll        *poll.Poll
+		ExpectedMsg string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
+				return api
+			},
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true}),
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(&model.AppError{})
+				return api
+			},
+			Poll:        testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true}),
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+		"Valid request, other answers not allowed": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			Poll:        testutils.GetPollWithVotes(),
+			ExpectedMsg: "This poll doesn't allow other answers.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetPost", "postID1").Return(post, nil)
+			api.On("HasPermissionToChannel", "userID5", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+			api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+			if test.ExpectedMsg != "" {
+				api.On("SendEphemeralPost", "userID5", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll, nil)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: "userID5", ChannelId: "channelID1", PostId: "postID1", TriggerId: triggerID}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/option/other/request", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", "userID5")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+		})
+	}
+}
+
+func TestHandleAddOtherConfirm(t *testing.T) {
+	// Votes and new options update the modification time of a poll
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	pollIn := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+	pollOut := pollIn.Copy()
+	require.Nil(t, pollOut.AddOtherAnswer("userID5", "Something else"))
+	expectedPost := post.Clone()
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		Answer           string
+		ExpectedResponse *model.SubmitDialogResponse
+		ExpectedMsg      string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("PublishWebSocketEvent", "has_voted", mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{UserId: "userID5"}).Return().Maybe()
+				api.On("PublishWebSocketEvent", websocketEventVote, pollOut.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			Answer:      "Something else",
+			ExpectedMsg: "Your answer has been added and your vote has been counted.",
+		},
+		"Valid request, empty answer": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Answer:     " ",
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					"answerOption": "Empty option not allowed",
+				},
+			},
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", pollIn, pollOut).Return(errors.New(""))
+				return store
+			},
+			Answer:      "Something else",
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetPost", "postID1").Return(post.Clone(), nil)
+			api.On("HasPermissionToChannel", "userID5", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+			api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+			if test.ExpectedMsg != "" {
+				api.On("SendEphemeralPost", "userID5", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.SubmitDialogRequest{
+				UserId:     "userID5",
+				CallbackId: "postID1",
+				ChannelId:  "channelID1",
+				Submission: map[string]interface{}{
+					"answerOption": test.Answer,
+				},
+			}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/option/other", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", "userID5")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			assert.Equal(t, test.ExpectedResponse, model.SubmitDialogResponseFromJson(result.Body))
+		})
+	}
+}
+
+func TestHandleComment(t *testing.T) {
+	triggerID := model.NewId()
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	pollWithComment := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true})
+	require.Nil(t, pollWithComment.SetComment("userID2", "Because"))
+	dialogRequest := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/comment", manifest.Id, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:       "Comment",
+			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.Id),
+			CallbackId:  "postID1",
+			SubmitLabel: "Save",
+			Elements: []model.DialogElement{{
+				DisplayName: "Why did you vote like this?",
+				Name:        "comment",
+				Type:        "textarea",
+				Default:     "Because",
+				MaxLength:   poll.MaxCommentLength,
+				Optional:    true,
+				HelpText:    "The comment is listed in the results when the poll ends. Leave it empty to remove your comment.",
+			}},
+		},
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		Poll        *poll.Poll
+		ExpectedMsg string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
+				return api
+			},
+			Poll: pollWithComment,
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(&model.AppError{})
+				return api
+			},
+			Poll:        pollWithComment,
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+		"Valid request, comments not allowed": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			Poll:        testutils.GetPollWithVotes(),
+			ExpectedMsg: "This poll doesn't allow comments.",
+		},
+		"Valid request, user hasn't voted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			Poll: func() *poll.Poll {
+				p := pollWithComment.Copy()
+				p.ResetVotes("userID2")
+				return p
+			}(),
+			ExpectedMsg: "Please vote before you add a comment.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetPost", "postID1").Return(post, nil)
+			api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+			api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+			if test.ExpectedMsg != "" {
+				api.On("SendEphemeralPost", "userID2", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll, nil)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: "userID2", ChannelId: "channelID1", PostId: "postID1", TriggerId: triggerID}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/comment/request", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", "userID2")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+		})
+	}
+}
+
+func TestHandleCommentConfirm(t *testing.T) {
+	// Comments update the modification time of a poll
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	pollIn := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true})
+	pollOut := pollIn.Copy()
+	require.Nil(t, pollOut.SetComment("userID2", "Because"))
+
+	for name, test := range map[string]struct {
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		Submission       map[string]interface{}
+		ExpectedResponse *model.SubmitDialogResponse
+		ExpectedMsg      string
+	}{
+		"Valid request": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			Submission:  map[string]interface{}{"comment": "  Because\n"},
+			ExpectedMsg: "Your comment has been saved.",
+		},
+		"Valid request, no comment to remove": {
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission:  map[string]interface{}{},
+			ExpectedMsg: "Your comment has been removed.",
+		},
+		"Valid request, comment too long": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission: map[string]interface{}{"comment": strings.Repeat("a", poll.MaxCommentLength+1)},
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					"comment": "Comments can have at most 300 characters.",
+				},
+			},
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", pollIn, pollOut).Return(errors.New(""))
+				return store
+			},
+			Submission:  map[string]interface{}{"comment": "Because"},
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetPost", "postID1").Return(post.Clone(), nil)
+			api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+			api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+			if test.ExpectedMsg != "" {
+				api.On("SendEphemeralPost", "userID2", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.SubmitDialogRequest{
+				UserId:     "userID2",
+				CallbackId: "postID1",
+				ChannelId:  "channelID1",
+				Submission: test.Submission,
+			}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/comment", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", "userID2")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			assert.Equal(t, test.ExpectedResponse, model.SubmitDialogResponseFromJson(result.Body))
+		})
+	}
+}
+
+func TestHandleVoteRequiresComment(t *testing.T) {
+	triggerID := model.NewId()
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	pl := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{2}})
+	dialogRequest := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/vote/1/comment", manifest.Id, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:       "Comment",
+			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.Id),
+			CallbackId:  "postID1",
+			SubmitLabel: "Vote",
+			Elements: []model.DialogElement{{
+				DisplayName: `Why do you vote for "Answer 2"?`,
+				Name:        "comment",
+				Type:        "textarea",
+				MaxLength:   poll.MaxCommentLength,
+				HelpText:    "A vote for this option needs a comment. It is listed in the results when the poll ends.",
+			}},
+		},
+	}
+
+	api := &plugintest.API{}
+	api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+	api.On("GetPost", "postID1").Return(post, nil)
+	api.On("HasPermissionToChannel", "userID5", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+	api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+	api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+	api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
+	defer api.AssertExpectations(t)
+	s := &mockstore.Store{}
+	s.PollStore.On("Get", testutils.GetPollID()).Return(pl.Copy(), nil)
+	defer s.AssertExpectations(t)
+	p := setupTestPlugin(t, api, s)
+
+	request := &model.PostActionIntegrationRequest{UserId: "userID5", ChannelId: "channelID1", PostId: "postID1", TriggerId: triggerID}
+	w := httptest.NewRecorder()
+	url := fmt.Sprintf("/api/v1/polls/%s/vote/1", testutils.GetPollID())
+	r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+	r.Header.Add("Mattermost-User-ID", "userID5")
+	p.ServeHTTP(nil, w, r)
+
+	result := w.Result()
+	require.NotNil(t, result)
+	defer result.Body.Close()
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	s.PollStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestHandleVoteWithComment(t *testing.T) {
+	// Votes update the modification time of a poll
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	post := &model.Post{
+		Id:        "postID1",
+		ChannelId: "channelID1",
+	}
+	pollIn := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{2}})
+	pollOut := pollIn.Copy()
+	require.Nil(t, pollOut.VoteWithComment("userID5", 1, "Too expensive"))
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		Submission       map[string]interface{}
+		ExpectedResponse *model.SubmitDialogResponse
+		ExpectedMsg      string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.Type == MatterpollPostType
+				})).Return(nil, nil)
+				api.On("PublishWebSocketEvent", "has_voted", mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{UserId: "userID5"}).Return().Maybe()
+				api.On("PublishWebSocketEvent", websocketEventVote, pollOut.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			Submission:  map[string]interface{}{"comment": "Too expensive"},
+			ExpectedMsg: "Your vote has been counted.",
+		},
+		"Valid request, empty comment": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission: map[string]interface{}{"comment": " "},
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					"comment": `Your vote for "Answer 2" needs a comment that explains it.`,
+				},
+			},
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", pollIn, pollOut).Return(errors.New(""))
+				return store
+			},
+			Submission:  map[string]interface{}{"comment": "Too expensive"},
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+		"Invalid request, comment missing": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission:  map[string]interface{}{},
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetPost", "postID1").Return(post.Clone(), nil)
+			api.On("HasPermissionToChannel", "userID5", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+			api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+			if test.ExpectedMsg != "" {
+				api.On("SendEphemeralPost", "userID5", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					RootId:    "postID1",
+					Message:   test.ExpectedMsg,
+				}).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.SubmitDialogRequest{
+				UserId:     "userID5",
+				CallbackId: "postID1",
+				ChannelId:  "channelID1",
+				Submission: test.Submission,
+			}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/vote/1/comment", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", "userID5")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			assert.Equal(t, test.ExpectedResponse, model.SubmitDialogResponseFromJson(result.Body))
+		})
+	}
+}
+
+func TestHandleEditPoll(t *testing.T) {
+	triggerID := model.NewId()
+	pollIn := testutils.GetPoll()
+	pollIn.AnswerOptions[1].Deleted = true
+	dialog := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/edit/confirm", manifest.Id, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:       "Edit Poll",
+			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.Id),
+			CallbackId:  "postID1",
+			SubmitLabel: "Save",
+			Elements: []model.DialogElement{{
+				DisplayName: "Question",
+				Name:        "question",
+				Type:        "text",
+				SubType:     "text",
+				Default:     "Question",
+			}, {
+				DisplayName: "Option 1",
+				Name:        "option1",
+				Type:        "text",
+				SubType:     "text",
+				Default:     "Answer 1",
+				HelpText:    "Leave empty to delete the option. Its votes are kept.",
+				Optional:    true,
+			}, {
+				DisplayName: "Option 3",
+				Name:        "option3",
+				Type:        "text",
+				SubType:     "text",
+				Default:     "Answer 3",
+				HelpText:    "Leave empty to delete the option. Its votes are kept.",
+				Optional:    true,
+			}},
+			State: "3",
+		},
+	}
+
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedMsg        string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("OpenInteractiveDialog", dialog).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "",
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID2",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "Only the creator of a poll and System Admins are allowed to edit it.",
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("OpenInteractiveDialog", dialog).Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "Something went wrong. Please try again later.",
+		},
+		"Invalid request, Store.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			if test.ExpectedMsg != "" {
+				ephemeralPost := &model.Post{
+					ChannelId: test.Request.ChannelId,
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}
+				api.On("SendEphemeralPost", test.Request.UserId, ephemeralPost).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/edit", testutils.GetPollID())
+			body := bytes.NewReader(test.Request.ToJson())
+			r := httptest.NewRequest(http.MethodPost, url, body)
+			r.Header.Add("Mattermost-User-ID", test.Request.UserId)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+		})
+	}
+}
+
+func TestHandleEditPollConfirm(t *testing.T) {
+	// Editing a poll updates its modification time
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	userID := testutils.GetPollWithVotes().Creator
+	channelID := model.NewId()
+	postID := model.NewId()
+
+	pollIn := testutils.GetPollWithVotes()
+	pollIn.PostID = postID
+	pollOut := pollIn.Copy()
+	errMsg := pollOut.Update("New Question", []string{"First", "", "Answer 3"})
+	require.Nil(t, errMsg)
+	expectedPost := &model.Post{
+		ChannelId: channelID,
+	}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
+
+	submission := map[string]interface{}{
+		"question": "New Question",
+		"option1":  "First",
+		"option2":  "",
+		"option3":  "Answer 3",
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.SubmitDialogRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.SubmitDialogResponse
+		ExpectedMsg        string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", postID).Return(expectedPost, nil)
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				State:      "3",
+				Submission: submission,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+			ExpectedMsg:        "Successfully updated the poll.",
+		},
+		"Valid request, option added after the dialog has been opened": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", postID).Return(expectedPost, nil)
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(expectedPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				in := pollIn.Copy()
+				require.Nil(t, in.AddAnswerOption("Answer 4"))
+				out := in.Copy()
+				require.Nil(t, out.Update("New Question", []string{"First", "", "Answer 3", "Answer 4"}))
+				store.PollStore.On("Get", testutils.GetPollID()).Return(in.Copy(), nil)
+				store.PollStore.On("Update", in, out).Return(nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				State:      "3",
+				Submission: submission,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+			ExpectedMsg:        "Successfully updated the poll.",
+		},
+		"Valid request, too few options": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", postID).Return(expectedPost, nil)
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				State:      "3",
+				Submission: map[string]interface{}{
+					"question": "New Question",
+					"option1":  "First",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Error: "A poll needs at least 2 options, but you specified 1.",
+			},
+		},
+		"Valid request, UpdatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", postID).Return(expectedPost, nil)
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", expectedPost).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				store.PollStore.On("Update", pollIn, pollOut).Return(nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				State:      "3",
+				Submission: submission,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+			ExpectedMsg:        "Something went wrong. Please try again later.",
+		},
+		"Invalid request, invalid state": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", postID).Return(expectedPost, nil)
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: submission,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+			ExpectedMsg:        "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			if test.ExpectedMsg != "" {
+				ephemeralPost := &model.Post{
+					ChannelId: test.Request.ChannelId,
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}
+				api.On("SendEphemeralPost", test.Request.UserId, ephemeralPost).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/edit/confirm", testutils.GetPollID())
+			body := bytes.NewReader(test.Request.ToJson())
+			r := httptest.NewRequest(http.MethodPost, url, body)
+			r.Header.Add("Mattermost-User-ID", test.Request.UserId)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			response := model.SubmitDialogResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleEndPoll(t *testing.T) {
+	t.Run("not-authorized", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		request := &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"}
+
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID())
+		body := bytes.NewReader(request.ToJson())
+		r := httptest.NewRequest(http.MethodPost, url, body)
+		p.ServeHTTP(nil, w, r)
+
+		result := w.Result()
+		require.NotNil(t, result)
+		defer result.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+	})
+
+	triggerID := model.NewId()
+	dialog := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("/plugins/%s/api/v1/polls/%s/end/confirm", manifest.Id, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:       "Confirm Poll End",
+			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.Id),
+			CallbackId:  "postID1",
+			SubmitLabel: "End",
+		},
+	}
+
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedMsg        string
+	}{
+		"Valid request with no votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("OpenInteractiveDialog", dialog).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "",
+		},
+		"Valid request, poll without postID": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("OpenInteractiveDialog", dialog).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithoutPostID(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "",
+		},
+		"Valid request with no votes, issuer is system admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID2").Return(&model.User{
+					Username: "user2",
+					Roles:    model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID,
+				}, nil)
+				api.On("OpenInteractiveDialog", dialog).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID2",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "",
+		},
+		"Valid request, GetUser fails for issuer": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID2",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "Something went wrong. Please try again later.",
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID2",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "Only the creator of a poll and System Admins are allowed to end it.",
+		},
+		"Valid request, quorum not reached with hold-for-quorum": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pollIn := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quorum: 2, HoldForQuorum: true})
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn, nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "This poll can't be ended before its quorum is reached.",
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("OpenInteractiveDialog", dialog).Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedMsg:        "Something went wrong. Please try again later.",
+		},
+		"Invalid request, Store.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+		"Invalid request, GetPost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+		"Invalid request, post with invalid channelID": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				p := post.Clone()
+				p.ChannelId = "channelID2"
+				api.On("GetPost", "postID1").Return(p, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		"Invalid request, without permission to read channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(false)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				PostId:    "postID1",
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedMsg:        "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			if test.ExpectedMsg != "" {
+				ephemeralPost := &model.Post{
+					ChannelId: test.Request.ChannelId,
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedMsg,
+				}
+				api.On("SendEphemeralPost", test.Request.UserId, ephemeralPost).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID())
+			body := bytes.NewReader(test.Request.ToJson())
+			r := httptest.NewRequest(http.MethodPost, url, body)
+			if test.Request != nil {
+				r.Header.Add("Mattermost-User-ID", test.Request.UserId)
+			} else {
+				r.Header.Add("Mattermost-User-ID", model.NewId())
+			}
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			if result.StatusCode == http.StatusOK {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+				assert.Equal(response, &model.PostActionIntegrationResponse{})
+			} else {
+				assert.Nil(response)
+			}
+		})
+	}
+}
+
+func TestHandleExportResults(t *testing.T) {
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	csv, err := testutils.GetPollWithVotes().ResultsCSV()
+	require.NoError(t, err)
+	dmPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: "dmChannelID",
+		Message:   "The results of the poll **Question** are attached.",
+		FileIds:   []string{"fileID1"},
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		SetupStore  func(*mockstore.Store) *mockstore.Store
+		UserID      string
+		ExpectedMsg string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID"}, nil)
+				api.On("UploadFile", csv, "dmChannelID", "poll-"+testutils.GetPollID()+".csv").Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", dmPost).Return(dmPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID1",
-				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
+			UserID:      "userID1",
+			ExpectedMsg: "The results have been sent to you as a direct message.",
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
 			},
-			ExpectedStatusCode: http.StatusOK,
-			ExpectedMsg:        "",
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			UserID:      "userID2",
+			ExpectedMsg: "Only the creator of a poll and System Admins are allowed to export the results.",
 		},
-		"Valid request, poll without postID": {
+		"Valid request, UploadFile fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
 				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-				api.On("OpenInteractiveDialog", dialog).Return(nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID"}, nil)
+				api.On("UploadFile", csv, "dmChannelID", "poll-"+testutils.GetPollID()+".csv").Return(nil, &model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithoutPostID(), nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID1",
+			UserID:      "userID1",
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("SendEphemeralPost", test.UserID, &model.Post{
 				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
+				UserId:    testutils.GetBotUserID(),
+				Message:   test.ExpectedMsg,
+			}).Return(nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, ChannelId: "channelID1", PostId: "postID1"}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/export", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", test.UserID)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+		})
+	}
+}
+
+func TestHandleRemindNonVoters(t *testing.T) {
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	channelUsers := []*model.User{
+		{Id: "userID1", Username: "user1"},
+		{Id: "userID5", Username: "user5"},
+		{Id: "userID6", Username: "user6"},
+		{Id: "botID", Username: "bot", IsBot: true},
+		{Id: "userID7", Username: "user7", DeleteAt: 1},
+	}
+	reminder := func(channelID string) *model.Post {
+		return &model.Post{
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: channelID,
+			Message:   fmt.Sprintf("You haven't voted in the poll **Question** yet: %s/_redirect/pl/postID1", testutils.GetSiteURL()),
+		}
+	}
+	setupReminders := func(api *plugintest.API) {
+		api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(channelUsers, nil)
+		api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+		api.On("GetUser", "userID6").Return(&model.User{Username: "user6"}, nil)
+		api.On("GetDirectChannel", "userID5", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID5"}, nil)
+		api.On("GetDirectChannel", "userID6", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID6"}, nil)
+		api.On("CreatePost", reminder("dmChannelID5")).Return(reminder("dmChannelID5"), nil)
+		api.On("CreatePost", reminder("dmChannelID6")).Return(reminder("dmChannelID6"), nil)
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		Poll        *poll.Poll
+		UserID      string
+		ExpectedMsg string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				setupReminders(api)
+				return api
 			},
-			ExpectedStatusCode: http.StatusOK,
-			ExpectedMsg:        "",
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID1",
+			ExpectedMsg: "A reminder has been sent to @user5, @user6.",
 		},
-		"Valid request with no votes, issuer is system admin": {
+		"Valid request, anonymous poll": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetPost", "postID1").Return(post, nil)
-				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
-				api.On("GetUser", "userID2").Return(&model.User{
-					Username: "user2",
-					Roles:    model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID,
-				}, nil)
-				api.On("OpenInteractiveDialog", dialog).Return(nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				setupReminders(api)
 				return api
 			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				return store
+			Poll:        testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Anonymous: true}),
+			UserID:      "userID1",
+			ExpectedMsg: "A reminder has been sent to 2 users who haven't voted yet.",
+		},
+		"Valid request, secret poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				setupReminders(api)
+				return api
 			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID2",
-				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
+			Poll:        testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Secret: true}),
+			UserID:      "userID1",
+			ExpectedMsg: "A reminder has been sent to everyone who hasn't voted yet.",
+		},
+		"Valid request, everybody has voted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(channelUsers[:1], nil)
+				return api
 			},
-			ExpectedStatusCode: http.StatusOK,
-			ExpectedMsg:        "",
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID1",
+			ExpectedMsg: "Everybody in this channel has already voted.",
 		},
-		"Valid request, GetUser fails for issuer": {
+		"Valid request, Invalid permission": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetPost", "postID1").Return(post, nil)
 				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
-				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
 				return api
 			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				return store
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID2",
+			ExpectedMsg: "Only the creator of a poll and System Admins are allowed to remind users who haven't voted.",
+		},
+		"Valid request, GetUsersInChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(nil, &model.AppError{})
+				return api
 			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID2",
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID1",
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("SendEphemeralPost", test.UserID, &model.Post{
 				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
+				UserId:    testutils.GetBotUserID(),
+				Message:   test.ExpectedMsg,
+			}).Return(nil)
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll, nil)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, ChannelId: "channelID1", PostId: "postID1"}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/remind", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", test.UserID)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+		})
+	}
+}
+
+func TestHandleShowNonVoters(t *testing.T) {
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	channelUsers := []*model.User{
+		{Id: "userID1", Username: "user1"},
+		{Id: "userID5", Username: "user5"},
+		{Id: "userID6", Username: "user6"},
+		{Id: "botID", Username: "bot", IsBot: true},
+		{Id: "userID7", Username: "user7", DeleteAt: 1},
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		Poll        *poll.Poll
+		UserID      string
+		ExpectedMsg string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(channelUsers, nil)
+				return api
 			},
-			ExpectedStatusCode: http.StatusOK,
-			ExpectedMsg:        "Something went wrong. Please try again later.",
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID1",
+			ExpectedMsg: "1 of 3 members of this channel have voted (33%). 2 users haven't voted yet: @user5, @user6",
+		},
+		"Valid request, one user hasn't voted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(channelUsers[:2], nil)
+				return api
+			},
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID1",
+			ExpectedMsg: "1 of 2 members of this channel have voted (50%). 1 user hasn't voted yet: @user5",
+		},
+		"Valid request, everybody has voted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(channelUsers[:1], nil)
+				return api
+			},
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID1",
+			ExpectedMsg: "Everybody in this channel has voted (1 of 1, 100%).",
+		},
+		"Valid request, anonymous poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			Poll:        testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Anonymous: true}),
+			UserID:      "userID1",
+			ExpectedMsg: "Who hasn't voted can't be shown for anonymous and secret polls.",
+		},
+		"Valid request, secret poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			Poll:        testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Secret: true}),
+			UserID:      "userID1",
+			ExpectedMsg: "Who hasn't voted can't be shown for anonymous and secret polls.",
 		},
 		"Valid request, Invalid permission": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -2040,162 +4571,154 @@ func TestHandleEndPoll(t *testing.T) {
 				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
 				return api
 			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				return store
-			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID2",
-				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
-			},
-			ExpectedStatusCode: http.StatusOK,
-			ExpectedMsg:        "Only the creator of a poll and System Admins are allowed to end it.",
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID2",
+			ExpectedMsg: "Only the creator of a poll and System Admins are allowed to see who hasn't voted.",
+		},
+		"Valid request, GetUsersInChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(nil, &model.AppError{})
+				return api
+			},
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID1",
+			ExpectedMsg: "Something went wrong. Please try again later.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("SendEphemeralPost", test.UserID, &model.Post{
+				ChannelId: "channelID1",
+				UserId:    testutils.GetBotUserID(),
+				Message:   test.ExpectedMsg,
+			}).Return(nil)
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll, nil)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, ChannelId: "channelID1", PostId: "postID1"}
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/polls/%s/nonvoters", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", test.UserID)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+		})
+	}
+}
+
+func TestHandleShowVoters(t *testing.T) {
+	post := &model.Post{
+		ChannelId: "channelID1",
+	}
+	semiAnonymous := poll.Settings{MaxVotes: 1, Anonymous: true, SemiAnonymous: true}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		Poll        *poll.Poll
+		UserID      string
+		ExpectedMsg string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				return api
+			},
+			Poll:   testutils.GetPollWithVotesAndSettings(semiAnonymous),
+			UserID: "userID1",
+			ExpectedMsg: "Only you can see who voted for what:\n" +
+				"#### Question\n" +
+				"- **Answer 1**: 3 votes: @user1, @user2 and @user3\n" +
+				"- **Answer 2**: 1 vote: @user4\n" +
+				"- **Answer 3**: 0 votes",
 		},
-		"Valid request, OpenInteractiveDialog fails": {
+		"Valid request, secret poll": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetPost", "postID1").Return(post, nil)
 				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-				api.On("OpenInteractiveDialog", dialog).Return(&model.AppError{})
 				return api
 			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				return store
-			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID1",
-				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
-			},
-			ExpectedStatusCode: http.StatusOK,
-			ExpectedMsg:        "Something went wrong. Please try again later.",
-		},
-		"Invalid request, Store.Get fails": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
-				return store
-			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID1",
-				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
-			},
-			ExpectedStatusCode: http.StatusInternalServerError,
+			Poll:        testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Anonymous: true, SemiAnonymous: true, Secret: true}),
+			UserID:      "userID1",
+			ExpectedMsg: "Who voted for what can't be shown before a secret poll ends.",
 		},
-		"Invalid request, GetPost fails": {
+		"Valid request, anonymous poll": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetPost", "postID1").Return(post, &model.AppError{})
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
 				return api
 			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				return store
-			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID1",
-				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
-			},
-			ExpectedStatusCode: http.StatusInternalServerError,
+			Poll:        testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Anonymous: true}),
+			UserID:      "userID1",
+			ExpectedMsg: "Only the creator of a semi-anonymous poll is allowed to see who voted for what.",
 		},
-		"Invalid request, post with invalid channelID": {
+		"Valid request, not the creator": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				p := post.Clone()
-				p.ChannelId = "channelID2"
-				api.On("GetPost", "postID1").Return(p, nil)
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID2", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
 				return api
 			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				return store
-			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID1",
-				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
-			},
-			ExpectedStatusCode: http.StatusUnauthorized,
+			Poll:        testutils.GetPollWithVotesAndSettings(semiAnonymous),
+			UserID:      "userID2",
+			ExpectedMsg: "Only the creator of a semi-anonymous poll is allowed to see who voted for what.",
 		},
-		"Invalid request, without permission to read channel": {
+		"Valid request, GetUser fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetPost", "postID1").Return(post, nil)
-				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(false)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
 				return api
 			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				return store
-			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    "userID1",
-				ChannelId: "channelID1",
-				PostId:    "postID1",
-				TriggerId: triggerID,
-			},
-			ExpectedStatusCode: http.StatusUnauthorized,
-		},
-		"Invalid request": {
-			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
-			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
-			Request:            nil,
-			ExpectedStatusCode: http.StatusBadRequest,
-			ExpectedMsg:        "",
+			Poll:        testutils.GetPollWithVotesAndSettings(semiAnonymous),
+			UserID:      "userID1",
+			ExpectedMsg: "Something went wrong. Please try again later.",
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
-
 			api := test.SetupAPI(&plugintest.API{})
 			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
 			api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return().Maybe()
-			if test.ExpectedMsg != "" {
-				ephemeralPost := &model.Post{
-					ChannelId: test.Request.ChannelId,
-					UserId:    testutils.GetBotUserID(),
-					Message:   test.ExpectedMsg,
-				}
-				api.On("SendEphemeralPost", test.Request.UserId, ephemeralPost).Return(nil)
-			}
+			api.On("SendEphemeralPost", test.UserID, &model.Post{
+				ChannelId: "channelID1",
+				UserId:    testutils.GetBotUserID(),
+				Message:   test.ExpectedMsg,
+			}).Return(nil)
 			defer api.AssertExpectations(t)
-
-			store := test.SetupStore(&mockstore.Store{})
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll, nil)
 			defer store.AssertExpectations(t)
-
 			p := setupTestPlugin(t, api, store)
 
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, ChannelId: "channelID1", PostId: "postID1"}
 			w := httptest.NewRecorder()
-			url := fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID())
-			body := bytes.NewReader(test.Request.ToJson())
-			r := httptest.NewRequest(http.MethodPost, url, body)
-			if test.Request != nil {
-				r.Header.Add("Mattermost-User-ID", test.Request.UserId)
-			} else {
-				r.Header.Add("Mattermost-User-ID", model.NewId())
-			}
+			url := fmt.Sprintf("/api/v1/polls/%s/voters", testutils.GetPollID())
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", test.UserID)
 			p.ServeHTTP(nil, w, r)
 
 			result := w.Result()
 			require.NotNil(t, result)
 			defer result.Body.Close()
-			response := model.PostActionIntegrationResponseFromJson(result.Body)
-
-			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
-			if result.StatusCode == http.StatusOK {
-				assert.Equal(http.Header{
-					"Content-Type": []string{"application/json"},
-				}, result.Header)
-				assert.Equal(response, &model.PostActionIntegrationResponse{})
-			} else {
-				assert.Nil(response)
-			}
+			assert.Equal(t, http.StatusOK, result.StatusCode)
 		})
 	}
 }
@@ -2250,6 +4773,7 @@ func TestHandleEndPollConfirm(t *testing.T) {
 		ExpectedStatusCode int
 		ExpectedResponse   *model.SubmitDialogResponse
 		ExpectedMsg        string
+		ReopenGracePeriod  string
 	}{
 		"Valid request with votes": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -2261,11 +4785,13 @@ func TestHandleEndPollConfirm(t *testing.T) {
 				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
 				api.On("UpdatePost", expectedPost).Return(nil, nil)
 				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				store.PollStore.On("Delete", testutils.GetPollWithVotes()).Return(nil)
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
 				return store
 			},
 			Request:            &model.SubmitDialogRequest{UserId: "userID1", ChannelId: "channelID1", CallbackId: "postID1", TeamId: "teamID1"},
@@ -2273,6 +4799,33 @@ func TestHandleEndPollConfirm(t *testing.T) {
 			ExpectedResponse:   nil,
 			ExpectedMsg:        "",
 		},
+		"Valid request, poll is kept for re-opening": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(post, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				api.On("UpdatePost", expectedPost).Return(nil, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollWithVotes(), mock.MatchedBy(func(p *poll.Poll) bool {
+					return p.HasEnded()
+				})).Return(nil)
+				return store
+			},
+			Request:            &model.SubmitDialogRequest{UserId: "userID1", ChannelId: "channelID1", CallbackId: "postID1", TeamId: "teamID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+			ExpectedMsg:        fmt.Sprintf("You can re-open the poll within 10 minutes using `/poll reopen %s`.", testutils.GetPollID()),
+			ReopenGracePeriod:  "10",
+		},
 		"Valid request, poll without postID": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
@@ -2282,6 +4835,7 @@ func TestHandleEndPollConfirm(t *testing.T) {
 				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
 				api.On("UpdatePost", expectedPost).Return(nil, nil)
 				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
@@ -2289,6 +4843,7 @@ func TestHandleEndPollConfirm(t *testing.T) {
 				poll.PostID = ""
 				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
 				store.PollStore.On("Delete", poll).Return(nil)
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
 				return store
 			},
 			Request:            &model.SubmitDialogRequest{UserId: "userID1", ChannelId: "channelID1", CallbackId: "postID1", TeamId: "teamID1"},
@@ -2447,6 +5002,7 @@ func TestHandleEndPollConfirm(t *testing.T) {
 			defer store.AssertExpectations(t)
 
 			p := setupTestPlugin(t, api, store)
+			p.configuration.ReopenGracePeriod = test.ReopenGracePeriod
 
 			w := httptest.NewRecorder()
 			url := fmt.Sprintf("/api/v1/polls/%s/end/confirm", testutils.GetPollID())
@@ -2476,10 +5032,37 @@ func TestHandleEndPollConfirm(t *testing.T) {
 }
 
 func TestPostEndPollAnnouncement(t *testing.T) {
+	meetingPoll := func(voters []string) *poll.Poll {
+		p, errMsg := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "2021-10-02T15:00"}, poll.Settings{Invite: true}, poll.Limits{})
+		require.Nil(t, errMsg)
+		p.ID = testutils.GetPollID()
+		p.SetVoters(1, voters...)
+		return p
+	}
+
+	results := &model.Post{}
+	model.ParseSlackAttachment(results, []*model.SlackAttachment{{Title: "Question", Text: "This poll has ended. The results are:"}})
+	threadResultsPost := func() *model.Post {
+		post := &model.Post{
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: "channelID1",
+			RootId:    "postID1",
+			Message: "The poll **Question** has ended and the original post has been updated. " +
+				"You can jump to it by pressing [here](https://example.org/_redirect/pl/postID1).",
+			Type:     model.POST_DEFAULT,
+			IsPinned: true,
+		}
+		model.ParseSlackAttachment(post, results.Attachments())
+		return post
+	}
+
 	for name, test := range map[string]struct {
-		SetupAPI func(*plugintest.API) *plugintest.API
+		SetupAPI      func(*plugintest.API) *plugintest.API
+		Poll          *poll.Poll
+		ThreadResults bool
 	}{
 		"Valid request": {
+			Poll: testutils.GetPoll(),
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("CreatePost", &model.Post{
 					UserId:    testutils.GetBotUserID(),
@@ -2492,19 +5075,89 @@ func TestPostEndPollAnnouncement(t *testing.T) {
 				return api
 			},
 		},
+		"Thread results setting": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ThreadResults: true}),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("CreatePost", threadResultsPost()).Return(nil, nil)
+				return api
+			},
+		},
+		"Thread results configuration": {
+			Poll: testutils.GetPoll(),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("CreatePost", threadResultsPost()).Return(nil, nil)
+				return api
+			},
+			ThreadResults: true,
+		},
 		"Valid request, CreatePost fails": {
+			Poll: testutils.GetPoll(),
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
 				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
 				return api
 			},
 		},
+		"Meeting poll with invite": {
+			Poll: meetingPoll([]string{"userID1"}),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				p := meetingPoll([]string{"userID1"})
+				api.On("UploadFile", p.MeetingInvite(1), "channelID1", "meeting-"+testutils.GetPollID()+".ics").Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.RootId == "postID1" && len(post.FileIds) == 1 && post.FileIds[0] == "fileID1"
+				})).Return(nil, nil)
+				return api
+			},
+		},
+		"Poll with chart": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Chart: poll.ChartBar}),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("UploadFile", mock.AnythingOfType("[]uint8"), "channelID1", "poll-"+testutils.GetPollID()+".png").Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.RootId == "postID1" && len(post.FileIds) == 1 && post.FileIds[0] == "fileID1"
+				})).Return(nil, nil)
+				return api
+			},
+		},
+		"Poll with chart, UploadFile fails": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Chart: poll.ChartPie}),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("UploadFile", mock.AnythingOfType("[]uint8"), "channelID1", mock.AnythingOfType("string")).Return(nil, &model.AppError{})
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return len(post.FileIds) == 0
+				})).Return(nil, nil)
+				return api
+			},
+		},
+		"Meeting poll with invite, no votes": {
+			Poll: meetingPoll([]string{}),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.RootId == "postID1" && len(post.FileIds) == 0
+				})).Return(nil, nil)
+				return api
+			},
+		},
+		"Meeting poll with invite, UploadFile fails": {
+			Poll: meetingPoll([]string{"userID1"}),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("UploadFile", mock.Anything, "channelID1", mock.AnythingOfType("string")).Return(nil, &model.AppError{})
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return len(post.FileIds) == 0
+				})).Return(nil, nil)
+				return api
+			},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
 
 			p := setupTestPlugin(t, api, &mockstore.Store{})
-			p.postEndPollAnnouncement("channelID1", "postID1", "Question")
+			p.configuration.ThreadResults = test.ThreadResults
+			p.postEndPollAnnouncement("channelID1", "postID1", test.Poll, results)
 		})
 	}
 }
@@ -2845,6 +5498,7 @@ func TestHandleDeletePollConfirm(t *testing.T) {
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
 				store.PollStore.On("Delete", testutils.GetPoll()).Return(nil)
+				store.ArchiveStore.On("Delete", "channelID1", testutils.GetPollID()).Return(nil)
 				return store
 			},
 			Request: &model.SubmitDialogRequest{
@@ -2867,6 +5521,7 @@ func TestHandleDeletePollConfirm(t *testing.T) {
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithoutPostID(), nil)
 				store.PollStore.On("Delete", testutils.GetPollWithoutPostID()).Return(nil)
+				store.ArchiveStore.On("Delete", "channelID1", testutils.GetPollID()).Return(nil)
 				return store
 			},
 			Request: &model.SubmitDialogRequest{
@@ -3047,6 +5702,108 @@ func TestHandleDeletePollConfirm(t *testing.T) {
 	}
 }
 
+func TestHandlePollSummary(t *testing.T) {
+	pollWithChannel := func(settings poll.Settings) *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(settings)
+		p.ChannelID = "channelID1"
+		return p
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", "userID5", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollWithChannel(poll.Settings{MaxVotes: 1, Progress: true}), nil)
+				return store
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody: `{"poll_id":"` + testutils.GetPollID() + `","question":"Question","options":[` +
+				`{"answer":"Answer 1","votes":3,"voter_ids":["userID1","userID2","userID3"]},` +
+				`{"answer":"Answer 2","votes":1,"voter_ids":["userID4"]},` +
+				`{"answer":"Answer 3","votes":0}],` +
+				`"votes_hidden":false,"voters":4,"status":"running","created_at":1234567890}` + "\n",
+		},
+		"Valid request, anonymous poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", "userID5", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollWithChannel(poll.Settings{MaxVotes: 1, Progress: true, Anonymous: true}), nil)
+				return store
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody: `{"poll_id":"` + testutils.GetPollID() + `","question":"Question","options":[` +
+				`{"answer":"Answer 1","votes":3},{"answer":"Answer 2","votes":1},{"answer":"Answer 3","votes":0}],` +
+				`"votes_hidden":false,"voters":4,"status":"running","created_at":1234567890}` + "\n",
+		},
+		"Invalid request, channel can't be read": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", "userID5", "channelID1", model.PERMISSION_READ_CHANNEL).Return(false)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollWithChannel(poll.Settings{MaxVotes: 1}), nil)
+				return store
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedBody:       "404 page not found\n",
+		},
+		"Invalid request, poll not found": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("Get", testutils.GetPollID()).Return(nil, store.ErrNotFound)
+				return s
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedBody:       "404 page not found\n",
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/polls/%s", testutils.GetPollID()), nil)
+			r.Header.Add("Mattermost-User-ID", "userID5")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+
+			bodyBytes, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(t, test.ExpectedBody, string(bodyBytes))
+		})
+	}
+}
+
 func TestHandlePollMetadata(t *testing.T) {
 	for name, test := range map[string]struct {
 		SetupAPI           func(*plugintest.API) *plugintest.API
@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+// Events of the lifecycle of a poll that are recorded in the audit log.
+const (
+	auditEventPollCreated = "poll_created"
+	auditEventOptionAdded = "option_added"
+	auditEventPollEnded   = "poll_ended"
+	auditEventPollDeleted = "poll_deleted"
+)
+
+// newAuditRecord returns a record of an event of a poll for the audit log, or nil if the audit log is disabled.
+// If channelID is empty, the channel of the poll is used. Older polls look it up from their post,
+// hence it has to be called before the post gets deleted.
+func (p *MatterpollPlugin) newAuditRecord(event, actorID, channelID string, pl *poll.Poll) *poll.AuditRecord {
+	if !p.getConfiguration().EnableAuditLog {
+		return nil
+	}
+	if channelID == "" {
+		// The record is still useful without the channel
+		channelID, _ = p.pollChannelID(pl)
+	}
+	return pl.NewAuditRecord(event, actorID, channelID)
+}
+
+// saveAuditRecord appends a record to the audit log. Nil records of a disabled audit log are ignored.
+// Failures are logged, since the action that was audited has already happened.
+func (p *MatterpollPlugin) saveAuditRecord(record *poll.AuditRecord) {
+	if record == nil {
+		return
+	}
+	for attempt := 1; ; attempt++ {
+		err := p.Store.Audit().Append(record)
+		if err == nil {
+			return
+		}
+		if errors.Cause(err) != store.ErrConflict || attempt == maxUpdateAttempts {
+			p.API.LogWarn("failed to save audit record", "event", record.Event, "pollID", record.PollID, "error", err.Error())
+			return
+		}
+	}
+}
+
+// auditOptionAdded records an answer option that was added to a poll in the audit log.
+func (p *MatterpollPlugin) auditOptionAdded(actorID, channelID string, pl *poll.Poll, option string) {
+	if record := p.newAuditRecord(auditEventOptionAdded, actorID, channelID, pl); record != nil {
+		record.Option = option
+		p.saveAuditRecord(record)
+	}
+}
+
+// handleAuditLog returns the audit records of a UTC day, given as "day" query parameter, as JSON.
+// The day defaults to today. Only System Admins are allowed to read the audit log.
+func (p *MatterpollPlugin) handleAuditLog(w http.ResponseWriter, r *http.Request) {
+	isSystemAdmin, appErr := p.isSystemAdmin(r.Header.Get("Mattermost-User-ID"))
+	if appErr != nil {
+		http.Error(w, "failed to check permission", http.StatusInternalServerError)
+		return
+	}
+	if !isSystemAdmin {
+		http.Error(w, "not authorized", http.StatusForbidden)
+		return
+	}
+
+	day := r.URL.Query().Get("day")
+	if day == "" {
+		day = time.Now().UTC().Format(poll.AuditDayLayout)
+	} else if _, err := time.Parse(poll.AuditDayLayout, day); err != nil {
+		http.Error(w, "invalid day", http.StatusBadRequest)
+		return
+	}
+
+	records, err := p.Store.Audit().List(day)
+	if err != nil {
+		p.API.LogWarn("failed to list audit records", "day", day, "error", err.Error())
+		http.Error(w, "failed to list audit records", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		p.API.LogWarn("failed to write audit log response", "error", err.Error())
+	}
+}
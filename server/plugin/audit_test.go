@@ -0,0 +1,182 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestAuditRecords(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	t.Run("audit log disabled", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		record := p.newAuditRecord(auditEventPollCreated, "userID1", "channelID1", testutils.GetPoll())
+		assert.Nil(t, record)
+		p.saveAuditRecord(record)
+	})
+	t.Run("channel of the post", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		p.setConfiguration(&configuration{Trigger: "poll", EnableAuditLog: true})
+
+		assert.Equal(t, &poll.AuditRecord{
+			CreatedAt: 1234567890,
+			Event:     auditEventPollDeleted,
+			ActorID:   "userID2",
+			ChannelID: "channelID1",
+			PollID:    testutils.GetPollID(),
+			Question:  "Question",
+		}, p.newAuditRecord(auditEventPollDeleted, "userID2", "", testutils.GetPollWithVotes()))
+	})
+	t.Run("option added", func(t *testing.T) {
+		expected := &poll.AuditRecord{
+			CreatedAt: 1234567890,
+			Event:     auditEventOptionAdded,
+			ActorID:   "userID2",
+			ChannelID: "channelID1",
+			PollID:    testutils.GetPollID(),
+			Question:  "Question",
+			Option:    "New Option",
+		}
+		mockStore := &mockstore.Store{}
+		mockStore.AuditStore.On("Append", expected).Return(nil)
+		defer mockStore.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, mockStore)
+		p.setConfiguration(&configuration{Trigger: "poll", EnableAuditLog: true})
+
+		p.auditOptionAdded("userID2", "channelID1", testutils.GetPoll(), "New Option")
+	})
+	t.Run("concurrent modification is retried", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.AuditStore.On("Append", mock.AnythingOfType("*poll.AuditRecord")).Return(store.ErrConflict).Once()
+		mockStore.AuditStore.On("Append", mock.AnythingOfType("*poll.AuditRecord")).Return(nil).Once()
+		defer mockStore.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, mockStore)
+		p.setConfiguration(&configuration{Trigger: "poll", EnableAuditLog: true})
+
+		p.saveAuditRecord(p.newAuditRecord(auditEventPollCreated, "userID1", "channelID1", testutils.GetPoll()))
+	})
+	t.Run("failure is logged", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		mockStore := &mockstore.Store{}
+		mockStore.AuditStore.On("Append", mock.AnythingOfType("*poll.AuditRecord")).Return(errors.New(""))
+		defer mockStore.AssertExpectations(t)
+		p := setupTestPlugin(t, api, mockStore)
+		p.setConfiguration(&configuration{Trigger: "poll", EnableAuditLog: true})
+
+		p.saveAuditRecord(p.newAuditRecord(auditEventPollCreated, "userID1", "channelID1", testutils.GetPoll()))
+	})
+}
+
+func TestHandleAuditLog(t *testing.T) {
+	records := []*poll.AuditRecord{{
+		CreatedAt: 1234567890,
+		Event:     auditEventPollCreated,
+		ActorID:   "userID1",
+		ChannelID: "channelID1",
+		PollID:    testutils.GetPollID(),
+		Question:  "Question",
+	}}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Query              string
+		ExpectedStatusCode int
+		ExpectedRecords    []*poll.AuditRecord
+	}{
+		"Records of a day": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.AuditStore.On("List", "1970-01-15").Return(records, nil)
+				return store
+			},
+			Query:              "?day=1970-01-15",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedRecords:    records,
+		},
+		"Invalid day": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Query:              "?day=yesterday",
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		"Not a System Admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Query:              "?day=1970-01-15",
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		"List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.AuditStore.On("List", "1970-01-15").Return(nil, errors.New(""))
+				return store
+			},
+			Query:              "?day=1970-01-15",
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/audit"+test.Query, nil)
+			r.Header.Add("Mattermost-User-ID", "userID1")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			if test.ExpectedRecords != nil {
+				bodyBytes, err := ioutil.ReadAll(result.Body)
+				require.Nil(t, err)
+				var rRecords []*poll.AuditRecord
+				require.Nil(t, json.Unmarshal(bodyBytes, &rRecords))
+				assert.Equal(t, test.ExpectedRecords, rRecords)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+var (
+	autocompletePollIDHint = &i18n.Message{
+		ID:    "autocomplete.pollID.hint",
+		Other: "[Poll ID]",
+	}
+	autocompletePollIDHelpText = &i18n.Message{
+		ID:    "autocomplete.pollID.helpText",
+		Other: "The ID of the poll",
+	}
+	autocompleteHelpHelpText = &i18n.Message{
+		ID:    "autocomplete.help.helpText",
+		Other: "Show how to create polls. Poll Settings: {{.Settings}}",
+	}
+	autocompleteListHelpText = &i18n.Message{
+		ID:    "autocomplete.list.helpText",
+		Other: "List your running polls",
+	}
+	autocompleteHistoryHelpText = &i18n.Message{
+		ID:    "autocomplete.history.helpText",
+		Other: "List the polls that have ended in this channel",
+	}
+	autocompleteDigestHelpText = &i18n.Message{
+		ID:    "autocomplete.digest.helpText",
+		Other: "List the running polls of this channel",
+	}
+	autocompleteDigestTeamHelpText = &i18n.Message{
+		ID:    "autocomplete.digest.team.helpText",
+		Other: "List the running polls of all your channels in this team",
+	}
+	autocompleteHistoryPageHelpText = &i18n.Message{
+		ID:    "autocomplete.history.page.helpText",
+		Other: "Page of older polls",
+	}
+	autocompleteHistoryPageHint = &i18n.Message{
+		ID:    "autocomplete.history.page.hint",
+		Other: "[page]",
+	}
+	autocompleteSearchHelpText = &i18n.Message{
+		ID:    "autocomplete.search.helpText",
+		Other: "Find polls by keywords of their question or answer options",
+	}
+	autocompleteSearchKeywordsHelpText = &i18n.Message{
+		ID:    "autocomplete.search.keywords.helpText",
+		Other: "Keywords. Add --open, --closed or --creator=@username to filter the polls",
+	}
+	autocompleteSearchKeywordsHint = &i18n.Message{
+		ID:    "autocomplete.search.keywords.hint",
+		Other: "<keywords>",
+	}
+	autocompleteVoteHelpText = &i18n.Message{
+		ID:    "autocomplete.vote.helpText",
+		Other: "Vote in a poll without its buttons",
+	}
+	autocompleteVoteOptionsHelpText = &i18n.Message{
+		ID:    "autocomplete.vote.options.helpText",
+		Other: "Numbers of the answer options, e.g. 2 or 1,3",
+	}
+	autocompleteVoteOptionsHint = &i18n.Message{
+		ID:    "autocomplete.vote.options.hint",
+		Other: "<option numbers>",
+	}
+	autocompleteEndHelpText = &i18n.Message{
+		ID:    "autocomplete.end.helpText",
+		Other: "End a running poll",
+	}
+	autocompleteResultsHelpText = &i18n.Message{
+		ID:    "autocomplete.results.helpText",
+		Other: "Show the current results of a poll",
+	}
+	autocompleteExportHelpText = &i18n.Message{
+		ID:    "autocomplete.export.helpText",
+		Other: "Get the results of a poll as CSV file",
+	}
+	autocompleteReopenHelpText = &i18n.Message{
+		ID:    "autocomplete.reopen.helpText",
+		Other: "Re-open a poll that has ended shortly before",
+	}
+	autocompleteTransferHelpText = &i18n.Message{
+		ID:    "autocomplete.transfer.helpText",
+		Other: "Make another user the creator of a poll",
+	}
+	autocompleteTransferUserHint = &i18n.Message{
+		ID:    "autocomplete.transfer.user.hint",
+		Other: "[@username]",
+	}
+	autocompleteTransferUserHelpText = &i18n.Message{
+		ID:    "autocomplete.transfer.user.helpText",
+		Other: "The new creator of the poll",
+	}
+	autocompleteScheduledHelpText = &i18n.Message{
+		ID:    "autocomplete.scheduled.helpText",
+		Other: "List or cancel your scheduled polls",
+	}
+	autocompleteScheduledListHelpText = &i18n.Message{
+		ID:    "autocomplete.scheduled.list.helpText",
+		Other: "List your scheduled polls",
+	}
+	autocompleteScheduledCancelHelpText = &i18n.Message{
+		ID:    "autocomplete.scheduled.cancel.helpText",
+		Other: "Cancel a scheduled poll",
+	}
+	autocompleteMeetingHint = &i18n.Message{
+		ID:    "autocomplete.meeting.hint",
+		Other: `"[Question]" "[Slot 1]" "[Slot 2]"...`,
+	}
+	autocompleteMeetingHelpText = &i18n.Message{
+		ID:    "autocomplete.meeting.helpText",
+		Other: "Create a poll to find a date for a meeting. The slots are times in UTC like 2021-10-01T15:00",
+	}
+	autocompleteTemplateHelpText = &i18n.Message{
+		ID:    "autocomplete.template.helpText",
+		Other: "Save poll templates and create polls from them",
+	}
+	autocompleteTemplateSaveHint = &i18n.Message{
+		ID:    "autocomplete.template.save.hint",
+		Other: `[name] "[Question]" "[Answer 1]" "[Answer 2]"...`,
+	}
+	autocompleteTemplateSaveHelpText = &i18n.Message{
+		ID:    "autocomplete.template.save.helpText",
+		Other: "Save a template. Add --channel to share it with the channel",
+	}
+	autocompleteTemplateListHelpText = &i18n.Message{
+		ID:    "autocomplete.template.list.helpText",
+		Other: "List your templates and the ones of the channel",
+	}
+	autocompleteTemplateUseHint = &i18n.Message{
+		ID:    "autocomplete.template.use.hint",
+		Other: "[name]",
+	}
+	autocompleteTemplateUseHelpText = &i18n.Message{
+		ID:    "autocomplete.template.use.helpText",
+		Other: "Create a poll from a template",
+	}
+	autocompleteAdminHelpText = &i18n.Message{
+		ID:    "autocomplete.admin.helpText",
+		Other: "Manage the polls of all users",
+	}
+	autocompleteAdminListHelpText = &i18n.Message{
+		ID:    "autocomplete.admin.list.helpText",
+		Other: "List all running polls",
+	}
+	autocompleteAdminEndHelpText = &i18n.Message{
+		ID:    "autocomplete.admin.end.helpText",
+		Other: "End a running poll of any user",
+	}
+	autocompleteAdminDeleteHelpText = &i18n.Message{
+		ID:    "autocomplete.admin.delete.helpText",
+		Other: "Delete a poll of any user",
+	}
+	autocompleteAdminAuditHelpText = &i18n.Message{
+		ID:    "autocomplete.admin.audit.helpText",
+		Other: "Show when the votes of a poll were cast and changed",
+	}
+	autocompleteAdminPruneHelpText = &i18n.Message{
+		ID:    "autocomplete.admin.prune.helpText",
+		Other: "Delete ended polls and polls whose post has been deleted",
+	}
+	autocompleteMyDataHelpText = &i18n.Message{
+		ID:    "autocomplete.myData.helpText",
+		Other: "Export or delete the data polls store about you",
+	}
+	autocompleteMyDataExportHelpText = &i18n.Message{
+		ID:    "autocomplete.myData.export.helpText",
+		Other: "Get the data polls store about you",
+	}
+	autocompleteMyDataDeleteHelpText = &i18n.Message{
+		ID:    "autocomplete.myData.delete.helpText",
+		Other: "Remove your user ID from all polls",
+	}
+)
+
+// getAutocompleteData returns the autocomplete suggestions of the slash command.
+// Mattermost only suggests subcommands and their arguments, so the Poll Settings,
+// which follow the quoted question and answer options, are listed in the help text of the help subcommand.
+func getAutocompleteData(trigger string, l *i18n.Localizer) *model.AutocompleteData {
+	localize := func(m *i18n.Message) string {
+		return l.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: m})
+	}
+	withPollID := func(command *model.AutocompleteData) *model.AutocompleteData {
+		command.AddTextArgument(localize(autocompletePollIDHelpText), localize(autocompletePollIDHint), "")
+		return command
+	}
+
+	root := model.NewAutocompleteData(trigger, localize(commandAutoCompleteHint), localize(commandAutoCompleteDesc))
+
+	root.AddCommand(model.NewAutocompleteData("help", "", l.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: autocompleteHelpHelpText,
+		TemplateData:   map[string]interface{}{"Settings": strings.Join(poll.SettingKeywords(), " ")},
+	})))
+	root.AddCommand(model.NewAutocompleteData(commandList, "", localize(autocompleteListHelpText)))
+	history := model.NewAutocompleteData(commandHistory, "", localize(autocompleteHistoryHelpText))
+	history.AddTextArgument(localize(autocompleteHistoryPageHelpText), localize(autocompleteHistoryPageHint), "")
+	root.AddCommand(history)
+	digest := model.NewAutocompleteData(commandDigest, "", localize(autocompleteDigestHelpText))
+	digest.AddCommand(model.NewAutocompleteData("team", "", localize(autocompleteDigestTeamHelpText)))
+	root.AddCommand(digest)
+	search := model.NewAutocompleteData(commandSearch, "", localize(autocompleteSearchHelpText))
+	search.AddTextArgument(localize(autocompleteSearchKeywordsHelpText), localize(autocompleteSearchKeywordsHint), "")
+	root.AddCommand(search)
+	vote := withPollID(model.NewAutocompleteData(commandVote, "", localize(autocompleteVoteHelpText)))
+	vote.AddTextArgument(localize(autocompleteVoteOptionsHelpText), localize(autocompleteVoteOptionsHint), "")
+	root.AddCommand(vote)
+	root.AddCommand(withPollID(model.NewAutocompleteData(commandEnd, "", localize(autocompleteEndHelpText))))
+	root.AddCommand(withPollID(model.NewAutocompleteData(commandResults, "", localize(autocompleteResultsHelpText))))
+	root.AddCommand(withPollID(model.NewAutocompleteData(commandExport, "", localize(autocompleteExportHelpText))))
+	root.AddCommand(withPollID(model.NewAutocompleteData(commandReopen, "", localize(autocompleteReopenHelpText))))
+
+	transfer := withPollID(model.NewAutocompleteData(commandTransfer, "", localize(autocompleteTransferHelpText)))
+	transfer.AddTextArgument(localize(autocompleteTransferUserHelpText), localize(autocompleteTransferUserHint), "")
+	root.AddCommand(transfer)
+
+	scheduled := model.NewAutocompleteData(commandScheduled, "", localize(autocompleteScheduledHelpText))
+	scheduled.AddCommand(model.NewAutocompleteData("list", "", localize(autocompleteScheduledListHelpText)))
+	scheduled.AddCommand(withPollID(model.NewAutocompleteData("cancel", "", localize(autocompleteScheduledCancelHelpText))))
+	root.AddCommand(scheduled)
+
+	root.AddCommand(model.NewAutocompleteData(commandScheduleMeeting, localize(autocompleteMeetingHint), localize(autocompleteMeetingHelpText)))
+
+	template := model.NewAutocompleteData(commandTemplate, "", localize(autocompleteTemplateHelpText))
+	template.AddCommand(model.NewAutocompleteData("save", localize(autocompleteTemplateSaveHint), localize(autocompleteTemplateSaveHelpText)))
+	template.AddCommand(model.NewAutocompleteData("list", "", localize(autocompleteTemplateListHelpText)))
+	use := model.NewAutocompleteData("use", "", localize(autocompleteTemplateUseHelpText))
+	use.AddTextArgument(localize(autocompleteTemplateUseHelpText), localize(autocompleteTemplateUseHint), "")
+	template.AddCommand(use)
+	root.AddCommand(template)
+
+	myData := model.NewAutocompleteData(commandMyData, "", localize(autocompleteMyDataHelpText))
+	myData.AddCommand(model.NewAutocompleteData("export", "", localize(autocompleteMyDataExportHelpText)))
+	myData.AddCommand(model.NewAutocompleteData("delete", "", localize(autocompleteMyDataDeleteHelpText)))
+	root.AddCommand(myData)
+
+	// Only System Admins get the admin subcommand suggested
+	admin := model.NewAutocompleteData(commandAdmin, "", localize(autocompleteAdminHelpText))
+	admin.RoleID = model.SYSTEM_ADMIN_ROLE_ID
+	admin.AddCommand(model.NewAutocompleteData("list", "", localize(autocompleteAdminListHelpText)))
+	admin.AddCommand(withPollID(model.NewAutocompleteData("end", "", localize(autocompleteAdminEndHelpText))))
+	admin.AddCommand(withPollID(model.NewAutocompleteData("delete", "", localize(autocompleteAdminDeleteHelpText))))
+	admin.AddCommand(withPollID(model.NewAutocompleteData("audit", "", localize(autocompleteAdminAuditHelpText))))
+	admin.AddCommand(model.NewAutocompleteData("prune", "", localize(autocompleteAdminPruneHelpText)))
+	root.AddCommand(admin)
+
+	return root
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// boardsPluginID is the ID of the Boards plugin, formerly known as Focalboard.
+const boardsPluginID = "focalboard"
+
+var (
+	boardCardOption = &i18n.Message{
+		ID:    "boards.card.option",
+		One:   "- **{{.Answer}}**: {{.Votes}} vote",
+		Other: "- **{{.Answer}}**: {{.Votes}} votes",
+	}
+	boardCardLink = &i18n.Message{
+		ID:    "boards.card.link",
+		Other: "[Go to the poll]({{.Link}})",
+	}
+)
+
+// boardBlock is a block of the Boards API. Cards and their content are blocks.
+type boardBlock struct {
+	ID         string                 `json:"id"`
+	BoardID    string                 `json:"boardId"`
+	ParentID   string                 `json:"parentId"`
+	Schema     int                    `json:"schema"`
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Fields     map[string]interface{} `json:"fields"`
+	CreatedBy  string                 `json:"createdBy"`
+	ModifiedBy string                 `json:"modifiedBy"`
+	CreateAt   int64                  `json:"createAt"`
+	UpdateAt   int64                  `json:"updateAt"`
+}
+
+// resultsCardBlocks returns the blocks of a card with the results of a poll for the board of its board setting:
+// The card itself, titled with the question, and a text with the number of votes of every answer option.
+func (p *MatterpollPlugin) resultsCardBlocks(pl *poll.Poll) []*boardBlock {
+	localizer := p.getPollLocalizer(pl)
+	lines := []string{}
+	for i, o := range pl.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
+		lines = append(lines, p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+			DefaultMessage: boardCardOption,
+			TemplateData:   map[string]interface{}{"Answer": o.Answer, "Votes": pl.VoteCount(i)},
+			PluralCount:    pl.VoteCount(i),
+		}))
+	}
+	if pl.PostID != "" {
+		lines = append(lines, "", p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+			DefaultMessage: boardCardLink,
+			TemplateData: map[string]interface{}{
+				"Link": fmt.Sprintf("%s/_redirect/pl/%s", *p.ServerConfig.ServiceSettings.SiteURL, pl.PostID),
+			},
+		}))
+	}
+
+	// The Boards plugin prefixes the IDs of cards with "c" and the IDs of other blocks with "a"
+	now := model.GetMillis()
+	card := &boardBlock{
+		ID:       "c" + model.NewId(),
+		BoardID:  pl.Settings.Board,
+		ParentID: pl.Settings.Board,
+		Type:     "card",
+		Title:    pl.Question,
+	}
+	text := &boardBlock{
+		ID:       "a" + model.NewId(),
+		BoardID:  pl.Settings.Board,
+		ParentID: card.ID,
+		Type:     "text",
+		Title:    strings.Join(lines, "\n"),
+		Fields:   map[string]interface{}{},
+	}
+	card.Fields = map[string]interface{}{
+		"icon":         "📊",
+		"properties":   map[string]interface{}{},
+		"contentOrder": []string{text.ID},
+	}
+
+	blocks := []*boardBlock{card, text}
+	for _, b := range blocks {
+		b.Schema = 1
+		b.CreatedBy = pl.Creator
+		b.ModifiedBy = pl.Creator
+		b.CreateAt = now
+		b.UpdateAt = now
+	}
+	return blocks
+}
+
+// sendResultsToBoard adds a card with the results of an ended poll to the board of its board setting.
+// The card is created on behalf of the creator of the poll, so the Boards plugin checks if they may edit the board.
+// It does nothing if the poll has no board.
+func (p *MatterpollPlugin) sendResultsToBoard(pl *poll.Poll) error {
+	if pl.Settings.Board == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(p.resultsCardBlocks(pl))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal blocks")
+	}
+
+	r, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/api/v2/boards/%s/blocks", boardsPluginID, pl.Settings.Board), bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Mattermost-User-ID", pl.Creator)
+	// The Boards plugin rejects requests without this header to prevent CSRF
+	r.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp := p.API.PluginHTTP(r)
+	if resp == nil {
+		return errors.Errorf("plugin %s didn't respond", boardsPluginID)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("plugin %s responded with status code %d", boardsPluginID, resp.StatusCode)
+	}
+	return nil
+}
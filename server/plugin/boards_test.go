@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPluginResultsCardBlocks(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	pl := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Board: "boardID1"})
+	p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+	blocks := p.resultsCardBlocks(pl)
+	require.Len(t, blocks, 2)
+	card, text := blocks[0], blocks[1]
+
+	assert.Equal(t, "card", card.Type)
+	assert.Equal(t, "Question", card.Title)
+	assert.Equal(t, "boardID1", card.BoardID)
+	assert.Equal(t, "boardID1", card.ParentID)
+	assert.Equal(t, []string{text.ID}, card.Fields["contentOrder"])
+
+	assert.Equal(t, "text", text.Type)
+	assert.Equal(t, card.ID, text.ParentID)
+	assert.Equal(t, "- **Answer 1**: 3 votes\n"+
+		"- **Answer 2**: 1 vote\n"+
+		"- **Answer 3**: 0 votes\n"+
+		"\n"+
+		"[Go to the poll](https://example.org/_redirect/pl/postID1)", text.Title)
+
+	for _, b := range blocks {
+		assert.Equal(t, "userID1", b.CreatedBy)
+		assert.Equal(t, int64(1234567890), b.CreateAt)
+		assert.Equal(t, 1, b.Schema)
+	}
+}
+
+func TestPluginSendResultsToBoard(t *testing.T) {
+	pl := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Board: "boardID1"})
+	pl.EndedAt = 1234567899
+
+	isBoardRequest := func(r *http.Request) bool {
+		var blocks []*boardBlock
+		if err := json.NewDecoder(r.Body).Decode(&blocks); err != nil {
+			return false
+		}
+		return r.Method == http.MethodPost && r.URL.Path == "/focalboard/api/v2/boards/boardID1/blocks" &&
+			r.Header.Get("Mattermost-User-ID") == "userID1" && len(blocks) == 2 && blocks[0].Title == "Question"
+	}
+
+	t.Run("card is created", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("PluginHTTP", mock.MatchedBy(isBoardRequest)).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(nil))})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.NoError(t, p.sendResultsToBoard(pl))
+	})
+	t.Run("no board", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		assert.NoError(t, p.sendResultsToBoard(testutils.GetPollWithVotes()))
+	})
+	t.Run("Boards isn't installed", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("PluginHTTP", mock.Anything).Return(nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.Error(t, p.sendResultsToBoard(pl))
+	})
+	t.Run("creator can't edit the board", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("PluginHTTP", mock.Anything).Return(&http.Response{StatusCode: http.StatusForbidden, Body: ioutil.NopCloser(bytes.NewReader(nil))})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		err := p.sendResultsToBoard(pl)
+		require.Error(t, err)
+		assert.Equal(t, "plugin focalboard responded with status code 403", err.Error())
+	})
+}
@@ -0,0 +1,107 @@
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+var commandErrorChannelNotFound = &i18n.Message{
+	ID:    "command.error.channelNotFound",
+	Other: "The channel ~{{.Name}} could not be found or you can't post in it.",
+}
+
+// resolveBroadcastChannels resolves the channels named in the channels setting of a new poll,
+// which get posted in the same team as channelID. An error message is returned if one of the channels doesn't exist
+// or the creator isn't allowed to post in it.
+func (p *MatterpollPlugin) resolveBroadcastChannels(newPoll *poll.Poll, channelID string) *poll.ErrorMessage {
+	names := newPoll.Settings.ChannelNames()
+	if len(names) == 0 {
+		return nil
+	}
+
+	channel, appErr := p.API.GetChannel(channelID)
+	if appErr != nil {
+		p.API.LogWarn("failed to get channel", "channelID", channelID, "error", appErr.Error())
+		return poll.NewInternalErrorMessage(commandErrorGeneric, appErr)
+	}
+
+	channelIDs := make([]string, 0, len(names))
+	for _, name := range names {
+		c, appErr := p.API.GetChannelByName(channel.TeamId, name, false)
+		// Channels the creator can't post in are reported as missing to not reveal private channels
+		if appErr != nil || !p.API.HasPermissionToChannel(newPoll.Creator, c.Id, model.PERMISSION_CREATE_POST) {
+			return &poll.ErrorMessage{
+				Message: commandErrorChannelNotFound,
+				Data:    map[string]interface{}{"Name": name},
+			}
+		}
+		channelIDs = append(channelIDs, c.Id)
+	}
+	newPoll.SetBroadcastChannels(channelID, channelIDs)
+	return nil
+}
+
+// createBroadcastPosts posts copies of the post of a new poll in the other channels it gets broadcast to.
+func (p *MatterpollPlugin) createBroadcastPosts(pl *poll.Poll, post *model.Post) error {
+	for _, b := range pl.Broadcasts {
+		c := post.Clone()
+		c.Id = ""
+		c.ChannelId = b.ChannelID
+		c.RootId = ""
+		rPost, appErr := p.API.CreatePost(c)
+		if appErr != nil {
+			return errors.Wrap(appErr, "failed to create broadcast post")
+		}
+		b.PostID = rPost.Id
+	}
+	return nil
+}
+
+// updateBroadcastPosts applies an update of one post of a poll to all its other posts, so every channel
+// the poll has been broadcast to shows the same votes. The post with the ID updatedPostID is skipped.
+// Failures are only logged, since the next update fixes stale posts.
+func (p *MatterpollPlugin) updateBroadcastPosts(pl *poll.Poll, post *model.Post, updatedPostID string) {
+	if len(pl.Broadcasts) == 0 {
+		return
+	}
+	for _, b := range pl.Posts() {
+		if b.PostID == "" || b.PostID == updatedPostID {
+			continue
+		}
+		c := post.Clone()
+		c.Id = b.PostID
+		c.ChannelId = b.ChannelID
+		c.RootId = ""
+		if _, appErr := p.API.UpdatePost(c); appErr != nil {
+			p.API.LogWarn("failed to update broadcast post", "pollID", pl.ID, "postID", b.PostID, "error", appErr.Error())
+		}
+	}
+}
+
+// deleteBroadcastPosts deletes the copies of the post of a poll in other channels.
+// Failures are only logged, since the poll is deleted anyway.
+func (p *MatterpollPlugin) deleteBroadcastPosts(pl *poll.Poll) {
+	for _, b := range pl.Broadcasts {
+		if b.PostID == "" {
+			continue
+		}
+		if appErr := p.API.DeletePost(b.PostID); appErr != nil {
+			p.API.LogWarn("failed to delete broadcast post", "pollID", pl.ID, "postID", b.PostID, "error", appErr.Error())
+		}
+	}
+}
+
+// syncBroadcastPosts wraps a post action handler of a poll, so the update of the post the action was
+// triggered on is applied to all other posts of the poll.
+func (p *MatterpollPlugin) syncBroadcastPosts(pl *poll.Poll, handler postActionHandler) postActionHandler {
+	return func(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+		lc, update, err := handler(vars, request)
+		if update != nil {
+			p.updateBroadcastPosts(pl, update, request.PostId)
+		}
+		return lc, update, err
+	}
+}
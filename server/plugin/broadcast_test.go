@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPluginResolveBroadcastChannels(t *testing.T) {
+	t.Run("channels of the team", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+		api.On("GetChannelByName", "teamID1", "town-square", false).Return(&model.Channel{Id: "channelID2"}, nil)
+		api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID3"}, nil)
+		api.On("HasPermissionToChannel", "userID1", "channelID2", model.PERMISSION_CREATE_POST).Return(true)
+		api.On("HasPermissionToChannel", "userID1", "channelID3", model.PERMISSION_CREATE_POST).Return(true)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		pl := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Channels: "town-square,dev"})
+		assert.Nil(t, p.resolveBroadcastChannels(pl, "channelID1"))
+		assert.Equal(t, []*poll.BroadcastPost{{ChannelID: "channelID2"}, {ChannelID: "channelID3"}}, pl.Broadcasts)
+	})
+	t.Run("no channels", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		pl := testutils.GetPoll()
+		assert.Nil(t, p.resolveBroadcastChannels(pl, "channelID1"))
+		assert.Nil(t, pl.Broadcasts)
+	})
+	t.Run("channel not found", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+		api.On("GetChannelByName", "teamID1", "dev", false).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		pl := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Channels: "dev"})
+		errMsg := p.resolveBroadcastChannels(pl, "channelID1")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, commandErrorChannelNotFound, errMsg.Message)
+		assert.Equal(t, map[string]interface{}{"Name": "dev"}, errMsg.Data)
+	})
+	t.Run("creator can't post in channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+		api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID3"}, nil)
+		api.On("HasPermissionToChannel", "userID1", "channelID3", model.PERMISSION_CREATE_POST).Return(false)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		pl := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Channels: "dev"})
+		errMsg := p.resolveBroadcastChannels(pl, "channelID1")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, commandErrorChannelNotFound, errMsg.Message)
+	})
+}
+
+func TestPluginUpdateBroadcastPosts(t *testing.T) {
+	pl := testutils.GetPoll()
+	pl.ChannelID = "channelID1"
+	pl.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}, {ChannelID: "channelID3", PostID: "postID3"}}
+	post := &model.Post{Id: "postID2", ChannelId: "channelID2", Message: "message"}
+
+	api := &plugintest.API{}
+	api.On("UpdatePost", &model.Post{Id: "postID1", ChannelId: "channelID1", Message: "message"}).Return(nil, nil)
+	api.On("UpdatePost", &model.Post{Id: "postID3", ChannelId: "channelID3", Message: "message"}).Return(nil, &model.AppError{})
+	api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+	defer api.AssertExpectations(t)
+	p := setupTestPlugin(t, api, &mockstore.Store{})
+
+	p.updateBroadcastPosts(pl, post, "postID2")
+	assert.Equal(t, "postID2", post.Id)
+}
+
+func TestPluginDeleteBroadcastPosts(t *testing.T) {
+	pl := testutils.GetPoll()
+	pl.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}, {ChannelID: "channelID3"}}
+
+	api := &plugintest.API{}
+	api.On("DeletePost", "postID2").Return(nil)
+	defer api.AssertExpectations(t)
+	p := setupTestPlugin(t, api, &mockstore.Store{})
+
+	p.deleteBroadcastPosts(pl)
+}
+
+func TestPluginSyncBroadcastPosts(t *testing.T) {
+	pl := testutils.GetPoll()
+	pl.ChannelID = "channelID1"
+	pl.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}}
+	update := &model.Post{Message: "message"}
+
+	api := &plugintest.API{}
+	api.On("UpdatePost", &model.Post{Id: "postID1", ChannelId: "channelID1", Message: "message"}).Return(nil, nil)
+	defer api.AssertExpectations(t)
+	p := setupTestPlugin(t, api, &mockstore.Store{})
+
+	handler := p.syncBroadcastPosts(pl, func(map[string]string, *model.PostActionIntegrationRequest) (*i18n.LocalizeConfig, *model.Post, error) {
+		return nil, update, nil
+	})
+	_, post, err := handler(nil, &model.PostActionIntegrationRequest{PostId: "postID2"})
+	require.NoError(t, err)
+	assert.Equal(t, update, post)
+}
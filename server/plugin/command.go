@@ -3,6 +3,10 @@ package plugin
 import (
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin"
@@ -16,6 +20,37 @@ import (
 const (
 	// Parameter: SiteURL, manifest.Id
 	responseIconURL = "%s/plugins/%s/logo_dark-bg.png"
+
+	// commandExport is the keyword of the command that exports the results of a poll.
+	commandExport = "export"
+	// commandScheduled is the keyword of the command that manages scheduled polls.
+	commandScheduled = "scheduled"
+	// commandReopen is the keyword of the command that re-opens an ended poll.
+	commandReopen = "reopen"
+	// commandTransfer is the keyword of the command that makes another user the creator of a poll.
+	commandTransfer = "transfer"
+	// commandEnd is the keyword of the command that ends a running poll.
+	commandEnd = "end"
+	// commandResults is the keyword of the command that shows the current results of a poll.
+	commandResults = "results"
+	// commandList is the keyword of the command that lists the running polls of a user.
+	commandList = "list"
+	// commandScheduleMeeting is the keyword of the command that creates a poll to find a date for a meeting.
+	commandScheduleMeeting = "schedule-meeting"
+	// commandTemplate is the keyword of the command that manages poll templates.
+	commandTemplate = "template"
+	// commandAdmin is the keyword of the command that lets System Admins manage the polls of all users.
+	commandAdmin = "admin"
+	// commandHistory is the keyword of the command that lists the polls that have ended in a channel.
+	commandHistory = "history"
+	// commandDigest is the keyword of the command that lists the running polls of a channel or team.
+	commandDigest = "digest"
+	// commandSearch is the keyword of the command that finds polls by keywords.
+	commandSearch = "search"
+	// commandVote is the keyword of the command that votes in a poll.
+	commandVote = "vote"
+	// commandMyData is the keyword of the command that exports or erases the data polls store about a user.
+	commandMyData = "my-data"
 )
 
 var (
@@ -49,22 +84,6 @@ var (
 		ID:    "command.help.text.pollSetting.introduction",
 		Other: "Poll Settings provider further customization, e.g. `/{{.Trigger}} \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --progress --anonymous`. The available Poll Settings are:",
 	}
-	commandHelpTextPollSettingAnonymous = &i18n.Message{
-		ID:    "command.help.text.pollSetting.anonymous",
-		Other: "Don't show who voted for what when the poll ends",
-	}
-	commandHelpTextPollSettingProgress = &i18n.Message{
-		ID:    "command.help.text.pollSetting.progress",
-		Other: "During the poll, show how many votes each answer option got",
-	}
-	commandHelpTextPollSettingPublicAddOption = &i18n.Message{
-		ID:    "command.help.text.pollSetting.public-add-option",
-		Other: "Allow all users to add additional options",
-	}
-	commandHelpTextPollSettingMultiVote = &i18n.Message{
-		ID:    "command.help.text.pollSetting.multi-vote",
-		Other: "Allow users to vote for X options",
-	}
 
 	commandErrorGeneric = &i18n.Message{
 		ID:    "command.error.generic",
@@ -74,6 +93,74 @@ var (
 		ID:    "command.error.invalidNumberOfOptions",
 		Other: "You must provide either no answer or at least two answers.",
 	}
+	commandErrorPollNotFound = &i18n.Message{
+		ID:    "command.error.pollNotFound",
+		Other: "The poll {{.ID}} could not be found. Only the results of running polls can be exported.",
+	}
+	commandScheduleSuccess = &i18n.Message{
+		ID:    "command.schedule.success",
+		Other: "Your poll will be posted at {{.Time}} UTC. Use `/{{.Trigger}} scheduled cancel {{.ID}}` to cancel it.",
+	}
+	commandScheduledUsage = &i18n.Message{
+		ID:    "command.scheduled.usage",
+		Other: "Use `/{{.Trigger}} scheduled list` to list your scheduled polls and `/{{.Trigger}} scheduled cancel <Poll ID>` to cancel one.",
+	}
+	commandScheduledListEmpty = &i18n.Message{
+		ID:    "command.scheduled.list.empty",
+		Other: "You don't have any scheduled polls.",
+	}
+	commandScheduledListHeader = &i18n.Message{
+		ID:    "command.scheduled.list.header",
+		Other: "Your scheduled polls:",
+	}
+	commandScheduledListEntry = &i18n.Message{
+		ID:    "command.scheduled.list.entry",
+		Other: "- `{{.ID}}`: **{{.Question}}** at {{.Time}} UTC",
+	}
+	commandScheduledListEntryRecurring = &i18n.Message{
+		ID:    "command.scheduled.list.entryRecurring",
+		Other: "- `{{.ID}}`: **{{.Question}}** at {{.Time}} UTC, repeated {{.Repeat}}",
+	}
+	commandScheduledCancelSuccess = &i18n.Message{
+		ID:    "command.scheduled.cancel.success",
+		Other: "The scheduled poll **{{.Question}}** has been canceled.",
+	}
+	commandScheduledCancelInvalidPermission = &i18n.Message{
+		ID:    "command.scheduled.cancel.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to cancel it.",
+	}
+	commandErrorScheduledPollNotFound = &i18n.Message{
+		ID:    "command.error.scheduledPollNotFound",
+		Other: "The scheduled poll {{.ID}} could not be found.",
+	}
+	commandReopenSuccess = &i18n.Message{
+		ID:    "command.reopen.success",
+		Other: "The poll **{{.Question}}** has been re-opened.",
+	}
+	commandReopenInvalidPermission = &i18n.Message{
+		ID:    "command.reopen.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to re-open it.",
+	}
+	commandErrorReopenPollNotFound = &i18n.Message{
+		ID:    "command.error.reopenPollNotFound",
+		Other: "The poll {{.ID}} could not be found. Polls can only be re-opened shortly after they have ended.",
+	}
+	commandTransferSuccess = &i18n.Message{
+		ID:    "command.transfer.success",
+		Other: "The poll **{{.Question}}** has been transferred to @{{.Username}}.",
+	}
+	commandTransferInvalidPermission = &i18n.Message{
+		ID:    "command.transfer.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to transfer it.",
+	}
+	commandErrorUserNotFound = &i18n.Message{
+		ID:    "command.error.userNotFound",
+		Other: "The user @{{.Username}} could not be found.",
+	}
+	commandErrorUnsupportedLocale = &i18n.Message{
+		ID:    "command.error.unsupportedLocale",
+		Other: "There are no translations for the language {{.Locale}}.",
+	}
 	commandErrorInvalidInput = &i18n.Message{
 		ID:    "command.error.invalidInput",
 		Other: "Invalid input: {{.Error}}",
@@ -99,6 +186,52 @@ func (p *MatterpollPlugin) executeCommand(args *model.CommandArgs) (string, *mod
 	defaultYes := p.LocalizeDefaultMessage(publicLocalizer, commandDefaultYes)
 	defaultNo := p.LocalizeDefaultMessage(publicLocalizer, commandDefaultNo)
 
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandExport); ok && len(subArgs) == 1 {
+		return p.executeExportCommand(subArgs[0], creatorID, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandScheduled); ok && len(subArgs) > 0 {
+		return p.executeScheduledCommand(subArgs, creatorID, configuration.Trigger, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandReopen); ok && len(subArgs) == 1 {
+		return p.executeReopenCommand(subArgs[0], creatorID, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandTransfer); ok && len(subArgs) == 2 {
+		return p.executeTransferCommand(subArgs[0], subArgs[1], creatorID, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandEnd); ok && len(subArgs) == 1 {
+		return p.executeEndCommand(subArgs[0], creatorID, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandResults); ok && len(subArgs) == 1 {
+		return p.executeResultsCommand(subArgs[0], creatorID, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandList); ok && len(subArgs) == 0 {
+		return p.executeListCommand(creatorID, userLocalizer), nil
+	}
+	if _, ok := parseSubcommand(args.Command, configuration.Trigger, commandScheduleMeeting); ok {
+		return p.executeMeetingCommand(args, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandTemplate); ok {
+		return p.executeTemplateCommand(args, subArgs, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandAdmin); ok {
+		return p.executeAdminCommand(subArgs, creatorID, configuration.Trigger, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandHistory); ok {
+		return p.executeHistoryCommand(subArgs, args.ChannelId, configuration.Trigger, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandDigest); ok {
+		return p.executeDigestCommand(subArgs, args.ChannelId, args.TeamId, creatorID, configuration.Trigger, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandSearch); ok {
+		return p.executeSearchCommand(subArgs, creatorID, configuration.Trigger, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandVote); ok {
+		return p.executeVoteCommand(subArgs, args.ChannelId, creatorID, configuration.Trigger, userLocalizer), nil
+	}
+	if subArgs, ok := parseSubcommand(args.Command, configuration.Trigger, commandMyData); ok {
+		return p.executeMyDataCommand(subArgs, creatorID, configuration.Trigger, userLocalizer), nil
+	}
+
 	q, o, s := utils.ParseInput(args.Command, configuration.Trigger)
 	if q == "" {
 		siteURL := *p.ServerConfig.ServiceSettings.SiteURL
@@ -128,10 +261,13 @@ func (p *MatterpollPlugin) executeCommand(args *model.CommandArgs) (string, *mod
 			DefaultMessage: commandHelpTextPollSettingIntroduction,
 			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
 		}) + "\n"
-		msg += "- `--anonymous`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingAnonymous) + "\n"
-		msg += "- `--progress`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingProgress) + "\n"
-		msg += "- `--public-add-option`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingPublicAddOption) + "\n"
-		msg += "- `--votes=X`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingMultiVote)
+		lines := []string{}
+		for _, d := range poll.SettingDefinitions() {
+			if d.HelpText != nil {
+				lines = append(lines, "- `"+d.Keyword()+"`: "+p.LocalizeDefaultMessage(userLocalizer, d.HelpText))
+			}
+		}
+		msg += strings.Join(lines, "\n")
 
 		return msg, nil
 	}
@@ -144,7 +280,7 @@ func (p *MatterpollPlugin) executeCommand(args *model.CommandArgs) (string, *mod
 		}
 	}
 
-	settings, errMsg := poll.NewSettingsFromStrings(s)
+	settings, errMsg := poll.NewSettingsFromStringsWithDefaults(poll.CanonicalizeSettings(s, userLocalizer), configuration.pollDefaults())
 	if errMsg != nil {
 		appErr := &model.AppError{
 			Id: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
@@ -152,17 +288,27 @@ func (p *MatterpollPlugin) executeCommand(args *model.CommandArgs) (string, *mod
 				TemplateData: map[string]interface{}{
 					"Error": p.LocalizeErrorMessage(userLocalizer, errMsg),
 				}}),
-			StatusCode: http.StatusBadRequest,
+			StatusCode: errMsg.HTTPStatus(),
 			Where:      "ExecuteCommand",
 		}
 		return "", appErr
 	}
 
-	var newPoll *poll.Poll
-	if len(o) == 0 {
-		newPoll, errMsg = poll.NewPoll(creatorID, q, []string{defaultYes, defaultNo}, settings)
-	} else {
-		newPoll, errMsg = poll.NewPoll(creatorID, q, o, settings)
+	// The options of a poll with a scale are generated from the scale
+	if len(o) == 0 && !settings.IsScale() {
+		pollLocalizer := p.getLocaleLocalizer(settings.Locale)
+		o = []string{
+			p.LocalizeDefaultMessage(pollLocalizer, commandDefaultYes),
+			p.LocalizeDefaultMessage(pollLocalizer, commandDefaultNo),
+		}
+	}
+	newPoll, errMsg := poll.NewPollWithLimits(creatorID, q, o, settings, configuration.pollLimits())
+	channelID, rootID := args.ChannelId, args.RootId
+	if errMsg == nil {
+		channelID, rootID, errMsg = p.resolveTarget(newPoll, args.ChannelId, args.RootId)
+	}
+	if errMsg == nil {
+		errMsg = p.resolveSettings(newPoll, channelID)
 	}
 	if errMsg != nil {
 		appErr := &model.AppError{
@@ -171,45 +317,455 @@ func (p *MatterpollPlugin) executeCommand(args *model.CommandArgs) (string, *mod
 				TemplateData: map[string]interface{}{
 					"Error": p.LocalizeErrorMessage(userLocalizer, errMsg),
 				}}),
-			StatusCode: http.StatusBadRequest,
+			StatusCode: errMsg.HTTPStatus(),
 			Where:      "ExecuteCommand",
 		}
 		return "", appErr
 	}
 
-	displayName, appErr := p.ConvertCreatorIDToDisplayName(creatorID)
+	return p.publishPoll(newPoll, channelID, rootID, userLocalizer), nil
+}
+
+// publishPoll either posts a new poll or schedules it, if it should be posted later, and returns the response message.
+// Creators that exceeded their rate limit get an error message instead.
+func (p *MatterpollPlugin) publishPoll(newPoll *poll.Poll, channelID, rootID string, userLocalizer *i18n.Localizer) string {
+	errMsg, err := p.allowPollCreation(newPoll.Creator)
+	if err != nil {
+		p.API.LogWarn("failed to check rate limit", "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if errMsg != nil {
+		return p.LocalizeErrorMessage(userLocalizer, errMsg)
+	}
+
+	if newPoll.Settings.ScheduledAt > 0 {
+		return p.schedulePoll(newPoll, channelID, rootID, userLocalizer)
+	}
+
+	if err := p.postPoll(newPoll, channelID, rootID); err != nil {
+		p.API.LogWarn("failed to post poll", "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.pollPostedMessage(newPoll, userLocalizer)
+}
+
+// resolveSettings applies the settings of a new poll that depend on users, on the channel the poll gets posted in
+// or on the language of the server. An error message is returned if they can't be applied.
+func (p *MatterpollPlugin) resolveSettings(newPoll *poll.Poll, channelID string) *poll.ErrorMessage {
+	if errMsg := p.resolveLocale(newPoll); errMsg != nil {
+		return errMsg
+	}
+	newPoll.LocalizeAbstainOption(p.getPollLocalizer(newPoll))
+	if errMsg := p.resolveAllowedVoters(newPoll); errMsg != nil {
+		return errMsg
+	}
+	if errMsg := p.resolveWeights(newPoll); errMsg != nil {
+		return errMsg
+	}
+	if errMsg := p.resolveBroadcastChannels(newPoll, channelID); errMsg != nil {
+		return errMsg
+	}
+	return p.resolveQuorum(newPoll, channelID)
+}
+
+// resolveLocale replaces the language of the locale setting of a new poll with the matching translation.
+// An error message is returned if there are no translations for the language.
+func (p *MatterpollPlugin) resolveLocale(newPoll *poll.Poll) *poll.ErrorMessage {
+	locale := newPoll.Settings.Locale
+	if locale == "" {
+		return nil
+	}
+
+	supported := p.supportedLocale(locale)
+	if supported == "" {
+		return &poll.ErrorMessage{
+			Message: commandErrorUnsupportedLocale,
+			Data:    map[string]interface{}{"Locale": locale},
+		}
+	}
+	newPoll.Settings.Locale = supported
+	return nil
+}
+
+// resolveQuorum turns the quorum of a new poll that is a share of the channel members into the number of voters.
+// Only members who may vote in the poll are counted.
+func (p *MatterpollPlugin) resolveQuorum(newPoll *poll.Poll, channelID string) *poll.ErrorMessage {
+	if newPoll.Settings.QuorumPercent <= 0 {
+		return nil
+	}
+
+	_, eligible, err := p.channelParticipation(newPoll, channelID)
+	if err != nil {
+		p.API.LogWarn("failed to count users in channel", "channelID", channelID, "error", err.Error())
+		return poll.NewInternalErrorMessage(commandErrorGeneric, err)
+	}
+	newPoll.SetQuorumFromEligible(eligible)
+	return nil
+}
+
+// resolveAllowedVoters restricts voting in a new poll to the users named in its voters setting.
+// An error message is returned if one of the users doesn't exist.
+func (p *MatterpollPlugin) resolveAllowedVoters(newPoll *poll.Poll) *poll.ErrorMessage {
+	usernames := newPoll.Settings.VoterUsernames()
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		user, appErr := p.API.GetUserByUsername(username)
+		if appErr != nil {
+			return &poll.ErrorMessage{
+				Message: commandErrorUserNotFound,
+				Data:    map[string]interface{}{"Username": username},
+			}
+		}
+		userIDs = append(userIDs, user.Id)
+	}
+	newPoll.SetAllowedVoters(userIDs)
+	return nil
+}
+
+// resolveWeights sets the weights of the users named in the weights setting of a new poll.
+// An error message is returned if one of the users doesn't exist.
+func (p *MatterpollPlugin) resolveWeights(newPoll *poll.Poll) *poll.ErrorMessage {
+	byUsername := newPoll.Settings.WeightsByUsername()
+	if len(byUsername) == 0 {
+		return nil
+	}
+
+	weights := make(map[string]int, len(byUsername))
+	for username, weight := range byUsername {
+		user, appErr := p.API.GetUserByUsername(username)
+		if appErr != nil {
+			return &poll.ErrorMessage{
+				Message: commandErrorUserNotFound,
+				Data:    map[string]interface{}{"Username": username},
+			}
+		}
+		weights[user.Id] = weight
+	}
+	newPoll.SetWeights(weights)
+	return nil
+}
+
+// postPoll posts a new poll in a channel and in the other channels it gets broadcast to and saves it.
+func (p *MatterpollPlugin) postPoll(poll *poll.Poll, channelID, rootID string) error {
+	p.preparePoll(poll)
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
 	if appErr != nil {
-		p.API.LogWarn("failed to ConvertCreatorIDToDisplayName", "error", appErr.Error())
-		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+		return errors.Wrap(appErr, "failed to get display name for creator")
 	}
 
-	actions := newPoll.ToPostActions(publicLocalizer, manifest.Id, displayName)
+	actions := poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName)
 	post := &model.Post{
 		UserId:    p.botUserID,
-		ChannelId: args.ChannelId,
-		RootId:    args.RootId,
+		ChannelId: channelID,
+		RootId:    rootID,
 		Type:      MatterpollPostType,
 		Props: map[string]interface{}{
-			"poll_id": newPoll.ID,
+			"poll_id": poll.ID,
 		},
 	}
 	model.ParseSlackAttachment(post, actions)
 
 	rPost, appErr := p.API.CreatePost(post)
 	if appErr != nil {
-		p.API.LogWarn("failed to post poll post", "error", appErr.Error())
-		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+		return errors.Wrap(appErr, "failed to create post")
 	}
 
-	newPoll.PostID = rPost.Id
+	poll.PostID = rPost.Id
+	poll.ChannelID = channelID
+	poll.RootID = rootID
+	if err := p.createBroadcastPosts(poll, post); err != nil {
+		return err
+	}
+
+	if err := p.Store.Poll().Insert(poll); err != nil {
+		return errors.Wrap(err, "failed to save poll")
+	}
+	p.metrics.IncPollsCreated()
+	p.saveAuditRecord(p.newAuditRecord(auditEventPollCreated, poll.Creator, channelID, poll))
 
-	if err := p.Store.Poll().Insert(newPoll); err != nil {
-		p.API.LogWarn("failed to save poll", "error", err.Error())
-		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	if err := p.addPollReactions(poll, rPost.Id); err != nil {
+		return errors.Wrap(err, "failed to add reactions")
 	}
 
 	p.API.LogDebug("Created a new poll", "post", rPost.ToJson())
-	return "", nil
+	return nil
+}
+
+// parseSubcommand returns the arguments of a command of the form "/<trigger> <subcommand> <arguments>".
+// The returned bool is false if the command doesn't start with subcommand.
+func parseSubcommand(command, trigger, subcommand string) ([]string, bool) {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(command), "/"+trigger))
+	if len(fields) == 0 || fields[0] != subcommand {
+		return nil, false
+	}
+	return fields[1:], true
+}
+
+// executeExportCommand sends the results of a poll as CSV file to the user and returns the response message.
+func (p *MatterpollPlugin) executeExportCommand(pollID, userID string, userLocalizer *i18n.Localizer) string {
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		p.API.LogWarn("failed to get poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to check permission", "pollID", pollID, "error", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if !canManagePoll {
+		return p.LocalizeDefaultMessage(userLocalizer, responseExportResultsInvalidPermission)
+	}
+
+	if err := p.sendResultsCSV(poll, userID); err != nil {
+		p.API.LogWarn("failed to send results", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, responseExportResultsSuccess)
+}
+
+// executeReopenCommand re-opens an ended poll, restores the buttons of its post and returns the response message.
+func (p *MatterpollPlugin) executeReopenCommand(pollID, userID string, userLocalizer *i18n.Localizer) string {
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		p.API.LogWarn("failed to get poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorReopenPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to check permission", "pollID", pollID, "error", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if !canManagePoll {
+		return p.LocalizeDefaultMessage(userLocalizer, commandReopenInvalidPermission)
+	}
+
+	prev := poll.Copy()
+	if errMsg := poll.Reopen(model.GetMillis(), p.getConfiguration().reopenGracePeriod()); errMsg != nil {
+		return p.LocalizeErrorMessage(userLocalizer, errMsg)
+	}
+
+	if err := p.restorePollPost(poll); err != nil {
+		p.API.LogWarn("failed to restore poll post", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	if err := p.Store.Poll().Update(prev, poll); err != nil {
+		p.API.LogWarn("failed to save poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if channelID, err := p.pollChannelID(poll); err == nil {
+		p.unarchivePoll(poll, channelID)
+	}
+
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandReopenSuccess,
+		TemplateData:   map[string]interface{}{"Question": poll.Question},
+	})
+}
+
+// executeTransferCommand makes another user the creator of a running poll, updates its post and returns the response message.
+func (p *MatterpollPlugin) executeTransferCommand(pollID, username, userID string, userLocalizer *i18n.Localizer) string {
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		p.API.LogWarn("failed to get poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAdminPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to check permission", "pollID", pollID, "error", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if !canManagePoll {
+		return p.LocalizeDefaultMessage(userLocalizer, commandTransferInvalidPermission)
+	}
+
+	username = strings.TrimPrefix(username, "@")
+	user, appErr := p.API.GetUserByUsername(username)
+	if appErr != nil {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorUserNotFound,
+			TemplateData:   map[string]interface{}{"Username": username},
+		})
+	}
+
+	prev := poll.Copy()
+	if errMsg := poll.Transfer(user.Id); errMsg != nil {
+		return p.LocalizeErrorMessage(userLocalizer, errMsg)
+	}
+
+	// The post shows the name of the creator
+	if err := p.restorePollPost(poll); err != nil {
+		p.API.LogWarn("failed to update poll post", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	if err := p.Store.Poll().Update(prev, poll); err != nil {
+		p.API.LogWarn("failed to save poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandTransferSuccess,
+		TemplateData:   map[string]interface{}{"Question": poll.Question, "Username": user.Username},
+	})
+}
+
+// restorePollPost renders the buttons of a running poll into its post again,
+// e.g. to turn the end poll post of a re-opened poll back into a post with buttons.
+func (p *MatterpollPlugin) restorePollPost(poll *poll.Poll) error {
+	if poll.PostID == "" {
+		return errors.New("poll has no post")
+	}
+
+	post, appErr := p.API.GetPost(poll.PostID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get post")
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	// The end poll post replaced the type and the properties of the post
+	post.Type = MatterpollPostType
+	post.AddProp("poll_id", poll.ID)
+	model.ParseSlackAttachment(post, poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName))
+	if _, appErr := p.API.UpdatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to update post")
+	}
+	p.updateBroadcastPosts(poll, post, post.Id)
+	return nil
+}
+
+// schedulePoll stores a poll, that gets posted later, and returns the response message.
+func (p *MatterpollPlugin) schedulePoll(newPoll *poll.Poll, channelID, rootID string, userLocalizer *i18n.Localizer) string {
+	if err := p.Store.ScheduledPoll().Insert(poll.NewScheduledPoll(newPoll, channelID, rootID)); err != nil {
+		p.API.LogWarn("failed to save scheduled poll", "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandScheduleSuccess,
+		TemplateData: map[string]interface{}{
+			"Time":    formatTime(newPoll.Settings.ScheduledAt),
+			"Trigger": p.getConfiguration().Trigger,
+			"ID":      newPoll.ID,
+		},
+	})
+}
+
+// executeScheduledCommand lists or cancels the scheduled polls of a user and returns the response message.
+func (p *MatterpollPlugin) executeScheduledCommand(args []string, userID, trigger string, userLocalizer *i18n.Localizer) string {
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		return p.listScheduledPolls(userID, userLocalizer)
+	case len(args) == 2 && args[0] == "cancel":
+		return p.cancelScheduledPoll(args[1], userID, userLocalizer)
+	default:
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandScheduledUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		})
+	}
+}
+
+// listScheduledPolls returns a message listing all scheduled polls created by a user, ordered by the time they get posted.
+func (p *MatterpollPlugin) listScheduledPolls(userID string, userLocalizer *i18n.Localizer) string {
+	ids, err := p.Store.ScheduledPoll().ListIDs()
+	if err != nil {
+		p.API.LogWarn("failed to list scheduled polls", "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	var scheduledPolls []*poll.ScheduledPoll
+	for _, id := range ids {
+		scheduledPoll, err := p.Store.ScheduledPoll().Get(id)
+		if err != nil {
+			p.API.LogWarn("failed to get scheduled poll", "pollID", id, "error", err.Error())
+			continue
+		}
+		if scheduledPoll.Poll.Creator == userID {
+			scheduledPolls = append(scheduledPolls, scheduledPoll)
+		}
+	}
+	if len(scheduledPolls) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandScheduledListEmpty)
+	}
+
+	sort.Slice(scheduledPolls, func(i, j int) bool {
+		return scheduledPolls[i].Poll.Settings.ScheduledAt < scheduledPolls[j].Poll.Settings.ScheduledAt
+	})
+	lines := []string{p.LocalizeDefaultMessage(userLocalizer, commandScheduledListHeader)}
+	for _, scheduledPoll := range scheduledPolls {
+		entry := commandScheduledListEntry
+		if scheduledPoll.Poll.Settings.Repeat != "" {
+			entry = commandScheduledListEntryRecurring
+		}
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: entry,
+			TemplateData: map[string]interface{}{
+				"ID":       scheduledPoll.Poll.ID,
+				"Question": scheduledPoll.Poll.Question,
+				"Time":     formatTime(scheduledPoll.Poll.Settings.ScheduledAt),
+				"Repeat":   scheduledPoll.Poll.Settings.Repeat,
+			},
+		}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cancelScheduledPoll deletes a scheduled poll, if the user is allowed to manage it, and returns the response message.
+func (p *MatterpollPlugin) cancelScheduledPoll(pollID, userID string, userLocalizer *i18n.Localizer) string {
+	scheduledPoll, err := p.Store.ScheduledPoll().Get(pollID)
+	if err != nil {
+		p.API.LogWarn("failed to get scheduled poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorScheduledPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(scheduledPoll.Poll, userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to check permission", "pollID", pollID, "error", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if !canManagePoll {
+		return p.LocalizeDefaultMessage(userLocalizer, commandScheduledCancelInvalidPermission)
+	}
+
+	if err := p.Store.ScheduledPoll().Delete(pollID); err != nil {
+		p.API.LogWarn("failed to delete scheduled poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandScheduledCancelSuccess,
+		TemplateData:   map[string]interface{}{"Question": scheduledPoll.Poll.Question},
+	})
+}
+
+// formatTime formats a time in milliseconds the same way times are given in poll settings.
+func formatTime(millis int64) string {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC().Format(poll.EndTimeLayout)
 }
 
 func (p *MatterpollPlugin) getCommand(trigger string) (*model.Command, error) {
@@ -225,11 +781,17 @@ func (p *MatterpollPlugin) getCommand(trigger string) (*model.Command, error) {
 		AutoComplete:         true,
 		AutoCompleteDesc:     p.LocalizeDefaultMessage(localizer, commandAutoCompleteDesc),
 		AutoCompleteHint:     p.LocalizeDefaultMessage(localizer, commandAutoCompleteHint),
+		AutocompleteData:     getAutocompleteData(trigger, localizer),
 		AutocompleteIconData: iconData,
 	}, nil
 }
 
 func (p *MatterpollPlugin) getCreatePollDialog(siteURL, rootID string, l *i18n.Localizer) model.Dialog {
+	defaults := p.getConfiguration().pollDefaults()
+	if defaults.MaxVotes <= 0 {
+		defaults.MaxVotes = 1
+	}
+
 	elements := []model.DialogElement{{
 		DisplayName: p.LocalizeDefaultMessage(l, &i18n.Message{
 			ID:    "dialog.createPoll.question",
@@ -239,7 +801,14 @@ func (p *MatterpollPlugin) getCreatePollDialog(siteURL, rootID string, l *i18n.L
 		Type:    "text",
 		SubType: "text",
 	}}
-	for i := 1; i < 4; i++ {
+	for i := 1; i <= createPollDialogOptions; i++ {
+		helpText := ""
+		if i == 1 {
+			helpText = p.LocalizeDefaultMessage(l, &i18n.Message{
+				ID:    "dialog.createPoll.option.helpText",
+				Other: "Add an image to an option with the link to it after a |, e.g. Blue|https://example.org/blue.png",
+			})
+		}
 		elements = append(elements, model.DialogElement{
 			DisplayName: p.LocalizeWithConfig(l, &i18n.LocalizeConfig{
 				DefaultMessage: &i18n.Message{
@@ -252,7 +821,8 @@ func (p *MatterpollPlugin) getCreatePollDialog(siteURL, rootID string, l *i18n.L
 			Name:     fmt.Sprintf("option%v", i),
 			Type:     "text",
 			SubType:  "text",
-			Optional: i > 2,
+			Optional: i > poll.MinAnswerOptions,
+			HelpText: helpText,
 		})
 	}
 
@@ -261,35 +831,27 @@ func (p *MatterpollPlugin) getCreatePollDialog(siteURL, rootID string, l *i18n.L
 		Name:        "setting-multi",
 		Type:        "text",
 		SubType:     "number",
-		Default:     "1",
+		Default:     strconv.Itoa(defaults.MaxVotes),
 		HelpText: p.LocalizeWithConfig(l, &i18n.LocalizeConfig{
 			DefaultMessage: &i18n.Message{
 				ID:    "dialog.createPoll.setting.multi",
-				Other: "The number of options that an user can vote on.",
+				Other: "The number of options that an user can vote on. Use 0 to allow any number of options.",
 			}}),
 		Optional: false,
 	})
-	elements = append(elements, model.DialogElement{
-		DisplayName: "Anonymous",
-		Name:        "setting-anonymous",
-		Type:        "bool",
-		Placeholder: p.LocalizeDefaultMessage(l, commandHelpTextPollSettingAnonymous),
-		Optional:    true,
-	})
-	elements = append(elements, model.DialogElement{
-		DisplayName: "Progress",
-		Name:        "setting-progress",
-		Type:        "bool",
-		Placeholder: p.LocalizeDefaultMessage(l, commandHelpTextPollSettingProgress),
-		Optional:    true,
-	})
-	elements = append(elements, model.DialogElement{
-		DisplayName: "Public Add Option",
-		Name:        "setting-public-add-option",
-		Type:        "bool",
-		Placeholder: p.LocalizeDefaultMessage(l, commandHelpTextPollSettingPublicAddOption),
-		Optional:    true,
-	})
+	for _, d := range poll.SettingDefinitions() {
+		if d.DialogName == nil {
+			continue
+		}
+		elements = append(elements, model.DialogElement{
+			DisplayName: p.LocalizeDefaultMessage(l, d.DialogName),
+			Name:        poll.SubmissionSettingPrefix + d.Key,
+			Type:        "bool",
+			Placeholder: p.LocalizeDefaultMessage(l, d.HelpText),
+			Default:     strconv.FormatBool(d.Enabled(defaults)),
+			Optional:    true,
+		})
+	}
 
 	dialog := model.Dialog{
 		CallbackId: rootID,
@@ -3,12 +3,19 @@ package plugin
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"bou.ke/monkey"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
 
 	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
@@ -23,9 +30,46 @@ func TestPluginExecuteCommand(t *testing.T) {
 		"- `--anonymous`: Don't show who voted for what when the poll ends\n" +
 		"- `--progress`: During the poll, show how many votes each answer option got\n" +
 		"- `--public-add-option`: Allow all users to add additional options\n" +
-		"- `--votes=X`: Allow users to vote for X options"
+		"- `--votes=X`: Allow users to vote for X options. Use 0 to allow any number of options\n" +
+		"- `--quorum=X`: Require at least X users to vote for the poll to be valid. X can also be a share of the channel members like `60%`\n" +
+		"- `--close-on-quorum`: End the poll as soon as the quorum is reached\n" +
+		"- `--hold-for-quorum`: Don't let anyone end the poll before the quorum is reached. It still ends at `--end=X`\n" +
+		"- `--reveal-on-end`: Keep the poll anonymous while it runs, but show who voted for what when it ends\n" +
+		"- `--semi-anonymous`: Like `--anonymous`, but you can see who voted for what\n" +
+		"- `--ranked`: Let users rank the options in the order they vote for them. The winner is determined by instant-runoff\n" +
+		"- `--secret`: Hide the number of votes and the voters from everyone until the poll ends\n" +
+		"- `--end=X`: End the poll automatically after a duration like `2h` or at a time in UTC like `2021-10-01T15:00`\n" +
+		"- `--capacity=X`: Allow at most X users to vote for each option, e.g. to sign up for slots\n" +
+		"- `--schedule=X`: Post the poll later, after a duration like `1h` or at a time in UTC like `2021-10-01T15:00`\n" +
+		"- `--repeat=X`: Post a scheduled poll again every day, week or month. X is `daily`, `weekly` or `monthly`\n" +
+		"- `--voters=X`: Only allow some users to vote. X is `channel` for the members of the channel or a list of users like `@user1,@user2`\n" +
+		"- `--weights=X`: Weight the votes of some users, e.g. `@user1:3,@user2:1`. X can also be the name of a user attribute that contains the weight. Other users have a weight of 1\n" +
+		"- `--channels=X`: Post the poll in other channels of the team as well, e.g. `~town-square,~dev`. All posts share the same votes\n" +
+		"- `--in=X`: Post the poll in another channel of the team, e.g. `~town-square`\n" +
+		"- `--dm=X`: Send the poll as direct or group message to some users, e.g. `@user1,@user2`\n" +
+		"- `--locale=X`: Show the poll and its results in language X for everyone, e.g. `de`\n" +
+		"- `--chart=X`: Attach a chart of the results when the poll ends. X is `bar` or `pie`\n" +
+		"- `--board=X`: Add a card with the results to the board with ID X in Boards when the poll ends\n" +
+		"- `--quiz=X`: Mark option X as the correct answer, which is revealed together with who answered correctly when the poll ends\n" +
+		"- `--scale=X`: Let users rate on a scale like `1-5` instead of giving options. The average and median are shown when the poll ends\n" +
+		"- `--remind=X`: Remind users who haven't voted yet X before the end, e.g. `2h`. Requires `--end=X`\n" +
+		"- `--reactions`: Let users vote by reacting to the poll with the numbered emoji of an option, in addition to the buttons\n" +
+		"- `--allow-other`: Let users vote for an answer of their own with an \"Other…\" button, which adds it as a new option\n" +
+		"- `--approve-options`: Only add options suggested by other users once you approve them. Requires `--public-add-option`\n" +
+		"- `--shuffle`: Show the options in a different order to every user to avoid a bias towards the first ones\n" +
+		"- `--thread-results`: Post the results as a pinned reply in the thread of the poll when it ends\n" +
+		"- `--abstain`: Add an \"Abstain\" option, whose votes are shown but not counted in the percentages and the quorum\n" +
+		"- `--comments`: Let users explain their vote with a short comment, which is listed in the results when the poll ends\n" +
+		"- `--require-comment=X`: Let votes for the options with the numbers X, e.g. `3` or `2,3`, only count with a comment. Needs `--comments`"
 	triggerID := model.NewId()
 	rootID := model.NewId()
+	// New polls store the channel and the thread they were posted in
+	inChannel := func(pl *poll.Poll) *poll.Poll {
+		pl = pl.Copy()
+		pl.ChannelID = "channelID1"
+		pl.RootID = rootID
+		return pl
+	}
 
 	createPollDialog := model.OpenDialogRequest{
 		TriggerId: triggerID,
@@ -44,6 +88,7 @@ func TestPluginExecuteCommand(t *testing.T) {
 				Name:        "option1",
 				Type:        "text",
 				SubType:     "text",
+				HelpText:    "Add an image to an option with the link to it after a |, e.g. Blue|https://example.org/blue.png",
 			}, {
 				DisplayName: "Option 2",
 				Name:        "option2",
@@ -55,31 +100,53 @@ func TestPluginExecuteCommand(t *testing.T) {
 				Type:        "text",
 				SubType:     "text",
 				Optional:    true,
+			}, {
+				DisplayName: "Option 4",
+				Name:        "option4",
+				Type:        "text",
+				SubType:     "text",
+				Optional:    true,
+			}, {
+				DisplayName: "Option 5",
+				Name:        "option5",
+				Type:        "text",
+				SubType:     "text",
+				Optional:    true,
 			}, {
 				DisplayName: "Number of Votes",
 				Name:        "setting-multi",
 				Type:        "text",
 				SubType:     "number",
 				Default:     "1",
-				HelpText:    "The number of options that an user can vote on.",
+				HelpText:    "The number of options that an user can vote on. Use 0 to allow any number of options.",
 				Optional:    false,
 			}, {
 				DisplayName: "Anonymous",
 				Name:        "setting-anonymous",
 				Type:        "bool",
 				Placeholder: "Don't show who voted for what when the poll ends",
+				Default:     "false",
 				Optional:    true,
 			}, {
 				DisplayName: "Progress",
 				Name:        "setting-progress",
 				Type:        "bool",
 				Placeholder: "During the poll, show how many votes each answer option got",
+				Default:     "false",
 				Optional:    true,
 			}, {
 				DisplayName: "Public Add Option",
 				Name:        "setting-public-add-option",
 				Type:        "bool",
 				Placeholder: "Allow all users to add additional options",
+				Default:     "false",
+				Optional:    true,
+			}, {
+				DisplayName: "Reveal Voters on End",
+				Name:        "setting-reveal-on-end",
+				Type:        "bool",
+				Placeholder: "Keep the poll anonymous while it runs, but show who voted for what when it ends",
+				Default:     "false",
 				Optional:    true,
 			}},
 			SubmitLabel: "Create",
@@ -87,11 +154,12 @@ func TestPluginExecuteCommand(t *testing.T) {
 	}
 
 	for name, test := range map[string]struct {
-		SetupAPI     func(*plugintest.API) *plugintest.API
-		SetupStore   func(*mockstore.Store) *mockstore.Store
-		Command      string
-		ExpectedText string
-		ShouldError  bool
+		SetupAPI      func(*plugintest.API) *plugintest.API
+		SetupStore    func(*mockstore.Store) *mockstore.Store
+		Configuration *configuration
+		Command       string
+		ExpectedText  string
+		ShouldError   bool
 	}{
 		"No argument": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -148,7 +216,9 @@ func TestPluginExecuteCommand(t *testing.T) {
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Insert", testutils.GetPollTwoOptions()).Return(nil)
+				poll := testutils.GetPollTwoOptions()
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\"", trigger),
@@ -200,7 +270,9 @@ func TestPluginExecuteCommand(t *testing.T) {
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Insert", testutils.GetPoll()).Return(nil)
+				poll := testutils.GetPoll()
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"", trigger),
@@ -231,7 +303,8 @@ func TestPluginExecuteCommand(t *testing.T) {
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				poll := testutils.GetPollWithSettings(poll.Settings{Progress: true, MaxVotes: 1})
-				store.PollStore.On("Insert", poll).Return(nil)
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --progress", trigger),
@@ -262,7 +335,8 @@ func TestPluginExecuteCommand(t *testing.T) {
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 3})
-				store.PollStore.On("Insert", poll).Return(nil)
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --votes=3", trigger),
@@ -293,15 +367,16 @@ func TestPluginExecuteCommand(t *testing.T) {
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				poll := testutils.GetPollWithSettings(poll.Settings{Progress: true, Anonymous: true, MaxVotes: 1})
-				store.PollStore.On("Insert", poll).Return(nil)
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --anonymous --progress", trigger),
 		},
-		"Store.Save fails": {
+		"With 4 arguments and default settings from the configuration": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
-				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
 
 				post := &model.Post{
 					UserId:    testutils.GetBotUserID(),
@@ -312,7 +387,8 @@ func TestPluginExecuteCommand(t *testing.T) {
 						"poll_id": testutils.GetPollID(),
 					},
 				}
-				actions := testutils.GetPoll().ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				poll := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 2})
+				actions := poll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
 				model.ParseSlackAttachment(post, actions)
 
 				rPost := post.Clone()
@@ -322,89 +398,1299 @@ func TestPluginExecuteCommand(t *testing.T) {
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				poll := testutils.GetPoll()
-				poll.PostID = "postID1"
-				store.PollStore.On("Insert", poll).Return(errors.New(""))
+				poll := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 2})
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
 				return store
 			},
-			Command:      fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"", trigger),
-			ExpectedText: commandErrorGeneric.Other,
+			Configuration: &configuration{DefaultMaxVotes: "2", DefaultAnonymous: true, DefaultProgress: true},
+			Command:       fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --no-progress", trigger),
 		},
-		"GetUser fails": {
+		"Question exceeds the configured maximum length": {
+			SetupAPI:      func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:    func(store *mockstore.Store) *mockstore.Store { return store },
+			Configuration: &configuration{MaxQuestionLength: "5"},
+			Command:       fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\"", trigger),
+			ShouldError:   true,
+		},
+		"Too many options for the configured maximum": {
+			SetupAPI:      func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:    func(store *mockstore.Store) *mockstore.Store { return store },
+			Configuration: &configuration{MaxAnswerOptions: "2"},
+			Command:       fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"", trigger),
+			ShouldError:   true,
+		},
+		"Rate limit for polls reached": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Take", "polls_userID1", 2, time.Hour).Return(false, nil)
+				return store
+			},
+			Configuration: &configuration{MaxPollsPerHour: "2"},
+			Command:       fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\"", trigger),
+			ExpectedText:  "You can only create 2 polls per hour. Please try again later.",
+		},
+		"Rate limit for polls, Take fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
 				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
 				return api
 			},
-			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
-			Command:      fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"", trigger),
-			ExpectedText: commandErrorGeneric.Other,
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Take", "polls_userID1", 2, time.Hour).Return(false, errors.New(""))
+				return store
+			},
+			Configuration: &configuration{MaxPollsPerHour: "2"},
+			Command:       fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\"", trigger),
+			ExpectedText:  commandErrorGeneric.Other,
 		},
-		"Invalid setting": {
-			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
-			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
-			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --unknownOption", trigger),
-			ShouldError: true,
+		"With voters setting": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2"}, nil)
+				api.On("GetUserByUsername", "user3").Return(&model.User{Id: "userID3"}, nil)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    rootID,
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
+				}
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Voters: "user2,user3"})
+				poll.SetAllowedVoters([]string{"userID2", "userID3"})
+				actions := poll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+
+				rPost := post.Clone()
+				rPost.Id = "postID1"
+
+				api.On("CreatePost", post).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Voters: "user2,user3"})
+				poll.SetAllowedVoters([]string{"userID2", "userID3"})
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --voters=@user2,@user3", trigger),
 		},
-		"Invalid multi setting, ": {
-			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
-			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
-			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --votes=4", trigger),
-			ShouldError: true,
+		"With weights setting": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2"}, nil)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    rootID,
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
+				}
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Weights: "user2:3"})
+				actions := poll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+
+				rPost := post.Clone()
+				rPost.Id = "postID1"
+
+				api.On("CreatePost", post).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Weights: "user2:3"})
+				poll.SetWeights(map[string]int{"userID2": 3})
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --weights=@user2:3", trigger),
 		},
-		"Invalid multi setting, invalid number": {
-			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+		"With weights setting, unknown user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(nil, &model.AppError{})
+				return api
+			},
 			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
-			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --votes=0", trigger),
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --weights=@user2:3", trigger),
 			ShouldError: true,
 		},
-		"Invalid multi setting, exceed number": {
-			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+		"With channels setting": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID2", model.PERMISSION_CREATE_POST).Return(true)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    rootID,
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
+				}
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Channels: "dev"})
+				actions := poll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+
+				rPost := post.Clone()
+				rPost.Id = "postID1"
+				api.On("CreatePost", post).Return(rPost, nil)
+
+				broadcastPost := post.Clone()
+				broadcastPost.ChannelId = "channelID2"
+				broadcastPost.RootId = ""
+				rBroadcastPost := broadcastPost.Clone()
+				rBroadcastPost.Id = "postID2"
+				api.On("CreatePost", broadcastPost).Return(rBroadcastPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Channels: "dev"})
+				poll.SetBroadcastChannels("channelID1", []string{"channelID2"})
+				poll.Broadcasts[0].PostID = "postID2"
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --channels=~dev", trigger),
+		},
+		"With channels setting, unknown channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(nil, &model.AppError{})
+				return api
+			},
 			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
-			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --votes=4", trigger),
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --channels=~dev", trigger),
 			ShouldError: true,
 		},
-		"Invalid multi setting, not number": {
-			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+		"With in setting": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID2", model.PERMISSION_CREATE_POST).Return(true)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID2",
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
+				}
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, In: "dev"})
+				actions := poll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+
+				rPost := post.Clone()
+				rPost.Id = "postID1"
+				api.On("CreatePost", post).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, In: "dev"})
+				poll.ModifiedAt = poll.CreatedAt
+				poll.ChannelID = "channelID2"
+				store.PollStore.On("Insert", poll).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --in=~dev", trigger),
+			ExpectedText: "Your poll has been posted in ~dev.",
+		},
+		"With dm setting": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2"}, nil)
+				api.On("HasPermissionTo", "userID1", model.PERMISSION_CREATE_DIRECT_CHANNEL).Return(true)
+				api.On("GetDirectChannel", "userID1", "userID2").Return(&model.Channel{Id: "directChannelID"}, nil)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "directChannelID",
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
+				}
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, DM: "user2"})
+				actions := poll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+
+				rPost := post.Clone()
+				rPost.Id = "postID1"
+				api.On("CreatePost", post).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, DM: "user2"})
+				poll.ModifiedAt = poll.CreatedAt
+				poll.ChannelID = "directChannelID"
+				store.PollStore.On("Insert", poll).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --dm=@user2", trigger),
+			ExpectedText: "Your poll has been sent to @user2.",
+		},
+		"With percentage quorum": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return([]*model.User{
+					{Id: "userID1"}, {Id: "userID2"}, {Id: "userID3"}, {Id: "botID", IsBot: true},
+				}, nil)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    rootID,
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
+				}
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quorum: 2, QuorumPercent: 60, HoldForQuorum: true})
+				actions := poll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+
+				rPost := post.Clone()
+				rPost.Id = "postID1"
+
+				api.On("CreatePost", post).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quorum: 2, QuorumPercent: 60, HoldForQuorum: true})
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --quorum=60%% --hold-for-quorum", trigger),
+		},
+		"With percentage quorum, GetUsersInChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(nil, &model.AppError{})
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
 			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
-			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --votes=abc", trigger),
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --quorum=60%%", trigger),
 			ShouldError: true,
 		},
-	} {
-		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
+		"Schedule meeting": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
 
-			api := test.SetupAPI(&plugintest.API{})
-			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-			if test.ExpectedText != "" {
-				ephemeralPost := &model.Post{
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
 					ChannelId: "channelID1",
 					RootId:    rootID,
-					UserId:    testutils.GetBotUserID(),
-					Message:   test.ExpectedText,
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
 				}
-				api.On("SendEphemeralPost", "userID1", ephemeralPost).Return(nil)
-			}
-			defer api.AssertExpectations(t)
-			store := test.SetupStore(&mockstore.Store{})
-			defer store.AssertExpectations(t)
-			p := setupTestPlugin(t, api, store)
-			p.configuration.Trigger = trigger
-
-			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
-			patch2 := monkey.Patch(model.NewId, testutils.GetPollID)
-			defer patch1.Unpatch()
-			defer patch2.Unpatch()
+				actions := getMeetingPoll(t).ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
 
-			r, err := p.ExecuteCommand(nil, &model.CommandArgs{
-				Command:   test.Command,
-				UserId:    "userID1",
-				ChannelId: "channelID1",
-				RootId:    rootID,
-				TriggerId: triggerID,
-			})
+				rPost := post.Clone()
+				rPost.Id = "postID1"
 
-			assert.Equal(&model.CommandResponse{}, r)
+				api.On("CreatePost", post).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := getMeetingPoll(t)
+				poll.PostID = "postID1"
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s schedule-meeting \"Question\" \"2021-10-01T15:00\" \"2021-10-02T15:00\" --invite", trigger),
+		},
+		"Schedule meeting, invalid slot": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s schedule-meeting \"Question\" \"2021-10-01T15:00\" \"tomorrow\"", trigger),
+			ExpectedText: "Invalid input: The option \"tomorrow\" is no time in UTC like \"2021-10-01T15:00\".",
+		},
+		"Schedule meeting, no slots": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s schedule-meeting", trigger),
+			ExpectedText: fmt.Sprintf("Use `/%s schedule-meeting \"Question\" \"2021-10-01T15:00\" \"2021-10-01T16:00\"` to find a date for a meeting. The times are in UTC. Add `--invite` to get an invite for the best slot when the poll ends.", trigger),
+		},
+		"With voters setting, unknown user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --voters=@user2", trigger),
+			ShouldError: true,
+		},
+		"Store.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    rootID,
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
+				}
+				actions := testutils.GetPoll().ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+
+				rPost := post.Clone()
+				rPost.Id = "postID1"
+
+				api.On("CreatePost", post).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.PostID = "postID1"
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"GetUser fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Invalid setting": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --unknownOption", trigger),
+			ShouldError: true,
+		},
+		"Invalid multi setting, ": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --votes=4", trigger),
+			ShouldError: true,
+		},
+		"Export command": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID"}, nil)
+				api.On("UploadFile", mock.AnythingOfType("[]uint8"), "dmChannelID", "poll-"+testutils.GetPollID()+".csv").Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s export %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseExportResultsSuccess.Other,
+		},
+		"Export command, poll not found": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s export pollID1", trigger),
+			ExpectedText: "The poll pollID1 could not be found. Only the results of running polls can be exported.",
+		},
+		"Export command, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.Creator = "userID2"
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s export %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseExportResultsInvalidPermission.Other,
+		},
+		"Transfer command": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.Type == MatterpollPostType
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				transferred := poll.Copy()
+				transferred.Creator = "userID2"
+				transferred.Version = 1
+				transferred.ModifiedAt = 1234567890
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
+				store.PollStore.On("Update", poll.Copy(), transferred).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user2", trigger, testutils.GetPollID()),
+			ExpectedText: "The poll **Question** has been transferred to @user2.",
+		},
+		"Transfer command, poll not found": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer pollID1 @user2", trigger),
+			ExpectedText: "The running poll pollID1 could not be found.",
+		},
+		"Transfer command, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.Creator = "userID2"
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user3", trigger, testutils.GetPollID()),
+			ExpectedText: "Only the creator of a poll and System Admins are allowed to transfer it.",
+		},
+		"Transfer command, unknown user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user2", trigger, testutils.GetPollID()),
+			ExpectedText: "The user @user2 could not be found.",
+		},
+		"Transfer command, same creator": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user1").Return(&model.User{Id: "userID1", Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s user1", trigger, testutils.GetPollID()),
+			ExpectedText: "The user already is the creator of the poll.",
+		},
+		"End command": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Update", mock.AnythingOfType("*poll.Poll"), mock.AnythingOfType("*poll.Poll")).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end pollID1", trigger),
+			ExpectedText: "The poll **Question** has been ended.",
+		},
+		"End command, ended poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.EndedAt = 1234567000
+				store.PollStore.On("Get", "pollID1").Return(poll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end pollID1", trigger),
+			ExpectedText: "The running poll pollID1 could not be found.",
+		},
+		"End command, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.Creator = "userID2"
+				store.PollStore.On("Get", "pollID1").Return(poll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end pollID1", trigger),
+			ExpectedText: responseEndPollInvalidPermission.Other,
+		},
+		"Results command": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s results pollID1", trigger),
+			ExpectedText: "#### Question\n" +
+				"- **Answer 1**: 3 votes: @user1, @user2 and @user3\n" +
+				"- **Answer 2**: 1 vote: @user4\n" +
+				"- **Answer 3**: 0 votes",
+		},
+		"Results command, secret poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Secret: true}), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s results pollID1", trigger),
+			ExpectedText: "#### Question\n- **Answer 1**\n- **Answer 2**\n- **Answer 3**",
+		},
+		"Results command, semi-anonymous poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Anonymous: true, SemiAnonymous: true}), nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s results pollID1", trigger),
+			ExpectedText: "#### Question\n" +
+				"- **Answer 1**: 3 votes: @user1, @user2 and @user3\n" +
+				"- **Answer 2**: 1 vote: @user4\n" +
+				"- **Answer 3**: 0 votes",
+		},
+		"Results command, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.Creator = "userID2"
+				store.PollStore.On("Get", "pollID1").Return(poll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s results pollID1", trigger),
+			ExpectedText: commandResultsInvalidPermission.Other,
+		},
+		"List command": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll1 := testutils.GetPollWithVotes()
+				poll1.ID = "pollID1"
+				poll2 := testutils.GetPoll()
+				poll2.ID = "pollID2"
+				poll2.PostID = "postID2"
+				poll2.CreatedAt = 1234567890 - 2*60*60*1000
+				poll3 := testutils.GetPollWithVotes()
+				poll3.ID = "pollID3"
+				poll3.Creator = "userID2"
+				poll4 := testutils.GetPoll()
+				poll4.ID = "pollID4"
+				poll4.EndedAt = 1234567890
+				store.PollStore.On("ListIDsByUser", "userID1", "").Return([]string{"pollID1", "pollID2", "pollID3", "pollID4"}, nil)
+				store.PollStore.On("Get", "pollID1").Return(poll1, nil)
+				store.PollStore.On("Get", "pollID2").Return(poll2, nil)
+				store.PollStore.On("Get", "pollID3").Return(poll3, nil)
+				store.PollStore.On("Get", "pollID4").Return(poll4, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s list", trigger),
+			ExpectedText: "Your running polls:\n" +
+				fmt.Sprintf("- `pollID2`: [**Question**](%s/_redirect/pl/postID2), created 2h ago, 0 voters\n", testutils.GetSiteURL()) +
+				fmt.Sprintf("- `pollID1`: [**Question**](%s/_redirect/pl/postID1), created 0m ago, 4 voters", testutils.GetSiteURL()),
+		},
+		"List command, no polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDsByUser", "userID1", "").Return([]string{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s list", trigger),
+			ExpectedText: commandListEmpty.Other,
+		},
+		"Reopen command": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.Type == MatterpollPostType && post.GetProp("poll_id") == testutils.GetPollID()
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ArchiveStore.On("Delete", "channelID1", testutils.GetPollID()).Return(nil)
+				poll := testutils.GetPoll()
+				poll.EndedAt = 1234567000
+				reopened := poll.Copy()
+				reopened.EndedAt = 0
+				reopened.Version = 1
+				reopened.ModifiedAt = 1234567890
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
+				store.PollStore.On("Update", poll.Copy(), reopened).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s reopen %s", trigger, testutils.GetPollID()),
+			ExpectedText: "The poll **Question** has been re-opened.",
+		},
+		"Reopen command, poll not found": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s reopen pollID1", trigger),
+			ExpectedText: "The poll pollID1 could not be found. Polls can only be re-opened shortly after they have ended.",
+		},
+		"Reopen command, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.Creator = "userID2"
+				poll.EndedAt = 1234567000
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s reopen %s", trigger, testutils.GetPollID()),
+			ExpectedText: "Only the creator of a poll and System Admins are allowed to re-open it.",
+		},
+		"Reopen command, grace period passed": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.EndedAt = 1234567890 - 10*60*1000
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s reopen %s", trigger, testutils.GetPollID()),
+			ExpectedText: "Polls can only be re-opened within 10 minutes after they have ended.",
+		},
+		"Reopen command, running poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s reopen %s", trigger, testutils.GetPollID()),
+			ExpectedText: "The poll is still running.",
+		},
+		"Scheduled poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ScheduledPollStore.On("Insert", mock.MatchedBy(func(s *poll.ScheduledPoll) bool {
+					return s.Poll.ID == testutils.GetPollID() && s.Poll.Settings.ScheduledAt == 1234567890+60*60*1000 &&
+						s.ChannelID == "channelID1" && s.RootID == rootID
+				})).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s \"Question\" --schedule=1h", trigger),
+			ExpectedText: fmt.Sprintf("Your poll will be posted at 1970-01-15T07:56 UTC. Use `/%s scheduled cancel %s` to cancel it.", trigger, testutils.GetPollID()),
+		},
+		"Scheduled list": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll1 := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: 1633100400000})
+				poll1.ID = "pollID1"
+				poll2 := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: 1633096800000})
+				poll2.ID = "pollID2"
+				poll3 := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: 1633096800000})
+				poll3.ID = "pollID3"
+				poll3.Creator = "userID2"
+				store.ScheduledPollStore.On("ListIDs").Return([]string{"pollID1", "pollID2", "pollID3"}, nil)
+				store.ScheduledPollStore.On("Get", "pollID1").Return(poll.NewScheduledPoll(poll1, "channelID1", ""), nil)
+				store.ScheduledPollStore.On("Get", "pollID2").Return(poll.NewScheduledPoll(poll2, "channelID1", ""), nil)
+				store.ScheduledPollStore.On("Get", "pollID3").Return(poll.NewScheduledPoll(poll3, "channelID1", ""), nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s scheduled list", trigger),
+			ExpectedText: "Your scheduled polls:\n" +
+				"- `pollID2`: **Question** at 2021-10-01T14:00 UTC\n" +
+				"- `pollID1`: **Question** at 2021-10-01T15:00 UTC",
+		},
+		"Scheduled list, no polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ScheduledPollStore.On("ListIDs").Return([]string{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled list", trigger),
+			ExpectedText: commandScheduledListEmpty.Other,
+		},
+		"Scheduled cancel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ScheduledPollStore.On("Get", "pollID1").Return(poll.NewScheduledPoll(testutils.GetPoll(), "channelID1", ""), nil)
+				store.ScheduledPollStore.On("Delete", "pollID1").Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled cancel pollID1", trigger),
+			ExpectedText: "The scheduled poll **Question** has been canceled.",
+		},
+		"Scheduled cancel, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				p := testutils.GetPoll()
+				p.Creator = "userID2"
+				store.ScheduledPollStore.On("Get", "pollID1").Return(poll.NewScheduledPoll(p, "channelID1", ""), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled cancel pollID1", trigger),
+			ExpectedText: commandScheduledCancelInvalidPermission.Other,
+		},
+		"Scheduled, invalid subcommand": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s scheduled remove pollID1", trigger),
+			ExpectedText: fmt.Sprintf("Use `/%[1]s scheduled list` to list your scheduled polls and `/%[1]s scheduled cancel <Poll ID>` to cancel one.", trigger),
+		},
+		"Template save": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TemplateStore.On("List", "userID1").Return([]*poll.Template{}, nil)
+				store.TemplateStore.On("Save", "userID1", &poll.Template{
+					Name:          "standup",
+					Creator:       "userID1",
+					Question:      "Question",
+					AnswerOptions: []string{"Answer 1", "Answer 2"},
+					Settings:      []string{"progress"},
+				}).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s template save standup \"Question\" \"Answer 1\" \"Answer 2\" --progress", trigger),
+			ExpectedText: fmt.Sprintf("The template `standup` has been saved. Use `/%s template use standup` to create a poll from it.", trigger),
+		},
+		"Template save, for the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TemplateStore.On("List", "channelID1").Return([]*poll.Template{}, nil)
+				store.TemplateStore.On("Save", "channelID1", &poll.Template{
+					Name:          "standup",
+					Creator:       "userID1",
+					Question:      "Question",
+					AnswerOptions: []string{"Yes", "No"},
+				}).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s template save standup \"Question\" --channel", trigger),
+			ExpectedText: fmt.Sprintf("The template `standup` has been saved. Use `/%s template use standup` to create a poll from it.", trigger),
+		},
+		"Template save, replace template of another user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TemplateStore.On("List", "channelID1").Return([]*poll.Template{{Name: "standup", Creator: "userID2"}}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s template save standup \"Question\" --channel", trigger),
+			ExpectedText: commandTemplateSaveInvalidPermission.Other,
+		},
+		"Template save, invalid setting": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s template save standup \"Question\" --unknown", trigger),
+			ExpectedText: "Invalid input: Unrecognized poll setting: unknown",
+		},
+		"Template list": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TemplateStore.On("List", "userID1").Return([]*poll.Template{{Name: "a", Question: "Question A"}, {Name: "b", Question: "Question B"}}, nil)
+				store.TemplateStore.On("List", "channelID1").Return([]*poll.Template{{Name: "c", Question: "Question C"}}, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s template list", trigger),
+			ExpectedText: "Your templates:\n" +
+				"- `a`: **Question A**\n" +
+				"- `b`: **Question B**\n" +
+				"Templates of this channel:\n" +
+				"- `c`: **Question C**",
+		},
+		"Template list, no templates": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TemplateStore.On("List", "userID1").Return([]*poll.Template{}, nil)
+				store.TemplateStore.On("List", "channelID1").Return([]*poll.Template{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s template list", trigger),
+			ExpectedText: commandTemplateListEmpty.Other,
+		},
+		"Template use, template of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    rootID,
+					Type:      MatterpollPostType,
+					Props: model.StringInterface{
+						"poll_id": testutils.GetPollID(),
+					},
+				}
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 3})
+				actions := poll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+
+				rPost := post.Clone()
+				rPost.Id = "postID1"
+
+				api.On("CreatePost", post).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TemplateStore.On("List", "userID1").Return([]*poll.Template{}, nil)
+				store.TemplateStore.On("List", "channelID1").Return([]*poll.Template{{
+					Name:          "standup",
+					Creator:       "userID2",
+					Question:      "Question",
+					AnswerOptions: []string{"Answer 1", "Answer 2", "Answer 3"},
+					Settings:      []string{"votes=3"},
+				}}, nil)
+
+				poll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 3})
+				poll.ModifiedAt = poll.CreatedAt
+				store.PollStore.On("Insert", inChannel(poll)).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s template use standup", trigger),
+		},
+		"Template use, template not found": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TemplateStore.On("List", "userID1").Return([]*poll.Template{}, nil)
+				store.TemplateStore.On("List", "channelID1").Return([]*poll.Template{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s template use standup", trigger),
+			ExpectedText: "The template `standup` could not be found.",
+		},
+		"Template, invalid subcommand": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s template remove standup", trigger),
+			ExpectedText: fmt.Sprintf("Use `/%[1]s template save <name> \"Question\" \"Answer 1\" \"Answer 2\"` to save a template, `/%[1]s template list` to list the templates and `/%[1]s template use <name>` to create a poll from one. Add `--channel` when saving a template to share it with everyone in the channel.", trigger),
+		},
+		"Admin list": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", DeleteAt: 1}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Name: "town-square"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll1 := testutils.GetPollWithVotes()
+				poll1.ID = "pollID1"
+				poll2 := testutils.GetPoll()
+				poll2.ID = "pollID2"
+				poll2.Creator = "userID2"
+				poll2.PostID = ""
+				poll2.CreatedAt = 1234567890 - 3*24*60*60*1000
+				poll3 := testutils.GetPoll()
+				poll3.ID = "pollID3"
+				poll3.EndedAt = 1234567890
+				store.PollStore.On("ListIDs").Return([]string{"pollID1", "pollID2", "pollID3"}, nil)
+				store.PollStore.On("Get", "pollID1").Return(poll1, nil)
+				store.PollStore.On("Get", "pollID2").Return(poll2, nil)
+				store.PollStore.On("Get", "pollID3").Return(poll3, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s admin list", trigger),
+			ExpectedText: "Running polls:\n" +
+				"- `pollID2`: **Question** by @user2 (deactivated) in unknown, created 3d ago, 0 voters\n" +
+				"- `pollID1`: **Question** by @user1 in ~town-square, created 0m ago, 4 voters",
+		},
+		"Admin list, no polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return([]string{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s admin list", trigger),
+			ExpectedText: commandAdminListEmpty.Other,
+		},
+		"Admin list, invalid permission": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s admin list", trigger),
+			ExpectedText: commandAdminInvalidPermission.Other,
+		},
+		"Admin end": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
+				poll := testutils.GetPoll()
+				poll.Creator = "userID2"
+				store.PollStore.On("Get", "pollID1").Return(poll, nil)
+				store.PollStore.On("Update", mock.AnythingOfType("*poll.Poll"), mock.AnythingOfType("*poll.Poll")).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s admin end pollID1", trigger),
+			ExpectedText: "The poll **Question** has been ended.",
+		},
+		"Admin end, poll not found": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s admin end pollID1", trigger),
+			ExpectedText: "The running poll pollID1 could not be found.",
+		},
+		"Admin delete": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+				api.On("DeletePost", "postID1").Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Delete", testutils.GetPoll()).Return(nil)
+				store.ArchiveStore.On("Delete", "channelID1", testutils.GetPollID()).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s admin delete pollID1", trigger),
+			ExpectedText: "The poll **Question** has been deleted.",
+		},
+		"Admin audit": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pl := testutils.GetPoll()
+				pl.Votes = []*poll.Vote{
+					{UserID: "userID1", Answer: 0, CreatedAt: 1234567890, RemovedAt: 1234627890},
+					{UserID: "userID2", Answer: 2, CreatedAt: 1234567890},
+					{UserID: "userID1", Answer: 1, CreatedAt: 1234627890},
+				}
+				store.PollStore.On("Get", "pollID1").Return(pl, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s admin audit pollID1", trigger),
+			ExpectedText: "Votes of the poll **Question** (times in UTC):\n" +
+				"- 1970-01-15T06:56: @user1 voted for **Answer 1**, removed at 1970-01-15T06:57\n" +
+				"- 1970-01-15T06:56: @user2 voted for **Answer 3**\n" +
+				"- 1970-01-15T06:57: @user1 voted for **Answer 2**",
+		},
+		"Admin audit, anonymous poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pl := testutils.GetPollWithSettings(poll.Settings{Anonymous: true})
+				pl.Votes = []*poll.Vote{
+					{UserID: "hashed_1", Answer: 0, CreatedAt: 1234567890},
+					{UserID: "hashed_2", Answer: 1, CreatedAt: 1234567890},
+					{UserID: "hashed_1", Answer: 1, CreatedAt: 1234627890},
+				}
+				store.PollStore.On("Get", "pollID1").Return(pl, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s admin audit pollID1", trigger),
+			ExpectedText: "Votes of the poll **Question** (times in UTC):\n" +
+				"- 1970-01-15T06:56: Anonymous voter 1 voted for **Answer 1**\n" +
+				"- 1970-01-15T06:56: Anonymous voter 2 voted for **Answer 2**\n" +
+				"- 1970-01-15T06:57: Anonymous voter 1 voted for **Answer 2**",
+		},
+		"Admin audit, running secret poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPollWithSettings(poll.Settings{Secret: true}), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s admin audit pollID1", trigger),
+			ExpectedText: "The votes of the secret poll **Question** can only be audited after it has ended.",
+		},
+		"Admin audit, no votes recorded": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s admin audit pollID1", trigger),
+			ExpectedText: "No votes have been recorded for the poll **Question**.",
+		},
+		"Admin prune": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				api.On("GetPost", "postID1").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+				store.PollStore.On("Get", "pollID1").Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Delete", testutils.GetPoll()).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s admin prune", trigger),
+			ExpectedText: "Deleted 0 ended polls and 1 polls whose post has been deleted.",
+		},
+		"Admin, invalid subcommand": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s admin remove pollID1", trigger),
+			ExpectedText: fmt.Sprintf("Use `/%[1]s admin list` to list all running polls, `/%[1]s admin end <Poll ID>` to end one, `/%[1]s admin delete <Poll ID>` to delete one, `/%[1]s admin audit <Poll ID>` to see when its votes were cast and `/%[1]s admin prune` to delete polls that aren't needed anymore.", trigger),
+		},
+		"History": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ArchiveStore.On("ListByChannel", "channelID1").Return([]*poll.ArchivedPoll{
+					{ID: "pollID2", Question: "Lunch?", ClosedAt: 1234627890, Answers: []string{"Pizza", "Sushi"}, Votes: []int{3, 1}, Voters: 4},
+					{ID: "pollID1", Question: "Date?", ClosedAt: 1234567890, Answers: []string{"Monday", "Friday"}, Votes: []int{1, 1}, Voters: 2},
+					{ID: "pollID0", Question: "Name?", ClosedAt: 1234567890, Answers: []string{"A", "B"}, Votes: []int{0, 0}},
+				}, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s history", trigger),
+			ExpectedText: "Polls that have ended in this channel (times in UTC):\n" +
+				"- 1970-01-15T06:57: **Lunch?**: **Pizza** with 3 votes, 4 voters in total\n" +
+				"- 1970-01-15T06:56: **Date?**: **Monday, Friday** with 1 vote, 2 voters in total\n" +
+				"- 1970-01-15T06:56: **Name?**: nobody voted",
+		},
+		"History, more pages": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				archivedPolls := []*poll.ArchivedPoll{}
+				for i := 0; i < 2*historyPageSize+1; i++ {
+					archivedPolls = append(archivedPolls, &poll.ArchivedPoll{Question: fmt.Sprintf("Question %d", i), ClosedAt: 1234567890, Answers: []string{}, Votes: []int{}})
+				}
+				store.ArchiveStore.On("ListByChannel", "channelID1").Return(archivedPolls, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s history 2", trigger),
+			ExpectedText: func() string {
+				lines := []string{"Polls that have ended in this channel (times in UTC):"}
+				for i := historyPageSize; i < 2*historyPageSize; i++ {
+					lines = append(lines, fmt.Sprintf("- 1970-01-15T06:56: **Question %d**: nobody voted", i))
+				}
+				lines = append(lines, fmt.Sprintf("Use `/%s history 3` to see older polls.", trigger))
+				return strings.Join(lines, "\n")
+			}(),
+		},
+		"History, page out of range": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ArchiveStore.On("ListByChannel", "channelID1").Return([]*poll.ArchivedPoll{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s history 2", trigger),
+			ExpectedText: "No polls have ended in this channel yet.",
+		},
+		"History, invalid page": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s history last", trigger),
+			ExpectedText: fmt.Sprintf("Use `/%[1]s history` to list the polls that have ended in this channel and `/%[1]s history <Page>` to see older ones.", trigger),
+		},
+		"History, ListByChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ArchiveStore.On("ListByChannel", "channelID1").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s history", trigger),
+			ExpectedText: "Something went wrong. Please try again later.",
+		},
+		"Digest": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDsByChannel", "channelID1").Return([]string{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s digest", trigger),
+			ExpectedText: "There are no running polls in this channel.",
+		},
+		"Search": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDsByKeywords", []string{"lunch"}).Return([]string{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s search lunch", trigger),
+			ExpectedText: "No polls match your search.",
+		},
+		"Vote, without answer options": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s vote %s", trigger, testutils.GetPollID()),
+			ExpectedText: fmt.Sprintf("Use `/%[1]s vote <Poll ID> <option numbers>` to vote, e.g. `/%[1]s vote <Poll ID> 2` for the second answer option. Several numbers like `1,3` vote for several answer options, if the poll allows it.", trigger),
+		},
+		"My data, export": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID"}, nil)
+				api.On("UploadFile", mock.AnythingOfType("[]uint8"), "dmChannelID", "matterpoll-data.json").Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "dmChannelID" && post.FileIds[0] == "fileID1"
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDsByUser", "userID1", "").Return([]string{testutils.GetPollID()}, nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s my-data export", trigger),
+			ExpectedText: "Your data has been sent to you as a direct message.",
+		},
+		"My data, export, ListIDsByUser fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDsByUser", "userID1", "").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s my-data export", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"My data, delete": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.Creator = "userID2"
+				poll.SetVoters(0, "userID1", "userID2")
+				erased := poll.Copy()
+				erased.SetVoters(0, "erased_"+testutils.GetPollID(), "userID2")
+				erased.Version = 1
+				erased.ModifiedAt = 1234567890
+				store.PollStore.On("ListIDsByUser", "userID1", "").Return([]string{testutils.GetPollID()}, nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
+				store.PollStore.On("Update", poll.Copy(), erased).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s my-data delete", trigger),
+			ExpectedText: "Your user ID has been removed from 1 poll. Your votes still count, but can't be linked to you anymore.",
+		},
+		"My data, delete, Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPoll()
+				poll.SetVoters(0, "userID1")
+				store.PollStore.On("ListIDsByUser", "userID1", "").Return([]string{testutils.GetPollID()}, nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll, nil)
+				store.PollStore.On("Update", mock.AnythingOfType("*poll.Poll"), mock.AnythingOfType("*poll.Poll")).Return(errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s my-data delete", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"My data, invalid subcommand": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s my-data", trigger),
+			ExpectedText: fmt.Sprintf("Use `/%[1]s my-data export` to get the data polls store about you and `/%[1]s my-data delete` to remove your user ID from all polls.", trigger),
+		},
+		"Invalid multi setting, exceed number": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --votes=4", trigger),
+			ShouldError: true,
+		},
+		"Invalid multi setting, not number": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --votes=abc", trigger),
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+			if test.ExpectedText != "" {
+				ephemeralPost := &model.Post{
+					ChannelId: "channelID1",
+					RootId:    rootID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   test.ExpectedText,
+				}
+				api.On("SendEphemeralPost", "userID1", ephemeralPost).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			if test.Configuration != nil {
+				p.setConfiguration(test.Configuration)
+			}
+			p.configuration.Trigger = trigger
+			p.configuration.ReopenGracePeriod = "10"
+
+			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			patch2 := monkey.Patch(model.NewId, testutils.GetPollID)
+			defer patch1.Unpatch()
+			defer patch2.Unpatch()
+
+			r, err := p.ExecuteCommand(nil, &model.CommandArgs{
+				Command:   test.Command,
+				UserId:    "userID1",
+				ChannelId: "channelID1",
+				RootId:    rootID,
+				TriggerId: triggerID,
+			})
+
+			assert.Equal(&model.CommandResponse{}, r)
 			if test.ShouldError {
 				assert.NotNil(err)
 			} else {
@@ -413,3 +1699,74 @@ func TestPluginExecuteCommand(t *testing.T) {
 		})
 	}
 }
+
+// getMeetingPoll returns the meeting poll created by the schedule-meeting command in TestPluginExecuteCommand.
+func getMeetingPoll(t *testing.T) *poll.Poll {
+	p, errMsg := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "2021-10-02T15:00"}, poll.Settings{MaxVotes: 1, Invite: true}, poll.Limits{})
+	require.Nil(t, errMsg)
+	p.ID = testutils.GetPollID()
+	p.CreatedAt = 1234567890
+	p.ModifiedAt = 1234567890
+	return p
+}
+
+func TestGetAutocompleteData(t *testing.T) {
+	data := getAutocompleteData("poll", testutils.GetLocalizer())
+
+	assert.Equal(t, "poll", data.Trigger)
+	triggers := map[string]*model.AutocompleteData{}
+	for _, command := range data.SubCommands {
+		triggers[command.Trigger] = command
+	}
+	for _, trigger := range []string{"help", commandList, commandDigest, commandSearch, commandVote, commandEnd, commandResults, commandExport, commandReopen, commandTransfer,
+		commandScheduled, commandScheduleMeeting, commandTemplate, commandMyData, commandAdmin} {
+		assert.Contains(t, triggers, trigger)
+	}
+	assert.Len(t, triggers[commandEnd].Arguments, 1)
+	assert.Len(t, triggers[commandTransfer].Arguments, 2)
+	assert.Equal(t, model.SYSTEM_ADMIN_ROLE_ID, triggers[commandAdmin].RoleID)
+	assert.Contains(t, triggers["help"].HelpText, "--anonymous")
+	assert.Contains(t, triggers["help"].HelpText, "--votes=X")
+}
+
+func TestPluginResolveLocale(t *testing.T) {
+	p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+	require.NoError(t, p.bundle.AddMessages(language.German, &i18n.Message{ID: "test", Other: "Test"}))
+
+	for name, test := range map[string]struct {
+		Locale         string
+		ExpectedLocale string
+		ExpectedError  *poll.ErrorMessage
+	}{
+		"no locale": {
+			Locale:         "",
+			ExpectedLocale: "",
+		},
+		"supported locale": {
+			Locale:         "de",
+			ExpectedLocale: "de",
+		},
+		"supported locale, different case": {
+			Locale:         "EN",
+			ExpectedLocale: "en",
+		},
+		"unsupported locale": {
+			Locale: "xx",
+			ExpectedError: &poll.ErrorMessage{
+				Message: commandErrorUnsupportedLocale,
+				Data:    map[string]interface{}{"Locale": "xx"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pl := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Locale: test.Locale})
+			errMsg := p.resolveLocale(pl)
+			if test.ExpectedError != nil {
+				assert.Equal(t, test.ExpectedError, errMsg)
+				return
+			}
+			require.Nil(t, errMsg)
+			assert.Equal(t, test.ExpectedLocale, pl.Settings.Locale)
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package plugin
 
 import (
+	"net/url"
+	"strconv"
+	"time"
+
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
 )
 
 // configuration captures the plugin's external configuration as exposed in the Mattermost server
@@ -11,6 +17,111 @@ import (
 type configuration struct {
 	Trigger        string `json:"trigger"`
 	ExperimentalUI bool   `json:"experimentalui"`
+	// ReopenGracePeriod is the number of minutes in which an ended poll can be re-opened. Empty or "0" disables it.
+	ReopenGracePeriod string `json:"reopengraceperiod"`
+	// RetentionDays is the number of days ended polls are kept in the store, see endedPollLifetime.
+	// Empty or "0" means they are deleted as soon as they can't be re-opened anymore.
+	RetentionDays string `json:"retentiondays"`
+	// DefaultMaxVotes, DefaultAnonymous and DefaultProgress are the settings of new polls, unless their creator
+	// specifies otherwise. An empty DefaultMaxVotes means one vote.
+	DefaultMaxVotes  string `json:"defaultmaxvotes"`
+	DefaultAnonymous bool   `json:"defaultanonymous"`
+	DefaultProgress  bool   `json:"defaultprogress"`
+	// MaxAnswerOptions, MaxQuestionLength and MaxAnswerLength limit the size of new polls. Empty means the limits
+	// of the poll package.
+	MaxAnswerOptions  string `json:"maxansweroptions"`
+	MaxQuestionLength string `json:"maxquestionlength"`
+	MaxAnswerLength   string `json:"maxanswerlength"`
+	// BannedCharacters contains the characters the questions and answer options of new polls must not contain.
+	BannedCharacters string `json:"bannedcharacters"`
+	// ResultsBarStyle is the style of the bars that show the share of votes of every answer option, e.g. poll.BarStyleBlocks.
+	// Empty means no bars.
+	ResultsBarStyle string `json:"resultsbarstyle"`
+	// VoterHashKey is the secret key the voters of anonymous polls are hashed with, see poll.Poll.SetVoterKey.
+	// It's generated on activation, if it's empty.
+	VoterHashKey string `json:"voterhashkey"`
+	// ResultsWebhookURL is the URL the results of every poll are posted to when it ends, see sendResultsWebhook.
+	// Empty means the results aren't sent anywhere.
+	ResultsWebhookURL string `json:"resultswebhookurl"`
+	// MetricsToken is the bearer token Prometheus has to send to scrape the metrics, see handleMetrics.
+	// Empty means the metrics endpoint is disabled.
+	MetricsToken string `json:"metricstoken"`
+	// EnableAuditLog records the creation, end and deletion of polls and the answer options added to them in the
+	// audit log, see newAuditRecord.
+	EnableAuditLog bool `json:"enableauditlog"`
+	// MaxPollsPerHour limits the number of polls a user can create per hour and MaxAddedOptionsPerUser the number
+	// of answer options a user can add to a poll of another user, see allowPollCreation and addedOptionLimitReached.
+	// Empty or "0" means no limit.
+	MaxPollsPerHour        string `json:"maxpollsperhour"`
+	MaxAddedOptionsPerUser string `json:"maxaddedoptionsperuser"`
+	// ThreadResults posts the results of every poll as a pinned reply in its thread when it ends,
+	// as if all polls used poll.Settings.ThreadResults.
+	ThreadResults bool `json:"threadresults"`
+	// DailyDigest sends every user a direct message once a day listing the running polls they haven't voted in,
+	// see sendDigests.
+	DailyDigest bool `json:"dailydigest"`
+}
+
+// pollDefaults returns the settings new polls start with.
+func (c *configuration) pollDefaults() poll.Settings {
+	maxVotes, _ := strconv.Atoi(c.DefaultMaxVotes)
+	return poll.Settings{
+		Anonymous: c.DefaultAnonymous,
+		Progress:  c.DefaultProgress,
+		MaxVotes:  maxVotes,
+	}
+}
+
+// pollLimits returns the limits of new polls.
+func (c *configuration) pollLimits() poll.Limits {
+	maxAnswerOptions, _ := strconv.Atoi(c.MaxAnswerOptions)
+	maxQuestionLength, _ := strconv.Atoi(c.MaxQuestionLength)
+	maxAnswerLength, _ := strconv.Atoi(c.MaxAnswerLength)
+	return poll.Limits{
+		MaxAnswerOptions:  maxAnswerOptions,
+		MaxQuestionLength: maxQuestionLength,
+		MaxAnswerLength:   maxAnswerLength,
+		BannedCharacters:  c.BannedCharacters,
+	}
+}
+
+// maxPollsPerHour returns the number of polls a user can create per hour. Zero means no limit.
+func (c *configuration) maxPollsPerHour() int {
+	limit, _ := strconv.Atoi(c.MaxPollsPerHour)
+	return limit
+}
+
+// maxAddedOptionsPerUser returns the number of answer options a user can add to a poll of another user. Zero means no limit.
+func (c *configuration) maxAddedOptionsPerUser() int {
+	limit, _ := strconv.Atoi(c.MaxAddedOptionsPerUser)
+	return limit
+}
+
+// reopenGracePeriod returns the duration in which an ended poll can be re-opened. Zero means polls can't be re-opened.
+func (c *configuration) reopenGracePeriod() time.Duration {
+	minutes, err := strconv.Atoi(c.ReopenGracePeriod)
+	if err != nil || minutes < 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// retentionPeriod returns the duration ended polls are kept in the store. Zero means they aren't kept.
+func (c *configuration) retentionPeriod() time.Duration {
+	days, err := strconv.Atoi(c.RetentionDays)
+	if err != nil || days < 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// endedPollLifetime returns the duration after which an ended poll gets deleted, which is the longer one
+// of the grace period for re-opening and the retention period. Zero means ended polls are deleted right away.
+func (c *configuration) endedPollLifetime() time.Duration {
+	if retention := c.retentionPeriod(); retention > c.reopenGracePeriod() {
+		return retention
+	}
+	return c.reopenGracePeriod()
 }
 
 // OnConfigurationChange loads the plugin configuration, validates it and saves it.
@@ -27,6 +138,67 @@ func (p *MatterpollPlugin) OnConfigurationChange() error {
 		return errors.New("empty trigger not allowed")
 	}
 
+	if configuration.ReopenGracePeriod != "" {
+		if minutes, err := strconv.Atoi(configuration.ReopenGracePeriod); err != nil || minutes < 0 {
+			return errors.New("grace period for re-opening polls must be a non-negative number of minutes")
+		}
+	}
+
+	if configuration.RetentionDays != "" {
+		if days, err := strconv.Atoi(configuration.RetentionDays); err != nil || days < 0 {
+			return errors.New("retention period of ended polls must be a non-negative number of days")
+		}
+	}
+
+	maxAnswerOptions := poll.MaxAnswerOptions
+	if configuration.MaxAnswerOptions != "" {
+		max, err := strconv.Atoi(configuration.MaxAnswerOptions)
+		if err != nil || max < poll.MinAnswerOptions {
+			return errors.Errorf("maximum number of options must be a number of at least %d", poll.MinAnswerOptions)
+		}
+		maxAnswerOptions = max
+	}
+
+	if configuration.DefaultMaxVotes != "" {
+		if maxVotes, err := strconv.Atoi(configuration.DefaultMaxVotes); err != nil || maxVotes <= 0 || maxVotes > maxAnswerOptions {
+			return errors.New("default number of votes must be a positive number and not exceed the maximum number of options")
+		}
+	}
+
+	if configuration.MaxQuestionLength != "" {
+		if length, err := strconv.Atoi(configuration.MaxQuestionLength); err != nil || length <= 0 {
+			return errors.New("maximum question length must be a positive number")
+		}
+	}
+
+	if configuration.MaxAnswerLength != "" {
+		if length, err := strconv.Atoi(configuration.MaxAnswerLength); err != nil || length <= 0 {
+			return errors.New("maximum option length must be a positive number")
+		}
+	}
+
+	if configuration.MaxPollsPerHour != "" {
+		if limit, err := strconv.Atoi(configuration.MaxPollsPerHour); err != nil || limit < 0 {
+			return errors.New("maximum number of polls per hour must be a non-negative number")
+		}
+	}
+
+	if configuration.MaxAddedOptionsPerUser != "" {
+		if limit, err := strconv.Atoi(configuration.MaxAddedOptionsPerUser); err != nil || limit < 0 {
+			return errors.New("maximum number of added options per user must be a non-negative number")
+		}
+	}
+
+	if configuration.ResultsBarStyle != "" && !poll.IsBarStyle(configuration.ResultsBarStyle) {
+		return errors.Errorf("unknown bar style: %s", configuration.ResultsBarStyle)
+	}
+
+	if configuration.ResultsWebhookURL != "" {
+		if u, err := url.Parse(configuration.ResultsWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("results webhook URL must be an http or https URL")
+		}
+	}
+
 	// This require a loaded i18n bundle
 	if p.isActivated() {
 		command, err := p.getCommand(configuration.Trigger)
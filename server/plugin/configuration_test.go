@@ -3,12 +3,14 @@ package plugin
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 )
@@ -19,6 +21,7 @@ func TestOnConfigurationChange(t *testing.T) {
 		AutoComplete:         true,
 		AutoCompleteDesc:     "Create a poll",
 		AutoCompleteHint:     `"[Question]" "[Answer 1]" "[Answer 2]"...`,
+		AutocompleteData:     getAutocompleteData("poll", testutils.GetLocalizer()),
 		AutocompleteIconData: "someIconData",
 	}
 
@@ -94,6 +97,133 @@ func TestOnConfigurationChange(t *testing.T) {
 			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
 			ShouldError:           true,
 		},
+		"Load invalid grace period": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.ReopenGracePeriod = "ten"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
+		"Load invalid retention period": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.RetentionDays = "-1"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
+		"Load invalid default number of votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.DefaultMaxVotes = "0"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
+		"Load default number of votes exceeding the maximum number of options": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.DefaultMaxVotes = "6"
+					arg.MaxAnswerOptions = "5"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
+		"Load invalid maximum number of options": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.MaxAnswerOptions = "1"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
+		"Load invalid maximum question length": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.MaxQuestionLength = "-1"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
+		"Load invalid maximum option length": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.MaxAnswerLength = "0"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
+		"Load invalid bar style": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.ResultsBarStyle = "circles"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
+		"Load invalid results webhook URL": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.ResultsWebhookURL = "ftp://example.org/results"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger", ExperimentalUI: false},
+			ShouldError:           true,
+		},
 		"UnregisterCommand fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetConfig").Return(testutils.GetServerConfig())
@@ -193,6 +323,30 @@ func TestConfiguration(t *testing.T) {
 		})
 	})
 
+	t.Run("poll defaults and limits", func(t *testing.T) {
+		config := &configuration{
+			DefaultMaxVotes:   "2",
+			DefaultAnonymous:  true,
+			DefaultProgress:   true,
+			MaxAnswerOptions:  "10",
+			MaxQuestionLength: "100",
+			MaxAnswerLength:   "50",
+			BannedCharacters:  "<>",
+		}
+
+		assert.Equal(t, poll.Settings{Anonymous: true, Progress: true, MaxVotes: 2}, config.pollDefaults())
+		assert.Equal(t, poll.Limits{MaxAnswerOptions: 10, MaxQuestionLength: 100, MaxAnswerLength: 50, BannedCharacters: "<>"}, config.pollLimits())
+		assert.Equal(t, poll.Settings{}, (&configuration{}).pollDefaults())
+		assert.Equal(t, poll.Limits{}, (&configuration{}).pollLimits())
+	})
+
+	t.Run("ended poll lifetime", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), (&configuration{}).endedPollLifetime())
+		assert.Equal(t, 10*time.Minute, (&configuration{ReopenGracePeriod: "10", RetentionDays: "0"}).endedPollLifetime())
+		assert.Equal(t, 48*time.Hour, (&configuration{ReopenGracePeriod: "10", RetentionDays: "2"}).endedPollLifetime())
+		assert.Equal(t, 72*time.Hour, (&configuration{ReopenGracePeriod: "4320", RetentionDays: "1"}).endedPollLifetime())
+	})
+
 	t.Run("clearing configuration", func(t *testing.T) {
 		plugin := &MatterpollPlugin{}
 		config := &configuration{Trigger: "poll"}
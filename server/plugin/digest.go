@@ -0,0 +1,282 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+var (
+	commandDigestUsage = &i18n.Message{
+		ID:    "command.digest.usage",
+		Other: "Use `/{{.Trigger}} digest` to list the running polls of this channel and `/{{.Trigger}} digest team` to list the running polls of all your channels in this team.",
+	}
+	commandDigestEmptyChannel = &i18n.Message{
+		ID:    "command.digest.emptyChannel",
+		Other: "There are no running polls in this channel.",
+	}
+	commandDigestEmptyTeam = &i18n.Message{
+		ID:    "command.digest.emptyTeam",
+		Other: "There are no running polls in your channels of this team.",
+	}
+	commandDigestHeaderChannel = &i18n.Message{
+		ID:    "command.digest.headerChannel",
+		Other: "Running polls in this channel:",
+	}
+	commandDigestHeaderTeam = &i18n.Message{
+		ID:    "command.digest.headerTeam",
+		Other: "Running polls in your channels of this team:",
+	}
+	digestEntry = &i18n.Message{
+		ID:    "digest.entry",
+		Other: "- [**{{.Question}}**]({{.Link}}): {{.Voted}} of {{.Eligible}} members have voted, ends in {{.Remaining}}",
+	}
+	digestEntryNoEnd = &i18n.Message{
+		ID:    "digest.entryNoEnd",
+		Other: "- [**{{.Question}}**]({{.Link}}): {{.Voted}} of {{.Eligible}} members have voted",
+	}
+	digestNotVoted = &i18n.Message{
+		ID:    "digest.notVoted",
+		Other: "(you haven't voted yet)",
+	}
+	digestMessageHeader = &i18n.Message{
+		ID:    "digest.message.header",
+		One:   "You haven't voted in this poll yet:",
+		Other: "You haven't voted in these {{.Count}} polls yet:",
+	}
+	digestMessageEntry = &i18n.Message{
+		ID:    "digest.message.entry",
+		Other: "- [**{{.Question}}**]({{.Link}}), ends in {{.Remaining}}",
+	}
+	digestMessageEntryNoEnd = &i18n.Message{
+		ID:    "digest.message.entryNoEnd",
+		Other: "- [**{{.Question}}**]({{.Link}})",
+	}
+)
+
+// channelPoll is a poll listed in a digest or search result, together with the channel it's listed for.
+type channelPoll struct {
+	poll      *poll.Poll
+	channelID string
+}
+
+// link returns the link to the post of the poll in the channel it's listed for.
+func (d *channelPoll) link(siteURL string) string {
+	return fmt.Sprintf("%s/_redirect/pl/%s", siteURL, d.poll.PostIDInChannel(d.channelID))
+}
+
+// sortDigestPolls orders polls by the time they end, soonest first. Polls without end time follow, oldest first.
+func sortDigestPolls(polls []*channelPoll) {
+	sort.SliceStable(polls, func(i, j int) bool {
+		a, b := polls[i].poll, polls[j].poll
+		if (a.Settings.EndTime > 0) != (b.Settings.EndTime > 0) {
+			return a.Settings.EndTime > 0
+		}
+		if a.Settings.EndTime != b.Settings.EndTime {
+			return a.Settings.EndTime < b.Settings.EndTime
+		}
+		return a.CreatedAt < b.CreatedAt
+	})
+}
+
+// executeDigestCommand returns a message listing the running polls of a channel, or of all channels of its team
+// the user is a member of, with the time they have left and how many members have voted.
+func (p *MatterpollPlugin) executeDigestCommand(args []string, channelID, teamID, userID, trigger string, userLocalizer *i18n.Localizer) string {
+	var channelIDs []string
+	empty, header := commandDigestEmptyChannel, commandDigestHeaderChannel
+	switch {
+	case len(args) == 0:
+		channelIDs = []string{channelID}
+	case len(args) == 1 && args[0] == "team":
+		channels, appErr := p.API.GetChannelsForTeamForUser(teamID, userID, false)
+		if appErr != nil {
+			p.API.LogWarn("failed to get channels of user", "teamID", teamID, "userID", userID, "error", appErr.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+		}
+		for _, c := range channels {
+			channelIDs = append(channelIDs, c.Id)
+		}
+		empty, header = commandDigestEmptyTeam, commandDigestHeaderTeam
+	default:
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandDigestUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		})
+	}
+
+	polls, err := p.runningPollsInChannels(channelIDs)
+	if err != nil {
+		p.API.LogWarn("failed to list running polls", "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if len(polls) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, empty)
+	}
+
+	sortDigestPolls(polls)
+	now := model.GetMillis()
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	lines := []string{p.LocalizeDefaultMessage(userLocalizer, header)}
+	for _, d := range polls {
+		nonVoters, eligible, err := p.channelParticipation(d.poll, d.channelID)
+		if err != nil {
+			p.API.LogWarn("failed to get participation", "pollID", d.poll.ID, "error", err.Error())
+			continue
+		}
+
+		message := digestEntryNoEnd
+		if d.poll.Settings.EndTime > 0 {
+			message = digestEntry
+		}
+		line := p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: message,
+			TemplateData: map[string]interface{}{
+				"Question":  d.poll.QuestionLinkText(),
+				"Link":      d.link(siteURL),
+				"Voted":     eligible - len(nonVoters),
+				"Eligible":  eligible,
+				"Remaining": formatAge(d.poll.Settings.EndTime - now),
+			},
+		})
+		if d.poll.CanVote(userID) && len(d.poll.GetVotedAnswers(userID)) == 0 {
+			line += " " + p.LocalizeDefaultMessage(userLocalizer, digestNotVoted)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runningPollsInChannels returns the polls that are running in some channels. A poll broadcast to several of them
+// is listed once, for the first of its channels. Polls that can't be read are logged and skipped.
+func (p *MatterpollPlugin) runningPollsInChannels(channelIDs []string) ([]*channelPoll, error) {
+	var polls []*channelPoll
+	seen := map[string]bool{}
+	for _, channelID := range channelIDs {
+		ids, err := p.Store.Poll().ListIDsByChannel(channelID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list polls of channel")
+		}
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			pl, err := p.getPoll(id)
+			if err != nil {
+				// The index may contain polls that have been deleted
+				if !errors.Is(err, store.ErrNotFound) {
+					p.API.LogWarn("failed to get poll", "pollID", id, "error", err.Error())
+				}
+				continue
+			}
+			seen[id] = true
+			if pl.PostID != "" && !pl.HasEnded() {
+				polls = append(polls, &channelPoll{poll: pl, channelID: channelID})
+			}
+		}
+	}
+	return polls, nil
+}
+
+// sendDigests sends every user a direct message listing the running polls they may vote in, but haven't voted in yet,
+// if the daily digest is enabled. Only members of the channels the polls are posted in are considered.
+func (p *MatterpollPlugin) sendDigests() {
+	if !p.getConfiguration().DailyDigest {
+		return
+	}
+
+	ids, err := p.Store.Poll().ListIDs()
+	if err != nil {
+		p.API.LogWarn("Failed to list polls", "error", err.Error())
+		return
+	}
+
+	pending := map[string][]*channelPoll{}
+	var userIDs []string
+	for _, id := range ids {
+		pl, err := p.getPoll(id)
+		if err != nil {
+			p.API.LogWarn("Failed to get poll", "pollID", id, "error", err.Error())
+			continue
+		}
+		if pl.PostID == "" || pl.HasEnded() {
+			continue
+		}
+
+		listed := map[string]bool{}
+		for _, post := range pl.Posts() {
+			if post.ChannelID == "" {
+				continue
+			}
+			nonVoters, err := p.channelNonVoters(pl, post.ChannelID)
+			if err != nil {
+				p.API.LogWarn("Failed to get users who haven't voted", "pollID", id, "channelID", post.ChannelID, "error", err.Error())
+				continue
+			}
+			for _, user := range nonVoters {
+				if listed[user.Id] {
+					continue
+				}
+				listed[user.Id] = true
+				if _, ok := pending[user.Id]; !ok {
+					userIDs = append(userIDs, user.Id)
+				}
+				pending[user.Id] = append(pending[user.Id], &channelPoll{poll: pl, channelID: post.ChannelID})
+			}
+		}
+	}
+
+	for _, userID := range userIDs {
+		if err := p.sendDigest(userID, pending[userID]); err != nil {
+			p.API.LogWarn("Failed to send digest", "userID", userID, "error", err.Error())
+		}
+	}
+}
+
+// sendDigest sends a user a direct message from the bot listing polls they haven't voted in yet.
+func (p *MatterpollPlugin) sendDigest(userID string, polls []*channelPoll) error {
+	channel, appErr := p.API.GetDirectChannel(userID, p.botUserID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get direct channel")
+	}
+
+	sortDigestPolls(polls)
+	userLocalizer := p.getUserLocalizer(userID)
+	now := model.GetMillis()
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	lines := []string{p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: digestMessageHeader,
+		TemplateData:   map[string]interface{}{"Count": len(polls)},
+		PluralCount:    len(polls),
+	})}
+	for _, d := range polls {
+		message := digestMessageEntryNoEnd
+		if d.poll.Settings.EndTime > 0 {
+			message = digestMessageEntry
+		}
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: message,
+			TemplateData: map[string]interface{}{
+				"Question":  d.poll.QuestionLinkText(),
+				"Link":      d.link(siteURL),
+				"Remaining": formatAge(d.poll.Settings.EndTime - now),
+			},
+		}))
+	}
+
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message:   strings.Join(lines, "\n"),
+	}
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to create post")
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+// getDigestPolls returns the running polls of channelID1 used in the digest tests: A poll userID1 has voted in,
+// which ends in two hours, and a poll without votes or end time.
+func getDigestPolls() (*poll.Poll, *poll.Poll) {
+	poll1 := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, EndTime: 1234567890 + 2*60*60*1000})
+	poll1.ID = "pollID1"
+	poll1.ChannelID = "channelID1"
+	poll2 := testutils.GetPoll()
+	poll2.ID = "pollID2"
+	poll2.PostID = "postID2"
+	poll2.ChannelID = "channelID1"
+	poll2.Question = "Lunch?"
+	return poll1, poll2
+}
+
+func getDigestChannelUsers() []*model.User {
+	return []*model.User{
+		{Id: "userID1", Username: "user1"},
+		{Id: "userID5", Username: "user5"},
+		{Id: testutils.GetBotUserID(), Username: "matterpoll", IsBot: true},
+	}
+}
+
+func TestPluginExecuteDigestCommand(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	t.Run("channel", func(t *testing.T) {
+		poll1, poll2 := getDigestPolls()
+		api := &plugintest.API{}
+		api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(getDigestChannelUsers(), nil)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByChannel", "channelID1").Return([]string{"pollID2", "pollID1"}, nil)
+		s.PollStore.On("Get", "pollID1").Return(poll1, nil)
+		s.PollStore.On("Get", "pollID2").Return(poll2, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "Running polls in this channel:\n"+
+			"- [**Question**](https://example.org/_redirect/pl/postID1): 1 of 2 members have voted, ends in 2h\n"+
+			"- [**Lunch?**](https://example.org/_redirect/pl/postID2): 0 of 2 members have voted (you haven't voted yet)",
+			p.executeDigestCommand([]string{}, "channelID1", "teamID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("team", func(t *testing.T) {
+		poll1, _ := getDigestPolls()
+		ended := testutils.GetPoll()
+		ended.ID = "pollID4"
+		ended.EndedAt = 1234567000
+		api := &plugintest.API{}
+		api.On("GetChannelsForTeamForUser", "teamID1", "userID1", false).Return([]*model.Channel{{Id: "channelID1"}, {Id: "channelID2"}}, nil)
+		api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(getDigestChannelUsers(), nil)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByChannel", "channelID1").Return([]string{"pollID1", "pollID3"}, nil)
+		s.PollStore.On("ListIDsByChannel", "channelID2").Return([]string{"pollID1", "pollID4"}, nil)
+		s.PollStore.On("Get", "pollID1").Return(poll1, nil)
+		s.PollStore.On("Get", "pollID3").Return(nil, store.ErrNotFound)
+		s.PollStore.On("Get", "pollID4").Return(ended, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "Running polls in your channels of this team:\n"+
+			"- [**Question**](https://example.org/_redirect/pl/postID1): 1 of 2 members have voted, ends in 2h",
+			p.executeDigestCommand([]string{"team"}, "channelID1", "teamID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("no running polls", func(t *testing.T) {
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByChannel", "channelID1").Return([]string{}, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, s)
+
+		assert.Equal(t, "There are no running polls in this channel.",
+			p.executeDigestCommand([]string{}, "channelID1", "teamID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("GetChannelsForTeamForUser fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetChannelsForTeamForUser", "teamID1", "userID1", false).Return(nil, &model.AppError{})
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.Equal(t, commandErrorGeneric.Other,
+			p.executeDigestCommand([]string{"team"}, "channelID1", "teamID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("ListIDsByChannel fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByChannel", "channelID1").Return(nil, errors.New(""))
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, commandErrorGeneric.Other,
+			p.executeDigestCommand([]string{}, "channelID1", "teamID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		assert.Equal(t, "Use `/poll digest` to list the running polls of this channel and `/poll digest team` to list the running polls of all your channels in this team.",
+			p.executeDigestCommand([]string{"server"}, "channelID1", "teamID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+}
+
+func TestPluginSendDigests(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	t.Run("digests are sent", func(t *testing.T) {
+		poll1, poll2 := getDigestPolls()
+		api := &plugintest.API{}
+		api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(getDigestChannelUsers(), nil)
+		api.On("GetUser", mock.AnythingOfType("string")).Return(&model.User{Locale: "en"}, nil)
+		api.On("GetDirectChannel", "userID5", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID5"}, nil)
+		api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID1"}, nil)
+		api.On("CreatePost", &model.Post{
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: "dmChannelID5",
+			Message: "You haven't voted in these 2 polls yet:\n" +
+				"- [**Question**](https://example.org/_redirect/pl/postID1), ends in 2h\n" +
+				"- [**Lunch?**](https://example.org/_redirect/pl/postID2)",
+		}).Return(nil, nil)
+		api.On("CreatePost", &model.Post{
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: "dmChannelID1",
+			Message: "You haven't voted in this poll yet:\n" +
+				"- [**Lunch?**](https://example.org/_redirect/pl/postID2)",
+		}).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDs").Return([]string{"pollID2", "pollID1"}, nil)
+		s.PollStore.On("Get", "pollID1").Return(poll1, nil)
+		s.PollStore.On("Get", "pollID2").Return(poll2, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+		p.configuration.DailyDigest = true
+
+		p.sendDigests()
+	})
+	t.Run("daily digest is disabled", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		p.sendDigests()
+	})
+	t.Run("direct message fails", func(t *testing.T) {
+		_, poll2 := getDigestPolls()
+		api := &plugintest.API{}
+		api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return(getDigestChannelUsers()[1:], nil)
+		api.On("GetDirectChannel", "userID5", testutils.GetBotUserID()).Return(nil, &model.AppError{})
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDs").Return([]string{"pollID2"}, nil)
+		s.PollStore.On("Get", "pollID2").Return(poll2, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+		p.configuration.DailyDigest = true
+
+		p.sendDigests()
+		api.AssertNotCalled(t, "CreatePost", mock.Anything)
+	})
+}
+
+func TestSortDigestPolls(t *testing.T) {
+	newDigestPoll := func(id string, endTime, createdAt int64) *channelPoll {
+		return &channelPoll{poll: &poll.Poll{ID: id, CreatedAt: createdAt, Settings: poll.Settings{EndTime: endTime}}}
+	}
+	polls := []*channelPoll{
+		newDigestPoll("noEndNew", 0, 20),
+		newDigestPoll("late", 200, 10),
+		newDigestPoll("noEndOld", 0, 10),
+		newDigestPoll("soon", 100, 30),
+	}
+
+	sortDigestPolls(polls)
+
+	ids := []string{}
+	for _, d := range polls {
+		ids = append(ids, d.poll.ID)
+	}
+	assert.Equal(t, "soon late noEndOld noEndNew", strings.Join(ids, " "))
+}
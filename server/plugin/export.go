@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+var (
+	responseExportResultsSuccess = &i18n.Message{
+		ID:    "response.exportResults.success",
+		Other: "The results have been sent to you as a direct message.",
+	}
+	responseExportResultsInvalidPermission = &i18n.Message{
+		ID:    "response.exportResults.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to export the results.",
+	}
+	exportResultsMessage = &i18n.Message{
+		ID:    "exportResults.message",
+		Other: "The results of the poll **{{.Question}}** are attached.",
+	}
+)
+
+// sendResultsCSV sends the results of a poll as CSV file to a user in a direct message from the bot.
+// Voters of anonymous polls are not included, see poll.ResultsCSV.
+func (p *MatterpollPlugin) sendResultsCSV(poll *poll.Poll, userID string) error {
+	b, err := poll.ResultsCSV()
+	if err != nil {
+		return errors.Wrap(err, "failed to create csv")
+	}
+
+	channel, appErr := p.API.GetDirectChannel(userID, p.botUserID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get direct channel")
+	}
+
+	fileInfo, appErr := p.API.UploadFile(b, channel.Id, fmt.Sprintf("poll-%s.csv", poll.ID))
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to upload file")
+	}
+
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message: p.LocalizeWithConfig(p.getUserLocalizer(userID), &i18n.LocalizeConfig{
+			DefaultMessage: exportResultsMessage,
+			TemplateData:   map[string]interface{}{"Question": poll.Question},
+		}),
+		FileIds: []string{fileInfo.Id},
+	}
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to create post")
+	}
+	return nil
+}
+
+// uploadResultsChart uploads the chart image of the results of a poll to a channel and returns the ID of the file.
+// It returns an empty ID if the poll has no chart, see poll.ResultsChart.
+func (p *MatterpollPlugin) uploadResultsChart(poll *poll.Poll, channelID string) (string, error) {
+	b, err := poll.ResultsChart()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create chart")
+	}
+	if b == nil {
+		return "", nil
+	}
+
+	fileInfo, appErr := p.API.UploadFile(b, channelID, fmt.Sprintf("poll-%s.png", poll.ID))
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to upload file")
+	}
+	return fileInfo.Id, nil
+}
@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// historyPageSize is the number of archived polls the history command lists at once.
+const historyPageSize = 10
+
+var (
+	commandHistoryUsage = &i18n.Message{
+		ID:    "command.history.usage",
+		Other: "Use `/{{.Trigger}} history` to list the polls that have ended in this channel and `/{{.Trigger}} history <Page>` to see older ones.",
+	}
+	commandHistoryEmpty = &i18n.Message{
+		ID:    "command.history.empty",
+		Other: "No polls have ended in this channel yet.",
+	}
+	commandHistoryHeader = &i18n.Message{
+		ID:    "command.history.header",
+		Other: "Polls that have ended in this channel (times in UTC):",
+	}
+	commandHistoryEntry = &i18n.Message{
+		ID:    "command.history.entry",
+		One:   "- {{.Time}}: **{{.Question}}**: **{{.Winners}}** with {{.Votes}} vote, {{.Voters}} voters in total",
+		Other: "- {{.Time}}: **{{.Question}}**: **{{.Winners}}** with {{.Votes}} votes, {{.Voters}} voters in total",
+	}
+	commandHistoryEntryNoVotes = &i18n.Message{
+		ID:    "command.history.entryNoVotes",
+		Other: "- {{.Time}}: **{{.Question}}**: nobody voted",
+	}
+	commandHistoryMore = &i18n.Message{
+		ID:    "command.history.more",
+		Other: "Use `/{{.Trigger}} history {{.Page}}` to see older polls.",
+	}
+)
+
+// archivePoll archives the outcome of a poll that has ended, so it shows up in the history of its channel.
+// Failures are only logged, since they must not prevent the poll from ending.
+func (p *MatterpollPlugin) archivePoll(pl *poll.Poll, channelID string) {
+	if err := p.Store.Archive().Save(pl.Archive(channelID)); err != nil {
+		p.API.LogWarn("failed to archive poll", "pollID", pl.ID, "error", err.Error())
+	}
+}
+
+// unarchivePoll removes a poll from the history of its channel, e.g. because it has been re-opened or deleted.
+// Failures are only logged.
+func (p *MatterpollPlugin) unarchivePoll(pl *poll.Poll, channelID string) {
+	if err := p.Store.Archive().Delete(channelID, pl.ID); err != nil {
+		p.API.LogWarn("failed to delete archived poll", "pollID", pl.ID, "error", err.Error())
+	}
+}
+
+// executeHistoryCommand returns a message listing the outcomes of the polls that have ended in a channel,
+// most recently closed first.
+func (p *MatterpollPlugin) executeHistoryCommand(args []string, channelID, trigger string, userLocalizer *i18n.Localizer) string {
+	page := 1
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if len(args) > 1 || err != nil || n < 1 {
+			return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandHistoryUsage,
+				TemplateData:   map[string]interface{}{"Trigger": trigger},
+			})
+		}
+		page = n
+	}
+
+	archivedPolls, err := p.Store.Archive().ListByChannel(channelID)
+	if err != nil {
+		p.API.LogWarn("failed to list archived polls", "channelID", channelID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	start := (page - 1) * historyPageSize
+	if start >= len(archivedPolls) {
+		return p.LocalizeDefaultMessage(userLocalizer, commandHistoryEmpty)
+	}
+	end := start + historyPageSize
+	if end > len(archivedPolls) {
+		end = len(archivedPolls)
+	}
+
+	lines := []string{p.LocalizeDefaultMessage(userLocalizer, commandHistoryHeader)}
+	for _, a := range archivedPolls[start:end] {
+		winners, votes := a.Winners()
+		if winners == nil {
+			lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandHistoryEntryNoVotes,
+				TemplateData:   map[string]interface{}{"Time": formatTime(a.ClosedAt), "Question": a.Question},
+			}))
+			continue
+		}
+
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHistoryEntry,
+			TemplateData: map[string]interface{}{
+				"Time":     formatTime(a.ClosedAt),
+				"Question": a.Question,
+				"Winners":  strings.Join(winners, ", "),
+				"Votes":    votes,
+				"Voters":   a.Voters,
+			},
+			PluralCount: votes,
+		}))
+	}
+	if end < len(archivedPolls) {
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHistoryMore,
+			TemplateData:   map[string]interface{}{"Trigger": trigger, "Page": page + 1},
+		}))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -62,6 +62,31 @@ func (p *MatterpollPlugin) getServerLocalizer() *i18n.Localizer {
 	return i18n.NewLocalizer(p.bundle, *p.ServerConfig.LocalizationSettings.DefaultClientLocale)
 }
 
+// getPollLocalizer returns a localizer that localizes the texts of a poll everyone sees, like its post and results.
+// They are in the language of the locale setting of the poll, if it's set, or else in the server default client locale.
+func (p *MatterpollPlugin) getPollLocalizer(pl *poll.Poll) *i18n.Localizer {
+	return p.getLocaleLocalizer(pl.Settings.Locale)
+}
+
+// getLocaleLocalizer returns a localizer that localizes in locale and falls back to the server default client locale
+func (p *MatterpollPlugin) getLocaleLocalizer(locale string) *i18n.Localizer {
+	if locale == "" {
+		return p.getServerLocalizer()
+	}
+	return i18n.NewLocalizer(p.bundle, locale, *p.ServerConfig.LocalizationSettings.DefaultClientLocale)
+}
+
+// supportedLocale returns the language of the bundle that matches locale, ignoring the case, or an empty string
+// if there are no translations for locale.
+func (p *MatterpollPlugin) supportedLocale(locale string) string {
+	for _, tag := range p.bundle.LanguageTags() {
+		if strings.EqualFold(tag.String(), locale) {
+			return tag.String()
+		}
+	}
+	return ""
+}
+
 // LocalizeDefaultMessage localizer the provided message
 func (p *MatterpollPlugin) LocalizeDefaultMessage(l *i18n.Localizer, m *i18n.Message) string {
 	s, err := l.LocalizeMessage(m)
@@ -6,7 +6,10 @@ import (
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
 
+	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 )
@@ -77,3 +80,18 @@ func TestLocalizeWithConfig(t *testing.T) {
 		assert.Equal(t, "", p.LocalizeWithConfig(l, lc))
 	})
 }
+
+func TestGetPollLocalizer(t *testing.T) {
+	p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+	m := &i18n.Message{ID: "test", Other: "test message"}
+	require.NoError(t, p.bundle.AddMessages(language.German, &i18n.Message{ID: "test", Other: "Testnachricht"}))
+
+	t.Run("without locale", func(t *testing.T) {
+		pl := testutils.GetPoll()
+		assert.Equal(t, "test message", p.LocalizeDefaultMessage(p.getPollLocalizer(pl), m))
+	})
+	t.Run("with locale", func(t *testing.T) {
+		pl := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Locale: "de"})
+		assert.Equal(t, "Testnachricht", p.LocalizeDefaultMessage(p.getPollLocalizer(pl), m))
+	})
+}
@@ -0,0 +1,123 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/interplugin"
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// initInterPluginAPI adds the routes of the inter-plugin API to r, see package interplugin.
+func (p *MatterpollPlugin) initInterPluginAPI(r *mux.Router) {
+	interPluginV1 := r.PathPrefix("/inter-plugin/v1").Subrouter()
+	interPluginV1.Use(checkPluginAuthenticity)
+	interPluginV1.HandleFunc("/polls", p.handleInterPluginCreatePoll).Methods(http.MethodPost)
+	interPluginV1.HandleFunc("/polls/{id:[a-z0-9]+}/results", p.handleInterPluginResults).Methods(http.MethodGet)
+}
+
+// checkPluginAuthenticity only lets requests of other plugins through.
+// The server sets the Mattermost-Plugin-ID header for requests sent via PluginHTTP and removes it from all other requests.
+func checkPluginAuthenticity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Mattermost-Plugin-ID") == "" {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (p *MatterpollPlugin) handleInterPluginCreatePoll(w http.ResponseWriter, r *http.Request) {
+	var request interplugin.CreatePollRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if request.UserID == "" {
+		http.Error(w, "missing user_id", http.StatusBadRequest)
+		return
+	}
+
+	var callback *poll.ResultsCallback
+	if request.ResultsCallbackPath != "" {
+		callback = &poll.ResultsCallback{
+			PluginID: r.Header.Get("Mattermost-Plugin-ID"),
+			Path:     request.ResultsCallbackPath,
+		}
+	}
+
+	newPoll := p.createPollFromRequest(w, request.UserID, &createPollRequest{
+		ChannelID:     request.ChannelID,
+		RootID:        request.RootID,
+		Question:      request.Question,
+		AnswerOptions: request.AnswerOptions,
+		Settings:      request.Settings,
+	}, callback)
+	if newPoll == nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(interplugin.CreatePollResponse{PollID: newPoll.ID, PostID: newPoll.PostID}); err != nil {
+		p.API.LogWarn("failed to write response", "error", err.Error())
+	}
+}
+
+func (p *MatterpollPlugin) handleInterPluginResults(w http.ResponseWriter, r *http.Request) {
+	pollID := mux.Vars(r)["id"]
+
+	pl, err := p.getPoll(pollID)
+	if err != nil {
+		http.Error(w, "poll not found", http.StatusNotFound)
+		return
+	}
+
+	b, err := pl.ResultsJSON()
+	if err != nil {
+		p.API.LogWarn("failed to create results", "pollID", pollID, "error", err.Error())
+		http.Error(w, "failed to create results", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		p.API.LogWarn("failed to write response", "error", err.Error())
+	}
+}
+
+// sendResultsCallback posts the results of an ended poll as JSON to the plugin that subscribed to them when it
+// created the poll, see interplugin.CreatePollRequest. It does nothing if no plugin subscribed.
+func (p *MatterpollPlugin) sendResultsCallback(pl *poll.Poll) error {
+	if pl.ResultsCallback == nil {
+		return nil
+	}
+
+	b, err := pl.ResultsJSON()
+	if err != nil {
+		return errors.Wrap(err, "failed to create results")
+	}
+
+	r, err := http.NewRequest(http.MethodPost, "/"+pl.ResultsCallback.PluginID+pl.ResultsCallback.Path, bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	resp := p.API.PluginHTTP(r)
+	if resp == nil {
+		return errors.Errorf("plugin %s didn't respond", pl.ResultsCallback.PluginID)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("plugin %s responded with status code %d", pl.ResultsCallback.PluginID, resp.StatusCode)
+	}
+	return nil
+}
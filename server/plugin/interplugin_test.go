@@ -0,0 +1,256 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/interplugin"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestInterPluginID(t *testing.T) {
+	assert.Equal(t, manifest.Id, interplugin.PluginID)
+}
+
+func TestHandleInterPluginCreatePoll(t *testing.T) {
+	userID := testutils.GetPoll().Creator
+	channelID := model.NewId()
+
+	expectedPoll := testutils.GetPoll()
+	expectedPoll.ModifiedAt = expectedPoll.CreatedAt
+	rPoll := expectedPoll.Copy()
+	rPoll.PostID = "postID1"
+	rPoll.ChannelID = channelID
+	rPoll.ResultsCallback = &poll.ResultsCallback{PluginID: "other.plugin", Path: "/poll-ended"}
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: channelID,
+		Type:      MatterpollPostType,
+		Props: model.StringInterface{
+			"poll_id": testutils.GetPollID(),
+		},
+	}
+	model.ParseSlackAttachment(expectedPost, expectedPoll.ToPostActions(testutils.GetLocalizer(), manifest.Id, "John Doe"))
+
+	validRequest := &interplugin.CreatePollRequest{
+		UserID:              userID,
+		ChannelID:           channelID,
+		Question:            expectedPoll.Question,
+		AnswerOptions:       []string{"Answer 1", "Answer 2", "Answer 3"},
+		ResultsCallbackPath: "/poll-ended",
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		PluginID           string
+		Body               string
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(true)
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+
+				rPost := expectedPost.Clone()
+				rPost.Id = "postID1"
+				api.On("CreatePost", expectedPost).Return(rPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Insert", rPoll).Return(nil)
+				return store
+			},
+			PluginID:           "other.plugin",
+			Body:               string(mustMarshal(t, validRequest)),
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedBody:       `{"poll_id":"` + testutils.GetPollID() + `","post_id":"postID1"}` + "\n",
+		},
+		"Invalid request, not sent by a plugin": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Body:               string(mustMarshal(t, validRequest)),
+			ExpectedStatusCode: http.StatusUnauthorized,
+			ExpectedBody:       "not authorized\n",
+		},
+		"Invalid request, malformed body": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			PluginID:           "other.plugin",
+			Body:               "{",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "invalid request\n",
+		},
+		"Invalid request, missing user": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			PluginID:           "other.plugin",
+			Body:               `{"channel_id":"` + channelID + `","question":"Question","answer_options":["Yes","No"]}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "missing user_id\n",
+		},
+		"Invalid request, without permission to post": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("HasPermissionToChannel", userID, channelID, model.PERMISSION_CREATE_POST).Return(false)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			PluginID:           "other.plugin",
+			Body:               string(mustMarshal(t, validRequest)),
+			ExpectedStatusCode: http.StatusForbidden,
+			ExpectedBody:       "not authorized\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			patch2 := monkey.Patch(model.NewId, testutils.GetPollID)
+			defer patch1.Unpatch()
+			defer patch2.Unpatch()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, interplugin.CreatePollPath, bytes.NewReader([]byte(test.Body)))
+			if test.PluginID != "" {
+				r.Header.Add("Mattermost-Plugin-ID", test.PluginID)
+			}
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			defer result.Body.Close()
+			body, err := ioutil.ReadAll(result.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(t, test.ExpectedBody, string(body))
+		})
+	}
+}
+
+func TestHandleInterPluginResults(t *testing.T) {
+	t.Run("results are returned", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, interplugin.ResultsPath(testutils.GetPollID()), nil)
+		r.Header.Add("Mattermost-Plugin-ID", "other.plugin")
+		p.ServeHTTP(nil, w, r)
+
+		result := w.Result()
+		require.NotNil(t, result)
+		defer result.Body.Close()
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, "application/json", result.Header.Get("Content-Type"))
+
+		var results interplugin.Results
+		require.NoError(t, json.NewDecoder(result.Body).Decode(&results))
+		assert.Equal(t, testutils.GetPollID(), results.PollID)
+		assert.Equal(t, "Question", results.Question)
+		require.Len(t, results.Options, 3)
+		assert.Equal(t, "Answer 1", results.Options[0].Answer)
+		require.NotNil(t, results.Options[0].Votes)
+	})
+	t.Run("results match the contract", func(t *testing.T) {
+		pl := testutils.GetPollWithVotes()
+		b, err := pl.ResultsJSON()
+		require.NoError(t, err)
+
+		var results interplugin.Results
+		require.NoError(t, json.Unmarshal(b, &results))
+		roundTripped, err := json.Marshal(results)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(b), string(roundTripped))
+	})
+	t.Run("poll not found", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, interplugin.ResultsPath(testutils.GetPollID()), nil)
+		r.Header.Add("Mattermost-Plugin-ID", "other.plugin")
+		p.ServeHTTP(nil, w, r)
+
+		result := w.Result()
+		require.NotNil(t, result)
+		defer result.Body.Close()
+		assert.Equal(t, http.StatusNotFound, result.StatusCode)
+	})
+}
+
+func TestSendResultsCallback(t *testing.T) {
+	pl := testutils.GetPollWithVotes()
+	pl.EndedAt = 1234567899
+	pl.ResultsCallback = &poll.ResultsCallback{PluginID: "other.plugin", Path: "/poll-ended"}
+
+	isCallbackRequest := func(r *http.Request) bool {
+		var results interplugin.Results
+		if err := json.NewDecoder(r.Body).Decode(&results); err != nil {
+			return false
+		}
+		return r.Method == http.MethodPost && r.URL.Path == "/other.plugin/poll-ended" &&
+			r.Header.Get("Content-Type") == "application/json" && results.PollID == pl.ID && results.EndedAt == pl.EndedAt
+	}
+
+	t.Run("results are sent", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("PluginHTTP", mock.MatchedBy(isCallbackRequest)).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(nil))})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.NoError(t, p.sendResultsCallback(pl))
+	})
+	t.Run("no plugin subscribed", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		assert.NoError(t, p.sendResultsCallback(testutils.GetPollWithVotes()))
+	})
+	t.Run("plugin doesn't respond", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("PluginHTTP", mock.Anything).Return(nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.Error(t, p.sendResultsCallback(pl))
+	})
+	t.Run("plugin fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("PluginHTTP", mock.Anything).Return(&http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(bytes.NewReader(nil))})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		err := p.sendResultsCallback(pl)
+		require.Error(t, err)
+		assert.Equal(t, "plugin other.plugin responded with status code 404", err.Error())
+	})
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+var (
+	commandResultsInvalidPermission = &i18n.Message{
+		ID:    "command.results.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to see its results before it ends.",
+	}
+	commandListEmpty = &i18n.Message{
+		ID:    "command.list.empty",
+		Other: "You don't have any running polls.",
+	}
+	commandListHeader = &i18n.Message{
+		ID:    "command.list.header",
+		Other: "Your running polls:",
+	}
+	commandListEntry = &i18n.Message{
+		ID:    "command.list.entry",
+		One:   "- `{{.ID}}`: [**{{.Question}}**]({{.Link}}), created {{.Age}} ago, {{.Voters}} voter",
+		Other: "- `{{.ID}}`: [**{{.Question}}**]({{.Link}}), created {{.Age}} ago, {{.Voters}} voters",
+	}
+)
+
+// executeEndCommand ends a running poll, if the user is allowed to manage it, and returns the response message.
+func (p *MatterpollPlugin) executeEndCommand(pollID, userID string, userLocalizer *i18n.Localizer) string {
+	poll, err := p.getPoll(pollID)
+	if err != nil || poll.HasEnded() {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAdminPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to check permission", "pollID", pollID, "error", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if !canManagePoll {
+		return p.LocalizeDefaultMessage(userLocalizer, responseEndPollInvalidPermission)
+	}
+	if poll.EndPrevented() {
+		return p.LocalizeDefaultMessage(userLocalizer, responseEndPollQuorumNotMet)
+	}
+
+	if err := p.endPoll(poll, userID); err != nil {
+		p.API.LogWarn("failed to end poll", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandAdminEndSuccess,
+		TemplateData:   map[string]interface{}{"Question": poll.Question},
+	})
+}
+
+// executeResultsCommand returns the current results of a poll as response message, if the user is allowed to manage it.
+// Results that the poll hides, e.g. the votes of a running secret poll, are not revealed.
+// Only the creator of a semi-anonymous poll gets its voters, see poll.Poll.ShowsVotersTo.
+func (p *MatterpollPlugin) executeResultsCommand(pollID, userID string, userLocalizer *i18n.Localizer) string {
+	poll, err := p.getPoll(pollID)
+	if err != nil {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAdminPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(poll, userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to check permission", "pollID", pollID, "error", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if !canManagePoll {
+		return p.LocalizeDefaultMessage(userLocalizer, commandResultsInvalidPermission)
+	}
+
+	results, appErr := poll.MarkdownResultsForUser(userLocalizer, p.ConvertUserIDToDisplayName, userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to render results", "pollID", pollID, "error", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return results
+}
+
+// executeListCommand returns a message listing the running polls created by a user, oldest first,
+// together with links to their posts.
+func (p *MatterpollPlugin) executeListCommand(userID string, userLocalizer *i18n.Localizer) string {
+	ids, err := p.Store.Poll().ListIDsByUser(userID, p.getConfiguration().VoterHashKey)
+	if err != nil {
+		p.API.LogWarn("failed to list polls of user", "userID", userID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	var polls []*poll.Poll
+	for _, id := range ids {
+		poll, err := p.getPoll(id)
+		if err != nil {
+			p.API.LogWarn("failed to get poll", "pollID", id, "error", err.Error())
+			continue
+		}
+		if poll.Creator == userID && !poll.HasEnded() {
+			polls = append(polls, poll)
+		}
+	}
+	if len(polls) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandListEmpty)
+	}
+
+	sort.Slice(polls, func(i, j int) bool { return polls[i].CreatedAt < polls[j].CreatedAt })
+	now := model.GetMillis()
+	lines := []string{p.LocalizeDefaultMessage(userLocalizer, commandListHeader)}
+	for _, poll := range polls {
+		voters := poll.VoterCount()
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandListEntry,
+			TemplateData: map[string]interface{}{
+				"ID":       poll.ID,
+				"Question": poll.QuestionLinkText(),
+				"Link":     fmt.Sprintf("%s/_redirect/pl/%s", *p.ServerConfig.ServiceSettings.SiteURL, poll.PostID),
+				"Age":      formatAge(now - poll.CreatedAt),
+				"Voters":   voters,
+			},
+			PluralCount: voters,
+		}))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -51,6 +51,164 @@ const manifestStr = `
         "help_text": "When true, Matterpoll will render poll posts with a rich UI. The rich UI is not available on mobile app.",
         "placeholder": "",
         "default": false
+      },
+      {
+        "key": "ReopenGracePeriod",
+        "display_name": "Grace Period for Re-opening Polls:",
+        "type": "text",
+        "help_text": "The number of minutes in which the creator of a poll can re-open it after it has ended, using the reopen command. Set to 0 to delete ended polls right away.",
+        "placeholder": "",
+        "default": "10"
+      },
+      {
+        "key": "RetentionDays",
+        "display_name": "Retention Period for Ended Polls:",
+        "type": "text",
+        "help_text": "The number of days ended polls are kept, e.g. to export their results. Polls whose post has been deleted are removed once a day. Set to 0 to delete ended polls once they can't be re-opened anymore.",
+        "placeholder": "",
+        "default": "0"
+      },
+      {
+        "key": "DefaultMaxVotes",
+        "display_name": "Default Number of Votes:",
+        "type": "text",
+        "help_text": "The number of options users can vote for in new polls, unless they specify --votes=X. Ranked polls, quizzes and polls with a scale always allow one vote.",
+        "placeholder": "",
+        "default": "1"
+      },
+      {
+        "key": "DefaultAnonymous",
+        "display_name": "Anonymous by Default:",
+        "type": "bool",
+        "help_text": "When true, new polls are anonymous unless their creator specifies --no-anonymous.",
+        "placeholder": "",
+        "default": false
+      },
+      {
+        "key": "DefaultProgress",
+        "display_name": "Progress by Default:",
+        "type": "bool",
+        "help_text": "When true, new polls show their progress unless their creator specifies --no-progress.",
+        "placeholder": "",
+        "default": false
+      },
+      {
+        "key": "MaxAnswerOptions",
+        "display_name": "Maximum Number of Options:",
+        "type": "text",
+        "help_text": "The maximum number of options a new poll may have. Must be at least 2.",
+        "placeholder": "",
+        "default": "20"
+      },
+      {
+        "key": "MaxQuestionLength",
+        "display_name": "Maximum Question Length:",
+        "type": "text",
+        "help_text": "The maximum number of characters of the question of a new poll.",
+        "placeholder": "",
+        "default": "300"
+      },
+      {
+        "key": "MaxAnswerLength",
+        "display_name": "Maximum Option Length:",
+        "type": "text",
+        "help_text": "The maximum number of characters of an option of a poll. Longer options don't fit on the buttons of the poll.",
+        "placeholder": "",
+        "default": "150"
+      },
+      {
+        "key": "BannedCharacters",
+        "display_name": "Banned Characters:",
+        "type": "text",
+        "help_text": "Characters that the questions and options of new polls must not contain, e.g. <>. Leave it empty to allow all characters.",
+        "placeholder": "",
+        "default": ""
+      },
+      {
+        "key": "MaxPollsPerHour",
+        "display_name": "Maximum Polls per Hour:",
+        "type": "text",
+        "help_text": "The maximum number of polls a user can create per hour. Leave it empty or set it to 0 for no limit.",
+        "placeholder": "",
+        "default": ""
+      },
+      {
+        "key": "MaxAddedOptionsPerUser",
+        "display_name": "Maximum Added Options per User:",
+        "type": "text",
+        "help_text": "The maximum number of options a user can add to or suggest for a poll of another user. Leave it empty or set it to 0 for no limit.",
+        "placeholder": "",
+        "default": ""
+      },
+      {
+        "key": "ResultsBarStyle",
+        "display_name": "Result Bars:",
+        "type": "radio",
+        "help_text": "The style of the bars that show the share of votes of every option in the results of polls with --progress and in the results of ended polls.",
+        "placeholder": "",
+        "default": "blocks",
+        "options": [
+          {
+            "display_name": "Blocks",
+            "value": "blocks"
+          },
+          {
+            "display_name": "Squares",
+            "value": "squares"
+          },
+          {
+            "display_name": "None",
+            "value": "none"
+          }
+        ]
+      },
+      {
+        "key": "ThreadResults",
+        "display_name": "Post Results in Thread:",
+        "type": "bool",
+        "help_text": "Post the results of every poll as a pinned reply in its thread when it ends, as if all polls used --thread-results.",
+        "placeholder": "",
+        "default": false
+      },
+      {
+        "key": "DailyDigest",
+        "display_name": "Send Daily Digest:",
+        "type": "bool",
+        "help_text": "Send every user a direct message once a day listing the running polls in their channels they haven't voted in yet.",
+        "placeholder": "",
+        "default": false
+      },
+      {
+        "key": "VoterHashKey",
+        "display_name": "Voter Hash Key:",
+        "type": "generated",
+        "help_text": "Secret key the voters of anonymous polls are hashed with before they are stored. It is generated when the plugin is activated. If it is changed, users can vote again in running anonymous polls.",
+        "placeholder": "",
+        "default": null
+      },
+      {
+        "key": "ResultsWebhookURL",
+        "display_name": "Results Webhook URL:",
+        "type": "text",
+        "help_text": "The URL the results of every poll are sent to as JSON when the poll ends. Leave it empty to not send the results anywhere.",
+        "placeholder": "",
+        "default": null
+      },
+      {
+        "key": "MetricsToken",
+        "display_name": "Metrics Token:",
+        "type": "generated",
+        "help_text": "Bearer token Prometheus has to send to scrape the metrics of the plugin at /plugins/com.github.matterpoll.matterpoll/metrics. Leave it empty to disable the metrics endpoint.",
+        "placeholder": "",
+        "default": null
+      },
+      {
+        "key": "EnableAuditLog",
+        "display_name": "Enable Audit Log:",
+        "type": "bool",
+        "help_text": "Record the creation, end and deletion of polls and the options added to them, together with the user and channel, in an audit log. System Admins can download the records of a day from /plugins/com.github.matterpoll.matterpoll/api/v1/audit?day=YYYY-MM-DD.",
+        "placeholder": "",
+        "default": false
       }
     ]
   }
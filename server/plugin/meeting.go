@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils"
+)
+
+var (
+	commandMeetingUsage = &i18n.Message{
+		ID:    "command.meeting.usage",
+		Other: "Use `/{{.Trigger}} schedule-meeting \"Question\" \"2021-10-01T15:00\" \"2021-10-01T16:00\"` to find a date for a meeting. The times are in UTC. Add `--invite` to get an invite for the best slot when the poll ends.",
+	}
+)
+
+// executeMeetingCommand creates a poll, whose answer options are the given slots, to find a date for a meeting
+// and returns the response message.
+func (p *MatterpollPlugin) executeMeetingCommand(args *model.CommandArgs, userLocalizer *i18n.Localizer) string {
+	trigger := p.getConfiguration().Trigger
+	in := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(args.Command), "/"+trigger))
+	_, in = cutField(in)
+
+	q, o, s := utils.ParseInput(in, trigger)
+	if q == "" || len(o) == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandMeetingUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		})
+	}
+
+	configuration := p.getConfiguration()
+	settings, errMsg := poll.NewSettingsFromStringsWithDefaults(poll.CanonicalizeSettings(s, userLocalizer), configuration.pollDefaults())
+	if errMsg != nil {
+		return p.localizeInvalidInput(userLocalizer, errMsg)
+	}
+	newPoll, errMsg := poll.NewMeetingPoll(args.UserId, q, o, settings, configuration.pollLimits())
+	channelID, rootID := args.ChannelId, args.RootId
+	if errMsg == nil {
+		channelID, rootID, errMsg = p.resolveTarget(newPoll, args.ChannelId, args.RootId)
+	}
+	if errMsg == nil {
+		errMsg = p.resolveSettings(newPoll, channelID)
+	}
+	if errMsg != nil {
+		return p.localizeInvalidInput(userLocalizer, errMsg)
+	}
+
+	return p.publishPoll(newPoll, channelID, rootID, userLocalizer)
+}
+
+// uploadMeetingInvite uploads an invite for the best slot of a meeting poll to a channel and returns the ID of the file.
+// If there are multiple best slots, the first one is used. It returns an empty ID if nobody has voted.
+func (p *MatterpollPlugin) uploadMeetingInvite(poll *poll.Poll, channelID string) (string, error) {
+	best := poll.BestSlots()
+	if len(best) == 0 {
+		return "", nil
+	}
+
+	fileInfo, appErr := p.API.UploadFile(poll.MeetingInvite(best[0]), channelID, fmt.Sprintf("meeting-%s.ics", poll.ID))
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to upload file")
+	}
+	return fileInfo.Id, nil
+}
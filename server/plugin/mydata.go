@@ -0,0 +1,142 @@
+package plugin
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// myDataFileName is the name of the file a user gets their data exported to.
+const myDataFileName = "matterpoll-data.json"
+
+var (
+	commandMyDataUsage = &i18n.Message{
+		ID:    "command.myData.usage",
+		Other: "Use `/{{.Trigger}} my-data export` to get the data polls store about you and `/{{.Trigger}} my-data delete` to remove your user ID from all polls.",
+	}
+	commandMyDataExportSuccess = &i18n.Message{
+		ID:    "command.myData.export.success",
+		Other: "Your data has been sent to you as a direct message.",
+	}
+	commandMyDataDeleteSuccess = &i18n.Message{
+		ID:    "command.myData.delete.success",
+		One:   "Your user ID has been removed from {{.Count}} poll. Your votes still count, but can't be linked to you anymore.",
+		Other: "Your user ID has been removed from {{.Count}} polls. Your votes still count, but can't be linked to you anymore.",
+	}
+	myDataExportMessage = &i18n.Message{
+		ID:    "myData.export.message",
+		Other: "The data polls store about you is attached.",
+	}
+)
+
+// myDataExport is the personal data export of a user.
+type myDataExport struct {
+	UserID string           `json:"user_id"`
+	Polls  []*poll.UserData `json:"polls"`
+}
+
+// executeMyDataCommand exports or erases the data polls store about a user and returns the response message.
+func (p *MatterpollPlugin) executeMyDataCommand(args []string, userID, trigger string, userLocalizer *i18n.Localizer) string {
+	switch {
+	case len(args) == 1 && args[0] == "export":
+		return p.exportMyData(userID, userLocalizer)
+	case len(args) == 1 && args[0] == "delete":
+		return p.deleteMyData(userID, userLocalizer)
+	default:
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandMyDataUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		})
+	}
+}
+
+// exportMyData sends the data all polls store about a user as JSON file in a direct message from the bot
+// and returns the response message.
+func (p *MatterpollPlugin) exportMyData(userID string, userLocalizer *i18n.Localizer) string {
+	ids, err := p.Store.Poll().ListIDsByUser(userID, p.getConfiguration().VoterHashKey)
+	if err != nil {
+		p.API.LogWarn("failed to list polls of user", "userID", userID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	export := &myDataExport{UserID: userID, Polls: []*poll.UserData{}}
+	for _, id := range ids {
+		poll, err := p.getPoll(id)
+		if err != nil {
+			p.API.LogWarn("failed to get poll", "pollID", id, "error", err.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+		}
+		export.Polls = append(export.Polls, poll.UserData(userID))
+	}
+
+	if err := p.sendMyData(export, userLocalizer); err != nil {
+		p.API.LogWarn("failed to send personal data", "userID", userID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, commandMyDataExportSuccess)
+}
+
+// sendMyData sends the personal data export of a user as JSON file in a direct message from the bot.
+func (p *MatterpollPlugin) sendMyData(export *myDataExport, userLocalizer *i18n.Localizer) error {
+	b, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode data")
+	}
+
+	channel, appErr := p.API.GetDirectChannel(export.UserID, p.botUserID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get direct channel")
+	}
+
+	fileInfo, appErr := p.API.UploadFile(b, channel.Id, myDataFileName)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to upload file")
+	}
+
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message:   p.LocalizeDefaultMessage(userLocalizer, myDataExportMessage),
+		FileIds:   []string{fileInfo.Id},
+	}
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to create post")
+	}
+	return nil
+}
+
+// deleteMyData replaces the ID of a user in the votes of all polls with an opaque token and returns the response message.
+// The results of the polls don't change. Polls the user created are kept, see poll.Poll.EraseUser.
+func (p *MatterpollPlugin) deleteMyData(userID string, userLocalizer *i18n.Localizer) string {
+	ids, err := p.Store.Poll().ListIDsByUser(userID, p.getConfiguration().VoterHashKey)
+	if err != nil {
+		p.API.LogWarn("failed to list polls of user", "userID", userID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	count := 0
+	for _, id := range ids {
+		erased := false
+		_, err := p.updatePoll(id, func(poll *poll.Poll) (bool, error) {
+			erased = poll.EraseUser(userID)
+			return erased, nil
+		})
+		if err != nil {
+			p.API.LogWarn("failed to erase user from poll", "pollID", id, "error", err.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+		}
+		if erased {
+			count++
+		}
+	}
+
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandMyDataDeleteSuccess,
+		TemplateData:   map[string]interface{}{"Count": count},
+		PluralCount:    count,
+	})
+}
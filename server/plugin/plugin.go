@@ -4,12 +4,14 @@ import (
 	"sync"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
 	"github.com/mattermost/mattermost-plugin-api/experimental/command"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
 
+	"github.com/matterpoll/matterpoll/server/metrics"
 	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store"
 	"github.com/matterpoll/matterpoll/server/store/kvstore"
@@ -34,6 +36,18 @@ type MatterpollPlugin struct {
 	configuration *configuration
 	ServerConfig  *model.Config
 
+	// endPollJob ends polls after their deadline.
+	endPollJob *cluster.Job
+	// postScheduledPollsJob posts scheduled polls once their time has come.
+	postScheduledPollsJob *cluster.Job
+	// pruneStoreJob deletes polls that aren't needed anymore.
+	pruneStoreJob *cluster.Job
+	// sendDigestsJob sends the daily digest of polls users haven't voted in.
+	sendDigestsJob *cluster.Job
+
+	// metrics collects the usage and performance metrics exported by handleMetrics.
+	metrics *metrics.Metrics
+
 	// getIconData provides access to command.GetIconData in a way that is mockable for unit testing.
 	getIconData func() (string, error)
 }
@@ -52,7 +66,9 @@ const (
 )
 
 func NewMatterpollPlugin() *MatterpollPlugin {
-	plugin := &MatterpollPlugin{}
+	plugin := &MatterpollPlugin{
+		metrics: metrics.New(),
+	}
 
 	getIconData := func() (string, error) {
 		return command.GetIconData(plugin.API, "assets/logo_dark-bg.svg")
@@ -105,8 +121,33 @@ func (p *MatterpollPlugin) OnActivate() error {
 		return errors.Wrap(err, "failed to register  command")
 	}
 
+	if err := p.ensureVoterHashKey(); err != nil {
+		return errors.Wrap(err, "failed to ensure voter hash key")
+	}
+	p.hashAnonymousVoters()
+
 	p.router = p.InitAPI()
 
+	p.endPollJob, err = cluster.Schedule(p.API, endPollJobKey, cluster.MakeWaitForInterval(endPollJobInterval), p.endExpiredPolls)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule end poll job")
+	}
+
+	p.postScheduledPollsJob, err = cluster.Schedule(p.API, postScheduledPollsJobKey, cluster.MakeWaitForInterval(postScheduledPollsJobInterval), p.postScheduledPolls)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule post scheduled polls job")
+	}
+
+	p.pruneStoreJob, err = cluster.Schedule(p.API, pruneStoreJobKey, cluster.MakeWaitForInterval(pruneStoreJobInterval), p.runPruneStoreJob)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule prune store job")
+	}
+
+	p.sendDigestsJob, err = cluster.Schedule(p.API, sendDigestsJobKey, cluster.MakeWaitForInterval(sendDigestsJobInterval), p.sendDigests)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule send digests job")
+	}
+
 	p.setActivated(true)
 
 	return nil
@@ -116,6 +157,27 @@ func (p *MatterpollPlugin) OnActivate() error {
 func (p *MatterpollPlugin) OnDeactivate() error {
 	p.setActivated(false)
 
+	if p.endPollJob != nil {
+		if err := p.endPollJob.Close(); err != nil {
+			return errors.Wrap(err, "failed to close end poll job")
+		}
+	}
+	if p.postScheduledPollsJob != nil {
+		if err := p.postScheduledPollsJob.Close(); err != nil {
+			return errors.Wrap(err, "failed to close post scheduled polls job")
+		}
+	}
+	if p.pruneStoreJob != nil {
+		if err := p.pruneStoreJob.Close(); err != nil {
+			return errors.Wrap(err, "failed to close prune store job")
+		}
+	}
+	if p.sendDigestsJob != nil {
+		if err := p.sendDigestsJob.Close(); err != nil {
+			return errors.Wrap(err, "failed to close send digests job")
+		}
+	}
+
 	return nil
 }
 
@@ -143,6 +205,82 @@ func (p *MatterpollPlugin) patchBotDescription() error {
 	return nil
 }
 
+// getPoll returns the poll for a given id from the store, ready to hash the voters of anonymous polls.
+// Corrupt polls are logged as error, so they don't go unnoticed among polls that have been deleted.
+func (p *MatterpollPlugin) getPoll(id string) (*poll.Poll, error) {
+	pl, err := p.Store.Poll().Get(id)
+	if err != nil {
+		if errors.Is(err, poll.ErrCorruptPoll) {
+			p.API.LogError("Stored poll is corrupt", "pollID", id, "error", err.Error())
+		}
+		return nil, err
+	}
+	p.preparePoll(pl)
+	return pl, nil
+}
+
+// preparePoll applies the parts of the plugin configuration to a poll that are never stored with it.
+func (p *MatterpollPlugin) preparePoll(poll *poll.Poll) {
+	configuration := p.getConfiguration()
+	poll.SetVoterKey(configuration.VoterHashKey)
+	poll.SetBarStyle(configuration.ResultsBarStyle)
+}
+
+// pollChannelID returns the ID of the channel a poll was posted in.
+// Polls posted before their channel was stored with them look it up from their post.
+func (p *MatterpollPlugin) pollChannelID(pl *poll.Poll) (string, error) {
+	if pl.ChannelID != "" {
+		return pl.ChannelID, nil
+	}
+	if pl.PostID == "" {
+		return "", errors.New("poll has no post")
+	}
+	post, appErr := p.API.GetPost(pl.PostID)
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to get post")
+	}
+	return post.ChannelId, nil
+}
+
+// pollPostChannelIDs returns the IDs of the channels the posts of a poll are in, starting with the channel it was
+// posted in. Channels that can't be looked up are logged and left out.
+func (p *MatterpollPlugin) pollPostChannelIDs(pl *poll.Poll) []string {
+	var channelIDs []string
+	for _, post := range pl.Posts() {
+		channelID := post.ChannelID
+		if channelID == "" && post.PostID == pl.PostID {
+			var err error
+			if channelID, err = p.pollChannelID(pl); err != nil {
+				p.API.LogWarn("failed to get channel of poll", "pollID", pl.ID, "error", err.Error())
+				continue
+			}
+		}
+		if channelID != "" {
+			channelIDs = append(channelIDs, channelID)
+		}
+	}
+	return channelIDs
+}
+
+// readableChannelID returns the ID of a channel a poll is posted in, that a user may read, like voting with the
+// buttons of its post requires. The given channel is preferred, if the poll is posted in it. It returns an empty
+// string if there is none.
+func (p *MatterpollPlugin) readableChannelID(pl *poll.Poll, channelID, userID string) string {
+	channelIDs := p.pollPostChannelIDs(pl)
+	for i, id := range channelIDs {
+		if id == channelID {
+			channelIDs[0], channelIDs[i] = channelIDs[i], channelIDs[0]
+			break
+		}
+	}
+	for _, id := range channelIDs {
+		if p.API.HasPermissionToChannel(userID, id, model.PERMISSION_READ_CHANNEL) {
+			return id
+		}
+	}
+	return ""
+}
+
 // ConvertUserIDToDisplayName returns the display name to a given user ID
 func (p *MatterpollPlugin) ConvertUserIDToDisplayName(userID string) (string, *model.AppError) {
 	user, err := p.API.GetUser(userID)
@@ -166,18 +304,24 @@ func (p *MatterpollPlugin) ConvertCreatorIDToDisplayName(creatorID string) (stri
 
 // CanManagePoll checks if a given user has the permission to manage i.e. end or delete a given poll
 func (p *MatterpollPlugin) CanManagePoll(poll *poll.Poll, issuerID string) (bool, *model.AppError) {
-	if issuerID == poll.Creator {
+	return p.isCreatorOrSystemAdmin(poll.Creator, issuerID)
+}
+
+// isCreatorOrSystemAdmin checks if a given user is either the creator of something or a System Admin
+func (p *MatterpollPlugin) isCreatorOrSystemAdmin(creatorID, issuerID string) (bool, *model.AppError) {
+	if issuerID == creatorID {
 		return true, nil
 	}
+	return p.isSystemAdmin(issuerID)
+}
 
-	user, appErr := p.API.GetUser(issuerID)
+// isSystemAdmin checks if a given user is a System Admin
+func (p *MatterpollPlugin) isSystemAdmin(userID string) (bool, *model.AppError) {
+	user, appErr := p.API.GetUser(userID)
 	if appErr != nil {
 		return false, appErr
 	}
-	if user.IsInRole(model.SYSTEM_ADMIN_ROLE_ID) {
-		return true, nil
-	}
-	return false, nil
+	return user.IsInRole(model.SYSTEM_ADMIN_ROLE_ID), nil
 }
 
 // SendEphemeralPost sends an ephemeral post to a user as the bot account
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
@@ -15,6 +16,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/text/language"
 
+	"github.com/matterpoll/matterpoll/server/metrics"
+	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store"
 	"github.com/matterpoll/matterpoll/server/store/kvstore"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
@@ -25,6 +28,7 @@ func setupTestPlugin(_ *testing.T, api *plugintest.API, store *mockstore.Store)
 	p := &MatterpollPlugin{
 		ServerConfig: testutils.GetServerConfig(),
 		getIconData:  getIconDataMock,
+		metrics:      metrics.New(),
 	}
 	p.setConfiguration(&configuration{
 		Trigger:        "poll",
@@ -56,6 +60,7 @@ func TestPluginOnActivate(t *testing.T) {
 		AutoComplete:         true,
 		AutoCompleteDesc:     "Create a poll",
 		AutoCompleteHint:     `"[Question]" "[Answer 1]" "[Answer 2]"...`,
+		AutocompleteData:     getAutocompleteData("poll", testutils.GetLocalizer()),
 		AutocompleteIconData: "someIconData",
 	}
 
@@ -72,6 +77,10 @@ func TestPluginOnActivate(t *testing.T) {
 				api.On("GetBundlePath").Return(path, nil)
 				api.On("PatchBot", testutils.GetBotUserID(), &model.BotPatch{Description: &botDescription.Other}).Return(nil, nil)
 				api.On("RegisterCommand", command).Return(nil)
+				api.On("SavePluginConfig", mock.MatchedBy(func(config map[string]interface{}) bool {
+					key, _ := config["voterhashkey"].(string)
+					return config["trigger"] == "poll" && len(key) == voterHashKeyLength
+				})).Return(nil)
 				return api
 			},
 			SetupHelpers: func(helpers *plugintest.Helpers) *plugintest.Helpers {
@@ -80,6 +89,22 @@ func TestPluginOnActivate(t *testing.T) {
 			},
 			ShouldError: false,
 		},
+		"SavePluginConfig fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				path, err := filepath.Abs("../..")
+				require.Nil(t, err)
+				api.On("GetBundlePath").Return(path, nil)
+				api.On("PatchBot", testutils.GetBotUserID(), &model.BotPatch{Description: &botDescription.Other}).Return(nil, nil)
+				api.On("RegisterCommand", command).Return(nil)
+				api.On("SavePluginConfig", mock.AnythingOfType("map[string]interface {}")).Return(&model.AppError{})
+				return api
+			},
+			SetupHelpers: func(helpers *plugintest.Helpers) *plugintest.Helpers {
+				helpers.On("EnsureBot", bot, mock.AnythingOfType("plugin.EnsureBotOption")).Return(testutils.GetBotUserID(), nil)
+				return helpers
+			},
+			ShouldError: true,
+		},
 		// i18n bundle tests
 		"GetBundlePath fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -135,10 +160,17 @@ func TestPluginOnActivate(t *testing.T) {
 			}
 
 			patch := monkey.Patch(kvstore.NewStore, func(plugin.API, string) (store.Store, error) {
-				return &mockstore.Store{}, nil
+				store := &mockstore.Store{}
+				store.PollStore.On("ListIDs").Return([]string{}, nil)
+				return store, nil
 			})
 			defer patch.Unpatch()
 
+			schedulePatch := monkey.Patch(cluster.Schedule, func(cluster.JobPluginAPI, string, cluster.NextWaitInterval, func()) (*cluster.Job, error) {
+				return &cluster.Job{}, nil
+			})
+			defer schedulePatch.Unpatch()
+
 			siteURL := testutils.GetSiteURL()
 			defaultClientLocale := "en"
 			p := &MatterpollPlugin{
@@ -217,9 +249,73 @@ func TestPluginOnActivate(t *testing.T) {
 	})
 }
 
+func TestPluginHashAnonymousVoters(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	anonymousPoll := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+	anonymousPoll.SetVoterKey("key")
+	hashedPoll := anonymousPoll.Copy()
+	require.True(t, hashedPoll.HashVoters())
+
+	api := &plugintest.API{}
+	api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+	defer api.AssertExpectations(t)
+	store := &mockstore.Store{}
+	store.PollStore.On("ListIDs").Return([]string{"pollID1", "pollID2", "pollID3"}, nil)
+	store.PollStore.On("Get", "pollID1").Return(anonymousPoll.Copy(), nil)
+	store.PollStore.On("Get", "pollID2").Return(testutils.GetPollWithVotes(), nil)
+	store.PollStore.On("Get", "pollID3").Return(nil, errors.New(""))
+	store.PollStore.On("Update", anonymousPoll, hashedPoll).Return(nil)
+	defer store.AssertExpectations(t)
+
+	p := setupTestPlugin(t, api, store)
+	p.setConfiguration(&configuration{Trigger: "poll", VoterHashKey: "key"})
+	p.hashAnonymousVoters()
+}
+
 func TestPluginOnDeactivate(t *testing.T) {
 	p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
 
 	err := p.OnDeactivate()
 	assert.Nil(t, err)
 }
+
+func TestPluginPollChannelID(t *testing.T) {
+	t.Run("stored channel", func(t *testing.T) {
+		pl := testutils.GetPoll()
+		pl.ChannelID = "channelID1"
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		channelID, err := p.pollChannelID(pl)
+		require.NoError(t, err)
+		assert.Equal(t, "channelID1", channelID)
+	})
+	t.Run("legacy poll", func(t *testing.T) {
+		pl := testutils.GetPoll()
+		api := &plugintest.API{}
+		api.On("GetPost", pl.PostID).Return(&model.Post{ChannelId: "channelID2"}, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		channelID, err := p.pollChannelID(pl)
+		require.NoError(t, err)
+		assert.Equal(t, "channelID2", channelID)
+	})
+	t.Run("legacy poll, GetPost fails", func(t *testing.T) {
+		pl := testutils.GetPoll()
+		api := &plugintest.API{}
+		api.On("GetPost", pl.PostID).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		_, err := p.pollChannelID(pl)
+		assert.Error(t, err)
+	})
+	t.Run("poll without post", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		_, err := p.pollChannelID(testutils.GetPollWithoutPostID())
+		assert.Error(t, err)
+	})
+}
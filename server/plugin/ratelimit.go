@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+var (
+	responseRateLimitPolls = &i18n.Message{
+		ID:    "response.rateLimit.polls",
+		Other: "You can only create {{.Limit}} polls per hour. Please try again later.",
+	}
+	responseRateLimitAddedOptions = &i18n.Message{
+		ID:    "response.rateLimit.addedOptions",
+		Other: "You can only add {{.Limit}} options to this poll.",
+	}
+)
+
+// pollCreationWindow is the period in which a user can create at most MaxPollsPerHour polls.
+const pollCreationWindow = time.Hour
+
+// allowPollCreation counts a poll a user wants to create and returns an error message,
+// if the user already created MaxPollsPerHour polls within the last hour.
+func (p *MatterpollPlugin) allowPollCreation(userID string) (*poll.ErrorMessage, error) {
+	limit := p.getConfiguration().maxPollsPerHour()
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		allowed, err := p.Store.RateLimit().Take("polls_"+userID, limit, pollCreationWindow)
+		if err == nil {
+			if allowed {
+				return nil, nil
+			}
+			return &poll.ErrorMessage{
+				Message:    responseRateLimitPolls,
+				Data:       map[string]interface{}{"Limit": limit},
+				StatusCode: http.StatusTooManyRequests,
+			}, nil
+		}
+		if errors.Cause(err) != store.ErrConflict || attempt == maxUpdateAttempts {
+			return nil, errors.Wrap(err, "failed to take rate limit")
+		}
+	}
+}
+
+// checkAddedOptionLimit returns an error message, if a user already added MaxAddedOptionsPerUser answer options
+// to a poll. The creator of the poll and System Admins aren't limited.
+func (p *MatterpollPlugin) checkAddedOptionLimit(pl *poll.Poll, userID string) (*poll.ErrorMessage, error) {
+	limit := p.getConfiguration().maxAddedOptionsPerUser()
+	if limit <= 0 || pl.AddedOptionCount(userID) < limit {
+		return nil, nil
+	}
+
+	canManagePoll, appErr := p.CanManagePoll(pl, userID)
+	if appErr != nil {
+		return nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if canManagePoll {
+		return nil, nil
+	}
+	return &poll.ErrorMessage{
+		Message:    responseRateLimitAddedOptions,
+		Data:       map[string]interface{}{"Limit": limit},
+		StatusCode: http.StatusTooManyRequests,
+	}, nil
+}
+
+// recordAddedOption counts an answer option a user added to a poll, if the number of added options is limited.
+func (p *MatterpollPlugin) recordAddedOption(pl *poll.Poll, userID string) {
+	if p.getConfiguration().maxAddedOptionsPerUser() > 0 {
+		pl.RecordAddedOption(userID)
+	}
+}
@@ -0,0 +1,164 @@
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// addPollReactions adds the emojis users vote with to the post of a poll, see poll.Settings.Reactions.
+func (p *MatterpollPlugin) addPollReactions(poll *poll.Poll, postID string) error {
+	for _, emoji := range poll.ReactionEmojis() {
+		if err := p.addPollReaction(postID, emoji); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPollReaction adds an emoji users vote with to the post of a poll.
+func (p *MatterpollPlugin) addPollReaction(postID, emojiName string) error {
+	reaction := &model.Reaction{
+		UserId:    p.botUserID,
+		PostId:    postID,
+		EmojiName: emojiName,
+	}
+	if _, appErr := p.API.AddReaction(reaction); appErr != nil {
+		return errors.Wrap(appErr, "failed to add reaction")
+	}
+	return nil
+}
+
+// ReactionHasBeenAdded counts a reaction to the post of a poll as vote, if users vote with reactions.
+// Mattermost invokes this hook since server version 5.30.
+func (p *MatterpollPlugin) ReactionHasBeenAdded(c *plugin.Context, reaction *model.Reaction) {
+	p.handleReaction(reaction, true)
+}
+
+// ReactionHasBeenRemoved removes the vote of a reaction to the post of a poll, if users vote with reactions.
+// Mattermost invokes this hook since server version 5.30.
+func (p *MatterpollPlugin) ReactionHasBeenRemoved(c *plugin.Context, reaction *model.Reaction) {
+	p.handleReaction(reaction, false)
+}
+
+// getReactionPoll returns the post a reaction belongs to and the ID of its poll.
+// The post is nil if the reaction was given by the bot or doesn't belong to the post of a running poll.
+func (p *MatterpollPlugin) getReactionPoll(reaction *model.Reaction) (*model.Post, string) {
+	if reaction.UserId == p.botUserID {
+		return nil, ""
+	}
+
+	post, appErr := p.API.GetPost(reaction.PostId)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get post of reaction", "postID", reaction.PostId, "error", appErr.Error())
+		return nil, ""
+	}
+	// The end poll post replaces the type of the post
+	if post.UserId != p.botUserID || post.Type != MatterpollPostType {
+		return nil, ""
+	}
+	pollID, ok := post.GetProp("poll_id").(string)
+	if !ok || pollID == "" {
+		return nil, ""
+	}
+	return post, pollID
+}
+
+// handleReaction adds or removes the vote of a user that reacted to the post of a poll.
+// Reactions of votes that aren't counted are removed again. In single answer mode,
+// the reaction of the previous vote of the user is removed as well.
+func (p *MatterpollPlugin) handleReaction(reaction *model.Reaction, added bool) {
+	post, pollID := p.getReactionPoll(reaction)
+	if post == nil {
+		return
+	}
+	userID := reaction.UserId
+
+	var replaced []string
+	var changed, closed bool
+	poll, err := p.updatePoll(pollID, func(poll *poll.Poll) (bool, error) {
+		replaced, changed, closed = nil, false, false
+		index := poll.AnswerOptionIndexFromReaction(reaction.EmojiName)
+		if index == -1 {
+			return false, nil
+		}
+
+		var err error
+		if !added {
+			changed, err = poll.RemoveVote(userID, index)
+			return changed, err
+		}
+
+		if voted, _ := poll.HasVotedFor(userID, index); voted {
+			return false, nil
+		}
+		if err = p.checkChannelVoter(poll, post.ChannelId, userID); err != nil {
+			return false, err
+		}
+		if !poll.IsMultiVote() {
+			for i := range poll.AnswerOptions {
+				if voted, _ := poll.HasVotedFor(userID, i); voted {
+					replaced = append(replaced, poll.ReactionEmoji(i))
+				}
+			}
+		}
+		if err = poll.UpdateVote(userID, index); err != nil {
+			return false, err
+		}
+		changed = true
+		// A poll that got closed is only saved if it can be re-opened. Otherwise it's deleted by endPoll.
+		closed = poll.MaybeAutoClose()
+		return !closed || p.getConfiguration().endedPollLifetime() > 0, nil
+	})
+	if err != nil {
+		if lc := localizeConfigFromVoteError(err); lc != nil {
+			if added {
+				p.removeReaction(reaction)
+			}
+			p.SendEphemeralPost(post.ChannelId, userID, post.RootId, p.LocalizeWithConfig(p.getUserLocalizer(userID), lc))
+			return
+		}
+		p.API.LogWarn("Failed to update poll from reaction", "pollID", pollID, "error", err.Error())
+		return
+	}
+	if !changed {
+		return
+	}
+	if added {
+		p.metrics.IncVotesCast()
+	}
+
+	for _, emoji := range replaced {
+		p.removeReaction(&model.Reaction{UserId: userID, PostId: post.Id, EmojiName: emoji})
+	}
+
+	if closed {
+		if err := p.endPoll(poll, ""); err != nil {
+			p.API.LogWarn("Failed to end poll from reaction", "pollID", pollID, "error", err.Error())
+		}
+		return
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get display name for creator", "pollID", pollID, "error", appErr.Error())
+		return
+	}
+	model.ParseSlackAttachment(post, poll.ToPostActions(p.getPollLocalizer(poll), manifest.Id, displayName))
+	if _, appErr := p.API.UpdatePost(post); appErr != nil {
+		p.API.LogWarn("Failed to update post", "postID", post.Id, "error", appErr.Error())
+		return
+	}
+
+	go p.publishPollMetadata(poll, userID)
+	p.publishPollResults(websocketEventVote, poll, post.ChannelId)
+}
+
+// removeReaction removes a reaction from the post of a poll.
+func (p *MatterpollPlugin) removeReaction(reaction *model.Reaction) {
+	if appErr := p.API.RemoveReaction(reaction); appErr != nil {
+		p.API.LogWarn("Failed to remove reaction", "postID", reaction.PostId, "error", appErr.Error())
+	}
+}
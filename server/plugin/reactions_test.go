@@ -0,0 +1,258 @@
+package plugin
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPluginAddPollReactions(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		for _, emoji := range []string{"one", "two", "three"} {
+			api.On("AddReaction", &model.Reaction{UserId: testutils.GetBotUserID(), PostId: "postID1", EmojiName: emoji}).Return(nil, nil)
+		}
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		err := p.addPollReactions(testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Reactions: true}), "postID1")
+		assert.Nil(t, err)
+	})
+	t.Run("no reactions", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		err := p.addPollReactions(testutils.GetPoll(), "postID1")
+		assert.Nil(t, err)
+	})
+	t.Run("AddReaction fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("AddReaction", mock.AnythingOfType("*model.Reaction")).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		err := p.addPollReactions(testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Reactions: true}), "postID1")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestPluginHandleReaction(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	pollPost := func() *model.Post {
+		return &model.Post{
+			Id:        "postID1",
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: "channelID1",
+			Type:      MatterpollPostType,
+			Props:     model.StringInterface{"poll_id": testutils.GetPollID()},
+		}
+	}
+	reaction := func(emoji string) *model.Reaction {
+		return &model.Reaction{UserId: "userID1", PostId: "postID1", EmojiName: emoji}
+	}
+
+	poll1In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Reactions: true})
+	poll1Out := poll1In.Copy()
+	require.Nil(t, poll1Out.UpdateVote("userID1", 0))
+
+	poll2In := poll1Out.Copy()
+	poll2Out := poll2In.Copy()
+	require.Nil(t, poll2Out.UpdateVote("userID1", 1))
+
+	poll3In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2, Reactions: true})
+	require.Nil(t, poll3In.UpdateVote("userID1", 0))
+	require.Nil(t, poll3In.UpdateVote("userID1", 1))
+
+	poll4Out := poll1Out.Copy()
+	removed, err := poll4Out.RemoveVote("userID1", 0)
+	require.Nil(t, err)
+	require.True(t, removed)
+
+	poll5In := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Reactions: true, Quorum: 1, CloseOnQuorum: true})
+
+	for name, test := range map[string]struct {
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		SetupStore func(*mockstore.Store) *mockstore.Store
+		Reaction   *model.Reaction
+		Added      bool
+	}{
+		"Reaction of the bot": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Reaction:   &model.Reaction{UserId: testutils.GetBotUserID(), PostId: "postID1", EmojiName: "one"},
+			Added:      true,
+		},
+		"Reaction to another post": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", UserId: "userID2"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Reaction:   reaction("one"),
+			Added:      true,
+		},
+		"Other emoji": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(pollPost(), nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll1In.Copy(), nil)
+				return store
+			},
+			Reaction: reaction("smile"),
+			Added:    true,
+		},
+		"Vote counted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(pollPost(), nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.Type == MatterpollPostType
+				})).Return(nil, nil)
+				api.On("PublishWebSocketEvent", "has_voted", mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{UserId: "userID1"}).Return().Maybe()
+				api.On("PublishWebSocketEvent", websocketEventVote, poll1Out.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll1In.Copy(), nil)
+				store.PollStore.On("Update", poll1In, poll1Out).Return(nil)
+				return store
+			},
+			Reaction: reaction("one"),
+			Added:    true,
+		},
+		"Vote replaced": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(pollPost(), nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("RemoveReaction", reaction("one")).Return(nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", "has_voted", mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{UserId: "userID1"}).Return().Maybe()
+				api.On("PublishWebSocketEvent", websocketEventVote, poll2Out.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll2In.Copy(), nil)
+				store.PollStore.On("Update", poll2In, poll2Out).Return(nil)
+				return store
+			},
+			Reaction: reaction("two"),
+			Added:    true,
+		},
+		"Vote rejected": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(pollPost(), nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("RemoveReaction", reaction("three")).Return(nil)
+				api.On("SendEphemeralPost", "userID1", mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "channelID1" && post.UserId == testutils.GetBotUserID()
+				})).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll3In.Copy(), nil)
+				return store
+			},
+			Reaction: reaction("three"),
+			Added:    true,
+		},
+		"Vote removed": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(pollPost(), nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", "has_voted", mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{UserId: "userID1"}).Return().Maybe()
+				api.On("PublishWebSocketEvent", websocketEventVote, poll4Out.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll1Out.Copy(), nil)
+				store.PollStore.On("Update", poll1Out, poll4Out).Return(nil)
+				return store
+			},
+			Reaction: reaction("one"),
+			Added:    false,
+		},
+		"Removed reaction without vote": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(pollPost(), nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll1In.Copy(), nil)
+				return store
+			},
+			Reaction: reaction("one"),
+			Added:    false,
+		},
+		"Quorum reached with close-on-quorum": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(pollPost(), nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll5In.Copy(), nil)
+				store.PollStore.On("Delete", mock.AnythingOfType("*poll.Poll")).Return(nil)
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
+				return store
+			},
+			Reaction: reaction("one"),
+			Added:    true,
+		},
+		"GetPost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(nil, &model.AppError{})
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Reaction:   reaction("one"),
+			Added:      true,
+		},
+		"Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(pollPost(), nil)
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll1In.Copy(), nil)
+				store.PollStore.On("Update", poll1In, poll1Out).Return(errors.New(""))
+				return store
+			},
+			Reaction: reaction("one"),
+			Added:    true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			if test.Added {
+				p.ReactionHasBeenAdded(nil, test.Reaction)
+			} else {
+				p.ReactionHasBeenRemoved(nil, test.Reaction)
+			}
+		})
+	}
+}
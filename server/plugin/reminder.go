@@ -0,0 +1,229 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// reminderUsersPerPage is the number of channel members fetched at once when looking for users who haven't voted.
+const reminderUsersPerPage = 200
+
+var (
+	reminderMessage = &i18n.Message{
+		ID:    "reminder.message",
+		Other: "You haven't voted in the poll **{{.Question}}** yet: {{.Link}}",
+	}
+	responseRemindNonVotersInvalidPermission = &i18n.Message{
+		ID:    "response.remindNonVoters.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to remind users who haven't voted.",
+	}
+	responseRemindNonVotersEnded = &i18n.Message{
+		ID:    "response.remindNonVoters.ended",
+		Other: "The poll has already ended.",
+	}
+	responseRemindNonVotersNobody = &i18n.Message{
+		ID:    "response.remindNonVoters.nobody",
+		Other: "Everybody in this channel has already voted.",
+	}
+	responseRemindNonVotersSuccess = &i18n.Message{
+		ID:    "response.remindNonVoters.success",
+		Other: "A reminder has been sent to {{.Users}}.",
+	}
+	responseRemindNonVotersSuccessAnonymous = &i18n.Message{
+		ID:    "response.remindNonVoters.successAnonymous",
+		One:   "A reminder has been sent to {{.Count}} user who hasn't voted yet.",
+		Other: "A reminder has been sent to {{.Count}} users who haven't voted yet.",
+	}
+	responseRemindNonVotersSuccessSecret = &i18n.Message{
+		ID:    "response.remindNonVoters.successSecret",
+		Other: "A reminder has been sent to everyone who hasn't voted yet.",
+	}
+	responseShowNonVotersInvalidPermission = &i18n.Message{
+		ID:    "response.showNonVoters.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to see who hasn't voted.",
+	}
+	responseShowNonVotersHidden = &i18n.Message{
+		ID:    "response.showNonVoters.hidden",
+		Other: "Who hasn't voted can't be shown for anonymous and secret polls.",
+	}
+	responseShowNonVotersNobody = &i18n.Message{
+		ID:    "response.showNonVoters.nobody",
+		Other: "Everybody in this channel has voted ({{.Voted}} of {{.Eligible}}, 100%).",
+	}
+	responseShowNonVotersSuccess = &i18n.Message{
+		ID:    "response.showNonVoters.success",
+		One:   "{{.Voted}} of {{.Eligible}} members of this channel have voted ({{.Rate}}%). {{.Count}} user hasn't voted yet: {{.Users}}",
+		Other: "{{.Voted}} of {{.Eligible}} members of this channel have voted ({{.Rate}}%). {{.Count}} users haven't voted yet: {{.Users}}",
+	}
+)
+
+// channelNonVoters returns the members of a channel who may vote in a poll, but haven't voted yet.
+// Bots and deactivated users are left out.
+func (p *MatterpollPlugin) channelNonVoters(poll *poll.Poll, channelID string) ([]*model.User, error) {
+	nonVoters, _, err := p.channelParticipation(poll, channelID)
+	return nonVoters, err
+}
+
+// channelParticipation returns the members of a channel who may vote in a poll, but haven't voted yet,
+// and the number of members who may vote. Bots and deactivated users are left out.
+func (p *MatterpollPlugin) channelParticipation(poll *poll.Poll, channelID string) ([]*model.User, int, error) {
+	var nonVoters []*model.User
+	eligible := 0
+	for page := 0; ; page++ {
+		users, appErr := p.API.GetUsersInChannel(channelID, model.CHANNEL_SORT_BY_USERNAME, page, reminderUsersPerPage)
+		if appErr != nil {
+			return nil, 0, errors.Wrap(appErr, "failed to get users in channel")
+		}
+
+		byID := make(map[string]*model.User, len(users))
+		var userIDs []string
+		for _, user := range users {
+			if user.IsBot || user.DeleteAt != 0 {
+				continue
+			}
+			byID[user.Id] = user
+			userIDs = append(userIDs, user.Id)
+			if poll.CanVote(user.Id) {
+				eligible++
+			}
+		}
+		for _, userID := range poll.NonVoters(userIDs) {
+			nonVoters = append(nonVoters, byID[userID])
+		}
+
+		if len(users) < reminderUsersPerPage {
+			return nonVoters, eligible, nil
+		}
+	}
+}
+
+// remindNonVoters sends a direct message from the bot to every member of the channel who hasn't voted in a poll yet.
+// It returns the users who got reminded. Users who can't be reminded are logged and skipped.
+func (p *MatterpollPlugin) remindNonVoters(poll *poll.Poll, channelID string) ([]*model.User, error) {
+	nonVoters, err := p.channelNonVoters(poll, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	link := fmt.Sprintf("%s/_redirect/pl/%s", *p.ServerConfig.ServiceSettings.SiteURL, poll.PostID)
+	var reminded []*model.User
+	for _, user := range nonVoters {
+		if err := p.sendReminder(poll, user.Id, link); err != nil {
+			p.API.LogWarn("Failed to remind user", "pollID", poll.ID, "userID", user.Id, "error", err.Error())
+			continue
+		}
+		reminded = append(reminded, user)
+	}
+	return reminded, nil
+}
+
+// sendReminder reminds a user to vote in a poll in a direct message from the bot.
+func (p *MatterpollPlugin) sendReminder(poll *poll.Poll, userID, link string) error {
+	channel, appErr := p.API.GetDirectChannel(userID, p.botUserID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get direct channel")
+	}
+
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message: p.LocalizeWithConfig(p.getUserLocalizer(userID), &i18n.LocalizeConfig{
+			DefaultMessage: reminderMessage,
+			TemplateData: map[string]interface{}{
+				"Question": poll.Question,
+				"Link":     link,
+			},
+		}),
+	}
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to create post")
+	}
+	return nil
+}
+
+// remindNonVotersResponse returns the response to the user who sent the reminders.
+// It doesn't reveal who hasn't voted in anonymous polls and how many users have voted in secret polls.
+func remindNonVotersResponse(poll *poll.Poll, reminded []*model.User) *i18n.LocalizeConfig {
+	switch {
+	case poll.HidesResults():
+		return &i18n.LocalizeConfig{DefaultMessage: responseRemindNonVotersSuccessSecret}
+	case len(reminded) == 0:
+		return &i18n.LocalizeConfig{DefaultMessage: responseRemindNonVotersNobody}
+	case poll.HidesVoters():
+		return &i18n.LocalizeConfig{
+			DefaultMessage: responseRemindNonVotersSuccessAnonymous,
+			TemplateData:   map[string]interface{}{"Count": len(reminded)},
+			PluralCount:    len(reminded),
+		}
+	default:
+		usernames := make([]string, len(reminded))
+		for i, user := range reminded {
+			usernames[i] = "@" + user.Username
+		}
+		return &i18n.LocalizeConfig{
+			DefaultMessage: responseRemindNonVotersSuccess,
+			TemplateData:   map[string]interface{}{"Users": strings.Join(usernames, ", ")},
+		}
+	}
+}
+
+// showNonVotersResponse returns the list of users who haven't voted and the participation rate of the channel.
+func showNonVotersResponse(nonVoters []*model.User, eligible int) *i18n.LocalizeConfig {
+	voted := eligible - len(nonVoters)
+	if len(nonVoters) == 0 {
+		return &i18n.LocalizeConfig{
+			DefaultMessage: responseShowNonVotersNobody,
+			TemplateData:   map[string]interface{}{"Voted": voted, "Eligible": eligible},
+		}
+	}
+
+	usernames := make([]string, len(nonVoters))
+	for i, user := range nonVoters {
+		usernames[i] = "@" + user.Username
+	}
+	return &i18n.LocalizeConfig{
+		DefaultMessage: responseShowNonVotersSuccess,
+		TemplateData: map[string]interface{}{
+			"Voted":    voted,
+			"Eligible": eligible,
+			"Rate":     voted * 100 / eligible,
+			"Count":    len(nonVoters),
+			"Users":    strings.Join(usernames, ", "),
+		},
+		PluralCount: len(nonVoters),
+	}
+}
+
+// sendAutomaticReminder reminds the users who haven't voted in a poll yet, if its automatic reminder is due at now.
+// The reminder is marked as sent before any message goes out, so it's never sent twice, e.g. by another node of a cluster.
+func (p *MatterpollPlugin) sendAutomaticReminder(pollID string, now int64) error {
+	due := false
+	poll, err := p.updatePoll(pollID, func(poll *poll.Poll) (bool, error) {
+		due = poll.ReminderDue(now)
+		if due {
+			poll.MarkReminded(now)
+		}
+		return due, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !due {
+		return nil
+	}
+
+	channelID, err := p.pollChannelID(poll)
+	if err != nil {
+		return err
+	}
+	if _, err := p.remindNonVoters(poll, channelID); err != nil {
+		return errors.Wrap(err, "failed to remind users")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// pruneResult contains the number of polls pruneStore deleted.
+type pruneResult struct {
+	// Ended is the number of ended polls whose grace period and retention period have passed.
+	Ended int
+	// Orphaned is the number of polls whose post has been deleted, e.g. together with its channel.
+	Orphaned int
+	// Corrupt is the number of polls whose stored data couldn't be decoded.
+	Corrupt int
+}
+
+// pruneStore deletes all polls from the store that aren't needed anymore: Ended polls once they can't be re-opened
+// anymore and their retention period has passed, and polls whose post has been deleted, since nobody can vote in them
+// or see their results. Corrupt polls are deleted too, since they can't be used anymore. Other polls that can't be read,
+// e.g. because of a KV Store error or a newer schema version, are skipped.
+func (p *MatterpollPlugin) pruneStore() (*pruneResult, error) {
+	pollIDs, err := p.Store.Poll().ListIDs()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list polls")
+	}
+
+	result := &pruneResult{}
+	now := model.GetMillis()
+	lifetime := p.getConfiguration().endedPollLifetime()
+	for _, pollID := range pollIDs {
+		pl, err := p.getPoll(pollID)
+		if errors.Is(err, poll.ErrCorruptPoll) {
+			if err := p.Store.Poll().Delete(&poll.Poll{ID: pollID}); err != nil {
+				p.API.LogWarn("Failed to delete corrupt poll", "pollID", pollID, "error", err.Error())
+				continue
+			}
+			result.Corrupt++
+			continue
+		}
+		if err != nil {
+			p.API.LogWarn("Failed to get poll", "pollID", pollID, "error", err.Error())
+			continue
+		}
+
+		orphaned := false
+		if !pl.ReopenPeriodPassed(now, lifetime) {
+			if pl.PostID == "" {
+				continue
+			}
+			_, appErr := p.API.GetPost(pl.PostID)
+			if appErr == nil || appErr.StatusCode != http.StatusNotFound {
+				continue
+			}
+			orphaned = true
+		}
+
+		if err := p.Store.Poll().Delete(pl); err != nil {
+			p.API.LogWarn("Failed to delete poll", "pollID", pollID, "error", err.Error())
+			continue
+		}
+		if orphaned {
+			result.Orphaned++
+		} else {
+			result.Ended++
+		}
+	}
+	return result, nil
+}
+
+// runPruneStoreJob prunes the store and logs the result.
+func (p *MatterpollPlugin) runPruneStoreJob() {
+	result, err := p.pruneStore()
+	if err != nil {
+		p.API.LogWarn("Failed to prune store", "error", err.Error())
+		return
+	}
+	p.API.LogDebug("Pruned store", "ended", result.Ended, "orphaned", result.Orphaned, "corrupt", result.Corrupt)
+}
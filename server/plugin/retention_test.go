@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPluginPruneStore(t *testing.T) {
+	const day = 24 * 60 * 60 * 1000
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 10 * day })
+	defer patch.Unpatch()
+
+	runningPoll := testutils.GetPoll()
+	runningPoll.ID = "pollID1"
+	recentlyEndedPoll := testutils.GetPoll()
+	recentlyEndedPoll.ID = "pollID2"
+	recentlyEndedPoll.PostID = "postID2"
+	recentlyEndedPoll.EndedAt = 8 * day
+	oldEndedPoll := testutils.GetPoll()
+	oldEndedPoll.ID = "pollID3"
+	oldEndedPoll.PostID = "postID3"
+	oldEndedPoll.EndedAt = 6 * day
+	orphanedPoll := testutils.GetPoll()
+	orphanedPoll.ID = "pollID4"
+	orphanedPoll.PostID = "postID4"
+	unpostedPoll := testutils.GetPoll()
+	unpostedPoll.ID = "pollID5"
+	unpostedPoll.PostID = ""
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+		api.On("GetPost", "postID2").Return(&model.Post{Id: "postID2"}, nil)
+		api.On("GetPost", "postID4").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+		defer api.AssertExpectations(t)
+
+		store := &mockstore.Store{}
+		store.PollStore.On("ListIDs").Return([]string{"pollID1", "pollID2", "pollID3", "pollID4", "pollID5"}, nil)
+		store.PollStore.On("Get", "pollID1").Return(runningPoll.Copy(), nil)
+		store.PollStore.On("Get", "pollID2").Return(recentlyEndedPoll.Copy(), nil)
+		store.PollStore.On("Get", "pollID3").Return(oldEndedPoll.Copy(), nil)
+		store.PollStore.On("Get", "pollID4").Return(orphanedPoll.Copy(), nil)
+		store.PollStore.On("Get", "pollID5").Return(unpostedPoll.Copy(), nil)
+		store.PollStore.On("Delete", oldEndedPoll).Return(nil)
+		store.PollStore.On("Delete", orphanedPoll).Return(nil)
+		defer store.AssertExpectations(t)
+
+		p := setupTestPlugin(t, api, store)
+		p.configuration.RetentionDays = "3"
+
+		result, err := p.pruneStore()
+		require.Nil(t, err)
+		assert.Equal(t, &pruneResult{Ended: 1, Orphaned: 1}, result)
+	})
+	t.Run("GetPost() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetPost", "postID1").Return(nil, &model.AppError{StatusCode: http.StatusInternalServerError})
+		defer api.AssertExpectations(t)
+
+		store := &mockstore.Store{}
+		store.PollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+		store.PollStore.On("Get", "pollID1").Return(runningPoll.Copy(), nil)
+		defer store.AssertExpectations(t)
+
+		p := setupTestPlugin(t, api, store)
+
+		result, err := p.pruneStore()
+		require.Nil(t, err)
+		assert.Equal(t, &pruneResult{}, result)
+	})
+	t.Run("Get() and Delete() fail", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+		defer api.AssertExpectations(t)
+
+		store := &mockstore.Store{}
+		store.PollStore.On("ListIDs").Return([]string{"pollID1", "pollID3"}, nil)
+		store.PollStore.On("Get", "pollID1").Return(nil, errors.New(""))
+		store.PollStore.On("Get", "pollID3").Return(oldEndedPoll.Copy(), nil)
+		store.PollStore.On("Delete", oldEndedPoll).Return(errors.New(""))
+		defer store.AssertExpectations(t)
+
+		p := setupTestPlugin(t, api, store)
+
+		result, err := p.pruneStore()
+		require.Nil(t, err)
+		assert.Equal(t, &pruneResult{}, result)
+	})
+	t.Run("corrupt poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogError", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+		defer api.AssertExpectations(t)
+
+		store := &mockstore.Store{}
+		store.PollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+		store.PollStore.On("Get", "pollID1").Return(nil, poll.ErrCorruptPoll)
+		store.PollStore.On("Delete", &poll.Poll{ID: "pollID1"}).Return(nil)
+		defer store.AssertExpectations(t)
+
+		p := setupTestPlugin(t, api, store)
+
+		result, err := p.pruneStore()
+		require.Nil(t, err)
+		assert.Equal(t, &pruneResult{Corrupt: 1}, result)
+	})
+	t.Run("ListIDs() fails", func(t *testing.T) {
+		store := &mockstore.Store{}
+		store.PollStore.On("ListIDs").Return(nil, errors.New(""))
+		defer store.AssertExpectations(t)
+
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		result, err := p.pruneStore()
+		assert.NotNil(t, err)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,169 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+const (
+	// endPollJobKey is the key of the cluster job that ends polls after their deadline.
+	endPollJobKey = "end_poll_job"
+
+	// endPollJobInterval is the time between two runs of the job that ends polls after their deadline.
+	endPollJobInterval = time.Minute
+
+	// postScheduledPollsJobKey is the key of the cluster job that posts scheduled polls.
+	postScheduledPollsJobKey = "post_scheduled_polls_job"
+
+	// postScheduledPollsJobInterval is the time between two runs of the job that posts scheduled polls.
+	postScheduledPollsJobInterval = time.Minute
+
+	// pruneStoreJobKey is the key of the cluster job that removes polls that aren't needed anymore from the store.
+	pruneStoreJobKey = "prune_store_job"
+
+	// pruneStoreJobInterval is the time between two runs of the job that prunes the store.
+	pruneStoreJobInterval = 24 * time.Hour
+
+	// sendDigestsJobKey is the key of the cluster job that sends the daily digest.
+	sendDigestsJobKey = "send_digests_job"
+
+	// sendDigestsJobInterval is the time between two runs of the job that sends the daily digest.
+	sendDigestsJobInterval = 24 * time.Hour
+)
+
+// endExpiredPolls ends all polls whose deadline has passed and deletes ended polls after their grace period for
+// re-opening and their retention period have passed. Polls whose automatic reminder is due get their reminder sent.
+func (p *MatterpollPlugin) endExpiredPolls() {
+	pollIDs, err := p.Store.Poll().ListIDs()
+	if err != nil {
+		p.API.LogWarn("Failed to list polls", "error", err.Error())
+		return
+	}
+
+	now := model.GetMillis()
+	lifetime := p.getConfiguration().endedPollLifetime()
+	for _, pollID := range pollIDs {
+		poll, err := p.getPoll(pollID)
+		if err != nil {
+			p.API.LogWarn("Failed to get poll", "pollID", pollID, "error", err.Error())
+			continue
+		}
+
+		// Ended polls are kept until they can't be re-opened anymore and their retention period has passed
+		if poll.HasEnded() {
+			if poll.ReopenPeriodPassed(now, lifetime) {
+				if err := p.Store.Poll().Delete(poll); err != nil {
+					p.API.LogWarn("Failed to delete ended poll", "pollID", pollID, "error", err.Error())
+				}
+			}
+			continue
+		}
+
+		if !poll.DeadlinePassed(now) {
+			if poll.ReminderDue(now) {
+				if err := p.sendAutomaticReminder(pollID, now); err != nil {
+					p.API.LogWarn("Failed to send automatic reminder", "pollID", pollID, "error", err.Error())
+				}
+			}
+			continue
+		}
+
+		if err := p.endPoll(poll, ""); err != nil {
+			p.API.LogWarn("Failed to end poll after its deadline", "pollID", pollID, "error", err.Error())
+		}
+	}
+}
+
+// endPoll ends a poll, updates its post and announces the end in the channel of the poll.
+// actorID is the user who ended the poll. It's empty if the plugin ended it, e.g. after its deadline or once its quorum was reached.
+func (p *MatterpollPlugin) endPoll(poll *poll.Poll, actorID string) error {
+	if poll.PostID == "" {
+		return errors.New("poll has no post")
+	}
+
+	oldPost, appErr := p.API.GetPost(poll.PostID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get post")
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get display name for creator")
+	}
+	if err := p.readAttributeWeights(poll); err != nil {
+		return err
+	}
+
+	post, appErr := poll.ToEndPollPost(p.getPollLocalizer(poll), displayName, p.ConvertUserIDToDisplayName)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get convert to end poll post")
+	}
+
+	post.Id = poll.PostID
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to update post")
+	}
+	p.updateBroadcastPosts(poll, post, post.Id)
+
+	if err := p.storeEndedPoll(poll, oldPost.ChannelId); err != nil {
+		return err
+	}
+	p.saveAuditRecord(p.newAuditRecord(auditEventPollEnded, actorID, oldPost.ChannelId, poll))
+
+	p.postEndPollAnnouncement(oldPost.ChannelId, poll.PostID, poll, post)
+	p.publishPollEnded(poll, oldPost.ChannelId)
+
+	if p.getConfiguration().ResultsWebhookURL != "" {
+		// The receiver may be slow, ending the poll shouldn't wait for it
+		go func() {
+			if err := p.sendResultsWebhook(poll); err != nil {
+				p.API.LogWarn("Failed to send results webhook", "pollID", poll.ID, "error", err.Error())
+			}
+		}()
+	}
+
+	return nil
+}
+
+// postScheduledPolls posts all scheduled polls whose time has come.
+func (p *MatterpollPlugin) postScheduledPolls() {
+	pollIDs, err := p.Store.ScheduledPoll().ListIDs()
+	if err != nil {
+		p.API.LogWarn("Failed to list scheduled polls", "error", err.Error())
+		return
+	}
+
+	now := model.GetMillis()
+	for _, pollID := range pollIDs {
+		scheduledPoll, err := p.Store.ScheduledPoll().Get(pollID)
+		if err != nil {
+			p.API.LogWarn("Failed to get scheduled poll", "pollID", pollID, "error", err.Error())
+			continue
+		}
+
+		if !scheduledPoll.IsDue(now) {
+			continue
+		}
+
+		// The scheduled poll is deleted first, so it never gets posted twice
+		if err := p.Store.ScheduledPoll().Delete(pollID); err != nil {
+			p.API.LogWarn("Failed to delete scheduled poll", "pollID", pollID, "error", err.Error())
+			continue
+		}
+
+		// Recurring polls are scheduled again, even if posting this occurrence fails
+		if next := scheduledPoll.Next(now); next != nil {
+			if err := p.Store.ScheduledPoll().Insert(next); err != nil {
+				p.API.LogWarn("Failed to schedule next occurrence of poll", "pollID", pollID, "error", err.Error())
+			}
+		}
+
+		if err := p.postPoll(scheduledPoll.Poll, scheduledPoll.ChannelID, scheduledPoll.RootID); err != nil {
+			p.API.LogWarn("Failed to post scheduled poll", "pollID", pollID, "error", err.Error())
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPluginEndExpiredPolls(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 2000 })
+	defer patch.Unpatch()
+
+	expiredPoll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 1000})
+	expiredPoll.ID = "pollID1"
+	runningPoll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 3000})
+	runningPoll.ID = "pollID2"
+	pollWithoutEnd := testutils.GetPoll()
+	pollWithoutEnd.ID = "pollID3"
+
+	converter := func(userID string) (string, *model.AppError) {
+		return "", &model.AppError{}
+	}
+	expectedPost, err := expiredPoll.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+	require.Nil(t, err)
+	expectedPost.Id = "postID1"
+	endedPoll := expiredPoll.Copy()
+	endedPoll.End()
+	reminderPoll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 3000, Remind: 1500})
+	reminderPoll.ID = "pollID4"
+	remindedPoll := reminderPoll.Copy()
+	remindedPoll.MarkReminded(2000)
+
+	for name, test := range map[string]struct {
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		SetupStore func(*mockstore.Store) *mockstore.Store
+	}{
+		"Ends expired polls only": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", expectedPost).Return(nil, nil)
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "channelID1" && post.RootId == "postID1"
+				})).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventEnded, endedPoll.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return([]string{"pollID1", "pollID2", "pollID3"}, nil)
+				store.PollStore.On("Get", "pollID1").Return(expiredPoll.Copy(), nil)
+				store.PollStore.On("Get", "pollID2").Return(runningPoll.Copy(), nil)
+				store.PollStore.On("Get", "pollID3").Return(pollWithoutEnd.Copy(), nil)
+				store.PollStore.On("Delete", expiredPoll).Return(nil)
+				store.ArchiveStore.On("Save", mock.AnythingOfType("*poll.ArchivedPoll")).Return(nil)
+				return store
+			},
+		},
+		"Sends due reminders": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+				api.On("GetUsersInChannel", "channelID1", model.CHANNEL_SORT_BY_USERNAME, 0, reminderUsersPerPage).Return([]*model.User{{Id: "userID1"}}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "dmChannelID"}, nil)
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "dmChannelID"
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return([]string{"pollID4"}, nil)
+				store.PollStore.On("Get", "pollID4").Return(reminderPoll.Copy(), nil)
+				store.PollStore.On("Update", reminderPoll, remindedPoll).Return(nil)
+				return store
+			},
+		},
+		"Reminder already sent": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return([]string{"pollID4"}, nil)
+				store.PollStore.On("Get", "pollID4").Return(remindedPoll.Copy(), nil)
+				return store
+			},
+		},
+		"ListIDs fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return(nil, errors.New(""))
+				return store
+			},
+		},
+		"Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+				store.PollStore.On("Get", "pollID1").Return(nil, errors.New(""))
+				return store
+			},
+		},
+		"Poll without PostID": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				p := expiredPoll.Copy()
+				p.PostID = ""
+				store.PollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+				store.PollStore.On("Get", "pollID1").Return(p, nil)
+				return store
+			},
+		},
+		"UpdatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("UpdatePost", expectedPost).Return(nil, &model.AppError{})
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+				store.PollStore.On("Get", "pollID1").Return(expiredPoll.Copy(), nil)
+				return store
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			p.endExpiredPolls()
+		})
+	}
+}
+
+func TestPluginEndExpiredPollsWithGracePeriod(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 20 * 60 * 1000 })
+	defer patch.Unpatch()
+
+	recentlyEndedPoll := testutils.GetPoll()
+	recentlyEndedPoll.ID = "pollID1"
+	recentlyEndedPoll.EndedAt = 15 * 60 * 1000
+	oldEndedPoll := testutils.GetPoll()
+	oldEndedPoll.ID = "pollID2"
+	oldEndedPoll.EndedAt = 5 * 60 * 1000
+	expiredPoll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 1000})
+	expiredPoll.ID = "pollID3"
+	endedExpiredPoll := expiredPoll.Copy()
+	endedExpiredPoll.End()
+
+	api := &plugintest.API{}
+	api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+	api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+	api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+	api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+	api.On("PublishWebSocketEvent", websocketEventEnded, endedExpiredPoll.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+	defer api.AssertExpectations(t)
+
+	store := &mockstore.Store{}
+	store.PollStore.On("ListIDs").Return([]string{"pollID1", "pollID2", "pollID3"}, nil)
+	store.PollStore.On("Get", "pollID1").Return(recentlyEndedPoll.Copy(), nil)
+	store.PollStore.On("Get", "pollID2").Return(oldEndedPoll.Copy(), nil)
+	store.PollStore.On("Get", "pollID3").Return(expiredPoll.Copy(), nil)
+	store.PollStore.On("Delete", oldEndedPoll).Return(nil)
+	store.PollStore.On("Update", expiredPoll, endedExpiredPoll).Return(nil)
+	store.ArchiveStore.On("Save", endedExpiredPoll.Archive("channelID1")).Return(nil)
+	defer store.AssertExpectations(t)
+
+	p := setupTestPlugin(t, api, store)
+	p.configuration.ReopenGracePeriod = "10"
+
+	p.endExpiredPolls()
+}
+
+func TestPluginPostScheduledPolls(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 2000 })
+	defer patch.Unpatch()
+
+	duePoll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: 1000})
+	duePoll.ID = "pollID1"
+	pendingPoll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: 3000})
+	pendingPoll.ID = "pollID2"
+	recurringPoll := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: 1000, Repeat: poll.RepeatWeekly})
+	recurringPoll.ID = "pollID1"
+
+	for name, test := range map[string]struct {
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		SetupStore func(*mockstore.Store) *mockstore.Store
+	}{
+		"Posts due polls only": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "channelID1" && post.RootId == "rootID1" && post.UserId == testutils.GetBotUserID()
+				})).Return(&model.Post{Id: "postID1"}, nil)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ScheduledPollStore.On("ListIDs").Return([]string{"pollID1", "pollID2"}, nil)
+				store.ScheduledPollStore.On("Get", "pollID1").Return(poll.NewScheduledPoll(duePoll.Copy(), "channelID1", "rootID1"), nil)
+				store.ScheduledPollStore.On("Get", "pollID2").Return(poll.NewScheduledPoll(pendingPoll.Copy(), "channelID1", ""), nil)
+				store.ScheduledPollStore.On("Delete", "pollID1").Return(nil)
+				store.PollStore.On("Insert", mock.MatchedBy(func(p *poll.Poll) bool {
+					return p.ID == "pollID1" && p.PostID == "postID1"
+				})).Return(nil)
+				return store
+			},
+		},
+		"Schedules next occurrence of recurring poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(&model.Post{Id: "postID1"}, nil)
+				api.On("LogDebug", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ScheduledPollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+				store.ScheduledPollStore.On("Get", "pollID1").Return(poll.NewScheduledPoll(recurringPoll.Copy(), "channelID1", ""), nil)
+				store.ScheduledPollStore.On("Delete", "pollID1").Return(nil)
+				store.ScheduledPollStore.On("Insert", mock.MatchedBy(func(s *poll.ScheduledPoll) bool {
+					return s.Poll.ID != "pollID1" && s.Poll.Settings.ScheduledAt == 1000+7*24*60*60*1000 && s.ChannelID == "channelID1"
+				})).Return(nil)
+				store.PollStore.On("Insert", mock.MatchedBy(func(p *poll.Poll) bool {
+					return p.ID == "pollID1" && p.PostID == "postID1"
+				})).Return(nil)
+				return store
+			},
+		},
+		"ListIDs fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ScheduledPollStore.On("ListIDs").Return(nil, errors.New(""))
+				return store
+			},
+		},
+		"Delete fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ScheduledPollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+				store.ScheduledPollStore.On("Get", "pollID1").Return(poll.NewScheduledPoll(duePoll.Copy(), "channelID1", ""), nil)
+				store.ScheduledPollStore.On("Delete", "pollID1").Return(errors.New(""))
+				return store
+			},
+		},
+		"CreatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ScheduledPollStore.On("ListIDs").Return([]string{"pollID1"}, nil)
+				store.ScheduledPollStore.On("Get", "pollID1").Return(poll.NewScheduledPoll(duePoll.Copy(), "channelID1", ""), nil)
+				store.ScheduledPollStore.On("Delete", "pollID1").Return(nil)
+				return store
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			p.postScheduledPolls()
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package plugin
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+const (
+	// searchResultsLimit is the maximum number of polls listed by the search command.
+	searchResultsLimit = 20
+
+	searchOpenFilter    = "--open"
+	searchClosedFilter  = "--closed"
+	searchCreatorFilter = "--creator="
+)
+
+var (
+	commandSearchUsage = &i18n.Message{
+		ID:    "command.search.usage",
+		Other: "Use `/{{.Trigger}} search <keywords>` to find polls whose question or answer options contain all keywords. Add `--open` or `--closed` to only find running or ended polls and `--creator=@username` to only find the polls of a user.",
+	}
+	commandSearchEmpty = &i18n.Message{
+		ID:    "command.search.empty",
+		Other: "No polls match your search.",
+	}
+	commandSearchHeader = &i18n.Message{
+		ID:    "command.search.header",
+		Other: "Polls matching your search, most recent first:",
+	}
+	commandSearchEntryRunning = &i18n.Message{
+		ID:    "command.search.entryRunning",
+		Other: "- `{{.ID}}`: [**{{.Question}}**]({{.Link}}) (running)",
+	}
+	commandSearchEntryEnded = &i18n.Message{
+		ID:    "command.search.entryEnded",
+		Other: "- `{{.ID}}`: [**{{.Question}}**]({{.Link}}) (ended)",
+	}
+	commandSearchMore = &i18n.Message{
+		ID:    "command.search.more",
+		Other: "Only the {{.Limit}} most recent of {{.Count}} matching polls are listed. Add keywords to narrow down the search.",
+	}
+)
+
+// searchQuery is a search for polls parsed from the arguments of the search command.
+type searchQuery struct {
+	keywords []string
+	open     bool
+	closed   bool
+	creator  string
+}
+
+// parseSearchQuery parses the arguments of the search command. Arguments that aren't filters are keywords.
+// It returns false if no keywords are given or the filters contradict each other.
+func parseSearchQuery(args []string) (*searchQuery, bool) {
+	query := &searchQuery{}
+	var words []string
+	for _, arg := range args {
+		switch {
+		case arg == searchOpenFilter:
+			query.open = true
+		case arg == searchClosedFilter:
+			query.closed = true
+		case strings.HasPrefix(arg, searchCreatorFilter):
+			query.creator = strings.TrimPrefix(strings.TrimPrefix(arg, searchCreatorFilter), "@")
+			if query.creator == "" {
+				return nil, false
+			}
+		default:
+			words = append(words, arg)
+		}
+	}
+	query.keywords = poll.Keywords(strings.Join(words, " "))
+	if len(query.keywords) == 0 || (query.open && query.closed) {
+		return nil, false
+	}
+	return query, true
+}
+
+// executeSearchCommand returns a message listing the polls that match the search given by args,
+// with links to their posts. Only polls posted in channels the user is a member of are listed.
+func (p *MatterpollPlugin) executeSearchCommand(args []string, userID, trigger string, userLocalizer *i18n.Localizer) string {
+	query, ok := parseSearchQuery(args)
+	if !ok {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandSearchUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		})
+	}
+
+	creatorID := ""
+	if query.creator != "" {
+		user, appErr := p.API.GetUserByUsername(query.creator)
+		if appErr != nil {
+			return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandErrorUserNotFound,
+				TemplateData:   map[string]interface{}{"Username": query.creator},
+			})
+		}
+		creatorID = user.Id
+	}
+
+	ids, err := p.Store.Poll().ListIDsByKeywords(query.keywords)
+	if err != nil {
+		p.API.LogWarn("failed to search polls", "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	var results []*channelPoll
+	for _, id := range ids {
+		pl, err := p.getPoll(id)
+		if err != nil {
+			// The index may contain polls that have been deleted
+			if !errors.Is(err, store.ErrNotFound) {
+				p.API.LogWarn("failed to get poll", "pollID", id, "error", err.Error())
+			}
+			continue
+		}
+		// The index may contain polls whose question or answer options have been edited since
+		if pl.PostID == "" || !pl.MatchesKeywords(query.keywords) ||
+			(query.open && pl.HasEnded()) || (query.closed && !pl.HasEnded()) ||
+			(creatorID != "" && pl.Creator != creatorID) {
+			continue
+		}
+		if channelID := p.memberChannelID(pl, userID); channelID != "" {
+			results = append(results, &channelPoll{poll: pl, channelID: channelID})
+		}
+	}
+	if len(results) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandSearchEmpty)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].poll.CreatedAt > results[j].poll.CreatedAt })
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	lines := []string{p.LocalizeDefaultMessage(userLocalizer, commandSearchHeader)}
+	for i, result := range results {
+		if i == searchResultsLimit {
+			lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandSearchMore,
+				TemplateData:   map[string]interface{}{"Limit": searchResultsLimit, "Count": len(results)},
+			}))
+			break
+		}
+
+		message := commandSearchEntryRunning
+		if result.poll.HasEnded() {
+			message = commandSearchEntryEnded
+		}
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: message,
+			TemplateData: map[string]interface{}{
+				"ID":       result.poll.ID,
+				"Question": result.poll.QuestionLinkText(),
+				"Link":     result.link(siteURL),
+			},
+		}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// memberChannelID returns the ID of a channel a poll is posted in, that a user is a member of.
+// It returns an empty string if the user isn't a member of any of them.
+func (p *MatterpollPlugin) memberChannelID(pl *poll.Poll, userID string) string {
+	for _, channelID := range p.pollPostChannelIDs(pl) {
+		if _, appErr := p.API.GetChannelMember(channelID, userID); appErr == nil {
+			return channelID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,211 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func getSearchPoll(id, channelID string, createdAt int64) *poll.Poll {
+	p := testutils.GetPoll()
+	p.ID = id
+	p.PostID = "post" + id
+	p.ChannelID = channelID
+	p.CreatedAt = createdAt
+	p.Question = "Lunch today?"
+	return p
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	for name, test := range map[string]struct {
+		Args          []string
+		ExpectedQuery *searchQuery
+	}{
+		"keywords": {
+			Args:          []string{"Lunch", "pizza?"},
+			ExpectedQuery: &searchQuery{keywords: []string{"lunch", "pizza"}},
+		},
+		"filters": {
+			Args:          []string{"--open", "lunch", "--creator=@user2"},
+			ExpectedQuery: &searchQuery{keywords: []string{"lunch"}, open: true, creator: "user2"},
+		},
+		"no arguments":       {Args: []string{}},
+		"only filters":       {Args: []string{"--closed"}},
+		"only short words":   {Args: []string{"a", "1"}},
+		"open and closed":    {Args: []string{"lunch", "--open", "--closed"}},
+		"creator is missing": {Args: []string{"lunch", "--creator="}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			query, ok := parseSearchQuery(test.Args)
+			assert.Equal(t, test.ExpectedQuery != nil, ok)
+			assert.Equal(t, test.ExpectedQuery, query)
+		})
+	}
+}
+
+func TestPluginExecuteSearchCommand(t *testing.T) {
+	t.Run("matching polls", func(t *testing.T) {
+		running := getSearchPoll("pollID1", "channelID1", 1000)
+		ended := getSearchPoll("pollID2", "channelID1", 2000)
+		ended.EndedAt = 3000
+		otherChannel := getSearchPoll("pollID3", "channelID2", 3000)
+		broadcast := getSearchPoll("pollID5", "channelID2", 500)
+		broadcast.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID1", PostID: "postID5b"}}
+		edited := getSearchPoll("pollID6", "channelID1", 4000)
+		edited.Question = "Dinner today?"
+
+		api := &plugintest.API{}
+		api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+		api.On("GetChannelMember", "channelID2", "userID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByKeywords", []string{"lunch"}).Return([]string{"pollID1", "pollID2", "pollID3", "pollID4", "pollID5", "pollID6"}, nil)
+		s.PollStore.On("Get", "pollID1").Return(running, nil)
+		s.PollStore.On("Get", "pollID2").Return(ended, nil)
+		s.PollStore.On("Get", "pollID3").Return(otherChannel, nil)
+		s.PollStore.On("Get", "pollID4").Return(nil, store.ErrNotFound)
+		s.PollStore.On("Get", "pollID5").Return(broadcast, nil)
+		s.PollStore.On("Get", "pollID6").Return(edited, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "Polls matching your search, most recent first:\n"+
+			"- `pollID2`: [**Lunch today?**](https://example.org/_redirect/pl/postpollID2) (ended)\n"+
+			"- `pollID1`: [**Lunch today?**](https://example.org/_redirect/pl/postpollID1) (running)\n"+
+			"- `pollID5`: [**Lunch today?**](https://example.org/_redirect/pl/postID5b) (running)",
+			p.executeSearchCommand([]string{"lunch"}, "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("filters", func(t *testing.T) {
+		mine := getSearchPoll("pollID1", "channelID1", 1000)
+		theirs := getSearchPoll("pollID2", "channelID1", 2000)
+		theirs.Creator = "userID2"
+		theirsEnded := getSearchPoll("pollID3", "channelID1", 3000)
+		theirsEnded.Creator = "userID2"
+		theirsEnded.EndedAt = 4000
+
+		api := &plugintest.API{}
+		api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2"}, nil)
+		api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByKeywords", []string{"lunch"}).Return([]string{"pollID1", "pollID2", "pollID3"}, nil)
+		s.PollStore.On("Get", "pollID1").Return(mine, nil)
+		s.PollStore.On("Get", "pollID2").Return(theirs, nil)
+		s.PollStore.On("Get", "pollID3").Return(theirsEnded, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "Polls matching your search, most recent first:\n"+
+			"- `pollID2`: [**Lunch today?**](https://example.org/_redirect/pl/postpollID2) (running)",
+			p.executeSearchCommand([]string{"lunch", "--open", "--creator=@user2"}, "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("poll without channel", func(t *testing.T) {
+		legacy := getSearchPoll("pollID1", "", 1000)
+
+		api := &plugintest.API{}
+		api.On("GetPost", "postpollID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+		api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByKeywords", []string{"lunch"}).Return([]string{"pollID1"}, nil)
+		s.PollStore.On("Get", "pollID1").Return(legacy, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "Polls matching your search, most recent first:\n"+
+			"- `pollID1`: [**Lunch today?**](https://example.org/_redirect/pl/postpollID1) (running)",
+			p.executeSearchCommand([]string{"lunch"}, "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("English translation", func(t *testing.T) {
+		running := getSearchPoll("pollID1", "channelID1", 1000)
+		ended := getSearchPoll("pollID2", "channelID1", 2000)
+		ended.EndedAt = 3000
+
+		api := &plugintest.API{}
+		api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByKeywords", []string{"lunch"}).Return([]string{"pollID1", "pollID2"}, nil)
+		s.PollStore.On("Get", "pollID1").Return(running, nil)
+		s.PollStore.On("Get", "pollID2").Return(ended, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+		p.bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
+		_, err := p.bundle.LoadMessageFile("../../assets/i18n/active.en.json")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Polls matching your search, most recent first:\n"+
+			"- `pollID2`: [**Lunch today?**](https://example.org/_redirect/pl/postpollID2) (ended)\n"+
+			"- `pollID1`: [**Lunch today?**](https://example.org/_redirect/pl/postpollID1) (running)",
+			p.executeSearchCommand([]string{"lunch"}, "userID1", "poll", i18n.NewLocalizer(p.bundle, "en")))
+	})
+	t.Run("too many matching polls", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		var ids []string
+		for i := 0; i < searchResultsLimit+1; i++ {
+			id := fmt.Sprintf("pollID%d", i)
+			ids = append(ids, id)
+			s.PollStore.On("Get", id).Return(getSearchPoll(id, "channelID1", int64(i)), nil)
+		}
+		s.PollStore.On("ListIDsByKeywords", []string{"lunch"}).Return(ids, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		lines := strings.Split(p.executeSearchCommand([]string{"lunch"}, "userID1", "poll", testutils.GetLocalizer()), "\n")
+		require.Len(t, lines, searchResultsLimit+2)
+		assert.Equal(t, "- `pollID20`: [**Lunch today?**](https://example.org/_redirect/pl/postpollID20) (running)", lines[1])
+		assert.Equal(t, "Only the 20 most recent of 21 matching polls are listed. Add keywords to narrow down the search.", lines[searchResultsLimit+1])
+	})
+	t.Run("no matching polls", func(t *testing.T) {
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByKeywords", []string{"lunch"}).Return([]string{}, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, s)
+
+		assert.Equal(t, "No polls match your search.",
+			p.executeSearchCommand([]string{"lunch"}, "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("creator not found", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUserByUsername", "user2").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.Equal(t, "The user @user2 could not be found.",
+			p.executeSearchCommand([]string{"lunch", "--creator=user2"}, "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("ListIDsByKeywords fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("ListIDsByKeywords", []string{"lunch"}).Return(nil, errors.New(""))
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, commandErrorGeneric.Other,
+			p.executeSearchCommand([]string{"lunch"}, "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		assert.Equal(t, "Use `/poll search <keywords>` to find polls whose question or answer options contain all keywords. Add `--open` or `--closed` to only find running or ended polls and `--creator=@username` to only find the polls of a user.",
+			p.executeSearchCommand([]string{}, "userID1", "poll", testutils.GetLocalizer()))
+	})
+}
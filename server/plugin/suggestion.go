@@ -0,0 +1,202 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+var (
+	suggestionMessage = &i18n.Message{
+		ID:    "suggestion.message",
+		Other: "@{{.User}} suggested the option **{{.Answer}}** for your poll **{{.Question}}**: {{.Link}}",
+	}
+	suggestionButtonApprove = &i18n.Message{
+		ID:    "suggestion.button.approve",
+		Other: "Approve",
+	}
+	suggestionButtonReject = &i18n.Message{
+		ID:    "suggestion.button.reject",
+		Other: "Reject",
+	}
+	suggestionApproved = &i18n.Message{
+		ID:    "suggestion.approved",
+		Other: "You approved the option **{{.Answer}}** for your poll **{{.Question}}**.",
+	}
+	suggestionRejected = &i18n.Message{
+		ID:    "suggestion.rejected",
+		Other: "You rejected the option **{{.Answer}}** for your poll **{{.Question}}**.",
+	}
+	suggestionApprovedNotice = &i18n.Message{
+		ID:    "suggestion.approvedNotice",
+		Other: "The option **{{.Answer}}** you suggested for the poll **{{.Question}}** has been added: {{.Link}}",
+	}
+	suggestionRejectedNotice = &i18n.Message{
+		ID:    "suggestion.rejectedNotice",
+		Other: "The option **{{.Answer}}** you suggested for the poll **{{.Question}}** has been rejected.",
+	}
+
+	responseAddOptionSuggested = &i18n.Message{
+		ID:    "response.addOption.suggested",
+		Other: "Your option has been suggested. It will be added once the creator of the poll approves it.",
+	}
+	responsePendingOptionInvalidPermission = &i18n.Message{
+		ID:    "response.pendingOption.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to approve or reject options.",
+	}
+	responsePendingOptionEnded = &i18n.Message{
+		ID:    "response.pendingOption.ended",
+		Other: "The poll has already ended.",
+	}
+	responsePendingOptionNotFound = &i18n.Message{
+		ID:    "response.pendingOption.notFound",
+		Other: "This option has already been approved or rejected.",
+	}
+)
+
+// suggestAnswerOption stores an answer option a user suggested as pending and asks the creator of the poll
+// to approve it in a direct message from the bot. Suggestions that can't be added are shown as error of the dialog.
+func (p *MatterpollPlugin) suggestAnswerOption(pollID, userID, answer string) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	var option *poll.PendingOption
+	var errMsg *poll.ErrorMessage
+	poll, err := p.updatePoll(pollID, func(pl *poll.Poll) (bool, error) {
+		option, errMsg = pl.SuggestAnswerOption(userID, answer)
+		if errMsg != nil {
+			return false, nil
+		}
+		// Suggestions count towards the limit of added options even if they get rejected later
+		p.recordAddedOption(pl, userID)
+		return true, nil
+	})
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	}
+	if errMsg != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				addOptionKey: p.LocalizeErrorMessage(p.getUserLocalizer(userID), errMsg),
+			},
+		}
+		return nil, response, nil
+	}
+
+	if err := p.sendSuggestion(poll, option); err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to send suggestion")
+	}
+	return responseAddOptionSuggested, nil, nil
+}
+
+// sendSuggestion asks the creator of a poll to approve or reject a suggested answer option in a direct message from the bot.
+func (p *MatterpollPlugin) sendSuggestion(poll *poll.Poll, option *poll.PendingOption) error {
+	channel, appErr := p.API.GetDirectChannel(poll.Creator, p.botUserID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get direct channel")
+	}
+	user, appErr := p.API.GetUser(option.SuggestedBy)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get user")
+	}
+
+	post := p.suggestionPost(poll, option, user.Username, p.getUserLocalizer(poll.Creator))
+	post.UserId = p.botUserID
+	post.ChannelId = channel.Id
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to create post")
+	}
+	return nil
+}
+
+// suggestionPost returns the direct message that asks the creator of a poll to approve or reject a suggested answer option.
+func (p *MatterpollPlugin) suggestionPost(poll *poll.Poll, option *poll.PendingOption, username string, localizer *i18n.Localizer) *model.Post {
+	url := fmt.Sprintf("/plugins/%s/api/v1/polls/%s/option/pending/%s", manifest.Id, poll.ID, option.ID)
+	attachment := &model.SlackAttachment{
+		Text: p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+			DefaultMessage: suggestionMessage,
+			TemplateData: map[string]interface{}{
+				"User":     username,
+				"Answer":   option.Answer,
+				"Question": poll.Question,
+				"Link":     fmt.Sprintf("%s/_redirect/pl/%s", *p.ServerConfig.ServiceSettings.SiteURL, poll.PostID),
+			},
+		}),
+		Actions: []*model.PostAction{{
+			Id:   "approveOption",
+			Name: p.LocalizeDefaultMessage(localizer, suggestionButtonApprove),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: url + "/approve",
+			},
+		}, {
+			Id:   "rejectOption",
+			Name: p.LocalizeDefaultMessage(localizer, suggestionButtonReject),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: url + "/reject",
+			},
+		}},
+	}
+
+	post := &model.Post{}
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{attachment})
+	return post
+}
+
+// suggestionDecisionPost returns the direct message that replaces the buttons to approve or reject a suggested answer option
+// once the creator decided about it.
+func (p *MatterpollPlugin) suggestionDecisionPost(poll *poll.Poll, option *poll.PendingOption, localizer *i18n.Localizer, decision *i18n.Message) *model.Post {
+	return &model.Post{
+		Message: p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+			DefaultMessage: decision,
+			TemplateData: map[string]interface{}{
+				"Answer":   option.Answer,
+				"Question": poll.Question,
+			},
+		}),
+	}
+}
+
+// notifySuggester tells the user who suggested an answer option about the decision of the creator in a direct message from the bot.
+// Failures are only logged, because the decision has already been saved.
+func (p *MatterpollPlugin) notifySuggester(poll *poll.Poll, option *poll.PendingOption, notice *i18n.Message) {
+	channel, appErr := p.API.GetDirectChannel(option.SuggestedBy, p.botUserID)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get direct channel", "userID", option.SuggestedBy, "error", appErr.Error())
+		return
+	}
+
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message: p.LocalizeWithConfig(p.getUserLocalizer(option.SuggestedBy), &i18n.LocalizeConfig{
+			DefaultMessage: notice,
+			TemplateData: map[string]interface{}{
+				"Answer":   option.Answer,
+				"Question": poll.Question,
+				"Link":     fmt.Sprintf("%s/_redirect/pl/%s", *p.ServerConfig.ServiceSettings.SiteURL, poll.PostID),
+			},
+		}),
+	}
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		p.API.LogWarn("Failed to notify user about suggestion", "pollID", poll.ID, "userID", option.SuggestedBy, "error", appErr.Error())
+	}
+}
+
+// checkPendingOptionDecision returns the response to a user who isn't allowed to approve or reject
+// the pending answer options of a poll right now. It returns nil if the user may decide about them.
+func (p *MatterpollPlugin) checkPendingOptionDecision(pl *poll.Poll, userID string) (*i18n.LocalizeConfig, error) {
+	canManagePoll, appErr := p.CanManagePoll(pl, userID)
+	if appErr != nil {
+		return nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !canManagePoll {
+		return &i18n.LocalizeConfig{DefaultMessage: responsePendingOptionInvalidPermission}, nil
+	}
+	if pl.HasEnded() {
+		return &i18n.LocalizeConfig{DefaultMessage: responsePendingOptionEnded}, nil
+	}
+	return nil, nil
+}
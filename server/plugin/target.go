@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+var (
+	commandErrorDMTooManyUsers = &i18n.Message{
+		ID:    "command.error.dmTooManyUsers",
+		Other: "A poll can be sent to at most {{.Max}} other users.",
+	}
+	commandErrorDMNotAllowed = &i18n.Message{
+		ID:    "command.error.dmNotAllowed",
+		Other: "You don't have permission to send direct or group messages.",
+	}
+
+	responsePollPostedIn = &i18n.Message{
+		ID:    "response.pollPosted.in",
+		Other: "Your poll has been posted in ~{{.Channel}}.",
+	}
+	responsePollPostedDM = &i18n.Message{
+		ID:    "response.pollPosted.dm",
+		Other: "Your poll has been sent to {{.Users}}.",
+	}
+)
+
+// resolveTarget returns the channel and the thread a new poll, that was created in channelID and rootID, gets posted in.
+// It's another channel of the team or a direct or group message, if the in or dm setting is used.
+// An error message is returned if the channel or one of the users doesn't exist or the creator isn't allowed to post there.
+func (p *MatterpollPlugin) resolveTarget(newPoll *poll.Poll, channelID, rootID string) (string, string, *poll.ErrorMessage) {
+	if name := newPoll.Settings.In; name != "" {
+		channel, appErr := p.API.GetChannel(channelID)
+		if appErr != nil {
+			p.API.LogWarn("failed to get channel", "channelID", channelID, "error", appErr.Error())
+			return "", "", poll.NewInternalErrorMessage(commandErrorGeneric, appErr)
+		}
+		c, appErr := p.API.GetChannelByName(channel.TeamId, name, false)
+		// Channels the creator can't post in are reported as missing to not reveal private channels
+		if appErr != nil || !p.API.HasPermissionToChannel(newPoll.Creator, c.Id, model.PERMISSION_CREATE_POST) {
+			return "", "", &poll.ErrorMessage{
+				Message: commandErrorChannelNotFound,
+				Data:    map[string]interface{}{"Name": name},
+			}
+		}
+		if c.Id == channelID {
+			return channelID, rootID, nil
+		}
+		return c.Id, "", nil
+	}
+
+	usernames := newPoll.Settings.DMUsernames()
+	if len(usernames) == 0 {
+		return channelID, rootID, nil
+	}
+
+	userIDs := []string{newPoll.Creator}
+	seen := map[string]bool{newPoll.Creator: true}
+	for _, username := range usernames {
+		user, appErr := p.API.GetUserByUsername(username)
+		if appErr != nil {
+			return "", "", &poll.ErrorMessage{
+				Message: commandErrorUserNotFound,
+				Data:    map[string]interface{}{"Username": username},
+			}
+		}
+		if !seen[user.Id] {
+			seen[user.Id] = true
+			userIDs = append(userIDs, user.Id)
+		}
+	}
+	if len(userIDs) > model.CHANNEL_GROUP_MAX_USERS {
+		return "", "", &poll.ErrorMessage{
+			Message: commandErrorDMTooManyUsers,
+			Data:    map[string]interface{}{"Max": model.CHANNEL_GROUP_MAX_USERS - 1},
+		}
+	}
+
+	// A poll sent to the creator alone is posted in their direct message with themselves
+	var channel *model.Channel
+	var appErr *model.AppError
+	if len(userIDs) <= 2 {
+		if !p.API.HasPermissionTo(newPoll.Creator, model.PERMISSION_CREATE_DIRECT_CHANNEL) {
+			return "", "", &poll.ErrorMessage{Message: commandErrorDMNotAllowed}
+		}
+		channel, appErr = p.API.GetDirectChannel(newPoll.Creator, userIDs[len(userIDs)-1])
+	} else {
+		if !p.API.HasPermissionTo(newPoll.Creator, model.PERMISSION_CREATE_GROUP_CHANNEL) {
+			return "", "", &poll.ErrorMessage{Message: commandErrorDMNotAllowed}
+		}
+		channel, appErr = p.API.GetGroupChannel(userIDs)
+	}
+	if appErr != nil {
+		p.API.LogWarn("failed to get direct channel", "error", appErr.Error())
+		return "", "", poll.NewInternalErrorMessage(commandErrorGeneric, appErr)
+	}
+	return channel.Id, "", nil
+}
+
+// pollPostedMessage returns the response message for the creator of a poll, that has been posted in another channel
+// or sent as direct or group message. It's empty if the poll has been posted where it was created.
+func (p *MatterpollPlugin) pollPostedMessage(newPoll *poll.Poll, userLocalizer *i18n.Localizer) string {
+	if newPoll.Settings.In != "" {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: responsePollPostedIn,
+			TemplateData:   map[string]interface{}{"Channel": newPoll.Settings.In},
+		})
+	}
+	if usernames := newPoll.Settings.DMUsernames(); len(usernames) > 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: responsePollPostedDM,
+			TemplateData:   map[string]interface{}{"Users": "@" + strings.Join(usernames, ", @")},
+		})
+	}
+	return ""
+}
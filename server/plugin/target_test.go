@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPluginResolveTarget(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupAPI          func(*plugintest.API)
+		Settings          poll.Settings
+		ExpectedChannelID string
+		ExpectedRootID    string
+		ExpectedError     *poll.ErrorMessage
+	}{
+		"no target": {
+			SetupAPI:          func(api *plugintest.API) {},
+			Settings:          poll.Settings{MaxVotes: 1},
+			ExpectedChannelID: "channelID1",
+			ExpectedRootID:    "rootID1",
+		},
+		"in another channel": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID2", model.PERMISSION_CREATE_POST).Return(true)
+			},
+			Settings:          poll.Settings{MaxVotes: 1, In: "dev"},
+			ExpectedChannelID: "channelID2",
+		},
+		"in the same channel": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_CREATE_POST).Return(true)
+			},
+			Settings:          poll.Settings{MaxVotes: 1, In: "dev"},
+			ExpectedChannelID: "channelID1",
+			ExpectedRootID:    "rootID1",
+		},
+		"in a channel the creator can't post in": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("HasPermissionToChannel", "userID1", "channelID2", model.PERMISSION_CREATE_POST).Return(false)
+			},
+			Settings: poll.Settings{MaxVotes: 1, In: "dev"},
+			ExpectedError: &poll.ErrorMessage{
+				Message: commandErrorChannelNotFound,
+				Data:    map[string]interface{}{"Name": "dev"},
+			},
+		},
+		"in an unknown channel": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(nil, &model.AppError{})
+			},
+			Settings: poll.Settings{MaxVotes: 1, In: "dev"},
+			ExpectedError: &poll.ErrorMessage{
+				Message: commandErrorChannelNotFound,
+				Data:    map[string]interface{}{"Name": "dev"},
+			},
+		},
+		"dm to one user": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2"}, nil)
+				api.On("HasPermissionTo", "userID1", model.PERMISSION_CREATE_DIRECT_CHANNEL).Return(true)
+				api.On("GetDirectChannel", "userID1", "userID2").Return(&model.Channel{Id: "directChannelID"}, nil)
+			},
+			Settings:          poll.Settings{MaxVotes: 1, DM: "user2"},
+			ExpectedChannelID: "directChannelID",
+		},
+		"dm to the creator": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetUserByUsername", "user1").Return(&model.User{Id: "userID1"}, nil)
+				api.On("HasPermissionTo", "userID1", model.PERMISSION_CREATE_DIRECT_CHANNEL).Return(true)
+				api.On("GetDirectChannel", "userID1", "userID1").Return(&model.Channel{Id: "directChannelID"}, nil)
+			},
+			Settings:          poll.Settings{MaxVotes: 1, DM: "user1"},
+			ExpectedChannelID: "directChannelID",
+		},
+		"dm to several users": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2"}, nil)
+				api.On("GetUserByUsername", "user3").Return(&model.User{Id: "userID3"}, nil)
+				api.On("HasPermissionTo", "userID1", model.PERMISSION_CREATE_GROUP_CHANNEL).Return(true)
+				api.On("GetGroupChannel", []string{"userID1", "userID2", "userID3"}).Return(&model.Channel{Id: "groupChannelID"}, nil)
+			},
+			Settings:          poll.Settings{MaxVotes: 1, DM: "user2,user3,user2"},
+			ExpectedChannelID: "groupChannelID",
+		},
+		"dm not allowed": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2"}, nil)
+				api.On("HasPermissionTo", "userID1", model.PERMISSION_CREATE_DIRECT_CHANNEL).Return(false)
+			},
+			Settings:      poll.Settings{MaxVotes: 1, DM: "user2"},
+			ExpectedError: &poll.ErrorMessage{Message: commandErrorDMNotAllowed},
+		},
+		"dm to an unknown user": {
+			SetupAPI: func(api *plugintest.API) {
+				api.On("GetUserByUsername", "user2").Return(nil, &model.AppError{})
+			},
+			Settings: poll.Settings{MaxVotes: 1, DM: "user2"},
+			ExpectedError: &poll.ErrorMessage{
+				Message: commandErrorUserNotFound,
+				Data:    map[string]interface{}{"Username": "user2"},
+			},
+		},
+		"dm to too many users": {
+			SetupAPI: func(api *plugintest.API) {
+				for _, username := range []string{"u2", "u3", "u4", "u5", "u6", "u7", "u8", "u9"} {
+					api.On("GetUserByUsername", username).Return(&model.User{Id: username}, nil)
+				}
+			},
+			Settings: poll.Settings{MaxVotes: 1, DM: "u2,u3,u4,u5,u6,u7,u8,u9"},
+			ExpectedError: &poll.ErrorMessage{
+				Message: commandErrorDMTooManyUsers,
+				Data:    map[string]interface{}{"Max": 7},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			test.SetupAPI(api)
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+
+			pl := testutils.GetPollWithSettings(test.Settings)
+			channelID, rootID, errMsg := p.resolveTarget(pl, "channelID1", "rootID1")
+			if test.ExpectedError != nil {
+				require.NotNil(t, errMsg)
+				assert.Equal(t, test.ExpectedError, errMsg)
+				return
+			}
+			require.Nil(t, errMsg)
+			assert.Equal(t, test.ExpectedChannelID, channelID)
+			assert.Equal(t, test.ExpectedRootID, rootID)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils"
+)
+
+const (
+	// templateChannelSetting is the setting that saves a template for the channel instead of the user.
+	templateChannelSetting = "channel"
+)
+
+var (
+	commandTemplateUsage = &i18n.Message{
+		ID:    "command.template.usage",
+		Other: "Use `/{{.Trigger}} template save <name> \"Question\" \"Answer 1\" \"Answer 2\"` to save a template, `/{{.Trigger}} template list` to list the templates and `/{{.Trigger}} template use <name>` to create a poll from one. Add `--channel` when saving a template to share it with everyone in the channel.",
+	}
+	commandTemplateSaveSuccess = &i18n.Message{
+		ID:    "command.template.save.success",
+		Other: "The template `{{.Name}}` has been saved. Use `/{{.Trigger}} template use {{.Name}}` to create a poll from it.",
+	}
+	commandTemplateSaveInvalidPermission = &i18n.Message{
+		ID:    "command.template.save.invalidPermission",
+		Other: "Only the creator of a template and System Admins are allowed to replace it.",
+	}
+	commandTemplateListEmpty = &i18n.Message{
+		ID:    "command.template.list.empty",
+		Other: "There are no templates yet.",
+	}
+	commandTemplateListUserHeader = &i18n.Message{
+		ID:    "command.template.list.userHeader",
+		Other: "Your templates:",
+	}
+	commandTemplateListChannelHeader = &i18n.Message{
+		ID:    "command.template.list.channelHeader",
+		Other: "Templates of this channel:",
+	}
+	commandTemplateListEntry = &i18n.Message{
+		ID:    "command.template.list.entry",
+		Other: "- `{{.Name}}`: **{{.Question}}**",
+	}
+	commandErrorTemplateNotFound = &i18n.Message{
+		ID:    "command.error.templateNotFound",
+		Other: "The template `{{.Name}}` could not be found.",
+	}
+)
+
+// executeTemplateCommand saves, lists or uses the poll templates of a user and the channel and returns the response message.
+func (p *MatterpollPlugin) executeTemplateCommand(args *model.CommandArgs, subArgs []string, userLocalizer *i18n.Localizer) string {
+	trigger := p.getConfiguration().Trigger
+	switch {
+	case len(subArgs) > 2 && subArgs[0] == "save":
+		return p.saveTemplate(args, userLocalizer)
+	case len(subArgs) == 1 && subArgs[0] == "list":
+		return p.listTemplates(args.UserId, args.ChannelId, userLocalizer)
+	case len(subArgs) == 2 && subArgs[0] == "use":
+		return p.useTemplate(subArgs[1], args, userLocalizer)
+	default:
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandTemplateUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		})
+	}
+}
+
+// saveTemplate saves the poll given by "/<trigger> template save <name> <poll>" as template of the user,
+// or of the channel if the channel setting is given, and returns the response message.
+func (p *MatterpollPlugin) saveTemplate(args *model.CommandArgs, userLocalizer *i18n.Localizer) string {
+	trigger := p.getConfiguration().Trigger
+	in := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(args.Command), "/"+trigger))
+	_, in = cutField(in)
+	_, in = cutField(in)
+	name, in := cutField(in)
+
+	q, o, s := utils.ParseInput(in, trigger)
+	if len(o) == 1 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorinvalidNumberOfOptions)
+	}
+
+	// Templates store the canonical keywords, since they may be used by users with another language
+	ownerID := args.UserId
+	var settings []string
+	for _, setting := range poll.CanonicalizeSettings(s, userLocalizer) {
+		if setting == templateChannelSetting {
+			ownerID = args.ChannelId
+			continue
+		}
+		settings = append(settings, setting)
+	}
+
+	// The options of a poll with a scale are generated from the scale. Invalid settings are reported by NewTemplate.
+	if parsed, _ := poll.NewSettingsFromStrings(settings); len(o) == 0 && !parsed.IsScale() {
+		publicLocalizer := p.getLocaleLocalizer(parsed.Locale)
+		o = []string{
+			p.LocalizeDefaultMessage(publicLocalizer, commandDefaultYes),
+			p.LocalizeDefaultMessage(publicLocalizer, commandDefaultNo),
+		}
+	}
+
+	configuration := p.getConfiguration()
+	template, errMsg := poll.NewTemplate(name, args.UserId, q, o, settings, configuration.pollDefaults(), configuration.pollLimits())
+	if errMsg != nil {
+		return p.localizeInvalidInput(userLocalizer, errMsg)
+	}
+
+	existing, err := p.findTemplate(ownerID, name)
+	if err != nil {
+		p.API.LogWarn("failed to list templates", "ownerID", ownerID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if existing != nil {
+		canReplace, appErr := p.isCreatorOrSystemAdmin(existing.Creator, args.UserId)
+		if appErr != nil {
+			p.API.LogWarn("failed to check permission", "userID", args.UserId, "error", appErr.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+		}
+		if !canReplace {
+			return p.LocalizeDefaultMessage(userLocalizer, commandTemplateSaveInvalidPermission)
+		}
+	}
+
+	if err := p.Store.Template().Save(ownerID, template); err != nil {
+		p.API.LogWarn("failed to save template", "ownerID", ownerID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandTemplateSaveSuccess,
+		TemplateData:   map[string]interface{}{"Name": name, "Trigger": trigger},
+	})
+}
+
+// listTemplates returns a message listing the templates of a user and of the channel.
+func (p *MatterpollPlugin) listTemplates(userID, channelID string, userLocalizer *i18n.Localizer) string {
+	var lines []string
+	for _, owner := range []struct {
+		ID     string
+		Header *i18n.Message
+	}{
+		{ID: userID, Header: commandTemplateListUserHeader},
+		{ID: channelID, Header: commandTemplateListChannelHeader},
+	} {
+		templates, err := p.Store.Template().List(owner.ID)
+		if err != nil {
+			p.API.LogWarn("failed to list templates", "ownerID", owner.ID, "error", err.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+		}
+		if len(templates) == 0 {
+			continue
+		}
+
+		lines = append(lines, p.LocalizeDefaultMessage(userLocalizer, owner.Header))
+		for _, template := range templates {
+			lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandTemplateListEntry,
+				TemplateData:   map[string]interface{}{"Name": template.Name, "Question": template.Question},
+			}))
+		}
+	}
+	if len(lines) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandTemplateListEmpty)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// useTemplate creates a poll from a template of the user or, if the user has none with the given name,
+// of the channel and returns the response message.
+func (p *MatterpollPlugin) useTemplate(name string, args *model.CommandArgs, userLocalizer *i18n.Localizer) string {
+	var template *poll.Template
+	for _, ownerID := range []string{args.UserId, args.ChannelId} {
+		t, err := p.findTemplate(ownerID, name)
+		if err != nil {
+			p.API.LogWarn("failed to list templates", "ownerID", ownerID, "error", err.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+		}
+		if t != nil {
+			template = t
+			break
+		}
+	}
+	if template == nil {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorTemplateNotFound,
+			TemplateData:   map[string]interface{}{"Name": name},
+		})
+	}
+
+	configuration := p.getConfiguration()
+	newPoll, errMsg := template.NewPoll(args.UserId, configuration.pollDefaults(), configuration.pollLimits())
+	channelID, rootID := args.ChannelId, args.RootId
+	if errMsg == nil {
+		channelID, rootID, errMsg = p.resolveTarget(newPoll, args.ChannelId, args.RootId)
+	}
+	if errMsg == nil {
+		errMsg = p.resolveSettings(newPoll, channelID)
+	}
+	if errMsg != nil {
+		return p.localizeInvalidInput(userLocalizer, errMsg)
+	}
+
+	return p.publishPoll(newPoll, channelID, rootID, userLocalizer)
+}
+
+// findTemplate returns the template of a user or channel with the given name. It returns nil if there is none.
+func (p *MatterpollPlugin) findTemplate(ownerID, name string) (*poll.Template, error) {
+	templates, err := p.Store.Template().List(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		if template.Name == name {
+			return template, nil
+		}
+	}
+	return nil, nil
+}
+
+// localizeInvalidInput returns the message for an invalid input given to a command.
+func (p *MatterpollPlugin) localizeInvalidInput(userLocalizer *i18n.Localizer, errMsg *poll.ErrorMessage) string {
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandErrorInvalidInput,
+		TemplateData: map[string]interface{}{
+			"Error": p.LocalizeErrorMessage(userLocalizer, errMsg),
+		},
+	})
+}
+
+// cutField splits s after its first whitespace separated field. Leading whitespace of the rest is removed.
+func cutField(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t\n"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i:])
+	}
+	return s, ""
+}
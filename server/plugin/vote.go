@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+var (
+	commandVoteUsage = &i18n.Message{
+		ID:    "command.vote.usage",
+		Other: "Use `/{{.Trigger}} vote <Poll ID> <option numbers>` to vote, e.g. `/{{.Trigger}} vote <Poll ID> 2` for the second answer option. Several numbers like `1,3` vote for several answer options, if the poll allows it.",
+	}
+	commandVoteInvalidOption = &i18n.Message{
+		ID:    "command.vote.invalidOption",
+		Other: "The poll **{{.Question}}** has no answer option {{.Number}}.",
+	}
+	commandVoteSingleOption = &i18n.Message{
+		ID:    "command.vote.singleOption",
+		Other: "You can only vote for one answer option in the poll **{{.Question}}**.",
+	}
+	commandVoteAlreadyVoted = &i18n.Message{
+		ID:    "command.vote.alreadyVoted",
+		Other: "You've already voted for these answer options.",
+	}
+)
+
+// parseOptionNumbers parses the numbers of answer options separated by spaces or commas, e.g. "1,3" or "1 3".
+// It returns false if an argument isn't a number of an answer option.
+func parseOptionNumbers(args []string) ([]int, bool) {
+	var numbers []int
+	for _, field := range strings.FieldsFunc(strings.Join(args, ","), func(r rune) bool { return r == ',' }) {
+		number, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || number < 1 {
+			return nil, false
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, len(numbers) > 0
+}
+
+// executeVoteCommand votes for the answer options with the given numbers, counting from one in the order they are
+// shown to the user, and returns the response message. It lets users vote without the buttons of the post, e.g. on clients that
+// don't render them well. The vote is checked like a vote with a reaction, see handleReaction.
+func (p *MatterpollPlugin) executeVoteCommand(args []string, channelID, userID, trigger string, userLocalizer *i18n.Localizer) string {
+	var numbers []int
+	ok := len(args) >= 2
+	if ok {
+		numbers, ok = parseOptionNumbers(args[1:])
+	}
+	if !ok {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandVoteUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		})
+	}
+	pollID := args[0]
+
+	pl, err := p.getPoll(pollID)
+	var voteChannelID string
+	if err == nil && pl.PostID != "" {
+		voteChannelID = p.readableChannelID(pl, channelID, userID)
+	}
+	if voteChannelID == "" {
+		// Users who can't see the poll don't learn that it exists
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAdminPollNotFound,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		})
+	}
+
+	var indexes []int
+	for _, number := range numbers {
+		index := pl.ActiveOptionIndex(userID, number)
+		if index == -1 {
+			return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandVoteInvalidOption,
+				TemplateData:   map[string]interface{}{"Question": pl.Question, "Number": number},
+			})
+		}
+		indexes = append(indexes, index)
+	}
+	if len(indexes) > 1 && !pl.IsMultiVote() && !pl.Settings.Ranked {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandVoteSingleOption,
+			TemplateData:   map[string]interface{}{"Question": pl.Question},
+		})
+	}
+
+	var votes int
+	var previouslyVoted, closed bool
+	pl, err = p.updatePoll(pollID, func(pl *poll.Poll) (bool, error) {
+		votes, closed = 0, false
+		if err := p.checkChannelVoter(pl, voteChannelID, userID); err != nil {
+			return false, err
+		}
+		previouslyVoted = pl.HasVoted(userID)
+		for _, index := range indexes {
+			// Unlike a button, voting again for an answer option doesn't remove the vote
+			if voted, _ := pl.HasVotedFor(userID, index); voted {
+				continue
+			}
+			if err := pl.UpdateVote(userID, index); err != nil {
+				return false, err
+			}
+			votes++
+		}
+		if votes == 0 {
+			return false, nil
+		}
+		// A poll that got closed is only saved if it can be re-opened. Otherwise it's deleted by endPoll.
+		closed = pl.MaybeAutoClose()
+		return !closed || p.getConfiguration().endedPollLifetime() > 0, nil
+	})
+	if err != nil {
+		if lc := localizeConfigFromVoteError(err); lc != nil {
+			return p.LocalizeWithConfig(userLocalizer, lc)
+		}
+		p.API.LogWarn("failed to vote", "pollID", pollID, "error", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	if votes == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandVoteAlreadyVoted)
+	}
+	for i := 0; i < votes; i++ {
+		p.metrics.IncVotesCast()
+	}
+
+	if closed {
+		if err := p.endPoll(pl, ""); err != nil {
+			p.API.LogWarn("failed to end poll after vote", "pollID", pollID, "error", err.Error())
+		}
+		return p.LocalizeDefaultMessage(userLocalizer, responseVoteCounted)
+	}
+
+	if err := p.restorePollPost(pl); err != nil {
+		p.API.LogWarn("failed to update poll post", "pollID", pollID, "error", err.Error())
+	}
+	go p.publishPollMetadata(pl, userID)
+	p.publishPollResults(websocketEventVote, pl, voteChannelID)
+
+	if pl.Settings.HasUnlimitedVotes() || pl.IsMultiVote() {
+		return p.LocalizeWithConfig(userLocalizer, multiVoteLocalizeConfig(pl, userID, false))
+	}
+	if previouslyVoted {
+		return p.LocalizeDefaultMessage(userLocalizer, responseVoteUpdated)
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, responseVoteCounted)
+}
@@ -0,0 +1,204 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestParseOptionNumbers(t *testing.T) {
+	for name, test := range map[string]struct {
+		Args            []string
+		ExpectedNumbers []int
+	}{
+		"one number":         {Args: []string{"2"}, ExpectedNumbers: []int{2}},
+		"separated by comma": {Args: []string{"1,3"}, ExpectedNumbers: []int{1, 3}},
+		"separated by space": {Args: []string{"1", "3,"}, ExpectedNumbers: []int{1, 3}},
+		"no numbers":         {Args: []string{","}},
+		"not a number":       {Args: []string{"1", "two"}},
+		"zero":               {Args: []string{"0"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			numbers, ok := parseOptionNumbers(test.Args)
+			assert.Equal(t, test.ExpectedNumbers != nil, ok)
+			assert.Equal(t, test.ExpectedNumbers, numbers)
+		})
+	}
+}
+
+func TestPluginExecuteVoteCommand(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	getVotePoll := func(settings poll.Settings) *poll.Poll {
+		p := testutils.GetPollWithSettings(settings)
+		p.ChannelID = "channelID1"
+		return p
+	}
+	// setupVotedAPI mocks the calls that update the post of a poll after a vote
+	setupVotedAPI := func(api *plugintest.API, pl *poll.Poll) {
+		api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+		api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "postID1" && post.Type == MatterpollPostType
+		})).Return(nil, nil)
+		api.On("PublishWebSocketEvent", "has_voted", mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{UserId: "userID1"}).Return().Maybe()
+		api.On("PublishWebSocketEvent", websocketEventVote, pl.GetResults().ToMap(), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+	}
+
+	t.Run("vote counted", func(t *testing.T) {
+		pollIn := getVotePoll(poll.Settings{MaxVotes: 1})
+		pollOut := pollIn.Copy()
+		require.Nil(t, pollOut.UpdateVote("userID1", 1))
+
+		api := &plugintest.API{}
+		setupVotedAPI(api, pollOut)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(func(string) *poll.Poll { return pollIn.Copy() }, nil)
+		s.PollStore.On("Update", pollIn, pollOut).Return(nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "Your vote has been counted.",
+			p.executeVoteCommand([]string{testutils.GetPollID(), "2"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("votes for several answer options", func(t *testing.T) {
+		pollIn := getVotePoll(poll.Settings{MaxVotes: 3})
+		pollIn.AnswerOptions[1].Deleted = true
+		pollOut := pollIn.Copy()
+		require.Nil(t, pollOut.UpdateVote("userID1", 0))
+		require.Nil(t, pollOut.UpdateVote("userID1", 2))
+
+		api := &plugintest.API{}
+		setupVotedAPI(api, pollOut)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(func(string) *poll.Poll { return pollIn.Copy() }, nil)
+		s.PollStore.On("Update", pollIn, pollOut).Return(nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, p.LocalizeWithConfig(testutils.GetLocalizer(), multiVoteLocalizeConfig(pollOut, "userID1", false)),
+			p.executeVoteCommand([]string{testutils.GetPollID(), "1,", "2"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("shuffled answer options", func(t *testing.T) {
+		pollIn := getVotePoll(poll.Settings{MaxVotes: 1, Shuffle: true})
+		order := pollIn.OptionOrder("userID1")
+		require.NotEqual(t, 1, order[1], "the test needs an order that differs from the stored one")
+		pollOut := pollIn.Copy()
+		require.Nil(t, pollOut.UpdateVote("userID1", order[1]))
+
+		api := &plugintest.API{}
+		setupVotedAPI(api, pollOut)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(func(string) *poll.Poll { return pollIn.Copy() }, nil)
+		s.PollStore.On("Update", pollIn, pollOut).Return(nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "Your vote has been counted.",
+			p.executeVoteCommand([]string{testutils.GetPollID(), "2"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("already voted", func(t *testing.T) {
+		pl := getVotePoll(poll.Settings{MaxVotes: 1})
+		require.Nil(t, pl.UpdateVote("userID1", 0))
+
+		api := &plugintest.API{}
+		api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(func(string) *poll.Poll { return pl.Copy() }, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "You've already voted for these answer options.",
+			p.executeVoteCommand([]string{testutils.GetPollID(), "1"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+		s.PollStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+	t.Run("invalid answer option", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(getVotePoll(poll.Settings{MaxVotes: 1}), nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "The poll **Question** has no answer option 4.",
+			p.executeVoteCommand([]string{testutils.GetPollID(), "4"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("several answer options in a poll with one vote", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(getVotePoll(poll.Settings{MaxVotes: 1}), nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "You can only vote for one answer option in the poll **Question**.",
+			p.executeVoteCommand([]string{testutils.GetPollID(), "1,2"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("answer option requires a comment", func(t *testing.T) {
+		pl := getVotePoll(poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{2}})
+
+		api := &plugintest.API{}
+		api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(func(string) *poll.Poll { return pl.Copy() }, nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, `Your vote for "Answer 2" needs a comment that explains it.`,
+			p.executeVoteCommand([]string{testutils.GetPollID(), "2"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+		s.PollStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+	t.Run("poll in a channel the user can't read", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(false)
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(getVotePoll(poll.Settings{MaxVotes: 1}), nil)
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, "The running poll "+testutils.GetPollID()+" could not be found.",
+			p.executeVoteCommand([]string{testutils.GetPollID(), "1"}, "channelID2", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("Update fails", func(t *testing.T) {
+		pollIn := getVotePoll(poll.Settings{MaxVotes: 1})
+
+		api := &plugintest.API{}
+		api.On("HasPermissionToChannel", "userID1", "channelID1", model.PERMISSION_READ_CHANNEL).Return(true)
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+		defer api.AssertExpectations(t)
+		s := &mockstore.Store{}
+		s.PollStore.On("Get", testutils.GetPollID()).Return(func(string) *poll.Poll { return pollIn.Copy() }, nil)
+		s.PollStore.On("Update", pollIn, mock.AnythingOfType("*poll.Poll")).Return(errors.New(""))
+		defer s.AssertExpectations(t)
+		p := setupTestPlugin(t, api, s)
+
+		assert.Equal(t, commandErrorGeneric.Other,
+			p.executeVoteCommand([]string{testutils.GetPollID(), "1"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		assert.Equal(t, "Use `/poll vote <Poll ID> <option numbers>` to vote, e.g. `/poll vote <Poll ID> 2` for the second answer option. Several numbers like `1,3` vote for several answer options, if the poll allows it.",
+			p.executeVoteCommand([]string{testutils.GetPollID(), "first"}, "channelID1", "userID1", "poll", testutils.GetLocalizer()))
+	})
+}
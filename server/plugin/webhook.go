@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// resultsWebhookTimeout is the time the receiver of the results webhook has to respond.
+const resultsWebhookTimeout = 10 * time.Second
+
+// sendResultsWebhook posts the results of an ended poll as JSON to the configured webhook URL, see poll.Poll.ResultsJSON.
+// It does nothing if no URL is configured.
+func (p *MatterpollPlugin) sendResultsWebhook(poll *poll.Poll) error {
+	webhookURL := p.getConfiguration().ResultsWebhookURL
+	if webhookURL == "" {
+		return nil
+	}
+
+	b, err := poll.ResultsJSON()
+	if err != nil {
+		return errors.Wrap(err, "failed to create results")
+	}
+
+	client := &http.Client{Timeout: resultsWebhookTimeout}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, "failed to send results")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestSendResultsWebhook(t *testing.T) {
+	poll := testutils.GetPollWithVotes()
+	poll.EndedAt = 1234567899
+
+	t.Run("results are sent", func(t *testing.T) {
+		var body map[string]interface{}
+		var contentType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			b, _ := ioutil.ReadAll(r.Body)
+			_ = json.Unmarshal(b, &body)
+		}))
+		defer server.Close()
+
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+		p.setConfiguration(&configuration{Trigger: "poll", ResultsWebhookURL: server.URL})
+
+		require.NoError(t, p.sendResultsWebhook(poll))
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, testutils.GetPollID(), body["poll_id"])
+		assert.Equal(t, "Question", body["question"])
+		assert.Len(t, body["options"], 3)
+	})
+	t.Run("no webhook configured", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		assert.NoError(t, p.sendResultsWebhook(poll))
+	})
+	t.Run("webhook fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+		p.setConfiguration(&configuration{Trigger: "poll", ResultsWebhookURL: server.URL})
+
+		assert.Error(t, p.sendResultsWebhook(poll))
+	})
+	t.Run("webhook unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+		p.setConfiguration(&configuration{Trigger: "poll", ResultsWebhookURL: server.URL})
+
+		assert.Error(t, p.sendResultsWebhook(poll))
+	})
+}
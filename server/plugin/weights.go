@@ -0,0 +1,41 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// readAttributeWeights reads the weights of the voters of a poll from the user attribute named in its settings.
+// It's called when the poll ends, so the weights reflect the attribute at that time. Voters without a valid
+// positive number in the attribute have a weight of one. Erased voters keep the weight they had.
+func (p *MatterpollPlugin) readAttributeWeights(pl *poll.Poll) error {
+	attribute := pl.Settings.WeightAttribute
+	if attribute == "" {
+		return nil
+	}
+
+	weights := map[string]int{}
+	for userID, weight := range pl.Weights {
+		if poll.IsErasedVoter(userID) {
+			weights[userID] = weight
+		}
+	}
+	for voterID := range pl.VotesByUser() {
+		if poll.IsErasedVoter(voterID) || poll.IsHashedVoter(voterID) {
+			continue
+		}
+		user, appErr := p.API.GetUser(voterID)
+		if appErr != nil {
+			return errors.Wrap(appErr, "failed to get voter")
+		}
+		if weight, err := strconv.Atoi(strings.TrimSpace(user.Props[attribute])); err == nil && weight > 0 {
+			weights[voterID] = weight
+		}
+	}
+	pl.SetWeights(weights)
+	return nil
+}
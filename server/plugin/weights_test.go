@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPluginReadAttributeWeights(t *testing.T) {
+	t.Run("weights from attribute", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Props: model.StringMap{"shares": "5"}}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Props: model.StringMap{"shares": "invalid"}}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Id: "userID3"}, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		pl := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, WeightAttribute: "shares"})
+		pl.SetVoters(0, "userID1", "userID2", poll.ErasedVoterPrefix+"token")
+		pl.SetVoters(1, "userID3")
+		pl.Weights = map[string]int{"userID2": 4, poll.ErasedVoterPrefix + "token": 2}
+
+		require.NoError(t, p.readAttributeWeights(pl))
+		assert.Equal(t, map[string]int{"userID1": 5, poll.ErasedVoterPrefix + "token": 2}, pl.Weights)
+		assert.Equal(t, []int{8, 1, 0}, pl.WeightedCounts())
+	})
+	t.Run("no attribute", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		pl := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Weights: "user1:3"})
+		pl.Weights = map[string]int{"userID1": 3}
+
+		require.NoError(t, p.readAttributeWeights(pl))
+		assert.Equal(t, map[string]int{"userID1": 3}, pl.Weights)
+	})
+	t.Run("GetUser fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		pl := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, WeightAttribute: "shares"})
+		pl.SetVoters(0, "userID1")
+
+		assert.Error(t, p.readAttributeWeights(pl))
+	})
+}
@@ -0,0 +1,86 @@
+package poll
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// HashedVoterPrefix is the prefix of the hashed voter IDs anonymous polls store instead of user IDs, see SetVoterKey.
+const HashedVoterPrefix = "hmac_"
+
+// SetVoterKey sets the secret key the IDs of the voters of anonymous polls are hashed with before they are stored.
+// The hashes are salted with the poll ID, so votes can still be checked per user, but the stored poll doesn't reveal
+// who voted without the key. An empty key stores user IDs as they are. The key is never stored in the poll.
+func (p *Poll) SetVoterKey(key string) {
+	p.voterKey = key
+}
+
+// hashesVoters returns true if the poll stores hashes instead of the user IDs of its voters.
+// Anonymous polls with reveal-on-end or semi-anonymous keep the user IDs, because their voters are shown to someone.
+func (p *Poll) hashesVoters() bool {
+	return p.voterKey != "" && p.Settings.Anonymous && !p.Settings.RevealOnEnd && !p.Settings.SemiAnonymous
+}
+
+// voterID returns the ID the votes of a user are stored with. It's the user ID itself, unless the poll hashes its voters.
+// IDs that are already hashed or erased are returned as they are.
+func (p *Poll) voterID(userID string) string {
+	if !p.hashesVoters() || userID == "" || IsHashedVoter(userID) || IsErasedVoter(userID) {
+		return userID
+	}
+	mac := hmac.New(sha256.New, []byte(p.voterKey))
+	mac.Write([]byte(p.ID + ":" + userID))
+	return HashedVoterPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsHashedVoter returns true if the given voter ID is the hash of a user ID, see SetVoterKey.
+func IsHashedVoter(userID string) bool {
+	return strings.HasPrefix(userID, HashedVoterPrefix)
+}
+
+// HashVoters replaces the user IDs the votes of the poll are stored with by their hashes, if the poll hashes its voters.
+// It's used to migrate polls that were stored before voters got hashed. It returns true if the poll was modified.
+func (p *Poll) HashVoters() bool {
+	if !p.hashesVoters() {
+		return false
+	}
+
+	hashed := false
+	for _, v := range append([]string{}, p.BallotOrder...) {
+		if p.renameVoter(v, p.voterID(v)) {
+			hashed = true
+		}
+	}
+	for _, v := range p.Votes {
+		if h := p.voterID(v.UserID); h != v.UserID {
+			v.UserID = h
+			hashed = true
+		}
+	}
+	for userID, ranking := range p.Rankings {
+		if h := p.voterID(userID); h != userID {
+			delete(p.Rankings, userID)
+			p.Rankings[h] = ranking
+			hashed = true
+		}
+	}
+	for userID, count := range p.AddedOptions {
+		if h := p.voterID(userID); h != userID {
+			delete(p.AddedOptions, userID)
+			p.AddedOptions[h] = count
+			hashed = true
+		}
+	}
+	for _, c := range p.Comments {
+		if h := p.voterID(c.UserID); h != c.UserID {
+			c.UserID = h
+			hashed = true
+		}
+	}
+
+	if hashed {
+		p.touch()
+	}
+	return hashed
+}
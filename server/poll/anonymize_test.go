@@ -0,0 +1,167 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollSetVoterKey(t *testing.T) {
+	t.Run("anonymous poll stores hashes", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+		p.SetVoterKey("key")
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		voterID := p.Voters(0)[0]
+		assert.True(t, poll.IsHashedVoter(voterID))
+		assert.NotContains(t, voterID, "userID1")
+
+		assert.True(t, p.HasVoted("userID1"))
+		assert.False(t, p.HasVoted("userID2"))
+		assert.Equal(t, []string{"Answer 1"}, p.GetVotedAnswers("userID1"))
+		assert.Equal(t, []string{"Answer 1"}, p.GetMetadata("userID1", false).VotedAnswers)
+
+		// Changing the vote replaces the old one
+		require.Nil(t, p.UpdateVote("userID1", 1))
+		assert.Empty(t, p.Voters(0))
+		assert.Equal(t, []string{voterID}, p.Voters(1))
+
+		p.ResetVotes("userID1")
+		assert.False(t, p.HasVoted("userID1"))
+	})
+	t.Run("hashes are salted with the poll ID", func(t *testing.T) {
+		p1 := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+		p1.SetVoterKey("key")
+		p2 := p1.Copy()
+		p2.ID = "pollID2"
+
+		require.Nil(t, p1.UpdateVote("userID1", 0))
+		require.Nil(t, p2.UpdateVote("userID1", 0))
+		assert.NotEqual(t, p1.Voters(0)[0], p2.Voters(0)[0])
+	})
+	t.Run("hashes depend on the key", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+		p.SetVoterKey("key")
+		require.Nil(t, p.UpdateVote("userID1", 0))
+
+		p.SetVoterKey("other key")
+		assert.False(t, p.HasVoted("userID1"))
+	})
+	t.Run("ranked anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, Ranked: true, MaxVotes: 1})
+		p.SetVoterKey("key")
+
+		require.Nil(t, p.UpdateVote("userID1", 2))
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		assert.NotContains(t, p.Rankings, "userID1")
+		assert.Equal(t, []string{"Answer 3", "Answer 1"}, p.UserData("userID1").Votes)
+	})
+	t.Run("multi vote anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 2})
+		p.SetVoterKey("key")
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		require.Nil(t, p.UpdateVote("userID1", 1))
+		removed, err := p.ToggleVote("userID1", 0)
+		require.Nil(t, err)
+		assert.True(t, removed)
+		assert.Equal(t, []string{"Answer 2"}, p.GetVotedAnswers("userID1"))
+	})
+	t.Run("public poll stores user IDs", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.SetVoterKey("key")
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		assert.Equal(t, []string{"userID1"}, p.Voters(0))
+	})
+	t.Run("anonymous poll with reveal-on-end stores user IDs", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, RevealOnEnd: true, MaxVotes: 1})
+		p.SetVoterKey("key")
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		assert.Equal(t, []string{"userID1"}, p.Voters(0))
+	})
+	t.Run("semi-anonymous poll stores user IDs", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, SemiAnonymous: true, MaxVotes: 1})
+		p.SetVoterKey("key")
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		assert.Equal(t, []string{"userID1"}, p.Voters(0))
+	})
+	t.Run("no key stores user IDs", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		assert.Equal(t, []string{"userID1"}, p.Voters(0))
+	})
+	t.Run("key isn't stored", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+		p.SetVoterKey("secret key")
+
+		assert.NotContains(t, string(p.EncodeToByte()), "secret key")
+	})
+}
+
+func TestPollHashVoters(t *testing.T) {
+	t.Run("anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+		p.Votes = []*poll.Vote{{UserID: "userID1", Answer: 0, CreatedAt: 1234567890}}
+		p.SetVoterKey("key")
+		version := p.Version
+
+		assert.True(t, p.HashVoters())
+		assert.Equal(t, version+1, p.Version)
+		for i := range p.AnswerOptions {
+			for _, v := range p.Voters(i) {
+				assert.True(t, poll.IsHashedVoter(v))
+			}
+		}
+		assert.True(t, poll.IsHashedVoter(p.Votes[0].UserID))
+		assert.True(t, p.HasVoted("userID1"))
+		assert.Equal(t, 4, p.VoterCount())
+
+		// Hashing again doesn't change anything
+		assert.False(t, p.HashVoters())
+		assert.Equal(t, version+1, p.Version)
+	})
+	t.Run("ranked anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, Ranked: true, MaxVotes: 1})
+		p.SetVoters(1, "userID1")
+		p.Rankings = map[string][]int{"userID1": {1}}
+		p.SetVoterKey("key")
+
+		assert.True(t, p.HashVoters())
+		assert.NotContains(t, p.Rankings, "userID1")
+		assert.Len(t, p.Rankings, 1)
+		assert.Equal(t, []string{"Answer 2"}, p.UserData("userID1").Votes)
+	})
+	t.Run("added answer options of an anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+		p.AddedOptions = map[string]int{"userID1": 1}
+		p.SetVoterKey("key")
+
+		assert.True(t, p.HashVoters())
+		assert.NotContains(t, p.AddedOptions, "userID1")
+		assert.Equal(t, 1, p.AddedOptionCount("userID1"))
+	})
+	t.Run("comments of an anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1, Comments: true})
+		p.Comments = []*poll.Comment{{UserID: "userID1", Text: "Cheaper"}}
+		p.SetVoterKey("key")
+
+		assert.True(t, p.HashVoters())
+		assert.True(t, poll.IsHashedVoter(p.Comments[0].UserID))
+		assert.Equal(t, "Cheaper", p.GetComment("userID1"))
+	})
+	t.Run("public poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.SetVoterKey("key")
+
+		assert.False(t, p.HashVoters())
+		assert.Equal(t, testutils.GetPollWithVotes().AnswerOptions, p.AnswerOptions)
+	})
+}
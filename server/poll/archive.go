@@ -0,0 +1,87 @@
+package poll
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// ArchivedPoll is the outcome of an ended poll. It's archived together with the channel of the poll when the poll ends,
+// so that past polls can be looked up after they have been deleted from the store.
+// It doesn't contain the IDs of any users, hence it's not affected if users erase their data.
+type ArchivedPoll struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+	Question  string `json:"question"`
+	// CreatedAt and ClosedAt are in milliseconds.
+	CreatedAt int64    `json:"created_at"`
+	ClosedAt  int64    `json:"closed_at"`
+	Answers   []string `json:"answers"`
+	Votes     []int    `json:"votes"`
+	Voters    int      `json:"voters"`
+}
+
+// Archive returns the outcome of an ended poll that was posted in a channel.
+// If the poll hasn't been marked as ended, it's considered closed now.
+func (p *Poll) Archive(channelID string) *ArchivedPoll {
+	closedAt := p.EndedAt
+	if closedAt == 0 {
+		closedAt = model.GetMillis()
+	}
+
+	a := &ArchivedPoll{
+		ID:        p.ID,
+		ChannelID: channelID,
+		Question:  p.Question,
+		CreatedAt: p.CreatedAt,
+		ClosedAt:  closedAt,
+		Answers:   []string{},
+		Votes:     []int{},
+		Voters:    p.VoterCount(),
+	}
+	for i, o := range p.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
+		a.Answers = append(a.Answers, o.Answer)
+		a.Votes = append(a.Votes, p.VoteCount(i))
+	}
+	return a
+}
+
+// Winners returns the answer options with the most votes together with their number of votes.
+// It returns nil if nobody voted.
+func (a *ArchivedPoll) Winners() ([]string, int) {
+	most := 0
+	for _, v := range a.Votes {
+		if v > most {
+			most = v
+		}
+	}
+	if most == 0 {
+		return nil, 0
+	}
+
+	var winners []string
+	for i, v := range a.Votes {
+		if v == most {
+			winners = append(winners, a.Answers[i])
+		}
+	}
+	return winners, most
+}
+
+// EncodeToByte returns an archived poll as a byte array
+func (a *ArchivedPoll) EncodeToByte() []byte {
+	b, _ := json.Marshal(a)
+	return b
+}
+
+// DecodeArchivedPollFromByte tries to create an archived poll from a byte array. It returns nil if the data is invalid.
+func DecodeArchivedPollFromByte(b []byte) *ArchivedPoll {
+	a := ArchivedPoll{}
+	if err := json.Unmarshal(b, &a); err != nil || a.ID == "" || len(a.Answers) != len(a.Votes) {
+		return nil
+	}
+	return &a
+}
@@ -0,0 +1,60 @@
+package poll_test
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollArchive(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567899 })
+	defer patch.Unpatch()
+
+	t.Run("ended poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.EndedAt = 1234567895
+		p.AnswerOptions[2].Deleted = true
+
+		a := p.Archive("channelID1")
+		assert.Equal(t, &poll.ArchivedPoll{
+			ID:        testutils.GetPollID(),
+			ChannelID: "channelID1",
+			Question:  "Question",
+			CreatedAt: 1234567890,
+			ClosedAt:  1234567895,
+			Answers:   []string{"Answer 1", "Answer 2"},
+			Votes:     []int{3, 1},
+			Voters:    4,
+		}, a)
+		winners, votes := a.Winners()
+		assert.Equal(t, []string{"Answer 1"}, winners)
+		assert.Equal(t, 3, votes)
+	})
+	t.Run("poll without end time", func(t *testing.T) {
+		a := testutils.GetPoll().Archive("channelID1")
+		assert.Equal(t, int64(1234567899), a.ClosedAt)
+		winners, votes := a.Winners()
+		assert.Nil(t, winners)
+		assert.Equal(t, 0, votes)
+	})
+}
+
+func TestArchivedPollWinners(t *testing.T) {
+	a := &poll.ArchivedPoll{Answers: []string{"A", "B", "C"}, Votes: []int{2, 1, 2}}
+	winners, votes := a.Winners()
+	assert.Equal(t, []string{"A", "C"}, winners)
+	assert.Equal(t, 2, votes)
+}
+
+func TestEncodeDecodeArchivedPoll(t *testing.T) {
+	a := testutils.GetPollWithVotes().Archive("channelID1")
+
+	assert.Equal(t, a, poll.DecodeArchivedPollFromByte(a.EncodeToByte()))
+	assert.Nil(t, poll.DecodeArchivedPollFromByte([]byte("invalid")))
+	assert.Nil(t, poll.DecodeArchivedPollFromByte([]byte(`{"id":"pollID1","answers":["A"],"votes":[]}`)))
+}
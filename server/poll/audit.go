@@ -0,0 +1,60 @@
+package poll
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// AuditDayLayout is the layout of the UTC days the audit log is grouped by.
+const AuditDayLayout = "2006-01-02"
+
+// AuditRecord is an entry of the audit log. The json tags are part of the audit log export.
+type AuditRecord struct {
+	// CreatedAt is the time of the event in milliseconds.
+	CreatedAt int64 `json:"created_at"`
+	// Event is the lifecycle event of the poll, e.g. "poll_created".
+	Event string `json:"event"`
+	// ActorID is the ID of the user who caused the event. It's empty if the plugin caused it,
+	// e.g. by ending a poll after its deadline, or if it would reveal a vote of an anonymous poll.
+	ActorID   string `json:"actor_id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	PollID    string `json:"poll_id"`
+	Question  string `json:"question"`
+	// Option is the answer option that was added. It's only set for "option_added" events.
+	Option string `json:"option,omitempty"`
+}
+
+// NewAuditRecord returns an audit record of an event of the poll that happens now.
+func (p *Poll) NewAuditRecord(event, actorID, channelID string) *AuditRecord {
+	return &AuditRecord{
+		CreatedAt: model.GetMillis(),
+		Event:     event,
+		ActorID:   actorID,
+		ChannelID: channelID,
+		PollID:    p.ID,
+		Question:  p.Question,
+	}
+}
+
+// Day returns the UTC day of the record, see AuditDayLayout.
+func (r *AuditRecord) Day() string {
+	return time.Unix(0, r.CreatedAt*int64(time.Millisecond)).UTC().Format(AuditDayLayout)
+}
+
+// EncodeAuditRecordsToByte returns a list of audit records as a byte array
+func EncodeAuditRecordsToByte(records []*AuditRecord) []byte {
+	b, _ := json.Marshal(records)
+	return b
+}
+
+// DecodeAuditRecordsFromByte tries to create a list of audit records from a byte array.
+// It returns nil if the data is invalid.
+func DecodeAuditRecordsFromByte(b []byte) []*AuditRecord {
+	var records []*AuditRecord
+	if err := json.Unmarshal(b, &records); err != nil || records == nil {
+		return nil
+	}
+	return records
+}
@@ -0,0 +1,39 @@
+package poll_test
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollNewAuditRecord(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	record := testutils.GetPoll().NewAuditRecord("poll_created", "userID1", "channelID1")
+	assert.Equal(t, &poll.AuditRecord{
+		CreatedAt: 1234567890,
+		Event:     "poll_created",
+		ActorID:   "userID1",
+		ChannelID: "channelID1",
+		PollID:    testutils.GetPollID(),
+		Question:  "Question",
+	}, record)
+	assert.Equal(t, "1970-01-15", record.Day())
+}
+
+func TestEncodeDecodeAuditRecords(t *testing.T) {
+	records := []*poll.AuditRecord{
+		{CreatedAt: 1234567890, Event: "poll_created", ActorID: "userID1", ChannelID: "channelID1", PollID: "pollID1", Question: "Question"},
+		{CreatedAt: 1234567899, Event: "option_added", ActorID: "userID2", ChannelID: "channelID1", PollID: "pollID1", Question: "Question", Option: "New Option"},
+	}
+
+	assert.Equal(t, records, poll.DecodeAuditRecordsFromByte(poll.EncodeAuditRecordsToByte(records)))
+	assert.Nil(t, poll.DecodeAuditRecordsFromByte([]byte("invalid")))
+	assert.Nil(t, poll.DecodeAuditRecordsFromByte([]byte("null")))
+}
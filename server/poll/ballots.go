@@ -0,0 +1,256 @@
+package poll
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// pollVotes are the parts of a poll that change with every vote, including the version. They are stored apart
+// from the rest of the poll, so a vote doesn't rewrite the whole poll, see EncodeVotesToByte.
+type pollVotes struct {
+	Ballots     map[string][]int `json:"ballots,omitempty"`
+	BallotOrder []string         `json:"ballot_order,omitempty"`
+	VoteCounts  []int            `json:"vote_counts,omitempty"`
+	Rankings    map[string][]int `json:"rankings,omitempty"`
+	Votes       []*Vote          `json:"votes,omitempty"`
+	Version     int              `json:"version,omitempty"`
+	ModifiedAt  int64            `json:"modified_at,omitempty"`
+}
+
+// EncodeWithoutVotesToByte returns the poll without its votes as byte array, see EncodeVotesToByte.
+func (p *Poll) EncodeWithoutVotesToByte() []byte {
+	p2 := *p
+	p2.Ballots = nil
+	p2.BallotOrder = nil
+	p2.VoteCounts = nil
+	p2.Rankings = nil
+	p2.Votes = nil
+	p2.Version = 0
+	p2.ModifiedAt = 0
+	return p2.EncodeToByte()
+}
+
+// EncodeVotesToByte returns the votes, the rankings, the vote log and the version of the poll as byte array.
+// It returns nil if the poll has none of them.
+func (p *Poll) EncodeVotesToByte() []byte {
+	b, _ := json.Marshal(&pollVotes{
+		Ballots:     p.Ballots,
+		BallotOrder: p.BallotOrder,
+		VoteCounts:  p.VoteCounts,
+		Rankings:    p.Rankings,
+		Votes:       p.Votes,
+		Version:     p.Version,
+		ModifiedAt:  p.ModifiedAt,
+	})
+	if string(b) == "{}" {
+		return nil
+	}
+	return b
+}
+
+// DecodeVotesFromByte sets the votes of a poll decoded with DecodePollFromByte from the output of EncodeVotesToByte.
+// Empty data means the poll has no votes, unless they were stored together with the poll before schema version 2.
+// An error wrapping ErrCorruptPoll is returned if the data is invalid.
+func (p *Poll) DecodeVotesFromByte(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	var votes pollVotes
+	if err := json.Unmarshal(b, &votes); err != nil {
+		return errors.Wrap(ErrCorruptPoll, err.Error())
+	}
+	p.Ballots = votes.Ballots
+	p.BallotOrder = votes.BallotOrder
+	p.VoteCounts = votes.VoteCounts
+	p.Rankings = votes.Rankings
+	p.Votes = votes.Votes
+	p.Version = votes.Version
+	p.ModifiedAt = votes.ModifiedAt
+	return nil
+}
+
+// Voters returns the voter IDs of the users who voted for the answer option at index, in the order they first voted
+// in the poll, see voterID.
+func (p *Poll) Voters(index int) []string {
+	voters := []string{}
+	if p.VoteCount(index) == 0 {
+		return voters
+	}
+	for _, voterID := range p.BallotOrder {
+		for _, i := range p.Ballots[voterID] {
+			if i == index {
+				voters = append(voters, voterID)
+				break
+			}
+		}
+	}
+	return voters
+}
+
+// VoteCount returns the number of votes for the answer option at index.
+func (p *Poll) VoteCount(index int) int {
+	if index < 0 || index >= len(p.VoteCounts) {
+		return 0
+	}
+	return p.VoteCounts[index]
+}
+
+// SetVoters replaces the voters of the answer option at index with the given voter IDs without recording
+// the votes in the vote log. It's used to restore the votes of imported polls, votes of users go through UpdateVote.
+func (p *Poll) SetVoters(index int, voterIDs ...string) {
+	for _, voterID := range p.Voters(index) {
+		p.removeBallotVote(voterID, index)
+	}
+	for _, voterID := range voterIDs {
+		p.addBallotVote(voterID, index)
+	}
+}
+
+// activeOptionIndexes returns the indexes of the answer options that aren't deleted, see ActiveOptions.
+func (p *Poll) activeOptionIndexes() []int {
+	indexes := []int{}
+	for i, o := range p.AnswerOptions {
+		if !o.Deleted {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// sortedBallot returns the indexes of the answer options a voter voted for in the order of the answer options.
+func (p *Poll) sortedBallot(voterID string) []int {
+	indexes := append([]int{}, p.Ballots[voterID]...)
+	sort.Ints(indexes)
+	return indexes
+}
+
+// addBallotVote adds a vote of a voter for the answer option at index. Voting twice for the same option isn't checked.
+func (p *Poll) addBallotVote(voterID string, index int) {
+	if p.Ballots == nil {
+		p.Ballots = map[string][]int{}
+	}
+	if _, ok := p.Ballots[voterID]; !ok {
+		p.BallotOrder = append(p.BallotOrder, voterID)
+	}
+	p.Ballots[voterID] = append(p.Ballots[voterID], index)
+	for len(p.VoteCounts) <= index {
+		p.VoteCounts = append(p.VoteCounts, 0)
+	}
+	p.VoteCounts[index]++
+}
+
+// removeBallotVote removes the vote of a voter for the answer option at index. It returns true if there was one.
+// Voters without votes are removed, so they are listed last, if they vote again.
+func (p *Poll) removeBallotVote(voterID string, index int) bool {
+	indexes := p.Ballots[voterID]
+	for j, i := range indexes {
+		if i != index {
+			continue
+		}
+		if len(indexes) == 1 {
+			p.removeBallot(voterID)
+		} else {
+			p.Ballots[voterID] = append(indexes[:j:j], indexes[j+1:]...)
+		}
+		p.VoteCounts[index]--
+		p.trimVoteCounts()
+		return true
+	}
+	return false
+}
+
+// removeBallot removes a voter without votes from Ballots and BallotOrder.
+func (p *Poll) removeBallot(voterID string) {
+	delete(p.Ballots, voterID)
+	if len(p.Ballots) == 0 {
+		p.Ballots = nil
+	}
+	order := p.BallotOrder[:0]
+	for _, v := range p.BallotOrder {
+		if v != voterID {
+			order = append(order, v)
+		}
+	}
+	if len(order) == 0 {
+		order = nil
+	}
+	p.BallotOrder = order
+}
+
+// trimVoteCounts removes the counts of the last answer options without votes, so polls with the same votes
+// have the same counts, no matter how many votes were removed.
+func (p *Poll) trimVoteCounts() {
+	n := len(p.VoteCounts)
+	for n > 0 && p.VoteCounts[n-1] == 0 {
+		n--
+	}
+	if n == 0 {
+		p.VoteCounts = nil
+		return
+	}
+	p.VoteCounts = p.VoteCounts[:n]
+}
+
+// removeOptionFromBallots removes the votes for the answer option at index and updates the indexes of the following
+// answer options after the option was removed.
+func (p *Poll) removeOptionFromBallots(index int) {
+	for _, voterID := range p.Voters(index) {
+		p.removeBallotVote(voterID, index)
+	}
+	for _, indexes := range p.Ballots {
+		for j := range indexes {
+			if indexes[j] > index {
+				indexes[j]--
+			}
+		}
+	}
+	if index < len(p.VoteCounts) {
+		p.VoteCounts = append(p.VoteCounts[:index:index], p.VoteCounts[index+1:]...)
+		p.trimVoteCounts()
+	}
+}
+
+// renameVoter replaces a voter ID in the votes with another one, e.g. its hash. It returns true if the voter had voted.
+// The voter keeps their place in BallotOrder.
+func (p *Poll) renameVoter(voterID, newVoterID string) bool {
+	indexes, ok := p.Ballots[voterID]
+	if !ok || voterID == newVoterID {
+		return false
+	}
+	delete(p.Ballots, voterID)
+	p.Ballots[newVoterID] = indexes
+	for i, v := range p.BallotOrder {
+		if v == voterID {
+			p.BallotOrder[i] = newVoterID
+		}
+	}
+	return true
+}
+
+// migrateLegacyVoters moves the voters of the answer options of polls stored before schema version 2 to Ballots.
+func (p *Poll) migrateLegacyVoters() {
+	for i, o := range p.AnswerOptions {
+		for _, voterID := range o.LegacyVoters {
+			p.addBallotVote(voterID, i)
+		}
+		o.LegacyVoters = nil
+	}
+}
+
+// copyBallots deep copies the votes of p to p2.
+func (p *Poll) copyBallots(p2 *Poll) {
+	if p.Ballots != nil {
+		p2.Ballots = make(map[string][]int, len(p.Ballots))
+		for voterID, indexes := range p.Ballots {
+			p2.Ballots[voterID] = append([]int{}, indexes...)
+		}
+	}
+	if p.BallotOrder != nil {
+		p2.BallotOrder = append([]string{}, p.BallotOrder...)
+	}
+	if p.VoteCounts != nil {
+		p2.VoteCounts = append([]int{}, p.VoteCounts...)
+	}
+}
@@ -0,0 +1,81 @@
+package poll_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollEncodeVotesToByte(t *testing.T) {
+	t.Run("votes are stored apart from the poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Ranked: true})
+		p.Rankings = map[string][]int{"userID1": {0}}
+		p.Votes = []*poll.Vote{{UserID: "userID1", Answer: 0, CreatedAt: 1234567890}}
+		p.Version = 3
+		p.ModifiedAt = 1234567891
+
+		p2, err := poll.DecodePollFromByte(p.EncodeWithoutVotesToByte(), true)
+		require.NoError(t, err)
+		assert.Zero(t, p2.TotalVotes())
+		assert.Nil(t, p2.Rankings)
+		assert.Nil(t, p2.Votes)
+		assert.Zero(t, p2.Version)
+
+		require.NoError(t, p2.DecodeVotesFromByte(p.EncodeVotesToByte()))
+		assert.Equal(t, p, p2)
+	})
+	t.Run("poll without votes", func(t *testing.T) {
+		p := testutils.GetPoll()
+		assert.Nil(t, p.EncodeVotesToByte())
+
+		p2 := testutils.GetPoll()
+		require.NoError(t, p2.DecodeVotesFromByte(nil))
+		assert.Equal(t, p, p2)
+	})
+	t.Run("corrupt votes", func(t *testing.T) {
+		p := testutils.GetPoll()
+		err := p.DecodeVotesFromByte([]byte("invalid"))
+		assert.True(t, errors.Is(err, poll.ErrCorruptPoll))
+	})
+}
+
+func TestPollVoters(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 3})
+	require.NoError(t, p.UpdateVote("b", 2))
+	require.NoError(t, p.UpdateVote("a", 0))
+	require.NoError(t, p.UpdateVote("a", 2))
+	require.NoError(t, p.UpdateVote("b", 0))
+
+	assert.Equal(t, []string{"b", "a"}, p.Voters(0))
+	assert.Equal(t, []string{}, p.Voters(1))
+	assert.Equal(t, []string{"b", "a"}, p.Voters(2))
+	assert.Equal(t, []string{}, p.Voters(3))
+	assert.Equal(t, 2, p.VoteCount(0))
+	assert.Equal(t, 0, p.VoteCount(1))
+	assert.Equal(t, 0, p.VoteCount(-1))
+	assert.Equal(t, 4, p.TotalVotes())
+	assert.Equal(t, 2, p.VoterCount())
+}
+
+func TestPollSetVoters(t *testing.T) {
+	p := testutils.GetPollWithVotes()
+	p.SetVoters(0, "userID5", "userID1")
+
+	assert.Equal(t, []string{"userID5", "userID1"}, p.Voters(0))
+	assert.Equal(t, []string{"userID4"}, p.Voters(1))
+	assert.False(t, p.HasVoted("userID2"))
+	assert.True(t, p.HasVoted("userID5"))
+	assert.Equal(t, 3, p.TotalVotes())
+	assert.Empty(t, p.Votes)
+
+	p.SetVoters(0)
+	p.SetVoters(1)
+	assert.Nil(t, p.Ballots)
+	assert.Nil(t, p.BallotOrder)
+	assert.Nil(t, p.VoteCounts)
+}
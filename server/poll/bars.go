@@ -0,0 +1,46 @@
+package poll
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Styles of the bars that show the share of votes of every answer option, see SetBarStyle.
+const (
+	BarStyleNone    = "none"
+	BarStyleBlocks  = "blocks"
+	BarStyleSquares = "squares"
+)
+
+// barWidth is the number of segments of a bar.
+const barWidth = 10
+
+// barSegments contains the filled and the empty segment of every bar style that shows bars.
+var barSegments = map[string][2]string{
+	BarStyleBlocks:  {"█", "░"},
+	BarStyleSquares: {"🟦", "⬜"},
+}
+
+// SetBarStyle sets the style of the bars that show the share of votes of every answer option in the results,
+// e.g. BarStyleBlocks. An empty style or BarStyleNone shows no bars. The style is never stored.
+func (p *Poll) SetBarStyle(style string) {
+	p.barStyle = style
+}
+
+// IsBarStyle returns true if style is one of the bar styles.
+func IsBarStyle(style string) bool {
+	_, ok := barSegments[style]
+	return ok || style == BarStyleNone
+}
+
+// makeBar returns a bar for a share of votes in percent followed by the rounded percentage, e.g. "█████░░░░░ 50%".
+// It returns an empty string if the poll shows no bars.
+func (p *Poll) makeBar(percentage float64) string {
+	segments, ok := barSegments[p.barStyle]
+	if !ok {
+		return ""
+	}
+	filled := int(math.Round(percentage / 100 * barWidth))
+	return strings.Repeat(segments[0], filled) + strings.Repeat(segments[1], barWidth-filled) + fmt.Sprintf(" %.0f%%", percentage)
+}
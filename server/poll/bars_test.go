@@ -0,0 +1,77 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestIsBarStyle(t *testing.T) {
+	assert.True(t, poll.IsBarStyle(poll.BarStyleBlocks))
+	assert.True(t, poll.IsBarStyle(poll.BarStyleSquares))
+	assert.True(t, poll.IsBarStyle(poll.BarStyleNone))
+	assert.False(t, poll.IsBarStyle(""))
+	assert.False(t, poll.IsBarStyle("circles"))
+}
+
+func TestPollToPostActionsBars(t *testing.T) {
+	t.Run("progress", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Progress: true})
+		p.SetBarStyle(poll.BarStyleBlocks)
+		attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+		require.Len(t, attachments, 1)
+		assert.Equal(t, "---\n"+
+			"**Answer 1** ████████░░ 75%\n"+
+			"**Answer 2** ███░░░░░░░ 25%\n"+
+			"**Answer 3** ░░░░░░░░░░ 0%\n"+
+			"**Poll Settings**: progress\n"+
+			"**Total votes**: 4", attachments[0].Text)
+	})
+	t.Run("without progress", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.SetBarStyle(poll.BarStyleBlocks)
+		attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+		require.Len(t, attachments, 1)
+		assert.Equal(t, "---\n**Total votes**: 4", attachments[0].Text)
+	})
+	t.Run("secret", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Progress: true, Secret: true})
+		p.SetBarStyle(poll.BarStyleBlocks)
+		attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+		require.Len(t, attachments, 1)
+		assert.NotContains(t, attachments[0].Text, "█")
+	})
+	t.Run("no bars", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Progress: true})
+		p.SetBarStyle(poll.BarStyleNone)
+		attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+		require.Len(t, attachments, 1)
+		assert.Equal(t, "---\n**Poll Settings**: progress\n**Total votes**: 4", attachments[0].Text)
+	})
+}
+
+func TestPollToEndPollPostBars(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+
+	p := testutils.GetPollWithVotes()
+	p.SetBarStyle(poll.BarStyleSquares)
+	post, appErr := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+	require.Nil(t, appErr)
+
+	fields := post.Attachments()[0].Fields
+	require.Len(t, fields, 3)
+	assert.Equal(t, "🟦🟦🟦🟦🟦🟦🟦🟦⬜⬜ 75%\n@userID1, @userID2 and @userID3", fields[0].Value)
+	assert.Equal(t, "🟦🟦🟦⬜⬜⬜⬜⬜⬜⬜ 25%\n@userID4", fields[1].Value)
+	assert.Equal(t, "⬜⬜⬜⬜⬜⬜⬜⬜⬜⬜ 0%", fields[2].Value)
+}
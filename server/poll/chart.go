@@ -0,0 +1,254 @@
+package poll
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Types of the chart image of the final results, see Settings.Chart.
+const (
+	ChartBar = "bar"
+	ChartPie = "pie"
+)
+
+const (
+	chartMargin    = 24
+	chartHeight    = 320
+	chartBarWidth  = 48
+	chartBarGap    = 16
+	chartPieRadius = 140
+	// chartLabelGap is the space between a label and what it labels.
+	chartLabelGap = 8
+	// chartDotSize is the size in pixels of one dot of the glyphs labels are drawn with.
+	chartDotSize = 3
+	// chartGlyphHeight is the height in pixels of a label.
+	chartGlyphHeight = 5 * chartDotSize
+	// chartGlyphAdvance is the width in pixels of a character of a label, including the space to the next one.
+	chartGlyphAdvance = 4 * chartDotSize
+)
+
+var (
+	chartBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	chartForeground = color.RGBA{0x3d, 0x3c, 0x40, 0xff}
+	chartEmpty      = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	// chartPalette contains the colors of the answer options. They repeat for polls with more answer options.
+	chartPalette = []color.RGBA{
+		{0x16, 0x6d, 0xe0, 0xff},
+		{0xf7, 0x43, 0x43, 0xff},
+		{0x06, 0xd6, 0xa0, 0xff},
+		{0xff, 0xbc, 0x1f, 0xff},
+		{0x7a, 0x5c, 0xc6, 0xff},
+		{0x2d, 0xbd, 0xc4, 0xff},
+		{0xe0, 0x71, 0x36, 0xff},
+		{0x8c, 0x8c, 0x8c, 0xff},
+	}
+)
+
+// chartGlyphs contains the characters labels can be drawn with as five rows of three dots.
+// Answer options can't be labeled with their text, since there are no fonts to draw it with.
+// Characters without a glyph are drawn as space.
+var chartGlyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+}
+
+// IsChart returns true if chart is one of the chart types.
+func IsChart(chart string) bool {
+	return chart == ChartBar || chart == ChartPie
+}
+
+// ResultsChart returns the results of the poll as PNG image of the chart type of Settings.Chart, e.g. to attach it
+// to the announcement of the end of the poll. The active answer options are numbered in the order they are shown,
+// starting at one. A bar chart labels every bar with the number of votes, a pie chart lists the share of the votes
+// of every answer option next to it. Weighted polls count the weights of the voters.
+// It returns nil if the poll has no chart or its results are hidden.
+func (p *Poll) ResultsChart() ([]byte, error) {
+	if p.Settings.Chart == "" || p.HidesResults() {
+		return nil, nil
+	}
+
+	var img *image.RGBA
+	switch p.Settings.Chart {
+	case ChartBar:
+		img = drawBarChart(p.chartCounts())
+	case ChartPie:
+		img = drawPieChart(p.chartCounts())
+	default:
+		return nil, errors.Errorf("unknown chart type %q", p.Settings.Chart)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, errors.Wrap(err, "failed to encode chart")
+	}
+	return buf.Bytes(), nil
+}
+
+// chartCounts returns the number of votes of every active answer option, or the sum of the weights of their voters
+// if the votes of the poll are weighted.
+func (p *Poll) chartCounts() []int {
+	weights := p.weightsByVoterID()
+	indexes := p.activeOptionIndexes()
+	counts := make([]int, len(indexes))
+	for i, index := range indexes {
+		if p.Settings.IsWeighted() {
+			counts[i] = weightedCount(p.Voters(index), weights)
+		} else {
+			counts[i] = p.VoteCount(index)
+		}
+	}
+	return counts
+}
+
+// drawBarChart draws a bar for every count, labeled with the count above and the number of the answer option below.
+// The highest bar fills the height of the chart.
+func drawBarChart(counts []int) *image.RGBA {
+	width := 2*chartMargin + len(counts)*(chartBarWidth+chartBarGap) - chartBarGap
+	img := newChartImage(width, chartHeight)
+
+	top := chartMargin + chartGlyphHeight + chartLabelGap
+	bottom := chartHeight - chartMargin - chartGlyphHeight - chartLabelGap
+	max := 0
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+
+	for i, count := range counts {
+		x := chartMargin + i*(chartBarWidth+chartBarGap)
+		barTop := bottom
+		if max > 0 {
+			barTop = bottom - (bottom-top)*count/max
+		}
+		fillRect(img, image.Rect(x, barTop, x+chartBarWidth, bottom), chartColor(i))
+		drawLabel(img, strconv.Itoa(count), x+(chartBarWidth-labelWidth(strconv.Itoa(count)))/2, barTop-chartLabelGap-chartGlyphHeight)
+		drawLabel(img, strconv.Itoa(i+1), x+(chartBarWidth-labelWidth(strconv.Itoa(i+1)))/2, bottom+chartLabelGap)
+	}
+	fillRect(img, image.Rect(chartMargin/2, bottom, width-chartMargin/2, bottom+2), chartForeground)
+	return img
+}
+
+// drawPieChart draws a pie with a slice for every count, starting at the top and going clockwise.
+// The legend next to the pie lists the number of every answer option with its share of the total count.
+// If nothing has been counted, an empty pie is drawn.
+func drawPieChart(counts []int) *image.RGBA {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	pieSize := 2 * (chartMargin + chartPieRadius)
+	rowHeight := chartGlyphHeight + chartLabelGap
+	legendWidth := chartGlyphHeight + chartLabelGap + labelWidth("00 100%") + chartMargin
+	height := pieSize
+	if legendHeight := 2*chartMargin + len(counts)*rowHeight; legendHeight > height {
+		height = legendHeight
+	}
+	img := newChartImage(pieSize+legendWidth, height)
+
+	// bounds contains the upper bound of the share of every slice, from zero to one
+	bounds := make([]float64, len(counts))
+	sum := 0
+	for i, count := range counts {
+		sum += count
+		if total > 0 {
+			bounds[i] = float64(sum) / float64(total)
+		}
+	}
+
+	center := chartMargin + chartPieRadius
+	for y := -chartPieRadius; y <= chartPieRadius; y++ {
+		for x := -chartPieRadius; x <= chartPieRadius; x++ {
+			if x*x+y*y > chartPieRadius*chartPieRadius {
+				continue
+			}
+			c := chartEmpty
+			if total > 0 {
+				// Zero is at the top, the angle increases clockwise
+				share := math.Atan2(float64(x), float64(-y)) / (2 * math.Pi)
+				if share < 0 {
+					share++
+				}
+				for i, bound := range bounds {
+					if share < bound || i == len(bounds)-1 {
+						c = chartColor(i)
+						break
+					}
+				}
+			}
+			img.SetRGBA(center+x, center+y, c)
+		}
+	}
+
+	for i, count := range counts {
+		x := pieSize
+		y := chartMargin + i*rowHeight
+		fillRect(img, image.Rect(x, y, x+chartGlyphHeight, y+chartGlyphHeight), chartColor(i))
+
+		percentage := 0.0
+		if total > 0 {
+			percentage = float64(count) / float64(total) * 100
+		}
+		drawLabel(img, fmt.Sprintf("%d %.0f%%", i+1, percentage), x+chartGlyphHeight+chartLabelGap, y)
+	}
+	return img
+}
+
+// chartColor returns the color of the answer option at index i.
+func chartColor(i int) color.RGBA {
+	return chartPalette[i%len(chartPalette)]
+}
+
+func newChartImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Bounds(), chartBackground)
+	return img
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// drawLabel draws text with its top left corner at x and y, see chartGlyphs.
+func drawLabel(img *image.RGBA, text string, x, y int) {
+	for _, r := range text {
+		glyph := chartGlyphs[r]
+		for row, dots := range glyph {
+			for col, dot := range dots {
+				if dot != '#' {
+					continue
+				}
+				dx, dy := x+col*chartDotSize, y+row*chartDotSize
+				fillRect(img, image.Rect(dx, dy, dx+chartDotSize, dy+chartDotSize), chartForeground)
+			}
+		}
+		x += chartGlyphAdvance
+	}
+}
+
+// labelWidth returns the width in pixels of a label with text.
+func labelWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return n*chartGlyphAdvance - chartDotSize
+}
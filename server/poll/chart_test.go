@@ -0,0 +1,92 @@
+package poll_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+var white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+func decodeChart(t *testing.T, b []byte) image.Image {
+	img, err := png.Decode(bytes.NewReader(b))
+	require.NoError(t, err)
+	return img
+}
+
+func rgba(img image.Image, x, y int) color.RGBA {
+	return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+}
+
+func TestIsChart(t *testing.T) {
+	assert.True(t, poll.IsChart(poll.ChartBar))
+	assert.True(t, poll.IsChart(poll.ChartPie))
+	assert.False(t, poll.IsChart(""))
+	assert.False(t, poll.IsChart("line"))
+}
+
+func TestPollResultsChart(t *testing.T) {
+	t.Run("bar chart", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Chart: poll.ChartBar})
+
+		b, err := p.ResultsChart()
+		require.NoError(t, err)
+		img := decodeChart(t, b)
+
+		// One bar per answer option. Answer 1 has the most votes and fills the height, Answer 3 has none.
+		assert.Equal(t, 2*24+3*48+2*16, img.Bounds().Dx())
+		first, second := rgba(img, 24+24, 60), rgba(img, 24+64+24, 260)
+		assert.NotEqual(t, white, first)
+		assert.NotEqual(t, white, second)
+		assert.NotEqual(t, first, second)
+		assert.Equal(t, white, rgba(img, 24+64+24, 60))
+		assert.Equal(t, white, rgba(img, 24+128+24, 260))
+	})
+	t.Run("pie chart", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Chart: poll.ChartPie})
+
+		b, err := p.ResultsChart()
+		require.NoError(t, err)
+		img := decodeChart(t, b)
+
+		// Answer 1 covers three quarters clockwise from the top, Answer 2 the last quarter
+		center := 24 + 140
+		first := rgba(img, center+100, center)
+		assert.NotEqual(t, white, first)
+		assert.Equal(t, first, rgba(img, center, center+100))
+		second := rgba(img, center-100, center-10)
+		assert.NotEqual(t, white, second)
+		assert.NotEqual(t, first, second)
+		assert.Equal(t, white, rgba(img, 2, 2))
+	})
+	t.Run("pie chart without votes", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Chart: poll.ChartPie})
+
+		b, err := p.ResultsChart()
+		require.NoError(t, err)
+		img := decodeChart(t, b)
+
+		center := 24 + 140
+		assert.NotEqual(t, white, rgba(img, center, center))
+	})
+	t.Run("no chart", func(t *testing.T) {
+		b, err := testutils.GetPollWithVotes().ResultsChart()
+		require.NoError(t, err)
+		assert.Nil(t, b)
+	})
+	t.Run("hidden results", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Chart: poll.ChartBar, Secret: true})
+
+		b, err := p.ResultsChart()
+		require.NoError(t, err)
+		assert.Nil(t, b)
+	})
+}
@@ -0,0 +1,223 @@
+package poll
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// MaxCommentLength is the maximum number of characters of a comment, see SetComment.
+const MaxCommentLength = 300
+
+var (
+	pollEndPostCommentsHeading = &i18n.Message{
+		ID:    "poll.endPost.comments.heading",
+		Other: "**Comments:**",
+	}
+	pollEndPostComment = &i18n.Message{
+		ID:    "poll.endPost.comments.comment",
+		Other: "{{.User}}: {{.Text}}",
+	}
+)
+
+// Comment is the short explanation a voter gave for their vote, see Settings.Comments.
+type Comment struct {
+	// UserID is the ID the votes of the author are stored with, see voterID.
+	UserID string `json:"user_id"`
+	Text   string `json:"text"`
+}
+
+// GetComment returns the comment of a user or an empty string if the user hasn't commented.
+func (p *Poll) GetComment(userID string) string {
+	voterID := p.voterID(userID)
+	for _, c := range p.Comments {
+		if c.UserID == voterID {
+			return c.Text
+		}
+	}
+	return ""
+}
+
+// CanComment returns a *VoteError if a user can't comment on their vote. Only users who have voted can comment,
+// and only while the poll is running.
+func (p *Poll) CanComment(userID string) error {
+	if !p.Settings.Comments {
+		return newCommentsNotAllowedError()
+	}
+	if p.HasEnded() {
+		return newPollEndedError()
+	}
+	if !p.HasVoted(userID) {
+		return &VoteError{
+			Err: ErrNotAllowed,
+			ErrorMessage: &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.setComment.notVoted",
+					Other: "Please vote before you add a comment.",
+				},
+			},
+		}
+	}
+	return nil
+}
+
+// RequiresComment returns true if a vote for the answer option at index needs a comment, see Settings.RequireComment.
+func (p *Poll) RequiresComment(index int) bool {
+	for _, number := range p.Settings.RequireComment {
+		if number == index+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// SetComment stores the comment a user gave for their vote, replacing an earlier one. An empty text removes the comment,
+// unless the user has voted for an answer option that requires one. The text is put on a single line.
+// See CanComment for who can comment.
+func (p *Poll) SetComment(userID, text string) error {
+	if err := p.CanComment(userID); err != nil {
+		return err
+	}
+	text, err := normalizeComment(text)
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		for i := range p.AnswerOptions {
+			if voted, _ := p.HasVotedFor(userID, i); voted && p.RequiresComment(i) {
+				return newCommentRequiredError(p.AnswerOptions[i].Answer)
+			}
+		}
+	}
+
+	p.setComment(p.voterID(userID), text)
+	return nil
+}
+
+// VoteWithComment votes for the answer option at index like UpdateVote and stores the comment that explains the vote
+// like SetComment. Unlike UpdateVote, it allows votes for answer options that require a comment.
+func (p *Poll) VoteWithComment(userID string, index int, text string) error {
+	if !p.Settings.Comments {
+		return newCommentsNotAllowedError()
+	}
+	text, err := normalizeComment(text)
+	if err != nil {
+		return err
+	}
+	if err := p.updateVote(userID, index, text); err != nil {
+		return err
+	}
+
+	p.setComment(p.voterID(userID), text)
+	return nil
+}
+
+func newCommentsNotAllowedError() *VoteError {
+	return &VoteError{
+		Err: ErrNotAllowed,
+		ErrorMessage: &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.setComment.notAllowed",
+				Other: "This poll doesn't allow comments.",
+			},
+		},
+	}
+}
+
+func newCommentRequiredError(answer string) *VoteError {
+	return &VoteError{
+		Err: ErrCommentRequired,
+		ErrorMessage: &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.setComment.required",
+				Other: `Your vote for "{{.Answer}}" needs a comment that explains it.`,
+			},
+			Data: map[string]interface{}{
+				"Answer": answer,
+			},
+		},
+	}
+}
+
+// normalizeComment puts the text of a comment on a single line and checks its length.
+func normalizeComment(text string) (string, error) {
+	// Comments are listed line by line in the results
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > MaxCommentLength {
+		return "", &VoteError{
+			Err: ErrInvalidComment,
+			ErrorMessage: &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.setComment.tooLong",
+					Other: "Comments can have at most {{.Max}} characters.",
+				},
+				Data: map[string]interface{}{
+					"Max": MaxCommentLength,
+				},
+			},
+		}
+	}
+	return text, nil
+}
+
+// setComment stores the comment of the user with the given voter ID. An empty text removes the comment.
+func (p *Poll) setComment(voterID, text string) {
+	for i, c := range p.Comments {
+		if c.UserID != voterID {
+			continue
+		}
+		if c.Text == text {
+			return
+		}
+		if text == "" {
+			p.Comments = append(p.Comments[:i], p.Comments[i+1:]...)
+		} else {
+			c.Text = text
+		}
+		p.touch()
+		return
+	}
+	if text == "" {
+		return
+	}
+	p.Comments = append(p.Comments, &Comment{UserID: voterID, Text: text})
+	p.touch()
+}
+
+// removeComment removes the comment of the user with the given voter ID. It returns true if there was one.
+func (p *Poll) removeComment(voterID string) bool {
+	for i, c := range p.Comments {
+		if c.UserID == voterID {
+			p.Comments = append(p.Comments[:i], p.Comments[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// makeCommentsText returns the comments of the poll for the end poll post, in the order they were written.
+// Their authors are named unless the voters of the poll are hidden. It returns an empty string if there are no comments.
+func (p *Poll) makeCommentsText(localizer *i18n.Localizer, convert IDToNameConverter) (string, *model.AppError) {
+	if len(p.Comments) == 0 {
+		return "", nil
+	}
+
+	lines := []string{localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostCommentsHeading})}
+	for _, c := range p.Comments {
+		if !p.revealsVotersOnEnd() {
+			lines = append(lines, "- "+c.Text)
+			continue
+		}
+		name, err := joinVoterNames(localizer, []string{c.UserID}, convert)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, "- "+localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollEndPostComment,
+			TemplateData:   map[string]interface{}{"User": name, "Text": c.Text},
+		}))
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,193 @@
+package poll_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollSetComment(t *testing.T) {
+	t.Run("comment is added, replaced and removed", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true})
+		version := p.Version
+
+		require.Nil(t, p.SetComment("userID2", " Cheaper\nand   faster "))
+		assert.Equal(t, "Cheaper and faster", p.GetComment("userID2"))
+		assert.Equal(t, version+1, p.Version)
+
+		require.Nil(t, p.SetComment("userID2", "Cheaper and faster"))
+		assert.Equal(t, version+1, p.Version)
+
+		require.Nil(t, p.SetComment("userID3", "Faster"))
+		require.Nil(t, p.SetComment("userID2", "Cheaper"))
+		assert.Equal(t, []*poll.Comment{{UserID: "userID2", Text: "Cheaper"}, {UserID: "userID3", Text: "Faster"}}, p.Comments)
+
+		require.Nil(t, p.SetComment("userID2", " "))
+		assert.Equal(t, "", p.GetComment("userID2"))
+		assert.Equal(t, []*poll.Comment{{UserID: "userID3", Text: "Faster"}}, p.Comments)
+		assert.Equal(t, version+4, p.Version)
+	})
+	t.Run("comment of an anonymous poll is stored with the hashed voter ID", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1, Comments: true})
+		p.SetVoterKey("key")
+		require.True(t, p.HashVoters())
+
+		require.Nil(t, p.SetComment("userID2", "Cheaper"))
+		assert.True(t, poll.IsHashedVoter(p.Comments[0].UserID))
+		assert.Equal(t, "Cheaper", p.GetComment("userID2"))
+	})
+	for name, test := range map[string]struct {
+		Poll          *poll.Poll
+		UserID        string
+		Text          string
+		ExpectedError error
+	}{
+		"comments not allowed": {
+			Poll:          testutils.GetPollWithVotes(),
+			UserID:        "userID2",
+			Text:          "Cheaper",
+			ExpectedError: poll.ErrNotAllowed,
+		},
+		"user hasn't voted": {
+			Poll:          testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true}),
+			UserID:        "userID5",
+			Text:          "Cheaper",
+			ExpectedError: poll.ErrNotAllowed,
+		},
+		"poll has ended": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true})
+				p.EndedAt = 1000
+				return p
+			}(),
+			UserID:        "userID2",
+			Text:          "Cheaper",
+			ExpectedError: poll.ErrPollEnded,
+		},
+		"comment is too long": {
+			Poll:          testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true}),
+			UserID:        "userID2",
+			Text:          strings.Repeat("ü", poll.MaxCommentLength+1),
+			ExpectedError: poll.ErrInvalidComment,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := test.Poll.SetComment(test.UserID, test.Text)
+			assert.True(t, errors.Is(err, test.ExpectedError))
+			var voteErr *poll.VoteError
+			require.True(t, errors.As(err, &voteErr))
+			assert.NotNil(t, voteErr.ErrorMessage)
+			assert.Nil(t, test.Poll.Comments)
+		})
+	}
+}
+
+func TestPollResetVotesRemovesComment(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true})
+	require.Nil(t, p.SetComment("userID2", "Cheaper"))
+
+	p.ResetVotes("userID2")
+	assert.Equal(t, "", p.GetComment("userID2"))
+	assert.Empty(t, p.Comments)
+}
+
+func TestPollToEndPollPostComments(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+
+	t.Run("authors are named", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true})
+		require.Nil(t, p.SetComment("userID4", "Faster"))
+		require.Nil(t, p.SetComment("userID2", "Cheaper"))
+
+		post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+		require.Nil(t, err)
+		assert.Equal(t, "This poll has ended. The results are:\n**Comments:**\n- @userID4: Faster\n- @userID2: Cheaper", post.Attachments()[0].Text)
+	})
+	t.Run("anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1, Comments: true})
+		require.Nil(t, p.SetComment("userID2", "Cheaper"))
+
+		post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+		require.Nil(t, err)
+		assert.Equal(t, "This poll has ended. The results are:\n**Comments:**\n- Cheaper", post.Attachments()[0].Text)
+	})
+	t.Run("no comments", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true})
+
+		post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+		require.Nil(t, err)
+		assert.Equal(t, "This poll has ended. The results are:", post.Attachments()[0].Text)
+	})
+}
+
+func TestPollToPostActionsComments(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Comments: true})
+
+	actions := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")[0].Actions
+	var comment *model.PostAction
+	for _, action := range actions {
+		if action.Id == "comment" {
+			comment = action
+		}
+	}
+	require.NotNil(t, comment)
+	assert.Equal(t, "Comment…", comment.Name)
+	assert.Equal(t, "/plugins/com.github.matterpoll.matterpoll/api/v1/polls/"+testutils.GetPollID()+"/comment/request", comment.Integration.URL)
+}
+
+func TestPollRequireComment(t *testing.T) {
+	settings := poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{2}}
+
+	t.Run("vote without comment", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(settings)
+
+		err := p.UpdateVote("userID1", 1)
+		assert.True(t, errors.Is(err, poll.ErrCommentRequired))
+		var voteErr *poll.VoteError
+		require.True(t, errors.As(err, &voteErr))
+		assert.Equal(t, map[string]interface{}{"Answer": "Answer 2"}, voteErr.ErrorMessage.Data)
+		assert.False(t, p.HasVoted("userID1"))
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+	})
+	t.Run("vote with comment", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(settings)
+
+		require.Nil(t, p.VoteWithComment("userID1", 1, " Too expensive "))
+		voted, _ := p.HasVotedFor("userID1", 1)
+		assert.True(t, voted)
+		assert.Equal(t, "Too expensive", p.GetComment("userID1"))
+
+		// The comment also explains a later vote
+		require.Nil(t, p.UpdateVote("userID1", 1))
+	})
+	t.Run("vote with empty comment", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(settings)
+
+		assert.True(t, errors.Is(p.VoteWithComment("userID1", 1, "  "), poll.ErrCommentRequired))
+		assert.False(t, p.HasVoted("userID1"))
+	})
+	t.Run("required comment can't be removed", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(settings)
+		require.Nil(t, p.VoteWithComment("userID1", 1, "Too expensive"))
+
+		assert.True(t, errors.Is(p.SetComment("userID1", ""), poll.ErrCommentRequired))
+		require.Nil(t, p.SetComment("userID1", "Far too expensive"))
+		assert.Equal(t, "Far too expensive", p.GetComment("userID1"))
+	})
+	t.Run("comments not allowed", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		assert.True(t, errors.Is(p.VoteWithComment("userID1", 1, "Too expensive"), poll.ErrNotAllowed))
+		assert.False(t, p.HasVoted("userID1"))
+	})
+}
@@ -0,0 +1,122 @@
+package poll
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	// ErrInvalidIndex is returned if an answer option index is out of range.
+	ErrInvalidIndex = errors.New("invalid index")
+	// ErrInvalidUser is returned if an empty user ID is given.
+	ErrInvalidUser = errors.New("invalid userID")
+	// ErrOptionDeleted is returned if the answer option was deleted.
+	ErrOptionDeleted = errors.New("answer option is deleted")
+	// ErrNotAllowed is returned if a user is not allowed to vote in a poll.
+	ErrNotAllowed = errors.New("user is not allowed to vote")
+	// ErrAlreadyVoted is returned if a user has already voted for an answer option.
+	ErrAlreadyVoted = errors.New("user has already voted for this option")
+	// ErrNoVotesLeft is returned if a user has used up all votes.
+	ErrNoVotesLeft = errors.New("user has no votes left")
+	// ErrOptionFull is returned if an answer option has reached the capacity of the poll.
+	ErrOptionFull = errors.New("answer option is full")
+	// ErrPollEnded is returned if the poll has already ended.
+	ErrPollEnded = errors.New("poll has ended")
+	// ErrInvalidAnswer is returned if the answer a user wrote in can't be added to a poll.
+	ErrInvalidAnswer = errors.New("invalid answer")
+	// ErrInvalidComment is returned if the comment a user gave for a vote can't be stored.
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrCommentRequired is returned if a vote for an answer option needs a comment, but the user gave none.
+	ErrCommentRequired = errors.New("comment required")
+	// ErrCorruptPoll is returned if stored data can't be decoded into a valid poll.
+	ErrCorruptPoll = errors.New("corrupt poll data")
+	// ErrNewerSchemaVersion is returned if a poll was stored with a newer schema version than this version supports,
+	// e.g. after a downgrade of the plugin. The data isn't corrupt and must be kept.
+	ErrNewerSchemaVersion = errors.New("poll was stored with a newer schema version")
+)
+
+// Severity tells whether an error a user is told about was caused by the user or by the server.
+type Severity int
+
+const (
+	// SeverityUser marks errors caused by the input or the permissions of the user. It's the default.
+	SeverityUser Severity = iota
+	// SeverityInternal marks errors caused by a failure of the server, e.g. of the store or the Mattermost API.
+	SeverityInternal
+)
+
+// ErrorMessage contains error messsage for a user that can be localized.
+// It implements error and may wrap the error that caused it, which is never shown to the user.
+type ErrorMessage struct {
+	Message *i18n.Message
+	Data    map[string]interface{}
+	// Severity tells whether the user or the server caused the error.
+	Severity Severity
+	// StatusCode is the HTTP status code of responses with this error. If it's zero, it's derived from Severity.
+	StatusCode int
+	// Err is the error that caused this one, if any.
+	Err error
+}
+
+// NewInternalErrorMessage returns an error message for a failure of the server that was caused by err.
+func NewInternalErrorMessage(message *i18n.Message, err error) *ErrorMessage {
+	return &ErrorMessage{
+		Message:  message,
+		Severity: SeverityInternal,
+		Err:      err,
+	}
+}
+
+// Error returns the ID of the message and the text of the wrapped error, if there is one.
+func (e *ErrorMessage) Error() string {
+	s := "error message"
+	if e.Message != nil && e.Message.ID != "" {
+		s = e.Message.ID
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Unwrap returns the wrapped error.
+func (e *ErrorMessage) Unwrap() error {
+	return e.Err
+}
+
+// IsInternal returns true if the error was caused by a failure of the server and not by the user.
+func (e *ErrorMessage) IsInternal() bool {
+	return e.Severity == SeverityInternal
+}
+
+// HTTPStatus returns the HTTP status code of responses with this error.
+func (e *ErrorMessage) HTTPStatus() int {
+	if e.StatusCode != 0 {
+		return e.StatusCode
+	}
+	if e.IsInternal() {
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+// VoteError is returned if a vote could not be performed.
+// It wraps one of the sentinel errors, so callers can check the reason using errors.Is.
+// ErrorMessage contains a message for the user. It is nil if the error was caused by
+// invalid input, e.g. ErrInvalidIndex, that is not meant to be shown to the user.
+type VoteError struct {
+	Err          error
+	ErrorMessage *ErrorMessage
+}
+
+// Error returns the error text of the wrapped error.
+func (e *VoteError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *VoteError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,43 @@
+package poll_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func TestErrorMessage(t *testing.T) {
+	message := &i18n.Message{ID: "test.error", Other: "Something failed"}
+
+	t.Run("user error", func(t *testing.T) {
+		errMsg := &poll.ErrorMessage{Message: message}
+		assert.Equal(t, "test.error", errMsg.Error())
+		assert.False(t, errMsg.IsInternal())
+		assert.Equal(t, http.StatusBadRequest, errMsg.HTTPStatus())
+		assert.Nil(t, errors.Unwrap(errMsg))
+	})
+	t.Run("user error with status code", func(t *testing.T) {
+		errMsg := &poll.ErrorMessage{Message: message, StatusCode: http.StatusTooManyRequests}
+		assert.False(t, errMsg.IsInternal())
+		assert.Equal(t, http.StatusTooManyRequests, errMsg.HTTPStatus())
+	})
+	t.Run("internal error", func(t *testing.T) {
+		cause := errors.New("store unavailable")
+		errMsg := poll.NewInternalErrorMessage(message, cause)
+		assert.Equal(t, "test.error: store unavailable", errMsg.Error())
+		assert.True(t, errMsg.IsInternal())
+		assert.Equal(t, http.StatusInternalServerError, errMsg.HTTPStatus())
+		assert.True(t, errors.Is(errMsg, cause))
+	})
+	t.Run("wrapped", func(t *testing.T) {
+		err := errors.Wrap(&poll.ErrorMessage{Message: message}, "failed to update poll")
+		var errMsg *poll.ErrorMessage
+		assert.True(t, errors.As(err, &errMsg))
+		assert.Equal(t, message, errMsg.Message)
+	})
+}
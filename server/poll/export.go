@@ -0,0 +1,455 @@
+package poll
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ExportFormatVersion is the version of the portable JSON format written by ExportJSON.
+// It only has to be increased for incompatible changes. Adding new fields doesn't require a new version.
+const ExportFormatVersion = 1
+
+// exportedPoll is the portable representation of a poll.
+// The json tags of this struct and its fields are a public contract and must not be changed.
+type exportedPoll struct {
+	FormatVersion  int                      `json:"format_version"`
+	ID             string                   `json:"id"`
+	PostID         string                   `json:"post_id,omitempty"`
+	ChannelID      string                   `json:"channel_id,omitempty"`
+	RootID         string                   `json:"root_id,omitempty"`
+	CreatedAt      int64                    `json:"created_at"`
+	Creator        string                   `json:"creator"`
+	Question       string                   `json:"question"`
+	AnswerOptions  []*exportedAnswerOption  `json:"answer_options"`
+	Settings       exportedSettings         `json:"settings"`
+	AllowedVoters  []string                 `json:"allowed_voters,omitempty"`
+	EndedAt        int64                    `json:"ended_at,omitempty"`
+	ModifiedAt     int64                    `json:"modified_at,omitempty"`
+	Rankings       map[string][]int         `json:"rankings,omitempty"`
+	PendingOptions []*exportedPendingOption `json:"pending_options,omitempty"`
+	Weights        map[string]int           `json:"weights,omitempty"`
+	Broadcasts     []*exportedBroadcastPost `json:"broadcasts,omitempty"`
+	Comments       []*exportedComment       `json:"comments,omitempty"`
+}
+
+// exportedComment is the portable representation of the comment a voter gave for a vote.
+type exportedComment struct {
+	UserID string `json:"user_id"`
+	Text   string `json:"text"`
+}
+
+// exportedBroadcastPost is the portable representation of a copy of the post of a poll in another channel.
+type exportedBroadcastPost struct {
+	ChannelID string `json:"channel_id"`
+	PostID    string `json:"post_id,omitempty"`
+}
+
+// exportedAnswerOption is the portable representation of an answer option.
+type exportedAnswerOption struct {
+	Answer  string   `json:"answer"`
+	Voters  []string `json:"voters"`
+	Deleted bool     `json:"deleted,omitempty"`
+	// Time is in milliseconds.
+	Time     int64  `json:"time,omitempty"`
+	AddedBy  string `json:"added_by,omitempty"`
+	Abstain  bool   `json:"abstain,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+// exportedPendingOption is the portable representation of a suggested answer option that waits for approval.
+type exportedPendingOption struct {
+	ID          string `json:"id"`
+	Answer      string `json:"answer"`
+	SuggestedBy string `json:"suggested_by"`
+}
+
+// exportedSettings is the portable representation of the poll settings.
+type exportedSettings struct {
+	Anonymous       bool `json:"anonymous"`
+	Progress        bool `json:"progress"`
+	PublicAddOption bool `json:"public_add_option"`
+	CloseOnQuorum   bool `json:"close_on_quorum,omitempty"`
+	HoldForQuorum   bool `json:"hold_for_quorum,omitempty"`
+	RevealOnEnd     bool `json:"reveal_on_end,omitempty"`
+	SemiAnonymous   bool `json:"semi_anonymous,omitempty"`
+	Ranked          bool `json:"ranked,omitempty"`
+	Secret          bool `json:"secret,omitempty"`
+	MaxVotes        int  `json:"max_votes"`
+	Quorum          int  `json:"quorum,omitempty"`
+	QuorumPercent   int  `json:"quorum_percent,omitempty"`
+	// EndTime is in milliseconds.
+	EndTime  int64  `json:"end_time,omitempty"`
+	Capacity int    `json:"capacity,omitempty"`
+	Voters   string `json:"voters,omitempty"`
+	Channels string `json:"channels,omitempty"`
+	In       string `json:"in,omitempty"`
+	DM       string `json:"dm,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	Chart    string `json:"chart,omitempty"`
+	Board    string `json:"board,omitempty"`
+	Weights  string `json:"weights,omitempty"`
+	// WeightAttribute is the name of the user attribute the weights are read from.
+	WeightAttribute string `json:"weight_attribute,omitempty"`
+	Quiz            int    `json:"quiz,omitempty"`
+	ScaleMin        int    `json:"scale_min,omitempty"`
+	ScaleMax        int    `json:"scale_max,omitempty"`
+	Meeting         bool   `json:"meeting,omitempty"`
+	Invite          bool   `json:"invite,omitempty"`
+	// Remind is in milliseconds.
+	Remind         int64 `json:"remind,omitempty"`
+	Reactions      bool  `json:"reactions,omitempty"`
+	AllowOther     bool  `json:"allow_other,omitempty"`
+	ApproveOptions bool  `json:"approve_options,omitempty"`
+	Shuffle        bool  `json:"shuffle,omitempty"`
+	ThreadResults  bool  `json:"thread_results,omitempty"`
+	Abstain        bool  `json:"abstain,omitempty"`
+	Comments       bool  `json:"comments,omitempty"`
+	RequireComment []int `json:"require_comment,omitempty"`
+}
+
+// ExportJSON returns the poll in a portable JSON format that is stable across plugin versions.
+// Use ImportJSON to restore a poll from it.
+func (p *Poll) ExportJSON() ([]byte, error) {
+	e := exportedPoll{
+		FormatVersion: ExportFormatVersion,
+		ID:            p.ID,
+		PostID:        p.PostID,
+		ChannelID:     p.ChannelID,
+		RootID:        p.RootID,
+		CreatedAt:     p.CreatedAt,
+		Creator:       p.Creator,
+		Question:      p.Question,
+		AnswerOptions: make([]*exportedAnswerOption, len(p.AnswerOptions)),
+		Settings: exportedSettings{
+			Anonymous:       p.Settings.Anonymous,
+			Progress:        p.Settings.Progress,
+			PublicAddOption: p.Settings.PublicAddOption,
+			MaxVotes:        p.Settings.MaxVotes,
+			Quorum:          p.Settings.Quorum,
+			QuorumPercent:   p.Settings.QuorumPercent,
+			CloseOnQuorum:   p.Settings.CloseOnQuorum,
+			HoldForQuorum:   p.Settings.HoldForQuorum,
+			RevealOnEnd:     p.Settings.RevealOnEnd,
+			SemiAnonymous:   p.Settings.SemiAnonymous,
+			Ranked:          p.Settings.Ranked,
+			Secret:          p.Settings.Secret,
+			EndTime:         p.Settings.EndTime,
+			Capacity:        p.Settings.Capacity,
+			Voters:          p.Settings.Voters,
+			Channels:        p.Settings.Channels,
+			In:              p.Settings.In,
+			DM:              p.Settings.DM,
+			Locale:          p.Settings.Locale,
+			Chart:           p.Settings.Chart,
+			Board:           p.Settings.Board,
+			Weights:         p.Settings.Weights,
+			WeightAttribute: p.Settings.WeightAttribute,
+			Quiz:            p.Settings.Quiz,
+			ScaleMin:        p.Settings.ScaleMin,
+			ScaleMax:        p.Settings.ScaleMax,
+			Meeting:         p.Settings.Meeting,
+			Invite:          p.Settings.Invite,
+			Remind:          p.Settings.Remind,
+			Reactions:       p.Settings.Reactions,
+			AllowOther:      p.Settings.AllowOther,
+			ApproveOptions:  p.Settings.ApproveOptions,
+			Shuffle:         p.Settings.Shuffle,
+			ThreadResults:   p.Settings.ThreadResults,
+			Abstain:         p.Settings.Abstain,
+			Comments:        p.Settings.Comments,
+			RequireComment:  p.Settings.RequireComment,
+		},
+		AllowedVoters: p.AllowedVoters,
+		EndedAt:       p.EndedAt,
+		ModifiedAt:    p.ModifiedAt,
+		Rankings:      p.Rankings,
+		Weights:       p.Weights,
+	}
+	for i, o := range p.AnswerOptions {
+		e.AnswerOptions[i] = &exportedAnswerOption{
+			Answer:   o.Answer,
+			Voters:   p.Voters(i),
+			Deleted:  o.Deleted,
+			Time:     o.Time,
+			AddedBy:  o.AddedBy,
+			Abstain:  o.Abstain,
+			ImageURL: o.ImageURL,
+		}
+	}
+	for _, o := range p.PendingOptions {
+		e.PendingOptions = append(e.PendingOptions, &exportedPendingOption{
+			ID:          o.ID,
+			Answer:      o.Answer,
+			SuggestedBy: o.SuggestedBy,
+		})
+	}
+	for _, b := range p.Broadcasts {
+		e.Broadcasts = append(e.Broadcasts, &exportedBroadcastPost{
+			ChannelID: b.ChannelID,
+			PostID:    b.PostID,
+		})
+	}
+	for _, c := range p.Comments {
+		e.Comments = append(e.Comments, &exportedComment{
+			UserID: c.UserID,
+			Text:   c.Text,
+		})
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal poll")
+	}
+	return b, nil
+}
+
+// ImportJSON restores a poll from the portable JSON format created by ExportJSON.
+// Unknown fields are ignored, so polls exported by newer plugin versions can still be imported.
+func ImportJSON(b []byte) (*Poll, error) {
+	var e exportedPoll
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal poll")
+	}
+	if e.FormatVersion > ExportFormatVersion {
+		return nil, errors.Errorf("unsupported format version %d", e.FormatVersion)
+	}
+	if e.ID == "" {
+		return nil, errors.New("missing poll id")
+	}
+
+	p := &Poll{
+		SchemaVersion: CurrentSchemaVersion,
+		ID:            e.ID,
+		PostID:        e.PostID,
+		ChannelID:     e.ChannelID,
+		RootID:        e.RootID,
+		CreatedAt:     e.CreatedAt,
+		Creator:       e.Creator,
+		Question:      e.Question,
+		AnswerOptions: make([]*AnswerOption, len(e.AnswerOptions)),
+		Settings: Settings{
+			Anonymous:       e.Settings.Anonymous,
+			Progress:        e.Settings.Progress,
+			PublicAddOption: e.Settings.PublicAddOption,
+			MaxVotes:        e.Settings.MaxVotes,
+			Quorum:          e.Settings.Quorum,
+			QuorumPercent:   e.Settings.QuorumPercent,
+			CloseOnQuorum:   e.Settings.CloseOnQuorum,
+			HoldForQuorum:   e.Settings.HoldForQuorum,
+			RevealOnEnd:     e.Settings.RevealOnEnd,
+			SemiAnonymous:   e.Settings.SemiAnonymous,
+			Ranked:          e.Settings.Ranked,
+			Secret:          e.Settings.Secret,
+			EndTime:         e.Settings.EndTime,
+			Capacity:        e.Settings.Capacity,
+			Voters:          e.Settings.Voters,
+			Channels:        e.Settings.Channels,
+			In:              e.Settings.In,
+			DM:              e.Settings.DM,
+			Locale:          e.Settings.Locale,
+			Chart:           e.Settings.Chart,
+			Board:           e.Settings.Board,
+			Weights:         e.Settings.Weights,
+			WeightAttribute: e.Settings.WeightAttribute,
+			Quiz:            e.Settings.Quiz,
+			ScaleMin:        e.Settings.ScaleMin,
+			ScaleMax:        e.Settings.ScaleMax,
+			Meeting:         e.Settings.Meeting,
+			Invite:          e.Settings.Invite,
+			Remind:          e.Settings.Remind,
+			Reactions:       e.Settings.Reactions,
+			AllowOther:      e.Settings.AllowOther,
+			ApproveOptions:  e.Settings.ApproveOptions,
+			Shuffle:         e.Settings.Shuffle,
+			ThreadResults:   e.Settings.ThreadResults,
+			Abstain:         e.Settings.Abstain,
+			Comments:        e.Settings.Comments,
+			RequireComment:  e.Settings.RequireComment,
+		},
+		EndedAt:    e.EndedAt,
+		ModifiedAt: e.ModifiedAt,
+	}
+	if len(e.Rankings) > 0 {
+		p.Rankings = e.Rankings
+	}
+	if len(e.AllowedVoters) > 0 {
+		p.AllowedVoters = e.AllowedVoters
+	}
+	if len(e.Weights) > 0 {
+		p.Weights = e.Weights
+	}
+	if p.Settings.MaxVotes == 0 || p.Settings.MaxVotes < UnlimitedVotes {
+		p.Settings.MaxVotes = 1
+	}
+	for i, o := range e.AnswerOptions {
+		p.AnswerOptions[i] = &AnswerOption{
+			Answer:  o.Answer,
+			Deleted: o.Deleted,
+			Time:    o.Time,
+			AddedBy: o.AddedBy,
+			Abstain: o.Abstain,
+		}
+		// Images are only shown from URLs that could have been given when adding the answer option
+		if isImageURL(o.ImageURL) {
+			p.AnswerOptions[i].ImageURL = o.ImageURL
+		}
+		p.SetVoters(i, o.Voters...)
+	}
+	for _, o := range e.PendingOptions {
+		p.PendingOptions = append(p.PendingOptions, &PendingOption{
+			ID:          o.ID,
+			Answer:      o.Answer,
+			SuggestedBy: o.SuggestedBy,
+		})
+	}
+	for _, b := range e.Broadcasts {
+		p.Broadcasts = append(p.Broadcasts, &BroadcastPost{
+			ChannelID: b.ChannelID,
+			PostID:    b.PostID,
+		})
+	}
+	for _, c := range e.Comments {
+		p.Comments = append(p.Comments, &Comment{
+			UserID: c.UserID,
+			Text:   c.Text,
+		})
+	}
+	return p, nil
+}
+
+// exportedResults is the portable representation of the results of a poll.
+// The json tags of this struct and its fields are a public contract and must not be changed.
+type exportedResults struct {
+	PollID    string `json:"poll_id"`
+	PostID    string `json:"post_id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	Question  string `json:"question"`
+	Creator   string `json:"creator"`
+	CreatedAt int64  `json:"created_at"`
+	EndedAt   int64  `json:"ended_at,omitempty"`
+	// Voters is the number of users who voted. It's omitted if the results are hidden.
+	Voters  int                     `json:"voters,omitempty"`
+	Options []*exportedOptionResult `json:"options"`
+}
+
+// exportedOptionResult is the portable representation of the results of an answer option.
+type exportedOptionResult struct {
+	Answer  string `json:"answer"`
+	Abstain bool   `json:"abstain,omitempty"`
+	// Votes is omitted if the results are hidden.
+	Votes *int `json:"votes,omitempty"`
+	// Weight is the sum of the weights of the votes. It's omitted if the votes aren't weighted or the results are hidden.
+	Weight *int `json:"weight,omitempty"`
+	// Voters contains user IDs. It's omitted if the voters are hidden or nobody voted for the answer option.
+	Voters []string `json:"voters,omitempty"`
+}
+
+// ResultsJSON returns the results of the poll in a portable JSON format, e.g. to send them to other systems.
+// The same information as in ResultsCSV is included: The voters of every answer option are left out if they are hidden
+// and the number of votes if the results are hidden. Deleted answer options are not included.
+func (p *Poll) ResultsJSON() ([]byte, error) {
+	hidesResults := p.HidesResults()
+	hidesVoters := p.HidesVoters()
+
+	e := exportedResults{
+		PollID:    p.ID,
+		PostID:    p.PostID,
+		ChannelID: p.ChannelID,
+		Question:  p.Question,
+		Creator:   p.Creator,
+		CreatedAt: p.CreatedAt,
+		EndedAt:   p.EndedAt,
+		Options:   []*exportedOptionResult{},
+	}
+	if !hidesResults {
+		e.Voters = p.VoterCount()
+	}
+	weights := p.weightsByVoterID()
+	for _, i := range p.activeOptionIndexes() {
+		o := p.AnswerOptions[i]
+		result := &exportedOptionResult{Answer: o.Answer, Abstain: o.Abstain}
+		if !hidesResults {
+			votes := p.VoteCount(i)
+			result.Votes = &votes
+			if p.Settings.IsWeighted() {
+				weight := weightedCount(p.Voters(i), weights)
+				result.Weight = &weight
+			}
+		}
+		if !hidesVoters {
+			result.Voters = p.Voters(i)
+		}
+		e.Options = append(e.Options, result)
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal results")
+	}
+	return b, nil
+}
+
+// ResultsCSV returns the results of the poll as CSV with one column per answer option.
+// If the voters are hidden, a single row contains the number of votes of each answer option.
+// Otherwise there is one row per voter, starting with the user ID, where voted answer options are marked with 1.
+// If the results are hidden, only the header is returned. Deleted answer options are not included.
+func (p *Poll) ResultsCSV() ([]byte, error) {
+	options := p.ActiveOptions()
+	indexes := p.activeOptionIndexes()
+	hidesVoters := p.HidesVoters()
+
+	var records [][]string
+	header := []string{}
+	if !hidesVoters {
+		header = append(header, "User")
+	}
+	for _, o := range options {
+		header = append(header, o.Answer)
+	}
+	records = append(records, header)
+
+	switch {
+	case p.HidesResults():
+		// Nothing but the answer options may be revealed
+	case hidesVoters:
+		counts := make([]string, len(indexes))
+		for i, index := range indexes {
+			counts[i] = strconv.Itoa(p.VoteCount(index))
+		}
+		records = append(records, counts)
+	default:
+		votes := map[string][]string{}
+		for i, index := range indexes {
+			for _, userID := range p.Voters(index) {
+				if _, ok := votes[userID]; !ok {
+					votes[userID] = make([]string, len(options))
+					for j := range votes[userID] {
+						votes[userID][j] = "0"
+					}
+				}
+				votes[userID][i] = "1"
+			}
+		}
+
+		userIDs := make([]string, 0, len(votes))
+		for userID := range votes {
+			userIDs = append(userIDs, userID)
+		}
+		sort.Strings(userIDs)
+		for _, userID := range userIDs {
+			records = append(records, append([]string{userID}, votes[userID]...))
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, errors.Wrap(err, "failed to write csv")
+	}
+	return buf.Bytes(), nil
+}
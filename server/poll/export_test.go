@@ -0,0 +1,390 @@
+package poll_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestExportImportJSON(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll *poll.Poll
+	}{
+		"poll without votes": {
+			Poll: testutils.GetPoll(),
+		},
+		"poll with votes": {
+			Poll: testutils.GetPollWithVotes(),
+		},
+		"poll with settings": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, Progress: true, PublicAddOption: true, MaxVotes: 2}),
+		},
+		"poll with deleted option": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.AnswerOptions[1].Deleted = true
+				return p
+			}(),
+		},
+		"quiz with a scale": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Quiz: 2, ScaleMin: 1, ScaleMax: 3}),
+		},
+		"poll with a reminder": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, EndTime: 10000, Remind: 2000}),
+		},
+		"meeting poll": {
+			Poll: func() *poll.Poll {
+				p, _ := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "2021-10-02T15:00"}, poll.Settings{Invite: true}, poll.Limits{})
+				p.SetVoters(0, "userID1")
+				return p
+			}(),
+		},
+		"poll with suggested options": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+				p.PendingOptions = []*poll.PendingOption{{ID: "optionID1", Answer: "New Option", SuggestedBy: "userID2"}}
+				return p
+			}(),
+		},
+		"poll with channel and thread": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.ChannelID = "channelID1"
+				p.RootID = "rootID1"
+				return p
+			}(),
+		},
+		"poll with abstain option": {
+			Poll: func() *poll.Poll {
+				p, _ := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1, Abstain: true})
+				p.SetVoters(2, "userID2")
+				return p
+			}(),
+		},
+		"poll with images": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.AnswerOptions[0].ImageURL = "https://example.org/a.png"
+				return p
+			}(),
+		},
+		"poll with comments": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Comments: true})
+				p.Comments = []*poll.Comment{{UserID: "userID1", Text: "Cheaper"}}
+				return p
+			}(),
+		},
+		"weighted poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Weights: "user1:3"})
+				p.Weights = map[string]int{"userID1": 3}
+				return p
+			}(),
+		},
+		"broadcast poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Channels: "dev"})
+				p.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}}
+				return p
+			}(),
+		},
+		"ended poll with quorum": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Quorum: 2, CloseOnQuorum: true})
+				p.EndedAt = 1234567899
+				return p
+			}(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := test.Poll.ExportJSON()
+			require.NoError(t, err)
+
+			p, err := poll.ImportJSON(b)
+			require.NoError(t, err)
+			assert.Equal(t, test.Poll, p)
+		})
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 2})
+
+	b, err := p.ExportJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"format_version": 1,
+		"id": "1234567890abcdefghij",
+		"post_id": "postID1",
+		"created_at": 1234567890,
+		"creator": "userID1",
+		"question": "Question",
+		"answer_options": [
+			{"answer": "Answer 1", "voters": ["userID1", "userID2", "userID3"]},
+			{"answer": "Answer 2", "voters": ["userID4"]},
+			{"answer": "Answer 3", "voters": []}
+		],
+		"settings": {"anonymous": true, "progress": false, "public_add_option": false, "max_votes": 2}
+	}`, string(b))
+}
+
+func TestImportJSON(t *testing.T) {
+	t.Run("export with an additional field", func(t *testing.T) {
+		b := []byte(`{
+			"format_version": 1,
+			"id": "1234567890abcdefghij",
+			"post_id": "postID1",
+			"created_at": 1234567890,
+			"creator": "userID1",
+			"question": "Question",
+			"new_field": "some value",
+			"answer_options": [
+				{"answer": "Answer 1", "voters": ["userID1", "userID2", "userID3"], "new_field": true},
+				{"answer": "Answer 2", "voters": ["userID4"]},
+				{"answer": "Answer 3", "voters": []}
+			],
+			"settings": {"anonymous": false, "progress": false, "public_add_option": false, "max_votes": 1, "new_setting": 5}
+		}`)
+
+		p, err := poll.ImportJSON(b)
+		require.NoError(t, err)
+		assert.Equal(t, testutils.GetPollWithVotes(), p)
+	})
+	t.Run("export without max_votes", func(t *testing.T) {
+		b := []byte(`{
+			"format_version": 1,
+			"id": "1234567890abcdefghij",
+			"question": "Question",
+			"answer_options": [{"answer": "Answer 1"}, {"answer": "Answer 2"}]
+		}`)
+
+		p, err := poll.ImportJSON(b)
+		require.NoError(t, err)
+		assert.Equal(t, 1, p.Settings.MaxVotes)
+		assert.Equal(t, []string{}, p.Voters(0))
+	})
+	t.Run("export with an invalid image URL", func(t *testing.T) {
+		b := []byte(`{
+			"format_version": 1,
+			"id": "1234567890abcdefghij",
+			"question": "Question",
+			"answer_options": [
+				{"answer": "Answer 1", "image_url": "javascript:alert(1)"},
+				{"answer": "Answer 2", "image_url": "https://example.org/b.png"}
+			]
+		}`)
+
+		p, err := poll.ImportJSON(b)
+		require.NoError(t, err)
+		assert.Equal(t, "", p.AnswerOptions[0].ImageURL)
+		assert.Equal(t, "https://example.org/b.png", p.AnswerOptions[1].ImageURL)
+	})
+	t.Run("unsupported format version", func(t *testing.T) {
+		p, err := poll.ImportJSON([]byte(`{"format_version": 2, "id": "1234567890abcdefghij"}`))
+		assert.Error(t, err)
+		assert.Nil(t, p)
+	})
+	t.Run("missing id", func(t *testing.T) {
+		p, err := poll.ImportJSON([]byte(`{"format_version": 1, "question": "Question"}`))
+		assert.Error(t, err)
+		assert.Nil(t, p)
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		p, err := poll.ImportJSON([]byte(`{`))
+		assert.Error(t, err)
+		assert.Nil(t, p)
+	})
+}
+
+func TestResultsJSON(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		ExpectedVoters  int
+		ExpectedOptions []map[string]interface{}
+	}{
+		"public poll": {
+			Poll:           testutils.GetPollWithVotes(),
+			ExpectedVoters: 4,
+			ExpectedOptions: []map[string]interface{}{
+				{"answer": "Answer 1", "votes": float64(3), "voters": []interface{}{"userID1", "userID2", "userID3"}},
+				{"answer": "Answer 2", "votes": float64(1), "voters": []interface{}{"userID4"}},
+				{"answer": "Answer 3", "votes": float64(0)},
+			},
+		},
+		"anonymous poll": {
+			Poll:           testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1}),
+			ExpectedVoters: 4,
+			ExpectedOptions: []map[string]interface{}{
+				{"answer": "Answer 1", "votes": float64(3)},
+				{"answer": "Answer 2", "votes": float64(1)},
+				{"answer": "Answer 3", "votes": float64(0)},
+			},
+		},
+		"weighted poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Weights: "user4:3"})
+				p.Weights = map[string]int{"userID4": 3}
+				return p
+			}(),
+			ExpectedVoters: 4,
+			ExpectedOptions: []map[string]interface{}{
+				{"answer": "Answer 1", "votes": float64(3), "weight": float64(3), "voters": []interface{}{"userID1", "userID2", "userID3"}},
+				{"answer": "Answer 2", "votes": float64(1), "weight": float64(3), "voters": []interface{}{"userID4"}},
+				{"answer": "Answer 3", "votes": float64(0), "weight": float64(0)},
+			},
+		},
+		"running secret poll": {
+			Poll:           testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true, MaxVotes: 1}),
+			ExpectedVoters: 0,
+			ExpectedOptions: []map[string]interface{}{
+				{"answer": "Answer 1"},
+				{"answer": "Answer 2"},
+				{"answer": "Answer 3"},
+			},
+		},
+		"deleted option": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.AnswerOptions[2].Deleted = true
+				return p
+			}(),
+			ExpectedVoters: 4,
+			ExpectedOptions: []map[string]interface{}{
+				{"answer": "Answer 1", "votes": float64(3), "voters": []interface{}{"userID1", "userID2", "userID3"}},
+				{"answer": "Answer 2", "votes": float64(1), "voters": []interface{}{"userID4"}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := test.Poll.ResultsJSON()
+			require.NoError(t, err)
+
+			var results struct {
+				PollID  string                   `json:"poll_id"`
+				Voters  int                      `json:"voters"`
+				Options []map[string]interface{} `json:"options"`
+			}
+			require.NoError(t, json.Unmarshal(b, &results))
+			assert.Equal(t, testutils.GetPollID(), results.PollID)
+			assert.Equal(t, test.ExpectedVoters, results.Voters)
+			assert.Equal(t, test.ExpectedOptions, results.Options)
+		})
+	}
+}
+
+func TestResultsCSV(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll        *poll.Poll
+		ExpectedCSV string
+	}{
+		"poll with votes": {
+			Poll: testutils.GetPollWithVotes(),
+			ExpectedCSV: "User,Answer 1,Answer 2,Answer 3\n" +
+				"userID1,1,0,0\n" +
+				"userID2,1,0,0\n" +
+				"userID3,1,0,0\n" +
+				"userID4,0,1,0\n",
+		},
+		"poll without votes": {
+			Poll:        testutils.GetPoll(),
+			ExpectedCSV: "User,Answer 1,Answer 2,Answer 3\n",
+		},
+		"option containing a comma": {
+			Poll: &poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Yes, sure"},
+					{Answer: `No "way"`},
+				},
+				Ballots:     map[string][]int{"userID1": {0}},
+				BallotOrder: []string{"userID1"},
+				VoteCounts:  []int{1},
+				Settings:    poll.Settings{MaxVotes: 1},
+			},
+			ExpectedCSV: "User,\"Yes, sure\",\"No \"\"way\"\"\"\n" +
+				"userID1,1,0\n",
+		},
+		"anonymous poll": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1}),
+			ExpectedCSV: "Answer 1,Answer 2,Answer 3\n" +
+				"3,1,0\n",
+		},
+		"reveal-on-end poll, before end": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, RevealOnEnd: true, MaxVotes: 1}),
+			ExpectedCSV: "Answer 1,Answer 2,Answer 3\n" +
+				"3,1,0\n",
+		},
+		"reveal-on-end poll, after end": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, RevealOnEnd: true, MaxVotes: 1})
+				p.EndedAt = 1234567899
+				return p
+			}(),
+			ExpectedCSV: "User,Answer 1,Answer 2,Answer 3\n" +
+				"userID1,1,0,0\n" +
+				"userID2,1,0,0\n" +
+				"userID3,1,0,0\n" +
+				"userID4,0,1,0\n",
+		},
+		"secret poll, before end": {
+			Poll:        testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true, MaxVotes: 1}),
+			ExpectedCSV: "Answer 1,Answer 2,Answer 3\n",
+		},
+		"secret poll, after end": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true, MaxVotes: 1})
+				p.EndedAt = 1234567899
+				return p
+			}(),
+			ExpectedCSV: "User,Answer 1,Answer 2,Answer 3\n" +
+				"userID1,1,0,0\n" +
+				"userID2,1,0,0\n" +
+				"userID3,1,0,0\n" +
+				"userID4,0,1,0\n",
+		},
+		"multi vote poll": {
+			Poll: &poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"userID2": {0, 2}, "userID1": {0, 1}},
+				BallotOrder: []string{"userID2", "userID1"},
+				VoteCounts:  []int{2, 1, 1},
+				Settings:    poll.Settings{MaxVotes: 2},
+			},
+			ExpectedCSV: "User,Answer 1,Answer 2,Answer 3\n" +
+				"userID1,1,1,0\n" +
+				"userID2,1,0,1\n",
+		},
+		"deleted option": {
+			Poll: &poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2", Deleted: true},
+				},
+				Ballots:     map[string][]int{"userID1": {0}, "userID2": {1}},
+				BallotOrder: []string{"userID1", "userID2"},
+				VoteCounts:  []int{1, 1},
+				Settings:    poll.Settings{MaxVotes: 1},
+			},
+			ExpectedCSV: "User,Answer 1\n" +
+				"userID1,1\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := test.Poll.ResultsCSV()
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedCSV, string(b))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package poll
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// imageSeparator separates the text of an answer option from the URL of its image, e.g. "Blue|https://example.org/blue.png".
+const imageSeparator = "|"
+
+// markdownURLEscaper percent-encodes the characters that would end the URL of a markdown image early.
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// markdownTextEscaper escapes the characters that would end the text of a markdown link or image early.
+var markdownTextEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+var (
+	// markdownBlockPattern matches the start of a line that markdown renders as a block, e.g. a heading, a list, a quote,
+	// a table or a horizontal rule.
+	markdownBlockPattern = regexp.MustCompile("^(#|>|\\||[-+*]\\s|`{3}|~{3}|([-*_=]\\s*)+$)")
+	// markdownOrderedListPattern matches the start of a line that markdown renders as an ordered list, e.g. "1. ".
+	markdownOrderedListPattern = regexp.MustCompile(`^(\d+)([.)])`)
+)
+
+// splitImageURL splits the URL of an image from the text of an answer option given as "answer|URL".
+// The text is kept as is if it doesn't end with the URL of an image, so answer options may contain the separator.
+func splitImageURL(answer string) (string, string) {
+	i := strings.LastIndex(answer, imageSeparator)
+	if i == -1 {
+		return answer, ""
+	}
+	imageURL := strings.TrimSpace(answer[i+len(imageSeparator):])
+	if !isImageURL(imageURL) {
+		return answer, ""
+	}
+	return strings.TrimSpace(answer[:i]), imageURL
+}
+
+// InputText returns the text of the answer option the way it's given when adding it, i.e. followed by the URL
+// of its image, if any.
+func (o *AnswerOption) InputText() string {
+	if o.ImageURL == "" {
+		return o.Answer
+	}
+	return o.Answer + imageSeparator + o.ImageURL
+}
+
+// isImageURL returns true if s is an absolute http or https URL, which is the only kind of URL images are shown from.
+func isImageURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// imageMarkdown returns the markdown of an image with the given alternative text.
+// Both are escaped, so neither can end the image early and inject other markdown into the post.
+func imageMarkdown(text, imageURL string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return "![" + markdownTextEscaper.Replace(text) + "](" + markdownURLEscaper.Replace(imageURL) + ")"
+}
+
+// inlineMarkdown returns text as a single line of markdown. Inline markdown like emphasis, code or links is kept,
+// but a block like a heading or a list is escaped, so the text can't change the layout of the post around it.
+func inlineMarkdown(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if markdownBlockPattern.MatchString(text) {
+		return `\` + text
+	}
+	return markdownOrderedListPattern.ReplaceAllString(text, `$1\$2`)
+}
+
+// QuestionLinkText returns the question of the poll escaped for the text of a markdown link, e.g. in a list of polls,
+// so brackets in the question can't end the link early.
+func (p *Poll) QuestionLinkText() string {
+	return markdownTextEscaper.Replace(p.Question)
+}
+
+// makeImagesText returns the images of the answer options that have one as markdown text, each below the text of its answer option.
+// It returns an empty string if no answer option has an image.
+func (p *Poll) makeImagesText() string {
+	var lines []string
+	for _, o := range p.AnswerOptions {
+		if o.Deleted || o.ImageURL == "" {
+			continue
+		}
+		lines = append(lines, inlineMarkdown(o.Answer), imageMarkdown(o.Answer, o.ImageURL))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,83 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestNewPollImages(t *testing.T) {
+	for name, test := range map[string]struct {
+		Answer           string
+		ExpectedAnswer   string
+		ExpectedImageURL string
+	}{
+		"image":               {Answer: "Blue | https://example.org/blue.png", ExpectedAnswer: "Blue", ExpectedImageURL: "https://example.org/blue.png"},
+		"separator in answer": {Answer: "Red|Green|http://example.org/rg.png", ExpectedAnswer: "Red|Green", ExpectedImageURL: "http://example.org/rg.png"},
+		"no URL":              {Answer: "Red|Green", ExpectedAnswer: "Red|Green"},
+		"relative URL":        {Answer: "Red|/red.png", ExpectedAnswer: "Red|/red.png"},
+		"other scheme":        {Answer: "Red|javascript:alert(1)", ExpectedAnswer: "Red|javascript:alert(1)"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, errMsg := poll.NewPoll("userID1", "Question", []string{test.Answer, "Answer 2"}, poll.Settings{MaxVotes: 1})
+			require.Nil(t, errMsg)
+			assert.Equal(t, test.ExpectedAnswer, p.AnswerOptions[0].Answer)
+			assert.Equal(t, test.ExpectedImageURL, p.AnswerOptions[0].ImageURL)
+			assert.Equal(t, test.Answer != test.ExpectedAnswer, p.AnswerOptions[0].InputText() != p.AnswerOptions[0].Answer)
+		})
+	}
+	t.Run("same answer with different images", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", []string{"Blue|https://example.org/1.png", "Blue|https://example.org/2.png"}, poll.Settings{MaxVotes: 1})
+		assert.Nil(t, p)
+		assert.NotNil(t, errMsg)
+	})
+}
+
+func TestPollImagesToPosts(t *testing.T) {
+	p := testutils.GetPoll()
+	p.AnswerOptions[0].ImageURL = "https://example.org/a (1).png"
+	p.AnswerOptions[1].Answer = "# [Answer 2]"
+	p.AnswerOptions[1].ImageURL = "https://example.org/b.png"
+
+	t.Run("poll post", func(t *testing.T) {
+		attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+		assert.Equal(t, "Answer 1\n"+
+			"![Answer 1](https://example.org/a%20%281%29.png)\n"+
+			"\\# [Answer 2]\n"+
+			"![# \\[Answer 2\\]](https://example.org/b.png)\n"+
+			"---\n**Total votes**: 0", attachments[0].Text)
+	})
+	t.Run("end poll post", func(t *testing.T) {
+		converter := func(userID string) (string, *model.AppError) { return "@" + userID, nil }
+		post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+		require.Nil(t, err)
+		fields := post.Attachments()[0].Fields
+		assert.Contains(t, fields[0].Value, "![Answer 1](https://example.org/a%20%281%29.png)")
+		assert.NotContains(t, fields[2].Value, "![")
+	})
+}
+
+func TestPollUpdateImages(t *testing.T) {
+	p := testutils.GetPoll()
+	p.AnswerOptions[0].ImageURL = "https://example.org/a.png"
+
+	require.Nil(t, p.Update("Question", []string{p.AnswerOptions[0].InputText(), "Answer 2|https://example.org/b.png", "Answer 3"}))
+	assert.Equal(t, "https://example.org/a.png", p.AnswerOptions[0].ImageURL)
+	assert.Equal(t, "Answer 2", p.AnswerOptions[1].Answer)
+	assert.Equal(t, "https://example.org/b.png", p.AnswerOptions[1].ImageURL)
+
+	require.Nil(t, p.Update("Question", []string{"Answer 1", "Answer 2|https://example.org/b.png", "Answer 3"}))
+	assert.Equal(t, "", p.AnswerOptions[0].ImageURL)
+}
+
+func TestPollQuestionLinkText(t *testing.T) {
+	p := testutils.GetPoll()
+	p.Question = `Is [this](https://example.org) \ fine?`
+
+	assert.Equal(t, `Is \[this\](https://example.org) \\ fine?`, p.QuestionLinkText())
+}
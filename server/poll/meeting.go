@@ -0,0 +1,111 @@
+package poll
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// MeetingSlotLayout is the layout in which the slots of a meeting poll are shown. Slots are always in UTC.
+	MeetingSlotLayout = "Mon, 2006-01-02 15:04 UTC"
+	// MeetingDuration is the duration of the meeting in the invite of a meeting poll.
+	MeetingDuration = time.Hour
+
+	// icsTimeLayout is the layout of times in UTC in an iCalendar file.
+	icsTimeLayout = "20060102T150405Z"
+)
+
+// NewMeetingPoll creates a new poll to find a date for a meeting. Every slot must be a time in UTC using
+// EndTimeLayout. Users can vote for all slots that work for them. The poll must not exceed limits.
+func NewMeetingPoll(creator, question string, slots []string, settings Settings, limits Limits) (*Poll, *ErrorMessage) {
+	settings.Meeting = true
+	return NewPollWithLimits(creator, question, slots, settings, limits)
+}
+
+// parseSlot parses a slot of a meeting poll, given either using EndTimeLayout or MeetingSlotLayout.
+// It returns the time of the slot in milliseconds.
+func parseSlot(slot string) (int64, *ErrorMessage) {
+	for _, layout := range []string{EndTimeLayout, MeetingSlotLayout} {
+		if t, err := time.ParseInLocation(layout, slot, time.UTC); err == nil {
+			return t.UnixNano() / int64(time.Millisecond), nil
+		}
+	}
+	return 0, &ErrorMessage{
+		Message: &i18n.Message{
+			ID:    "poll.meeting.invalidSlot",
+			Other: `The option "{{.Slot}}" is no time in UTC like "2021-10-01T15:00".`,
+		},
+		Data: map[string]interface{}{
+			"Slot": slot,
+		},
+	}
+}
+
+// formatSlot formats the time of a slot, given in milliseconds, using MeetingSlotLayout.
+func formatSlot(millis int64) string {
+	return millisToTime(millis).Format(MeetingSlotLayout)
+}
+
+func millisToTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC()
+}
+
+// BestSlots returns the indexes of the slots of a meeting poll that got the most votes.
+// Deleted slots are ignored. It returns nil if the poll isn't a meeting poll or nobody has voted yet.
+func (p *Poll) BestSlots() []int {
+	if !p.Settings.Meeting {
+		return nil
+	}
+
+	var best []int
+	max := 0
+	for i, o := range p.AnswerOptions {
+		votes := p.VoteCount(i)
+		if o.Deleted || votes == 0 || votes < max {
+			continue
+		}
+		if votes > max {
+			max = votes
+			best = nil
+		}
+		best = append(best, i)
+	}
+	return best
+}
+
+// MeetingInvite returns an iCalendar file with an event for the slot at index, which lasts MeetingDuration.
+func (p *Poll) MeetingInvite(index int) []byte {
+	stamp := p.CreatedAt
+	if p.HasEnded() {
+		stamp = p.EndedAt
+	}
+	start := millisToTime(p.AnswerOptions[index].Time)
+
+	var b bytes.Buffer
+	for _, line := range []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Matterpoll//Matterpoll//EN",
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:%s-%d@matterpoll", p.ID, index),
+		"DTSTAMP:" + millisToTime(stamp).Format(icsTimeLayout),
+		"DTSTART:" + start.Format(icsTimeLayout),
+		"DTEND:" + start.Add(MeetingDuration).Format(icsTimeLayout),
+		"SUMMARY:" + escapeICSText(p.Question),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	} {
+		// iCalendar requires CRLF line endings
+		b.WriteString(line + "\r\n")
+	}
+	return b.Bytes()
+}
+
+// escapeICSText escapes a text value of an iCalendar file.
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
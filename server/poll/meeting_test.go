@@ -0,0 +1,162 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func TestNewMeetingPoll(t *testing.T) {
+	t.Run("slots are parsed", func(t *testing.T) {
+		p, errMsg := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "Sat, 2021-10-02 09:30 UTC"}, poll.Settings{MaxVotes: 1}, poll.Limits{})
+		require.Nil(t, errMsg)
+		require.NotNil(t, p)
+		require.Len(t, p.AnswerOptions, 2)
+		assert.Equal(t, "Fri, 2021-10-01 15:00 UTC", p.AnswerOptions[0].Answer)
+		assert.Equal(t, int64(1633100400000), p.AnswerOptions[0].Time)
+		assert.Equal(t, "Sat, 2021-10-02 09:30 UTC", p.AnswerOptions[1].Answer)
+		assert.Equal(t, int64(1633167000000), p.AnswerOptions[1].Time)
+		assert.True(t, p.Settings.Meeting)
+		assert.Equal(t, 2, p.Settings.MaxVotes)
+	})
+	t.Run("invalid slot", func(t *testing.T) {
+		p, errMsg := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "tomorrow"}, poll.Settings{MaxVotes: 1}, poll.Limits{})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.meeting.invalidSlot", errMsg.Message.ID)
+	})
+	t.Run("duplicate slot", func(t *testing.T) {
+		p, errMsg := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "Fri, 2021-10-01 15:00 UTC"}, poll.Settings{MaxVotes: 1}, poll.Limits{})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.duplicate", errMsg.Message.ID)
+	})
+	t.Run("conflicting setting", func(t *testing.T) {
+		p, errMsg := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "2021-10-02T15:00"}, poll.Settings{MaxVotes: 1, Ranked: true}, poll.Limits{})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.meetingConflict", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Setting": "ranked"}, errMsg.Data)
+	})
+	t.Run("invite without meeting", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1, Invite: true})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.inviteWithoutMeeting", errMsg.Message.ID)
+	})
+}
+
+func TestMeetingPollSlots(t *testing.T) {
+	newMeetingPoll := func() *poll.Poll {
+		p, errMsg := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "2021-10-02T15:00"}, poll.Settings{MaxVotes: 1}, poll.Limits{})
+		require.Nil(t, errMsg)
+		return p
+	}
+
+	t.Run("added slot can be voted for", func(t *testing.T) {
+		p := newMeetingPoll()
+		require.Nil(t, p.AddAnswerOption("2021-10-03T15:00"))
+		assert.Equal(t, "Sun, 2021-10-03 15:00 UTC", p.AnswerOptions[2].Answer)
+		assert.Equal(t, 3, p.Settings.MaxVotes)
+	})
+	t.Run("invalid added slot", func(t *testing.T) {
+		p := newMeetingPoll()
+		errMsg := p.AddAnswerOption("next week")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.meeting.invalidSlot", errMsg.Message.ID)
+		assert.Len(t, p.AnswerOptions, 2)
+	})
+	t.Run("slot can't be renamed", func(t *testing.T) {
+		p := newMeetingPoll()
+		errMsg := p.RenameAnswerOption("Fri, 2021-10-01 15:00 UTC", "Fri, 2021-10-01 16:00 UTC")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.meeting.slotChanged", errMsg.Message.ID)
+	})
+	t.Run("slot can't be changed when editing", func(t *testing.T) {
+		p := newMeetingPoll()
+		errMsg := p.Update("New question", []string{"Fri, 2021-10-01 16:00 UTC", "Sat, 2021-10-02 15:00 UTC"})
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.meeting.slotChanged", errMsg.Message.ID)
+		assert.Equal(t, "Question", p.Question)
+	})
+	t.Run("question can be changed when editing", func(t *testing.T) {
+		p := newMeetingPoll()
+		require.Nil(t, p.Update("New question", []string{"Fri, 2021-10-01 15:00 UTC", "Sat, 2021-10-02 15:00 UTC"}))
+		assert.Equal(t, "New question", p.Question)
+	})
+}
+
+func TestBestSlots(t *testing.T) {
+	for name, test := range map[string]struct {
+		Meeting  bool
+		Voters   [][]string
+		Deleted  int
+		Expected []int
+	}{
+		"single best slot": {
+			Meeting:  true,
+			Voters:   [][]string{{"userID1"}, {"userID1", "userID2"}, {"userID2"}},
+			Deleted:  -1,
+			Expected: []int{1},
+		},
+		"tie": {
+			Meeting:  true,
+			Voters:   [][]string{{"userID1", "userID2"}, {"userID1"}, {"userID2", "userID3"}},
+			Deleted:  -1,
+			Expected: []int{0, 2},
+		},
+		"deleted slot": {
+			Meeting:  true,
+			Voters:   [][]string{{"userID1"}, {"userID1", "userID2"}, {"userID2"}},
+			Deleted:  1,
+			Expected: []int{0, 2},
+		},
+		"no votes": {
+			Meeting:  true,
+			Voters:   [][]string{{}, {}, {}},
+			Deleted:  -1,
+			Expected: nil,
+		},
+		"no meeting poll": {
+			Meeting:  false,
+			Voters:   [][]string{{"userID1"}, {}, {}},
+			Deleted:  -1,
+			Expected: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &poll.Poll{Settings: poll.Settings{MaxVotes: 3, Meeting: test.Meeting}}
+			for i, voters := range test.Voters {
+				p.AnswerOptions = append(p.AnswerOptions, &poll.AnswerOption{
+					Answer:  "Answer",
+					Deleted: i == test.Deleted,
+				})
+				p.SetVoters(i, voters...)
+			}
+			assert.Equal(t, test.Expected, p.BestSlots())
+		})
+	}
+}
+
+func TestMeetingInvite(t *testing.T) {
+	p, errMsg := poll.NewMeetingPoll("userID1", "Team lunch; bring snacks, please", []string{"2021-10-01T15:00", "2021-10-02T15:00"}, poll.Settings{MaxVotes: 1}, poll.Limits{})
+	require.Nil(t, errMsg)
+	p.ID = "pollID1"
+	p.CreatedAt = 1633000000000
+	p.EndedAt = 1633050000000
+
+	assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:-//Matterpoll//Matterpoll//EN\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:pollID1-1@matterpoll\r\n"+
+		"DTSTAMP:20211001T010000Z\r\n"+
+		"DTSTART:20211002T150000Z\r\n"+
+		"DTEND:20211002T160000Z\r\n"+
+		"SUMMARY:Team lunch\\; bring snacks\\, please\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n", string(p.MeetingInvite(1)))
+}
@@ -7,15 +7,71 @@ type Metadata struct {
 	UserID                 string   `json:"user_id"`
 	CanManagePoll          bool     `json:"can_manage_poll"` // CanManagePoll will be true if the user with "UserID" can manage the poll with "PollID", otherwise false.
 	SettingPublicAddOption bool     `json:"setting_public_add_option"`
+	// OptionOrder contains the indexes of the answer options in the order they are shown to the user. It's nil if the order isn't shuffled.
+	OptionOrder []int `json:"option_order,omitempty"`
 }
 
 // ToMap returns a Metadata as a map
 func (m *Metadata) ToMap() map[string]interface{} {
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"voted_answers":             m.VotedAnswers,
 		"poll_id":                   m.PollID,
 		"user_id":                   m.UserID,
 		"can_manage_poll":           m.CanManagePoll,
 		"setting_public_add_option": m.SettingPublicAddOption,
 	}
+	if m.OptionOrder != nil {
+		result["option_order"] = m.OptionOrder
+	}
+	return result
+}
+
+// Results stores the aggregated results of a poll, that can be shown to everyone.
+type Results struct {
+	PollID  string   `json:"poll_id"`
+	Answers []string `json:"answers"`
+	Votes   []int    `json:"votes"`  // Votes is the number of votes for every answer option. It's nil if the votes are hidden.
+	Voters  int      `json:"voters"` // Voters is the number of users who voted. It's zero if the votes are hidden.
+	Ended   bool     `json:"ended"`
+}
+
+// ToMap returns Results as a map
+func (r *Results) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"poll_id": r.PollID,
+		"answers": r.Answers,
+		"votes":   r.Votes,
+		"voters":  r.Voters,
+		"ended":   r.Ended,
+	}
+}
+
+const (
+	// StatusRunning is the Status of a Summary of a poll that hasn't ended.
+	StatusRunning = "running"
+	// StatusEnded is the Status of a Summary of a poll that has ended.
+	StatusEnded = "ended"
+)
+
+// Summary describes a poll and its aggregated results for a user, e.g. to show them on a dashboard.
+type Summary struct {
+	PollID   string           `json:"poll_id"`
+	Question string           `json:"question"`
+	Options  []*OptionSummary `json:"options"`
+	// VotesHidden is true if the votes aren't shown yet. The votes of the answer options and Voters are zero then.
+	VotesHidden bool   `json:"votes_hidden"`
+	Voters      int    `json:"voters"`
+	Status      string `json:"status"`
+	CreatedAt   int64  `json:"created_at"`
+	EndTime     int64  `json:"end_time,omitempty"`
+	EndedAt     int64  `json:"ended_at,omitempty"`
+}
+
+// OptionSummary describes an answer option of a poll in a Summary.
+type OptionSummary struct {
+	Answer   string `json:"answer"`
+	ImageURL string `json:"image_url,omitempty"`
+	Votes    int    `json:"votes"`
+	// VoterIDs contains the IDs of the users who voted for the answer option. It's nil if the user may not see them.
+	VoterIDs []string `json:"voter_ids,omitempty"`
 }
@@ -25,3 +25,32 @@ func TestToMap(t *testing.T) {
 	}
 	assert.Equal(t, expectedMap, m.ToMap())
 }
+
+func TestToMapWithOptionOrder(t *testing.T) {
+	m := poll.Metadata{
+		PollID:      "pollID",
+		UserID:      "userID",
+		OptionOrder: []int{2, 0, 1},
+	}
+
+	assert.Equal(t, []int{2, 0, 1}, m.ToMap()["option_order"])
+}
+
+func TestResultsToMap(t *testing.T) {
+	r := poll.Results{
+		PollID:  "pollID",
+		Answers: []string{"Answer 1", "Answer 2"},
+		Votes:   []int{2, 1},
+		Voters:  3,
+		Ended:   true,
+	}
+
+	expectedMap := map[string]interface{}{
+		"poll_id": "pollID",
+		"answers": []string{"Answer 1", "Answer 2"},
+		"votes":   []int{2, 1},
+		"voters":  3,
+		"ended":   true,
+	}
+	assert.Equal(t, expectedMap, r.ToMap())
+}
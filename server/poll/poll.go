@@ -1,39 +1,219 @@
 package poll
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
 )
 
-var votesSettingPattern = regexp.MustCompile(`^votes=(\d+)$`)
+var (
+	votesSettingPattern    = regexp.MustCompile(`^votes=(\d+)$`)
+	quorumSettingPattern   = regexp.MustCompile(`^quorum=(\d+)(%?)$`)
+	endSettingPattern      = regexp.MustCompile(`^end=(.+)$`)
+	capacitySettingPattern = regexp.MustCompile(`^capacity=(\d+)$`)
+	scheduleSettingPattern = regexp.MustCompile(`^schedule=(.+)$`)
+	repeatSettingPattern   = regexp.MustCompile(`^repeat=(.+)$`)
+	votersSettingPattern   = regexp.MustCompile(`^voters=(.+)$`)
+	weightsSettingPattern  = regexp.MustCompile(`^weights=(.+)$`)
+	channelsSettingPattern = regexp.MustCompile(`^channels=(.+)$`)
+	inSettingPattern       = regexp.MustCompile(`^in=(.+)$`)
+	dmSettingPattern       = regexp.MustCompile(`^dm=(.+)$`)
+	localeSettingPattern   = regexp.MustCompile(`^locale=(.+)$`)
+	localeCodePattern      = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	chartSettingPattern    = regexp.MustCompile(`^chart=(.+)$`)
+	boardSettingPattern    = regexp.MustCompile(`^board=(.+)$`)
+	boardIDPattern         = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	weightEntryPattern     = regexp.MustCompile(`^@?([^@:\s]+):(\d+)$`)
+	attributeNamePattern   = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	quizSettingPattern     = regexp.MustCompile(`^quiz=(\d+)$`)
+	scaleSettingPattern    = regexp.MustCompile(`^scale=(\d+)-(\d+)$`)
+	remindSettingPattern   = regexp.MustCompile(`^remind=(.+)$`)
+
+	requireCommentSettingPattern = regexp.MustCompile(`^require-comment=(.+)$`)
+)
+
+const (
+	// MaxQuestionLength is the maximum number of characters of a question, unless Limits say otherwise.
+	MaxQuestionLength = 300
+	// MinAnswerOptions is the minimum number of answer options a new poll must have.
+	MinAnswerOptions = 2
+	// MaxAnswerOptions is the maximum number of answer options a poll may have, unless Limits say otherwise.
+	MaxAnswerOptions = 20
+	// MaxAnswerLength is the maximum number of characters of an answer option, unless Limits say otherwise.
+	// Longer answer options don't fit on the buttons of the poll.
+	MaxAnswerLength = 150
+	// EndTimeLayout is the layout of absolute end times, which are interpreted as UTC.
+	EndTimeLayout = "2006-01-02T15:04"
+	// UnlimitedVotes is the MaxVotes of polls that let users vote for as many answer options as they like.
+	// It's given as "--votes=0" or "--multi".
+	UnlimitedVotes = -1
+)
+
+// Intervals in which a scheduled poll can be repeated, see Settings.Repeat.
+const (
+	RepeatDaily   = "daily"
+	RepeatWeekly  = "weekly"
+	RepeatMonthly = "monthly"
+)
+
+// AbstainAnswer is the text of the abstain option until it gets localized, see LocalizeAbstainOption.
+const AbstainAnswer = "Abstain"
+
+// VotersChannel is the value of Settings.Voters that only allows members of the channel of a poll to vote.
+const VotersChannel = "channel"
 
 const (
 	SettingKeyAnonymous       = "anonymous"
 	SettingKeyProgress        = "progress"
 	SettingKeyPublicAddOption = "public-add-option"
+	SettingKeyCloseOnQuorum   = "close-on-quorum"
+	SettingKeyHoldForQuorum   = "hold-for-quorum"
+	SettingKeyRevealOnEnd     = "reveal-on-end"
+	SettingKeySemiAnonymous   = "semi-anonymous"
+	SettingKeyRanked          = "ranked"
+	SettingKeySecret          = "secret"
+	SettingKeyInvite          = "invite"
+	SettingKeyReactions       = "reactions"
+	SettingKeyAllowOther      = "allow-other"
+	SettingKeyApproveOptions  = "approve-options"
+	SettingKeyShuffle         = "shuffle"
+	SettingKeyThreadResults   = "thread-results"
+	SettingKeyAbstain         = "abstain"
+	SettingKeyComments        = "comments"
+
+	settingKeyVotes    = "votes"
+	settingKeyMulti    = "multi"
+	settingKeyQuorum   = "quorum"
+	settingKeyEnd      = "end"
+	settingKeyCapacity = "capacity"
+	settingKeySchedule = "schedule"
+	settingKeyRepeat   = "repeat"
+	settingKeyVoters   = "voters"
+	settingKeyWeights  = "weights"
+	settingKeyChannels = "channels"
+	settingKeyIn       = "in"
+	settingKeyDM       = "dm"
+	settingKeyLocale   = "locale"
+	settingKeyChart    = "chart"
+	settingKeyBoard    = "board"
+	settingKeyQuiz     = "quiz"
+	settingKeyScale    = "scale"
+	settingKeyRemind   = "remind"
+
+	settingKeyRequireComment = "require-comment"
 )
 
+// ResultsCallback is an endpoint of another plugin, which is called via the inter-plugin API.
+type ResultsCallback struct {
+	PluginID string `json:"plugin_id"`
+	// Path is the path of the endpoint within the plugin. It starts with a slash.
+	Path string `json:"path"`
+}
+
+// BroadcastPost is a copy of the post of a poll in another channel, see Settings.Channels.
+type BroadcastPost struct {
+	ChannelID string `json:"channel_id"`
+	// PostID is empty until the poll is posted.
+	PostID string `json:"post_id,omitempty"`
+}
+
 // Poll stores all needed information for a poll
 type Poll struct {
-	ID            string
-	PostID        string `json:"post_id,omitempty"`
+	ID     string
+	PostID string `json:"post_id,omitempty"`
+	// ChannelID and RootID are the channel and the thread the post of the poll was created in.
+	// They're empty for polls posted before they were stored, which have to look up the post instead.
+	ChannelID     string `json:"channel_id,omitempty"`
+	RootID        string `json:"root_id,omitempty"`
 	CreatedAt     int64
 	Creator       string
 	Question      string
 	AnswerOptions []*AnswerOption
 	Settings      Settings
+	// AllowedVoters contains the IDs of all users that may vote. If empty, everyone may vote.
+	AllowedVoters []string `json:"allowed_voters,omitempty"`
+	// EndedAt is the time the poll was closed in milliseconds. Zero means the poll is still running.
+	EndedAt int64 `json:"ended_at,omitempty"`
+	// Version is increased by every method that modifies the poll. It can be used to detect concurrent updates.
+	Version int `json:"version,omitempty"`
+	// ModifiedAt is the time of the last modification in milliseconds.
+	ModifiedAt int64 `json:"modified_at,omitempty"`
+	// Ballots contains the indexes of the answer options every user voted for in the order they voted for them,
+	// keyed by voter ID, see voterID. Users who haven't voted have no entry.
+	Ballots map[string][]int `json:"ballots,omitempty"`
+	// BallotOrder contains the voter IDs of Ballots in the order the users first voted. It keeps the voters
+	// of an answer option in a stable order, see Voters.
+	BallotOrder []string `json:"ballot_order,omitempty"`
+	// VoteCounts contains the number of votes of every answer option by index, see VoteCount.
+	// The last answer options without votes are left out.
+	VoteCounts []int `json:"vote_counts,omitempty"`
+	// Rankings contains the indexes of the answer options ranked by every user in order of preference, keyed by user ID.
+	// It's only used by ranked polls.
+	Rankings map[string][]int `json:"rankings,omitempty"`
+	// Weights contains the weight of the votes of every user, keyed by user ID, see VoteWeight.
+	// Users without an entry have a weight of one.
+	Weights map[string]int `json:"weights,omitempty"`
+	// RemindedAt is the time the automatic reminder was sent in milliseconds. Zero means it wasn't sent yet.
+	RemindedAt int64 `json:"reminded_at,omitempty"`
+	// SchemaVersion is the version of the format the poll was stored in. Zero means it was stored before versioning
+	// was introduced. Older polls are migrated when they are decoded, see CurrentSchemaVersion.
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// PendingOptions contains the answer options users suggested, which wait for the approval of the creator,
+	// see Settings.ApproveOptions.
+	PendingOptions []*PendingOption `json:"pending_options,omitempty"`
+	// Votes is the vote log of the poll. It contains every vote ever cast, including the ones
+	// that were removed later, in the order they were cast. Polls stored before it was introduced
+	// have no log of their older votes.
+	Votes []*Vote `json:"votes,omitempty"`
+	// AddedOptions contains the number of answer options every user added or suggested, keyed by voter ID,
+	// see RecordAddedOption.
+	AddedOptions map[string]int `json:"added_options,omitempty"`
+	// ResultsCallback is the endpoint of another plugin that receives the results once the poll has ended.
+	// It's nil if no plugin subscribed to them.
+	ResultsCallback *ResultsCallback `json:"results_callback,omitempty"`
+	// Broadcasts are the copies of the post of the poll in other channels. They share the votes of the poll.
+	Broadcasts []*BroadcastPost `json:"broadcasts,omitempty"`
+	// Limits are the limits the poll was created with. They also apply to answer options added later
+	// and to edits of the poll. Nil means the default limits.
+	Limits *Limits `json:"limits,omitempty"`
+	// Comments are the comments the voters gave for their votes in the order they were written, see Settings.Comments.
+	Comments []*Comment `json:"comments,omitempty"`
+
+	// voterKey is the secret key the voters of anonymous polls are hashed with, see SetVoterKey. It's never stored.
+	voterKey string
+	// barStyle is the style of the bars in the results, see SetBarStyle. It's never stored.
+	barStyle string
 }
 
-// AnswerOption stores a possible answer and a list of user who voted for this
+// AnswerOption stores a possible answer. The votes for it are stored in Poll.Ballots.
 type AnswerOption struct {
 	Answer string
-	Voter  []string
+	// LegacyVoters are the voters of polls stored before schema version 2. They're moved to Poll.Ballots
+	// when the poll is decoded.
+	LegacyVoters []string `json:"Voter,omitempty"`
+	// Deleted hides the answer option without removing its votes.
+	Deleted bool `json:"deleted,omitempty"`
+	// Time is the start of the slot in milliseconds, if the answer option is a slot of a meeting poll.
+	Time int64 `json:"time,omitempty"`
+	// AddedBy is the user who wrote in the answer option, see AddOtherAnswer. It's empty for options
+	// added by the creator and for anonymous polls.
+	AddedBy string `json:"added_by,omitempty"`
+	// Abstain marks the built-in abstain option of Settings.Abstain. Its votes are shown, but they aren't
+	// counted in the percentages and the quorum.
+	Abstain bool `json:"abstain,omitempty"`
+	// ImageURL is the URL of an image that shows the answer option, given as "answer|URL" when the option is added.
+	ImageURL string `json:"image_url,omitempty"`
 }
 
 // Settings stores possible settings for a poll
@@ -41,32 +221,169 @@ type Settings struct {
 	Anonymous       bool
 	Progress        bool
 	PublicAddOption bool
-	MaxVotes        int `json:"max_votes"`
+	// CloseOnQuorum closes the poll as soon as the quorum is reached.
+	CloseOnQuorum bool `json:"close_on_quorum,omitempty"`
+	// HoldForQuorum prevents the poll from being ended before the quorum is reached, see EndPrevented.
+	// The poll still ends at its EndTime.
+	HoldForQuorum bool `json:"hold_for_quorum,omitempty"`
+	// RevealOnEnd shows the voters of an anonymous poll once it has ended.
+	// This relies on the plain user IDs being stored as voters, so it can't be combined with a
+	// setting that stores voters in a non-reversible form.
+	RevealOnEnd bool `json:"reveal_on_end,omitempty"`
+	// SemiAnonymous lets the creator of an anonymous poll see who voted for what, see ShowsVotersTo.
+	// Like RevealOnEnd, it relies on the plain user IDs being stored as voters.
+	SemiAnonymous bool `json:"semi_anonymous,omitempty"`
+	// Ranked lets users rank the answer options in the order they vote for them.
+	// The winner is determined by an instant-runoff tabulation, see InstantRunoff.
+	Ranked bool `json:"ranked,omitempty"`
+	// Secret hides the number of votes and the voters of every answer option from everyone until the poll has ended.
+	Secret bool `json:"secret,omitempty"`
+	// MaxVotes is the number of answer options every user may vote for, or UnlimitedVotes.
+	MaxVotes int `json:"max_votes"`
+	// Quorum is the number of distinct voters required for the poll to be valid. Zero means no quorum.
+	Quorum int `json:"quorum,omitempty"`
+	// QuorumPercent is the share of the users who may vote in the channel that is required for the poll to be valid.
+	// The poll doesn't know the members of its channel, hence the plugin resolves it into Quorum when the poll
+	// gets created, see SetQuorumFromEligible. Zero means the quorum is an absolute number.
+	QuorumPercent int `json:"quorum_percent,omitempty"`
+	// EndTime is the time in milliseconds at which the poll gets ended automatically. Zero means the poll has no deadline.
+	EndTime int64 `json:"end_time,omitempty"`
+	// Capacity is the maximum number of voters of every answer option. Zero means no limit.
+	Capacity int `json:"capacity,omitempty"`
+	// ScheduledAt is the time in milliseconds at which the poll gets posted. Zero means the poll is posted right away.
+	ScheduledAt int64 `json:"scheduled_at,omitempty"`
+	// Repeat is the interval in which a scheduled poll is posted again, e.g. RepeatWeekly. Empty means the poll is posted once.
+	Repeat string `json:"repeat,omitempty"`
+	// Voters restricts who may vote. It's either VotersChannel or a comma separated list of usernames.
+	// The poll doesn't know the members of its channel or the IDs of the users, hence the plugin enforces
+	// VotersChannel and resolves the usernames into AllowedVoters when the poll gets created.
+	Voters string `json:"voters,omitempty"`
+	// Weights is a comma separated list of usernames with the weight of their votes, e.g. "user1:3,user2:1".
+	// The plugin resolves the usernames into Poll.Weights when the poll gets created.
+	Weights string `json:"weights,omitempty"`
+	// Channels is a comma separated list of the names of other channels of the team the poll gets posted in as well,
+	// e.g. "town-square,dev". The plugin resolves them into Poll.Broadcasts when the poll gets created.
+	Channels string `json:"channels,omitempty"`
+	// In is the name of the channel of the team the poll gets posted in instead of the channel it was created in.
+	// The plugin resolves it when the poll gets created.
+	In string `json:"in,omitempty"`
+	// DM is a comma separated list of usernames. The poll gets posted in the direct or group message of
+	// the creator with these users instead of the channel it was created in.
+	DM string `json:"dm,omitempty"`
+	// Locale is the language the poll post and its results are shown in for everyone, e.g. "de".
+	// If it's empty, the default client locale of the server is used.
+	Locale string `json:"locale,omitempty"`
+	// Chart is the type of the chart image of the results that is attached to the announcement of the end of the poll,
+	// e.g. ChartBar. Empty means no chart is attached.
+	Chart string `json:"chart,omitempty"`
+	// Board is the ID of a board of the Boards plugin. A card with the results is added to it when the poll ends.
+	Board string `json:"board,omitempty"`
+	// WeightAttribute is the name of the user attribute that contains the weight of the votes of a user.
+	// The plugin reads it into Poll.Weights when the poll ends. Only one of Weights and WeightAttribute is set.
+	WeightAttribute string `json:"weight_attribute,omitempty"`
+	// Quiz is the number of the correct answer option, starting at one. It's revealed when the poll ends.
+	// Zero means the poll isn't a quiz.
+	Quiz int `json:"quiz,omitempty"`
+	// ScaleMin and ScaleMax are the lowest and highest number of a rating scale. The answer options of a poll
+	// with a scale are all numbers in between, see IsScale.
+	ScaleMin int `json:"scale_min,omitempty"`
+	ScaleMax int `json:"scale_max,omitempty"`
+	// Meeting turns the answer options into slots for a meeting, see NewMeetingPoll.
+	Meeting bool `json:"meeting,omitempty"`
+	// Invite sends an invite for the best slot of a meeting poll when it ends.
+	Invite bool `json:"invite,omitempty"`
+	// Remind is the time in milliseconds before EndTime at which the users who haven't voted yet get reminded.
+	// Zero means there is no automatic reminder.
+	Remind int64 `json:"remind,omitempty"`
+	// Reactions lets users vote by reacting to the poll post with the numbered emoji of an answer option.
+	// Reactions show who reacted, hence it can't be combined with settings that hide the voters.
+	Reactions bool `json:"reactions,omitempty"`
+	// AllowOther lets users vote for an answer of their own, which gets added as a new answer option, see AddOtherAnswer.
+	AllowOther bool `json:"allow_other,omitempty"`
+	// ApproveOptions makes answer options added by other users than the creator pending until the creator approves them,
+	// see SuggestAnswerOption.
+	ApproveOptions bool `json:"approve_options,omitempty"`
+	// Shuffle shows the answer options in a different order to every user to avoid a bias towards the first ones,
+	// see OptionOrder. The votes are still stored in the original order.
+	Shuffle bool `json:"shuffle,omitempty"`
+	// ThreadResults posts the results as a pinned reply in the thread of the poll when it ends,
+	// so they don't get buried by the following conversation.
+	ThreadResults bool `json:"thread_results,omitempty"`
+	// Abstain adds an abstain option after the answer options of the poll when it gets created.
+	Abstain bool `json:"abstain,omitempty"`
+	// Comments lets voters explain their vote with a short comment, see SetComment.
+	// The comments are listed in the results when the poll ends.
+	Comments bool `json:"comments,omitempty"`
+	// RequireComment are the numbers of the answer options, starting at one, whose voters must explain their vote
+	// with a comment, see VoteWithComment. It needs Comments.
+	RequireComment []int `json:"require_comment,omitempty"`
 }
 
-// ErrorMessage contains error messsage for a user that can be localized.
-// It should not be wrapped and instead always returned.
-type ErrorMessage struct {
-	Message *i18n.Message
-	Data    map[string]interface{}
+// AnswerOptionError describes why the answer option at Index could not be added.
+type AnswerOptionError struct {
+	Index        int
+	ErrorMessage *ErrorMessage
 }
 
 // NewPoll creates a new poll with the given parameter.
+// The answer options of a poll with a scale are generated, hence answerOptions must be empty in this case.
 func NewPoll(creator, question string, answerOptions []string, settings Settings) (*Poll, *ErrorMessage) {
+	return NewPollWithLimits(creator, question, answerOptions, settings, Limits{})
+}
+
+// NewPollWithLimits creates a new poll with the given parameter, whose question and number of answer options must not exceed limits.
+func NewPollWithLimits(creator, question string, answerOptions []string, settings Settings, limits Limits) (*Poll, *ErrorMessage) {
+	if errMsg := limits.validateQuestion(question); errMsg != nil {
+		return nil, errMsg
+	}
+	if errMsg := settings.ValidateCombination(); errMsg != nil {
+		return nil, errMsg
+	}
+	if settings.IsScale() {
+		if len(answerOptions) > 0 {
+			return nil, &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.newPoll.scaleSettings.answerOptions",
+					Other: "A poll with a scale gets its options from the scale, so no options must be given.",
+				},
+			}
+		}
+		answerOptions = settings.scaleOptions()
+	}
+
 	p := Poll{
-		ID:        model.NewId(),
-		CreatedAt: model.GetMillis(),
-		Creator:   creator,
-		Question:  question,
-		Settings:  settings,
+		ID:            model.NewId(),
+		CreatedAt:     model.GetMillis(),
+		Creator:       creator,
+		Question:      question,
+		Settings:      settings,
+		SchemaVersion: CurrentSchemaVersion,
 	}
-	for _, answerOption := range answerOptions {
-		if errMsg := p.AddAnswerOption(answerOption); errMsg != nil {
+	if limits != (Limits{}) {
+		p.Limits = &limits
+	}
+	// The number of answer options is checked upfront, so the error tells how many were given
+	count := len(answerOptions)
+	if settings.Abstain {
+		count++
+	}
+	if errMsg := limits.validateAnswerOptionCount(count); errMsg != nil {
+		return nil, errMsg
+	}
+	if errs := p.AddAnswerOptions(answerOptions); len(errs) > 0 {
+		return nil, errs[0].ErrorMessage
+	}
+	if settings.Abstain {
+		if errMsg := p.validateAnswerOption(AbstainAnswer, -1); errMsg != nil {
 			return nil, errMsg
 		}
+		p.AnswerOptions = append(p.AnswerOptions, &AnswerOption{Answer: AbstainAnswer, Abstain: true})
 	}
+	// A new poll always starts with the initial version
+	p.Version = 0
+	p.ModifiedAt = p.CreatedAt
 
-	if errMsg := p.validate(); errMsg != nil {
+	if errMsg := p.Validate(); errMsg != nil {
 		return nil, errMsg
 	}
 
@@ -74,59 +391,185 @@ func NewPoll(creator, question string, answerOptions []string, settings Settings
 }
 
 // NewSettingsFromStrings creates a new settings with the given parameter.
+// Aliases of setting keywords are resolved before the settings are parsed.
 func NewSettingsFromStrings(strs []string) (Settings, *ErrorMessage) {
-	settings := Settings{MaxVotes: 1}
+	return NewSettingsFromStringsWithDefaults(strs, Settings{})
+}
+
+// NewSettingsFromStringsWithDefaults creates a new settings that starts from defaults and applies the given parameter on top.
+// Settings without a value can be turned off by prefixing them with "no-". A MaxVotes default of zero is treated as one.
+// Defaults that conflict with the given settings are dropped, e.g. a default number of votes for a ranked poll.
+func NewSettingsFromStringsWithDefaults(strs []string, defaults Settings) (Settings, *ErrorMessage) {
+	settings := defaults
+	if settings.MaxVotes == 0 || settings.MaxVotes < UnlimitedVotes {
+		settings.MaxVotes = 1
+	}
 	for _, str := range strs {
-		switch {
-		case str == SettingKeyAnonymous:
-			settings.Anonymous = true
-		case str == SettingKeyProgress:
-			settings.Progress = true
-		case str == SettingKeyPublicAddOption:
-			settings.PublicAddOption = true
-		case votesSettingPattern.MatchString(str):
-			i, errMsg := parseVotesSettings(str)
-			if errMsg != nil {
-				return settings, errMsg
+		keyword, value, hasValue := resolveSettingAlias(str)
+		if !hasValue {
+			enabled := true
+			if strings.HasPrefix(keyword, negatedSettingPrefix) {
+				keyword, _, _ = resolveSettingAlias(strings.TrimPrefix(keyword, negatedSettingPrefix))
+				enabled = false
 			}
-			settings.MaxVotes = i
-		default:
-			return settings, &ErrorMessage{
-				Message: &i18n.Message{
-					ID:    "poll.newPoll.unrecognizedSetting",
-					Other: "Unrecognized poll setting: {{.Setting}}",
-				},
-				Data: map[string]interface{}{
-					"Setting": str,
-				},
+			if d := lookupSetting(keyword); d != nil && !d.HasValue() {
+				d.setEnabled(&settings, enabled)
+				continue
 			}
 		}
+		if d := lookupSetting(keyword); d != nil && d.HasValue() && hasValue {
+			canonical := keyword + "=" + value
+			if d.pattern.MatchString(canonical) {
+				if errMsg := d.parse(&settings, canonical); errMsg != nil {
+					return settings, errMsg
+				}
+				continue
+			}
+		}
+
+		return settings, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.unrecognizedSetting",
+				Other: "Unrecognized poll setting: {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": str,
+			},
+		}
+	}
+
+	if !givesSetting(strs, settingKeyVotes) && (settings.Ranked || settings.Quiz > 0 || settings.IsScale()) {
+		settings.MaxVotes = 1
+	}
+	if !givesSetting(strs, SettingKeyProgress) && settings.Secret {
+		settings.Progress = false
 	}
 	return settings, nil
 }
 
+// givesSetting returns true if strs contain the setting with the canonical keyword, either turned on or off.
+func givesSetting(strs []string, keyword string) bool {
+	for _, str := range strs {
+		k, _, _ := resolveSettingAlias(str)
+		if k == keyword || settingAliases[k] == keyword {
+			return true
+		}
+		if k, _, _ = resolveSettingAlias(strings.TrimPrefix(k, negatedSettingPrefix)); k == keyword || settingAliases[k] == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSettingAlias splits a setting into its keyword and value and replaces the keyword
+// with the canonical one, if it's an alias. Aliases that are settings without a value on their own,
+// like "multi", are only replaced if a value is given.
+func resolveSettingAlias(str string) (keyword, value string, hasValue bool) {
+	keyword = str
+	if i := strings.Index(str, "="); i != -1 {
+		keyword, value, hasValue = str[:i], str[i+1:], true
+	}
+	if d := lookupSetting(keyword); d != nil && !d.HasValue() && !hasValue {
+		return keyword, value, hasValue
+	}
+	if canonical, ok := settingAliases[keyword]; ok {
+		keyword = canonical
+	}
+	return keyword, value, hasValue
+}
+
+// ValidateCombination checks that the settings don't contradict each other.
+// Every used setting is checked in the order of settingDefinitions, followed by the restrictions of meeting polls.
+func (s Settings) ValidateCombination() *ErrorMessage {
+	for _, d := range settingDefinitions {
+		if d.validate == nil || !d.isUsed(s) {
+			continue
+		}
+		if errMsg := d.validate(s); errMsg != nil {
+			return errMsg
+		}
+	}
+	if s.Meeting {
+		// Users vote for all slots that work for them, hence only settings that work with multiple votes are allowed
+		for _, conflict := range []struct {
+			setting string
+			used    bool
+		}{
+			{SettingKeyRanked, s.Ranked},
+			{settingKeyQuiz + "=X", s.Quiz > 0},
+			{settingKeyScale + "=X", s.IsScale()},
+			{SettingKeyAllowOther, s.AllowOther},
+			// The slots are sorted by time
+			{SettingKeyShuffle, s.Shuffle},
+		} {
+			if conflict.used {
+				return &ErrorMessage{
+					Message: &i18n.Message{
+						ID:    "poll.newPoll.settings.meetingConflict",
+						Other: `The setting "{{.Setting}}" can't be used in meeting polls.`,
+					},
+					Data: map[string]interface{}{
+						"Setting": conflict.setting,
+					},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func newMissingSettingDependencyError(setting, dependency string) *ErrorMessage {
+	return &ErrorMessage{
+		Message: &i18n.Message{
+			ID:    "poll.newPoll.settings.missingDependency",
+			Other: `The setting "{{.Setting}}" can only be used together with "{{.Dependency}}".`,
+		},
+		Data: map[string]interface{}{
+			"Setting":    setting,
+			"Dependency": dependency,
+		},
+	}
+}
+
+func newConflictingSettingsError(setting, conflict string) *ErrorMessage {
+	return &ErrorMessage{
+		Message: &i18n.Message{
+			ID:    "poll.newPoll.settings.conflict",
+			Other: `The settings "{{.Setting}}" and "{{.Conflict}}" can't be used together.`,
+		},
+		Data: map[string]interface{}{
+			"Setting":  setting,
+			"Conflict": conflict,
+		},
+	}
+}
+
+// NewPollWithDefaults creates a new poll with settings parsed from strings, which are applied on top of defaults.
+// Explicitly given settings take precedence over the defaults. The poll must not exceed limits.
+func NewPollWithDefaults(creator, question string, answerOptions, settings []string, defaults Settings, limits Limits) (*Poll, *ErrorMessage) {
+	s, errMsg := NewSettingsFromStringsWithDefaults(settings, defaults)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	return NewPollWithLimits(creator, question, answerOptions, s, limits)
+}
+
 // NewSettingsFromSubmission creates a new settings with the given parameter.
+// The number of votes and all settings that are offered in the create poll dialog are read from the submission.
 func NewSettingsFromSubmission(submission map[string]interface{}) Settings {
 	settings := Settings{MaxVotes: 1}
-	for k, v := range submission {
-		if k == "setting-multi" {
-			f, ok := v.(float64)
-			if ok {
-				settings.MaxVotes = int(f)
-			}
-		} else if strings.HasPrefix(k, "setting-") {
-			b, ok := v.(bool)
-			if b && ok {
-				s := strings.TrimPrefix(k, "setting-")
-				switch s {
-				case SettingKeyAnonymous:
-					settings.Anonymous = true
-				case SettingKeyProgress:
-					settings.Progress = true
-				case SettingKeyPublicAddOption:
-					settings.PublicAddOption = true
-				}
-			}
+	if f, ok := submission[SubmissionSettingPrefix+settingKeyMulti].(float64); ok {
+		settings.MaxVotes = int(f)
+		if settings.MaxVotes == 0 {
+			settings.MaxVotes = UnlimitedVotes
+		}
+	}
+	for _, d := range settingDefinitions {
+		if d.DialogName == nil {
+			continue
+		}
+		if b, ok := submission[SubmissionSettingPrefix+d.Key].(bool); ok && b {
+			d.setEnabled(&settings, true)
 		}
 	}
 	return settings
@@ -158,182 +601,1853 @@ func parseVotesSettings(s string) (int, *ErrorMessage) {
 			},
 		}
 	}
+	if i == 0 {
+		return UnlimitedVotes, nil
+	}
 	return i, nil
 }
 
-// validate checks if poll is valid
-func (p *Poll) validate() *ErrorMessage {
-	if p.Settings.MaxVotes <= 0 || p.Settings.MaxVotes > len(p.AnswerOptions) {
-		return &ErrorMessage{
+// parseQuorumSettings parses setting for quorum ("--quorum=X" or "--quorum=X%").
+// It returns the quorum and true, if it's a percentage.
+func parseQuorumSettings(s string) (int, bool, *ErrorMessage) {
+	e := quorumSettingPattern.FindStringSubmatch(s)
+	if len(e) != 3 {
+		return 0, false, &ErrorMessage{
 			Message: &i18n.Message{
-				ID:    "poll.newPoll.votesettings.invalidSetting",
-				Other: `The number of votes must be a positive number and less than or equal to the number of options. You specified "{{.MaxVotes}}", but the number of options is "{{.Options}}".`,
+				ID:    "poll.newPoll.quorumSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
 			},
 			Data: map[string]interface{}{
-				"MaxVotes": p.Settings.MaxVotes,
-				"Options":  len(p.AnswerOptions),
+				"Setting": s,
 			},
 		}
 	}
-	return nil
-}
-
-// IsMultiVote return true if poll is set to multi vote
-func (p *Poll) IsMultiVote() bool {
-	return p.Settings.MaxVotes > 1
-}
-
-// AddAnswerOption adds a new AnswerOption to a poll
-func (p *Poll) AddAnswerOption(newAnswerOption string) *ErrorMessage {
-	newAnswerOption = strings.TrimSpace(newAnswerOption)
-	if newAnswerOption == "" {
-		return &ErrorMessage{
+	percent := e[2] == "%"
+	i, err := strconv.Atoi(e[1])
+	if err != nil || i <= 0 {
+		return 0, false, &ErrorMessage{
 			Message: &i18n.Message{
-				ID:    "poll.addAnswerOption.empty",
-				Other: "Empty option not allowed",
+				ID:    "poll.newPoll.quorumSettings.invalidSetting",
+				Other: `The quorum must be a positive number. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
 			},
 		}
 	}
-	for _, answerOption := range p.AnswerOptions {
-		if answerOption.Answer == newAnswerOption {
-			return &ErrorMessage{
-				Message: &i18n.Message{
-					ID:    "poll.addAnswerOption.duplicate",
-					Other: "Duplicate option: {{.Option}}",
-				},
-				Data: map[string]interface{}{
-					"Option": newAnswerOption,
-				},
-			}
+	if percent && i > 100 {
+		return 0, false, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.quorumSettings.invalidPercentage",
+				Other: `The quorum can be at most 100%. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
 		}
 	}
-	ao := &AnswerOption{
-		Answer: newAnswerOption,
-		Voter:  []string{},
-	}
-	p.AnswerOptions = append(p.AnswerOptions, ao)
-	return nil
+	return i, percent, nil
 }
 
-// UpdateVote performs a vote for a given user
-func (p *Poll) UpdateVote(userID string, index int) (*i18n.Message, error) {
-	if len(p.AnswerOptions) <= index || index < 0 {
-		return nil, fmt.Errorf("invalid index")
+// parseCapacitySettings parses setting for the capacity of answer options ("--capacity=X")
+func parseCapacitySettings(s string) (int, *ErrorMessage) {
+	e := capacitySettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.capacitySettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
 	}
-	if userID == "" {
-		return nil, fmt.Errorf("invalid userID")
+	i, err := strconv.Atoi(e[1])
+	if err != nil || i <= 0 {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.capacitySettings.invalidSetting",
+				Other: `The capacity must be a positive number. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
 	}
+	return i, nil
+}
 
-	if p.IsMultiVote() {
-		// Multi Answer Mode
-		votedAnswers := p.GetVotedAnswers(userID)
-		for _, answer := range votedAnswers {
-			if answer == p.AnswerOptions[index].Answer {
-				return &i18n.Message{
-					ID:    "poll.updateVote.alreadyVoted",
-					Other: "You've already voted for this option.",
-				}, nil
-			}
-		}
-		if p.Settings.MaxVotes <= len(votedAnswers) {
-			return &i18n.Message{
-				ID:    "poll.updateVote.maxVotes",
-				Other: "You could't vote for this option, because you don't have any votes left. Use the reset button to reset your votes.",
-			}, nil
-		}
-	} else {
-		// Single Answer Mode
-		for _, o := range p.AnswerOptions {
-			for i := 0; i < len(o.Voter); i++ {
-				if userID == o.Voter[i] {
-					o.Voter = append(o.Voter[:i], o.Voter[i+1:]...)
-				}
-			}
+// parseEndSettings parses setting for the end of a poll ("--end=X").
+// X is either a duration relative to now, e.g. "2h", or an absolute time in UTC using EndTimeLayout.
+// The returned end time is in milliseconds.
+func parseEndSettings(s string) (int64, *ErrorMessage) {
+	e := endSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.endSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
 		}
 	}
 
-	p.AnswerOptions[index].Voter = append(p.AnswerOptions[index].Voter, userID)
-	return nil, nil
-}
-
-// ResetVotes remove votes by a given user
-func (p *Poll) ResetVotes(userID string) {
-	for _, o := range p.AnswerOptions {
-		for i := 0; i < len(o.Voter); i++ {
-			if userID == o.Voter[i] {
-				o.Voter = append(o.Voter[:i], o.Voter[i+1:]...)
-			}
+	now := model.GetMillis()
+	endTime, ok := parseTime(e[1], now)
+	if !ok {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.endSettings.invalidSetting",
+				Other: `The end of a poll must be a duration like "2h" or a time in UTC like "2021-10-01T15:00". You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
 		}
 	}
-}
 
-// getAnswerOptionName returns answer option name (with voter count if progress setting is available)
-func (p *Poll) getAnswerOptionName(o *AnswerOption) string {
-	if p.Settings.Progress {
-		return fmt.Sprintf("%s (%d)", o.Answer, len(o.Voter))
+	if endTime <= now {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.endSettings.inPast",
+				Other: `The end of a poll must be in the future. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
 	}
-	return o.Answer
+	return endTime, nil
 }
 
-// GetVotedAnswers collect voted answers by a user and returns it as string array.
-func (p *Poll) GetVotedAnswers(userID string) []string {
-	votedAnswer := []string{}
-	for _, o := range p.AnswerOptions {
-		for _, v := range o.Voter {
-			if userID == v {
-				votedAnswer = append(votedAnswer, o.Answer)
-			}
+// parseScheduleSettings parses setting for the time a poll gets posted ("--schedule=X").
+// Like for "--end=X", X is either a duration relative to now or an absolute time in UTC using EndTimeLayout.
+// The returned time is in milliseconds.
+func parseScheduleSettings(s string) (int64, *ErrorMessage) {
+	e := scheduleSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.scheduleSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
 		}
 	}
 
-	return votedAnswer
-}
-
-// GetMetadata returns personalized metadata of a poll.
-func (p *Poll) GetMetadata(userID string, permission bool) *Metadata {
-	votedAnswers := []string{}
-	for _, o := range p.AnswerOptions {
-		for _, v := range o.Voter {
-			if userID == v {
-				votedAnswers = append(votedAnswers, p.getAnswerOptionName(o))
-			}
+	now := model.GetMillis()
+	scheduledAt, ok := parseTime(e[1], now)
+	if !ok {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.scheduleSettings.invalidSetting",
+				Other: `The time to post a poll must be a duration like "2h" or a time in UTC like "2021-10-01T15:00". You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
 		}
 	}
-	return &Metadata{
-		PollID:                 p.ID,
-		UserID:                 userID,
-		CanManagePoll:          permission,
-		VotedAnswers:           votedAnswers,
-		SettingPublicAddOption: p.Settings.PublicAddOption,
+
+	if scheduledAt <= now {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.scheduleSettings.inPast",
+				Other: `The time to post a poll must be in the future. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
 	}
+	return scheduledAt, nil
 }
 
-// HasVoted return true if a given user has voted in this poll
-func (p *Poll) HasVoted(userID string) bool {
-	for _, o := range p.AnswerOptions {
-		for i := 0; i < len(o.Voter); i++ {
-			if userID == o.Voter[i] {
-				return true
-			}
+// parseRepeatSettings parses setting for the repeat interval of a scheduled poll ("--repeat=X")
+func parseRepeatSettings(s string) (string, *ErrorMessage) {
+	e := repeatSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.repeatSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
 		}
 	}
-	return false
-}
 
-// EncodeToByte returns a poll as a byte array
-func (p *Poll) EncodeToByte() []byte {
-	b, _ := json.Marshal(p)
-	return b
+	switch e[1] {
+	case RepeatDaily, RepeatWeekly, RepeatMonthly:
+		return e[1], nil
+	default:
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.repeatSettings.invalidSetting",
+				Other: `A poll can be repeated "daily", "weekly" or "monthly". You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
 }
 
-// DecodePollFromByte tries to create a poll from a byte array
-func DecodePollFromByte(b []byte) *Poll {
-	p := Poll{}
-	err := json.Unmarshal(b, &p)
-	if err != nil {
+// parseVotersSettings parses setting for the users that may vote ("--voters=X").
+// X is either VotersChannel or a comma separated list of usernames, e.g. "@user1,@user2".
+// The usernames are returned without the leading "@".
+func parseVotersSettings(s string) (string, *ErrorMessage) {
+	e := votersSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.votersSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	if e[1] == VotersChannel {
+		return VotersChannel, nil
+	}
+
+	var usernames []string
+	for _, username := range strings.Split(e[1], ",") {
+		username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+		if username == "" || strings.ContainsAny(username, "@ ") {
+			return "", &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.newPoll.votersSettings.invalidSetting",
+					Other: `The voters must be "channel" or a list of users like "@user1,@user2". You specified "{{.Setting}}".`,
+				},
+				Data: map[string]interface{}{
+					"Setting": s,
+				},
+			}
+		}
+		usernames = append(usernames, username)
+	}
+	return strings.Join(usernames, ","), nil
+}
+
+// VoterUsernames returns the usernames of the users that may vote, without the leading "@".
+// It returns nil if the voters aren't restricted to a list of users.
+func (s Settings) VoterUsernames() []string {
+	if s.Voters == "" || s.Voters == VotersChannel {
+		return nil
+	}
+	return strings.Split(s.Voters, ",")
+}
+
+// parseChannelsSettings parses setting for the other channels a poll gets posted in ("--channels=X").
+// X is a comma separated list of channel names, e.g. "~town-square,~dev". The names are returned without the leading "~".
+func parseChannelsSettings(s string) (string, *ErrorMessage) {
+	e := channelsSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.channelsSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+
+	var names []string
+	for _, name := range strings.Split(e[1], ",") {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "~")
+		if name == "" || strings.ContainsAny(name, "~ ") {
+			return "", &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.newPoll.channelsSettings.invalidSetting",
+					Other: `The channels must be a list of channels like "~town-square,~dev". You specified "{{.Setting}}".`,
+				},
+				Data: map[string]interface{}{
+					"Setting": s,
+				},
+			}
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ","), nil
+}
+
+// parseInSettings parses setting for the channel a poll gets posted in ("--in=X").
+// X is the name of a channel, e.g. "~town-square". The name is returned without the leading "~".
+func parseInSettings(s string) (string, *ErrorMessage) {
+	e := inSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.inSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+
+	name := strings.TrimPrefix(strings.TrimSpace(e[1]), "~")
+	if name == "" || strings.ContainsAny(name, "~, ") {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.inSettings.invalidSetting",
+				Other: `The channel must be a single channel like "~town-square". You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	return name, nil
+}
+
+// parseLocaleSettings parses setting for the language a poll is shown in ("--locale=X").
+// X is a language code like "de" or "zh-CN". Underscores are accepted as separator as well, e.g. "zh_CN".
+// Whether the language is supported is checked by the plugin, which knows the available translations.
+func parseLocaleSettings(s string) (string, *ErrorMessage) {
+	e := localeSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.localeSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+
+	locale := strings.Replace(strings.TrimSpace(e[1]), "_", "-", -1)
+	if !localeCodePattern.MatchString(locale) {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.localeSettings.invalidSetting",
+				Other: `The language must be a language code like "de". You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	return locale, nil
+}
+
+// parseChartSettings parses setting for the chart of the final results ("--chart=X"). X is ChartBar or ChartPie.
+func parseChartSettings(s string) (string, *ErrorMessage) {
+	e := chartSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.chartSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+
+	if !IsChart(e[1]) {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.chartSettings.invalidSetting",
+				Other: `A chart can be a "bar" or a "pie" chart. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	return e[1], nil
+}
+
+// parseBoardSettings parses setting for the board the results are added to ("--board=X").
+// X is the ID of the board. Whether the board exists is checked by the Boards plugin when the poll ends.
+func parseBoardSettings(s string) (string, *ErrorMessage) {
+	e := boardSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.boardSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+
+	board := strings.TrimSpace(e[1])
+	if !boardIDPattern.MatchString(board) {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.boardSettings.invalidSetting",
+				Other: `The board must be the ID of a board. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	return board, nil
+}
+
+// parseDMSettings parses setting for the users a poll gets sent to as direct or group message ("--dm=X").
+// X is a comma separated list of usernames, e.g. "@user1,@user2". The usernames are returned without the leading "@".
+func parseDMSettings(s string) (string, *ErrorMessage) {
+	e := dmSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.dmSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+
+	var usernames []string
+	for _, username := range strings.Split(e[1], ",") {
+		username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+		if username == "" || strings.ContainsAny(username, "@ ") {
+			return "", &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.newPoll.dmSettings.invalidSetting",
+					Other: `The users must be a list of users like "@user1,@user2". You specified "{{.Setting}}".`,
+				},
+				Data: map[string]interface{}{
+					"Setting": s,
+				},
+			}
+		}
+		usernames = append(usernames, username)
+	}
+	return strings.Join(usernames, ","), nil
+}
+
+// DMUsernames returns the usernames of the users the poll gets sent to as direct or group message,
+// without the leading "@". It returns nil if the poll gets posted in a channel.
+func (s Settings) DMUsernames() []string {
+	if s.DM == "" {
+		return nil
+	}
+	return strings.Split(s.DM, ",")
+}
+
+// ChannelNames returns the names of the other channels the poll gets posted in, without the leading "~".
+func (s Settings) ChannelNames() []string {
+	if s.Channels == "" {
+		return nil
+	}
+	return strings.Split(s.Channels, ",")
+}
+
+// parseWeightsSettings parses setting for the weights of the votes ("--weights=X").
+// X is either a list of users with their weight like "@user1:3,@user2:1" or the name of a user attribute.
+// It returns the list without the leading "@" of the usernames or the name of the attribute.
+func parseWeightsSettings(s string) (weights string, attribute string, errMsg *ErrorMessage) {
+	e := weightsSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return "", "", &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.weightsSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	invalid := &ErrorMessage{
+		Message: &i18n.Message{
+			ID:    "poll.newPoll.weightsSettings.invalidSetting",
+			Other: `The weights must be a list of users with positive weights like "@user1:3,@user2:1" or the name of a user attribute. You specified "{{.Setting}}".`,
+		},
+		Data: map[string]interface{}{
+			"Setting": s,
+		},
+	}
+	if attributeNamePattern.MatchString(e[1]) {
+		return "", e[1], nil
+	}
+
+	var entries []string
+	for _, entry := range strings.Split(e[1], ",") {
+		m := weightEntryPattern.FindStringSubmatch(strings.TrimSpace(entry))
+		if len(m) != 3 {
+			return "", "", invalid
+		}
+		if weight, err := strconv.Atoi(m[2]); err != nil || weight <= 0 {
+			return "", "", invalid
+		}
+		entries = append(entries, m[1]+":"+m[2])
+	}
+	return strings.Join(entries, ","), "", nil
+}
+
+// WeightsByUsername returns the weights of the votes of the users listed in the weights setting, keyed by username.
+// It returns nil if the weights aren't given as a list of users.
+func (s Settings) WeightsByUsername() map[string]int {
+	if s.Weights == "" {
+		return nil
+	}
+	weights := map[string]int{}
+	for _, entry := range strings.Split(s.Weights, ",") {
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if weight, err := strconv.Atoi(parts[1]); err == nil {
+			weights[parts[0]] = weight
+		}
+	}
+	return weights
+}
+
+// IsWeighted returns true if the votes of the users have different weights.
+func (s Settings) IsWeighted() bool {
+	return s.Weights != "" || s.WeightAttribute != ""
+}
+
+// parseQuizSettings parses setting for the correct answer of a quiz ("--quiz=X")
+func parseQuizSettings(s string) (int, *ErrorMessage) {
+	e := quizSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.quizSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	i, err := strconv.Atoi(e[1])
+	if err != nil || i <= 0 {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.quizSettings.invalidSetting",
+				Other: `The correct answer must be the number of an option, starting at 1. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	return i, nil
+}
+
+// parseRequireCommentSettings parses setting for the answer options whose votes need a comment ("--require-comment=X"),
+// where X are numbers of answer options separated by commas, e.g. "--require-comment=2,3".
+func parseRequireCommentSettings(s string) ([]int, *ErrorMessage) {
+	e := requireCommentSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return nil, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.requireCommentSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+
+	var numbers []int
+	for _, field := range strings.Split(e[1], ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || i <= 0 {
+			return nil, &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.newPoll.requireCommentSettings.invalidSetting",
+					Other: `The options that need a comment must be numbers of options, starting at 1 and separated by commas. You specified "{{.Setting}}".`,
+				},
+				Data: map[string]interface{}{
+					"Setting": s,
+				},
+			}
+		}
+		numbers = append(numbers, i)
+	}
+	return numbers, nil
+}
+
+// parseScaleSettings parses setting for a rating scale ("--scale=X-Y")
+func parseScaleSettings(s string) (int, int, *ErrorMessage) {
+	e := scaleSettingPattern.FindStringSubmatch(s)
+	if len(e) != 3 {
+		return 0, 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.scaleSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	min, minErr := strconv.Atoi(e[1])
+	max, maxErr := strconv.Atoi(e[2])
+	if minErr != nil || maxErr != nil || min >= max {
+		return 0, 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.scaleSettings.invalidSetting",
+				Other: `The scale must be a range like "1-5" with the lower number first. You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	return min, max, nil
+}
+
+// parseRemindSettings parses setting for the automatic reminder before the end of a poll ("--remind=X").
+// X is a duration like "2h". The returned duration is in milliseconds.
+func parseRemindSettings(s string) (int64, *ErrorMessage) {
+	e := remindSettingPattern.FindStringSubmatch(s)
+	if len(e) != 2 {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.remindSettings.unexpectedError",
+				Other: "Unexpected error happens when parsing {{.Setting}}",
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	d, err := time.ParseDuration(e[1])
+	if err != nil || d < time.Minute {
+		return 0, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.remindSettings.invalidSetting",
+				Other: `The time of the reminder before the end of a poll must be a duration of at least one minute like "2h". You specified "{{.Setting}}".`,
+			},
+			Data: map[string]interface{}{
+				"Setting": s,
+			},
+		}
+	}
+	return int64(d / time.Millisecond), nil
+}
+
+// parseTime parses either a duration that is added to now or an absolute time in UTC using EndTimeLayout.
+// now and the returned time are in milliseconds. The returned bool is false if value is neither.
+func parseTime(value string, now int64) (int64, bool) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now + int64(d/time.Millisecond), true
+	}
+	if t, err := time.ParseInLocation(EndTimeLayout, value, time.UTC); err == nil {
+		return t.UnixNano() / int64(time.Millisecond), true
+	}
+	return 0, false
+}
+
+// CorrectAnswer returns the index of the correct answer option of a quiz.
+// It returns -1 if the poll isn't a quiz or the correct answer option was deleted.
+func (p *Poll) CorrectAnswer() int {
+	i := p.Settings.Quiz - 1
+	if i < 0 || i >= len(p.AnswerOptions) || p.AnswerOptions[i].Deleted {
+		return -1
+	}
+	return i
+}
+
+// UpdateQuestion changes the question of a poll
+func (p *Poll) UpdateQuestion(question string) *ErrorMessage {
+	if errMsg := p.limits().validateQuestion(question); errMsg != nil {
+		return errMsg
+	}
+	p.Question = question
+	p.touch()
+	return nil
+}
+
+// IsMultiVote return true if poll is set to multi vote.
+// Polls with an invalid MaxVotes value, e.g. decoded from corrupt data, are treated as single vote polls.
+func (p *Poll) IsMultiVote() bool {
+	return p.Settings.IsMultiVote()
+}
+
+// IsMultiVote returns true if users may vote for more than one answer option.
+func (s Settings) IsMultiVote() bool {
+	return s.MaxVotes > 1 || s.HasUnlimitedVotes()
+}
+
+// HasUnlimitedVotes returns true if users may vote for as many answer options as they like.
+func (s Settings) HasUnlimitedVotes() bool {
+	return s.MaxVotes == UnlimitedVotes
+}
+
+// AddAnswerOption adds a new AnswerOption to a poll
+func (p *Poll) AddAnswerOption(newAnswerOption string) *ErrorMessage {
+	newAnswerOption = strings.TrimSpace(newAnswerOption)
+	var slot int64
+	var imageURL string
+	if !p.Settings.Meeting && !p.Settings.IsScale() {
+		newAnswerOption, imageURL = splitImageURL(newAnswerOption)
+	}
+	if p.Settings.Meeting && newAnswerOption != "" {
+		var errMsg *ErrorMessage
+		if slot, errMsg = parseSlot(newAnswerOption); errMsg != nil {
+			return errMsg
+		}
+		newAnswerOption = formatSlot(slot)
+	}
+	if errMsg := p.validateAnswerOption(newAnswerOption, -1); errMsg != nil {
+		return errMsg
+	}
+	if errMsg := p.limits().validateAnswerOptionCount(len(p.AnswerOptions) + 1); errMsg != nil {
+		return errMsg
+	}
+	if p.Settings.Reactions && len(p.AnswerOptions) >= MaxReactionOptions {
+		return newTooManyReactionOptionsError()
+	}
+	ao := &AnswerOption{
+		Answer:   newAnswerOption,
+		Time:     slot,
+		ImageURL: imageURL,
+	}
+	p.AnswerOptions = append(p.AnswerOptions, ao)
+	if p.Settings.Meeting {
+		// Users can vote for every slot that works for them
+		p.Settings.MaxVotes = len(p.AnswerOptions)
+	}
+	p.touch()
+	return nil
+}
+
+// AddOtherAnswer adds the answer a user wrote in as a new answer option and votes for it, see Settings.AllowOther.
+// If an active answer option with the same text already exists, the user votes for it instead.
+// Nothing is changed if the answer is invalid or the user can't vote for it.
+func (p *Poll) AddOtherAnswer(userID, answer string) error {
+	answer = strings.TrimSpace(answer)
+	for i, o := range p.AnswerOptions {
+		if o.Answer == answer && !o.Deleted {
+			return p.UpdateVote(userID, i)
+		}
+	}
+
+	version, modifiedAt := p.Version, p.ModifiedAt
+	if errMsg := p.AddAnswerOption(answer); errMsg != nil {
+		return &VoteError{Err: ErrInvalidAnswer, ErrorMessage: errMsg}
+	}
+	index := len(p.AnswerOptions) - 1
+	if err := p.UpdateVote(userID, index); err != nil {
+		p.AnswerOptions = p.AnswerOptions[:index]
+		p.Version, p.ModifiedAt = version, modifiedAt
+		return err
+	}
+	if !p.Settings.Anonymous {
+		p.AnswerOptions[index].AddedBy = userID
+	}
+	return nil
+}
+
+// AddedOptionCount returns the number of answer options a user added to or suggested for the poll, see RecordAddedOption.
+func (p *Poll) AddedOptionCount(userID string) int {
+	return p.AddedOptions[p.voterID(userID)]
+}
+
+// RecordAddedOption counts an answer option a user added to or suggested for the poll.
+// It's recorded together with the answer option, hence it doesn't change the version of the poll on its own.
+func (p *Poll) RecordAddedOption(userID string) {
+	if p.AddedOptions == nil {
+		p.AddedOptions = map[string]int{}
+	}
+	p.AddedOptions[p.voterID(userID)]++
+}
+
+// newTooManyReactionOptionsError returns the error for a poll with more answer options than there are emojis to vote with.
+func newTooManyReactionOptionsError() *ErrorMessage {
+	return &ErrorMessage{
+		Message: &i18n.Message{
+			ID:    "poll.newPoll.settings.reactionsTooManyOptions",
+			Other: `Polls with the setting "{{.Setting}}" can have at most {{.Max}} options.`,
+		},
+		Data: map[string]interface{}{
+			"Setting": SettingKeyReactions,
+			"Max":     MaxReactionOptions,
+		},
+	}
+}
+
+// newSlotChangedError returns the error for renaming a slot of a meeting poll.
+// The time of a slot can't be changed, because users voted for this time.
+func newSlotChangedError(answer string) *ErrorMessage {
+	return &ErrorMessage{
+		Message: &i18n.Message{
+			ID:    "poll.meeting.slotChanged",
+			Other: `The slot "{{.Slot}}" can't be changed. Delete it and add a new one instead.`,
+		},
+		Data: map[string]interface{}{
+			"Slot": answer,
+		},
+	}
+}
+
+// RenameAnswerOption changes the text of an existing AnswerOption. The votes for the option are kept.
+func (p *Poll) RenameAnswerOption(oldAnswer, newAnswer string) *ErrorMessage {
+	oldAnswer = strings.TrimSpace(oldAnswer)
+	newAnswer = strings.TrimSpace(newAnswer)
+
+	index := p.OptionIndex(oldAnswer)
+	if index == -1 {
+		return newAnswerOptionNotFoundError(oldAnswer)
+	}
+
+	if errMsg := p.validateAnswerOption(newAnswer, index); errMsg != nil {
+		return errMsg
+	}
+
+	if p.AnswerOptions[index].Answer == newAnswer {
+		return nil
+	}
+	if p.Settings.Meeting {
+		return newSlotChangedError(oldAnswer)
+	}
+	p.AnswerOptions[index].Answer = newAnswer
+	p.touch()
+	return nil
+}
+
+// SoftDeleteOption hides an answer option. Its votes are kept and it can be restored using RestoreOption.
+// The indexes of the answer options don't change.
+func (p *Poll) SoftDeleteOption(answer string) *ErrorMessage {
+	index := p.OptionIndex(answer)
+	if index == -1 {
+		return newAnswerOptionNotFoundError(answer)
+	}
+	p.AnswerOptions[index].Deleted = true
+	p.touch()
+	return nil
+}
+
+// RestoreOption makes an answer option, that was deleted by SoftDeleteOption, visible again.
+func (p *Poll) RestoreOption(answer string) *ErrorMessage {
+	index := p.OptionIndex(answer)
+	if index == -1 {
+		return newAnswerOptionNotFoundError(answer)
+	}
+	p.AnswerOptions[index].Deleted = false
+	p.touch()
+	return nil
+}
+
+// Update changes the question and all answer options of a poll at once.
+// answers must contain the new text of every answer option, in the same order as AnswerOptions.
+// Renamed answer options keep their votes. An empty answer deletes the answer option using the same rules as
+// SoftDeleteOption, hence the indexes of the answer options don't change and their votes are kept.
+// An answer given as "answer|URL" sets the image of the answer option, an answer without an URL removes it,
+// see AnswerOption.InputText. Either all changes are applied or, if one of them is invalid, none.
+func (p *Poll) Update(question string, answers []string) *ErrorMessage {
+	if len(answers) != len(p.AnswerOptions) {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.update.optionCountMismatch",
+				Other: "The poll has {{.Options}} options, but {{.Answers}} were given.",
+			},
+			Data: map[string]interface{}{
+				"Options": len(p.AnswerOptions),
+				"Answers": len(answers),
+			},
+		}
+	}
+	if errMsg := p.limits().validateQuestion(question); errMsg != nil {
+		return errMsg
+	}
+
+	updated := p.Copy()
+	updated.Question = question
+	changed := p.Question != question
+	active := 0
+	for i, answer := range answers {
+		answer = strings.TrimSpace(answer)
+		o := updated.AnswerOptions[i]
+		if answer == "" {
+			changed = changed || !o.Deleted
+			o.Deleted = true
+			continue
+		}
+		if p.Settings.Meeting && o.Answer != answer {
+			return newSlotChangedError(o.Answer)
+		}
+		var imageURL string
+		if !p.Settings.Meeting && !p.Settings.IsScale() {
+			answer, imageURL = splitImageURL(answer)
+		}
+		changed = changed || o.Deleted || o.Answer != answer || o.ImageURL != imageURL
+		o.Answer = answer
+		o.ImageURL = imageURL
+		o.Deleted = false
+		active++
+	}
+	// Answers are validated after all of them have been applied, so that two answer options can swap their text
+	for i, o := range updated.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
+		if errMsg := updated.validateAnswerOption(o.Answer, i); errMsg != nil {
+			return errMsg
+		}
+	}
+	if active < MinAnswerOptions {
+		return newTooFewOptionsError(active)
+	}
+
+	if !changed {
+		return nil
+	}
+	p.Question = updated.Question
+	p.AnswerOptions = updated.AnswerOptions
+	p.touch()
+	return nil
+}
+
+// ActiveOptions returns all answer options that are not deleted
+func (p *Poll) ActiveOptions() []*AnswerOption {
+	options := []*AnswerOption{}
+	for _, o := range p.AnswerOptions {
+		if !o.Deleted {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+// PruneEmptyOptions removes all answer options without votes and returns the number of removed options.
+// Answer options are removed starting from the last one and never below MinAnswerOptions.
+// MaxVotes is reduced if it exceeds the remaining number of answer options.
+// The author of an answer option isn't tracked, so options added by the poll creator are removed too.
+// The abstain option, the correct answer of a quiz and the options of a scale are kept.
+func (p *Poll) PruneEmptyOptions() int {
+	// The options of a scale are generated from its range, removing one would leave a gap
+	if p.Settings.IsScale() {
+		return 0
+	}
+	pruned := 0
+	for i := len(p.AnswerOptions) - 1; i >= 0 && len(p.AnswerOptions) > MinAnswerOptions; i-- {
+		if p.VoteCount(i) == 0 && !p.AnswerOptions[i].Abstain && i != p.Settings.Quiz-1 {
+			p.AnswerOptions = append(p.AnswerOptions[:i], p.AnswerOptions[i+1:]...)
+			// Settings.Quiz and Settings.RequireComment are numbers of answer options, starting at one
+			if p.Settings.Quiz > i+1 {
+				p.Settings.Quiz--
+			}
+			p.Settings.RequireComment = removeOptionNumber(p.Settings.RequireComment, i+1)
+			// Options without votes aren't ranked by anyone, but the indexes of the following options change
+			for _, ranking := range p.Rankings {
+				for j := range ranking {
+					if ranking[j] > i {
+						ranking[j]--
+					}
+				}
+			}
+			p.removeOptionFromVotes(i)
+			pruned++
+		}
+	}
+	if pruned == 0 {
+		return 0
+	}
+
+	if p.Settings.MaxVotes > len(p.AnswerOptions) {
+		p.Settings.MaxVotes = len(p.AnswerOptions)
+	}
+	p.touch()
+	return pruned
+}
+
+// removeOptionNumber removes the number of a removed answer option from numbers and decreases the numbers
+// of the following answer options.
+func removeOptionNumber(numbers []int, removed int) []int {
+	var result []int
+	for _, n := range numbers {
+		switch {
+		case n < removed:
+			result = append(result, n)
+		case n > removed:
+			result = append(result, n-1)
+		}
+	}
+	return result
+}
+
+// OptionIndex returns the index of the answer option with the given answer or -1 if there is none.
+// Like when adding answer options, surrounding whitespace of answer is ignored.
+func (p *Poll) OptionIndex(answer string) int {
+	answer = strings.TrimSpace(answer)
+	for i, o := range p.AnswerOptions {
+		if o.Answer == answer {
+			return i
+		}
+	}
+	return -1
+}
+
+// ActiveOptionIndex returns the index of the active answer option with the given number, counting from one
+// in the order they are shown to a user, or -1 if there is none. See OptionOrder for polls that shuffle their answer options.
+func (p *Poll) ActiveOptionIndex(userID string, number int) int {
+	if order := p.OptionOrder(userID); order != nil {
+		if number < 1 || number > len(order) {
+			return -1
+		}
+		return order[number-1]
+	}
+	for i, o := range p.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
+		if number--; number == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func newAnswerOptionNotFoundError(answer string) *ErrorMessage {
+	return &ErrorMessage{
+		Message: &i18n.Message{
+			ID:    "poll.answerOption.notFound",
+			Other: "Option not found: {{.Option}}",
+		},
+		Data: map[string]interface{}{
+			"Option": strings.TrimSpace(answer),
+		},
+	}
+}
+
+// AddAnswerOptions tries to add all given answer options to a poll.
+// Options that are valid are added even if others fail. An AnswerOptionError is returned for every
+// option that couldn't be added, with Index referring to the position in newAnswerOptions.
+func (p *Poll) AddAnswerOptions(newAnswerOptions []string) []*AnswerOptionError {
+	var errs []*AnswerOptionError
+	for i, answerOption := range newAnswerOptions {
+		if errMsg := p.AddAnswerOption(answerOption); errMsg != nil {
+			errs = append(errs, &AnswerOptionError{
+				Index:        i,
+				ErrorMessage: errMsg,
+			})
+		}
+	}
+	return errs
+}
+
+// UpdateVote performs a vote for a given user.
+// If the vote could not be performed, a *VoteError is returned.
+func (p *Poll) UpdateVote(userID string, index int) error {
+	return p.updateVote(userID, index, p.GetComment(userID))
+}
+
+// updateVote performs a vote for a given user, who explains it with comment. See RequiresComment.
+func (p *Poll) updateVote(userID string, index int, comment string) error {
+	if len(p.AnswerOptions) <= index || index < 0 {
+		return &VoteError{Err: ErrInvalidIndex}
+	}
+	if userID == "" {
+		return &VoteError{Err: ErrInvalidUser}
+	}
+	if p.AnswerOptions[index].Deleted {
+		return &VoteError{
+			Err: ErrOptionDeleted,
+			ErrorMessage: &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.updateVote.optionDeleted",
+					Other: "This option has been removed from the poll.",
+				},
+			},
+		}
+	}
+	if p.HasEnded() {
+		return newPollEndedError()
+	}
+	if !p.CanVote(userID) {
+		return &VoteError{
+			Err: ErrNotAllowed,
+			ErrorMessage: &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.updateVote.notAllowed",
+					Other: "You are not allowed to vote in this poll.",
+				},
+			},
+		}
+	}
+	if comment == "" && p.RequiresComment(index) {
+		return newCommentRequiredError(p.AnswerOptions[index].Answer)
+	}
+	userID = p.voterID(userID)
+
+	if p.IsFull(index, userID) {
+		return &VoteError{
+			Err: ErrOptionFull,
+			ErrorMessage: &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.updateVote.optionFull",
+					Other: "This option is full. All {{.Capacity}} slots are taken.",
+				},
+				Data: map[string]interface{}{
+					"Capacity": p.Settings.Capacity,
+				},
+			},
+		}
+	}
+
+	if p.Settings.Ranked {
+		// Ranked Mode
+		for _, i := range p.Rankings[userID] {
+			if i == index {
+				return newAlreadyVotedError()
+			}
+		}
+		if p.Rankings == nil {
+			p.Rankings = map[string][]int{}
+		}
+		p.Rankings[userID] = append(p.Rankings[userID], index)
+	} else if p.IsMultiVote() {
+		// Multi Answer Mode
+		votedAnswers := p.GetVotedAnswers(userID)
+		for _, answer := range votedAnswers {
+			if answer == p.AnswerOptions[index].Answer {
+				return newAlreadyVotedError()
+			}
+		}
+		if !p.Settings.HasUnlimitedVotes() && p.Settings.MaxVotes <= len(votedAnswers) {
+			return &VoteError{
+				Err: ErrNoVotesLeft,
+				ErrorMessage: &ErrorMessage{
+					Message: &i18n.Message{
+						ID:    "poll.updateVote.maxVotes",
+						Other: "You couldn't vote for this option, because you don't have any votes left. You've voted for [{{.Votes}}]. Use the reset button to reset your votes.",
+					},
+					Data: map[string]interface{}{
+						"Votes": strings.Join(votedAnswers, ", "),
+					},
+				},
+			}
+		}
+	} else {
+		// Single Answer Mode
+		for _, i := range p.sortedBallot(userID) {
+			p.removeVote(userID, i)
+		}
+	}
+
+	p.addBallotVote(userID, index)
+	p.recordVote(userID, index)
+	p.touch()
+	return nil
+}
+
+// ToggleVote adds a vote of a user for an answer option like UpdateVote. In multi answer mode, a vote the user has
+// already given for the answer option is removed instead. The returned bool is true if a vote was removed.
+func (p *Poll) ToggleVote(userID string, index int) (bool, error) {
+	if !p.IsMultiVote() || p.Settings.Ranked {
+		return false, p.UpdateVote(userID, index)
+	}
+	if voted, _ := p.HasVotedFor(userID, index); !voted || userID == "" {
+		return false, p.UpdateVote(userID, index)
+	}
+	if p.HasEnded() {
+		return false, newPollEndedError()
+	}
+
+	p.removeVote(p.voterID(userID), index)
+	p.touch()
+	return true, nil
+}
+
+// RemoveVote removes the vote of a user for the answer option at index. It returns true if the user had voted for it.
+func (p *Poll) RemoveVote(userID string, index int) (bool, error) {
+	if voted, err := p.HasVotedFor(userID, index); err != nil || !voted {
+		return false, err
+	}
+	if p.HasEnded() {
+		return false, newPollEndedError()
+	}
+
+	p.removeVote(p.voterID(userID), index)
+	p.touch()
+	return true, nil
+}
+
+func newPollEndedError() *VoteError {
+	return &VoteError{
+		Err: ErrPollEnded,
+		ErrorMessage: &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.updateVote.pollEnded",
+				Other: "This poll has already ended.",
+			},
+		},
+	}
+}
+
+func newAlreadyVotedError() *VoteError {
+	return &VoteError{
+		Err: ErrAlreadyVoted,
+		ErrorMessage: &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.updateVote.alreadyVoted",
+				Other: "You've already voted for this option.",
+			},
+		},
+	}
+}
+
+// IsFull returns true if the answer option at index has reached the capacity of the poll.
+// The vote of userID isn't counted, so users can still vote again for an answer option they have already voted for.
+func (p *Poll) IsFull(index int, userID string) bool {
+	if p.Settings.Capacity <= 0 {
+		return false
+	}
+	voters := p.VoteCount(index)
+	if voted, _ := p.HasVotedFor(userID, index); voted {
+		voters--
+	}
+	return voters >= p.Settings.Capacity
+}
+
+// SetAllowedVoters restricts voting to the given users. Empty and duplicate user IDs are ignored.
+// Passing an empty list allows everyone to vote.
+func (p *Poll) SetAllowedVoters(userIDs []string) {
+	allowedVoters := []string{}
+	seen := map[string]bool{}
+	for _, userID := range userIDs {
+		userID = strings.TrimSpace(userID)
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		allowedVoters = append(allowedVoters, userID)
+	}
+
+	if len(allowedVoters) == 0 {
+		allowedVoters = nil
+	}
+	p.AllowedVoters = allowedVoters
+	p.touch()
+}
+
+// SetBroadcastChannels sets the other channels the poll gets posted in. Empty and duplicate channel IDs
+// and the channel of the poll itself are ignored.
+func (p *Poll) SetBroadcastChannels(channelID string, channelIDs []string) {
+	var broadcasts []*BroadcastPost
+	seen := map[string]bool{channelID: true}
+	for _, id := range channelIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		broadcasts = append(broadcasts, &BroadcastPost{ChannelID: id})
+	}
+	p.Broadcasts = broadcasts
+	p.touch()
+}
+
+// Posts returns the post of the poll followed by all its copies in other channels.
+func (p *Poll) Posts() []*BroadcastPost {
+	posts := []*BroadcastPost{{ChannelID: p.ChannelID, PostID: p.PostID}}
+	return append(posts, p.Broadcasts...)
+}
+
+// PostIDInChannel returns the ID of the post of the poll in a channel. It's the ID of the copy,
+// if the poll has been broadcast to the channel, and PostID otherwise.
+func (p *Poll) PostIDInChannel(channelID string) string {
+	for _, b := range p.Broadcasts {
+		if b.ChannelID == channelID && b.PostID != "" {
+			return b.PostID
+		}
+	}
+	return p.PostID
+}
+
+// SetWeights sets the weights of the votes of the given users, keyed by user ID.
+// Weights below one are ignored. Passing an empty map gives every user a weight of one.
+func (p *Poll) SetWeights(weights map[string]int) {
+	p.Weights = nil
+	for userID, weight := range weights {
+		if userID == "" || weight < 1 {
+			continue
+		}
+		if p.Weights == nil {
+			p.Weights = map[string]int{}
+		}
+		p.Weights[userID] = weight
+	}
+	p.touch()
+}
+
+// WeightedCounts returns the sum of the weights of the voters of every answer option.
+// The weights are keyed by user ID, hence they are hashed the same way as the voters.
+// It returns nil if the votes of the poll aren't weighted.
+func (p *Poll) WeightedCounts() []int {
+	if !p.Settings.IsWeighted() {
+		return nil
+	}
+	weights := p.weightsByVoterID()
+	counts := make([]int, len(p.AnswerOptions))
+	for i := range p.AnswerOptions {
+		counts[i] = weightedCount(p.Voters(i), weights)
+	}
+	return counts
+}
+
+// weightsByVoterID returns the weights of the poll keyed by the IDs the votes of the users are stored with.
+func (p *Poll) weightsByVoterID() map[string]int {
+	weights := make(map[string]int, len(p.Weights))
+	for userID, weight := range p.Weights {
+		weights[p.voterID(userID)] = weight
+	}
+	return weights
+}
+
+// weightedCount returns the sum of the weights of the voters of an answer option.
+func weightedCount(voters []string, weights map[string]int) int {
+	count := 0
+	for _, v := range voters {
+		if weight, ok := weights[v]; ok {
+			count += weight
+		} else {
+			count++
+		}
+	}
+	return count
+}
+
+// CanVote returns true if a given user is allowed to vote in this poll
+func (p *Poll) CanVote(userID string) bool {
+	if len(p.AllowedVoters) == 0 {
+		return true
+	}
+	for _, allowedVoter := range p.AllowedVoters {
+		if userID == allowedVoter {
+			return true
+		}
+	}
+	return false
+}
+
+// ResetVotes remove votes by a given user
+func (p *Poll) ResetVotes(userID string) {
+	userID = p.voterID(userID)
+	removed := false
+	for _, i := range p.sortedBallot(userID) {
+		if p.removeVote(userID, i) {
+			removed = true
+		}
+	}
+	if _, ok := p.Rankings[userID]; ok {
+		delete(p.Rankings, userID)
+		removed = true
+	}
+	// A comment explains the votes of a user, hence it's reset with them
+	if p.removeComment(userID) {
+		removed = true
+	}
+	if removed {
+		p.touch()
+	}
+}
+
+// getAnswerOptionName returns the name of the answer option at index (with voter count if progress setting is available
+// or the number of taken slots if the poll has a capacity)
+func (p *Poll) getAnswerOptionName(index int) string {
+	o := p.AnswerOptions[index]
+	if p.HidesResults() {
+		return o.Answer
+	}
+	if p.Settings.Capacity > 0 {
+		return fmt.Sprintf("%s (%d/%d)", o.Answer, p.VoteCount(index), p.Settings.Capacity)
+	}
+	if p.Settings.Progress {
+		return fmt.Sprintf("%s (%d)", o.Answer, p.VoteCount(index))
+	}
+	return o.Answer
+}
+
+// GetVotedAnswers collect voted answers by a user and returns it as string array.
+func (p *Poll) GetVotedAnswers(userID string) []string {
+	votedAnswer := []string{}
+	for _, i := range p.sortedBallot(p.voterID(userID)) {
+		votedAnswer = append(votedAnswer, p.AnswerOptions[i].Answer)
+	}
+
+	return votedAnswer
+}
+
+// VotedAnswersText returns the answer options a user voted for as comma separated list. Every answer option
+// is prefixed with the emoji of its number, e.g. ":one: Answer 1, :three: Answer 3". The numbers are left out
+// if the answer options are shuffled, since every user sees them in a different order.
+func (p *Poll) VotedAnswersText(userID string) string {
+	answers := []string{}
+	for _, i := range p.sortedBallot(p.voterID(userID)) {
+		answer := p.AnswerOptions[i].Answer
+		if emoji := numberEmoji(i); emoji != "" && !p.Settings.Shuffle {
+			answer = emoji + " " + answer
+		}
+		answers = append(answers, answer)
+	}
+	return strings.Join(answers, ", ")
+}
+
+// GetMetadata returns personalized metadata of a poll.
+func (p *Poll) GetMetadata(userID string, permission bool) *Metadata {
+	votedAnswers := []string{}
+	for _, i := range p.sortedBallot(p.voterID(userID)) {
+		votedAnswers = append(votedAnswers, p.getAnswerOptionName(i))
+	}
+	return &Metadata{
+		PollID:                 p.ID,
+		UserID:                 userID,
+		CanManagePoll:          permission,
+		VotedAnswers:           votedAnswers,
+		SettingPublicAddOption: p.Settings.PublicAddOption,
+		OptionOrder:            p.OptionOrder(userID),
+	}
+}
+
+// GetResults returns the aggregated results of a poll. The number of votes is only included if it's also
+// shown in the poll post, i.e. after the poll has ended or during the poll with the progress setting.
+func (p *Poll) GetResults() *Results {
+	showVotes := p.HasEnded() || (p.Settings.Progress && !p.Settings.Secret)
+
+	results := &Results{
+		PollID:  p.ID,
+		Answers: []string{},
+		Ended:   p.HasEnded(),
+	}
+	if showVotes {
+		results.Votes = []int{}
+		results.Voters = p.VoterCount()
+	}
+	for i, o := range p.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
+		results.Answers = append(results.Answers, o.Answer)
+		if showVotes {
+			results.Votes = append(results.Votes, p.VoteCount(i))
+		}
+	}
+	return results
+}
+
+// GetSummary returns the question, the answer options and the aggregated results of a poll for a user.
+// The votes are included if they are also shown in the poll, the voters only if the user may see them, see ShowsVotersTo.
+func (p *Poll) GetSummary(userID string) *Summary {
+	results := p.GetResults()
+	showVoters := results.Votes != nil && p.ShowsVotersTo(userID)
+
+	summary := &Summary{
+		PollID:      p.ID,
+		Question:    p.Question,
+		Options:     []*OptionSummary{},
+		VotesHidden: results.Votes == nil,
+		Voters:      results.Voters,
+		Status:      StatusRunning,
+		CreatedAt:   p.CreatedAt,
+		EndTime:     p.Settings.EndTime,
+		EndedAt:     p.EndedAt,
+	}
+	if p.HasEnded() {
+		summary.Status = StatusEnded
+	}
+	for i, o := range p.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
+		option := &OptionSummary{Answer: o.Answer, ImageURL: o.ImageURL}
+		if results.Votes != nil {
+			option.Votes = p.VoteCount(i)
+		}
+		if showVoters {
+			option.VoterIDs = p.Voters(i)
+		}
+		summary.Options = append(summary.Options, option)
+	}
+	return summary
+}
+
+// TotalVotes returns the number of votes over all answer options
+func (p *Poll) TotalVotes() int {
+	total := 0
+	for _, count := range p.VoteCounts {
+		total += count
+	}
+	return total
+}
+
+// Percentages returns the share of votes of every answer option in percent, in the same order as AnswerOptions.
+// The values are not rounded, so callers can format them as needed. If there are no votes, all values are zero.
+// Votes for the abstain option are left out, so its share is always zero.
+func (p *Poll) Percentages() []float64 {
+	percentages := make([]float64, len(p.AnswerOptions))
+	total := 0
+	for i, o := range p.AnswerOptions {
+		if !o.Abstain {
+			total += p.VoteCount(i)
+		}
+	}
+	if total == 0 {
+		return percentages
+	}
+	for i, o := range p.AnswerOptions {
+		if !o.Abstain {
+			percentages[i] = float64(p.VoteCount(i)) * 100 / float64(total)
+		}
+	}
+	return percentages
+}
+
+// VoterCount returns the number of distinct users that have voted
+func (p *Poll) VoterCount() int {
+	return len(p.Ballots)
+}
+
+// VotesByUser returns the indexes of the answer options every user voted for, keyed by voter ID.
+// The indexes are in ascending order.
+func (p *Poll) VotesByUser() map[string][]int {
+	votes := make(map[string][]int, len(p.Ballots))
+	for voterID := range p.Ballots {
+		votes[voterID] = p.sortedBallot(voterID)
+	}
+	return votes
+}
+
+// VoteSummaryByUser returns the number of answer options each user voted for, keyed by user ID.
+// If the voters are hidden, an empty map is returned, so voter identities are not exposed.
+func (p *Poll) VoteSummaryByUser() map[string]int {
+	summary := map[string]int{}
+	if p.HidesVoters() {
+		return summary
+	}
+	for userID, indexes := range p.VotesByUser() {
+		summary[userID] = len(indexes)
+	}
+	return summary
+}
+
+// QuorumMet returns true if enough distinct users have voted to reach the quorum.
+// Polls without a quorum always return true.
+func (p *Poll) QuorumMet() bool {
+	return p.QuorumVoterCount() >= p.Settings.Quorum
+}
+
+// QuorumVoterCount returns the number of distinct users that count for the quorum,
+// i.e. that voted for at least one answer option other than the abstain option.
+func (p *Poll) QuorumVoterCount() int {
+	voters := 0
+	for _, indexes := range p.Ballots {
+		for _, i := range indexes {
+			if !p.AnswerOptions[i].Abstain {
+				voters++
+				break
+			}
+		}
+	}
+	return voters
+}
+
+// SetQuorumFromEligible sets the quorum of a poll whose quorum is a percentage to that share of the given number
+// of users who may vote, rounded up. The quorum is at least one voter. Polls with an absolute quorum are left untouched.
+func (p *Poll) SetQuorumFromEligible(eligible int) {
+	if p.Settings.QuorumPercent <= 0 {
+		return
+	}
+	quorum := (eligible*p.Settings.QuorumPercent + 99) / 100
+	if quorum < 1 {
+		quorum = 1
+	}
+	p.Settings.Quorum = quorum
+}
+
+// EndPrevented returns true if the poll must not be ended by a user yet, because HoldForQuorum is set
+// and the quorum isn't reached.
+func (p *Poll) EndPrevented() bool {
+	return p.Settings.HoldForQuorum && !p.QuorumMet()
+}
+
+// HidesVoters returns true if the identities of the voters must not be shown.
+// This is the case for anonymous polls, unless RevealOnEnd is set and the poll has ended,
+// and for secret polls that are still running.
+func (p *Poll) HidesVoters() bool {
+	if p.HidesResults() {
+		return true
+	}
+	if !p.Settings.Anonymous {
+		return false
+	}
+	return !(p.revealsVotersOnEnd() && p.HasEnded())
+}
+
+// revealsVotersOnEnd returns true if the voters are shown once the poll has ended.
+// RevealOnEnd only changes how the results are rendered, the voters of such polls are always stored the same way.
+func (p *Poll) revealsVotersOnEnd() bool {
+	return !p.Settings.Anonymous || p.Settings.RevealOnEnd
+}
+
+// ShowsVotersTo returns true if a user may see who voted for what. Everyone may see it unless HidesVoters is true,
+// in which case only the creator of a semi-anonymous poll may see it, as long as the results aren't hidden.
+func (p *Poll) ShowsVotersTo(userID string) bool {
+	if !p.HidesVoters() {
+		return true
+	}
+	return p.Settings.SemiAnonymous && userID == p.Creator && !p.HidesResults()
+}
+
+// HidesResults returns true if the number of votes of the answer options must not be shown.
+// This is the case for secret polls that are still running.
+func (p *Poll) HidesResults() bool {
+	return p.Settings.Secret && !p.HasEnded()
+}
+
+// HasEnded returns true if the poll was closed.
+func (p *Poll) HasEnded() bool {
+	return p.EndedAt != 0
+}
+
+// DeadlinePassed returns true if the poll has an end time that is not after now.
+// now is given in milliseconds.
+func (p *Poll) DeadlinePassed(now int64) bool {
+	return p.Settings.EndTime > 0 && p.Settings.EndTime <= now
+}
+
+// MaybeAutoClose closes the poll if CloseOnQuorum is set and the quorum is reached.
+// It returns true if the poll got closed by this call. Polls that have already ended are left untouched.
+func (p *Poll) MaybeAutoClose() bool {
+	if p.HasEnded() || !p.Settings.CloseOnQuorum || p.Settings.Quorum <= 0 {
+		return false
+	}
+	if !p.QuorumMet() {
+		return false
+	}
+	p.End()
+	return true
+}
+
+// End closes the poll. Polls that have already ended are left untouched.
+func (p *Poll) End() {
+	if p.HasEnded() {
+		return
+	}
+	p.EndedAt = model.GetMillis()
+	p.touch()
+}
+
+// ReopenPeriodPassed returns true if the poll has ended at least gracePeriod before now.
+// now is given in milliseconds.
+func (p *Poll) ReopenPeriodPassed(now int64, gracePeriod time.Duration) bool {
+	return p.HasEnded() && p.EndedAt+int64(gracePeriod/time.Millisecond) <= now
+}
+
+// Reopen makes an ended poll running again. This is only possible within gracePeriod after the poll has ended
+// and only if the end time of the poll hasn't passed yet. now is given in milliseconds.
+func (p *Poll) Reopen(now int64, gracePeriod time.Duration) *ErrorMessage {
+	if !p.HasEnded() {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.reopen.notEnded",
+				Other: "The poll is still running.",
+			},
+		}
+	}
+	if p.ReopenPeriodPassed(now, gracePeriod) {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.reopen.gracePeriodPassed",
+				Other: "Polls can only be re-opened within {{.Minutes}} minutes after they have ended.",
+			},
+			Data: map[string]interface{}{
+				"Minutes": int(gracePeriod / time.Minute),
+			},
+		}
+	}
+	if p.DeadlinePassed(now) {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.reopen.deadlinePassed",
+				Other: "The poll can't be re-opened, because its end time has passed.",
+			},
+		}
+	}
+	p.EndedAt = 0
+	p.touch()
+	return nil
+}
+
+// Transfer makes another user the creator of a running poll, who is then allowed to manage it.
+func (p *Poll) Transfer(creator string) *ErrorMessage {
+	if p.HasEnded() {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.transfer.pollEnded",
+				Other: "The poll has already ended.",
+			},
+		}
+	}
+	if p.Creator == creator {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.transfer.sameCreator",
+				Other: "The user already is the creator of the poll.",
+			},
+		}
+	}
+	p.Creator = creator
+	p.touch()
+	return nil
+}
+
+// CompareAndApply applies mutate to the poll, if the version of the poll is still expectedVersion.
+// The returned bool is false if the version didn't match, in which case the poll is left untouched.
+// Otherwise the error message of mutate is returned. A successful mutation always increases the version.
+func (p *Poll) CompareAndApply(expectedVersion int, mutate func(*Poll) *ErrorMessage) (*ErrorMessage, bool) {
+	if p.Version != expectedVersion {
+		return nil, false
+	}
+	if errMsg := mutate(p); errMsg != nil {
+		return errMsg, true
+	}
+	if p.Version == expectedVersion {
+		p.touch()
+	}
+	return nil, true
+}
+
+// Touch marks the poll as modified. It has to be called after fields of the poll got changed directly.
+func (p *Poll) Touch() {
+	p.touch()
+}
+
+// touch increases the version and updates ModifiedAt
+func (p *Poll) touch() {
+	p.Version++
+	p.ModifiedAt = model.GetMillis()
+}
+
+// HasVoted return true if a given user has voted in this poll
+func (p *Poll) HasVoted(userID string) bool {
+	_, ok := p.Ballots[p.voterID(userID)]
+	return ok
+}
+
+// HasVotedFor returns true if a given user has voted for the answer option at index.
+// ErrInvalidIndex is returned if there is no answer option at index.
+func (p *Poll) HasVotedFor(userID string, index int) (bool, error) {
+	if len(p.AnswerOptions) <= index || index < 0 {
+		return false, ErrInvalidIndex
+	}
+	for _, i := range p.Ballots[p.voterID(userID)] {
+		if i == index {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Fingerprint returns a SHA-256 hash over the content of the poll as hex string.
+// The order of the voters and their votes and of the allowed voters as well as the version and
+// modification time don't affect the result.
+func (p *Poll) Fingerprint() string {
+	c := p.Copy()
+	for voterID := range c.Ballots {
+		c.Ballots[voterID] = c.sortedBallot(voterID)
+	}
+	sort.Strings(c.BallotOrder)
+	sort.Strings(c.AllowedVoters)
+	// The version changes with every modification, even if the content ends up the same
+	c.Version = 0
+	c.ModifiedAt = 0
+
+	sum := sha256.Sum256(c.EncodeToByte())
+	return hex.EncodeToString(sum[:])
+}
+
+// EncodeToByte returns a poll as a byte array
+func (p *Poll) EncodeToByte() []byte {
+	b, _ := json.Marshal(p)
+	return b
+}
+
+// DecodePollFromByte tries to create a poll from a byte array and migrates it to CurrentSchemaVersion.
+// If strict is set, fields the poll doesn't know are rejected. Fields inside the settings aren't checked,
+// since they support a legacy format and are decoded on their own.
+//
+// An error wrapping ErrCorruptPoll is returned if the data is invalid, has no poll ID or an invalid number of votes.
+// ErrNewerSchemaVersion is returned if the poll was stored with a newer schema version.
+func DecodePollFromByte(b []byte, strict bool) (*Poll, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	if strict {
+		d.DisallowUnknownFields()
+	}
+
+	p := Poll{}
+	if err := d.Decode(&p); err != nil {
+		return nil, errors.Wrap(ErrCorruptPoll, err.Error())
+	}
+	if p.ID == "" {
+		return nil, errors.Wrap(ErrCorruptPoll, "missing poll ID")
+	}
+	if p.SchemaVersion > CurrentSchemaVersion {
+		return nil, ErrNewerSchemaVersion
+	}
+	p.migrate()
+	if p.Settings.MaxVotes < 1 && !p.Settings.HasUnlimitedVotes() {
+		return nil, errors.Wrapf(ErrCorruptPoll, "invalid number of votes %d", p.Settings.MaxVotes)
+	}
+	return &p, nil
+}
+
+// UnmarshalJSON decodes settings. Besides the current format, the legacy format is supported,
+// where all settings are stored as a single comma separated string, e.g. "anonymous,votes=2".
+// Legacy settings are parsed the same way as the settings of a new poll.
+func (s *Settings) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '"' {
+		var legacy string
+		if err := json.Unmarshal(b, &legacy); err != nil {
+			return err
+		}
+		settings, errMsg := NewSettingsFromStrings(splitLegacySettings(legacy))
+		if errMsg != nil {
+			return fmt.Errorf("failed to parse legacy settings %q", legacy)
+		}
+		*s = settings
 		return nil
 	}
-	return &p
+
+	// Use a different type to not call UnmarshalJSON recursively
+	type currentSettings Settings
+	return json.Unmarshal(b, (*currentSettings)(s))
+}
+
+// splitLegacySettings splits a comma separated settings string into single settings.
+// Empty settings and leading dashes, as used in the slash command, are removed.
+func splitLegacySettings(legacy string) []string {
+	strs := []string{}
+	for _, str := range strings.Split(legacy, ",") {
+		str = strings.TrimLeft(strings.TrimSpace(str), "-")
+		if str != "" {
+			strs = append(strs, str)
+		}
+	}
+	return strs
 }
 
 // Copy deep copies a poll
@@ -344,13 +2458,107 @@ func (p *Poll) Copy() *Poll {
 	for i, o := range p.AnswerOptions {
 		p2.AnswerOptions[i] = new(AnswerOption)
 		p2.AnswerOptions[i].Answer = o.Answer
-		// Only copy Voter if they are nil to ensure the new poll is an exact copy.
-		// Please note that polls fetched from the DB might have a nil value,
-		// hence we have to still think about this case in the future.
-		if o.Voter != nil {
-			p2.AnswerOptions[i].Voter = make([]string, len(o.Voter))
-			copy(p2.AnswerOptions[i].Voter, o.Voter)
+		p2.AnswerOptions[i].Deleted = o.Deleted
+		p2.AnswerOptions[i].Time = o.Time
+		p2.AnswerOptions[i].AddedBy = o.AddedBy
+		p2.AnswerOptions[i].Abstain = o.Abstain
+		p2.AnswerOptions[i].ImageURL = o.ImageURL
+	}
+	p.copyBallots(p2)
+	if p.Settings.RequireComment != nil {
+		p2.Settings.RequireComment = make([]int, len(p.Settings.RequireComment))
+		copy(p2.Settings.RequireComment, p.Settings.RequireComment)
+	}
+	if p.Limits != nil {
+		limits := *p.Limits
+		p2.Limits = &limits
+	}
+	if p.AllowedVoters != nil {
+		p2.AllowedVoters = make([]string, len(p.AllowedVoters))
+		copy(p2.AllowedVoters, p.AllowedVoters)
+	}
+	if p.PendingOptions != nil {
+		p2.PendingOptions = make([]*PendingOption, len(p.PendingOptions))
+		for i, o := range p.PendingOptions {
+			o2 := *o
+			p2.PendingOptions[i] = &o2
+		}
+	}
+	if p.Votes != nil {
+		p2.Votes = make([]*Vote, len(p.Votes))
+		for i, v := range p.Votes {
+			v2 := *v
+			p2.Votes[i] = &v2
 		}
 	}
+	if p.AddedOptions != nil {
+		p2.AddedOptions = make(map[string]int, len(p.AddedOptions))
+		for userID, count := range p.AddedOptions {
+			p2.AddedOptions[userID] = count
+		}
+	}
+	if p.Broadcasts != nil {
+		p2.Broadcasts = make([]*BroadcastPost, len(p.Broadcasts))
+		for i, b := range p.Broadcasts {
+			b2 := *b
+			p2.Broadcasts[i] = &b2
+		}
+	}
+	if p.Comments != nil {
+		p2.Comments = make([]*Comment, len(p.Comments))
+		for i, c := range p.Comments {
+			c2 := *c
+			p2.Comments[i] = &c2
+		}
+	}
+	if p.Weights != nil {
+		p2.Weights = make(map[string]int, len(p.Weights))
+		for userID, weight := range p.Weights {
+			p2.Weights[userID] = weight
+		}
+	}
+	if p.Rankings != nil {
+		p2.Rankings = make(map[string][]int, len(p.Rankings))
+		for userID, ranking := range p.Rankings {
+			p2.Rankings[userID] = make([]int, len(ranking))
+			copy(p2.Rankings[userID], ranking)
+		}
+	}
+	if p.ResultsCallback != nil {
+		callback := *p.ResultsCallback
+		p2.ResultsCallback = &callback
+	}
+	return p2
+}
+
+// CloneWithNewID creates a fresh poll with the question, answer options and settings of the poll.
+// In contrast to Copy, the new poll gets a new ID, has no votes, is still running and isn't linked to a post.
+func (p *Poll) CloneWithNewID(creator string) *Poll {
+	p2 := p.Copy()
+	p2.ID = model.NewId()
+	p2.PostID = ""
+	p2.ChannelID = ""
+	p2.RootID = ""
+	p2.CreatedAt = model.GetMillis()
+	p2.Creator = creator
+	p2.EndedAt = 0
+	p2.RemindedAt = 0
+	p2.Version = 0
+	p2.ModifiedAt = p2.CreatedAt
+	p2.Ballots = nil
+	p2.BallotOrder = nil
+	p2.VoteCounts = nil
+	p2.Rankings = nil
+	p2.Votes = nil
+	p2.AddedOptions = nil
+	p2.ResultsCallback = nil
+	// The clone is posted to the same channels, but needs its own posts
+	for _, b := range p2.Broadcasts {
+		b.PostID = ""
+	}
+	// Weights read from an attribute are only valid for the voters of the original poll
+	if p2.Settings.WeightAttribute != "" {
+		p2.Weights = nil
+	}
 	return p2
 }
@@ -1,8 +1,11 @@
 package poll_test
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"bou.ke/monkey"
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -35,11 +38,12 @@ func TestNewPoll(t *testing.T) {
 		require.NotNil(t, p)
 		assert.Equal(testutils.GetPollID(), p.ID)
 		assert.Equal(int64(1234567890), p.CreatedAt)
+		assert.Equal(int64(1234567890), p.ModifiedAt)
 		assert.Equal(creator, p.Creator)
 		assert.Equal(question, p.Question)
-		assert.Equal(&poll.AnswerOption{Answer: answerOptions[0], Voter: []string{}}, p.AnswerOptions[0])
-		assert.Equal(&poll.AnswerOption{Answer: answerOptions[1], Voter: []string{}}, p.AnswerOptions[1])
-		assert.Equal(&poll.AnswerOption{Answer: answerOptions[2], Voter: []string{}}, p.AnswerOptions[2])
+		assert.Equal(&poll.AnswerOption{Answer: answerOptions[0]}, p.AnswerOptions[0])
+		assert.Equal(&poll.AnswerOption{Answer: answerOptions[1]}, p.AnswerOptions[1])
+		assert.Equal(&poll.AnswerOption{Answer: answerOptions[2]}, p.AnswerOptions[2])
 		assert.Equal(poll.Settings{Anonymous: true, Progress: true, PublicAddOption: true, MaxVotes: 3}, p.Settings)
 	})
 
@@ -64,6 +68,18 @@ func TestNewPoll(t *testing.T) {
 		assert.NotNil(err)
 	})
 
+	t.Run("error, question too long", func(t *testing.T) {
+		assert := assert.New(t)
+
+		question := strings.Repeat("a", poll.MaxQuestionLength+1)
+		p, errMsg := poll.NewPoll("userID1", question, []string{"Yes", "No"}, poll.Settings{MaxVotes: 1})
+
+		assert.Nil(p)
+		require.NotNil(t, errMsg)
+		assert.Equal("poll.question.tooLong", errMsg.Message.ID)
+		assert.Equal(map[string]interface{}{"Limit": poll.MaxQuestionLength, "Length": poll.MaxQuestionLength + 1}, errMsg.Data)
+	})
+
 	t.Run("error, duplicate option", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -78,6 +94,383 @@ func TestNewPoll(t *testing.T) {
 	})
 }
 
+func TestSettingsValidateCombination(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings     poll.Settings
+		ExpectedData map[string]interface{}
+	}{
+		"no settings": {
+			Settings:     poll.Settings{MaxVotes: 1},
+			ExpectedData: nil,
+		},
+		"valid combination": {
+			Settings:     poll.Settings{Anonymous: true, Progress: true, PublicAddOption: true, MaxVotes: 2, Quorum: 3, CloseOnQuorum: true, RevealOnEnd: true},
+			ExpectedData: nil,
+		},
+		"close-on-quorum without quorum": {
+			Settings: poll.Settings{MaxVotes: 1, CloseOnQuorum: true},
+			ExpectedData: map[string]interface{}{
+				"Setting":    "close-on-quorum",
+				"Dependency": "quorum=X",
+			},
+		},
+		"close-on-quorum with a percentage quorum": {
+			Settings:     poll.Settings{MaxVotes: 1, QuorumPercent: 60, CloseOnQuorum: true},
+			ExpectedData: nil,
+		},
+		"hold-for-quorum without quorum": {
+			Settings: poll.Settings{MaxVotes: 1, HoldForQuorum: true},
+			ExpectedData: map[string]interface{}{
+				"Setting":    "hold-for-quorum",
+				"Dependency": "quorum=X",
+			},
+		},
+		"reveal-on-end without anonymous": {
+			Settings: poll.Settings{MaxVotes: 1, RevealOnEnd: true},
+			ExpectedData: map[string]interface{}{
+				"Setting":    "reveal-on-end",
+				"Dependency": "anonymous",
+			},
+		},
+		"semi-anonymous without anonymous": {
+			Settings: poll.Settings{MaxVotes: 1, SemiAnonymous: true},
+			ExpectedData: map[string]interface{}{
+				"Setting":    "semi-anonymous",
+				"Dependency": "anonymous",
+			},
+		},
+		"approve-options without public-add-option": {
+			Settings: poll.Settings{MaxVotes: 1, ApproveOptions: true},
+			ExpectedData: map[string]interface{}{
+				"Setting":    "approve-options",
+				"Dependency": "public-add-option",
+			},
+		},
+		"approve-options with public-add-option": {
+			Settings:     poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true},
+			ExpectedData: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			errMsg := test.Settings.ValidateCombination()
+			if test.ExpectedData == nil {
+				assert.Nil(t, errMsg)
+				return
+			}
+			require.NotNil(t, errMsg)
+			assert.Equal(t, "poll.newPoll.settings.missingDependency", errMsg.Message.ID)
+			assert.Equal(t, test.ExpectedData, errMsg.Data)
+		})
+	}
+
+	t.Run("ranked with multiple votes", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 2, Ranked: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.conflict", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Setting": "ranked", "Conflict": "votes=X"}, errMsg.Data)
+	})
+
+	t.Run("ranked with unlimited votes", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: poll.UnlimitedVotes, Ranked: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "ranked", "Conflict": "votes=X"}, errMsg.Data)
+	})
+
+	t.Run("weights with ranked", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Weights: "user1:3", Ranked: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.conflict", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Setting": "weights=X", "Conflict": "ranked"}, errMsg.Data)
+	})
+
+	t.Run("weights from an attribute with anonymous", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, WeightAttribute: "shares", Anonymous: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "weights=X", "Conflict": "anonymous"}, errMsg.Data)
+		assert.Nil(t, poll.Settings{MaxVotes: 1, WeightAttribute: "shares", Anonymous: true, RevealOnEnd: true}.ValidateCombination())
+		assert.Nil(t, poll.Settings{MaxVotes: 1, Weights: "user1:3", Anonymous: true}.ValidateCombination())
+	})
+
+	t.Run("channels with reactions", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Channels: "dev", Reactions: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.conflict", errMsg.Message.ID)
+	})
+
+	t.Run("dm with in", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, DM: "user1", In: "dev"}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "dm=X", "Conflict": "in=X"}, errMsg.Data)
+	})
+
+	t.Run("dm with channels", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, DM: "user1", Channels: "dev"}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "dm=X", "Conflict": "channels=X"}, errMsg.Data)
+		assert.Nil(t, poll.Settings{MaxVotes: 1, In: "town-square", Channels: "dev"}.ValidateCombination())
+	})
+
+	t.Run("secret with progress", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Secret: true, Progress: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.conflict", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Setting": "secret", "Conflict": "progress"}, errMsg.Data)
+	})
+
+	t.Run("repeat without schedule", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Repeat: poll.RepeatWeekly}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "repeat=X", "Dependency": "schedule=X"}, errMsg.Data)
+	})
+
+	t.Run("remind without end", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Remind: 1000}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "remind=X", "Dependency": "end=X"}, errMsg.Data)
+		assert.Nil(t, poll.Settings{MaxVotes: 1, Remind: 1000, EndTime: 5000}.ValidateCombination())
+	})
+
+	t.Run("require-comment without comments", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, RequireComment: []int{2}}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "require-comment=X", "Dependency": "comments"}, errMsg.Data)
+		assert.Nil(t, poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{2}}.ValidateCombination())
+	})
+
+	t.Run("end before schedule", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, ScheduledAt: 2000, EndTime: 2000}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.endBeforeSchedule", errMsg.Message.ID)
+		assert.Nil(t, poll.Settings{MaxVotes: 1, ScheduledAt: 2000, EndTime: 3000}.ValidateCombination())
+	})
+
+	t.Run("secret with capacity", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Secret: true, Capacity: 2}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "secret", "Conflict": "capacity=X"}, errMsg.Data)
+	})
+
+	t.Run("secret with semi-anonymous", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Anonymous: true, SemiAnonymous: true, Secret: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "secret", "Conflict": "semi-anonymous"}, errMsg.Data)
+		assert.Nil(t, poll.Settings{MaxVotes: 1, Anonymous: true, RevealOnEnd: true, Secret: true}.ValidateCombination())
+	})
+
+	t.Run("quiz with multiple votes", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 2, Quiz: 1}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "quiz=X", "Conflict": "votes=X"}, errMsg.Data)
+	})
+
+	t.Run("quiz with ranked", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Ranked: true, Quiz: 1}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "quiz=X", "Conflict": "ranked"}, errMsg.Data)
+	})
+
+	t.Run("scale with multiple votes", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 2, ScaleMin: 1, ScaleMax: 5}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "scale=X", "Conflict": "votes=X"}, errMsg.Data)
+	})
+
+	t.Run("reactions with settings that hide the voters", func(t *testing.T) {
+		for conflict, settings := range map[string]poll.Settings{
+			"anonymous": {MaxVotes: 1, Reactions: true, Anonymous: true},
+			"secret":    {MaxVotes: 1, Reactions: true, Secret: true},
+			"ranked":    {MaxVotes: 1, Reactions: true, Ranked: true},
+		} {
+			errMsg := settings.ValidateCombination()
+			require.NotNil(t, errMsg)
+			assert.Equal(t, map[string]interface{}{"Setting": "reactions", "Conflict": conflict}, errMsg.Data)
+		}
+	})
+
+	t.Run("reactions with settings reactions can't follow", func(t *testing.T) {
+		for conflict, settings := range map[string]poll.Settings{
+			"shuffle":           {MaxVotes: 1, Reactions: true, Shuffle: true},
+			"require-comment=X": {MaxVotes: 1, Reactions: true, Comments: true, RequireComment: []int{1}},
+		} {
+			errMsg := settings.ValidateCombination()
+			require.NotNil(t, errMsg)
+			assert.Equal(t, map[string]interface{}{"Setting": "reactions", "Conflict": conflict}, errMsg.Data)
+		}
+	})
+
+	t.Run("allow-other with settings that generate or hide the options", func(t *testing.T) {
+		for conflict, settings := range map[string]poll.Settings{
+			"secret":  {MaxVotes: 1, AllowOther: true, Secret: true},
+			"scale=X": {MaxVotes: 1, AllowOther: true, ScaleMin: 1, ScaleMax: 5},
+		} {
+			errMsg := settings.ValidateCombination()
+			require.NotNil(t, errMsg)
+			assert.Equal(t, map[string]interface{}{"Setting": "allow-other", "Conflict": conflict}, errMsg.Data)
+		}
+
+		errMsg := poll.Settings{MaxVotes: 1, AllowOther: true, Meeting: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.meetingConflict", errMsg.Message.ID)
+	})
+
+	t.Run("shuffle with settings that order the options", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Shuffle: true, ScaleMin: 1, ScaleMax: 5}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "scale=X", "Conflict": "shuffle"}, errMsg.Data)
+
+		errMsg = poll.Settings{MaxVotes: 1, Shuffle: true, Meeting: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.meetingConflict", errMsg.Message.ID)
+	})
+
+	t.Run("NewPoll rejects conflicting settings", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1, CloseOnQuorum: true})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.settings.missingDependency", errMsg.Message.ID)
+	})
+}
+
+func TestNewPollWithDefaults(t *testing.T) {
+	answerOptions := []string{"Answer 1", "Answer 2"}
+
+	t.Run("default is reinforced", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithDefaults("userID1", "Question", answerOptions, []string{"anonymous"}, poll.Settings{Anonymous: true}, poll.Limits{})
+		require.Nil(t, errMsg)
+		require.NotNil(t, p)
+		assert.Equal(t, poll.Settings{Anonymous: true, MaxVotes: 1}, p.Settings)
+	})
+	t.Run("default is overridden", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithDefaults("userID1", "Question", answerOptions, []string{"no-anonymous", "progress"}, poll.Settings{Anonymous: true}, poll.Limits{})
+		require.Nil(t, errMsg)
+		require.NotNil(t, p)
+		assert.Equal(t, poll.Settings{Progress: true, MaxVotes: 1}, p.Settings)
+	})
+	t.Run("invalid setting", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithDefaults("userID1", "Question", answerOptions, []string{"invalid"}, poll.Settings{Anonymous: true}, poll.Limits{})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.unrecognizedSetting", errMsg.Message.ID)
+	})
+	t.Run("defaults are validated", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithDefaults("userID1", "Question", answerOptions, []string{}, poll.Settings{MaxVotes: 3}, poll.Limits{})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.votesettings.invalidSetting", errMsg.Message.ID)
+	})
+}
+
+func TestNewPollQuiz(t *testing.T) {
+	answerOptions := []string{"Answer 1", "Answer 2"}
+	t.Run("correct answer is an option", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", answerOptions, poll.Settings{MaxVotes: 1, Quiz: 2})
+		require.Nil(t, errMsg)
+		require.NotNil(t, p)
+		assert.Equal(t, 1, p.CorrectAnswer())
+	})
+	t.Run("correct answer isn't an option", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", answerOptions, poll.Settings{MaxVotes: 1, Quiz: 3})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.quizSettings.unknownOption", errMsg.Message.ID)
+	})
+	t.Run("no quiz", func(t *testing.T) {
+		assert.Equal(t, -1, testutils.GetPoll().CorrectAnswer())
+	})
+	t.Run("option that needs a comment isn't an option", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", answerOptions, poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{1, 3}})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.requireCommentSettings.unknownOption", errMsg.Message.ID)
+	})
+	t.Run("correct answer deleted", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quiz: 1})
+		p.AnswerOptions[0].Deleted = true
+		assert.Equal(t, -1, p.CorrectAnswer())
+	})
+}
+
+func TestNewPollAbstain(t *testing.T) {
+	t.Run("abstain option is added", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1, Abstain: true})
+		require.Nil(t, errMsg)
+		require.Len(t, p.AnswerOptions, 3)
+		assert.Equal(t, &poll.AnswerOption{Answer: poll.AbstainAnswer, Abstain: true}, p.AnswerOptions[2])
+		assert.False(t, p.AnswerOptions[0].Abstain)
+	})
+	t.Run("abstain option is already an option", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Abstain"}, poll.Settings{MaxVotes: 1, Abstain: true})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.duplicate", errMsg.Message.ID)
+	})
+	t.Run("abstain with ranked", func(t *testing.T) {
+		errMsg := poll.Settings{MaxVotes: 1, Abstain: true, Ranked: true}.ValidateCombination()
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Setting": "abstain", "Conflict": "ranked"}, errMsg.Data)
+	})
+}
+
+func TestNewPollNumberOfOptions(t *testing.T) {
+	makeOptions := func(n int) []string {
+		options := make([]string, n)
+		for i := range options {
+			options[i] = fmt.Sprintf("Answer %d", i+1)
+		}
+		return options
+	}
+
+	for name, test := range map[string]struct {
+		Options           []string
+		Reactions         bool
+		ExpectedMessageID string
+	}{
+		"zero options": {
+			Options:           []string{},
+			ExpectedMessageID: "poll.newPoll.tooFewOptions",
+		},
+		"one option": {
+			Options:           makeOptions(1),
+			ExpectedMessageID: "poll.newPoll.tooFewOptions",
+		},
+		"two options": {
+			Options:           makeOptions(poll.MinAnswerOptions),
+			ExpectedMessageID: "",
+		},
+		"twenty options": {
+			Options:           makeOptions(poll.MaxAnswerOptions),
+			ExpectedMessageID: "",
+		},
+		"twenty-one options": {
+			Options:           makeOptions(poll.MaxAnswerOptions + 1),
+			ExpectedMessageID: "poll.newPoll.tooManyOptions",
+		},
+		"ten options with reactions": {
+			Options:           makeOptions(poll.MaxReactionOptions),
+			Reactions:         true,
+			ExpectedMessageID: "",
+		},
+		"eleven options with reactions": {
+			Options:           makeOptions(poll.MaxReactionOptions + 1),
+			Reactions:         true,
+			ExpectedMessageID: "poll.newPoll.settings.reactionsTooManyOptions",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, errMsg := poll.NewPoll("userID1", "Question", test.Options, poll.Settings{MaxVotes: 1, Reactions: test.Reactions})
+			if test.ExpectedMessageID != "" {
+				assert.Nil(t, p)
+				require.NotNil(t, errMsg)
+				assert.Equal(t, test.ExpectedMessageID, errMsg.Message.ID)
+			} else {
+				assert.Nil(t, errMsg)
+				require.NotNil(t, p)
+				assert.Len(t, p.AnswerOptions, len(test.Options))
+			}
+		})
+	}
+}
+
 func TestNewSettingsFromStrings(t *testing.T) {
 	for name, test := range map[string]struct {
 		Strs             []string
@@ -94,6 +487,92 @@ func TestNewSettingsFromStrings(t *testing.T) {
 				MaxVotes:        1,
 			},
 		},
+		"unlimited votes": {
+			Strs:        []string{"votes=0"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: poll.UnlimitedVotes,
+			},
+		},
+		"multi without number": {
+			Strs:        []string{"multi"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: poll.UnlimitedVotes,
+			},
+		},
+		"allow-other setting": {
+			Strs:        []string{"allow-other"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				AllowOther: true,
+				MaxVotes:   1,
+			},
+		},
+		"shuffle setting": {
+			Strs:        []string{"shuffle"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Shuffle:  true,
+				MaxVotes: 1,
+			},
+		},
+		"thread-results setting": {
+			Strs:        []string{"thread-results"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				ThreadResults: true,
+				MaxVotes:      1,
+			},
+		},
+		"abstain setting": {
+			Strs:        []string{"abstain"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Abstain:  true,
+				MaxVotes: 1,
+			},
+		},
+		"comments setting": {
+			Strs:        []string{"comments"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Comments: true,
+				MaxVotes: 1,
+			},
+		},
+		"approve-options setting": {
+			Strs:        []string{"approve-options"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				ApproveOptions: true,
+				MaxVotes:       1,
+			},
+		},
+		"reactions setting": {
+			Strs:        []string{"reactions"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Reactions: true,
+				MaxVotes:  1,
+			},
+		},
+		"secret setting": {
+			Strs:        []string{"secret"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Secret:   true,
+				MaxVotes: 1,
+			},
+		},
+		"ranked setting": {
+			Strs:        []string{"ranked"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Ranked:   true,
+				MaxVotes: 1,
+			},
+		},
 		"full settings": {
 			Strs:        []string{"anonymous", "progress", "public-add-option", "votes=4"},
 			ShouldError: false,
@@ -124,630 +603,3426 @@ func TestNewSettingsFromStrings(t *testing.T) {
 				MaxVotes:        1,
 			},
 		},
-		"invalid setting": {
-			Strs:        []string{"anonymous", "progress", "public-add-option", "invalid"},
+		"quorum setting": {
+			Strs:        []string{"quorum=5"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Quorum:   5,
+			},
+		},
+		"close-on-quorum setting": {
+			Strs:        []string{"quorum=5", "close-on-quorum"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes:      1,
+				Quorum:        5,
+				CloseOnQuorum: true,
+			},
+		},
+		"invalid quorum setting, zero": {
+			Strs:        []string{"quorum=0"},
 			ShouldError: true,
 			ExpectedSettings: poll.Settings{
-				Anonymous:       true,
-				Progress:        true,
-				PublicAddOption: true,
-				MaxVotes:        1,
+				MaxVotes: 1,
 			},
 		},
-	} {
-		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
-
-			settings, errMsg := poll.NewSettingsFromStrings(test.Strs)
-			if test.ShouldError {
-				assert.NotNil(errMsg)
-			} else {
-				assert.Nil(errMsg)
-			}
-			assert.Equal(test.ExpectedSettings, settings)
-		})
-	}
-}
-
-func TestNewSettingsFromSubmission(t *testing.T) {
-	for name, test := range map[string]struct {
-		Submission       map[string]interface{}
-		ExpectedSettings poll.Settings
-	}{
-		"no settings": {
-			Submission: map[string]interface{}{},
+		"invalid quorum setting, negative": {
+			Strs:        []string{"quorum=-1"},
+			ShouldError: true,
 			ExpectedSettings: poll.Settings{
-				Anonymous:       false,
-				Progress:        false,
-				PublicAddOption: false,
-				MaxVotes:        1,
+				MaxVotes: 1,
 			},
 		},
-		"full settings": {
-			Submission: map[string]interface{}{
-				"setting-anonymous":         true,
-				"setting-progress":          true,
-				"setting-public-add-option": true,
-				"setting-multi":             float64(4),
+		"percentage quorum setting": {
+			Strs:        []string{"quorum=60%", "hold-for-quorum"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes:      1,
+				QuorumPercent: 60,
+				HoldForQuorum: true,
 			},
+		},
+		"invalid quorum setting, more than 100%": {
+			Strs:        []string{"quorum=101%"},
+			ShouldError: true,
 			ExpectedSettings: poll.Settings{
-				Anonymous:       true,
-				Progress:        true,
-				PublicAddOption: true,
-				MaxVotes:        4,
+				MaxVotes: 1,
 			},
 		},
-		"without votes settings": {
-			Submission: map[string]interface{}{
-				"setting-anonymous":         false,
-				"setting-progress":          false,
-				"setting-public-add-option": false,
+		"repeat setting": {
+			Strs:        []string{"repeat=weekly"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Repeat:   poll.RepeatWeekly,
+			},
+		},
+		"invalid repeat setting": {
+			Strs:        []string{"repeat=hourly"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
 			},
+		},
+		"voters setting, channel": {
+			Strs:        []string{"voters=channel"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Voters:   poll.VotersChannel,
+			},
+		},
+		"voters setting, users": {
+			Strs:        []string{"voters=@user1,user2"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Voters:   "user1,user2",
+			},
+		},
+		"invalid voters setting": {
+			Strs:        []string{"voters=@user1,,@user2"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"weights setting, users": {
+			Strs:        []string{"weights=@user1:3, user2:1"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Weights:  "user1:3,user2:1",
+			},
+		},
+		"weights setting, attribute": {
+			Strs:        []string{"weights=shares"},
+			ShouldError: false,
 			ExpectedSettings: poll.Settings{
-				Anonymous:       false,
-				Progress:        false,
-				PublicAddOption: false,
 				MaxVotes:        1,
+				WeightAttribute: "shares",
 			},
 		},
-	} {
-		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
-
-			settings := poll.NewSettingsFromSubmission(test.Submission)
-			assert.Equal(test.ExpectedSettings, settings)
-		})
-	}
+		"invalid weights setting, missing weight": {
+			Strs:        []string{"weights=@user1:3,@user2"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"invalid weights setting, zero": {
+			Strs:        []string{"weights=@user1:0"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"channels setting": {
+			Strs:        []string{"channels=~town-square, dev"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Channels: "town-square,dev",
+			},
+		},
+		"invalid channels setting": {
+			Strs:        []string{"channels=~dev,,~town-square"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"in setting": {
+			Strs:        []string{"in=~dev"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				In:       "dev",
+			},
+		},
+		"invalid in setting": {
+			Strs:        []string{"in=~dev,~town-square"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"locale setting": {
+			Strs:        []string{"locale=zh_CN"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Locale:   "zh-CN",
+			},
+		},
+		"invalid locale setting": {
+			Strs:        []string{"locale=de de"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"chart setting": {
+			Strs:        []string{"chart=pie"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Chart:    poll.ChartPie,
+			},
+		},
+		"invalid chart setting": {
+			Strs:        []string{"chart=line"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"board setting": {
+			Strs:        []string{"board=bq3ejd6a5ttgn3yn9ciuznrkbxh"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Board:    "bq3ejd6a5ttgn3yn9ciuznrkbxh",
+			},
+		},
+		"invalid board setting": {
+			Strs:        []string{"board=../teams"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"dm setting": {
+			Strs:        []string{"dm=@user1, user2"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				DM:       "user1,user2",
+			},
+		},
+		"invalid dm setting": {
+			Strs:        []string{"dm=@user1,@"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"capacity setting": {
+			Strs:        []string{"capacity=3"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Capacity: 3,
+			},
+		},
+		"invalid capacity setting, zero": {
+			Strs:        []string{"capacity=0"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"quiz setting": {
+			Strs:        []string{"quiz=2"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Quiz:     2,
+			},
+		},
+		"require-comment setting": {
+			Strs:        []string{"comments", "require-comment=2, 3"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes:       1,
+				Comments:       true,
+				RequireComment: []int{2, 3},
+			},
+		},
+		"invalid require-comment setting": {
+			Strs:        []string{"comments", "require-comment=2,x"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Comments: true,
+			},
+		},
+		"invalid quiz setting, zero": {
+			Strs:        []string{"quiz=0"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"scale setting": {
+			Strs:        []string{"scale=0-10"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				ScaleMin: 0,
+				ScaleMax: 10,
+			},
+		},
+		"invalid scale setting, reversed": {
+			Strs:        []string{"scale=5-1"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"remind setting": {
+			Strs:        []string{"remind=1h30m"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+				Remind:   90 * 60 * 1000,
+			},
+		},
+		"invalid remind setting, too short": {
+			Strs:        []string{"remind=30s"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"invalid remind setting, no duration": {
+			Strs:        []string{"remind=tomorrow"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"reveal-on-end setting": {
+			Strs:        []string{"anonymous", "reveal-on-end"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Anonymous:   true,
+				MaxVotes:    1,
+				RevealOnEnd: true,
+			},
+		},
+		"reveal-on-end setting implies anonymous": {
+			Strs:        []string{"reveal-on-end"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Anonymous:   true,
+				MaxVotes:    1,
+				RevealOnEnd: true,
+			},
+		},
+		"no-reveal-on-end keeps anonymous": {
+			Strs:        []string{"reveal-on-end", "no-reveal-on-end"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Anonymous: true,
+				MaxVotes:  1,
+			},
+		},
+		"semi-anonymous setting implies anonymous": {
+			Strs:        []string{"semi-anonymous"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Anonymous:     true,
+				MaxVotes:      1,
+				SemiAnonymous: true,
+			},
+		},
+		"no-semi-anonymous keeps anonymous": {
+			Strs:        []string{"semi-anonymous", "no-semi-anonymous"},
+			ShouldError: false,
+			ExpectedSettings: poll.Settings{
+				Anonymous: true,
+				MaxVotes:  1,
+			},
+		},
+		"flag setting with value": {
+			Strs:        []string{"anonymous=true"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"value setting without value": {
+			Strs:        []string{"votes"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				MaxVotes: 1,
+			},
+		},
+		"invalid setting": {
+			Strs:        []string{"anonymous", "progress", "public-add-option", "invalid"},
+			ShouldError: true,
+			ExpectedSettings: poll.Settings{
+				Anonymous:       true,
+				Progress:        true,
+				PublicAddOption: true,
+				MaxVotes:        1,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			settings, errMsg := poll.NewSettingsFromStrings(test.Strs)
+			if test.ShouldError {
+				assert.NotNil(errMsg)
+			} else {
+				assert.Nil(errMsg)
+			}
+			assert.Equal(test.ExpectedSettings, settings)
+		})
+	}
+}
+
+func TestNewSettingsFromStringsEndTime(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Strs            []string
+		ShouldError     bool
+		ExpectedEndTime int64
+	}{
+		"duration": {
+			Strs:            []string{"end=2h"},
+			ShouldError:     false,
+			ExpectedEndTime: 1234567890 + 2*60*60*1000,
+		},
+		"absolute time": {
+			Strs:            []string{"end=2021-10-01T15:00"},
+			ShouldError:     false,
+			ExpectedEndTime: 1633100400000,
+		},
+		"negative duration": {
+			Strs:        []string{"end=-1h"},
+			ShouldError: true,
+		},
+		"time in the past": {
+			Strs:        []string{"end=1970-01-01T00:00"},
+			ShouldError: true,
+		},
+		"invalid value": {
+			Strs:        []string{"end=tomorrow"},
+			ShouldError: true,
+		},
+		"without value": {
+			Strs:        []string{"end"},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			settings, errMsg := poll.NewSettingsFromStrings(test.Strs)
+			if test.ShouldError {
+				assert.NotNil(errMsg)
+			} else {
+				assert.Nil(errMsg)
+			}
+			assert.Equal(test.ExpectedEndTime, settings.EndTime)
+		})
+	}
+}
+
+func TestNewSettingsFromStringsSchedule(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Strs                []string
+		ShouldError         bool
+		ExpectedScheduledAt int64
+	}{
+		"duration": {
+			Strs:                []string{"schedule=30m"},
+			ShouldError:         false,
+			ExpectedScheduledAt: 1234567890 + 30*60*1000,
+		},
+		"absolute time": {
+			Strs:                []string{"schedule=2021-10-01T15:00"},
+			ShouldError:         false,
+			ExpectedScheduledAt: 1633100400000,
+		},
+		"time in the past": {
+			Strs:        []string{"schedule=1970-01-01T00:00"},
+			ShouldError: true,
+		},
+		"invalid value": {
+			Strs:        []string{"schedule=monday"},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			settings, errMsg := poll.NewSettingsFromStrings(test.Strs)
+			if test.ShouldError {
+				assert.NotNil(errMsg)
+			} else {
+				assert.Nil(errMsg)
+			}
+			assert.Equal(test.ExpectedScheduledAt, settings.ScheduledAt)
+		})
+	}
+}
+
+func TestNewSettingsFromStringsWithAliases(t *testing.T) {
+	for name, test := range map[string]struct {
+		Alias     []string
+		Canonical []string
+	}{
+		"anon": {
+			Alias:     []string{"anon"},
+			Canonical: []string{"anonymous"},
+		},
+		"multi": {
+			Alias:     []string{"multi=3"},
+			Canonical: []string{"votes=3"},
+		},
+		"multi without number": {
+			Alias:     []string{"multi"},
+			Canonical: []string{"votes=0"},
+		},
+		"mixed with canonical keywords": {
+			Alias:     []string{"anon", "progress", "multi=2"},
+			Canonical: []string{"anonymous", "progress", "votes=2"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			expected, errMsg := poll.NewSettingsFromStrings(test.Canonical)
+			require.Nil(t, errMsg)
+
+			settings, errMsg := poll.NewSettingsFromStrings(test.Alias)
+			require.Nil(t, errMsg)
+			assert.Equal(t, expected, settings)
+		})
+	}
+
+	t.Run("unknown keyword", func(t *testing.T) {
+		_, errMsg := poll.NewSettingsFromStrings([]string{"anonym"})
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.unrecognizedSetting", errMsg.Message.ID)
+		assert.Equal(t, "anonym", errMsg.Data["Setting"])
+	})
+	t.Run("alias with invalid value", func(t *testing.T) {
+		_, errMsg := poll.NewSettingsFromStrings([]string{"multi=abc"})
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.unrecognizedSetting", errMsg.Message.ID)
+		assert.Equal(t, "multi=abc", errMsg.Data["Setting"])
+	})
+}
+
+func TestNewPollWithLimits(t *testing.T) {
+	limits := poll.Limits{MaxQuestionLength: 10, MaxAnswerOptions: 3}
+
+	t.Run("within limits", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithLimits("userID1", strings.Repeat("a", 10), []string{"1", "2", "3"}, poll.Settings{MaxVotes: 1}, limits)
+		require.Nil(t, errMsg)
+		require.NotNil(t, p)
+	})
+	t.Run("question too long", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithLimits("userID1", strings.Repeat("a", 11), []string{"1", "2"}, poll.Settings{MaxVotes: 1}, limits)
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.question.tooLong", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Limit": 10, "Length": 11}, errMsg.Data)
+	})
+	t.Run("too many options", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithLimits("userID1", "Question", []string{"1", "2", "3", "4"}, poll.Settings{MaxVotes: 1}, limits)
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.tooManyOptions", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Max": 3, "Options": 4}, errMsg.Data)
+	})
+	t.Run("zero limits fall back to the defaults", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithLimits("userID1", strings.Repeat("a", poll.MaxQuestionLength), []string{"1", "2", "3", "4"}, poll.Settings{MaxVotes: 1}, poll.Limits{})
+		require.Nil(t, errMsg)
+		require.NotNil(t, p)
+		assert.Nil(t, p.Limits)
+	})
+	t.Run("limits are stored", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithLimits("userID1", "Question", []string{"1", "2"}, poll.Settings{MaxVotes: 1}, limits)
+		require.Nil(t, errMsg)
+		assert.Equal(t, &limits, p.Limits)
+	})
+	t.Run("option too long", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithLimits("userID1", "Question", []string{"1", strings.Repeat("投", 6)}, poll.Settings{MaxVotes: 1}, poll.Limits{MaxAnswerLength: 5})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.tooLong", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Limit": 5, "Length": 6}, errMsg.Data)
+	})
+	t.Run("option with line break", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithLimits("userID1", "Question", []string{"1", "2\n3"}, poll.Settings{MaxVotes: 1}, poll.Limits{})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.controlCharacter", errMsg.Message.ID)
+	})
+	t.Run("banned character", func(t *testing.T) {
+		banned := poll.Limits{BannedCharacters: "<>"}
+		p, errMsg := poll.NewPollWithLimits("userID1", "Question", []string{"1", "<2>"}, poll.Settings{MaxVotes: 1}, banned)
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.bannedCharacter", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Character": "<"}, errMsg.Data)
+
+		p, errMsg = poll.NewPollWithLimits("userID1", "Question>", []string{"1", "2"}, poll.Settings{MaxVotes: 1}, banned)
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, map[string]interface{}{"Character": ">"}, errMsg.Data)
+	})
+	t.Run("too many options with abstain", func(t *testing.T) {
+		p, errMsg := poll.NewPollWithLimits("userID1", "Question", []string{"1", "2", "3"}, poll.Settings{MaxVotes: 1, Abstain: true}, limits)
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.tooManyOptions", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Max": 3, "Options": 4}, errMsg.Data)
+	})
+}
+
+func TestPollLimitsApplyToChanges(t *testing.T) {
+	limits := poll.Limits{MaxQuestionLength: 10, MaxAnswerOptions: 3, MaxAnswerLength: 5, BannedCharacters: "#"}
+	newPoll := func(t *testing.T) *poll.Poll {
+		p, errMsg := poll.NewPollWithLimits("userID1", "Question", []string{"1", "2"}, poll.Settings{MaxVotes: 1}, limits)
+		require.Nil(t, errMsg)
+		return p
+	}
+
+	t.Run("add option", func(t *testing.T) {
+		p := newPoll(t)
+		errMsg := p.AddAnswerOption("123456")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.tooLong", errMsg.Message.ID)
+
+		assert.Nil(t, p.AddAnswerOption("3"))
+		errMsg = p.AddAnswerOption("4")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.tooManyOptions", errMsg.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Max": 3, "Options": 4}, errMsg.Data)
+	})
+	t.Run("update question", func(t *testing.T) {
+		p := newPoll(t)
+		errMsg := p.UpdateQuestion("Question #1")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.question.tooLong", errMsg.Message.ID)
+
+		errMsg = p.UpdateQuestion("#1")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.bannedCharacter", errMsg.Message.ID)
+	})
+	t.Run("edit", func(t *testing.T) {
+		p := newPoll(t)
+		errMsg := p.Update("Question", []string{"1", "#2"})
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.bannedCharacter", errMsg.Message.ID)
+		assert.Equal(t, "2", p.AnswerOptions[1].Answer)
+	})
+	t.Run("rename option", func(t *testing.T) {
+		p := newPoll(t)
+		errMsg := p.RenameAnswerOption("2", "123456")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.tooLong", errMsg.Message.ID)
+	})
+	t.Run("copy", func(t *testing.T) {
+		p := newPoll(t)
+		p2 := p.Copy()
+		assert.Equal(t, p.Limits, p2.Limits)
+		p2.Limits.MaxAnswerOptions = 10
+		assert.Equal(t, 3, p.Limits.MaxAnswerOptions)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	p := testutils.GetPoll()
+	assert.Nil(t, p.Validate())
+
+	p.AnswerOptions[0].Answer = strings.Repeat("a", poll.MaxAnswerLength+1)
+	errMsg := p.Validate()
+	require.NotNil(t, errMsg)
+	assert.Equal(t, "poll.addAnswerOption.tooLong", errMsg.Message.ID)
+
+	// Deleted options aren't shown, so their text isn't checked
+	p.AnswerOptions[0].Deleted = true
+	assert.Nil(t, p.Validate())
+
+	p.AnswerOptions[1].Deleted = true
+	errMsg = p.Validate()
+	require.NotNil(t, errMsg)
+	assert.Equal(t, "poll.newPoll.tooFewOptions", errMsg.Message.ID)
+	assert.Equal(t, map[string]interface{}{"Min": poll.MinAnswerOptions, "Options": 1}, errMsg.Data)
+}
+
+func TestNewSettingsFromStringsWithDefaults(t *testing.T) {
+	for name, test := range map[string]struct {
+		Strs             []string
+		Defaults         poll.Settings
+		ShouldError      bool
+		ExpectedSettings poll.Settings
+	}{
+		"zero value defaults": {
+			Strs:             []string{},
+			Defaults:         poll.Settings{},
+			ExpectedSettings: poll.Settings{MaxVotes: 1},
+		},
+		"defaults only": {
+			Strs:             []string{},
+			Defaults:         poll.Settings{Anonymous: true, MaxVotes: 2},
+			ExpectedSettings: poll.Settings{Anonymous: true, MaxVotes: 2},
+		},
+		"default reinforced": {
+			Strs:             []string{"anonymous", "progress"},
+			Defaults:         poll.Settings{Anonymous: true},
+			ExpectedSettings: poll.Settings{Anonymous: true, Progress: true, MaxVotes: 1},
+		},
+		"default overridden": {
+			Strs:             []string{"no-anonymous"},
+			Defaults:         poll.Settings{Anonymous: true},
+			ExpectedSettings: poll.Settings{MaxVotes: 1},
+		},
+		"default overridden using an alias": {
+			Strs:             []string{"no-anon"},
+			Defaults:         poll.Settings{Anonymous: true},
+			ExpectedSettings: poll.Settings{MaxVotes: 1},
+		},
+		"value default overridden": {
+			Strs:             []string{"votes=3"},
+			Defaults:         poll.Settings{MaxVotes: 2},
+			ExpectedSettings: poll.Settings{MaxVotes: 3},
+		},
+		"default votes dropped for a ranked poll": {
+			Strs:             []string{"ranked"},
+			Defaults:         poll.Settings{MaxVotes: 2},
+			ExpectedSettings: poll.Settings{Ranked: true, MaxVotes: 1},
+		},
+		"explicit votes kept for a ranked poll": {
+			Strs:             []string{"ranked", "votes=2"},
+			Defaults:         poll.Settings{MaxVotes: 3},
+			ExpectedSettings: poll.Settings{Ranked: true, MaxVotes: 2},
+		},
+		"default progress dropped for a secret poll": {
+			Strs:             []string{"secret"},
+			Defaults:         poll.Settings{Progress: true},
+			ExpectedSettings: poll.Settings{Secret: true, MaxVotes: 1},
+		},
+		"explicit progress kept for a secret poll": {
+			Strs:             []string{"secret", "progress"},
+			Defaults:         poll.Settings{Progress: true},
+			ExpectedSettings: poll.Settings{Secret: true, Progress: true, MaxVotes: 1},
+		},
+		"multi can be negated": {
+			Strs:             []string{"no-multi"},
+			Defaults:         poll.Settings{MaxVotes: 2},
+			ExpectedSettings: poll.Settings{MaxVotes: 1},
+		},
+		"unlimited votes kept for a ranked poll": {
+			Strs:             []string{"ranked", "multi"},
+			ExpectedSettings: poll.Settings{Ranked: true, MaxVotes: poll.UnlimitedVotes},
+		},
+		"value setting can't be negated": {
+			Strs:             []string{"no-votes"},
+			Defaults:         poll.Settings{MaxVotes: 2},
+			ShouldError:      true,
+			ExpectedSettings: poll.Settings{MaxVotes: 2},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			settings, errMsg := poll.NewSettingsFromStringsWithDefaults(test.Strs, test.Defaults)
+			if test.ShouldError {
+				assert.NotNil(t, errMsg)
+			} else {
+				assert.Nil(t, errMsg)
+			}
+			assert.Equal(t, test.ExpectedSettings, settings)
+		})
+	}
+}
+
+func TestNewSettingsFromSubmission(t *testing.T) {
+	for name, test := range map[string]struct {
+		Submission       map[string]interface{}
+		ExpectedSettings poll.Settings
+	}{
+		"no settings": {
+			Submission: map[string]interface{}{},
+			ExpectedSettings: poll.Settings{
+				Anonymous:       false,
+				Progress:        false,
+				PublicAddOption: false,
+				MaxVotes:        1,
+			},
+		},
+		"full settings": {
+			Submission: map[string]interface{}{
+				"setting-anonymous":         true,
+				"setting-progress":          true,
+				"setting-public-add-option": true,
+				"setting-multi":             float64(4),
+			},
+			ExpectedSettings: poll.Settings{
+				Anonymous:       true,
+				Progress:        true,
+				PublicAddOption: true,
+				MaxVotes:        4,
+			},
+		},
+		"reveal-on-end without anonymous": {
+			Submission: map[string]interface{}{
+				"setting-anonymous":     false,
+				"setting-reveal-on-end": true,
+			},
+			ExpectedSettings: poll.Settings{
+				Anonymous:   true,
+				RevealOnEnd: true,
+				MaxVotes:    1,
+			},
+		},
+		"without votes settings": {
+			Submission: map[string]interface{}{
+				"setting-anonymous":         false,
+				"setting-progress":          false,
+				"setting-public-add-option": false,
+			},
+			ExpectedSettings: poll.Settings{
+				Anonymous:       false,
+				Progress:        false,
+				PublicAddOption: false,
+				MaxVotes:        1,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			settings := poll.NewSettingsFromSubmission(test.Submission)
+			assert.Equal(test.ExpectedSettings, settings)
+		})
+	}
+}
+
+func TestAddAnswerOption(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption("new option")
+		assert.Nil(err)
+		assert.Equal("new option", p.AnswerOptions[len(p.AnswerOptions)-1].Answer)
+	})
+	t.Run("dublicant options", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption(p.AnswerOptions[0].Answer)
+		assert.NotNil(err)
+	})
+	t.Run("dublicant options with spaces", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption(fmt.Sprintf(" %s ", p.AnswerOptions[0].Answer))
+		assert.NotNil(err)
+	})
+	t.Run("empty options", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption("")
+		assert.NotNil(err)
+	})
+	t.Run("empty optinos with spaces", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption("  ")
+		assert.NotNil(err)
+	})
+	t.Run("no emoji left for reactions", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Reactions: true})
+		for i := len(p.AnswerOptions); i < poll.MaxReactionOptions; i++ {
+			assert.Nil(p.AddAnswerOption(fmt.Sprintf("Option %d", i)))
+		}
+
+		err := p.AddAnswerOption("new option")
+		assert.NotNil(err)
+		assert.Equal("poll.newPoll.settings.reactionsTooManyOptions", err.Message.ID)
+		assert.Len(p.AnswerOptions, poll.MaxReactionOptions)
+	})
+}
+
+func TestPollAddOtherAnswer(t *testing.T) {
+	t.Run("new answer", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+
+		require.Nil(t, p.AddOtherAnswer("userID5", " Something else "))
+		require.Len(t, p.AnswerOptions, 4)
+		assert.Equal(t, &poll.AnswerOption{Answer: "Something else", AddedBy: "userID5"}, p.AnswerOptions[3])
+		assert.Equal(t, []string{"userID5"}, p.Voters(3))
+	})
+	t.Run("vote moves to the new answer", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+
+		require.Nil(t, p.AddOtherAnswer("userID4", "Something else"))
+		assert.Empty(t, p.Voters(1))
+		assert.Equal(t, []string{"userID4"}, p.Voters(3))
+	})
+	t.Run("existing answer gets the vote", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+
+		require.Nil(t, p.AddOtherAnswer("userID5", "Answer 2"))
+		assert.Len(t, p.AnswerOptions, 3)
+		assert.Equal(t, []string{"userID4", "userID5"}, p.Voters(1))
+		assert.Empty(t, p.AnswerOptions[1].AddedBy)
+	})
+	t.Run("anonymous poll doesn't store the author", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true, Anonymous: true})
+
+		require.Nil(t, p.AddOtherAnswer("userID5", "Something else"))
+		assert.Empty(t, p.AnswerOptions[3].AddedBy)
+	})
+	t.Run("empty answer", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+		version := p.Version
+
+		err := p.AddOtherAnswer("userID5", "  ")
+		assert.True(t, errors.Is(err, poll.ErrInvalidAnswer))
+		assert.Len(t, p.AnswerOptions, 3)
+		assert.Equal(t, version, p.Version)
+	})
+	t.Run("user may not vote", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+		p.AllowedVoters = []string{"userID1"}
+		version := p.Version
+
+		err := p.AddOtherAnswer("userID5", "Something else")
+		assert.True(t, errors.Is(err, poll.ErrNotAllowed))
+		assert.Len(t, p.AnswerOptions, 3)
+		assert.Equal(t, version, p.Version)
+	})
+	t.Run("poll ended", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+		p.End()
+
+		err := p.AddOtherAnswer("userID5", "Something else")
+		assert.True(t, errors.Is(err, poll.ErrPollEnded))
+		assert.Len(t, p.AnswerOptions, 3)
+	})
+}
+
+func TestPollRecordAddedOption(t *testing.T) {
+	p := testutils.GetPoll()
+	version := p.Version
+	assert.Equal(t, 0, p.AddedOptionCount("userID2"))
+
+	p.RecordAddedOption("userID2")
+	p.RecordAddedOption("userID2")
+	p.RecordAddedOption("userID3")
+	assert.Equal(t, 2, p.AddedOptionCount("userID2"))
+	assert.Equal(t, 1, p.AddedOptionCount("userID3"))
+	assert.Equal(t, version, p.Version)
+
+	p2 := p.Copy()
+	p2.RecordAddedOption("userID3")
+	assert.Equal(t, 1, p.AddedOptionCount("userID3"))
+	assert.Equal(t, 2, p2.AddedOptionCount("userID3"))
+}
+
+func TestAddAnswerOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errs := p.AddAnswerOptions([]string{"new option 1", "new option 2"})
+		assert.Nil(errs)
+		assert.Len(p.AnswerOptions, 5)
+		assert.Equal("new option 1", p.AnswerOptions[3].Answer)
+		assert.Equal("new option 2", p.AnswerOptions[4].Answer)
+	})
+	t.Run("mixed valid, empty and duplicate options", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errs := p.AddAnswerOptions([]string{"new option 1", "", "new option 2", " new option 1 ", "Answer 1", "new option 3"})
+		require.Len(t, errs, 3)
+		assert.Equal(1, errs[0].Index)
+		assert.Equal("poll.addAnswerOption.empty", errs[0].ErrorMessage.Message.ID)
+		assert.Equal(3, errs[1].Index)
+		assert.Equal("poll.addAnswerOption.duplicate", errs[1].ErrorMessage.Message.ID)
+		assert.Equal(4, errs[2].Index)
+		assert.Equal("poll.addAnswerOption.duplicate", errs[2].ErrorMessage.Message.ID)
+
+		require.Len(t, p.AnswerOptions, 6)
+		assert.Equal("new option 1", p.AnswerOptions[3].Answer)
+		assert.Equal("new option 2", p.AnswerOptions[4].Answer)
+		assert.Equal("new option 3", p.AnswerOptions[5].Answer)
+	})
+}
+
+func TestRenameAnswerOption(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errMsg := p.RenameAnswerOption("Answer 1", " New Answer 1 ")
+		assert.Nil(t, errMsg)
+		assert.Equal(t, "New Answer 1", p.AnswerOptions[0].Answer)
+		assert.Equal(t, []string{"userID1", "userID2", "userID3"}, p.Voters(0))
+	})
+	t.Run("same name", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errMsg := p.RenameAnswerOption("Answer 1", "Answer 1")
+		assert.Nil(t, errMsg)
+		assert.Equal(t, testutils.GetPollWithVotes(), p)
+	})
+	t.Run("duplicate target", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errMsg := p.RenameAnswerOption("Answer 1", "Answer 2")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.duplicate", errMsg.Message.ID)
+		assert.Equal(t, testutils.GetPollWithVotes(), p)
+	})
+	t.Run("empty target", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errMsg := p.RenameAnswerOption("Answer 1", "  ")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.empty", errMsg.Message.ID)
+		assert.Equal(t, testutils.GetPollWithVotes(), p)
+	})
+	t.Run("unknown source", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errMsg := p.RenameAnswerOption("Answer 4", "New Answer 4")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.answerOption.notFound", errMsg.Message.ID)
+		assert.Equal(t, testutils.GetPollWithVotes(), p)
+	})
+}
+
+func TestPruneEmptyOptions(t *testing.T) {
+	for name, test := range map[string]struct {
+		AnswerOptions    []*poll.AnswerOption
+		Voters           [][]string
+		MaxVotes         int
+		ExpectedPruned   int
+		ExpectedOptions  []string
+		ExpectedMaxVotes int
+	}{
+		"prune zero-vote options": {
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+				{Answer: "Answer 4"},
+			},
+			Voters:           [][]string{{"a"}, {}, {"b"}},
+			MaxVotes:         1,
+			ExpectedPruned:   2,
+			ExpectedOptions:  []string{"Answer 1", "Answer 3"},
+			ExpectedMaxVotes: 1,
+		},
+		"nothing to prune": {
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+			},
+			Voters:           [][]string{{"a"}, {"b"}, {"c"}},
+			MaxVotes:         1,
+			ExpectedPruned:   0,
+			ExpectedOptions:  []string{"Answer 1", "Answer 2", "Answer 3"},
+			ExpectedMaxVotes: 1,
+		},
+		"don't prune below two options": {
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+				{Answer: "Answer 4"},
+			},
+			Voters:           [][]string{{}, {}, {}, {}},
+			MaxVotes:         1,
+			ExpectedPruned:   2,
+			ExpectedOptions:  []string{"Answer 1", "Answer 2"},
+			ExpectedMaxVotes: 1,
+		},
+		"keep options with votes when stopping at two options": {
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+			},
+			Voters:           [][]string{{}, {}, {"a"}},
+			MaxVotes:         1,
+			ExpectedPruned:   1,
+			ExpectedOptions:  []string{"Answer 1", "Answer 3"},
+			ExpectedMaxVotes: 1,
+		},
+		"clamp MaxVotes": {
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+				{Answer: "Answer 4"},
+			},
+			Voters:           [][]string{{"a"}, {"a"}, {}, {}},
+			MaxVotes:         4,
+			ExpectedPruned:   2,
+			ExpectedOptions:  []string{"Answer 1", "Answer 2"},
+			ExpectedMaxVotes: 2,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &poll.Poll{
+				Question:      "Question",
+				AnswerOptions: test.AnswerOptions,
+				Settings:      poll.Settings{PublicAddOption: true, MaxVotes: test.MaxVotes},
+			}
+			testutils.WithVoters(p, test.Voters...)
+
+			assert.Equal(t, test.ExpectedPruned, p.PruneEmptyOptions())
+			answers := []string{}
+			for _, o := range p.AnswerOptions {
+				answers = append(answers, o.Answer)
+			}
+			assert.Equal(t, test.ExpectedOptions, answers)
+			assert.Equal(t, test.ExpectedMaxVotes, p.Settings.MaxVotes)
+			if test.ExpectedPruned == 0 {
+				assert.Equal(t, 0, p.Version)
+			} else {
+				assert.Equal(t, 1, p.Version)
+			}
+		})
+	}
+
+	t.Run("rankings are updated", func(t *testing.T) {
+		p := &poll.Poll{
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+				{Answer: "Answer 4"},
+			},
+			Ballots:     map[string][]int{"a": {1, 3}, "b": {3}},
+			BallotOrder: []string{"a", "b"},
+			VoteCounts:  []int{0, 1, 0, 2},
+			Settings:    poll.Settings{MaxVotes: 1, Ranked: true},
+			Rankings:    map[string][]int{"a": {3, 1}, "b": {3}},
+		}
+
+		assert.Equal(t, 2, p.PruneEmptyOptions())
+		assert.Equal(t, map[string][]int{"a": {1, 0}, "b": {1}}, p.Rankings)
+	})
+	t.Run("correct answer of a quiz is updated", func(t *testing.T) {
+		p := testutils.WithVoters(&poll.Poll{
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+			},
+			Settings: poll.Settings{MaxVotes: 1, Quiz: 3},
+		}, []string{"a"}, []string{}, []string{"b"})
+
+		assert.Equal(t, 1, p.PruneEmptyOptions())
+		assert.Equal(t, 2, p.Settings.Quiz)
+		assert.Equal(t, 1, p.CorrectAnswer())
+		assert.Equal(t, "Answer 3", p.AnswerOptions[p.CorrectAnswer()].Answer)
+	})
+	t.Run("correct answer of a quiz without votes is kept", func(t *testing.T) {
+		p := testutils.WithVoters(&poll.Poll{
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+				{Answer: "Answer 4"},
+			},
+			Settings: poll.Settings{MaxVotes: 1, Quiz: 2},
+		}, []string{"a"}, []string{}, []string{"b"})
+
+		assert.Equal(t, 1, p.PruneEmptyOptions())
+		assert.Equal(t, []string{"Answer 1", "Answer 2", "Answer 3"}, []string{p.AnswerOptions[0].Answer, p.AnswerOptions[1].Answer, p.AnswerOptions[2].Answer})
+		assert.Equal(t, 2, p.Settings.Quiz)
+	})
+	t.Run("answer options that require a comment are updated", func(t *testing.T) {
+		p := testutils.WithVoters(&poll.Poll{
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+				{Answer: "Answer 4"},
+			},
+			Settings: poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{1, 2, 4}},
+		}, []string{"a"}, []string{}, []string{"b"}, []string{"c"})
+
+		assert.Equal(t, 1, p.PruneEmptyOptions())
+		assert.Equal(t, []int{1, 3}, p.Settings.RequireComment)
+		assert.True(t, p.RequiresComment(2))
+		assert.False(t, p.RequiresComment(1))
+	})
+	t.Run("last answer option that requires a comment is removed", func(t *testing.T) {
+		p := testutils.WithVoters(&poll.Poll{
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+			},
+			Settings: poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{3}},
+		}, []string{"a"}, []string{"b"})
+
+		assert.Equal(t, 1, p.PruneEmptyOptions())
+		assert.Nil(t, p.Settings.RequireComment)
+	})
+	t.Run("abstain option is kept", func(t *testing.T) {
+		p := testutils.WithVoters(&poll.Poll{
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
+				{Answer: poll.AbstainAnswer, Abstain: true},
+			},
+			Settings: poll.Settings{MaxVotes: 1, Abstain: true},
+		}, []string{"a"}, []string{"b"})
+
+		assert.Equal(t, 1, p.PruneEmptyOptions())
+		require.Len(t, p.AnswerOptions, 3)
+		assert.True(t, p.AnswerOptions[2].Abstain)
+	})
+	t.Run("options of a scale are kept", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", nil, poll.Settings{MaxVotes: 1, ScaleMin: 1, ScaleMax: 5})
+		require.Nil(t, errMsg)
+		require.NoError(t, p.UpdateVote("a", 0))
+
+		assert.Equal(t, 0, p.PruneEmptyOptions())
+		assert.Len(t, p.AnswerOptions, 5)
+		assert.Equal(t, 1, p.Version)
+	})
+}
+
+func TestOptionIndex(t *testing.T) {
+	p := testutils.GetPoll()
+
+	for name, test := range map[string]struct {
+		Answer   string
+		Expected int
+	}{
+		"first option": {
+			Answer:   "Answer 1",
+			Expected: 0,
+		},
+		"last option": {
+			Answer:   "Answer 3",
+			Expected: 2,
+		},
+		"absent option": {
+			Answer:   "Answer 4",
+			Expected: -1,
+		},
+		"whitespace variant": {
+			Answer:   "  Answer 2\t",
+			Expected: 1,
+		},
+		"different case": {
+			Answer:   "answer 2",
+			Expected: -1,
+		},
+		"empty answer": {
+			Answer:   "",
+			Expected: -1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, p.OptionIndex(test.Answer))
+		})
+	}
+}
+
+func TestActiveOptionIndex(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.AnswerOptions[1].Deleted = true
+
+		assert.Equal(t, 0, p.ActiveOptionIndex("userID1", 1))
+		assert.Equal(t, 2, p.ActiveOptionIndex("userID1", 2))
+		assert.Equal(t, -1, p.ActiveOptionIndex("userID1", 3))
+		assert.Equal(t, -1, p.ActiveOptionIndex("userID1", 0))
+		assert.Equal(t, -1, p.ActiveOptionIndex("userID1", -1))
+	})
+	t.Run("shuffled", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Shuffle: true})
+		p.AnswerOptions[1].Deleted = true
+
+		order := p.OptionOrder("userID1")
+		require.Len(t, order, 2)
+		assert.Equal(t, order[0], p.ActiveOptionIndex("userID1", 1))
+		assert.Equal(t, order[1], p.ActiveOptionIndex("userID1", 2))
+		assert.Equal(t, -1, p.ActiveOptionIndex("userID1", 3))
+		assert.Equal(t, -1, p.ActiveOptionIndex("userID1", 0))
+	})
+}
+
+func TestSoftDeleteOption(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errMsg := p.SoftDeleteOption("Answer 1")
+		assert.Nil(t, errMsg)
+		assert.True(t, p.AnswerOptions[0].Deleted)
+		assert.Equal(t, []string{"userID1", "userID2", "userID3"}, p.Voters(0))
+		assert.Len(t, p.AnswerOptions, 3)
+	})
+	t.Run("unknown option", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errMsg := p.SoftDeleteOption("Answer 4")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.answerOption.notFound", errMsg.Message.ID)
+		assert.Equal(t, testutils.GetPollWithVotes(), p)
+	})
+	t.Run("voting on a deleted option", func(t *testing.T) {
+		p := testutils.GetPoll()
+		require.Nil(t, p.SoftDeleteOption("Answer 2"))
+
+		err := p.UpdateVote("userID1", 1)
+		require.NotNil(t, err)
+		assert.True(t, errors.Is(err, poll.ErrOptionDeleted))
+		var voteErr *poll.VoteError
+		require.True(t, errors.As(err, &voteErr))
+		require.NotNil(t, voteErr.ErrorMessage)
+		assert.Equal(t, "poll.updateVote.optionDeleted", voteErr.ErrorMessage.Message.ID)
+		assert.Equal(t, []string{}, p.Voters(1))
+
+		assert.Nil(t, p.UpdateVote("userID1", 2))
+	})
+}
+
+func TestRestoreOption(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+		defer patch.Unpatch()
+		p := testutils.GetPollWithVotes()
+		require.Nil(t, p.SoftDeleteOption("Answer 1"))
+
+		errMsg := p.RestoreOption("Answer 1")
+		assert.Nil(t, errMsg)
+		expected := testutils.GetPollWithVotes()
+		expected.Version = 2
+		expected.ModifiedAt = 1234567890
+		assert.Equal(t, expected, p)
+		assert.Nil(t, p.UpdateVote("userID5", 0))
+	})
+	t.Run("unknown option", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		errMsg := p.RestoreOption("Answer 4")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.answerOption.notFound", errMsg.Message.ID)
+	})
+}
+
+func TestPollUpdate(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Question        string
+		Answers         []string
+		ExpectedPoll    func() *poll.Poll
+		ExpectedErrorID string
+	}{
+		"Rename and delete options": {
+			Question: "New Question",
+			Answers:  []string{" First ", "", "Answer 3"},
+			ExpectedPoll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.Question = "New Question"
+				p.AnswerOptions[0].Answer = "First"
+				p.AnswerOptions[1].Deleted = true
+				p.Version = 1
+				p.ModifiedAt = 1234567890
+				return p
+			},
+		},
+		"Swap options": {
+			Question: "Question",
+			Answers:  []string{"Answer 2", "Answer 1", "Answer 3"},
+			ExpectedPoll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.AnswerOptions[0].Answer = "Answer 2"
+				p.AnswerOptions[1].Answer = "Answer 1"
+				p.Version = 1
+				p.ModifiedAt = 1234567890
+				return p
+			},
+		},
+		"Nothing changed": {
+			Question:     "Question",
+			Answers:      []string{"Answer 1", "Answer 2", "Answer 3"},
+			ExpectedPoll: testutils.GetPollWithVotes,
+		},
+		"Duplicate options": {
+			Question:        "Question",
+			Answers:         []string{"Answer 1", "Answer 1", "Answer 3"},
+			ExpectedPoll:    testutils.GetPollWithVotes,
+			ExpectedErrorID: "poll.addAnswerOption.duplicate",
+		},
+		"Too few options": {
+			Question:        "Question",
+			Answers:         []string{"Answer 1", "", ""},
+			ExpectedPoll:    testutils.GetPollWithVotes,
+			ExpectedErrorID: "poll.newPoll.tooFewOptions",
+		},
+		"Question too long": {
+			Question:        strings.Repeat("a", poll.MaxQuestionLength+1),
+			Answers:         []string{"Answer 1", "Answer 2", "Answer 3"},
+			ExpectedPoll:    testutils.GetPollWithVotes,
+			ExpectedErrorID: "poll.question.tooLong",
+		},
+		"Wrong number of answers": {
+			Question:        "Question",
+			Answers:         []string{"Answer 1", "Answer 2"},
+			ExpectedPoll:    testutils.GetPollWithVotes,
+			ExpectedErrorID: "poll.update.optionCountMismatch",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotes()
+
+			errMsg := p.Update(test.Question, test.Answers)
+			if test.ExpectedErrorID != "" {
+				require.NotNil(t, errMsg)
+				assert.Equal(t, test.ExpectedErrorID, errMsg.Message.ID)
+			} else {
+				assert.Nil(t, errMsg)
+			}
+			assert.Equal(t, test.ExpectedPoll(), p)
+		})
+	}
+}
+
+func TestActiveOptions(t *testing.T) {
+	p := testutils.GetPollWithVotes()
+	assert.Equal(t, p.AnswerOptions, p.ActiveOptions())
+
+	require.Nil(t, p.SoftDeleteOption("Answer 2"))
+	active := p.ActiveOptions()
+	require.Len(t, active, 2)
+	assert.Equal(t, "Answer 1", active[0].Answer)
+	assert.Equal(t, "Answer 3", active[1].Answer)
+
+	require.Nil(t, p.SoftDeleteOption("Answer 1"))
+	require.Nil(t, p.SoftDeleteOption("Answer 3"))
+	assert.Equal(t, []*poll.AnswerOption{}, p.ActiveOptions())
+}
+
+func TestUpdateQuestion(t *testing.T) {
+	for name, test := range map[string]struct {
+		Question    string
+		ShouldError bool
+	}{
+		"at the limit": {
+			Question:    strings.Repeat("a", poll.MaxQuestionLength),
+			ShouldError: false,
+		},
+		"just over the limit": {
+			Question:    strings.Repeat("a", poll.MaxQuestionLength+1),
+			ShouldError: true,
+		},
+		"multibyte characters at the limit": {
+			Question:    strings.Repeat("投", poll.MaxQuestionLength),
+			ShouldError: false,
+		},
+		"multibyte characters over the limit": {
+			Question:    strings.Repeat("投", poll.MaxQuestionLength+1),
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPoll()
+
+			errMsg := p.UpdateQuestion(test.Question)
+			if test.ShouldError {
+				require.NotNil(t, errMsg)
+				assert.Equal(t, "poll.question.tooLong", errMsg.Message.ID)
+				assert.Equal(t, testutils.GetPoll().Question, p.Question)
+			} else {
+				assert.Nil(t, errMsg)
+				assert.Equal(t, test.Question, p.Question)
+			}
+		})
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	p1 := testutils.GetPollWithVotes()
+	p2, err := poll.DecodePollFromByte(p1.EncodeToByte(), true)
+	require.NoError(t, err)
+	assert.Equal(t, p1, p2)
+}
+
+func TestDecode(t *testing.T) {
+	for name, b := range map[string][]byte{
+		"empty":                 {},
+		"null":                  []byte("null"),
+		"no poll ID":            []byte(`{"Question": "Question"}`),
+		"invalid answer option": []byte(`{"ID": "pollID1", "AnswerOptions": "Answer 1"}`),
+		"no votes":              []byte(fmt.Sprintf(`{"ID": "pollID1", "schema_version": %d, "Settings": {"max_votes": 0}}`, poll.CurrentSchemaVersion)),
+		"negative votes":        []byte(fmt.Sprintf(`{"ID": "pollID1", "schema_version": %d, "Settings": {"max_votes": -2}}`, poll.CurrentSchemaVersion)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, err := poll.DecodePollFromByte(b, false)
+			assert.True(t, errors.Is(err, poll.ErrCorruptPoll))
+			assert.Nil(t, p)
+		})
+	}
+
+	t.Run("newer schema version", func(t *testing.T) {
+		p, err := poll.DecodePollFromByte([]byte(fmt.Sprintf(`{"ID": "pollID1", "schema_version": %d}`, poll.CurrentSchemaVersion+1)), false)
+		assert.Equal(t, poll.ErrNewerSchemaVersion, err)
+		assert.False(t, errors.Is(err, poll.ErrCorruptPoll))
+		assert.Nil(t, p)
+	})
+	t.Run("unlimited votes", func(t *testing.T) {
+		p, err := poll.DecodePollFromByte([]byte(fmt.Sprintf(`{"ID": "pollID1", "schema_version": %d, "Settings": {"max_votes": -1}}`, poll.CurrentSchemaVersion)), false)
+		require.NoError(t, err)
+		assert.True(t, p.Settings.HasUnlimitedVotes())
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		b := []byte(fmt.Sprintf(`{"ID": "pollID1", "schema_version": %d, "Settings": {"max_votes": 1}, "Unknown": true}`, poll.CurrentSchemaVersion))
+
+		p, err := poll.DecodePollFromByte(b, false)
+		require.NoError(t, err)
+		assert.Equal(t, "pollID1", p.ID)
+
+		p, err = poll.DecodePollFromByte(b, true)
+		assert.True(t, errors.Is(err, poll.ErrCorruptPoll))
+		assert.Nil(t, p)
+	})
+}
+
+func TestDecodeMigratesSchemaVersion(t *testing.T) {
+	t.Run("unversioned poll without number of votes", func(t *testing.T) {
+		p, err := poll.DecodePollFromByte([]byte(`{"ID": "pollID1", "Settings": {"Anonymous": true}}`), true)
+		require.NoError(t, err)
+		assert.Equal(t, poll.CurrentSchemaVersion, p.SchemaVersion)
+		assert.Equal(t, poll.Settings{Anonymous: true, MaxVotes: 1}, p.Settings)
+	})
+	t.Run("unversioned poll with number of votes", func(t *testing.T) {
+		p, err := poll.DecodePollFromByte([]byte(`{"ID": "pollID1", "Settings": {"max_votes": 2}}`), true)
+		require.NoError(t, err)
+		assert.Equal(t, poll.CurrentSchemaVersion, p.SchemaVersion)
+		assert.Equal(t, 2, p.Settings.MaxVotes)
+	})
+	t.Run("current poll is kept", func(t *testing.T) {
+		p1 := testutils.GetPollWithVotes()
+		b := p1.EncodeToByte()
+		p2, err := poll.DecodePollFromByte(b, true)
+		require.NoError(t, err)
+		assert.Equal(t, b, p2.EncodeToByte())
+	})
+	t.Run("voters of the answer options are indexed by voter", func(t *testing.T) {
+		p, err := poll.DecodePollFromByte([]byte(`{"ID": "pollID1", "schema_version": 1, "Settings": {"max_votes": 2},
+			"AnswerOptions": [{"Answer": "Answer 1", "Voter": ["b", "a"]}, {"Answer": "Answer 2", "Voter": []}, {"Answer": "Answer 3", "Voter": ["a"]}]}`), true)
+		require.NoError(t, err)
+		assert.Equal(t, poll.CurrentSchemaVersion, p.SchemaVersion)
+		assert.Equal(t, map[string][]int{"a": {0, 2}, "b": {0}}, p.Ballots)
+		assert.Equal(t, []string{"b", "a"}, p.BallotOrder)
+		assert.Equal(t, []int{2, 0, 1}, p.VoteCounts)
+		for _, o := range p.AnswerOptions {
+			assert.Nil(t, o.LegacyVoters)
+		}
+		assert.NotContains(t, string(p.EncodeToByte()), `"Voter"`)
+	})
+	t.Run("new polls have the current schema version", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1})
+		require.Nil(t, errMsg)
+		assert.Equal(t, poll.CurrentSchemaVersion, p.SchemaVersion)
+	})
+}
+
+func TestDecodeLegacySettings(t *testing.T) {
+	legacyPoll := func(settings string) []byte {
+		return []byte(`{
+			"ID": "1234567890abcdefghij",
+			"post_id": "postID1",
+			"CreatedAt": 1234567890,
+			"Creator": "userID1",
+			"Question": "Question",
+			"AnswerOptions": [
+				{"Answer": "Answer 1", "Voter": ["userID1", "userID2", "userID3"]},
+				{"Answer": "Answer 2", "Voter": ["userID4"]},
+				{"Answer": "Answer 3", "Voter": []}
+			],
+			"Settings": ` + settings + `
+		}`)
+	}
+
+	for name, test := range map[string]struct {
+		Settings         string
+		ExpectedSettings poll.Settings
+	}{
+		"All settings": {
+			Settings:         `"anonymous,progress,public-add-option,votes=2"`,
+			ExpectedSettings: poll.Settings{Anonymous: true, Progress: true, PublicAddOption: true, MaxVotes: 2},
+		},
+		"Whitespace and dashes": {
+			Settings:         `" --anonymous , progress,"`,
+			ExpectedSettings: poll.Settings{Anonymous: true, Progress: true, MaxVotes: 1},
+		},
+		"Empty string": {
+			Settings:         `""`,
+			ExpectedSettings: poll.Settings{MaxVotes: 1},
+		},
+		"Current format": {
+			Settings:         `{"Anonymous": true, "Progress": false, "PublicAddOption": false, "max_votes": 2}`,
+			ExpectedSettings: poll.Settings{Anonymous: true, MaxVotes: 2},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, err := poll.DecodePollFromByte(legacyPoll(test.Settings), true)
+			require.NoError(t, err)
+
+			assert.Equal(t, testutils.GetPollWithVotesAndSettings(test.ExpectedSettings), p)
+		})
+	}
+
+	t.Run("Unknown legacy setting", func(t *testing.T) {
+		p, err := poll.DecodePollFromByte(legacyPoll(`"anonymous,invalid"`), false)
+		assert.True(t, errors.Is(err, poll.ErrCorruptPoll))
+		assert.Nil(t, p)
+	})
+}
+
+func TestUpdateVote(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Poll          poll.Poll
+		UserID        string
+		Index         int
+		ExpectedPoll  poll.Poll
+		ExpectedError error
+	}{
+		"Negative Index": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+			},
+			UserID: "a",
+			Index:  -1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+			},
+			ExpectedError: poll.ErrInvalidIndex,
+		},
+		"To high Index": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+			},
+			UserID: "a",
+			Index:  2,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+			},
+			ExpectedError: poll.ErrInvalidIndex,
+		},
+		"Invalid userID": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+			},
+			UserID: "",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+			},
+			ExpectedError: poll.ErrInvalidUser,
+		},
+		"Idempotent": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+			},
+			UserID: "a",
+			Index:  0,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+				Votes:       []*poll.Vote{{UserID: "a", Answer: 0, CreatedAt: 1234567890}},
+				Version:     1,
+				ModifiedAt:  1234567890,
+			},
+			ExpectedError: nil,
+		},
+		"Valid Vote": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Ballots:     map[string][]int{"a": {1}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{0, 1},
+				Votes:       []*poll.Vote{{UserID: "a", Answer: 1, CreatedAt: 1234567890}},
+				Version:     1,
+				ModifiedAt:  1234567890,
+			},
+			ExpectedError: nil,
+		},
+		"Multi votes setting, first vote": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Settings: poll.Settings{MaxVotes: 2},
+			},
+			UserID: "a",
+			Index:  0,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+				Settings:    poll.Settings{MaxVotes: 2},
+				Votes:       []*poll.Vote{{UserID: "a", Answer: 0, CreatedAt: 1234567890}},
+				Version:     1,
+				ModifiedAt:  1234567890,
+			},
+			ExpectedError: nil,
+		},
+		"Multi votes setting, second vote": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+				Settings:    poll.Settings{MaxVotes: 2},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0, 1}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1, 1},
+				Settings:    poll.Settings{MaxVotes: 2},
+				Votes:       []*poll.Vote{{UserID: "a", Answer: 1, CreatedAt: 1234567890}},
+				Version:     1,
+				ModifiedAt:  1234567890,
+			},
+			ExpectedError: nil,
+		},
+		"Multi votes setting, duplicated vote error": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+				Settings:    poll.Settings{MaxVotes: 2},
+			},
+			UserID: "a",
+			Index:  0,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+				Settings:    poll.Settings{MaxVotes: 2},
+			},
+			ExpectedError: poll.ErrAlreadyVoted,
+		},
+		"Multi votes setting, with progress option, duplicated vote error": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+				Settings:    poll.Settings{Progress: true, MaxVotes: 2},
+			},
+			UserID: "a",
+			Index:  0,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1},
+				Settings:    poll.Settings{Progress: true, MaxVotes: 2},
+			},
+			ExpectedError: poll.ErrAlreadyVoted,
+		},
+		"Multi votes setting, exceed votes error": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0, 1}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1, 1},
+				Settings:    poll.Settings{MaxVotes: 2},
+			},
+			UserID: "a",
+			Index:  2,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0, 1}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1, 1},
+				Settings:    poll.Settings{MaxVotes: 2},
+			},
+			ExpectedError: poll.ErrNoVotesLeft,
+		},
+		"Multi votes setting, invalid user id error": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0, 1}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1, 1},
+				Settings:    poll.Settings{MaxVotes: 2},
+			},
+			UserID: "",
+			Index:  2,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0, 1}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1, 1},
+				Settings:    poll.Settings{MaxVotes: 2},
+			},
+			ExpectedError: poll.ErrInvalidUser,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := test.Poll.UpdateVote(test.UserID, test.Index)
+
+			if test.ExpectedError != nil {
+				require.NotNil(t, err)
+				assert.True(errors.Is(err, test.ExpectedError))
+
+				var voteErr *poll.VoteError
+				require.True(t, errors.As(err, &voteErr))
+				if test.ExpectedError == poll.ErrInvalidIndex || test.ExpectedError == poll.ErrInvalidUser {
+					assert.Nil(voteErr.ErrorMessage)
+				} else {
+					assert.NotNil(voteErr.ErrorMessage)
+				}
+			} else {
+				assert.Nil(err)
+			}
+			assert.Equal(test.ExpectedPoll, test.Poll)
+		})
+	}
+}
+
+func TestUpdateVoteNoVotesLeft(t *testing.T) {
+	p := &poll.Poll{
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+			{Answer: "Answer 3"},
+		},
+		Ballots:     map[string][]int{"a": {0, 2}, "b": {1}},
+		BallotOrder: []string{"a", "b"},
+		VoteCounts:  []int{1, 1, 1},
+		Settings:    poll.Settings{MaxVotes: 2},
+	}
+
+	err := p.UpdateVote("a", 1)
+	require.True(t, errors.Is(err, poll.ErrNoVotesLeft))
+	var voteErr *poll.VoteError
+	require.True(t, errors.As(err, &voteErr))
+	require.NotNil(t, voteErr.ErrorMessage)
+	assert.Equal(t, "poll.updateVote.maxVotes", voteErr.ErrorMessage.Message.ID)
+	assert.Equal(t, map[string]interface{}{"Votes": "Answer 1, Answer 3"}, voteErr.ErrorMessage.Data)
+}
+
+func TestToggleVote(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Settings        poll.Settings
+		EndedAt         int64
+		Index           int
+		ExpectedRemoved bool
+		ExpectedError   error
+		ExpectedVoters  [][]string
+	}{
+		"multi vote, remove vote": {
+			Settings:        poll.Settings{MaxVotes: 2},
+			Index:           0,
+			ExpectedRemoved: true,
+			ExpectedVoters:  [][]string{{}, {"b"}, {"a"}},
+		},
+		"multi vote, add vote": {
+			Settings:       poll.Settings{MaxVotes: 3},
+			Index:          1,
+			ExpectedVoters: [][]string{{"a"}, {"a", "b"}, {"a"}},
+		},
+		"multi vote, poll ended": {
+			Settings:       poll.Settings{MaxVotes: 2},
+			EndedAt:        1000,
+			Index:          0,
+			ExpectedError:  poll.ErrPollEnded,
+			ExpectedVoters: [][]string{{"a"}, {"b"}, {"a"}},
+		},
+		"single vote, vote again": {
+			Settings:       poll.Settings{MaxVotes: 1},
+			Index:          0,
+			ExpectedVoters: [][]string{{"a"}, {"b"}, {}},
+		},
+		"ranked, vote again": {
+			Settings:       poll.Settings{MaxVotes: 2, Ranked: true},
+			Index:          0,
+			ExpectedError:  poll.ErrAlreadyVoted,
+			ExpectedVoters: [][]string{{"a"}, {"b"}, {"a"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &poll.Poll{
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Ballots:     map[string][]int{"a": {0, 2}, "b": {1}},
+				BallotOrder: []string{"a", "b"},
+				VoteCounts:  []int{1, 1, 1},
+				Settings:    test.Settings,
+				EndedAt:     test.EndedAt,
+			}
+			if test.Settings.Ranked {
+				p.Rankings = map[string][]int{"a": {0, 2}, "b": {1}}
+			}
+
+			removed, err := p.ToggleVote("a", test.Index)
+			if test.ExpectedError != nil {
+				assert.True(t, errors.Is(err, test.ExpectedError))
+			} else {
+				require.Nil(t, err)
+			}
+			assert.Equal(t, test.ExpectedRemoved, removed)
+			for i := range p.AnswerOptions {
+				assert.Equal(t, test.ExpectedVoters[i], p.Voters(i))
+			}
+		})
+	}
+}
+
+func TestUpdateVoteCapacity(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	p := &poll.Poll{
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+		},
+		Ballots:     map[string][]int{"a": {0}, "b": {0}, "c": {1}},
+		BallotOrder: []string{"a", "b", "c"},
+		VoteCounts:  []int{2, 1},
+		Settings:    poll.Settings{MaxVotes: 1, Capacity: 2},
+	}
+
+	t.Run("full option", func(t *testing.T) {
+		err := p.UpdateVote("c", 0)
+		require.True(t, errors.Is(err, poll.ErrOptionFull))
+		var voteErr *poll.VoteError
+		require.True(t, errors.As(err, &voteErr))
+		require.NotNil(t, voteErr.ErrorMessage)
+		assert.Equal(t, "poll.updateVote.optionFull", voteErr.ErrorMessage.Message.ID)
+		assert.Equal(t, map[string]interface{}{"Capacity": 2}, voteErr.ErrorMessage.Data)
+		assert.Equal(t, []string{"c"}, p.Voters(1))
+	})
+	t.Run("vote again for full option", func(t *testing.T) {
+		require.Nil(t, p.UpdateVote("a", 0))
+		assert.Equal(t, []string{"b", "a"}, p.Voters(0))
+	})
+	t.Run("vote for option with free slots", func(t *testing.T) {
+		require.Nil(t, p.UpdateVote("a", 1))
+		assert.Equal(t, []string{"b"}, p.Voters(0))
+		assert.Equal(t, []string{"c", "a"}, p.Voters(1))
+	})
 }
 
-func TestAddAnswerOption(t *testing.T) {
-	assert := assert.New(t)
+func TestUpdateVoteRanked(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
 
-	t.Run("all fine", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+	p := &poll.Poll{
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+			{Answer: "Answer 3"},
+		},
+		Settings: poll.Settings{MaxVotes: 1, Ranked: true},
+	}
 
-		err := p.AddAnswerOption("new option")
-		assert.Nil(err)
-		assert.Equal("new option", p.AnswerOptions[len(p.AnswerOptions)-1].Answer)
+	require.Nil(t, p.UpdateVote("a", 2))
+	require.Nil(t, p.UpdateVote("a", 0))
+	require.Nil(t, p.UpdateVote("b", 1))
+	assert.Equal(t, map[string][]int{"a": {2, 0}, "b": {1}}, p.Rankings)
+	assert.Equal(t, []string{"a"}, p.Voters(0))
+	assert.Equal(t, []string{"b"}, p.Voters(1))
+	assert.Equal(t, []string{"a"}, p.Voters(2))
+
+	t.Run("already ranked", func(t *testing.T) {
+		err := p.UpdateVote("a", 2)
+		assert.True(t, errors.Is(err, poll.ErrAlreadyVoted))
+		assert.Equal(t, []int{2, 0}, p.Rankings["a"])
 	})
-	t.Run("dublicant options", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+	t.Run("reset removes ranking", func(t *testing.T) {
+		p.ResetVotes("a")
+		assert.Equal(t, map[string][]int{"b": {1}}, p.Rankings)
+		assert.Equal(t, []string{}, p.Voters(0))
+	})
+}
 
-		err := p.AddAnswerOption(p.AnswerOptions[0].Answer)
-		assert.NotNil(err)
+func TestUpdateVoteAllowedVoters(t *testing.T) {
+	t.Run("allowed user", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.SetAllowedVoters([]string{"a", "b"})
+
+		err := p.UpdateVote("a", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a"}, p.Voters(0))
 	})
-	t.Run("dublicant options with spaces", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+	t.Run("disallowed user", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.SetAllowedVoters([]string{"a", "b"})
 
-		err := p.AddAnswerOption(fmt.Sprintf(" %s ", p.AnswerOptions[0].Answer))
-		assert.NotNil(err)
+		err := p.UpdateVote("c", 0)
+		assert.True(t, errors.Is(err, poll.ErrNotAllowed))
+		var voteErr *poll.VoteError
+		require.True(t, errors.As(err, &voteErr))
+		assert.Equal(t, "poll.updateVote.notAllowed", voteErr.ErrorMessage.Message.ID)
+		assert.Equal(t, []string{}, p.Voters(0))
 	})
-	t.Run("empty options", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+	t.Run("empty list allows everyone", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.SetAllowedVoters([]string{})
 
-		err := p.AddAnswerOption("")
-		assert.NotNil(err)
+		err := p.UpdateVote("c", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c"}, p.Voters(0))
 	})
-	t.Run("empty optinos with spaces", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+}
 
-		err := p.AddAnswerOption("  ")
-		assert.NotNil(err)
+func TestSetAllowedVoters(t *testing.T) {
+	for name, test := range map[string]struct {
+		UserIDs               []string
+		ExpectedAllowedVoters []string
+	}{
+		"nil": {
+			UserIDs:               nil,
+			ExpectedAllowedVoters: nil,
+		},
+		"only empty IDs": {
+			UserIDs:               []string{"", " "},
+			ExpectedAllowedVoters: nil,
+		},
+		"duplicate and empty IDs": {
+			UserIDs:               []string{"a", "", "b", " a "},
+			ExpectedAllowedVoters: []string{"a", "b"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPoll()
+			p.SetAllowedVoters(test.UserIDs)
+			assert.Equal(t, test.ExpectedAllowedVoters, p.AllowedVoters)
+		})
+	}
+}
+
+func TestVoterUsernames(t *testing.T) {
+	assert.Nil(t, poll.Settings{}.VoterUsernames())
+	assert.Nil(t, poll.Settings{Voters: poll.VotersChannel}.VoterUsernames())
+	assert.Equal(t, []string{"user1", "user2"}, poll.Settings{Voters: "user1,user2"}.VoterUsernames())
+}
+
+func TestChannelNames(t *testing.T) {
+	assert.Nil(t, poll.Settings{}.ChannelNames())
+	assert.Equal(t, []string{"town-square", "dev"}, poll.Settings{Channels: "town-square,dev"}.ChannelNames())
+}
+
+func TestDMUsernames(t *testing.T) {
+	assert.Nil(t, poll.Settings{}.DMUsernames())
+	assert.Equal(t, []string{"user1", "user2"}, poll.Settings{DM: "user1,user2"}.DMUsernames())
+}
+
+func TestSetBroadcastChannels(t *testing.T) {
+	p := testutils.GetPoll()
+	p.SetBroadcastChannels("channelID1", []string{"channelID2", "", "channelID1", "channelID3", "channelID2"})
+	assert.Equal(t, []*poll.BroadcastPost{{ChannelID: "channelID2"}, {ChannelID: "channelID3"}}, p.Broadcasts)
+
+	p.SetBroadcastChannels("channelID1", []string{"channelID1"})
+	assert.Nil(t, p.Broadcasts)
+}
+
+func TestPostIDInChannel(t *testing.T) {
+	p := testutils.GetPoll()
+	p.PostID = "postID1"
+	p.ChannelID = "channelID1"
+	p.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}, {ChannelID: "channelID3"}}
+
+	assert.Equal(t, "postID1", p.PostIDInChannel("channelID1"))
+	assert.Equal(t, "postID2", p.PostIDInChannel("channelID2"))
+	assert.Equal(t, "postID1", p.PostIDInChannel("channelID3"))
+	assert.Equal(t, []*poll.BroadcastPost{
+		{ChannelID: "channelID1", PostID: "postID1"},
+		{ChannelID: "channelID2", PostID: "postID2"},
+		{ChannelID: "channelID3"},
+	}, p.Posts())
+}
+
+func TestWeightsByUsername(t *testing.T) {
+	assert.Nil(t, poll.Settings{}.WeightsByUsername())
+	assert.Nil(t, poll.Settings{WeightAttribute: "shares"}.WeightsByUsername())
+	assert.Equal(t, map[string]int{"user1": 3, "user2": 1}, poll.Settings{Weights: "user1:3,user2:1"}.WeightsByUsername())
+}
+
+func TestWeightedCounts(t *testing.T) {
+	t.Run("not weighted", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		assert.Nil(t, p.WeightedCounts())
+	})
+	t.Run("weighted", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Weights: "user1:5,user4:2"})
+		p.SetWeights(map[string]int{"userID1": 5, "userID4": 2, "userID5": 0})
+		assert.Equal(t, map[string]int{"userID1": 5, "userID4": 2}, p.Weights)
+		assert.Equal(t, []int{7, 2, 0}, p.WeightedCounts())
+	})
+	t.Run("hashed voters", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, MaxVotes: 1, Weights: "user1:5"})
+		p.SetVoterKey("key")
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		require.Nil(t, p.UpdateVote("userID2", 0))
+		p.SetWeights(map[string]int{"userID1": 5})
+		assert.Equal(t, []int{6, 0, 0}, p.WeightedCounts())
 	})
 }
 
-func TestEncodeDecode(t *testing.T) {
-	p1 := testutils.GetPollWithVotes()
-	p2 := poll.DecodePollFromByte(p1.EncodeToByte())
-	assert.Equal(t, p1, p2)
+func TestCanVote(t *testing.T) {
+	p := testutils.GetPoll()
+	assert.True(t, p.CanVote("a"))
+
+	p.SetAllowedVoters([]string{"a"})
+	assert.True(t, p.CanVote("a"))
+	assert.False(t, p.CanVote("b"))
 }
 
-func TestDecode(t *testing.T) {
-	p := poll.DecodePollFromByte([]byte{})
-	assert.Nil(t, p)
+func TestIsMultiVote(t *testing.T) {
+	for name, test := range map[string]struct {
+		MaxVotes int
+		Expected bool
+	}{
+		"MaxVotes 0":  {MaxVotes: 0, Expected: false},
+		"MaxVotes -2": {MaxVotes: -2, Expected: false},
+		"MaxVotes 1":  {MaxVotes: 1, Expected: false},
+		"MaxVotes 3":  {MaxVotes: 3, Expected: true},
+		"Unlimited":   {MaxVotes: poll.UnlimitedVotes, Expected: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: test.MaxVotes})
+			assert.Equal(t, test.Expected, p.IsMultiVote())
+		})
+	}
 }
 
-func TestUpdateVote(t *testing.T) {
+func TestUpdateVoteWithoutMaxVotes(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 0})
+
+	require.Nil(t, p.UpdateVote("a", 0))
+	require.Nil(t, p.UpdateVote("a", 1))
+	assert.Equal(t, []string{}, p.Voters(0))
+	assert.Equal(t, []string{"a"}, p.Voters(1))
+}
+
+func TestUpdateVoteWithUnlimitedVotes(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: poll.UnlimitedVotes})
+
+	for i := range p.AnswerOptions {
+		require.Nil(t, p.UpdateVote("a", i))
+	}
+	assert.Len(t, p.GetVotedAnswers("a"), len(p.AnswerOptions))
+
+	require.Nil(t, p.AddAnswerOption("New Answer"))
+	require.Nil(t, p.UpdateVote("a", len(p.AnswerOptions)-1))
+	assert.Len(t, p.GetVotedAnswers("a"), len(p.AnswerOptions))
+}
+
+func TestResetVotes(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
 	for name, test := range map[string]struct {
-		Poll          poll.Poll
-		UserID        string
-		Index         int
-		ExpectedPoll  poll.Poll
-		Error         bool
-		ReturnMessage bool
+		Poll         poll.Poll
+		UserID       string
+		ExpectedPoll poll.Poll
 	}{
-		"Negative Index": {
+		"Reset success, with votes": {
 			Poll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
 				},
+				Ballots:     map[string][]int{"a": {0, 1, 2}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1, 1, 1},
+				Settings:    poll.Settings{MaxVotes: 3},
 			},
 			UserID: "a",
-			Index:  -1,
 			ExpectedPoll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
 				},
+				Settings:   poll.Settings{MaxVotes: 3},
+				Version:    1,
+				ModifiedAt: 1234567890,
 			},
-			Error:         true,
-			ReturnMessage: false,
 		},
-		"To high Index": {
+		"Reset success, with no votes": {
 			Poll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
 				},
+				Settings: poll.Settings{MaxVotes: 3},
 			},
 			UserID: "a",
-			Index:  2,
 			ExpectedPoll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
 				},
+				Settings: poll.Settings{MaxVotes: 3},
 			},
-			Error:         true,
-			ReturnMessage: false,
 		},
-		"Invalid userID": {
+		"Reset success, with votes from multi user": {
 			Poll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
 				},
+				Ballots:     map[string][]int{"a": {0, 1, 2}, "b": {0}, "1": {2}, "z": {2}},
+				BallotOrder: []string{"a", "b", "1", "z"},
+				VoteCounts:  []int{2, 1, 3},
+				Settings:    poll.Settings{MaxVotes: 3},
 			},
-			UserID: "",
-			Index:  1,
+			UserID: "a",
 			ExpectedPoll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
 				},
+				Ballots:     map[string][]int{"b": {0}, "1": {2}, "z": {2}},
+				BallotOrder: []string{"b", "1", "z"},
+				VoteCounts:  []int{1, 0, 2},
+				Settings:    poll.Settings{MaxVotes: 3},
+				Version:     1,
+				ModifiedAt:  1234567890,
 			},
-			Error:         true,
-			ReturnMessage: false,
 		},
-		"Idempotent": {
+		"invalid user id": {
 			Poll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
 				},
+				Ballots:     map[string][]int{"a": {0, 1}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1, 1},
+				Settings:    poll.Settings{MaxVotes: 3},
 			},
-			UserID: "a",
-			Index:  0,
+			UserID: "",
 			ExpectedPoll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
 				},
+				Ballots:     map[string][]int{"a": {0, 1}},
+				BallotOrder: []string{"a"},
+				VoteCounts:  []int{1, 1},
+				Settings:    poll.Settings{MaxVotes: 3},
 			},
-			Error:         false,
-			ReturnMessage: false,
 		},
-		"Valid Vote": {
-			Poll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
-			},
-			UserID: "a",
-			Index:  1,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{}},
-					{Answer: "Answer 2",
-						Voter: []string{"a"}},
-				},
-			},
-			Error:         false,
-			ReturnMessage: false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			test.Poll.ResetVotes(test.UserID)
+			assert.Equal(test.ExpectedPoll, test.Poll)
+		})
+	}
+}
+
+func TestVotedAnswersText(t *testing.T) {
+	p := &poll.Poll{
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+			{Answer: "Answer 3"},
 		},
-		"Multi votes setting, first vote": {
+		Ballots:     map[string][]int{"a": {0, 2}, "b": {0}, "c": {1}},
+		BallotOrder: []string{"a", "b", "c"},
+		VoteCounts:  []int{2, 1, 1},
+		Settings:    poll.Settings{MaxVotes: 2},
+	}
+	assert.Equal(t, ":one: Answer 1, :three: Answer 3", p.VotedAnswersText("a"))
+	assert.Equal(t, ":two: Answer 2", p.VotedAnswersText("c"))
+	assert.Equal(t, "", p.VotedAnswersText("d"))
+
+	p.Settings.Shuffle = true
+	assert.Equal(t, "Answer 1, Answer 3", p.VotedAnswersText("a"))
+
+	p = &poll.Poll{Settings: poll.Settings{MaxVotes: 0}}
+	for i := 0; i < poll.MaxReactionOptions+1; i++ {
+		p.AnswerOptions = append(p.AnswerOptions, &poll.AnswerOption{Answer: fmt.Sprintf("Answer %d", i+1)})
+		p.SetVoters(i, "a")
+	}
+	text := p.VotedAnswersText("a")
+	assert.True(t, strings.HasPrefix(text, ":one: Answer 1, "))
+	assert.True(t, strings.HasSuffix(text, fmt.Sprintf(", Answer %d", poll.MaxReactionOptions+1)))
+}
+
+func TestGetMetadata(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll             poll.Poll
+		UserID           string
+		Permission       bool
+		ExpectedResponse *poll.Metadata
+	}{
+		"Voted an Answer": {
 			Poll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
 					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
 					{Answer: "Answer 3"},
 				},
-				Settings: poll.Settings{MaxVotes: 2},
+				Ballots:     map[string][]int{"a": {0}, "b": {1, 2}},
+				BallotOrder: []string{"a", "b"},
+				VoteCounts:  []int{1, 1, 1},
 			},
-			UserID: "a",
-			Index:  0,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-					{Answer: "Answer 3"},
-				},
-				Settings: poll.Settings{MaxVotes: 2},
+			UserID:     "a",
+			Permission: true,
+			ExpectedResponse: &poll.Metadata{
+				PollID:        testutils.GetPollID(),
+				UserID:        "a",
+				CanManagePoll: true,
+				VotedAnswers:  []string{"Answer 1"},
 			},
-			Error:         false,
-			ReturnMessage: false,
 		},
-		"Multi votes setting, second vote": {
+		"Voted two Answers": {
 			Poll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
 					{Answer: "Answer 3"},
 				},
-				Settings: poll.Settings{MaxVotes: 2},
+				Ballots:     map[string][]int{"a": {0}, "b": {1, 2}},
+				BallotOrder: []string{"a", "b"},
+				VoteCounts:  []int{1, 1, 1},
 			},
-			UserID: "a",
-			Index:  1,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-					{Answer: "Answer 3"},
-				},
-				Settings: poll.Settings{MaxVotes: 2},
+			UserID:     "b",
+			Permission: true,
+			ExpectedResponse: &poll.Metadata{
+				PollID:        testutils.GetPollID(),
+				UserID:        "b",
+				CanManagePoll: true,
+				VotedAnswers:  []string{"Answer 2", "Answer 3"},
 			},
-			Error:         false,
-			ReturnMessage: false,
 		},
-		"Multi votes setting, duplicated vote error": {
+		"Voted two Answers, with progress option": {
 			Poll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
 					{Answer: "Answer 3"},
 				},
-				Settings: poll.Settings{MaxVotes: 2},
-			},
-			UserID: "a",
-			Index:  0,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-					{Answer: "Answer 3"},
+				Ballots:     map[string][]int{"a": {0, 1}, "b": {1, 2}},
+				BallotOrder: []string{"a", "b"},
+				VoteCounts:  []int{1, 2, 1},
+				Settings: poll.Settings{
+					Progress: true,
 				},
-				Settings: poll.Settings{MaxVotes: 2},
 			},
-			Error:         false,
-			ReturnMessage: true,
+			UserID:     "b",
+			Permission: true,
+			ExpectedResponse: &poll.Metadata{
+				PollID:        testutils.GetPollID(),
+				UserID:        "b",
+				CanManagePoll: true,
+				VotedAnswers:  []string{"Answer 2 (2)", "Answer 3 (1)"},
+			},
 		},
-		"Multi votes setting, with progress option, duplicated vote error": {
+		"Voted no Answers": {
 			Poll: poll.Poll{
-				Question: "Question",
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
 					{Answer: "Answer 3"},
 				},
-				Settings: poll.Settings{Progress: true, MaxVotes: 2},
+				Ballots:     map[string][]int{"a": {0}, "b": {1, 2}},
+				BallotOrder: []string{"a", "b"},
+				VoteCounts:  []int{1, 1, 1},
 			},
-			UserID: "a",
-			Index:  0,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
+			UserID:     "c",
+			Permission: true,
+			ExpectedResponse: &poll.Metadata{
+				PollID:        testutils.GetPollID(),
+				UserID:        "c",
+				CanManagePoll: true,
+				VotedAnswers:  []string{},
+			}},
+		"Invalid userID": {
+			Poll: poll.Poll{
+				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
+					{Answer: "Answer 1"},
 					{Answer: "Answer 2"},
 					{Answer: "Answer 3"},
 				},
-				Settings: poll.Settings{Progress: true, MaxVotes: 2},
+				Ballots:     map[string][]int{"a": {0}, "b": {1, 2}},
+				BallotOrder: []string{"a", "b"},
+				VoteCounts:  []int{1, 1, 1},
+			},
+			UserID: "",
+			ExpectedResponse: &poll.Metadata{
+				PollID:        testutils.GetPollID(),
+				UserID:        "",
+				CanManagePoll: false,
+				VotedAnswers:  []string{},
 			},
-			Error:         false,
-			ReturnMessage: true,
 		},
-		"Multi votes setting, exceed votes error": {
-			Poll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-					{Answer: "Answer 3"},
-				},
-				Settings: poll.Settings{MaxVotes: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			metadata := test.Poll.GetMetadata(test.UserID, test.Permission)
+			assert.Equal(test.ExpectedResponse, metadata)
+		})
+	}
+}
+
+func TestGetResults(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings        poll.Settings
+		EndedAt         int64
+		ExpectedResults *poll.Results
+	}{
+		"votes hidden": {
+			Settings: poll.Settings{MaxVotes: 1},
+			ExpectedResults: &poll.Results{
+				PollID:  testutils.GetPollID(),
+				Answers: []string{"Answer 1", "Answer 2"},
 			},
-			UserID: "a",
-			Index:  2,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-					{Answer: "Answer 3"},
-				},
-				Settings: poll.Settings{MaxVotes: 2},
+		},
+		"progress": {
+			Settings: poll.Settings{MaxVotes: 1, Progress: true},
+			ExpectedResults: &poll.Results{
+				PollID:  testutils.GetPollID(),
+				Answers: []string{"Answer 1", "Answer 2"},
+				Votes:   []int{3, 1},
+				Voters:  4,
 			},
-			Error:         false,
-			ReturnMessage: true,
 		},
-		"Multi votes setting, invalid user id error": {
-			Poll: poll.Poll{
+		"ended": {
+			Settings: poll.Settings{MaxVotes: 1, Secret: true},
+			EndedAt:  1000,
+			ExpectedResults: &poll.Results{
+				PollID:  testutils.GetPollID(),
+				Answers: []string{"Answer 1", "Answer 2"},
+				Votes:   []int{3, 1},
+				Voters:  4,
+				Ended:   true,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotes()
+			p.Settings = test.Settings
+			p.EndedAt = test.EndedAt
+			p.AnswerOptions[2].Deleted = true
+
+			assert.Equal(t, test.ExpectedResults, p.GetResults())
+		})
+	}
+}
+
+func TestGetSummary(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings        poll.Settings
+		EndedAt         int64
+		UserID          string
+		ExpectedSummary *poll.Summary
+	}{
+		"votes hidden": {
+			Settings: poll.Settings{MaxVotes: 1, EndTime: 2000},
+			UserID:   "userID2",
+			ExpectedSummary: &poll.Summary{
+				PollID:      testutils.GetPollID(),
+				Question:    "Question",
+				Options:     []*poll.OptionSummary{{Answer: "Answer 1"}, {Answer: "Answer 2"}},
+				VotesHidden: true,
+				Status:      poll.StatusRunning,
+				CreatedAt:   1234567890,
+				EndTime:     2000,
+			},
+		},
+		"progress": {
+			Settings: poll.Settings{MaxVotes: 1, Progress: true},
+			UserID:   "userID2",
+			ExpectedSummary: &poll.Summary{
+				PollID:   testutils.GetPollID(),
 				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-					{Answer: "Answer 3"},
+				Options: []*poll.OptionSummary{
+					{Answer: "Answer 1", Votes: 3, VoterIDs: []string{"userID1", "userID2", "userID3"}},
+					{Answer: "Answer 2", Votes: 1, VoterIDs: []string{"userID4"}},
 				},
-				Settings: poll.Settings{MaxVotes: 2},
+				Voters:    4,
+				Status:    poll.StatusRunning,
+				CreatedAt: 1234567890,
 			},
-			UserID: "",
-			Index:  2,
-			ExpectedPoll: poll.Poll{
+		},
+		"anonymous": {
+			Settings: poll.Settings{MaxVotes: 1, Anonymous: true},
+			EndedAt:  1000,
+			UserID:   "userID2",
+			ExpectedSummary: &poll.Summary{
+				PollID:    testutils.GetPollID(),
+				Question:  "Question",
+				Options:   []*poll.OptionSummary{{Answer: "Answer 1", Votes: 3}, {Answer: "Answer 2", Votes: 1}},
+				Voters:    4,
+				Status:    poll.StatusEnded,
+				CreatedAt: 1234567890,
+				EndedAt:   1000,
+			},
+		},
+		"semi-anonymous, creator": {
+			Settings: poll.Settings{MaxVotes: 1, Anonymous: true, SemiAnonymous: true},
+			EndedAt:  1000,
+			UserID:   "userID1",
+			ExpectedSummary: &poll.Summary{
+				PollID:   testutils.GetPollID(),
 				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-					{Answer: "Answer 3"},
+				Options: []*poll.OptionSummary{
+					{Answer: "Answer 1", Votes: 3, VoterIDs: []string{"userID1", "userID2", "userID3"}},
+					{Answer: "Answer 2", Votes: 1, VoterIDs: []string{"userID4"}},
 				},
-				Settings: poll.Settings{MaxVotes: 2},
+				Voters:    4,
+				Status:    poll.StatusEnded,
+				CreatedAt: 1234567890,
+				EndedAt:   1000,
 			},
-			Error:         true,
-			ReturnMessage: false,
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
+			p := testutils.GetPollWithVotes()
+			p.Settings = test.Settings
+			p.EndedAt = test.EndedAt
+			p.AnswerOptions[2].Deleted = true
 
-			msg, err := test.Poll.UpdateVote(test.UserID, test.Index)
+			assert.Equal(t, test.ExpectedSummary, p.GetSummary(test.UserID))
+		})
+	}
+}
 
-			if test.Error {
-				assert.NotNil(err)
-			} else {
-				assert.Nil(err)
-			}
+func TestHasVoted(t *testing.T) {
+	p1 := &poll.Poll{Question: "Question",
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+		},
+		Ballots:     map[string][]int{"a": {0}},
+		BallotOrder: []string{"a"},
+		VoteCounts:  []int{1},
+	}
+	assert.True(t, p1.HasVoted("a"))
+	assert.False(t, p1.HasVoted("b"))
+}
 
-			if test.ReturnMessage {
-				assert.NotNil(msg)
-			} else {
-				assert.Nil(msg)
-			}
-			assert.Equal(test.ExpectedPoll, test.Poll)
+func TestHasVotedFor(t *testing.T) {
+	p := testutils.GetPollWithVotes()
+
+	for name, test := range map[string]struct {
+		UserID        string
+		Index         int
+		Expected      bool
+		ExpectedError error
+	}{
+		"voted for the option": {
+			UserID:   "userID4",
+			Index:    1,
+			Expected: true,
+		},
+		"voted for another option": {
+			UserID:   "userID1",
+			Index:    1,
+			Expected: false,
+		},
+		"didn't vote at all": {
+			UserID:   "userID5",
+			Index:    0,
+			Expected: false,
+		},
+		"index out of range": {
+			UserID:        "userID1",
+			Index:         3,
+			ExpectedError: poll.ErrInvalidIndex,
+		},
+		"negative index": {
+			UserID:        "userID1",
+			Index:         -1,
+			ExpectedError: poll.ErrInvalidIndex,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			voted, err := p.HasVotedFor(test.UserID, test.Index)
+			assert.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.Expected, voted)
 		})
 	}
 }
 
-func TestResetVotes(t *testing.T) {
+func TestTotalVotes(t *testing.T) {
+	assert.Equal(t, 0, testutils.GetPoll().TotalVotes())
+	assert.Equal(t, 4, testutils.GetPollWithVotes().TotalVotes())
+}
+
+func TestPercentages(t *testing.T) {
 	for name, test := range map[string]struct {
-		Poll         poll.Poll
-		UserID       string
-		ExpectedPoll poll.Poll
+		Poll                poll.Poll
+		ExpectedPercentages []float64
 	}{
-		"Reset success, with votes": {
+		"even split": {
 			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-					{Answer: "Answer 3", Voter: []string{"a"}},
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
 				},
-				Settings: poll.Settings{MaxVotes: 3},
+				Ballots:     map[string][]int{"a": {0}, "b": {0}, "c": {1}, "d": {1}},
+				BallotOrder: []string{"a", "b", "c", "d"},
+				VoteCounts:  []int{2, 2},
 			},
-			UserID: "a",
-			ExpectedPoll: poll.Poll{
-				ID: testutils.GetPollID(),
+			ExpectedPercentages: []float64{50, 50},
+		},
+		"empty poll": {
+			Poll: poll.Poll{
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{}},
-					{Answer: "Answer 2", Voter: []string{}},
-					{Answer: "Answer 3", Voter: []string{}},
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
 				},
-				Settings: poll.Settings{MaxVotes: 3},
 			},
+			ExpectedPercentages: []float64{0, 0, 0},
 		},
-		"Reset success, with no votes": {
+		"one option has all votes": {
 			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{}},
-					{Answer: "Answer 2", Voter: []string{}},
-					{Answer: "Answer 3", Voter: []string{}},
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
 				},
-				Settings: poll.Settings{MaxVotes: 3},
+				Ballots:     map[string][]int{"a": {1}, "b": {1}, "c": {1}},
+				BallotOrder: []string{"a", "b", "c"},
+				VoteCounts:  []int{0, 3},
 			},
-			UserID: "a",
-			ExpectedPoll: poll.Poll{
-				ID: testutils.GetPollID(),
+			ExpectedPercentages: []float64{0, 100, 0},
+		},
+		"unrounded": {
+			Poll: poll.Poll{
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{}},
-					{Answer: "Answer 2", Voter: []string{}},
-					{Answer: "Answer 3", Voter: []string{}},
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
 				},
-				Settings: poll.Settings{MaxVotes: 3},
+				Ballots:     map[string][]int{"a": {0}, "b": {1}, "c": {1}},
+				BallotOrder: []string{"a", "b", "c"},
+				VoteCounts:  []int{1, 2},
 			},
+			ExpectedPercentages: []float64{100.0 / 3, 200.0 / 3},
 		},
-		"Reset success, with votes from multi user": {
+		"abstentions are left out": {
 			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
 				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a", "b"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-					{Answer: "Answer 3", Voter: []string{"1", "a", "z"}},
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+					{Answer: "Abstain", Abstain: true},
 				},
-				Settings: poll.Settings{MaxVotes: 3},
+				Ballots:     map[string][]int{"a": {0}, "b": {1}, "c": {1}, "d": {1}, "e": {2}, "f": {2}},
+				BallotOrder: []string{"a", "b", "c", "d", "e", "f"},
+				VoteCounts:  []int{1, 3, 2},
 			},
-			UserID: "a",
-			ExpectedPoll: poll.Poll{
-				ID: testutils.GetPollID(),
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"b"}},
-					{Answer: "Answer 2", Voter: []string{}},
-					{Answer: "Answer 3", Voter: []string{"1", "z"}},
-				},
-				Settings: poll.Settings{MaxVotes: 3},
+			ExpectedPercentages: []float64{25, 75, 0},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedPercentages, test.Poll.Percentages())
+		})
+	}
+}
+
+func TestVoterCount(t *testing.T) {
+	p := &poll.Poll{
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+			{Answer: "Answer 3"},
+		},
+		Ballots:     map[string][]int{"a": {0, 1}, "b": {0}, "c": {1}},
+		BallotOrder: []string{"a", "b", "c"},
+		VoteCounts:  []int{2, 2},
+		Settings:    poll.Settings{MaxVotes: 2},
+	}
+	assert.Equal(t, 3, p.VoterCount())
+}
+
+func TestVotesByUser(t *testing.T) {
+	p := &poll.Poll{
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+			{Answer: "Answer 3"},
+		},
+		Ballots:     map[string][]int{"a": {0, 2}, "b": {0}, "c": {1}},
+		BallotOrder: []string{"a", "b", "c"},
+		VoteCounts:  []int{2, 1, 1},
+		Settings:    poll.Settings{MaxVotes: 2},
+	}
+	assert.Equal(t, map[string][]int{"a": {0, 2}, "b": {0}, "c": {1}}, p.VotesByUser())
+	assert.Equal(t, map[string][]int{}, (&poll.Poll{}).VotesByUser())
+}
+
+func TestVoteSummaryByUser(t *testing.T) {
+	t.Run("multi vote poll", func(t *testing.T) {
+		p := &poll.Poll{
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2"},
+				{Answer: "Answer 3"},
 			},
+			Ballots:     map[string][]int{"a": {0, 1, 2}, "b": {0}, "c": {0, 1}},
+			BallotOrder: []string{"a", "b", "c"},
+			VoteCounts:  []int{3, 2, 1},
+			Settings:    poll.Settings{MaxVotes: 3},
+		}
+		assert.Equal(t, map[string]int{"a": 3, "b": 1, "c": 2}, p.VoteSummaryByUser())
+	})
+	t.Run("poll without votes", func(t *testing.T) {
+		p := testutils.GetPoll()
+		assert.Equal(t, map[string]int{}, p.VoteSummaryByUser())
+	})
+	t.Run("anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1})
+		assert.Equal(t, map[string]int{}, p.VoteSummaryByUser())
+	})
+}
+
+func TestQuorumMet(t *testing.T) {
+	for name, test := range map[string]struct {
+		Voters   []string
+		Quorum   int
+		Expected bool
+	}{
+		"no quorum": {
+			Voters:   []string{},
+			Quorum:   0,
+			Expected: true,
+		},
+		"below quorum": {
+			Voters:   []string{"a", "b"},
+			Quorum:   3,
+			Expected: false,
+		},
+		"at quorum": {
+			Voters:   []string{"a", "b", "c"},
+			Quorum:   3,
+			Expected: true,
+		},
+		"above quorum": {
+			Voters:   []string{"a", "b", "c", "d"},
+			Quorum:   3,
+			Expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quorum: test.Quorum})
+			p.SetVoters(0, test.Voters...)
+			assert.Equal(t, test.Expected, p.QuorumMet())
+		})
+	}
+}
+
+func TestQuorumMetAbstain(t *testing.T) {
+	p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 2, Quorum: 2, Abstain: true})
+	require.Nil(t, errMsg)
+	p.SetVoters(0, "a", "b")
+	p.SetVoters(2, "b", "c", "d")
+
+	assert.Equal(t, 4, p.VoterCount())
+	assert.Equal(t, 2, p.QuorumVoterCount())
+	assert.True(t, p.QuorumMet())
+
+	p.SetVoters(0, "a")
+	assert.False(t, p.QuorumMet())
+}
+
+func TestSetQuorumFromEligible(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings poll.Settings
+		Eligible int
+		Expected int
+	}{
+		"absolute quorum": {
+			Settings: poll.Settings{MaxVotes: 1, Quorum: 3},
+			Eligible: 10,
+			Expected: 3,
+		},
+		"rounded up": {
+			Settings: poll.Settings{MaxVotes: 1, QuorumPercent: 60},
+			Eligible: 9,
+			Expected: 6,
+		},
+		"exact": {
+			Settings: poll.Settings{MaxVotes: 1, QuorumPercent: 50},
+			Eligible: 10,
+			Expected: 5,
+		},
+		"at least one voter": {
+			Settings: poll.Settings{MaxVotes: 1, QuorumPercent: 50},
+			Eligible: 0,
+			Expected: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(test.Settings)
+			p.SetQuorumFromEligible(test.Eligible)
+			assert.Equal(t, test.Expected, p.Settings.Quorum)
+		})
+	}
+}
+
+func TestEndPrevented(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings poll.Settings
+		Expected bool
+	}{
+		"without hold-for-quorum": {
+			Settings: poll.Settings{MaxVotes: 1, Quorum: 5},
+			Expected: false,
+		},
+		"quorum not reached": {
+			Settings: poll.Settings{MaxVotes: 1, Quorum: 5, HoldForQuorum: true},
+			Expected: true,
+		},
+		"quorum reached": {
+			Settings: poll.Settings{MaxVotes: 1, Quorum: 4, HoldForQuorum: true},
+			Expected: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotesAndSettings(test.Settings)
+			assert.Equal(t, test.Expected, p.EndPrevented())
+		})
+	}
+}
+
+func TestHidesVoters(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings poll.Settings
+		EndedAt  int64
+		Expected bool
+	}{
+		"not anonymous": {
+			Settings: poll.Settings{MaxVotes: 1},
+			Expected: false,
+		},
+		"not anonymous, ended": {
+			Settings: poll.Settings{MaxVotes: 1},
+			EndedAt:  1234567899,
+			Expected: false,
+		},
+		"anonymous": {
+			Settings: poll.Settings{Anonymous: true, MaxVotes: 1},
+			Expected: true,
+		},
+		"anonymous, ended": {
+			Settings: poll.Settings{Anonymous: true, MaxVotes: 1},
+			EndedAt:  1234567899,
+			Expected: true,
+		},
+		"reveal-on-end, before end": {
+			Settings: poll.Settings{Anonymous: true, RevealOnEnd: true, MaxVotes: 1},
+			Expected: true,
+		},
+		"reveal-on-end, after end": {
+			Settings: poll.Settings{Anonymous: true, RevealOnEnd: true, MaxVotes: 1},
+			EndedAt:  1234567899,
+			Expected: false,
+		},
+		"secret, before end": {
+			Settings: poll.Settings{Secret: true, MaxVotes: 1},
+			Expected: true,
+		},
+		"secret, after end": {
+			Settings: poll.Settings{Secret: true, MaxVotes: 1},
+			EndedAt:  1234567899,
+			Expected: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotesAndSettings(test.Settings)
+			p.EndedAt = test.EndedAt
+			assert.Equal(t, test.Expected, p.HidesVoters())
+		})
+	}
+}
+
+func TestShowsVotersTo(t *testing.T) {
+	creator := testutils.GetPoll().Creator
+	for name, test := range map[string]struct {
+		Settings poll.Settings
+		EndedAt  int64
+		UserID   string
+		Expected bool
+	}{
+		"not anonymous": {
+			Settings: poll.Settings{MaxVotes: 1},
+			UserID:   "userID2",
+			Expected: true,
+		},
+		"anonymous, creator": {
+			Settings: poll.Settings{Anonymous: true, MaxVotes: 1},
+			UserID:   creator,
+			Expected: false,
+		},
+		"semi-anonymous, creator": {
+			Settings: poll.Settings{Anonymous: true, SemiAnonymous: true, MaxVotes: 1},
+			UserID:   creator,
+			Expected: true,
+		},
+		"semi-anonymous, other user": {
+			Settings: poll.Settings{Anonymous: true, SemiAnonymous: true, MaxVotes: 1},
+			UserID:   "userID2",
+			Expected: false,
+		},
+		"semi-anonymous and secret, creator before end": {
+			Settings: poll.Settings{Anonymous: true, SemiAnonymous: true, Secret: true, MaxVotes: 1},
+			UserID:   creator,
+			Expected: false,
+		},
+		"semi-anonymous and secret, creator after end": {
+			Settings: poll.Settings{Anonymous: true, SemiAnonymous: true, Secret: true, MaxVotes: 1},
+			EndedAt:  1234567899,
+			UserID:   creator,
+			Expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotesAndSettings(test.Settings)
+			p.EndedAt = test.EndedAt
+			assert.Equal(t, test.Expected, p.ShowsVotersTo(test.UserID))
+		})
+	}
+}
+
+func TestHidesResults(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings poll.Settings
+		EndedAt  int64
+		Expected bool
+	}{
+		"not secret": {
+			Settings: poll.Settings{Anonymous: true, MaxVotes: 1},
+			Expected: false,
+		},
+		"secret, before end": {
+			Settings: poll.Settings{Secret: true, MaxVotes: 1},
+			Expected: true,
 		},
-		"invalid user id": {
-			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-				},
-				Settings: poll.Settings{MaxVotes: 3},
-			},
-			UserID: "",
-			ExpectedPoll: poll.Poll{
-				ID: testutils.GetPollID(),
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a"}},
-				},
-				Settings: poll.Settings{MaxVotes: 3},
-			},
+		"secret, after end": {
+			Settings: poll.Settings{Secret: true, MaxVotes: 1},
+			EndedAt:  1234567899,
+			Expected: false,
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
+			p := testutils.GetPollWithVotesAndSettings(test.Settings)
+			p.EndedAt = test.EndedAt
+			assert.Equal(t, test.Expected, p.HidesResults())
+		})
+	}
+}
 
-			test.Poll.ResetVotes(test.UserID)
-			assert.Equal(test.ExpectedPoll, test.Poll)
+func TestDeadlinePassed(t *testing.T) {
+	for name, test := range map[string]struct {
+		EndTime  int64
+		Now      int64
+		Expected bool
+	}{
+		"no end time": {
+			EndTime:  0,
+			Now:      1234567890,
+			Expected: false,
+		},
+		"before end time": {
+			EndTime:  1234567890,
+			Now:      1234567889,
+			Expected: false,
+		},
+		"at end time": {
+			EndTime:  1234567890,
+			Now:      1234567890,
+			Expected: true,
+		},
+		"after end time": {
+			EndTime:  1234567890,
+			Now:      1234567891,
+			Expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: test.EndTime})
+			assert.Equal(t, test.Expected, p.DeadlinePassed(test.Now))
 		})
 	}
 }
 
-func TestGetMetadata(t *testing.T) {
+func TestMaybeAutoClose(t *testing.T) {
+	t.Run("closes when quorum is reached", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quorum: 2, CloseOnQuorum: true})
+
+		require.Nil(t, p.UpdateVote("a", 0))
+		assert.False(t, p.MaybeAutoClose())
+		assert.False(t, p.HasEnded())
+
+		require.Nil(t, p.UpdateVote("b", 1))
+		assert.True(t, p.MaybeAutoClose())
+		assert.True(t, p.HasEnded())
+		assert.NotZero(t, p.EndedAt)
+	})
+	t.Run("already ended poll", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quorum: 1, CloseOnQuorum: true})
+		require.Nil(t, p.UpdateVote("a", 0))
+		p.EndedAt = 1234567899
+
+		assert.False(t, p.MaybeAutoClose())
+		assert.Equal(t, int64(1234567899), p.EndedAt)
+	})
+	t.Run("without close-on-quorum", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Quorum: 1})
+		require.Nil(t, p.UpdateVote("a", 0))
+
+		assert.False(t, p.MaybeAutoClose())
+		assert.False(t, p.HasEnded())
+	})
+	t.Run("without quorum", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, CloseOnQuorum: true})
+		require.Nil(t, p.UpdateVote("a", 0))
+
+		assert.False(t, p.MaybeAutoClose())
+		assert.False(t, p.HasEnded())
+	})
+}
+
+func TestEnd(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	p := testutils.GetPoll()
+	p.End()
+	assert.True(t, p.HasEnded())
+	assert.Equal(t, int64(1234567890), p.EndedAt)
+	assert.Equal(t, 1, p.Version)
+
+	p.End()
+	assert.Equal(t, 1, p.Version)
+
+	err := p.UpdateVote("userID1", 0)
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, poll.ErrPollEnded))
+}
+
+func TestReopen(t *testing.T) {
 	for name, test := range map[string]struct {
-		Poll             poll.Poll
-		UserID           string
-		Permission       bool
-		ExpectedResponse *poll.Metadata
+		EndedAt         int64
+		EndTime         int64
+		Now             int64
+		ExpectedErrorID string
 	}{
-		"Voted an Answer": {
-			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"b"}},
-					{Answer: "Answer 3", Voter: []string{"b"}},
-				},
-			},
-			UserID:     "a",
-			Permission: true,
-			ExpectedResponse: &poll.Metadata{
-				PollID:        testutils.GetPollID(),
-				UserID:        "a",
-				CanManagePoll: true,
-				VotedAnswers:  []string{"Answer 1"},
-			},
+		"within grace period": {
+			EndedAt: 1000,
+			Now:     1000 + 5*60*1000,
 		},
-		"Voted two Answers": {
-			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"b"}},
-					{Answer: "Answer 3", Voter: []string{"b"}},
-				},
-			},
-			UserID:     "b",
-			Permission: true,
-			ExpectedResponse: &poll.Metadata{
-				PollID:        testutils.GetPollID(),
-				UserID:        "b",
-				CanManagePoll: true,
-				VotedAnswers:  []string{"Answer 2", "Answer 3"},
-			},
+		"running poll": {
+			EndedAt:         0,
+			Now:             1000,
+			ExpectedErrorID: "poll.reopen.notEnded",
 		},
-		"Voted two Answers, with progress option": {
-			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"a", "b"}},
-					{Answer: "Answer 3", Voter: []string{"b"}},
-				},
-				Settings: poll.Settings{
-					Progress: true,
-				},
-			},
-			UserID:     "b",
-			Permission: true,
-			ExpectedResponse: &poll.Metadata{
-				PollID:        testutils.GetPollID(),
-				UserID:        "b",
-				CanManagePoll: true,
-				VotedAnswers:  []string{"Answer 2 (2)", "Answer 3 (1)"},
-			},
+		"grace period passed": {
+			EndedAt:         1000,
+			Now:             1000 + 10*60*1000,
+			ExpectedErrorID: "poll.reopen.gracePeriodPassed",
 		},
-		"Voted no Answers": {
-			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"b"}},
-					{Answer: "Answer 3", Voter: []string{"b"}},
-				},
-			},
-			UserID:     "c",
-			Permission: true,
-			ExpectedResponse: &poll.Metadata{
-				PollID:        testutils.GetPollID(),
-				UserID:        "c",
-				CanManagePoll: true,
-				VotedAnswers:  []string{},
-			}},
-		"Invalid userID": {
-			Poll: poll.Poll{
-				ID: testutils.GetPollID(),
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1", Voter: []string{"a"}},
-					{Answer: "Answer 2", Voter: []string{"b"}},
-					{Answer: "Answer 3", Voter: []string{"b"}},
-				},
-			},
-			UserID: "",
-			ExpectedResponse: &poll.Metadata{
-				PollID:        testutils.GetPollID(),
-				UserID:        "",
-				CanManagePoll: false,
-				VotedAnswers:  []string{},
-			},
+		"end time passed": {
+			EndedAt:         1000,
+			EndTime:         2000,
+			Now:             3000,
+			ExpectedErrorID: "poll.reopen.deadlinePassed",
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
+			p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: test.EndTime})
+			p.EndedAt = test.EndedAt
 
-			metadata := test.Poll.GetMetadata(test.UserID, test.Permission)
-			assert.Equal(test.ExpectedResponse, metadata)
+			errMsg := p.Reopen(test.Now, 10*time.Minute)
+			if test.ExpectedErrorID != "" {
+				require.NotNil(t, errMsg)
+				assert.Equal(t, test.ExpectedErrorID, errMsg.Message.ID)
+				assert.Equal(t, test.EndedAt, p.EndedAt)
+				return
+			}
+			require.Nil(t, errMsg)
+			assert.False(t, p.HasEnded())
+			assert.Nil(t, p.UpdateVote("userID1", 0))
 		})
 	}
 }
 
-func TestHasVoted(t *testing.T) {
-	p1 := &poll.Poll{Question: "Question",
-		AnswerOptions: []*poll.AnswerOption{
-			{Answer: "Answer 1", Voter: []string{"a"}},
-			{Answer: "Answer 2"},
+func TestReopenPeriodPassed(t *testing.T) {
+	p := testutils.GetPoll()
+	assert.False(t, p.ReopenPeriodPassed(1000, time.Minute))
+
+	p.EndedAt = 1000
+	assert.False(t, p.ReopenPeriodPassed(1000+59*1000, time.Minute))
+	assert.True(t, p.ReopenPeriodPassed(1000+60*1000, time.Minute))
+	assert.True(t, p.ReopenPeriodPassed(1000, 0))
+}
+
+func TestTransfer(t *testing.T) {
+	for name, test := range map[string]struct {
+		EndedAt         int64
+		Creator         string
+		ExpectedErrorID string
+	}{
+		"running poll": {
+			Creator: "userID2",
+		},
+		"ended poll": {
+			EndedAt:         1000,
+			Creator:         "userID2",
+			ExpectedErrorID: "poll.transfer.pollEnded",
+		},
+		"same creator": {
+			Creator:         "userID1",
+			ExpectedErrorID: "poll.transfer.sameCreator",
 		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPoll()
+			p.EndedAt = test.EndedAt
+
+			errMsg := p.Transfer(test.Creator)
+			if test.ExpectedErrorID != "" {
+				require.NotNil(t, errMsg)
+				assert.Equal(t, test.ExpectedErrorID, errMsg.Message.ID)
+				assert.Equal(t, "userID1", p.Creator)
+				return
+			}
+			require.Nil(t, errMsg)
+			assert.Equal(t, test.Creator, p.Creator)
+		})
 	}
-	assert.True(t, p1.HasVoted("a"))
-	assert.False(t, p1.HasVoted("b"))
+}
+
+func TestVersion(t *testing.T) {
+	t.Run("new poll", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1})
+		require.Nil(t, errMsg)
+		assert.Equal(t, 0, p.Version)
+	})
+	t.Run("mutating methods bump the version", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		assert.Equal(t, 1, p.Version)
+		require.Nil(t, p.AddAnswerOption("Answer 4"))
+		assert.Equal(t, 2, p.Version)
+		require.Nil(t, p.RenameAnswerOption("Answer 4", "Answer 5"))
+		assert.Equal(t, 3, p.Version)
+		require.Nil(t, p.UpdateQuestion("New Question"))
+		assert.Equal(t, 4, p.Version)
+		p.ResetVotes("userID1")
+		assert.Equal(t, 5, p.Version)
+	})
+	t.Run("failed mutations don't bump the version", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		require.NotNil(t, p.UpdateVote("userID1", 5))
+		require.NotNil(t, p.AddAnswerOption("Answer 1"))
+		p.ResetVotes("userID1")
+		assert.Equal(t, 0, p.Version)
+	})
+}
+
+func TestCompareAndApply(t *testing.T) {
+	t.Run("successful apply", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		errMsg, applied := p.CompareAndApply(0, func(p *poll.Poll) *poll.ErrorMessage {
+			return p.AddAnswerOption("Answer 4")
+		})
+		assert.True(t, applied)
+		assert.Nil(t, errMsg)
+		assert.Equal(t, 1, p.Version)
+		assert.Len(t, p.AnswerOptions, 4)
+	})
+	t.Run("direct modification bumps the version", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		errMsg, applied := p.CompareAndApply(0, func(p *poll.Poll) *poll.ErrorMessage {
+			p.Settings.Progress = true
+			return nil
+		})
+		assert.True(t, applied)
+		assert.Nil(t, errMsg)
+		assert.Equal(t, 1, p.Version)
+	})
+	t.Run("mutation fails", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		errMsg, applied := p.CompareAndApply(0, func(p *poll.Poll) *poll.ErrorMessage {
+			return p.AddAnswerOption("Answer 1")
+		})
+		assert.True(t, applied)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.addAnswerOption.duplicate", errMsg.Message.ID)
+		assert.Equal(t, testutils.GetPoll(), p)
+	})
+	t.Run("stale version", func(t *testing.T) {
+		p := testutils.GetPoll()
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		expected := p.Copy()
+
+		called := false
+		errMsg, applied := p.CompareAndApply(0, func(p *poll.Poll) *poll.ErrorMessage {
+			called = true
+			return p.AddAnswerOption("Answer 4")
+		})
+		assert.False(t, applied)
+		assert.Nil(t, errMsg)
+		assert.False(t, called)
+		assert.Equal(t, expected, p)
+	})
+}
+
+func TestModifiedAt(t *testing.T) {
+	t.Run("voting updates ModifiedAt", func(t *testing.T) {
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567899 })
+		defer patch.Unpatch()
+		p := testutils.GetPoll()
+
+		require.Nil(t, p.UpdateVote("userID1", 0))
+		assert.Equal(t, int64(1234567899), p.ModifiedAt)
+	})
+	t.Run("failed vote doesn't update ModifiedAt", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		require.NotNil(t, p.UpdateVote("userID1", 5))
+		assert.Equal(t, int64(0), p.ModifiedAt)
+	})
+	t.Run("Touch", func(t *testing.T) {
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567899 })
+		defer patch.Unpatch()
+		p := testutils.GetPoll()
+
+		p.Settings.Progress = true
+		p.Touch()
+		assert.Equal(t, int64(1234567899), p.ModifiedAt)
+		assert.Equal(t, 1, p.Version)
+	})
+	t.Run("Copy preserves ModifiedAt", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.ModifiedAt = 1234567899
+
+		assert.Equal(t, int64(1234567899), p.Copy().ModifiedAt)
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Run("stable for the same poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		assert.Equal(t, p.Fingerprint(), testutils.GetPollWithVotes().Fingerprint())
+		assert.Len(t, p.Fingerprint(), 64)
+	})
+	t.Run("reordering voters doesn't change the fingerprint", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p2 := p.Copy()
+		p2.SetVoters(0, "userID3", "userID1", "userID2")
+		assert.Equal(t, p.Fingerprint(), p2.Fingerprint())
+	})
+	t.Run("nil and empty ballots have the same fingerprint", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p2 := p.Copy()
+		p2.Ballots = map[string][]int{}
+		assert.Equal(t, p.Fingerprint(), p2.Fingerprint())
+	})
+	t.Run("adding a vote changes the fingerprint", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p2 := p.Copy()
+		require.Nil(t, p2.UpdateVote("userID5", 2))
+		assert.NotEqual(t, p.Fingerprint(), p2.Fingerprint())
+	})
+	t.Run("changing the settings changes the fingerprint", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p2 := p.Copy()
+		p2.Settings.Anonymous = true
+		assert.NotEqual(t, p.Fingerprint(), p2.Fingerprint())
+	})
+	t.Run("doesn't modify the poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.SetVoters(0, "userID3", "userID1", "userID2")
+		p.Fingerprint()
+		assert.Equal(t, []string{"userID3", "userID1", "userID2"}, p.Voters(0))
+	})
 }
 
 func TestPollCopy(t *testing.T) {
@@ -777,12 +4052,27 @@ func TestPollCopy(t *testing.T) {
 		assert.NotEqual(p, p2)
 		assert.Equal(testutils.GetPoll(), p2)
 	})
+	t.Run("deleted AnswerOption", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.AnswerOptions[0].Deleted = true
+		p2 := p.Copy()
+
+		assert.Equal(p, p2)
+		p.AnswerOptions[0].Deleted = false
+		assert.True(p2.AnswerOptions[0].Deleted)
+	})
+	t.Run("written in AnswerOption", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.AnswerOptions[2].AddedBy = "userID2"
+		p2 := p.Copy()
+
+		assert.Equal(p, p2)
+	})
 	t.Run("change Voter", func(t *testing.T) {
 		p := testutils.GetPollWithVotes()
 		p2 := p.Copy()
 
-		msg, err := p.UpdateVote("userID1", 0)
-		require.Nil(t, msg)
+		err := p.UpdateVote("userID1", 0)
 		require.NoError(t, err)
 		assert.NotEqual(p, p2)
 		assert.Equal(testutils.GetPollWithVotes(), p2)
@@ -796,4 +4086,122 @@ func TestPollCopy(t *testing.T) {
 		assert.NotEqual(p, p2)
 		assert.Equal(testutils.GetPoll(), p2)
 	})
+	t.Run("change AllowedVoters", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.SetAllowedVoters([]string{"a", "b"})
+		p2 := p.Copy()
+
+		p.AllowedVoters[0] = "c"
+		assert.Equal([]string{"a", "b"}, p2.AllowedVoters)
+	})
+	t.Run("change Rankings", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.Rankings = map[string][]int{"a": {1, 0}}
+		p2 := p.Copy()
+
+		p.Rankings["a"][0] = 2
+		p.Rankings["b"] = []int{0}
+		assert.Equal(map[string][]int{"a": {1, 0}}, p2.Rankings)
+	})
+	t.Run("change Weights", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.Weights = map[string]int{"a": 3}
+		p2 := p.Copy()
+
+		p.Weights["a"] = 1
+		assert.Equal(map[string]int{"a": 3}, p2.Weights)
+	})
+	t.Run("change Broadcasts", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}}
+		p2 := p.Copy()
+
+		p.Broadcasts[0].PostID = "postID3"
+		assert.Equal([]*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}}, p2.Broadcasts)
+	})
+	t.Run("change PendingOptions", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.PendingOptions = []*poll.PendingOption{{ID: "optionID1", Answer: "New Option", SuggestedBy: "userID2"}}
+		p2 := p.Copy()
+
+		assert.Equal(p, p2)
+		p.PendingOptions[0].Answer = "Other Option"
+		assert.Equal("New Option", p2.PendingOptions[0].Answer)
+	})
+	t.Run("change RequireComment", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Comments: true, RequireComment: []int{1, 3}})
+		p2 := p.Copy()
+
+		assert.Equal(p, p2)
+		p.Settings.RequireComment[0] = 2
+		assert.Equal([]int{1, 3}, p2.Settings.RequireComment)
+
+		p3 := p2.CloneWithNewID("userID2")
+		p2.Settings.RequireComment[1] = 2
+		assert.Equal([]int{1, 3}, p3.Settings.RequireComment)
+	})
+	t.Run("change ResultsCallback", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.ResultsCallback = &poll.ResultsCallback{PluginID: "pluginID1", Path: "/results"}
+		p2 := p.Copy()
+
+		assert.Equal(p, p2)
+		p.ResultsCallback.Path = "/other"
+		assert.Equal("/results", p2.ResultsCallback.Path)
+	})
+}
+
+func TestPollCloneWithNewID(t *testing.T) {
+	assert := assert.New(t)
+	patch1 := monkey.Patch(model.GetMillis, func() int64 { return 9876543210 })
+	patch2 := monkey.Patch(model.NewId, func() string { return "newPollID" })
+	defer patch1.Unpatch()
+	defer patch2.Unpatch()
+
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 2})
+	p.Version = 5
+	p.ModifiedAt = 1234567899
+	p.RemindedAt = 1234567898
+	p.ResultsCallback = &poll.ResultsCallback{PluginID: "pluginID1", Path: "/results"}
+	p2 := p.CloneWithNewID("userID2")
+	p.Version = 0
+	p.ModifiedAt = 0
+	p.RemindedAt = 0
+	p.ResultsCallback = nil
+
+	assert.Equal("newPollID", p2.ID)
+	assert.Equal("", p2.PostID)
+	assert.Equal(int64(9876543210), p2.CreatedAt)
+	assert.Equal(int64(9876543210), p2.ModifiedAt)
+	assert.Equal(0, p2.Version)
+	assert.Equal(int64(0), p2.RemindedAt)
+	assert.Nil(p2.ResultsCallback)
+	assert.Equal("userID2", p2.Creator)
+	assert.Equal(p.Question, p2.Question)
+	assert.Equal(p.Settings, p2.Settings)
+	require.Len(t, p2.AnswerOptions, len(p.AnswerOptions))
+	for i, o := range p2.AnswerOptions {
+		assert.Equal(p.AnswerOptions[i].Answer, o.Answer)
+	}
+	assert.Nil(p2.Ballots)
+
+	// The original poll is unchanged
+	assert.Equal(testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 2}), p)
+
+	t.Run("weights", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Weights: "user2:3"})
+		p.Weights = map[string]int{"userID2": 3}
+		assert.Equal(map[string]int{"userID2": 3}, p.CloneWithNewID("userID1").Weights)
+
+		p = testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, WeightAttribute: "shares"})
+		p.Weights = map[string]int{"userID2": 3}
+		assert.Nil(p.CloneWithNewID("userID1").Weights)
+	})
+	t.Run("broadcasts", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Channels: "dev"})
+		p.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}}
+
+		assert.Equal([]*poll.BroadcastPost{{ChannelID: "channelID2"}}, p.CloneWithNewID("userID1").Broadcasts)
+		assert.Equal("postID2", p.Broadcasts[0].PostID)
+	})
 }
@@ -0,0 +1,70 @@
+package poll
+
+// RunoffRound is a single round of an instant-runoff tabulation.
+type RunoffRound struct {
+	// Votes contains the number of votes of every answer option in this round, in the same order as AnswerOptions.
+	// Answer options that are deleted or have been eliminated in an earlier round have zero votes.
+	Votes []int
+	// Eliminated contains the indexes of the answer options that got eliminated after this round.
+	Eliminated []int
+}
+
+// InstantRunoff tabulates the rankings of a ranked poll.
+// In every round each user's vote counts for their most preferred answer option that hasn't been eliminated yet.
+// If an answer option gets more than half of the votes, it wins. Otherwise all answer options with the fewest votes
+// are eliminated and the next round starts. Deleted answer options are never counted.
+// It returns all rounds and the index of the winning answer option or -1, if there are no votes or
+// all remaining answer options are tied.
+func (p *Poll) InstantRunoff() ([]*RunoffRound, int) {
+	remaining := map[int]bool{}
+	for i, o := range p.AnswerOptions {
+		if !o.Deleted {
+			remaining[i] = true
+		}
+	}
+
+	rounds := []*RunoffRound{}
+	for len(remaining) > 0 {
+		round := &RunoffRound{Votes: make([]int, len(p.AnswerOptions))}
+		rounds = append(rounds, round)
+
+		total := 0
+		for _, ranking := range p.Rankings {
+			for _, i := range ranking {
+				if remaining[i] {
+					round.Votes[i]++
+					total++
+					break
+				}
+			}
+		}
+		if total == 0 {
+			return rounds, -1
+		}
+
+		fewest := total
+		for i := range remaining {
+			if round.Votes[i]*2 > total {
+				return rounds, i
+			}
+			if round.Votes[i] < fewest {
+				fewest = round.Votes[i]
+			}
+		}
+
+		for i := range p.AnswerOptions {
+			if remaining[i] && round.Votes[i] == fewest {
+				round.Eliminated = append(round.Eliminated, i)
+			}
+		}
+		if len(round.Eliminated) == len(remaining) {
+			// All remaining answer options are tied
+			round.Eliminated = nil
+			return rounds, -1
+		}
+		for _, i := range round.Eliminated {
+			delete(remaining, i)
+		}
+	}
+	return rounds, -1
+}
@@ -0,0 +1,90 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func TestInstantRunoff(t *testing.T) {
+	answerOptions := func() []*poll.AnswerOption {
+		return []*poll.AnswerOption{
+			{Answer: "Answer 1"},
+			{Answer: "Answer 2"},
+			{Answer: "Answer 3"},
+		}
+	}
+
+	for name, test := range map[string]struct {
+		Deleted        int
+		Rankings       map[string][]int
+		ExpectedRounds []*poll.RunoffRound
+		ExpectedWinner int
+	}{
+		"no votes": {
+			Deleted:        -1,
+			Rankings:       nil,
+			ExpectedRounds: []*poll.RunoffRound{{Votes: []int{0, 0, 0}}},
+			ExpectedWinner: -1,
+		},
+		"majority in first round": {
+			Deleted:        -1,
+			Rankings:       map[string][]int{"a": {0}, "b": {0, 1}, "c": {1}},
+			ExpectedRounds: []*poll.RunoffRound{{Votes: []int{2, 1, 0}}},
+			ExpectedWinner: 0,
+		},
+		"majority after elimination": {
+			Deleted: -1,
+			Rankings: map[string][]int{
+				"a": {0},
+				"b": {0},
+				"c": {1},
+				"d": {1},
+				"e": {2, 1},
+			},
+			ExpectedRounds: []*poll.RunoffRound{
+				{Votes: []int{2, 2, 1}, Eliminated: []int{2}},
+				{Votes: []int{2, 3, 0}},
+			},
+			ExpectedWinner: 1,
+		},
+		"users without remaining preferences": {
+			Deleted: -1,
+			Rankings: map[string][]int{
+				"a": {0},
+				"b": {0},
+				"c": {1},
+				"d": {1, 0},
+				"e": {2},
+			},
+			ExpectedRounds: []*poll.RunoffRound{
+				{Votes: []int{2, 2, 1}, Eliminated: []int{2}},
+				{Votes: []int{2, 2, 0}},
+			},
+			ExpectedWinner: -1,
+		},
+		"deleted options are skipped": {
+			Deleted:        0,
+			Rankings:       map[string][]int{"a": {0, 2}, "b": {1}, "c": {2}},
+			ExpectedRounds: []*poll.RunoffRound{{Votes: []int{0, 1, 2}}},
+			ExpectedWinner: 2,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &poll.Poll{
+				AnswerOptions: answerOptions(),
+				Settings:      poll.Settings{MaxVotes: 1, Ranked: true},
+				Rankings:      test.Rankings,
+			}
+			if test.Deleted != -1 {
+				p.AnswerOptions[test.Deleted].Deleted = true
+			}
+
+			rounds, winner := p.InstantRunoff()
+			assert.Equal(t, test.ExpectedRounds, rounds)
+			assert.Equal(t, test.ExpectedWinner, winner)
+		})
+	}
+}
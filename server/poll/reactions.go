@@ -0,0 +1,75 @@
+package poll
+
+// MaxReactionOptions is the maximum number of answer options of a poll that users vote in with reactions.
+const MaxReactionOptions = 10
+
+// reactionEmojis are the emojis users react with to vote for the answer option with the same index, see Settings.Reactions.
+// name is the name Mattermost uses for the emoji and keycap is the emoji itself, which is shown in the vote buttons.
+var reactionEmojis = [MaxReactionOptions]struct {
+	name   string
+	keycap string
+}{
+	{"one", "1️⃣"},
+	{"two", "2️⃣"},
+	{"three", "3️⃣"},
+	{"four", "4️⃣"},
+	{"five", "5️⃣"},
+	{"six", "6️⃣"},
+	{"seven", "7️⃣"},
+	{"eight", "8️⃣"},
+	{"nine", "9️⃣"},
+	{"keycap_ten", "\U0001f51f"},
+}
+
+// ReactionEmojis returns the names of the emojis users react with to vote for the answer options that aren't deleted,
+// in the order of the answer options. It returns nil if users don't vote with reactions.
+func (p *Poll) ReactionEmojis() []string {
+	var emojis []string
+	for i := range p.AnswerOptions {
+		if emoji := p.ReactionEmoji(i); emoji != "" {
+			emojis = append(emojis, emoji)
+		}
+	}
+	return emojis
+}
+
+// ReactionEmoji returns the name of the emoji users react with to vote for the answer option at index.
+// It returns an empty string if users don't vote with reactions or the answer option doesn't exist or is deleted.
+func (p *Poll) ReactionEmoji(index int) string {
+	if !p.Settings.Reactions || index < 0 || index >= len(p.AnswerOptions) || index >= MaxReactionOptions || p.AnswerOptions[index].Deleted {
+		return ""
+	}
+	return reactionEmojis[index].name
+}
+
+// AnswerOptionIndexFromReaction returns the index of the answer option users vote for by reacting with the emoji.
+// It returns -1 if users don't vote with reactions or the emoji doesn't belong to an answer option.
+func (p *Poll) AnswerOptionIndexFromReaction(emojiName string) int {
+	if !p.Settings.Reactions {
+		return -1
+	}
+	for i, e := range reactionEmojis {
+		if e.name == emojiName && i < len(p.AnswerOptions) {
+			return i
+		}
+	}
+	return -1
+}
+
+// numberEmoji returns the emoji with the number of the answer option at index, e.g. ":one:", independent of
+// whether users vote with reactions. It returns an empty string if there is no emoji for the number.
+func numberEmoji(index int) string {
+	if index < 0 || index >= MaxReactionOptions {
+		return ""
+	}
+	return ":" + reactionEmojis[index].name + ":"
+}
+
+// reactionKeycap returns the emoji users react with to vote for the answer option at index.
+// It returns an empty string if users don't vote with reactions.
+func (p *Poll) reactionKeycap(index int) string {
+	if p.ReactionEmoji(index) == "" {
+		return ""
+	}
+	return reactionEmojis[index].keycap
+}
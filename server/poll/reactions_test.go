@@ -0,0 +1,88 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollReactionEmojis(t *testing.T) {
+	t.Run("reactions", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Reactions: true})
+		p.AnswerOptions[1].Deleted = true
+
+		assert.Equal(t, []string{"one", "three"}, p.ReactionEmojis())
+		assert.Equal(t, "one", p.ReactionEmoji(0))
+		assert.Equal(t, "", p.ReactionEmoji(1))
+		assert.Equal(t, "", p.ReactionEmoji(3))
+		assert.Equal(t, "", p.ReactionEmoji(-1))
+	})
+	t.Run("no reactions", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		assert.Nil(t, p.ReactionEmojis())
+		assert.Equal(t, "", p.ReactionEmoji(0))
+	})
+}
+
+func TestPollAnswerOptionIndexFromReaction(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Reactions: true})
+
+	assert.Equal(t, 0, p.AnswerOptionIndexFromReaction("one"))
+	assert.Equal(t, 2, p.AnswerOptionIndexFromReaction("three"))
+	assert.Equal(t, -1, p.AnswerOptionIndexFromReaction("four"))
+	assert.Equal(t, -1, p.AnswerOptionIndexFromReaction("smile"))
+	assert.Equal(t, -1, testutils.GetPoll().AnswerOptionIndexFromReaction("one"))
+}
+
+func TestPollToPostActionsReactions(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Reactions: true})
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "1️⃣ Answer 1", attachments[0].Actions[0].Name)
+	assert.Equal(t, "3️⃣ Answer 3", attachments[0].Actions[2].Name)
+	assert.Contains(t, attachments[0].Text, "reactions")
+}
+
+func TestPollRemoveVote(t *testing.T) {
+	t.Run("remove vote", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		version := p.Version
+
+		removed, err := p.RemoveVote("userID1", 0)
+		require.Nil(t, err)
+		assert.True(t, removed)
+		assert.False(t, p.HasVoted("userID1"))
+		assert.Equal(t, version+1, p.Version)
+	})
+	t.Run("no vote", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		version := p.Version
+
+		removed, err := p.RemoveVote("userID1", 1)
+		require.Nil(t, err)
+		assert.False(t, removed)
+		assert.Equal(t, version, p.Version)
+	})
+	t.Run("invalid index", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		removed, err := p.RemoveVote("userID1", 5)
+		assert.Equal(t, poll.ErrInvalidIndex, err)
+		assert.False(t, removed)
+	})
+	t.Run("poll ended", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.End()
+
+		removed, err := p.RemoveVote("userID1", 0)
+		require.NotNil(t, err)
+		assert.False(t, removed)
+		assert.True(t, p.HasVoted("userID1"))
+	})
+}
@@ -0,0 +1,45 @@
+package poll
+
+import (
+	"strings"
+	"time"
+)
+
+// NonVoters returns the users of userIDs who are allowed to vote in the poll, but haven't voted yet.
+// The order of userIDs is kept.
+func (p *Poll) NonVoters(userIDs []string) []string {
+	var nonVoters []string
+	for _, userID := range userIDs {
+		if p.CanVote(userID) && !p.HasVoted(userID) {
+			nonVoters = append(nonVoters, userID)
+		}
+	}
+	return nonVoters
+}
+
+// ReminderDue returns true if the automatic reminder of a running poll should be sent at now, given in milliseconds.
+// The reminder is only sent once, see MarkReminded.
+func (p *Poll) ReminderDue(now int64) bool {
+	if p.Settings.Remind <= 0 || p.Settings.EndTime <= 0 || p.RemindedAt != 0 || p.HasEnded() {
+		return false
+	}
+	return p.Settings.EndTime-p.Settings.Remind <= now
+}
+
+// MarkReminded records that the automatic reminder was sent at now, given in milliseconds.
+func (p *Poll) MarkReminded(now int64) {
+	p.RemindedAt = now
+	p.touch()
+}
+
+// formatRemind formats the time of the reminder before the end of a poll like it's given to "--remind=X", e.g. "1h30m".
+func formatRemind(millis int64) string {
+	s := (time.Duration(millis) * time.Millisecond).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
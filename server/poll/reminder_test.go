@@ -0,0 +1,84 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestNonVoters(t *testing.T) {
+	p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1})
+	require.Nil(t, errMsg)
+	require.Nil(t, p.UpdateVote("userID2", 0))
+
+	t.Run("everyone may vote", func(t *testing.T) {
+		assert.Equal(t, []string{"userID1", "userID3"}, p.NonVoters([]string{"userID1", "userID2", "userID3"}))
+	})
+	t.Run("everyone has voted", func(t *testing.T) {
+		assert.Nil(t, p.NonVoters([]string{"userID2"}))
+	})
+	t.Run("only allowed voters", func(t *testing.T) {
+		p2 := p.Copy()
+		p2.SetAllowedVoters([]string{"userID2", "userID3"})
+		assert.Equal(t, []string{"userID3"}, p2.NonVoters([]string{"userID1", "userID2", "userID3"}))
+	})
+}
+
+func TestReminderDue(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll     *poll.Poll
+		Now      int64
+		Expected bool
+	}{
+		"no reminder": {
+			Poll:     testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 10000}),
+			Now:      9000,
+			Expected: false,
+		},
+		"before the reminder": {
+			Poll:     testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 10000, Remind: 2000}),
+			Now:      7999,
+			Expected: false,
+		},
+		"reminder due": {
+			Poll:     testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 10000, Remind: 2000}),
+			Now:      8000,
+			Expected: true,
+		},
+		"reminder already sent": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 10000, Remind: 2000})
+				p.MarkReminded(8000)
+				return p
+			}(),
+			Now:      9000,
+			Expected: false,
+		},
+		"poll has ended": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 10000, Remind: 2000})
+				p.EndedAt = 8500
+				return p
+			}(),
+			Now:      9000,
+			Expected: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Poll.ReminderDue(test.Now))
+		})
+	}
+}
+
+func TestMarkReminded(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 10000, Remind: 2000})
+	version := p.Version
+
+	p.MarkReminded(8000)
+	assert.Equal(t, int64(8000), p.RemindedAt)
+	assert.Equal(t, version+1, p.Version)
+}
@@ -0,0 +1,69 @@
+package poll
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ScaleStatistics summarizes the votes of a poll with a scale.
+type ScaleStatistics struct {
+	// Average is the arithmetic mean of all votes.
+	Average float64
+	// Median is the middle vote. For an even number of votes it's the mean of the two middle votes.
+	Median float64
+	// Votes is the number of votes the statistics are based on.
+	Votes int
+}
+
+// IsScale returns true if the answer options of a poll are the numbers from ScaleMin to ScaleMax.
+func (s Settings) IsScale() bool {
+	return s.ScaleMax > s.ScaleMin
+}
+
+// scaleOptions returns the answer options of a poll with a scale, i.e. all numbers from ScaleMin to ScaleMax.
+func (s Settings) scaleOptions() []string {
+	options := make([]string, 0, s.ScaleMax-s.ScaleMin+1)
+	for i := s.ScaleMin; i <= s.ScaleMax; i++ {
+		options = append(options, strconv.Itoa(i))
+	}
+	return options
+}
+
+// ScaleStatistics computes the average and the median of the votes of a poll with a scale.
+// The value of the answer option at index i is ScaleMin+i, so renaming an option doesn't change its value.
+// Deleted answer options and answer options that got added later are ignored.
+// It returns nil if the poll has no scale or no votes.
+func (p *Poll) ScaleStatistics() *ScaleStatistics {
+	if !p.Settings.IsScale() {
+		return nil
+	}
+
+	var values []int
+	for i, o := range p.AnswerOptions {
+		value := p.Settings.ScaleMin + i
+		if o.Deleted || value > p.Settings.ScaleMax {
+			continue
+		}
+		for j := 0; j < p.VoteCount(i); j++ {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	sort.Ints(values)
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	median := float64(values[len(values)/2])
+	if len(values)%2 == 0 {
+		median = float64(values[len(values)/2-1]+values[len(values)/2]) / 2
+	}
+	return &ScaleStatistics{
+		Average: float64(sum) / float64(len(values)),
+		Median:  median,
+		Votes:   len(values),
+	}
+}
@@ -0,0 +1,87 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func TestNewPollScale(t *testing.T) {
+	t.Run("options are generated", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", nil, poll.Settings{MaxVotes: 1, ScaleMin: 1, ScaleMax: 5})
+		require.Nil(t, errMsg)
+		require.NotNil(t, p)
+		var answers []string
+		for _, o := range p.AnswerOptions {
+			answers = append(answers, o.Answer)
+		}
+		assert.Equal(t, []string{"1", "2", "3", "4", "5"}, answers)
+	})
+	t.Run("options given", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1, ScaleMin: 1, ScaleMax: 5})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.scaleSettings.answerOptions", errMsg.Message.ID)
+	})
+	t.Run("scale too large", func(t *testing.T) {
+		p, errMsg := poll.NewPoll("userID1", "Question", nil, poll.Settings{MaxVotes: 1, ScaleMin: 0, ScaleMax: 100})
+		assert.Nil(t, p)
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.newPoll.tooManyOptions", errMsg.Message.ID)
+	})
+}
+
+func TestScaleStatistics(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings      poll.Settings
+		Voters        [][]string
+		Deleted       int
+		ExpectedStats *poll.ScaleStatistics
+	}{
+		"odd number of votes": {
+			Settings:      poll.Settings{MaxVotes: 1, ScaleMin: 1, ScaleMax: 3},
+			Voters:        [][]string{{"userID1"}, {"userID2"}, {"userID3", "userID4", "userID5"}},
+			Deleted:       -1,
+			ExpectedStats: &poll.ScaleStatistics{Average: 2.4, Median: 3, Votes: 5},
+		},
+		"even number of votes": {
+			Settings:      poll.Settings{MaxVotes: 1, ScaleMin: 0, ScaleMax: 2},
+			Voters:        [][]string{{"userID1"}, {}, {"userID2"}},
+			Deleted:       -1,
+			ExpectedStats: &poll.ScaleStatistics{Average: 1, Median: 1, Votes: 2},
+		},
+		"deleted option": {
+			Settings:      poll.Settings{MaxVotes: 1, ScaleMin: 1, ScaleMax: 3},
+			Voters:        [][]string{{"userID1"}, {"userID2"}, {"userID3"}},
+			Deleted:       2,
+			ExpectedStats: &poll.ScaleStatistics{Average: 1.5, Median: 1.5, Votes: 2},
+		},
+		"no votes": {
+			Settings:      poll.Settings{MaxVotes: 1, ScaleMin: 1, ScaleMax: 3},
+			Voters:        [][]string{{}, {}, {}},
+			Deleted:       -1,
+			ExpectedStats: nil,
+		},
+		"no scale": {
+			Settings:      poll.Settings{MaxVotes: 1},
+			Voters:        [][]string{{"userID1"}, {}, {}},
+			Deleted:       -1,
+			ExpectedStats: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &poll.Poll{Settings: test.Settings}
+			for i, voters := range test.Voters {
+				p.AnswerOptions = append(p.AnswerOptions, &poll.AnswerOption{
+					Answer:  "Answer",
+					Deleted: i == test.Deleted,
+				})
+				p.SetVoters(i, voters...)
+			}
+			assert.Equal(t, test.ExpectedStats, p.ScaleStatistics())
+		})
+	}
+}
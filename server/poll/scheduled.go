@@ -0,0 +1,78 @@
+package poll
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ScheduledPoll is a poll that gets posted once the time set by Settings.ScheduledAt has come.
+type ScheduledPoll struct {
+	Poll *Poll `json:"poll"`
+	// ChannelID is the ID of the channel the poll gets posted in.
+	ChannelID string `json:"channel_id"`
+	// RootID is the ID of the post the poll gets posted as reply to. Empty if the poll isn't a reply.
+	RootID string `json:"root_id,omitempty"`
+}
+
+// NewScheduledPoll returns a poll that gets posted in the given channel at the time given by its settings.
+func NewScheduledPoll(p *Poll, channelID, rootID string) *ScheduledPoll {
+	return &ScheduledPoll{
+		Poll:      p,
+		ChannelID: channelID,
+		RootID:    rootID,
+	}
+}
+
+// IsDue returns true if the time to post the poll has come. now is given in milliseconds.
+func (s *ScheduledPoll) IsDue(now int64) bool {
+	return s.Poll.Settings.ScheduledAt <= now
+}
+
+// Next returns the next occurrence of a recurring poll as a fresh poll with a new ID. It gets posted at the
+// first time after now that is a multiple of the repeat interval after the current one. The end time is moved
+// by the same amount. Next returns nil if the poll isn't repeated.
+func (s *ScheduledPoll) Next(now int64) *ScheduledPoll {
+	if s.Poll.Settings.Repeat == "" {
+		return nil
+	}
+
+	p := s.Poll.CloneWithNewID(s.Poll.Creator)
+	scheduledAt := time.Unix(0, p.Settings.ScheduledAt*int64(time.Millisecond)).UTC()
+	for p.Settings.ScheduledAt <= now {
+		switch p.Settings.Repeat {
+		case RepeatDaily:
+			scheduledAt = scheduledAt.AddDate(0, 0, 1)
+		case RepeatWeekly:
+			scheduledAt = scheduledAt.AddDate(0, 0, 7)
+		case RepeatMonthly:
+			scheduledAt = scheduledAt.AddDate(0, 1, 0)
+		default:
+			return nil
+		}
+
+		next := scheduledAt.UnixNano() / int64(time.Millisecond)
+		if p.Settings.EndTime > 0 {
+			p.Settings.EndTime += next - p.Settings.ScheduledAt
+		}
+		p.Settings.ScheduledAt = next
+	}
+
+	return NewScheduledPoll(p, s.ChannelID, s.RootID)
+}
+
+// EncodeToByte returns a scheduled poll as a byte array
+func (s *ScheduledPoll) EncodeToByte() []byte {
+	b, _ := json.Marshal(s)
+	return b
+}
+
+// DecodeScheduledPollFromByte tries to create a scheduled poll from a byte array and migrates its poll
+// like DecodePollFromByte. It returns nil if the data is invalid.
+func DecodeScheduledPollFromByte(b []byte) *ScheduledPoll {
+	s := ScheduledPoll{}
+	if err := json.Unmarshal(b, &s); err != nil || s.Poll == nil || s.Poll.SchemaVersion > CurrentSchemaVersion {
+		return nil
+	}
+	s.Poll.migrate()
+	return &s
+}
@@ -0,0 +1,94 @@
+package poll_test
+
+import (
+	"fmt"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestScheduledPollIsDue(t *testing.T) {
+	s := poll.NewScheduledPoll(testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: 2000}), "channelID1", "")
+
+	assert.False(t, s.IsDue(1999))
+	assert.True(t, s.IsDue(2000))
+	assert.True(t, s.IsDue(2001))
+}
+
+func TestScheduledPollEncodeDecode(t *testing.T) {
+	s := poll.NewScheduledPoll(testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: 2000}), "channelID1", "rootID1")
+	assert.Equal(t, s, poll.DecodeScheduledPollFromByte(s.EncodeToByte()))
+
+	assert.Nil(t, poll.DecodeScheduledPollFromByte([]byte("{}")))
+	assert.Nil(t, poll.DecodeScheduledPollFromByte([]byte("invalid")))
+	assert.Nil(t, poll.DecodeScheduledPollFromByte([]byte(fmt.Sprintf(`{"poll": {"ID": "pollID1", "schema_version": %d}}`, poll.CurrentSchemaVersion+1))))
+
+	unversioned := poll.DecodeScheduledPollFromByte([]byte(`{"poll": {"ID": "pollID1"}, "channel_id": "channelID1"}`))
+	require.NotNil(t, unversioned)
+	assert.Equal(t, poll.CurrentSchemaVersion, unversioned.Poll.SchemaVersion)
+	assert.Equal(t, 1, unversioned.Poll.Settings.MaxVotes)
+}
+
+func TestScheduledPollNext(t *testing.T) {
+	patch := monkey.Patch(model.NewId, func() string { return "pollID2" })
+	defer patch.Unpatch()
+
+	// 2021-10-01T15:00 UTC
+	const scheduledAt = 1633100400000
+	const day = 24 * 60 * 60 * 1000
+
+	for name, test := range map[string]struct {
+		Settings            poll.Settings
+		Now                 int64
+		ExpectedScheduledAt int64
+		ExpectedEndTime     int64
+	}{
+		"daily": {
+			Settings:            poll.Settings{MaxVotes: 1, ScheduledAt: scheduledAt, Repeat: poll.RepeatDaily},
+			Now:                 scheduledAt,
+			ExpectedScheduledAt: scheduledAt + day,
+		},
+		"weekly with end time": {
+			Settings:            poll.Settings{MaxVotes: 1, ScheduledAt: scheduledAt, EndTime: scheduledAt + 3600000, Repeat: poll.RepeatWeekly},
+			Now:                 scheduledAt,
+			ExpectedScheduledAt: scheduledAt + 7*day,
+			ExpectedEndTime:     scheduledAt + 7*day + 3600000,
+		},
+		"monthly": {
+			Settings:            poll.Settings{MaxVotes: 1, ScheduledAt: scheduledAt, Repeat: poll.RepeatMonthly},
+			Now:                 scheduledAt,
+			ExpectedScheduledAt: scheduledAt + 31*day,
+		},
+		"missed occurrences are skipped": {
+			Settings:            poll.Settings{MaxVotes: 1, ScheduledAt: scheduledAt, Repeat: poll.RepeatDaily},
+			Now:                 scheduledAt + 3*day + 1,
+			ExpectedScheduledAt: scheduledAt + 4*day,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotesAndSettings(test.Settings)
+			s := poll.NewScheduledPoll(p, "channelID1", "rootID1")
+
+			next := s.Next(test.Now)
+			require.NotNil(t, next)
+			assert.Equal(t, "pollID2", next.Poll.ID)
+			assert.Equal(t, test.ExpectedScheduledAt, next.Poll.Settings.ScheduledAt)
+			assert.Equal(t, test.ExpectedEndTime, next.Poll.Settings.EndTime)
+			assert.Equal(t, 0, next.Poll.TotalVotes())
+			assert.Equal(t, "channelID1", next.ChannelID)
+			assert.Equal(t, "rootID1", next.RootID)
+			assert.Equal(t, test.Settings, p.Settings)
+		})
+	}
+
+	t.Run("not repeated", func(t *testing.T) {
+		s := poll.NewScheduledPoll(testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, ScheduledAt: scheduledAt}), "channelID1", "")
+		assert.Nil(t, s.Next(scheduledAt))
+	})
+}
@@ -0,0 +1,29 @@
+package poll
+
+// CurrentSchemaVersion is the version of the format polls are stored in. Whenever stored polls have to be changed to
+// still be read correctly, e.g. because the shape of Settings changes, it has to be increased and a migration has to be
+// added to migrations.
+const CurrentSchemaVersion = 2
+
+// migrations upgrade stored polls to CurrentSchemaVersion. migrations[i] upgrades a poll from version i to i+1.
+var migrations = []func(p *Poll){
+	// 0 to 1: Polls stored before the number of votes was configurable allowed a single vote
+	func(p *Poll) {
+		if p.Settings.MaxVotes <= 0 {
+			p.Settings.MaxVotes = 1
+		}
+	},
+	// 1 to 2: Polls stored before the votes were indexed by voter stored the voters of every answer option
+	(*Poll).migrateLegacyVoters,
+}
+
+// migrate applies all migrations a poll of an older schema version needs, one version after another.
+func (p *Poll) migrate() {
+	if p.SchemaVersion < 0 {
+		p.SchemaVersion = 0
+	}
+	for p.SchemaVersion < len(migrations) {
+		migrations[p.SchemaVersion](p)
+		p.SchemaVersion++
+	}
+}
@@ -0,0 +1,51 @@
+package poll
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minKeywordLength is the minimum number of characters of a keyword. Shorter words, like the numbers
+// of "Answer 1" and "Answer 2", would match too many polls to be of use.
+const minKeywordLength = 2
+
+// Keywords returns the distinct words of a text in lower case, in the order they first appear.
+// Anything but letters and digits separates words. Words shorter than minKeywordLength are left out.
+func Keywords(text string) []string {
+	keywords := []string{}
+	seen := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		if len([]rune(word)) < minKeywordLength || seen[word] {
+			continue
+		}
+		seen[word] = true
+		keywords = append(keywords, word)
+	}
+	return keywords
+}
+
+// Keywords returns the keywords of the question and the active answer options of the poll, see Keywords.
+func (p *Poll) Keywords() []string {
+	texts := []string{p.Question}
+	for _, o := range p.ActiveOptions() {
+		texts = append(texts, o.Answer)
+	}
+	return Keywords(strings.Join(texts, "\n"))
+}
+
+// MatchesKeywords returns true if the question or the active answer options of the poll contain all keywords.
+func (p *Poll) MatchesKeywords(keywords []string) bool {
+	own := map[string]bool{}
+	for _, keyword := range p.Keywords() {
+		own[keyword] = true
+	}
+	for _, keyword := range keywords {
+		if !own[keyword] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestKeywords(t *testing.T) {
+	for name, test := range map[string]struct {
+		Text     string
+		Expected []string
+	}{
+		"empty":             {Text: "", Expected: []string{}},
+		"lower case":        {Text: "Where to HAVE lunch?", Expected: []string{"where", "to", "have", "lunch"}},
+		"duplicates":        {Text: "Pizza, pizza or sushi? Pizza!", Expected: []string{"pizza", "or", "sushi"}},
+		"short words":       {Text: "Answer 1, a or 42", Expected: []string{"answer", "or", "42"}},
+		"punctuation":       {Text: "Q3-roadmap: (draft)", Expected: []string{"q3", "roadmap", "draft"}},
+		"non-latin letters": {Text: "Übung für Käse", Expected: []string{"übung", "für", "käse"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, poll.Keywords(test.Text))
+		})
+	}
+}
+
+func TestPollKeywords(t *testing.T) {
+	p := testutils.GetPoll()
+	p.Question = "Lunch today?"
+	p.AnswerOptions[1].Answer = "Pizza place"
+	p.AnswerOptions[2].Answer = "Sushi"
+	p.AnswerOptions[2].Deleted = true
+
+	assert.Equal(t, []string{"lunch", "today", "answer", "pizza", "place"}, p.Keywords())
+}
+
+func TestPollMatchesKeywords(t *testing.T) {
+	p := testutils.GetPoll()
+	p.Question = "Lunch today?"
+	p.AnswerOptions[1].Answer = "Pizza place"
+
+	assert.True(t, p.MatchesKeywords([]string{"lunch"}))
+	assert.True(t, p.MatchesKeywords([]string{"pizza", "lunch"}))
+	assert.True(t, p.MatchesKeywords([]string{}))
+	assert.False(t, p.MatchesKeywords([]string{"lunch", "sushi"}))
+	assert.False(t, p.MatchesKeywords([]string{"lun"}))
+}
@@ -0,0 +1,938 @@
+package poll
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// SubmissionSettingPrefix is the prefix of the names of the elements of the create poll dialog
+// that hold a setting, e.g. "setting-anonymous".
+const SubmissionSettingPrefix = "setting-"
+
+// SettingDefinition describes a Poll Setting. All settings are registered in settingDefinitions,
+// which drives the parsing of settings, the help text of the slash command, autocomplete and the create poll dialog.
+type SettingDefinition struct {
+	// Key is the canonical keyword of the setting.
+	Key string
+	// LocalizedKey is the keyword of the setting in the language of a user, see CanonicalizeSettings.
+	// The canonical Key is accepted in every language. It's nil if the keyword isn't translated.
+	LocalizedKey *i18n.Message
+	// HelpText explains the setting. Settings without a help text aren't listed, e.g. because they are only
+	// used by meeting polls.
+	HelpText *i18n.Message
+	// DialogName is the name of the checkbox the setting is offered with in the create poll dialog.
+	// Settings without a dialog name aren't offered there.
+	DialogName *i18n.Message
+
+	// flag returns the field of a setting without a value.
+	flag func(s *Settings) *bool
+	// enable turns a setting without a value on or off, if it doesn't map to a single field.
+	enable func(s *Settings, enabled bool)
+	// pattern matches "keyword=value" of a setting with a value. It's nil for settings without a value.
+	pattern *regexp.Regexp
+	// parse applies "keyword=value" of a setting with a value.
+	parse func(s *Settings, str string) *ErrorMessage
+	// used returns true if a setting with a value is given in s.
+	used func(s Settings) bool
+	// validate checks that the setting doesn't contradict the other settings. It's only called if the setting is used.
+	validate func(s Settings) *ErrorMessage
+}
+
+// HasValue returns true if the setting is given as "keyword=value".
+func (d *SettingDefinition) HasValue() bool {
+	return d.pattern != nil
+}
+
+// Keyword returns the setting the way it's typed after the answer options, e.g. "--anonymous" or "--votes=X".
+func (d *SettingDefinition) Keyword() string {
+	if d.HasValue() {
+		return "--" + d.Key + "=X"
+	}
+	return "--" + d.Key
+}
+
+// Enabled returns true if the setting without a value is turned on in s.
+func (d *SettingDefinition) Enabled(s Settings) bool {
+	if d.flag == nil {
+		return false
+	}
+	return *d.flag(&s)
+}
+
+// isUsed returns true if the setting is turned on or given a value in s.
+func (d *SettingDefinition) isUsed(s Settings) bool {
+	if d.used != nil {
+		return d.used(s)
+	}
+	return d.Enabled(s)
+}
+
+// setEnabled turns the setting without a value on or off.
+func (d *SettingDefinition) setEnabled(s *Settings, enabled bool) {
+	if d.enable != nil {
+		d.enable(s, enabled)
+		return
+	}
+	*d.flag(s) = enabled
+}
+
+// SettingDefinitions returns all Poll Settings in the order they are listed in the help text.
+func SettingDefinitions() []*SettingDefinition {
+	definitions := make([]*SettingDefinition, len(settingDefinitions))
+	copy(definitions, settingDefinitions)
+	return definitions
+}
+
+// lookupSetting returns the definition of the setting with the canonical keyword or nil, if there is none.
+func lookupSetting(keyword string) *SettingDefinition {
+	for _, d := range settingDefinitions {
+		if d.Key == keyword {
+			return d
+		}
+	}
+	return nil
+}
+
+// SettingKeywords returns the keywords of all listed Poll Settings the way they are typed after the answer options,
+// e.g. "--anonymous" or "--votes=X", in alphabetical order.
+func SettingKeywords() []string {
+	keywords := []string{}
+	for _, d := range settingDefinitions {
+		if d.HelpText != nil {
+			keywords = append(keywords, d.Keyword())
+		}
+	}
+	sort.Strings(keywords)
+	return keywords
+}
+
+// settingAliases maps alternative keywords of settings to their canonical keyword.
+var settingAliases = map[string]string{
+	"anon":          SettingKeyAnonymous,
+	settingKeyMulti: settingKeyVotes,
+}
+
+// negatedSettingPrefix turns off a setting without a value, e.g. "no-anonymous".
+const negatedSettingPrefix = "no-"
+
+// CanonicalizeSettings replaces the keywords of settings, that are given in the language of localizer,
+// with their canonical keywords, e.g. "--anonym" with "--anonymous" for German. Values and the "no-" prefix are kept.
+// Canonical keywords, aliases and unknown settings are returned unchanged, so English settings work in every language.
+func CanonicalizeSettings(strs []string, localizer *i18n.Localizer) []string {
+	localized := map[string]string{}
+	for _, d := range settingDefinitions {
+		if d.LocalizedKey == nil {
+			continue
+		}
+		keyword, err := localizer.Localize(&i18n.LocalizeConfig{DefaultMessage: d.LocalizedKey})
+		if err != nil || keyword == d.Key {
+			continue
+		}
+		// A translation must not shadow the canonical keyword of another setting
+		if lookupSetting(keyword) != nil || settingAliases[keyword] != "" {
+			continue
+		}
+		localized[keyword] = d.Key
+	}
+	if len(localized) == 0 {
+		return strs
+	}
+
+	canonical := make([]string, len(strs))
+	for i, str := range strs {
+		keyword, value := str, ""
+		if j := strings.Index(str, "="); j >= 0 {
+			keyword, value = str[:j], str[j:]
+		}
+		prefix := ""
+		if _, ok := localized[keyword]; !ok && strings.HasPrefix(keyword, negatedSettingPrefix) {
+			prefix, keyword = negatedSettingPrefix, strings.TrimPrefix(keyword, negatedSettingPrefix)
+		}
+		if key, ok := localized[keyword]; ok {
+			str = prefix + key + value
+		}
+		canonical[i] = str
+	}
+	return canonical
+}
+
+// settingDefinitions contains all Poll Settings in the order they are listed in the help text.
+var settingDefinitions = []*SettingDefinition{{
+	Key: SettingKeyAnonymous,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.anonymous",
+		Other: "anonymous",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.anonymous",
+		Other: "Don't show who voted for what when the poll ends",
+	},
+	DialogName: &i18n.Message{
+		ID:    "dialog.createPoll.setting.anonymous.name",
+		Other: "Anonymous",
+	},
+	flag: func(s *Settings) *bool { return &s.Anonymous },
+}, {
+	Key: SettingKeyProgress,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.progress",
+		Other: "progress",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.progress",
+		Other: "During the poll, show how many votes each answer option got",
+	},
+	DialogName: &i18n.Message{
+		ID:    "dialog.createPoll.setting.progress.name",
+		Other: "Progress",
+	},
+	flag: func(s *Settings) *bool { return &s.Progress },
+}, {
+	Key: SettingKeyPublicAddOption,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.public-add-option",
+		Other: "public-add-option",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.public-add-option",
+		Other: "Allow all users to add additional options",
+	},
+	DialogName: &i18n.Message{
+		ID:    "dialog.createPoll.setting.public-add-option.name",
+		Other: "Public Add Option",
+	},
+	flag: func(s *Settings) *bool { return &s.PublicAddOption },
+}, {
+	Key: settingKeyVotes,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.votes",
+		Other: "votes",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.multi-vote",
+		Other: "Allow users to vote for X options. Use 0 to allow any number of options",
+	},
+	pattern: votesSettingPattern,
+	used:    func(s Settings) bool { return s.IsMultiVote() },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		i, errMsg := parseVotesSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.MaxVotes = i
+		return nil
+	},
+}, {
+	Key: settingKeyQuorum,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.quorum",
+		Other: "quorum",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.quorum",
+		Other: "Require at least X users to vote for the poll to be valid. X can also be a share of the channel members like `60%`",
+	},
+	pattern: quorumSettingPattern,
+	used:    func(s Settings) bool { return s.Quorum > 0 || s.QuorumPercent > 0 },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		i, percent, errMsg := parseQuorumSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Quorum, s.QuorumPercent = i, 0
+		if percent {
+			s.Quorum, s.QuorumPercent = 0, i
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyCloseOnQuorum,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.close-on-quorum",
+		Other: "close-on-quorum",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.close-on-quorum",
+		Other: "End the poll as soon as the quorum is reached",
+	},
+	flag: func(s *Settings) *bool { return &s.CloseOnQuorum },
+	validate: func(s Settings) *ErrorMessage {
+		if s.Quorum <= 0 && s.QuorumPercent <= 0 {
+			return newMissingSettingDependencyError(SettingKeyCloseOnQuorum, settingKeyQuorum+"=X")
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyHoldForQuorum,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.hold-for-quorum",
+		Other: "hold-for-quorum",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.hold-for-quorum",
+		Other: "Don't let anyone end the poll before the quorum is reached. It still ends at `--end=X`",
+	},
+	flag: func(s *Settings) *bool { return &s.HoldForQuorum },
+	validate: func(s Settings) *ErrorMessage {
+		if s.Quorum <= 0 && s.QuorumPercent <= 0 {
+			return newMissingSettingDependencyError(SettingKeyHoldForQuorum, settingKeyQuorum+"=X")
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyRevealOnEnd,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.reveal-on-end",
+		Other: "reveal-on-end",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.reveal-on-end",
+		Other: "Keep the poll anonymous while it runs, but show who voted for what when it ends",
+	},
+	DialogName: &i18n.Message{
+		ID:    "dialog.createPoll.setting.reveal-on-end.name",
+		Other: "Reveal Voters on End",
+	},
+	flag: func(s *Settings) *bool { return &s.RevealOnEnd },
+	// "--reveal-on-end" implies "--anonymous", "--no-reveal-on-end" keeps the poll anonymous
+	enable: func(s *Settings, enabled bool) {
+		s.RevealOnEnd = enabled
+		if enabled {
+			s.Anonymous = true
+		}
+	},
+	validate: func(s Settings) *ErrorMessage {
+		if !s.Anonymous {
+			return newMissingSettingDependencyError(SettingKeyRevealOnEnd, SettingKeyAnonymous)
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeySemiAnonymous,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.semi-anonymous",
+		Other: "semi-anonymous",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.semi-anonymous",
+		Other: "Like `--anonymous`, but you can see who voted for what",
+	},
+	flag: func(s *Settings) *bool { return &s.SemiAnonymous },
+	// "--semi-anonymous" implies "--anonymous", "--no-semi-anonymous" keeps the poll anonymous
+	enable: func(s *Settings, enabled bool) {
+		s.SemiAnonymous = enabled
+		if enabled {
+			s.Anonymous = true
+		}
+	},
+	validate: func(s Settings) *ErrorMessage {
+		if !s.Anonymous {
+			return newMissingSettingDependencyError(SettingKeySemiAnonymous, SettingKeyAnonymous)
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyRanked,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.ranked",
+		Other: "ranked",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.ranked",
+		Other: "Let users rank the options in the order they vote for them. The winner is determined by instant-runoff",
+	},
+	flag: func(s *Settings) *bool { return &s.Ranked },
+	validate: func(s Settings) *ErrorMessage {
+		if s.IsMultiVote() {
+			return newConflictingSettingsError(SettingKeyRanked, settingKeyVotes+"=X")
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeySecret,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.secret",
+		Other: "secret",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.secret",
+		Other: "Hide the number of votes and the voters from everyone until the poll ends",
+	},
+	flag: func(s *Settings) *bool { return &s.Secret },
+	validate: func(s Settings) *ErrorMessage {
+		if s.Progress {
+			return newConflictingSettingsError(SettingKeySecret, SettingKeyProgress)
+		}
+		// A full answer option would reveal its number of votes
+		if s.Capacity > 0 {
+			return newConflictingSettingsError(SettingKeySecret, settingKeyCapacity+"=X")
+		}
+		// The voters are hidden from the creator, too
+		if s.SemiAnonymous {
+			return newConflictingSettingsError(SettingKeySecret, SettingKeySemiAnonymous)
+		}
+		return nil
+	},
+}, {
+	Key: settingKeyEnd,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.end",
+		Other: "end",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.end",
+		Other: "End the poll automatically after a duration like `2h` or at a time in UTC like `2021-10-01T15:00`",
+	},
+	pattern: endSettingPattern,
+	used:    func(s Settings) bool { return s.EndTime > 0 },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		t, errMsg := parseEndSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.EndTime = t
+		return nil
+	},
+}, {
+	Key: settingKeyCapacity,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.capacity",
+		Other: "capacity",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.capacity",
+		Other: "Allow at most X users to vote for each option, e.g. to sign up for slots",
+	},
+	pattern: capacitySettingPattern,
+	used:    func(s Settings) bool { return s.Capacity > 0 },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		i, errMsg := parseCapacitySettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Capacity = i
+		return nil
+	},
+}, {
+	Key: settingKeySchedule,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.schedule",
+		Other: "schedule",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.schedule",
+		Other: "Post the poll later, after a duration like `1h` or at a time in UTC like `2021-10-01T15:00`",
+	},
+	pattern: scheduleSettingPattern,
+	used:    func(s Settings) bool { return s.ScheduledAt > 0 },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		t, errMsg := parseScheduleSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.ScheduledAt = t
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		if s.EndTime > 0 && s.EndTime <= s.ScheduledAt {
+			return &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.newPoll.settings.endBeforeSchedule",
+					Other: "A poll must end after it gets posted.",
+				},
+			}
+		}
+		return nil
+	},
+}, {
+	Key: settingKeyRepeat,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.repeat",
+		Other: "repeat",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.repeat",
+		Other: "Post a scheduled poll again every day, week or month. X is `daily`, `weekly` or `monthly`",
+	},
+	pattern: repeatSettingPattern,
+	used:    func(s Settings) bool { return s.Repeat != "" },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		repeat, errMsg := parseRepeatSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Repeat = repeat
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		if s.ScheduledAt <= 0 {
+			return newMissingSettingDependencyError(settingKeyRepeat+"=X", settingKeySchedule+"=X")
+		}
+		return nil
+	},
+}, {
+	Key: settingKeyVoters,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.voters",
+		Other: "voters",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.voters",
+		Other: "Only allow some users to vote. X is `channel` for the members of the channel or a list of users like `@user1,@user2`",
+	},
+	pattern: votersSettingPattern,
+	used:    func(s Settings) bool { return s.Voters != "" },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		voters, errMsg := parseVotersSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Voters = voters
+		return nil
+	},
+}, {
+	Key: settingKeyWeights,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.weights",
+		Other: "weights",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.weights",
+		Other: "Weight the votes of some users, e.g. `@user1:3,@user2:1`. X can also be the name of a user attribute that contains the weight. Other users have a weight of 1",
+	},
+	pattern: weightsSettingPattern,
+	used:    func(s Settings) bool { return s.IsWeighted() },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		weights, attribute, errMsg := parseWeightsSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Weights = weights
+		s.WeightAttribute = attribute
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		// The instant-runoff tabulation and the statistics of a scale count every user once
+		if s.Ranked {
+			return newConflictingSettingsError(settingKeyWeights+"=X", SettingKeyRanked)
+		}
+		if s.IsScale() {
+			return newConflictingSettingsError(settingKeyWeights+"=X", settingKeyScale+"=X")
+		}
+		// The attribute is read from the users when the poll ends, which requires to know who voted
+		if s.WeightAttribute != "" && s.Anonymous && !s.RevealOnEnd && !s.SemiAnonymous {
+			return newConflictingSettingsError(settingKeyWeights+"=X", SettingKeyAnonymous)
+		}
+		return nil
+	},
+}, {
+	Key: settingKeyChannels,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.channels",
+		Other: "channels",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.channels",
+		Other: "Post the poll in other channels of the team as well, e.g. `~town-square,~dev`. All posts share the same votes",
+	},
+	pattern: channelsSettingPattern,
+	used:    func(s Settings) bool { return s.Channels != "" },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		channels, errMsg := parseChannelsSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Channels = channels
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		// Reactions are counted per post, they can't be shared between the copies
+		if s.Reactions {
+			return newConflictingSettingsError(settingKeyChannels+"=X", SettingKeyReactions)
+		}
+		return nil
+	},
+}, {
+	Key: settingKeyIn,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.in",
+		Other: "in",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.in",
+		Other: "Post the poll in another channel of the team, e.g. `~town-square`",
+	},
+	pattern: inSettingPattern,
+	used:    func(s Settings) bool { return s.In != "" },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		in, errMsg := parseInSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.In = in
+		return nil
+	},
+}, {
+	Key: settingKeyDM,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.dm",
+		Other: "dm",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.dm",
+		Other: "Send the poll as direct or group message to some users, e.g. `@user1,@user2`",
+	},
+	pattern: dmSettingPattern,
+	used:    func(s Settings) bool { return s.DM != "" },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		dm, errMsg := parseDMSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.DM = dm
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		if s.In != "" {
+			return newConflictingSettingsError(settingKeyDM+"=X", settingKeyIn+"=X")
+		}
+		// The other channels are looked up in the team of the poll, which direct messages don't belong to
+		if s.Channels != "" {
+			return newConflictingSettingsError(settingKeyDM+"=X", settingKeyChannels+"=X")
+		}
+		return nil
+	},
+}, {
+	Key: settingKeyLocale,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.locale",
+		Other: "locale",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.locale",
+		Other: "Show the poll and its results in language X for everyone, e.g. `de`",
+	},
+	pattern: localeSettingPattern,
+	used:    func(s Settings) bool { return s.Locale != "" },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		locale, errMsg := parseLocaleSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Locale = locale
+		return nil
+	},
+}, {
+	Key: settingKeyChart,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.chart",
+		Other: "chart",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.chart",
+		Other: "Attach a chart of the results when the poll ends. X is `bar` or `pie`",
+	},
+	pattern: chartSettingPattern,
+	used:    func(s Settings) bool { return s.Chart != "" },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		chart, errMsg := parseChartSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Chart = chart
+		return nil
+	},
+}, {
+	Key: settingKeyBoard,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.board",
+		Other: "board",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.board",
+		Other: "Add a card with the results to the board with ID X in Boards when the poll ends",
+	},
+	pattern: boardSettingPattern,
+	used:    func(s Settings) bool { return s.Board != "" },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		board, errMsg := parseBoardSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Board = board
+		return nil
+	},
+}, {
+	Key: settingKeyQuiz,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.quiz",
+		Other: "quiz",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.quiz",
+		Other: "Mark option X as the correct answer, which is revealed together with who answered correctly when the poll ends",
+	},
+	pattern: quizSettingPattern,
+	used:    func(s Settings) bool { return s.Quiz > 0 },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		i, errMsg := parseQuizSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Quiz = i
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		// A quiz has exactly one correct answer, hence every user may only pick one option
+		if s.IsMultiVote() {
+			return newConflictingSettingsError(settingKeyQuiz+"=X", settingKeyVotes+"=X")
+		}
+		if s.Ranked {
+			return newConflictingSettingsError(settingKeyQuiz+"=X", SettingKeyRanked)
+		}
+		return nil
+	},
+}, {
+	Key: settingKeyScale,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.scale",
+		Other: "scale",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.scale",
+		Other: "Let users rate on a scale like `1-5` instead of giving options. The average and median are shown when the poll ends",
+	},
+	pattern: scaleSettingPattern,
+	used:    func(s Settings) bool { return s.IsScale() },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		min, max, errMsg := parseScaleSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.ScaleMin = min
+		s.ScaleMax = max
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		// The statistics of a scale assume a single rating per user
+		if s.IsMultiVote() {
+			return newConflictingSettingsError(settingKeyScale+"=X", settingKeyVotes+"=X")
+		}
+		if s.Ranked {
+			return newConflictingSettingsError(settingKeyScale+"=X", SettingKeyRanked)
+		}
+		// The numbers of a scale only make sense in order
+		if s.Shuffle {
+			return newConflictingSettingsError(settingKeyScale+"=X", SettingKeyShuffle)
+		}
+		return nil
+	},
+}, {
+	Key: settingKeyRemind,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.remind",
+		Other: "remind",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.remind",
+		Other: "Remind users who haven't voted yet X before the end, e.g. `2h`. Requires `--end=X`",
+	},
+	pattern: remindSettingPattern,
+	used:    func(s Settings) bool { return s.Remind > 0 },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		remind, errMsg := parseRemindSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.Remind = remind
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		if s.EndTime <= 0 {
+			return newMissingSettingDependencyError(settingKeyRemind+"=X", settingKeyEnd+"=X")
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyReactions,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.reactions",
+		Other: "reactions",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.reactions",
+		Other: "Let users vote by reacting to the poll with the numbered emoji of an option, in addition to the buttons",
+	},
+	flag: func(s *Settings) *bool { return &s.Reactions },
+	validate: func(s Settings) *ErrorMessage {
+		for _, conflict := range []struct {
+			setting string
+			used    bool
+		}{
+			{SettingKeyAnonymous, s.Anonymous},
+			{SettingKeySecret, s.Secret},
+			{SettingKeyRanked, s.Ranked},
+			// The emojis are numbered in the stored order, not in the order a user sees
+			{SettingKeyShuffle, s.Shuffle},
+			// A reaction can't carry a comment
+			{settingKeyRequireComment + "=X", len(s.RequireComment) > 0},
+		} {
+			if conflict.used {
+				return newConflictingSettingsError(SettingKeyReactions, conflict.setting)
+			}
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyAllowOther,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.allow-other",
+		Other: "allow-other",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.allowOther",
+		Other: "Let users vote for an answer of their own with an \"Other…\" button, which adds it as a new option",
+	},
+	flag: func(s *Settings) *bool { return &s.AllowOther },
+	validate: func(s Settings) *ErrorMessage {
+		// A written-in answer option would reveal that it got a vote
+		if s.Secret {
+			return newConflictingSettingsError(SettingKeyAllowOther, SettingKeySecret)
+		}
+		if s.IsScale() {
+			return newConflictingSettingsError(SettingKeyAllowOther, settingKeyScale+"=X")
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyApproveOptions,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.approve-options",
+		Other: "approve-options",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.approveOptions",
+		Other: "Only add options suggested by other users once you approve them. Requires `--public-add-option`",
+	},
+	flag: func(s *Settings) *bool { return &s.ApproveOptions },
+	validate: func(s Settings) *ErrorMessage {
+		if !s.PublicAddOption {
+			return newMissingSettingDependencyError(SettingKeyApproveOptions, SettingKeyPublicAddOption)
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyShuffle,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.shuffle",
+		Other: "shuffle",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.shuffle",
+		Other: "Show the options in a different order to every user to avoid a bias towards the first ones",
+	},
+	flag: func(s *Settings) *bool { return &s.Shuffle },
+}, {
+	Key: SettingKeyThreadResults,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.thread-results",
+		Other: "thread-results",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.threadResults",
+		Other: "Post the results as a pinned reply in the thread of the poll when it ends",
+	},
+	flag: func(s *Settings) *bool { return &s.ThreadResults },
+}, {
+	Key: SettingKeyAbstain,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.abstain",
+		Other: "abstain",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.abstain",
+		Other: "Add an \"Abstain\" option, whose votes are shown but not counted in the percentages and the quorum",
+	},
+	flag: func(s *Settings) *bool { return &s.Abstain },
+	validate: func(s Settings) *ErrorMessage {
+		// A ranking or a rating has no place for an abstention
+		if s.Ranked {
+			return newConflictingSettingsError(SettingKeyAbstain, SettingKeyRanked)
+		}
+		if s.IsScale() {
+			return newConflictingSettingsError(SettingKeyAbstain, settingKeyScale+"=X")
+		}
+		return nil
+	},
+}, {
+	Key: SettingKeyComments,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.comments",
+		Other: "comments",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.comments",
+		Other: "Let users explain their vote with a short comment, which is listed in the results when the poll ends",
+	},
+	flag: func(s *Settings) *bool { return &s.Comments },
+}, {
+	Key: settingKeyRequireComment,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.requireComment",
+		Other: "require-comment",
+	},
+	HelpText: &i18n.Message{
+		ID:    "command.help.text.pollSetting.requireComment",
+		Other: "Let votes for the options with the numbers X, e.g. `3` or `2,3`, only count with a comment. Needs `--comments`",
+	},
+	pattern: requireCommentSettingPattern,
+	used:    func(s Settings) bool { return len(s.RequireComment) > 0 },
+	parse: func(s *Settings, str string) *ErrorMessage {
+		numbers, errMsg := parseRequireCommentSettings(str)
+		if errMsg != nil {
+			return errMsg
+		}
+		s.RequireComment = numbers
+		return nil
+	},
+	validate: func(s Settings) *ErrorMessage {
+		if !s.Comments {
+			return newMissingSettingDependencyError(settingKeyRequireComment+"=X", SettingKeyComments)
+		}
+		return nil
+	},
+}, {
+	// "--invite" is explained in the usage of the schedule-meeting command
+	Key: SettingKeyInvite,
+	LocalizedKey: &i18n.Message{
+		ID:    "poll.setting.keyword.invite",
+		Other: "invite",
+	},
+	flag: func(s *Settings) *bool { return &s.Invite },
+	validate: func(s Settings) *ErrorMessage {
+		if !s.Meeting {
+			return &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.newPoll.settings.inviteWithoutMeeting",
+					Other: `The setting "{{.Setting}}" can only be used in meeting polls.`,
+				},
+				Data: map[string]interface{}{
+					"Setting": SettingKeyInvite,
+				},
+			}
+		}
+		return nil
+	},
+}, {
+	// "--multi" without a number allows unlimited votes, "--no-multi" a single one
+	Key: settingKeyMulti,
+	enable: func(s *Settings, enabled bool) {
+		s.MaxVotes = 1
+		if enabled {
+			s.MaxVotes = UnlimitedVotes
+		}
+	},
+}}
@@ -0,0 +1,81 @@
+package poll_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func TestSettingDefinitions(t *testing.T) {
+	keys := map[string]bool{}
+	for _, d := range poll.SettingDefinitions() {
+		assert.False(t, keys[d.Key], "duplicate setting %s", d.Key)
+		keys[d.Key] = true
+
+		if d.DialogName != nil {
+			assert.False(t, d.HasValue(), d.Key)
+			assert.NotNil(t, d.HelpText, d.Key)
+		}
+		if d.HasValue() {
+			assert.True(t, strings.HasSuffix(d.Keyword(), "=X"), d.Key)
+		}
+		if d.LocalizedKey != nil {
+			assert.Equal(t, d.Key, d.LocalizedKey.Other, d.Key)
+		}
+	}
+}
+
+func TestCanonicalizeSettings(t *testing.T) {
+	bundle := i18n.NewBundle(language.German)
+	require.NoError(t, bundle.AddMessages(language.German,
+		&i18n.Message{ID: "poll.setting.keyword.anonymous", Other: "anonym"},
+		&i18n.Message{ID: "poll.setting.keyword.votes", Other: "stimmen"},
+		// Translations that shadow another setting are ignored
+		&i18n.Message{ID: "poll.setting.keyword.secret", Other: "progress"},
+	))
+	localizer := i18n.NewLocalizer(bundle, "de")
+
+	assert.Equal(t,
+		[]string{"anonymous", "no-anonymous", "votes=3", "anonymous", "progress", "unknown"},
+		poll.CanonicalizeSettings([]string{"anonym", "no-anonym", "stimmen=3", "anonymous", "progress", "unknown"}, localizer),
+	)
+
+	english := i18n.NewLocalizer(i18n.NewBundle(language.English), "en")
+	assert.Equal(t, []string{"anonym", "votes=3"}, poll.CanonicalizeSettings([]string{"anonym", "votes=3"}, english))
+}
+
+func TestSettingDefinitionsParse(t *testing.T) {
+	for _, d := range poll.SettingDefinitions() {
+		// "--multi" changes the number of votes instead of a single field
+		if d.HasValue() || d.Key == "multi" {
+			continue
+		}
+		t.Run(d.Key, func(t *testing.T) {
+			settings, errMsg := poll.NewSettingsFromStrings([]string{d.Key})
+			require.Nil(t, errMsg)
+			assert.True(t, d.Enabled(settings))
+
+			settings, errMsg = poll.NewSettingsFromStringsWithDefaults([]string{"no-" + d.Key}, settings)
+			require.Nil(t, errMsg)
+			assert.False(t, d.Enabled(settings))
+		})
+	}
+}
+
+func TestSettingKeywords(t *testing.T) {
+	keywords := poll.SettingKeywords()
+
+	assert.Contains(t, keywords, "--anonymous")
+	assert.Contains(t, keywords, "--votes=X")
+	assert.NotContains(t, keywords, "--anon")
+	assert.NotContains(t, keywords, "--multi")
+	assert.NotContains(t, keywords, "--invite")
+	assert.True(t, sort.StringsAreSorted(keywords))
+}
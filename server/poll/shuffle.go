@@ -0,0 +1,33 @@
+package poll
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// OptionOrder returns the indexes of the answer options that aren't deleted in the order a user sees them,
+// see Settings.Shuffle. The order is random, but stable for every user, because it's derived from the poll ID,
+// the user ID and the index of each answer option. New answer options don't change the order of the existing ones.
+// The indexes are the ones votes are stored with. It returns nil if the poll doesn't shuffle its answer options.
+func (p *Poll) OptionOrder(userID string) []int {
+	if !p.Settings.Shuffle {
+		return nil
+	}
+
+	order := []int{}
+	keys := map[int]uint64{}
+	for i, o := range p.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
+		h := fnv.New64a()
+		h.Write([]byte(p.ID + ":" + userID + ":" + strconv.Itoa(i)))
+		keys[i] = h.Sum64()
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return keys[order[a]] < keys[order[b]]
+	})
+	return order
+}
@@ -0,0 +1,61 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollOptionOrder(t *testing.T) {
+	t.Run("not shuffled", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		assert.Nil(t, p.OptionOrder("userID1"))
+		assert.Nil(t, p.GetMetadata("userID1", false).OptionOrder)
+	})
+	t.Run("stable per user", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Shuffle: true})
+
+		order := p.OptionOrder("userID1")
+		assert.ElementsMatch(t, []int{0, 1, 2}, order)
+		assert.Equal(t, order, p.OptionOrder("userID1"))
+		assert.Equal(t, order, p.GetMetadata("userID1", false).OptionOrder)
+	})
+	t.Run("differs between users", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Shuffle: true})
+		for _, answer := range []string{"Answer 4", "Answer 5", "Answer 6"} {
+			require.Nil(t, p.AddAnswerOption(answer))
+		}
+
+		differs := false
+		for _, userID := range []string{"userID2", "userID3", "userID4", "userID5"} {
+			if !assert.ObjectsAreEqual(p.OptionOrder(userID), p.OptionOrder("userID1")) {
+				differs = true
+			}
+		}
+		assert.True(t, differs)
+	})
+	t.Run("deleted options are left out", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Shuffle: true})
+		p.AnswerOptions[1].Deleted = true
+
+		assert.ElementsMatch(t, []int{0, 2}, p.OptionOrder("userID1"))
+	})
+	t.Run("new options keep the order of the existing ones", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Shuffle: true})
+		before := p.OptionOrder("userID1")
+		require.Nil(t, p.AddAnswerOption("Answer 4"))
+
+		var after []int
+		for _, i := range p.OptionOrder("userID1") {
+			if i != 3 {
+				after = append(after, i)
+			}
+		}
+		assert.Equal(t, before, after)
+	})
+}
@@ -0,0 +1,100 @@
+package poll
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PendingOption is an answer option a user suggested, which isn't votable until the creator approves it,
+// see Settings.ApproveOptions.
+type PendingOption struct {
+	ID          string `json:"id"`
+	Answer      string `json:"answer"`
+	SuggestedBy string `json:"suggested_by"`
+}
+
+// SuggestAnswerOption stores an answer option suggested by a user as pending, see Settings.ApproveOptions.
+// The suggestion is rejected for the same reasons as an answer option that is added right away.
+func (p *Poll) SuggestAnswerOption(userID, answer string) (*PendingOption, *ErrorMessage) {
+	// Validate against a copy, which also brings the answer into the form it gets added in
+	c := p.Copy()
+	if errMsg := c.AddAnswerOption(answer); errMsg != nil {
+		return nil, errMsg
+	}
+	answer = c.AnswerOptions[len(c.AnswerOptions)-1].Answer
+
+	for _, o := range p.PendingOptions {
+		if o.Answer == answer {
+			return nil, &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.suggestAnswerOption.duplicate",
+					Other: "Someone already suggested the answer option {{.Answer}}. It's waiting for the approval of the poll creator.",
+				},
+				Data: map[string]interface{}{
+					"Answer": answer,
+				},
+			}
+		}
+	}
+
+	option := &PendingOption{
+		ID:          model.NewId(),
+		Answer:      answer,
+		SuggestedBy: userID,
+	}
+	p.PendingOptions = append(p.PendingOptions, option)
+	p.touch()
+	return option, nil
+}
+
+// GetPendingOption returns the pending answer option with the given ID or nil if it doesn't exist.
+func (p *Poll) GetPendingOption(id string) *PendingOption {
+	for _, o := range p.PendingOptions {
+		if o.ID == id {
+			return o
+		}
+	}
+	return nil
+}
+
+// ApprovePendingOption adds the pending answer option with the given ID to the poll.
+// It returns nil if the answer option doesn't exist, e.g. because it was already approved or rejected.
+// If the answer option can't be added anymore, it stays pending and an error message is returned.
+func (p *Poll) ApprovePendingOption(id string) (*PendingOption, *ErrorMessage) {
+	option := p.GetPendingOption(id)
+	if option == nil {
+		return nil, nil
+	}
+	if errMsg := p.AddAnswerOption(option.Answer); errMsg != nil {
+		return option, errMsg
+	}
+	if !p.Settings.Anonymous {
+		p.AnswerOptions[len(p.AnswerOptions)-1].AddedBy = option.SuggestedBy
+	}
+	p.removePendingOption(id)
+	return option, nil
+}
+
+// RejectPendingOption removes the pending answer option with the given ID from the poll.
+// It returns nil if the answer option doesn't exist, e.g. because it was already approved or rejected.
+func (p *Poll) RejectPendingOption(id string) *PendingOption {
+	option := p.GetPendingOption(id)
+	if option == nil {
+		return nil
+	}
+	p.removePendingOption(id)
+	p.touch()
+	return option
+}
+
+func (p *Poll) removePendingOption(id string) {
+	for i, o := range p.PendingOptions {
+		if o.ID == id {
+			p.PendingOptions = append(p.PendingOptions[:i], p.PendingOptions[i+1:]...)
+			break
+		}
+	}
+	if len(p.PendingOptions) == 0 {
+		p.PendingOptions = nil
+	}
+}
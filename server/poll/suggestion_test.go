@@ -0,0 +1,117 @@
+package poll_test
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollSuggestAnswerOption(t *testing.T) {
+	patch := monkey.Patch(model.NewId, func() string { return "optionID1" })
+	defer patch.Unpatch()
+
+	t.Run("valid suggestion", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+		version := p.Version
+
+		option, errMsg := p.SuggestAnswerOption("userID2", " New Option ")
+		require.Nil(t, errMsg)
+		assert.Equal(t, &poll.PendingOption{ID: "optionID1", Answer: "New Option", SuggestedBy: "userID2"}, option)
+		assert.Equal(t, []*poll.PendingOption{option}, p.PendingOptions)
+		assert.Len(t, p.AnswerOptions, 3)
+		assert.Equal(t, version+1, p.Version)
+	})
+	t.Run("duplicate answer option", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+
+		option, errMsg := p.SuggestAnswerOption("userID2", "Answer 1")
+		require.NotNil(t, errMsg)
+		assert.Nil(t, option)
+		assert.Nil(t, p.PendingOptions)
+	})
+	t.Run("duplicate suggestion", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+		_, errMsg := p.SuggestAnswerOption("userID2", "New Option")
+		require.Nil(t, errMsg)
+
+		option, errMsg := p.SuggestAnswerOption("userID3", "New Option")
+		require.NotNil(t, errMsg)
+		assert.Equal(t, "poll.suggestAnswerOption.duplicate", errMsg.Message.ID)
+		assert.Nil(t, option)
+		assert.Len(t, p.PendingOptions, 1)
+	})
+}
+
+func TestPollApprovePendingOption(t *testing.T) {
+	t.Run("approve", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+		p.PendingOptions = []*poll.PendingOption{{ID: "optionID1", Answer: "New Option", SuggestedBy: "userID2"}}
+		version := p.Version
+
+		option, errMsg := p.ApprovePendingOption("optionID1")
+		require.Nil(t, errMsg)
+		assert.Equal(t, "New Option", option.Answer)
+		assert.Nil(t, p.PendingOptions)
+		require.Len(t, p.AnswerOptions, 4)
+		assert.Equal(t, "New Option", p.AnswerOptions[3].Answer)
+		assert.Equal(t, "userID2", p.AnswerOptions[3].AddedBy)
+		assert.Equal(t, version+1, p.Version)
+	})
+	t.Run("anonymous poll doesn't store who suggested the option", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Anonymous: true, PublicAddOption: true, ApproveOptions: true})
+		p.PendingOptions = []*poll.PendingOption{{ID: "optionID1", Answer: "New Option", SuggestedBy: "userID2"}}
+
+		_, errMsg := p.ApprovePendingOption("optionID1")
+		require.Nil(t, errMsg)
+		assert.Empty(t, p.AnswerOptions[3].AddedBy)
+	})
+	t.Run("option can't be added anymore", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+		p.PendingOptions = []*poll.PendingOption{{ID: "optionID1", Answer: "Answer 1", SuggestedBy: "userID2"}}
+
+		option, errMsg := p.ApprovePendingOption("optionID1")
+		require.NotNil(t, errMsg)
+		assert.NotNil(t, option)
+		assert.Len(t, p.PendingOptions, 1)
+		assert.Len(t, p.AnswerOptions, 3)
+	})
+	t.Run("unknown option", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+		version := p.Version
+
+		option, errMsg := p.ApprovePendingOption("optionID1")
+		assert.Nil(t, errMsg)
+		assert.Nil(t, option)
+		assert.Equal(t, version, p.Version)
+	})
+}
+
+func TestPollRejectPendingOption(t *testing.T) {
+	t.Run("reject", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+		p.PendingOptions = []*poll.PendingOption{
+			{ID: "optionID1", Answer: "New Option", SuggestedBy: "userID2"},
+			{ID: "optionID2", Answer: "Other Option", SuggestedBy: "userID3"},
+		}
+		version := p.Version
+
+		option := p.RejectPendingOption("optionID1")
+		assert.Equal(t, "New Option", option.Answer)
+		assert.Equal(t, []*poll.PendingOption{{ID: "optionID2", Answer: "Other Option", SuggestedBy: "userID3"}}, p.PendingOptions)
+		assert.Len(t, p.AnswerOptions, 3)
+		assert.Equal(t, version+1, p.Version)
+	})
+	t.Run("unknown option", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, PublicAddOption: true, ApproveOptions: true})
+		version := p.Version
+
+		assert.Nil(t, p.RejectPendingOption("optionID1"))
+		assert.Equal(t, version, p.Version)
+	})
+}
@@ -0,0 +1,65 @@
+package poll
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// Template is a stored combination of a question, answer options and settings that new polls can be created from.
+type Template struct {
+	Name string `json:"name"`
+	// Creator is the ID of the user who saved the template.
+	Creator       string   `json:"creator"`
+	Question      string   `json:"question"`
+	AnswerOptions []string `json:"answer_options"`
+	// Settings are the poll settings as given to the command, e.g. "end=2h". They get parsed every time a poll
+	// is created from the template, so that relative times are counted from that moment.
+	Settings []string `json:"settings,omitempty"`
+}
+
+// NewTemplate returns a new template, if the name is valid and a poll can be created from it using defaults and limits.
+func NewTemplate(name, creator, question string, answerOptions, settings []string, defaults Settings, limits Limits) (*Template, *ErrorMessage) {
+	if name == "" || strings.ContainsAny(name, " \t\n\"") {
+		return nil, &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newTemplate.invalidName",
+				Other: "The name of a template must not be empty or contain spaces or quotes.",
+			},
+		}
+	}
+
+	t := &Template{
+		Name:          name,
+		Creator:       creator,
+		Question:      question,
+		AnswerOptions: answerOptions,
+		Settings:      settings,
+	}
+	if _, errMsg := t.NewPoll(creator, defaults, limits); errMsg != nil {
+		return nil, errMsg
+	}
+	return t, nil
+}
+
+// NewPoll creates a new poll from the template. The settings of the template are applied on top of defaults.
+func (t *Template) NewPoll(creator string, defaults Settings, limits Limits) (*Poll, *ErrorMessage) {
+	return NewPollWithDefaults(creator, t.Question, t.AnswerOptions, t.Settings, defaults, limits)
+}
+
+// EncodeTemplatesToByte returns a list of templates as a byte array
+func EncodeTemplatesToByte(templates []*Template) []byte {
+	b, _ := json.Marshal(templates)
+	return b
+}
+
+// DecodeTemplatesFromByte tries to create a list of templates from a byte array.
+// It returns nil if the data is invalid.
+func DecodeTemplatesFromByte(b []byte) []*Template {
+	var templates []*Template
+	if err := json.Unmarshal(b, &templates); err != nil || templates == nil {
+		return nil
+	}
+	return templates
+}
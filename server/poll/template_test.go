@@ -0,0 +1,93 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func TestNewTemplate(t *testing.T) {
+	for name, test := range map[string]struct {
+		Name          string
+		AnswerOptions []string
+		Settings      []string
+		ShouldError   bool
+	}{
+		"valid template": {
+			Name:          "standup",
+			AnswerOptions: []string{"Answer 1", "Answer 2"},
+			Settings:      []string{"anonymous", "end=2h"},
+		},
+		"empty name": {
+			Name:          "",
+			AnswerOptions: []string{"Answer 1", "Answer 2"},
+			ShouldError:   true,
+		},
+		"name with space": {
+			Name:          "daily standup",
+			AnswerOptions: []string{"Answer 1", "Answer 2"},
+			ShouldError:   true,
+		},
+		"name with quote": {
+			Name:          `"Question"`,
+			AnswerOptions: []string{"Answer 1", "Answer 2"},
+			ShouldError:   true,
+		},
+		"invalid setting": {
+			Name:          "standup",
+			AnswerOptions: []string{"Answer 1", "Answer 2"},
+			Settings:      []string{"unknown"},
+			ShouldError:   true,
+		},
+		"duplicate options": {
+			Name:          "standup",
+			AnswerOptions: []string{"Answer 1", "Answer 1"},
+			ShouldError:   true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			template, errMsg := poll.NewTemplate(test.Name, "userID1", "Question", test.AnswerOptions, test.Settings, poll.Settings{}, poll.Limits{})
+			if test.ShouldError {
+				assert.NotNil(t, errMsg)
+				assert.Nil(t, template)
+			} else {
+				require.Nil(t, errMsg)
+				assert.Equal(t, &poll.Template{
+					Name:          test.Name,
+					Creator:       "userID1",
+					Question:      "Question",
+					AnswerOptions: test.AnswerOptions,
+					Settings:      test.Settings,
+				}, template)
+			}
+		})
+	}
+}
+
+func TestTemplateNewPoll(t *testing.T) {
+	template, errMsg := poll.NewTemplate("standup", "userID1", "Question", []string{"Answer 1", "Answer 2"}, []string{"anonymous", "votes=2"}, poll.Settings{}, poll.Limits{})
+	require.Nil(t, errMsg)
+
+	p, errMsg := template.NewPoll("userID2", poll.Settings{}, poll.Limits{})
+	require.Nil(t, errMsg)
+	assert.Equal(t, "userID2", p.Creator)
+	assert.Equal(t, "Question", p.Question)
+	assert.Equal(t, []string{"Answer 1", "Answer 2"}, []string{p.AnswerOptions[0].Answer, p.AnswerOptions[1].Answer})
+	assert.True(t, p.Settings.Anonymous)
+	assert.Equal(t, 2, p.Settings.MaxVotes)
+}
+
+func TestTemplatesEncodeDecode(t *testing.T) {
+	templates := []*poll.Template{
+		{Name: "a", Creator: "userID1", Question: "Question", AnswerOptions: []string{"Answer 1", "Answer 2"}},
+		{Name: "b", Creator: "userID1", Question: "Question", AnswerOptions: []string{"Answer 1", "Answer 2"}, Settings: []string{"progress"}},
+	}
+	assert.Equal(t, templates, poll.DecodeTemplatesFromByte(poll.EncodeTemplatesToByte(templates)))
+	assert.Equal(t, []*poll.Template{}, poll.DecodeTemplatesFromByte([]byte("[]")))
+
+	assert.Nil(t, poll.DecodeTemplatesFromByte([]byte("null")))
+	assert.Nil(t, poll.DecodeTemplatesFromByte([]byte("invalid")))
+}
@@ -2,7 +2,10 @@ package poll
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -11,6 +14,9 @@ import (
 const (
 	// MatterpollAdminButtonType is action_type of buttons that are used for managing a poll.
 	MatterpollAdminButtonType = "custom_matterpoll_admin_button"
+	// MaxOptionButtons is the maximum number of answer options that are shown as buttons.
+	// Polls with more answer options show them in a select menu, because that many buttons are hard to use, especially on mobile.
+	MaxOptionButtons = 5
 )
 
 // IDToNameConverter converts a given userID to a human readable name.
@@ -26,50 +32,202 @@ var (
 		Other: "**Total votes**: {{.TotalVotes}}",
 	}
 
+	pollMessageVoterCount = &i18n.Message{
+		ID:    "poll.message.voterCount",
+		One:   "**{{.Count}}** person has voted",
+		Few:   "**{{.Count}}** people have voted",
+		Many:  "**{{.Count}}** people have voted",
+		Other: "**{{.Count}}** people have voted",
+	}
+
 	pollEndPostText = &i18n.Message{
 		ID:    "poll.endPost.text",
 		Other: "This poll has ended. The results are:",
 	}
+	pollEndPostRankedWinner = &i18n.Message{
+		ID:    "poll.endPost.ranked.winner",
+		One:   "**{{.Answer}}** won the instant-runoff after {{.Rounds}} round.",
+		Few:   "**{{.Answer}}** won the instant-runoff after {{.Rounds}} rounds.",
+		Many:  "**{{.Answer}}** won the instant-runoff after {{.Rounds}} rounds.",
+		Other: "**{{.Answer}}** won the instant-runoff after {{.Rounds}} rounds.",
+	}
+	pollEndPostRankedNoWinner = &i18n.Message{
+		ID:    "poll.endPost.ranked.noWinner",
+		Other: "The instant-runoff didn't determine a winner.",
+	}
+	pollEndPostQuizAnswer = &i18n.Message{
+		ID:    "poll.endPost.quiz.answer",
+		Other: "The correct answer is **{{.Answer}}**.",
+	}
+	pollEndPostQuizCorrectVoters = &i18n.Message{
+		ID:    "poll.endPost.quiz.correctVoters",
+		Other: "Answered correctly: {{.Voters}}",
+	}
+	pollEndPostQuizNoCorrectVoters = &i18n.Message{
+		ID:    "poll.endPost.quiz.noCorrectVoters",
+		Other: "Nobody answered correctly.",
+	}
+	pollEndPostQuizPercentage = &i18n.Message{
+		ID:    "poll.endPost.quiz.percentage",
+		Other: "{{.Percentage}}% answered correctly.",
+	}
+	pollEndPostScaleStatistics = &i18n.Message{
+		ID:    "poll.endPost.scale.statistics",
+		Other: "**Average**: {{.Average}}, **Median**: {{.Median}}",
+	}
+	pollEndPostScaleAnswerHeading = &i18n.Message{
+		ID:    "poll.endPost.scale.answer.heading",
+		One:   "{{.Answer}} ({{.Count}} vote, {{.Percentage}}%)",
+		Few:   "{{.Answer}} ({{.Count}} votes, {{.Percentage}}%)",
+		Many:  "{{.Answer}} ({{.Count}} votes, {{.Percentage}}%)",
+		Other: "{{.Answer}} ({{.Count}} votes, {{.Percentage}}%)",
+	}
+	pollEndPostMeetingBestSlots = &i18n.Message{
+		ID:    "poll.endPost.meeting.bestSlots",
+		One:   "Most available: {{.Slots}} ({{.Count}} vote)",
+		Few:   "Most available: {{.Slots}} ({{.Count}} votes)",
+		Many:  "Most available: {{.Slots}} ({{.Count}} votes)",
+		Other: "Most available: {{.Slots}} ({{.Count}} votes)",
+	}
 	pollEndPostSeperator = &i18n.Message{
 		ID:    "poll.endPost.seperator",
 		Other: "and",
 	}
+	pollEndPostErasedVoter = &i18n.Message{
+		ID:    "poll.endPost.erasedVoter",
+		Other: "a deleted user",
+	}
+	pollEndPostAddedBy = &i18n.Message{
+		ID:    "poll.endPost.addedBy",
+		Other: "_Added by {{.User}}_",
+	}
+	pollEndPostQuorumMet = &i18n.Message{
+		ID:    "poll.endPost.quorum.met",
+		One:   "The quorum of {{.Quorum}} voter was reached ({{.Voters}} voted). The result is valid.",
+		Other: "The quorum of {{.Quorum}} voters was reached ({{.Voters}} voted). The result is valid.",
+	}
+	pollEndPostQuorumNotMet = &i18n.Message{
+		ID:    "poll.endPost.quorum.notMet",
+		One:   "The quorum of {{.Quorum}} voter was not reached ({{.Voters}} voted). The result is not valid.",
+		Other: "The quorum of {{.Quorum}} voters was not reached ({{.Voters}} voted). The result is not valid.",
+	}
+
+	pollEndPostWeightedAnswerHeading = &i18n.Message{
+		ID:    "poll.endPost.answer.weightedHeading",
+		One:   "{{.Answer}} ({{.Count}} vote, weight {{.Weight}})",
+		Few:   "{{.Answer}} ({{.Count}} votes, weight {{.Weight}})",
+		Many:  "{{.Answer}} ({{.Count}} votes, weight {{.Weight}})",
+		Other: "{{.Answer}} ({{.Count}} votes, weight {{.Weight}})",
+	}
+
+	pollAbstainAnswer = &i18n.Message{
+		ID:    "poll.abstain.answer",
+		Other: "Abstain",
+	}
+
+	pollMarkdownResultsAnswer = &i18n.Message{
+		ID:    "poll.markdownResults.answer",
+		One:   "**{{.Answer}}**: {{.Count}} vote",
+		Few:   "**{{.Answer}}**: {{.Count}} votes",
+		Many:  "**{{.Answer}}**: {{.Count}} votes",
+		Other: "**{{.Answer}}**: {{.Count}} votes",
+	}
 )
 
+// LocalizeAbstainOption translates the text of the abstain option of a new poll.
+// The text is kept if the translation is the same as another answer option.
+func (p *Poll) LocalizeAbstainOption(localizer *i18n.Localizer) {
+	for i, o := range p.AnswerOptions {
+		if !o.Abstain || o.Answer != AbstainAnswer {
+			continue
+		}
+		answer := localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollAbstainAnswer})
+		if p.validateAnswerOption(answer, i) == nil {
+			o.Answer = answer
+		}
+	}
+}
+
 // ToPostActions returns the poll as a message
 func (p *Poll) ToPostActions(localizer *i18n.Localizer, pluginID, authorName string) []*model.SlackAttachment {
-	numberOfVotes := 0
+	numberOfVotes := p.TotalVotes()
 	actions := []*model.PostAction{}
+	menuOptions := []*model.PostActionOptions{}
 
 	for i, o := range p.AnswerOptions {
-		numberOfVotes += len(o.Voter)
+		if o.Deleted {
+			continue
+		}
+		name := p.getAnswerOptionName(i)
+		if keycap := p.reactionKeycap(i); keycap != "" {
+			name = keycap + " " + name
+		}
 		actions = append(actions, &model.PostAction{
 			Id:   fmt.Sprintf("vote%v", i),
-			Name: p.getAnswerOptionName(o),
+			Name: name,
 			Type: model.POST_ACTION_TYPE_BUTTON,
 			Integration: &model.PostActionIntegration{
 				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/vote/%v", pluginID, p.ID, i),
 			},
 		})
+		menuOptions = append(menuOptions, &model.PostActionOptions{Text: name, Value: strconv.Itoa(i)})
 	}
 
-	if p.Settings.MaxVotes > 1 {
-		actions = append(actions,
-			&model.PostAction{
-				Id: "resetVote",
-				Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
-					ID:    "poll.button.resetVotes",
-					Other: "Reset Votes",
-				}}),
-				Type: model.POST_ACTION_TYPE_BUTTON,
-				Integration: &model.PostActionIntegration{
-					URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/votes/reset", pluginID, p.ID),
-				},
+	if len(actions) > MaxOptionButtons {
+		actions = []*model.PostAction{{
+			Id: "voteMenu",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.menu.vote",
+				Other: "Select an option",
+			}}),
+			Type:    model.POST_ACTION_TYPE_SELECT,
+			Options: menuOptions,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/vote/menu", pluginID, p.ID),
+			},
+		}}
+	}
+
+	if p.Settings.AllowOther {
+		actions = append(actions, &model.PostAction{
+			Id: "addOther",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.button.addOther",
+				Other: "Other…",
+			}}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/option/other/request", pluginID, p.ID),
 			},
-		)
+		})
 	}
+
+	if p.Settings.Comments {
+		actions = append(actions, &model.PostAction{
+			Id: "comment",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.button.comment",
+				Other: "Comment…",
+			}}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/comment/request", pluginID, p.ID),
+			},
+		})
+	}
+
 	actions = append(actions,
 		&model.PostAction{
+			Id: "resetVote",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.button.resetVotes",
+				Other: "Reset My Votes",
+			}}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/votes/reset", pluginID, p.ID),
+			},
+		}, &model.PostAction{
 			Id: "addOption",
 			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
 				ID:    "poll.button.addOption",
@@ -79,6 +237,16 @@ func (p *Poll) ToPostActions(localizer *i18n.Localizer, pluginID, authorName str
 			Integration: &model.PostActionIntegration{
 				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/option/add/request", pluginID, p.ID),
 			},
+		}, &model.PostAction{
+			Id: "editPoll",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.button.editPoll",
+				Other: "Edit Poll",
+			}}),
+			Type: MatterpollAdminButtonType,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/edit", pluginID, p.ID),
+			},
 		}, &model.PostAction{
 			Id: "deletePoll",
 			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
@@ -90,6 +258,56 @@ func (p *Poll) ToPostActions(localizer *i18n.Localizer, pluginID, authorName str
 				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/delete", pluginID, p.ID),
 			},
 		}, &model.PostAction{
+			Id: "exportResults",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.button.exportResults",
+				Other: "Export Results",
+			}}),
+			Type: MatterpollAdminButtonType,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/export", pluginID, p.ID),
+			},
+		}, &model.PostAction{
+			Id: "remindNonVoters",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.button.remindNonVoters",
+				Other: "Remind Non-Voters",
+			}}),
+			Type: MatterpollAdminButtonType,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/remind", pluginID, p.ID),
+			},
+		},
+	)
+	if !p.Settings.Anonymous && !p.Settings.Secret {
+		// Who hasn't voted would reveal who has voted in anonymous polls and how many have voted in secret polls
+		actions = append(actions, &model.PostAction{
+			Id: "showNonVoters",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.button.showNonVoters",
+				Other: "Show Non-Voters",
+			}}),
+			Type: MatterpollAdminButtonType,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/nonvoters", pluginID, p.ID),
+			},
+		})
+	}
+	if p.Settings.SemiAnonymous {
+		actions = append(actions, &model.PostAction{
+			Id: "showVoters",
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
+				ID:    "poll.button.showVoters",
+				Other: "Show Voters",
+			}}),
+			Type: MatterpollAdminButtonType,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("/plugins/%s/api/v1/polls/%s/voters", pluginID, p.ID),
+			},
+		})
+	}
+	actions = append(actions,
+		&model.PostAction{
 			Id: "endPoll",
 			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{
 				ID:    "poll.button.endPoll",
@@ -102,10 +320,15 @@ func (p *Poll) ToPostActions(localizer *i18n.Localizer, pluginID, authorName str
 		},
 	)
 
+	text := p.makeAdditionalText(localizer, numberOfVotes)
+	if images := p.makeImagesText(); images != "" {
+		// Buttons only show text, hence the images of the answer options are shown above them
+		text = images + "\n" + text
+	}
 	return []*model.SlackAttachment{{
 		AuthorName: authorName,
 		Title:      p.Question,
-		Text:       p.makeAdditionalText(localizer, numberOfVotes),
+		Text:       text,
 		Actions:    actions,
 	}}
 }
@@ -123,11 +346,103 @@ func (p *Poll) makeAdditionalText(localizer *i18n.Localizer, numberOfVotes int)
 	if p.Settings.PublicAddOption {
 		settingsText = append(settingsText, "public-add-option")
 	}
-	if p.Settings.MaxVotes > 1 {
+	if p.Settings.HasUnlimitedVotes() {
+		settingsText = append(settingsText, "votes=unlimited")
+	} else if p.IsMultiVote() {
 		settingsText = append(settingsText, fmt.Sprintf("votes=%d", p.Settings.MaxVotes))
 	}
+	if p.Settings.QuorumPercent > 0 {
+		settingsText = append(settingsText, fmt.Sprintf("quorum=%d%% (%d)", p.Settings.QuorumPercent, p.Settings.Quorum))
+	} else if p.Settings.Quorum > 0 {
+		settingsText = append(settingsText, fmt.Sprintf("quorum=%d", p.Settings.Quorum))
+	}
+	if p.Settings.CloseOnQuorum {
+		settingsText = append(settingsText, SettingKeyCloseOnQuorum)
+	}
+	if p.Settings.HoldForQuorum {
+		settingsText = append(settingsText, SettingKeyHoldForQuorum)
+	}
+	if p.Settings.RevealOnEnd {
+		settingsText = append(settingsText, SettingKeyRevealOnEnd)
+	}
+	if p.Settings.SemiAnonymous {
+		settingsText = append(settingsText, SettingKeySemiAnonymous)
+	}
+	if p.Settings.Ranked {
+		settingsText = append(settingsText, SettingKeyRanked)
+	}
+	if p.Settings.Secret {
+		settingsText = append(settingsText, SettingKeySecret)
+	}
+	if p.Settings.Capacity > 0 {
+		settingsText = append(settingsText, fmt.Sprintf("capacity=%d", p.Settings.Capacity))
+	}
+	if p.Settings.IsWeighted() {
+		settingsText = append(settingsText, "weighted")
+	}
+	if p.Settings.Repeat != "" {
+		settingsText = append(settingsText, settingKeyRepeat+"="+p.Settings.Repeat)
+	}
+	if p.Settings.Quiz > 0 {
+		// The correct answer stays hidden until the poll has ended
+		settingsText = append(settingsText, settingKeyQuiz)
+	}
+	if p.Settings.Invite {
+		settingsText = append(settingsText, SettingKeyInvite)
+	}
+	if p.Settings.Reactions {
+		settingsText = append(settingsText, SettingKeyReactions)
+	}
+	if p.Settings.AllowOther {
+		settingsText = append(settingsText, SettingKeyAllowOther)
+	}
+	if p.Settings.ApproveOptions {
+		settingsText = append(settingsText, SettingKeyApproveOptions)
+	}
+	if p.Settings.Shuffle {
+		settingsText = append(settingsText, SettingKeyShuffle)
+	}
+	if p.Settings.ThreadResults {
+		settingsText = append(settingsText, SettingKeyThreadResults)
+	}
+	if p.Settings.Abstain {
+		settingsText = append(settingsText, SettingKeyAbstain)
+	}
+	if p.Settings.Comments {
+		settingsText = append(settingsText, SettingKeyComments)
+	}
+	if len(p.Settings.RequireComment) > 0 {
+		numbers := make([]string, len(p.Settings.RequireComment))
+		for i, n := range p.Settings.RequireComment {
+			numbers[i] = strconv.Itoa(n)
+		}
+		settingsText = append(settingsText, settingKeyRequireComment+"="+strings.Join(numbers, ","))
+	}
+	if p.Settings.Remind > 0 {
+		settingsText = append(settingsText, settingKeyRemind+"="+formatRemind(p.Settings.Remind))
+	}
+	if p.Settings.IsScale() {
+		settingsText = append(settingsText, fmt.Sprintf("%s=%d-%d", settingKeyScale, p.Settings.ScaleMin, p.Settings.ScaleMax))
+	}
+	if p.Settings.Voters == VotersChannel {
+		settingsText = append(settingsText, settingKeyVoters+"="+VotersChannel)
+	} else if usernames := p.Settings.VoterUsernames(); len(usernames) > 0 {
+		settingsText = append(settingsText, settingKeyVoters+"=@"+strings.Join(usernames, ",@"))
+	}
+	if p.Settings.EndTime > 0 {
+		endTime := time.Unix(0, p.Settings.EndTime*int64(time.Millisecond)).UTC()
+		settingsText = append(settingsText, fmt.Sprintf("end=%s UTC", endTime.Format(EndTimeLayout)))
+	}
 
 	lines := []string{"---"}
+	if p.Settings.Progress && !p.HidesResults() {
+		percentages := p.Percentages()
+		for i, o := range p.AnswerOptions {
+			if bar := p.makeBar(percentages[i]); bar != "" && !o.Deleted {
+				lines = append(lines, fmt.Sprintf("**%s** %s", o.Answer, bar))
+			}
+		}
+	}
 	if len(settingsText) > 0 {
 		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
 			DefaultMessage: pollMessageSettings,
@@ -135,6 +450,17 @@ func (p *Poll) makeAdditionalText(localizer *i18n.Localizer, numberOfVotes int)
 		}))
 	}
 
+	if p.HidesResults() {
+		// The total number of votes reveals partial results of multi vote polls, hence only the voters are counted
+		voterCount := p.VoterCount()
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollMessageVoterCount,
+			TemplateData:   map[string]interface{}{"Count": voterCount},
+			PluralCount:    voterCount,
+		}))
+		return strings.Join(lines, "\n")
+	}
+
 	lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
 		DefaultMessage: pollMessageTotalVotes,
 		TemplateData:   map[string]interface{}{"TotalVotes": numberOfVotes},
@@ -146,51 +472,266 @@ func (p *Poll) makeAdditionalText(localizer *i18n.Localizer, numberOfVotes int)
 func (p *Poll) ToEndPollPost(localizer *i18n.Localizer, authorName string, convert IDToNameConverter) (*model.Post, *model.AppError) {
 	post := &model.Post{}
 	fields := []*model.SlackAttachmentField{}
+	percentages := p.Percentages()
+	weighted := p.WeightedCounts()
 
-	for _, o := range p.AnswerOptions {
+	for i, o := range p.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
 		var voter string
-		if !p.Settings.Anonymous {
-			for i := 0; i < len(o.Voter); i++ {
-				displayName, err := convert(o.Voter[i])
-				if err != nil {
-					return nil, err
-				}
-				if i+1 == len(o.Voter) && len(o.Voter) > 1 {
-					voter += " " + localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostSeperator}) + " "
-				} else if i != 0 {
-					voter += ", "
-				}
-				voter += displayName
+		// The end poll post is only shown once the poll has ended, hence RevealOnEnd always applies
+		if p.revealsVotersOnEnd() {
+			var err *model.AppError
+			voter, err = joinVoterNames(localizer, p.Voters(i), convert)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if o.AddedBy != "" {
+			addedBy, err := joinVoterNames(localizer, []string{o.AddedBy}, convert)
+			if err != nil {
+				return nil, err
 			}
+			voter = strings.TrimSpace(voter + "\n" + localizer.MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: pollEndPostAddedBy,
+				TemplateData:   map[string]interface{}{"User": addedBy},
+			}))
+		}
+
+		heading := &i18n.Message{
+			ID:    "poll.endPost.answer.heading",
+			One:   "{{.Answer}} ({{.Count}} vote)",
+			Few:   "{{.Answer}} ({{.Count}} votes)",
+			Many:  "{{.Answer}} ({{.Count}} votes)",
+			Other: "{{.Answer}} ({{.Count}} votes)",
+		}
+		if p.Settings.IsScale() {
+			// The distribution of the ratings is easier to read in percent
+			heading = pollEndPostScaleAnswerHeading
+		}
+		weight := 0
+		if weighted != nil {
+			heading = pollEndPostWeightedAnswerHeading
+			weight = weighted[i]
 		}
 
+		value := p.makeBar(percentages[i])
+		if o.ImageURL != "" {
+			value += "\n" + imageMarkdown(o.Answer, o.ImageURL)
+		}
 		fields = append(fields, &model.SlackAttachmentField{
 			Short: true,
 			Title: localizer.MustLocalize(&i18n.LocalizeConfig{
-				DefaultMessage: &i18n.Message{
-					ID:    "poll.endPost.answer.heading",
-					One:   "{{.Answer}} ({{.Count}} vote)",
-					Few:   "{{.Answer}} ({{.Count}} votes)",
-					Many:  "{{.Answer}} ({{.Count}} votes)",
-					Other: "{{.Answer}} ({{.Count}} votes)",
-				},
+				DefaultMessage: heading,
 				TemplateData: map[string]interface{}{
-					"Answer": o.Answer,
-					"Count":  len(o.Voter),
+					"Answer":     o.Answer,
+					"Count":      p.VoteCount(i),
+					"Percentage": fmt.Sprintf("%.0f", percentages[i]),
+					"Weight":     weight,
 				},
-				PluralCount: len(o.Voter),
+				PluralCount: p.VoteCount(i),
 			}),
-			Value: voter,
+			Value: strings.TrimSpace(value + "\n" + voter),
 		})
 	}
 
+	text := localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostText})
+	if p.Settings.Quorum > 0 {
+		text += "\n" + p.makeQuorumText(localizer)
+	}
+	if p.Settings.Ranked {
+		text += "\n" + p.makeRunoffText(localizer)
+	}
+	if stats := p.ScaleStatistics(); stats != nil {
+		text += "\n" + localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollEndPostScaleStatistics,
+			TemplateData: map[string]interface{}{
+				"Average": strconv.FormatFloat(math.Round(stats.Average*100)/100, 'f', -1, 64),
+				"Median":  strconv.FormatFloat(stats.Median, 'f', -1, 64),
+			},
+		})
+	}
+	if best := p.BestSlots(); len(best) > 0 {
+		slots := make([]string, len(best))
+		for i, index := range best {
+			slots[i] = "**" + p.AnswerOptions[index].Answer + "**"
+		}
+		count := p.VoteCount(best[0])
+		text += "\n" + localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollEndPostMeetingBestSlots,
+			TemplateData: map[string]interface{}{
+				"Slots": joinNames(localizer, slots),
+				"Count": count,
+			},
+			PluralCount: count,
+		})
+	}
+	if p.CorrectAnswer() != -1 {
+		quizText, err := p.makeQuizText(localizer, convert)
+		if err != nil {
+			return nil, err
+		}
+		text += "\n" + quizText
+	}
+	commentsText, err := p.makeCommentsText(localizer, convert)
+	if err != nil {
+		return nil, err
+	}
+	if commentsText != "" {
+		text += "\n" + commentsText
+	}
+
 	attachments := []*model.SlackAttachment{{
 		AuthorName: authorName,
 		Title:      p.Question,
-		Text:       localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostText}),
+		Text:       text,
 		Fields:     fields,
 	}}
 	model.ParseSlackAttachment(post, attachments)
 
 	return post, nil
 }
+
+// makeQuorumText returns whether the quorum of the poll was reached and hence its result is valid.
+func (p *Poll) makeQuorumText(localizer *i18n.Localizer) string {
+	message := pollEndPostQuorumNotMet
+	if p.QuorumMet() {
+		message = pollEndPostQuorumMet
+	}
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: message,
+		TemplateData: map[string]interface{}{
+			"Quorum": p.Settings.Quorum,
+			"Voters": p.QuorumVoterCount(),
+		},
+		PluralCount: p.Settings.Quorum,
+	})
+}
+
+// makeRunoffText returns the result of the instant-runoff tabulation of a ranked poll as markdown text.
+func (p *Poll) makeRunoffText(localizer *i18n.Localizer) string {
+	rounds, winner := p.InstantRunoff()
+	if winner == -1 {
+		return localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostRankedNoWinner})
+	}
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollEndPostRankedWinner,
+		TemplateData: map[string]interface{}{
+			"Answer": p.AnswerOptions[winner].Answer,
+			"Rounds": len(rounds),
+		},
+		PluralCount: len(rounds),
+	})
+}
+
+// makeQuizText returns the correct answer of a quiz and who answered correctly as markdown text.
+// For anonymous polls only the share of users that answered correctly is shown.
+func (p *Poll) makeQuizText(localizer *i18n.Localizer, convert IDToNameConverter) (string, *model.AppError) {
+	correct := p.CorrectAnswer()
+	o := p.AnswerOptions[correct]
+	lines := []string{localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollEndPostQuizAnswer,
+		TemplateData:   map[string]interface{}{"Answer": o.Answer},
+	})}
+
+	switch {
+	case p.VoteCount(correct) == 0:
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostQuizNoCorrectVoters}))
+	case !p.revealsVotersOnEnd():
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollEndPostQuizPercentage,
+			TemplateData:   map[string]interface{}{"Percentage": p.VoteCount(correct) * 100 / p.VoterCount()},
+		}))
+	default:
+		voters, err := joinVoterNames(localizer, p.Voters(correct), convert)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollEndPostQuizCorrectVoters,
+			TemplateData:   map[string]interface{}{"Voters": voters},
+		}))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// MarkdownResults returns the results of the poll as a markdown list.
+// Every answer option is listed with its number of votes. If the progress setting is enabled,
+// the share of votes in percent is added. Unless the voters are hidden, they are listed too.
+// If the results are hidden, only the answer options are listed.
+func (p *Poll) MarkdownResults(localizer *i18n.Localizer, convert IDToNameConverter) (string, *model.AppError) {
+	return p.markdownResults(localizer, convert, !p.HidesVoters())
+}
+
+// MarkdownResultsForUser is like MarkdownResults, but lists the voters if the given user may see them, see ShowsVotersTo.
+func (p *Poll) MarkdownResultsForUser(localizer *i18n.Localizer, convert IDToNameConverter, userID string) (string, *model.AppError) {
+	return p.markdownResults(localizer, convert, p.ShowsVotersTo(userID))
+}
+
+func (p *Poll) markdownResults(localizer *i18n.Localizer, convert IDToNameConverter, showVoters bool) (string, *model.AppError) {
+	percentages := p.Percentages()
+
+	lines := []string{"#### " + p.Question}
+	for i, o := range p.AnswerOptions {
+		if o.Deleted {
+			continue
+		}
+		if p.HidesResults() {
+			lines = append(lines, "- **"+o.Answer+"**")
+			continue
+		}
+		line := "- " + localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollMarkdownResultsAnswer,
+			TemplateData: map[string]interface{}{
+				"Answer": o.Answer,
+				"Count":  p.VoteCount(i),
+			},
+			PluralCount: p.VoteCount(i),
+		})
+		if p.Settings.Progress {
+			line += fmt.Sprintf(" (%.1f%%)", percentages[i])
+		}
+		if showVoters && p.VoteCount(i) > 0 {
+			voter, err := joinVoterNames(localizer, p.Voters(i), convert)
+			if err != nil {
+				return "", err
+			}
+			line += ": " + voter
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// joinVoterNames converts the given user IDs to display names and joins them to a human readable list,
+// e.g. "@user1, @user2 and @user3". Erased users are not converted, see Poll.EraseUser.
+func joinVoterNames(localizer *i18n.Localizer, voters []string, convert IDToNameConverter) (string, *model.AppError) {
+	names := make([]string, len(voters))
+	for i, userID := range voters {
+		if IsErasedVoter(userID) {
+			names[i] = localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostErasedVoter})
+			continue
+		}
+		displayName, err := convert(userID)
+		if err != nil {
+			return "", err
+		}
+		names[i] = displayName
+	}
+	return joinNames(localizer, names), nil
+}
+
+// joinNames joins the given names to a human readable list, e.g. "a, b and c".
+func joinNames(localizer *i18n.Localizer, names []string) string {
+	var joined string
+	for i, name := range names {
+		if i+1 == len(names) && len(names) > 1 {
+			joined += " " + localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostSeperator}) + " "
+		} else if i != 0 {
+			joined += ", "
+		}
+		joined += name
+	}
+	return joined
+}
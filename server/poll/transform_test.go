@@ -53,6 +53,73 @@ func TestPollToEndPollPost(t *testing.T) {
 				}},
 			}},
 		},
+		"Anonymous poll with reveal-on-end": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, RevealOnEnd: true, MaxVotes: 1}),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Answer 1 (3 votes)",
+					Value: "@user1, @user2 and @user3",
+					Short: true,
+				}, {
+					Title: "Answer 2 (1 vote)",
+					Value: "@user4",
+					Short: true,
+				}, {
+					Title: "Answer 3 (0 votes)",
+					Value: "",
+					Short: true,
+				}},
+			}},
+		},
+		"Poll with erased voter": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.SetVoters(1, poll.ErasedVoterPrefix+"token")
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Answer 1 (3 votes)",
+					Value: "@user1, @user2 and @user3",
+					Short: true,
+				}, {
+					Title: "Answer 2 (1 vote)",
+					Value: "a deleted user",
+					Short: true,
+				}, {
+					Title: "Answer 3 (0 votes)",
+					Value: "",
+					Short: true,
+				}},
+			}},
+		},
+		"Poll with deleted option": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.AnswerOptions[1].Deleted = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Answer 1 (3 votes)",
+					Value: "@user1, @user2 and @user3",
+					Short: true,
+				}, {
+					Title: "Answer 3 (0 votes)",
+					Value: "",
+					Short: true,
+				}},
+			}},
+		},
 		"Anonymous poll": {
 			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true}),
 			ExpectedAttachments: []*model.SlackAttachment{{
@@ -128,6 +195,13 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/vote/1", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "resetVote",
+					Name: "Reset My Votes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/votes/reset", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "addOption",
 					Name: "Add Option",
@@ -135,6 +209,13 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/option/add/request", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "editPoll",
+					Name: "Edit Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/edit", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "deletePoll",
 					Name: "Delete Poll",
@@ -142,6 +223,110 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/delete", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "exportResults",
+					Name: "Export Results",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/export", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "remindNonVoters",
+					Name: "Remind Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/remind", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "showNonVoters",
+					Name: "Show Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/nonvoters", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "endPoll",
+					Name: "End Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/end", PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Deleted option": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPoll()
+				p.AnswerOptions[1].Deleted = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Id:   "vote0",
+					Name: "Answer 1",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/vote/0", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "vote2",
+					Name: "Answer 3",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/vote/2", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "resetVote",
+					Name: "Reset My Votes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/votes/reset", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "addOption",
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/option/add/request", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "editPoll",
+					Name: "Edit Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/edit", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "deletePoll",
+					Name: "Delete Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/delete", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "exportResults",
+					Name: "Export Results",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/export", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "remindNonVoters",
+					Name: "Remind Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/remind", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "showNonVoters",
+					Name: "Show Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/nonvoters", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "endPoll",
 					Name: "End Poll",
@@ -179,6 +364,13 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/vote/2", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "resetVote",
+					Name: "Reset My Votes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/votes/reset", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "addOption",
 					Name: "Add Option",
@@ -186,6 +378,13 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/option/add/request", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "editPoll",
+					Name: "Edit Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/edit", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "deletePoll",
 					Name: "Delete Poll",
@@ -193,6 +392,27 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/delete", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "exportResults",
+					Name: "Export Results",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/export", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "remindNonVoters",
+					Name: "Remind Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/remind", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "showNonVoters",
+					Name: "Show Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/nonvoters", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "endPoll",
 					Name: "End Poll",
@@ -231,6 +451,13 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/vote/2", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "resetVote",
+					Name: "Reset My Votes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/votes/reset", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "addOption",
 					Name: "Add Option",
@@ -238,6 +465,13 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/option/add/request", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "editPoll",
+					Name: "Edit Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/edit", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "deletePoll",
 					Name: "Delete Poll",
@@ -245,6 +479,20 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/delete", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "exportResults",
+					Name: "Export Results",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/export", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "remindNonVoters",
+					Name: "Remind Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/remind", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "endPoll",
 					Name: "End Poll",
@@ -285,7 +533,94 @@ func TestPollToPostActions(t *testing.T) {
 					},
 				}, {
 					Id:   "resetVote",
-					Name: "Reset Votes",
+					Name: "Reset My Votes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/votes/reset", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "addOption",
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/option/add/request", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "editPoll",
+					Name: "Edit Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/edit", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "deletePoll",
+					Name: "Delete Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/delete", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "exportResults",
+					Name: "Export Results",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/export", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "remindNonVoters",
+					Name: "Remind Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/remind", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "showNonVoters",
+					Name: "Show Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/nonvoters", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "endPoll",
+					Name: "End Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/end", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Multipile questions, settings: votes=3, quorum=2, close-on-quorum": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{MaxVotes: 3, Quorum: 2, CloseOnQuorum: true}),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: votes=3, quorum=2, close-on-quorum\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Id:   "vote0",
+					Name: "Answer 1",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/vote/0", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "vote1",
+					Name: "Answer 2",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/vote/1", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "vote2",
+					Name: "Answer 3",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/vote/2", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "resetVote",
+					Name: "Reset My Votes",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/votes/reset", PluginID, currentAPIVersion, testutils.GetPollID()),
@@ -297,6 +632,13 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/option/add/request", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "editPoll",
+					Name: "Edit Poll",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/edit", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "deletePoll",
 					Name: "Delete Poll",
@@ -304,6 +646,27 @@ func TestPollToPostActions(t *testing.T) {
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/delete", PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Id:   "exportResults",
+					Name: "Export Results",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/export", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "remindNonVoters",
+					Name: "Remind Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/remind", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Id:   "showNonVoters",
+					Name: "Show Non-Voters",
+					Type: poll.MatterpollAdminButtonType,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s/api/%s/polls/%s/nonvoters", PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Id:   "endPoll",
 					Name: "End Poll",
@@ -321,3 +684,438 @@ func TestPollToPostActions(t *testing.T) {
 		})
 	}
 }
+
+func TestPollToPostActionsEndTime(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, EndTime: 1633100400000})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: end=2021-10-01T15:00 UTC\n**Total votes**: 0", attachments[0].Text)
+}
+
+func TestPollToPostActionsRanked(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Ranked: true})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: ranked\n**Total votes**: 0", attachments[0].Text)
+	ids := []string{}
+	for _, action := range attachments[0].Actions {
+		ids = append(ids, action.Id)
+	}
+	assert.Contains(t, ids, "resetVote")
+}
+
+func TestPollToPostActionsSecret(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Secret: true})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: secret\n**4** people have voted", attachments[0].Text)
+	assert.Equal(t, "Answer 1", attachments[0].Actions[0].Name)
+}
+
+func TestPollToPostActionsSemiAnonymous(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Anonymous: true, SemiAnonymous: true})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: anonymous, semi-anonymous\n**Total votes**: 4", attachments[0].Text)
+	var showVoters *model.PostAction
+	for _, action := range attachments[0].Actions {
+		if action.Id == "showVoters" {
+			showVoters = action
+		}
+	}
+	require.NotNil(t, showVoters)
+	assert.Equal(t, poll.MatterpollAdminButtonType, showVoters.Type)
+	assert.Equal(t, "/plugins/com.github.matterpoll.matterpoll/api/v1/polls/"+testutils.GetPollID()+"/voters", showVoters.Integration.URL)
+}
+
+func TestPollToPostActionsQuiz(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Quiz: 2})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: quiz\n**Total votes**: 4", attachments[0].Text)
+}
+
+func TestPollToPostActionsQuorumPercent(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Quorum: 6, QuorumPercent: 60, HoldForQuorum: true})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: quorum=60% (6), hold-for-quorum\n**Total votes**: 4", attachments[0].Text)
+}
+
+func TestPollLocalizeAbstainOption(t *testing.T) {
+	p, errMsg := poll.NewPoll("userID1", "Question", []string{"Answer 1", "Answer 2"}, poll.Settings{MaxVotes: 1, Abstain: true})
+	require.Nil(t, errMsg)
+	p.AnswerOptions[1].Answer = "Other"
+
+	p.LocalizeAbstainOption(testutils.GetLocalizer())
+	assert.Equal(t, "Abstain", p.AnswerOptions[2].Answer)
+	assert.Equal(t, "Other", p.AnswerOptions[1].Answer)
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: abstain\n**Total votes**: 0", attachments[0].Text)
+	assert.Equal(t, "Abstain", attachments[0].Actions[2].Name)
+}
+
+func TestPollToPostActionsCapacity(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Capacity: 3})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: capacity=3\n**Total votes**: 4", attachments[0].Text)
+	assert.Equal(t, "Answer 1 (3/3)", attachments[0].Actions[0].Name)
+	assert.Equal(t, "Answer 2 (1/3)", attachments[0].Actions[1].Name)
+}
+
+func TestPollToPostActionsMenu(t *testing.T) {
+	t.Run("buttons up to the limit", func(t *testing.T) {
+		p := testutils.GetPoll()
+		for i := len(p.AnswerOptions); i < poll.MaxOptionButtons; i++ {
+			require.Nil(t, p.AddAnswerOption(fmt.Sprintf("Answer %d", i+1)))
+		}
+		attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+		require.Len(t, attachments, 1)
+		assert.Equal(t, model.POST_ACTION_TYPE_BUTTON, attachments[0].Actions[poll.MaxOptionButtons-1].Type)
+		assert.Equal(t, "resetVote", attachments[0].Actions[poll.MaxOptionButtons].Id)
+	})
+	t.Run("select menu above the limit", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Progress: true})
+		for i := len(p.AnswerOptions); i <= poll.MaxOptionButtons; i++ {
+			require.Nil(t, p.AddAnswerOption(fmt.Sprintf("Answer %d", i+1)))
+		}
+		p.AnswerOptions[1].Deleted = true
+		p.SetVoters(2, "userID2")
+		require.Nil(t, p.AddAnswerOption("Answer 7"))
+		attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+		require.Len(t, attachments, 1)
+		menu := attachments[0].Actions[0]
+		assert.Equal(t, "voteMenu", menu.Id)
+		assert.Equal(t, "Select an option", menu.Name)
+		assert.Equal(t, model.POST_ACTION_TYPE_SELECT, menu.Type)
+		assert.Equal(t, fmt.Sprintf("/plugins/com.github.matterpoll.matterpoll/api/v1/polls/%s/vote/menu", p.ID), menu.Integration.URL)
+		assert.Equal(t, []*model.PostActionOptions{
+			{Text: "Answer 1 (0)", Value: "0"},
+			{Text: "Answer 3 (1)", Value: "2"},
+			{Text: "Answer 4 (0)", Value: "3"},
+			{Text: "Answer 5 (0)", Value: "4"},
+			{Text: "Answer 6 (0)", Value: "5"},
+			{Text: "Answer 7 (0)", Value: "6"},
+		}, menu.Options)
+		assert.Equal(t, "resetVote", attachments[0].Actions[1].Id)
+	})
+}
+
+func TestPollToPostActionsRemind(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Remind: 90 * 60 * 1000})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: remind=1h30m\n**Total votes**: 4", attachments[0].Text)
+
+	p.Settings.Remind = 2 * 60 * 60 * 1000
+	attachments = p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+	assert.Equal(t, "---\n**Poll Settings**: remind=2h\n**Total votes**: 4", attachments[0].Text)
+}
+
+func TestPollToPostActionsAllowOther(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+	attachments := p.ToPostActions(testutils.GetLocalizer(), "com.github.matterpoll.matterpoll", "John Doe")
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "---\n**Poll Settings**: allow-other\n**Total votes**: 4", attachments[0].Text)
+	other := attachments[0].Actions[3]
+	assert.Equal(t, "addOther", other.Id)
+	assert.Equal(t, "Other…", other.Name)
+	assert.Equal(t, fmt.Sprintf("/plugins/com.github.matterpoll.matterpoll/api/v1/polls/%s/option/other/request", p.ID), other.Integration.URL)
+	assert.Equal(t, "resetVote", attachments[0].Actions[4].Id)
+}
+
+func TestPollToEndPollPostAddedBy(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, AllowOther: true})
+	require.Nil(t, p.AddOtherAnswer("userID5", "Something else"))
+	p.AnswerOptions[2].AddedBy = poll.ErasedVoterPrefix + "token"
+
+	post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+	require.Nil(t, err)
+	fields := post.Attachments()[0].Fields
+	require.Len(t, fields, 4)
+	assert.Equal(t, "_Added by a deleted user_", fields[2].Value)
+	assert.Equal(t, "@userID5\n_Added by @userID5_", fields[3].Value)
+}
+
+func TestPollToEndPollPostQuiz(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+
+	for name, test := range map[string]struct {
+		Settings     poll.Settings
+		Deleted      bool
+		ExpectedText string
+	}{
+		"correct voters": {
+			Settings:     poll.Settings{MaxVotes: 1, Quiz: 1},
+			ExpectedText: "This poll has ended. The results are:\nThe correct answer is **Answer 1**.\nAnswered correctly: @userID1, @userID2 and @userID3",
+		},
+		"nobody answered correctly": {
+			Settings:     poll.Settings{MaxVotes: 1, Quiz: 3},
+			ExpectedText: "This poll has ended. The results are:\nThe correct answer is **Answer 3**.\nNobody answered correctly.",
+		},
+		"anonymous": {
+			Settings:     poll.Settings{MaxVotes: 1, Anonymous: true, Quiz: 1},
+			ExpectedText: "This poll has ended. The results are:\nThe correct answer is **Answer 1**.\n75% answered correctly.",
+		},
+		"anonymous with reveal-on-end": {
+			Settings:     poll.Settings{MaxVotes: 1, Anonymous: true, RevealOnEnd: true, Quiz: 2},
+			ExpectedText: "This poll has ended. The results are:\nThe correct answer is **Answer 2**.\nAnswered correctly: @userID4",
+		},
+		"correct answer deleted": {
+			Settings:     poll.Settings{MaxVotes: 1, Quiz: 2},
+			Deleted:      true,
+			ExpectedText: "This poll has ended. The results are:",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotesAndSettings(test.Settings)
+			p.AnswerOptions[1].Deleted = test.Deleted
+
+			post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+			require.Nil(t, err)
+			attachments := post.Attachments()
+			require.Len(t, attachments, 1)
+			assert.Equal(t, test.ExpectedText, attachments[0].Text)
+		})
+	}
+}
+
+func TestPollToEndPollPostQuorum(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+
+	for name, test := range map[string]struct {
+		Quorum       int
+		ExpectedText string
+	}{
+		"quorum reached": {
+			Quorum:       4,
+			ExpectedText: "This poll has ended. The results are:\nThe quorum of 4 voters was reached (4 voted). The result is valid.",
+		},
+		"quorum not reached": {
+			Quorum:       5,
+			ExpectedText: "This poll has ended. The results are:\nThe quorum of 5 voters was not reached (4 voted). The result is not valid.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Quorum: test.Quorum})
+
+			post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+			require.Nil(t, err)
+			attachments := post.Attachments()
+			require.Len(t, attachments, 1)
+			assert.Equal(t, test.ExpectedText, attachments[0].Text)
+		})
+	}
+}
+
+func TestPollToEndPollPostWeighted(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 1, Weights: "user4:5"})
+	p.Weights = map[string]int{"userID4": 5}
+
+	post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	})
+	require.Nil(t, err)
+	fields := post.Attachments()[0].Fields
+	require.Len(t, fields, 3)
+	assert.Equal(t, "Answer 1 (3 votes, weight 3)", fields[0].Title)
+	assert.Equal(t, "Answer 2 (1 vote, weight 5)", fields[1].Title)
+	assert.Equal(t, "Answer 3 (0 votes, weight 0)", fields[2].Title)
+}
+
+func TestPollToEndPollPostScale(t *testing.T) {
+	p, errMsg := poll.NewPoll("userID1", "Question", nil, poll.Settings{MaxVotes: 1, ScaleMin: 1, ScaleMax: 3})
+	require.Nil(t, errMsg)
+	p.SetVoters(0, "userID1")
+	p.SetVoters(2, "userID2", "userID3")
+
+	post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	})
+	require.Nil(t, err)
+	attachments := post.Attachments()
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "This poll has ended. The results are:\n**Average**: 2.33, **Median**: 3", attachments[0].Text)
+	require.Len(t, attachments[0].Fields, 3)
+	assert.Equal(t, "1 (1 vote, 33%)", attachments[0].Fields[0].Title)
+	assert.Equal(t, "2 (0 votes, 0%)", attachments[0].Fields[1].Title)
+	assert.Equal(t, "3 (2 votes, 67%)", attachments[0].Fields[2].Title)
+}
+
+func TestPollToEndPollPostMeeting(t *testing.T) {
+	p, errMsg := poll.NewMeetingPoll("userID1", "Question", []string{"2021-10-01T15:00", "2021-10-02T15:00", "2021-10-03T15:00"}, poll.Settings{MaxVotes: 1}, poll.Limits{})
+	require.Nil(t, errMsg)
+	p.SetVoters(0, "userID1", "userID2")
+	p.SetVoters(1, "userID1")
+	p.SetVoters(2, "userID1", "userID2")
+
+	post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	})
+	require.Nil(t, err)
+	attachments := post.Attachments()
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "This poll has ended. The results are:\nMost available: **Fri, 2021-10-01 15:00 UTC** and **Sun, 2021-10-03 15:00 UTC** (2 votes)", attachments[0].Text)
+}
+
+func TestPollToEndPollPostRanked(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+
+	for name, test := range map[string]struct {
+		Rankings     map[string][]int
+		ExpectedText string
+	}{
+		"winner": {
+			Rankings:     map[string][]int{"userID1": {2, 0}, "userID2": {0}, "userID3": {0}, "userID4": {1}},
+			ExpectedText: "This poll has ended. The results are:\n**Answer 1** won the instant-runoff after 2 rounds.",
+		},
+		"no votes": {
+			Rankings:     nil,
+			ExpectedText: "This poll has ended. The results are:\nThe instant-runoff didn't determine a winner.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Ranked: true})
+			p.Rankings = test.Rankings
+
+			post, err := p.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+			require.Nil(t, err)
+			attachments := post.Attachments()
+			require.Len(t, attachments, 1)
+			assert.Equal(t, test.ExpectedText, attachments[0].Text)
+		})
+	}
+}
+
+func TestPollMarkdownResults(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+
+	for name, test := range map[string]struct {
+		Poll             *poll.Poll
+		ExpectedMarkdown string
+	}{
+		"Normal poll": {
+			Poll: testutils.GetPollWithVotes(),
+			ExpectedMarkdown: "#### Question\n" +
+				"- **Answer 1**: 3 votes: @userID1, @userID2 and @userID3\n" +
+				"- **Answer 2**: 1 vote: @userID4\n" +
+				"- **Answer 3**: 0 votes",
+		},
+		"Anonymous poll": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, MaxVotes: 1}),
+			ExpectedMarkdown: "#### Question\n" +
+				"- **Answer 1**: 3 votes\n" +
+				"- **Answer 2**: 1 vote\n" +
+				"- **Answer 3**: 0 votes",
+		},
+		"Progress poll": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Progress: true, MaxVotes: 1}),
+			ExpectedMarkdown: "#### Question\n" +
+				"- **Answer 1**: 3 votes (75.0%): @userID1, @userID2 and @userID3\n" +
+				"- **Answer 2**: 1 vote (25.0%): @userID4\n" +
+				"- **Answer 3**: 0 votes (0.0%)",
+		},
+		"Anonymous poll with progress": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, Progress: true, MaxVotes: 1}),
+			ExpectedMarkdown: "#### Question\n" +
+				"- **Answer 1**: 3 votes (75.0%)\n" +
+				"- **Answer 2**: 1 vote (25.0%)\n" +
+				"- **Answer 3**: 0 votes (0.0%)",
+		},
+		"Secret poll, before end": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true, MaxVotes: 1}),
+			ExpectedMarkdown: "#### Question\n" +
+				"- **Answer 1**\n" +
+				"- **Answer 2**\n" +
+				"- **Answer 3**",
+		},
+		"Reveal on end poll, before end": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, RevealOnEnd: true, MaxVotes: 1}),
+			ExpectedMarkdown: "#### Question\n" +
+				"- **Answer 1**: 3 votes\n" +
+				"- **Answer 2**: 1 vote\n" +
+				"- **Answer 3**: 0 votes",
+		},
+		"Reveal on end poll, after end": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, RevealOnEnd: true, MaxVotes: 1})
+				p.EndedAt = 1234567899
+				return p
+			}(),
+			ExpectedMarkdown: "#### Question\n" +
+				"- **Answer 1**: 3 votes: @userID1, @userID2 and @userID3\n" +
+				"- **Answer 2**: 1 vote: @userID4\n" +
+				"- **Answer 3**: 0 votes",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			markdown, err := test.Poll.MarkdownResults(testutils.GetLocalizer(), converter)
+
+			require.Nil(t, err)
+			assert.Equal(t, test.ExpectedMarkdown, markdown)
+		})
+	}
+
+	t.Run("converter fails", func(t *testing.T) {
+		converter := func(userID string) (string, *model.AppError) {
+			return "", &model.AppError{}
+		}
+		p := testutils.GetPollWithVotes()
+
+		markdown, err := p.MarkdownResults(testutils.GetLocalizer(), converter)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "", markdown)
+	})
+}
+
+func TestPollMarkdownResultsForUser(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true, SemiAnonymous: true, MaxVotes: 1})
+
+	t.Run("creator of a semi-anonymous poll", func(t *testing.T) {
+		markdown, err := p.MarkdownResultsForUser(testutils.GetLocalizer(), converter, p.Creator)
+
+		require.Nil(t, err)
+		assert.Equal(t, "#### Question\n"+
+			"- **Answer 1**: 3 votes: @userID1, @userID2 and @userID3\n"+
+			"- **Answer 2**: 1 vote: @userID4\n"+
+			"- **Answer 3**: 0 votes", markdown)
+	})
+	t.Run("other user", func(t *testing.T) {
+		markdown, err := p.MarkdownResultsForUser(testutils.GetLocalizer(), converter, "userID2")
+
+		require.Nil(t, err)
+		assert.Equal(t, "#### Question\n"+
+			"- **Answer 1**: 3 votes\n"+
+			"- **Answer 2**: 1 vote\n"+
+			"- **Answer 3**: 0 votes", markdown)
+	})
+}
@@ -0,0 +1,156 @@
+package poll
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// ErasedVoterPrefix is the prefix of the opaque tokens that replace the IDs of erased users, see EraseUser.
+const ErasedVoterPrefix = "erased_"
+
+// UserData is the data a poll stores about a single user. The json tags are part of the personal data export.
+type UserData struct {
+	PollID    string `json:"poll_id"`
+	Question  string `json:"question"`
+	CreatedAt int64  `json:"created_at"`
+	// Creator is true if the user created the poll.
+	Creator bool `json:"creator,omitempty"`
+	// AllowedVoter is true if the user is one of the users the poll is restricted to.
+	AllowedVoter bool `json:"allowed_voter,omitempty"`
+	// Votes contains the answers the user voted for. For ranked polls, they are in order of preference.
+	Votes []string `json:"votes"`
+	// Comment is the comment the user gave for the votes, see Settings.Comments.
+	Comment string `json:"comment,omitempty"`
+}
+
+// IsErasedVoter returns true if the given voter ID is the opaque token of an erased user.
+func IsErasedVoter(userID string) bool {
+	return strings.HasPrefix(userID, ErasedVoterPrefix)
+}
+
+// ContainsUser returns true if the poll stores the ID of a user, i.e. if the user created the poll,
+// voted in it, wrote in or suggested an answer option, commented, is one of the allowed voters or has a weight.
+func (p *Poll) ContainsUser(userID string) bool {
+	if p.Creator == userID || p.HasVoted(userID) || p.isAllowedVoter(userID) {
+		return true
+	}
+	for _, o := range p.AnswerOptions {
+		if o.AddedBy == userID {
+			return true
+		}
+	}
+	for _, o := range p.PendingOptions {
+		if o.SuggestedBy == userID {
+			return true
+		}
+	}
+	voterID := p.voterID(userID)
+	for _, v := range p.Votes {
+		if v.UserID == voterID {
+			return true
+		}
+	}
+	if _, ok := p.AddedOptions[voterID]; ok {
+		return true
+	}
+	for _, c := range p.Comments {
+		if c.UserID == voterID {
+			return true
+		}
+	}
+	if _, ok := p.Weights[userID]; ok {
+		return true
+	}
+	_, ok := p.Rankings[voterID]
+	return ok
+}
+
+// isAllowedVoter returns true if voting is restricted and the user is one of the allowed voters.
+func (p *Poll) isAllowedVoter(userID string) bool {
+	return len(p.AllowedVoters) > 0 && p.CanVote(userID)
+}
+
+// UserData returns the data the poll stores about a user.
+func (p *Poll) UserData(userID string) *UserData {
+	votes := p.GetVotedAnswers(userID)
+	if ranking, ok := p.Rankings[p.voterID(userID)]; ok {
+		votes = make([]string, 0, len(ranking))
+		for _, i := range ranking {
+			if i >= 0 && i < len(p.AnswerOptions) {
+				votes = append(votes, p.AnswerOptions[i].Answer)
+			}
+		}
+	}
+
+	return &UserData{
+		PollID:       p.ID,
+		Question:     p.Question,
+		CreatedAt:    p.CreatedAt,
+		Creator:      p.Creator == userID,
+		AllowedVoter: p.isAllowedVoter(userID),
+		Votes:        votes,
+		Comment:      p.GetComment(userID),
+	}
+}
+
+// EraseUser replaces the ID of a user in the votes, the vote log, the rankings, the counts of added answer options, the authors of answer options, the suggested answer options,
+// the comments, the allowed voters and the weights with an opaque token.
+// The same token is used for all occurrences, so the number of votes and voters doesn't change.
+// The creator of the poll is kept. It returns true if the poll was modified.
+func (p *Poll) EraseUser(userID string) bool {
+	token := ErasedVoterPrefix + model.NewId()
+	voterID := p.voterID(userID)
+	erased := p.renameVoter(voterID, token)
+	for _, o := range p.AnswerOptions {
+		if o.AddedBy == userID {
+			o.AddedBy = token
+			erased = true
+		}
+	}
+	for _, o := range p.PendingOptions {
+		if o.SuggestedBy == userID {
+			o.SuggestedBy = token
+			erased = true
+		}
+	}
+	for _, v := range p.Votes {
+		if v.UserID == voterID {
+			v.UserID = token
+			erased = true
+		}
+	}
+	if ranking, ok := p.Rankings[voterID]; ok {
+		delete(p.Rankings, voterID)
+		p.Rankings[token] = ranking
+		erased = true
+	}
+	if count, ok := p.AddedOptions[voterID]; ok {
+		delete(p.AddedOptions, voterID)
+		p.AddedOptions[token] = count
+		erased = true
+	}
+	for _, c := range p.Comments {
+		if c.UserID == voterID {
+			c.UserID = token
+			erased = true
+		}
+	}
+	for i, v := range p.AllowedVoters {
+		if v == userID {
+			p.AllowedVoters[i] = token
+			erased = true
+		}
+	}
+	if weight, ok := p.Weights[userID]; ok {
+		// Erased IDs aren't hashed, so the erased votes keep their weight
+		delete(p.Weights, userID)
+		p.Weights[token] = weight
+		erased = true
+	}
+
+	if erased {
+		p.touch()
+	}
+	return erased
+}
@@ -0,0 +1,165 @@
+package poll_test
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollContainsUser(t *testing.T) {
+	p := testutils.GetPoll()
+	p.SetVoters(0, "userID2")
+	p.AllowedVoters = []string{"userID2", "userID3"}
+	p.Rankings = map[string][]int{"userID4": {1}}
+	p.AnswerOptions[2].AddedBy = "userID6"
+	p.PendingOptions = []*poll.PendingOption{{ID: "optionID1", Answer: "New Option", SuggestedBy: "userID7"}}
+	p.Votes = []*poll.Vote{{UserID: "userID8", Answer: 1, CreatedAt: 1234567890, RemovedAt: 1234627890}}
+	p.Comments = []*poll.Comment{{UserID: "userID9", Text: "Cheaper"}}
+
+	assert.True(t, p.ContainsUser("userID1"))
+	assert.True(t, p.ContainsUser("userID2"))
+	assert.True(t, p.ContainsUser("userID3"))
+	assert.True(t, p.ContainsUser("userID4"))
+	assert.False(t, p.ContainsUser("userID5"))
+	assert.True(t, p.ContainsUser("userID6"))
+	assert.True(t, p.ContainsUser("userID7"))
+	assert.True(t, p.ContainsUser("userID8"))
+	assert.True(t, p.ContainsUser("userID9"))
+}
+
+func TestPollUserData(t *testing.T) {
+	t.Run("votes", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2})
+		p.SetVoters(0, "userID2")
+		p.SetVoters(2, "userID2")
+
+		assert.Equal(t, &poll.UserData{
+			PollID:    testutils.GetPollID(),
+			Question:  "Question",
+			CreatedAt: 1234567890,
+			Votes:     []string{"Answer 1", "Answer 3"},
+		}, p.UserData("userID2"))
+	})
+	t.Run("creator and allowed voter without votes", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.AllowedVoters = []string{"userID1"}
+
+		assert.Equal(t, &poll.UserData{
+			PollID:       testutils.GetPollID(),
+			Question:     "Question",
+			CreatedAt:    1234567890,
+			Creator:      true,
+			AllowedVoter: true,
+			Votes:        []string{},
+		}, p.UserData("userID1"))
+	})
+	t.Run("ranked poll", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Ranked: true})
+		p.SetVoters(0, "userID2")
+		p.SetVoters(2, "userID2")
+		p.Rankings = map[string][]int{"userID2": {2, 0}}
+
+		assert.Equal(t, []string{"Answer 3", "Answer 1"}, p.UserData("userID2").Votes)
+	})
+	t.Run("comment", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Comments: true})
+		p.SetVoters(0, "userID2")
+		p.Comments = []*poll.Comment{{UserID: "userID2", Text: "Cheaper"}}
+
+		assert.Equal(t, "Cheaper", p.UserData("userID2").Comment)
+	})
+}
+
+func TestPollEraseUser(t *testing.T) {
+	patch := monkey.Patch(model.NewId, func() string { return "token" })
+	defer patch.Unpatch()
+
+	t.Run("votes, rankings and allowed voters", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Ranked: true})
+		p.SetVoters(0, "userID2", "userID3")
+		p.SetVoters(1, "userID2")
+		p.Rankings = map[string][]int{"userID2": {1, 0}, "userID3": {0}}
+		p.AllowedVoters = []string{"userID2", "userID3"}
+		version := p.Version
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, []string{"erased_token", "userID3"}, p.Voters(0))
+		assert.Equal(t, []string{"erased_token"}, p.Voters(1))
+		assert.Equal(t, map[string][]int{"erased_token": {1, 0}, "userID3": {0}}, p.Rankings)
+		assert.Equal(t, []string{"erased_token", "userID3"}, p.AllowedVoters)
+		assert.Equal(t, 2, p.VoterCount())
+		assert.Equal(t, version+1, p.Version)
+		assert.False(t, p.ContainsUser("userID2"))
+	})
+	t.Run("vote log", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.Votes = []*poll.Vote{{UserID: "userID2", Answer: 1, CreatedAt: 1234567890, RemovedAt: 1234627890}}
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, "erased_token", p.Votes[0].UserID)
+		assert.False(t, p.ContainsUser("userID2"))
+	})
+	t.Run("added answer options", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.AddedOptions = map[string]int{"userID2": 2}
+
+		assert.True(t, p.ContainsUser("userID2"))
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, map[string]int{"erased_token": 2}, p.AddedOptions)
+		assert.False(t, p.ContainsUser("userID2"))
+	})
+	t.Run("weights", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Weights: "user2:3"})
+		p.SetVoters(0, "userID2")
+		p.Weights = map[string]int{"userID2": 3}
+
+		assert.True(t, p.ContainsUser("userID2"))
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, map[string]int{"erased_token": 3}, p.Weights)
+		assert.Equal(t, []int{3, 0, 0}, p.WeightedCounts())
+		assert.False(t, p.ContainsUser("userID2"))
+	})
+	t.Run("author of an answer option", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.AnswerOptions[2].AddedBy = "userID2"
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, "erased_token", p.AnswerOptions[2].AddedBy)
+		assert.False(t, p.ContainsUser("userID2"))
+	})
+	t.Run("author of a suggested answer option", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.PendingOptions = []*poll.PendingOption{{ID: "optionID1", Answer: "New Option", SuggestedBy: "userID2"}}
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, "erased_token", p.PendingOptions[0].SuggestedBy)
+		assert.False(t, p.ContainsUser("userID2"))
+	})
+	t.Run("comment", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 1, Comments: true})
+		p.SetVoters(0, "userID2")
+		p.Comments = []*poll.Comment{{UserID: "userID2", Text: "Cheaper"}}
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, []*poll.Comment{{UserID: "erased_token", Text: "Cheaper"}}, p.Comments)
+		assert.False(t, p.ContainsUser("userID2"))
+	})
+	t.Run("creator is kept", func(t *testing.T) {
+		p := testutils.GetPoll()
+		version := p.Version
+
+		assert.False(t, p.EraseUser("userID1"))
+		assert.Equal(t, "userID1", p.Creator)
+		assert.Equal(t, version, p.Version)
+	})
+}
+
+func TestIsErasedVoter(t *testing.T) {
+	assert.True(t, poll.IsErasedVoter("erased_token"))
+	assert.False(t, poll.IsErasedVoter("userID1"))
+}
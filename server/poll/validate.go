@@ -0,0 +1,236 @@
+package poll
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// Limits restricts the size and the content of polls. A field of zero or less falls back to MaxQuestionLength,
+// MaxAnswerOptions or MaxAnswerLength.
+type Limits struct {
+	MaxQuestionLength int `json:"max_question_length,omitempty"`
+	MaxAnswerOptions  int `json:"max_answer_options,omitempty"`
+	// MaxAnswerLength is the maximum number of characters of an answer option.
+	MaxAnswerLength int `json:"max_answer_length,omitempty"`
+	// BannedCharacters contains the characters questions and answer options must not contain.
+	BannedCharacters string `json:"banned_characters,omitempty"`
+}
+
+func (l Limits) maxQuestionLength() int {
+	if l.MaxQuestionLength > 0 {
+		return l.MaxQuestionLength
+	}
+	return MaxQuestionLength
+}
+
+func (l Limits) maxAnswerOptions() int {
+	if l.MaxAnswerOptions > 0 {
+		return l.MaxAnswerOptions
+	}
+	return MaxAnswerOptions
+}
+
+func (l Limits) maxAnswerLength() int {
+	if l.MaxAnswerLength > 0 {
+		return l.MaxAnswerLength
+	}
+	return MaxAnswerLength
+}
+
+// limits returns the limits the poll was created with.
+func (p *Poll) limits() Limits {
+	if p.Limits == nil {
+		return Limits{}
+	}
+	return *p.Limits
+}
+
+// Validate checks if the question, the answer options and the settings of a poll are valid
+// and within the limits the poll was created with.
+func (p *Poll) Validate() *ErrorMessage {
+	limits := p.limits()
+	if errMsg := limits.validateQuestion(p.Question); errMsg != nil {
+		return errMsg
+	}
+
+	active := p.ActiveOptions()
+	if len(active) < MinAnswerOptions {
+		return newTooFewOptionsError(len(active))
+	}
+	if errMsg := limits.validateAnswerOptionCount(len(p.AnswerOptions)); errMsg != nil {
+		return errMsg
+	}
+	for _, o := range active {
+		if errMsg := limits.validateAnswerText(o.Answer); errMsg != nil {
+			return errMsg
+		}
+	}
+
+	if p.Settings.Reactions && len(p.AnswerOptions) > MaxReactionOptions {
+		return newTooManyReactionOptionsError()
+	}
+	if (p.Settings.MaxVotes <= 0 && !p.Settings.HasUnlimitedVotes()) || p.Settings.MaxVotes > len(p.AnswerOptions) {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.votesettings.invalidSetting",
+				Other: `The number of votes must be zero for unlimited votes or a positive number less than or equal to the number of options. You specified "{{.MaxVotes}}", but the number of options is "{{.Options}}".`,
+			},
+			Data: map[string]interface{}{
+				"MaxVotes": p.Settings.MaxVotes,
+				"Options":  len(p.AnswerOptions),
+			},
+		}
+	}
+	if p.Settings.Quiz > len(p.AnswerOptions) {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.quizSettings.unknownOption",
+				Other: `The correct answer must be one of the options. You specified "{{.Quiz}}", but the number of options is "{{.Options}}".`,
+			},
+			Data: map[string]interface{}{
+				"Quiz":    p.Settings.Quiz,
+				"Options": len(p.AnswerOptions),
+			},
+		}
+	}
+	for _, number := range p.Settings.RequireComment {
+		if number > len(p.AnswerOptions) {
+			return &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.newPoll.requireCommentSettings.unknownOption",
+					Other: `The options that need a comment must be options of the poll. You specified "{{.Number}}", but the number of options is "{{.Options}}".`,
+				},
+				Data: map[string]interface{}{
+					"Number":  number,
+					"Options": len(p.AnswerOptions),
+				},
+			}
+		}
+	}
+	return nil
+}
+
+// validateQuestion checks if a question doesn't exceed the maximum length and contains no banned characters.
+// The length is counted in runes, so multibyte characters count as one character.
+func (l Limits) validateQuestion(question string) *ErrorMessage {
+	if length, limit := utf8.RuneCountInString(question), l.maxQuestionLength(); length > limit {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.question.tooLong",
+				Other: "The question must not be longer than {{.Limit}} characters, but it is {{.Length}} characters long.",
+			},
+			Data: map[string]interface{}{
+				"Limit":  limit,
+				"Length": length,
+			},
+		}
+	}
+	return l.validateCharacters(question)
+}
+
+// validateAnswerText checks if the text of an answer option doesn't exceed the maximum length and contains
+// neither banned characters nor line breaks, which the buttons of the poll can't show.
+// The length is counted in runes, so multibyte characters count as one character.
+func (l Limits) validateAnswerText(answer string) *ErrorMessage {
+	if length, limit := utf8.RuneCountInString(answer), l.maxAnswerLength(); length > limit {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.addAnswerOption.tooLong",
+				Other: "The option must not be longer than {{.Limit}} characters, but it is {{.Length}} characters long.",
+			},
+			Data: map[string]interface{}{
+				"Limit":  limit,
+				"Length": length,
+			},
+		}
+	}
+	if strings.IndexFunc(answer, unicode.IsControl) != -1 {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.addAnswerOption.controlCharacter",
+				Other: "Options must not contain line breaks or other control characters.",
+			},
+		}
+	}
+	return l.validateCharacters(answer)
+}
+
+// validateCharacters checks if a text contains none of the banned characters.
+func (l Limits) validateCharacters(text string) *ErrorMessage {
+	if i := strings.IndexAny(text, l.BannedCharacters); i != -1 {
+		r, _ := utf8.DecodeRuneInString(text[i:])
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.bannedCharacter",
+				Other: `The character "{{.Character}}" is not allowed in questions and options.`,
+			},
+			Data: map[string]interface{}{
+				"Character": string(r),
+			},
+		}
+	}
+	return nil
+}
+
+// validateAnswerOptionCount checks if a poll with count answer options doesn't exceed the maximum number of answer options.
+func (l Limits) validateAnswerOptionCount(count int) *ErrorMessage {
+	if max := l.maxAnswerOptions(); count > max {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.newPoll.tooManyOptions",
+				Other: "A poll can have at most {{.Max}} options, but you specified {{.Options}}.",
+			},
+			Data: map[string]interface{}{
+				"Max":     max,
+				"Options": count,
+			},
+		}
+	}
+	return nil
+}
+
+func newTooFewOptionsError(options int) *ErrorMessage {
+	return &ErrorMessage{
+		Message: &i18n.Message{
+			ID:    "poll.newPoll.tooFewOptions",
+			Other: "A poll needs at least {{.Min}} options, but you specified {{.Options}}.",
+		},
+		Data: map[string]interface{}{
+			"Min":     MinAnswerOptions,
+			"Options": options,
+		},
+	}
+}
+
+// validateAnswerOption checks if a trimmed answer option is neither empty nor a duplicate of an existing answer option
+// and within the limits of the poll. The answer option at index skip is not checked for duplicates.
+func (p *Poll) validateAnswerOption(answerOption string, skip int) *ErrorMessage {
+	if answerOption == "" {
+		return &ErrorMessage{
+			Message: &i18n.Message{
+				ID:    "poll.addAnswerOption.empty",
+				Other: "Empty option not allowed",
+			},
+		}
+	}
+	if errMsg := p.limits().validateAnswerText(answerOption); errMsg != nil {
+		return errMsg
+	}
+	for i, o := range p.AnswerOptions {
+		if i != skip && o.Answer == answerOption {
+			return &ErrorMessage{
+				Message: &i18n.Message{
+					ID:    "poll.addAnswerOption.duplicate",
+					Other: "Duplicate option: {{.Option}}",
+				},
+				Data: map[string]interface{}{
+					"Option": answerOption,
+				},
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package poll
+
+// PollView provides read-only access to a poll.
+// Functions that only read a poll should accept a PollView, so they can't modify it by accident.
+type PollView interface { //nolint:golint
+	GetID() string
+	GetCreator() string
+	GetQuestion() string
+	GetOptions() []*AnswerOption
+	GetSettings() Settings
+	TotalVotes() int
+	VoterCount() int
+	HasVoted(userID string) bool
+}
+
+var _ PollView = (*Poll)(nil)
+
+// GetID returns the ID of the poll
+func (p *Poll) GetID() string {
+	return p.ID
+}
+
+// GetCreator returns the user ID of the poll creator
+func (p *Poll) GetCreator() string {
+	return p.Creator
+}
+
+// GetQuestion returns the question of the poll
+func (p *Poll) GetQuestion() string {
+	return p.Question
+}
+
+// GetOptions returns a copy of the answer options, so modifying them doesn't affect the poll.
+func (p *Poll) GetOptions() []*AnswerOption {
+	return p.Copy().AnswerOptions
+}
+
+// GetSettings returns the settings of the poll
+func (p *Poll) GetSettings() Settings {
+	return p.Settings
+}
@@ -0,0 +1,46 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollView(t *testing.T) {
+	var v poll.PollView = testutils.GetPollWithVotesAndSettings(poll.Settings{Progress: true, MaxVotes: 2})
+
+	assert.Equal(t, testutils.GetPollID(), v.GetID())
+	assert.Equal(t, "userID1", v.GetCreator())
+	assert.Equal(t, "Question", v.GetQuestion())
+	assert.Equal(t, poll.Settings{Progress: true, MaxVotes: 2}, v.GetSettings())
+	assert.Equal(t, 4, v.TotalVotes())
+	assert.Equal(t, 4, v.VoterCount())
+	assert.True(t, v.HasVoted("userID1"))
+	assert.Equal(t, testutils.GetPollWithVotes().AnswerOptions, v.GetOptions())
+}
+
+func TestPollViewGetOptions(t *testing.T) {
+	p := testutils.GetPollWithVotes()
+
+	options := p.GetOptions()
+	require.Len(t, options, 3)
+	options[0].Answer = "Changed"
+	options[1].Deleted = true
+	options = append(options, &poll.AnswerOption{Answer: "Answer 4"})
+
+	assert.Len(t, options, 4)
+	assert.Equal(t, testutils.GetPollWithVotes(), p)
+}
+
+func TestPollViewGetSettings(t *testing.T) {
+	p := testutils.GetPoll()
+
+	settings := p.GetSettings()
+	settings.Anonymous = true
+
+	assert.Equal(t, testutils.GetPoll(), p)
+}
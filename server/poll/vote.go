@@ -0,0 +1,67 @@
+package poll
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// Vote is an entry of the vote log of a poll. It records when a voter voted for an answer option
+// and when the vote was removed again, e.g. because the voter changed it.
+type Vote struct {
+	// UserID is the voter ID of the user, i.e. a hash for polls that hash their voters.
+	UserID string `json:"user_id"`
+	// Answer is the index of the answer option the vote was cast for.
+	Answer int `json:"answer"`
+	// CreatedAt is the time the vote was cast in milliseconds.
+	CreatedAt int64 `json:"created_at"`
+	// RemovedAt is the time the vote was removed in milliseconds. Zero means the vote still counts.
+	RemovedAt int64 `json:"removed_at,omitempty"`
+}
+
+// recordVote adds the vote of a voter for the answer option at index to the vote log.
+func (p *Poll) recordVote(voterID string, index int) {
+	p.Votes = append(p.Votes, &Vote{
+		UserID:    voterID,
+		Answer:    index,
+		CreatedAt: model.GetMillis(),
+	})
+}
+
+// recordRemovedVotes marks all votes of a voter for the answer option at index in the vote log as removed.
+func (p *Poll) recordRemovedVotes(voterID string, index int) {
+	now := model.GetMillis()
+	for _, v := range p.Votes {
+		if v.UserID == voterID && v.Answer == index && v.RemovedAt == 0 {
+			v.RemovedAt = now
+		}
+	}
+}
+
+// removeVote removes the vote of a voter for the answer option at index and records it in the vote log.
+// It returns true if a vote was removed.
+func (p *Poll) removeVote(voterID string, index int) bool {
+	if !p.removeBallotVote(voterID, index) {
+		return false
+	}
+	p.recordRemovedVotes(voterID, index)
+	return true
+}
+
+// removeOptionFromVotes removes the votes for the answer option at index from the votes and the vote log
+// and updates the indexes of the following answer options after the option was removed.
+func (p *Poll) removeOptionFromVotes(index int) {
+	p.removeOptionFromBallots(index)
+	if p.Votes == nil {
+		return
+	}
+	votes := p.Votes[:0]
+	for _, v := range p.Votes {
+		if v.Answer == index {
+			continue
+		}
+		if v.Answer > index {
+			v.Answer--
+		}
+		votes = append(votes, v)
+	}
+	p.Votes = votes
+}
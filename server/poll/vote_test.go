@@ -0,0 +1,69 @@
+package poll_test
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestPollVoteLog(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	t.Run("changed vote", func(t *testing.T) {
+		p := testutils.GetPoll()
+		require.Nil(t, p.UpdateVote("userID2", 0))
+		now = 1234627890
+		require.Nil(t, p.UpdateVote("userID2", 1))
+
+		assert.Equal(t, []*poll.Vote{
+			{UserID: "userID2", Answer: 0, CreatedAt: 1234567890, RemovedAt: 1234627890},
+			{UserID: "userID2", Answer: 1, CreatedAt: 1234627890},
+		}, p.Votes)
+	})
+	t.Run("toggled and reset votes", func(t *testing.T) {
+		now = 1234567890
+		p := testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2})
+		require.Nil(t, p.UpdateVote("userID2", 0))
+		require.Nil(t, p.UpdateVote("userID2", 2))
+		now = 1234627890
+		removed, err := p.ToggleVote("userID2", 0)
+		require.Nil(t, err)
+		require.True(t, removed)
+		now = 1234687890
+		p.ResetVotes("userID2")
+
+		assert.Equal(t, []*poll.Vote{
+			{UserID: "userID2", Answer: 0, CreatedAt: 1234567890, RemovedAt: 1234627890},
+			{UserID: "userID2", Answer: 2, CreatedAt: 1234567890, RemovedAt: 1234687890},
+		}, p.Votes)
+	})
+	t.Run("pruned answer option", func(t *testing.T) {
+		now = 1234567890
+		p := testutils.GetPoll()
+		require.Nil(t, p.UpdateVote("userID2", 1))
+		require.Nil(t, p.UpdateVote("userID2", 2))
+
+		assert.Equal(t, 1, p.PruneEmptyOptions())
+		assert.Equal(t, []*poll.Vote{
+			{UserID: "userID2", Answer: 1, CreatedAt: 1234567890},
+		}, p.Votes)
+	})
+	t.Run("copy", func(t *testing.T) {
+		now = 1234567890
+		p := testutils.GetPoll()
+		require.Nil(t, p.UpdateVote("userID2", 0))
+		p2 := p.Copy()
+		p2.Votes[0].RemovedAt = 1234627890
+
+		assert.Equal(t, int64(0), p.Votes[0].RemovedAt)
+		assert.Nil(t, p.CloneWithNewID("userID1").Votes)
+	})
+}
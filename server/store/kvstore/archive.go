@@ -0,0 +1,77 @@
+package kvstore
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/plugin"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// ArchiveStore allows to access archived polls in the KV Store.
+// Every archived poll is stored under a key that starts with the ID of its channel,
+// so that the archived polls of a channel can be listed without reading all of them.
+type ArchiveStore struct {
+	api plugin.API
+}
+
+const archivePrefix = "archive_"
+
+func archiveKey(channelID, pollID string) string {
+	return archivePrefix + channelID + "_" + pollID
+}
+
+// Save stores an archived poll in the KV Store. An existing archived poll with the same ID is replaced.
+func (s *ArchiveStore) Save(archivedPoll *poll.ArchivedPoll) error {
+	if err := s.api.KVSet(archiveKey(archivedPoll.ChannelID, archivedPoll.ID), archivedPoll.EncodeToByte()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes an archived poll from the KV Store.
+func (s *ArchiveStore) Delete(channelID, pollID string) error {
+	if err := s.api.KVDelete(archiveKey(channelID, pollID)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListByChannel returns the archived polls of a channel, most recently closed first.
+func (s *ArchiveStore) ListByChannel(channelID string) ([]*poll.ArchivedPoll, error) {
+	prefix := archiveKey(channelID, "")
+	archivedPolls := []*poll.ArchivedPoll{}
+	for page := 0; ; page++ {
+		keys, appErr := s.api.KVList(page, listPerPage)
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		for _, key := range keys {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+
+			b, appErr := s.api.KVGet(key)
+			if appErr != nil {
+				return nil, appErr
+			}
+			archivedPoll := poll.DecodeArchivedPollFromByte(b)
+			if archivedPoll == nil {
+				return nil, errors.New("failed to decode archived poll")
+			}
+			archivedPolls = append(archivedPolls, archivedPoll)
+		}
+
+		if len(keys) < listPerPage {
+			break
+		}
+	}
+
+	sort.SliceStable(archivedPolls, func(i, j int) bool { return archivedPolls[i].ClosedAt > archivedPolls[j].ClosedAt })
+	return archivedPolls, nil
+}
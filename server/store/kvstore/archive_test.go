@@ -0,0 +1,89 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func TestArchiveStoreSave(t *testing.T) {
+	archivedPoll := &poll.ArchivedPoll{ID: "pollID1", ChannelID: "channelID1", Question: "Question", Answers: []string{"A"}, Votes: []int{1}}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", archivePrefix+"channelID1_pollID1", archivedPoll.EncodeToByte()).Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Archive().Save(archivedPoll)
+		require.NoError(t, err)
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", archivePrefix+"channelID1_pollID1", archivedPoll.EncodeToByte()).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Archive().Save(archivedPoll)
+		assert.Error(t, err)
+	})
+}
+
+func TestArchiveStoreDelete(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("KVDelete", archivePrefix+"channelID1_pollID1").Return(nil)
+	defer api.AssertExpectations(t)
+	store := setupTestStore(api)
+
+	err := store.Archive().Delete("channelID1", "pollID1")
+	require.NoError(t, err)
+}
+
+func TestArchiveStoreListByChannel(t *testing.T) {
+	older := &poll.ArchivedPoll{ID: "pollID1", ChannelID: "channelID1", ClosedAt: 1000, Answers: []string{}, Votes: []int{}}
+	newer := &poll.ArchivedPoll{ID: "pollID2", ChannelID: "channelID1", ClosedAt: 2000, Answers: []string{}, Votes: []int{}}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{
+			archivePrefix + "channelID1_pollID1",
+			archivePrefix + "channelID2_pollID3",
+			pollPrefix + "pollID4",
+			archivePrefix + "channelID1_pollID2",
+		}, nil)
+		api.On("KVGet", archivePrefix+"channelID1_pollID1").Return(older.EncodeToByte(), nil)
+		api.On("KVGet", archivePrefix+"channelID1_pollID2").Return(newer.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		archivedPolls, err := store.Archive().ListByChannel("channelID1")
+		require.NoError(t, err)
+		assert.Equal(t, []*poll.ArchivedPoll{newer, older}, archivedPolls)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		archivedPolls, err := store.Archive().ListByChannel("channelID1")
+		assert.Error(t, err)
+		assert.Nil(t, archivedPolls)
+	})
+	t.Run("invalid data", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{archivePrefix + "channelID1_pollID1"}, nil)
+		api.On("KVGet", archivePrefix+"channelID1_pollID1").Return([]byte("{}"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		archivedPolls, err := store.Archive().ListByChannel("channelID1")
+		assert.Error(t, err)
+		assert.Nil(t, archivedPolls)
+	})
+}
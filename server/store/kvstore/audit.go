@@ -0,0 +1,68 @@
+package kvstore
+
+import (
+	"errors"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+// AuditStore allows to access the audit log in the KV Store.
+// The records of every UTC day are stored together under a single key, so that no key grows without bounds.
+type AuditStore struct {
+	api plugin.API
+}
+
+const auditPrefix = "audit_"
+
+// Append adds a record to the audit log of its day using an atomic compare-and-set.
+// store.ErrConflict is returned if the audit log was modified concurrently.
+func (s *AuditStore) Append(record *poll.AuditRecord) error {
+	day := record.Day()
+	records, b, err := s.get(day)
+	if err != nil {
+		return err
+	}
+
+	opt := model.PluginKVSetOptions{
+		Atomic:   true,
+		OldValue: b,
+	}
+	ok, appErr := s.api.KVSetWithOptions(auditPrefix+day, poll.EncodeAuditRecordsToByte(append(records, record)), opt)
+	if appErr != nil {
+		return appErr
+	}
+
+	if !ok {
+		return store.ErrConflict
+	}
+
+	return nil
+}
+
+// List returns the records of a UTC day in the order they were added.
+func (s *AuditStore) List(day string) ([]*poll.AuditRecord, error) {
+	records, _, err := s.get(day)
+	return records, err
+}
+
+// get returns the records of a day together with their raw value in the KV Store.
+func (s *AuditStore) get(day string) ([]*poll.AuditRecord, []byte, error) {
+	b, appErr := s.api.KVGet(auditPrefix + day)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	if b == nil {
+		return []*poll.AuditRecord{}, nil, nil
+	}
+
+	records := poll.DecodeAuditRecordsFromByte(b)
+	if records == nil {
+		return nil, nil, errors.New("failed to decode audit records")
+	}
+
+	return records, b, nil
+}
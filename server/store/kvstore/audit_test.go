@@ -0,0 +1,99 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+func TestAuditStoreAppend(t *testing.T) {
+	recordA := &poll.AuditRecord{CreatedAt: 1234567890, Event: "poll_created", ActorID: "userID1", PollID: "pollID1", Question: "Question"}
+	recordB := &poll.AuditRecord{CreatedAt: 1234567899, Event: "poll_ended", ActorID: "userID1", PollID: "pollID1", Question: "Question"}
+
+	t.Run("first record of the day", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"1970-01-15").Return(nil, nil)
+		api.On("KVSetWithOptions", auditPrefix+"1970-01-15", poll.EncodeAuditRecordsToByte([]*poll.AuditRecord{recordA}), model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: nil,
+		}).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		require.NoError(t, kvStore.Audit().Append(recordA))
+	})
+	t.Run("records are appended", func(t *testing.T) {
+		old := poll.EncodeAuditRecordsToByte([]*poll.AuditRecord{recordA})
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"1970-01-15").Return(old, nil)
+		api.On("KVSetWithOptions", auditPrefix+"1970-01-15", poll.EncodeAuditRecordsToByte([]*poll.AuditRecord{recordA, recordB}), model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: old,
+		}).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		require.NoError(t, kvStore.Audit().Append(recordB))
+	})
+	t.Run("concurrent modification", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"1970-01-15").Return(nil, nil)
+		api.On("KVSetWithOptions", auditPrefix+"1970-01-15", poll.EncodeAuditRecordsToByte([]*poll.AuditRecord{recordA}), model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: nil,
+		}).Return(false, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		assert.Equal(t, store.ErrConflict, kvStore.Audit().Append(recordA))
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"1970-01-15").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		assert.Error(t, kvStore.Audit().Append(recordA))
+	})
+}
+
+func TestAuditStoreList(t *testing.T) {
+	records := []*poll.AuditRecord{{CreatedAt: 1234567890, Event: "poll_created", ActorID: "userID1", PollID: "pollID1", Question: "Question"}}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"1970-01-15").Return(poll.EncodeAuditRecordsToByte(records), nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		rRecords, err := kvStore.Audit().List("1970-01-15")
+		require.NoError(t, err)
+		assert.Equal(t, records, rRecords)
+	})
+	t.Run("no records", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"1970-01-15").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		rRecords, err := kvStore.Audit().List("1970-01-15")
+		require.NoError(t, err)
+		assert.Empty(t, rRecords)
+	})
+	t.Run("invalid data", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"1970-01-15").Return([]byte("invalid"), nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		rRecords, err := kvStore.Audit().List("1970-01-15")
+		assert.Error(t, err)
+		assert.Nil(t, rRecords)
+	})
+}
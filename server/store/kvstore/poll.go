@@ -1,12 +1,16 @@
 package kvstore
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"strings"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin"
 
 	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
 )
 
 // PollStore allows to access polls in the KV Store.
@@ -14,73 +18,447 @@ type PollStore struct {
 	api plugin.API
 }
 
-const pollPrefix = "poll_"
+const (
+	pollPrefix = "poll_"
+	// votesPrefix is the prefix of the keys of the votes of the polls, see poll.Poll.EncodeVotesToByte. They're
+	// stored apart from the polls, so a vote doesn't rewrite the whole poll. It must not start with pollPrefix.
+	votesPrefix = "votes_"
+	// channelIndexPrefix is the prefix of the keys of the lists of the IDs of the polls posted in a channel
+	// and keywordIndexPrefix the one of the lists of the IDs of the polls containing a keyword.
+	// They must not start with pollPrefix, see ListIDs.
+	channelIndexPrefix = "channel_polls_"
+	keywordIndexPrefix = "keyword_polls_"
+	// indexAttempts is the number of times an update of an index is tried, if the index is updated concurrently.
+	indexAttempts = 5
 
-// Get returns the poll for a given id. Returns an error if the poll doesn't exist or a KV Store error occurred.
+	// listPerPage is the number of keys fetched per KV Store request when listing polls.
+	listPerPage = 100
+)
+
+// Get returns the poll for a given id. Returns store.ErrNotFound if the poll doesn't exist
+// and an error wrapping poll.ErrCorruptPoll if the stored data is corrupt.
 func (s *PollStore) Get(id string) (*poll.Poll, error) {
 	b, err := s.api.KVGet(pollPrefix + id)
 	if err != nil {
 		return nil, err
 	}
+	if len(b) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	votes, err := s.api.KVGet(votesPrefix + id)
+	if err != nil {
+		return nil, err
+	}
 
-	poll := poll.DecodePollFromByte(b)
-	if poll == nil {
-		return nil, errors.New("failed to decode poll")
+	poll, decodeErr := poll.DecodePollFromByte(b, false)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	if decodeErr := poll.DecodeVotesFromByte(votes); decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	// Polls stored in an older format are migrated on read. The migrated poll is stored right away,
+	// so updates, which compare against the stored data, don't fail. A concurrent migration is no error.
+	if err := s.set(id, b, votes, poll.EncodeWithoutVotesToByte(), poll.EncodeVotesToByte()); err != nil && err != store.ErrConflict {
+		return nil, err
 	}
 
 	return poll, nil
 }
 
-// Insert stores new a poll in the KV Store.
+// Insert stores new a poll in the KV Store using atomic compare-and-sets, see set.
 func (s *PollStore) Insert(poll *poll.Poll) error {
-	opt := model.PluginKVSetOptions{
-		Atomic:   true,
-		OldValue: nil,
-	}
-	ok, err := s.api.KVSetWithOptions(pollPrefix+poll.ID, poll.EncodeToByte(), opt)
-	if err != nil {
+	if err := s.set(poll.ID, nil, nil, poll.EncodeWithoutVotesToByte(), poll.EncodeVotesToByte()); err != nil {
+		if err == store.ErrConflict {
+			return errors.New("poll already exists in database")
+		}
 		return err
 	}
 
-	if !ok {
-		return errors.New("poll already exists in database")
-	}
-
+	s.indexPoll(poll)
 	return nil
 }
 
 // Save stores a poll in the KV Store. Overwrittes any existing poll with the same id.
 func (s *PollStore) Save(poll *poll.Poll) error {
-	if err := s.api.KVSet(pollPrefix+poll.ID, poll.EncodeToByte()); err != nil {
+	if err := s.api.KVSet(pollPrefix+poll.ID, poll.EncodeWithoutVotesToByte()); err != nil {
+		return err
+	}
+	if err := s.api.KVSet(votesPrefix+poll.ID, poll.EncodeVotesToByte()); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// Update updates an existing a poll in the KV Store.
+// Update updates an existing a poll in the KV Store using atomic compare-and-sets, see set.
+// store.ErrConflict is returned if the stored poll doesn't equal prev anymore.
 func (s *PollStore) Update(prev *poll.Poll, new *poll.Poll) error {
+	if err := s.set(prev.ID, prev.EncodeWithoutVotesToByte(), prev.EncodeVotesToByte(), new.EncodeWithoutVotesToByte(), new.EncodeVotesToByte()); err != nil {
+		return err
+	}
+
+	s.reindexPoll(prev, new)
+	return nil
+}
+
+// set replaces the stored poll and its votes, which are prev and prevVotes, with new and newVotes.
+// Only the values that changed are written, each with an atomic compare-and-set. If only one of them changed,
+// the other one is read again afterwards, so an update of it that ran concurrently is detected, too.
+// On a conflict, a value that was already written is restored and store.ErrConflict is returned.
+func (s *PollStore) set(id string, prev, prevVotes, new, newVotes []byte) error {
+	pollKey, votesKey := pollPrefix+id, votesPrefix+id
+	pollChanged, votesChanged := !bytes.Equal(prev, new), !bytes.Equal(prevVotes, newVotes)
+
+	if votesChanged {
+		if err := s.compareAndSet(votesKey, prevVotes, newVotes); err != nil {
+			return err
+		}
+		var check error
+		if pollChanged {
+			check = s.compareAndSet(pollKey, prev, new)
+		} else {
+			check = s.checkUnchanged(pollKey, prev)
+		}
+		if check != nil {
+			s.restore(votesKey, newVotes, prevVotes)
+			return check
+		}
+		return nil
+	}
+
+	if !pollChanged {
+		return nil
+	}
+	if err := s.compareAndSet(pollKey, prev, new); err != nil {
+		return err
+	}
+	if err := s.checkUnchanged(votesKey, prevVotes); err != nil {
+		s.restore(pollKey, new, prev)
+		return err
+	}
+	return nil
+}
+
+// compareAndSet replaces the value of a key with value, if it still equals prev. Otherwise store.ErrConflict is returned.
+func (s *PollStore) compareAndSet(key string, prev, value []byte) error {
 	opt := model.PluginKVSetOptions{
 		Atomic:   true,
-		OldValue: prev.EncodeToByte(),
+		OldValue: prev,
 	}
-	ok, err := s.api.KVSetWithOptions(pollPrefix+prev.ID, new.EncodeToByte(), opt)
+	ok, err := s.api.KVSetWithOptions(key, value, opt)
 	if err != nil {
 		return err
 	}
-
 	if !ok {
-		return errors.New("poll already exists in database")
+		return store.ErrConflict
 	}
+	return nil
+}
 
+// checkUnchanged returns store.ErrConflict if the value of a key doesn't equal expected anymore.
+func (s *PollStore) checkUnchanged(key string, expected []byte) error {
+	b, err := s.api.KVGet(key)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(b, expected) {
+		return store.ErrConflict
+	}
 	return nil
 }
 
+// restore sets a key that was set to value by a failed update back to prev. Failures are only logged.
+func (s *PollStore) restore(key string, value, prev []byte) {
+	if err := s.compareAndSet(key, value, prev); err != nil {
+		s.api.LogWarn("Failed to restore poll after a conflict", "key", key, "error", err.Error())
+	}
+}
+
 // Delete deletes a poll from the KV Store.
 func (s *PollStore) Delete(poll *poll.Poll) error {
 	if err := s.api.KVDelete(pollPrefix + poll.ID); err != nil {
 		return err
 	}
+	if err := s.api.KVDelete(votesPrefix + poll.ID); err != nil {
+		return err
+	}
 
+	s.unindexPoll(poll)
 	return nil
 }
+
+// ListIDs returns the IDs of all polls in the KV Store.
+func (s *PollStore) ListIDs() ([]string, error) {
+	ids := []string{}
+	for page := 0; ; page++ {
+		keys, err := s.api.KVList(page, listPerPage)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, pollPrefix) {
+				ids = append(ids, strings.TrimPrefix(key, pollPrefix))
+			}
+		}
+
+		if len(keys) < listPerPage {
+			return ids, nil
+		}
+	}
+}
+
+// ListIDsByUser returns the IDs of all polls in the KV Store that contain the ID of a user.
+// The KV Store has no index, so every poll is read. voterKey is needed to find the hashed votes of anonymous polls.
+func (s *PollStore) ListIDsByUser(userID, voterKey string) ([]string, error) {
+	ids, err := s.ListIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := []string{}
+	for _, id := range ids {
+		poll, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		poll.SetVoterKey(voterKey)
+		if poll.ContainsUser(userID) {
+			userIDs = append(userIDs, id)
+		}
+	}
+	return userIDs, nil
+}
+
+// ListIDsByChannel returns the IDs of the polls posted in a channel, including polls broadcast to it.
+// The IDs are read from an index, which may contain IDs of polls that have been deleted.
+func (s *PollStore) ListIDsByChannel(channelID string) ([]string, error) {
+	ids, _, err := s.getIndex(channelIndexPrefix + channelID)
+	return ids, err
+}
+
+// ListIDsByKeywords returns the IDs of the polls whose question or answer options contain all keywords,
+// see poll.Keywords. The IDs are read from an index, which may contain IDs of polls that have been deleted
+// or edited since. No IDs are returned if keywords is empty.
+func (s *PollStore) ListIDsByKeywords(keywords []string) ([]string, error) {
+	ids := []string{}
+	for i, keyword := range keywords {
+		indexed, _, err := s.getIndex(keywordIndexPrefix + keyword)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			ids = indexed
+			continue
+		}
+		ids = intersectIDs(ids, indexed)
+	}
+	return ids, nil
+}
+
+// migrateAll migrates all polls stored in an older format, see Get, and adds polls that are missing
+// in the indexes of their channels and keywords, e.g. because they were stored before the indexes existed.
+// Polls that fail to migrate are logged and skipped. The returned bool is false if a poll or an index
+// may be migrated by another attempt. Corrupt polls and polls of a newer schema version can't be migrated
+// by any attempt, hence they don't count.
+func (s *PollStore) migrateAll() (bool, error) {
+	ids, err := s.ListIDs()
+	if err != nil {
+		return false, err
+	}
+
+	complete := true
+	byKey := map[string][]string{}
+	var keys []string
+	for _, id := range ids {
+		p, err := s.Get(id)
+		if err != nil {
+			s.api.LogWarn("Failed to migrate poll", "pollID", id, "error", err.Error())
+			if !errors.Is(err, poll.ErrCorruptPoll) && err != poll.ErrNewerSchemaVersion {
+				complete = false
+			}
+			continue
+		}
+		for _, key := range indexKeys(p) {
+			if _, ok := byKey[key]; !ok {
+				keys = append(keys, key)
+			}
+			byKey[key] = append(byKey[key], id)
+		}
+	}
+
+	for _, key := range keys {
+		if err := s.updateIndex(key, func(indexed []string) []string {
+			return addIDs(indexed, byKey[key]...)
+		}); err != nil {
+			s.api.LogWarn("Failed to index polls", "key", key, "error", err.Error())
+			complete = false
+		}
+	}
+	return complete, nil
+}
+
+// indexPoll adds a poll to the indexes of the channels it's posted in and of its keywords.
+// Failures are only logged, the index then misses the poll.
+func (s *PollStore) indexPoll(poll *poll.Poll) {
+	s.addToIndexes(poll.ID, indexKeys(poll))
+}
+
+// reindexPoll moves a poll to the indexes of the channels and keywords, that were added or removed by an update.
+// Failures are only logged, since the indexes may contain IDs of polls that don't match anymore.
+func (s *PollStore) reindexPoll(prev, new *poll.Poll) {
+	prevKeys, newKeys := indexKeys(prev), indexKeys(new)
+	s.addToIndexes(new.ID, subtractIDs(newKeys, prevKeys))
+	s.removeFromIndexes(new.ID, subtractIDs(prevKeys, newKeys))
+}
+
+// unindexPoll removes a poll from the indexes of the channels it's posted in and of its keywords.
+// Failures are only logged, since the indexes may contain IDs of deleted polls.
+func (s *PollStore) unindexPoll(poll *poll.Poll) {
+	s.removeFromIndexes(poll.ID, indexKeys(poll))
+}
+
+func (s *PollStore) addToIndexes(pollID string, keys []string) {
+	for _, key := range keys {
+		if err := s.updateIndex(key, func(ids []string) []string { return addIDs(ids, pollID) }); err != nil {
+			s.api.LogWarn("Failed to index poll", "pollID", pollID, "key", key, "error", err.Error())
+		}
+	}
+}
+
+func (s *PollStore) removeFromIndexes(pollID string, keys []string) {
+	for _, key := range keys {
+		if err := s.updateIndex(key, func(ids []string) []string { return removeID(ids, pollID) }); err != nil {
+			s.api.LogWarn("Failed to remove poll from index", "pollID", pollID, "key", key, "error", err.Error())
+		}
+	}
+}
+
+// getIndex returns the IDs of the polls of an index together with the stored index they were decoded from.
+func (s *PollStore) getIndex(key string) ([]string, []byte, error) {
+	b, err := s.api.KVGet(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := []string{}
+	if len(b) == 0 {
+		return ids, b, nil
+	}
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return nil, nil, errors.New("failed to decode index")
+	}
+	return ids, b, nil
+}
+
+// updateIndex replaces the IDs of the polls of an index with the result of update using an atomic compare-and-set.
+// The update is retried if the index was modified concurrently. Nothing is stored if update doesn't change the IDs.
+func (s *PollStore) updateIndex(key string, update func([]string) []string) error {
+	for i := 0; i < indexAttempts; i++ {
+		ids, prev, err := s.getIndex(key)
+		if err != nil {
+			return err
+		}
+
+		updated := update(ids)
+		if equalIDs(ids, updated) {
+			return nil
+		}
+		b, _ := json.Marshal(updated)
+
+		opt := model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: prev,
+		}
+		ok, appErr := s.api.KVSetWithOptions(key, b, opt)
+		if appErr != nil {
+			return appErr
+		}
+		if ok {
+			return nil
+		}
+	}
+	return store.ErrConflict
+}
+
+// indexKeys returns the keys of the indexes a poll is listed in: The index of every channel it's posted in
+// and the index of every keyword of its question and answer options.
+func indexKeys(poll *poll.Poll) []string {
+	var keys []string
+	for _, post := range poll.Posts() {
+		if post.ChannelID != "" {
+			keys = append(keys, channelIndexPrefix+post.ChannelID)
+		}
+	}
+	for _, keyword := range poll.Keywords() {
+		keys = append(keys, keywordIndexPrefix+keyword)
+	}
+	return keys
+}
+
+// addIDs returns ids with the IDs of newIDs appended, that it doesn't contain yet.
+func addIDs(ids []string, newIDs ...string) []string {
+	result := append([]string{}, ids...)
+	for _, newID := range newIDs {
+		found := false
+		for _, id := range result {
+			if id == newID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, newID)
+		}
+	}
+	return result
+}
+
+// subtractIDs returns the IDs of ids, that other doesn't contain.
+func subtractIDs(ids, other []string) []string {
+	result := []string{}
+	for _, id := range ids {
+		found := false
+		for _, o := range other {
+			if o == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// intersectIDs returns the IDs of ids, that other contains as well.
+func intersectIDs(ids, other []string) []string {
+	return subtractIDs(ids, subtractIDs(ids, other))
+}
+
+// removeID returns ids without id.
+func removeID(ids []string, id string) []string {
+	result := []string{}
+	for _, other := range ids {
+		if other != id {
+			result = append(result, other)
+		}
+	}
+	return result
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
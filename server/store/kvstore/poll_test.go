@@ -1,13 +1,17 @@
 package kvstore
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 )
 
@@ -15,6 +19,7 @@ func TestPollStoreGet(t *testing.T) {
 	t.Run("all fine", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(testutils.GetPoll().EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, nil)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
@@ -22,6 +27,19 @@ func TestPollStoreGet(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, testutils.GetPoll(), rpoll)
 	})
+	t.Run("poll with votes", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(p.EncodeWithoutVotesToByte(), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(p.EncodeVotesToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Get(testutils.GetPollID())
+		require.NoError(t, err)
+		assert.Equal(t, p, rpoll)
+	})
 	t.Run("KVGet() fails", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return([]byte{}, &model.AppError{})
@@ -32,9 +50,73 @@ func TestPollStoreGet(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, rpoll)
 	})
-	t.Run("Decode fails", func(t *testing.T) {
+	t.Run("KVGet() fails for the votes", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(testutils.GetPoll().EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Get(testutils.GetPollID())
+		assert.Error(t, err)
+		assert.Nil(t, rpoll)
+	})
+	t.Run("poll of an older schema version is migrated", func(t *testing.T) {
+		old := testutils.GetPoll()
+		old.SchemaVersion = 0
+		old.Settings.MaxVotes = 0
+		opt := model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: old.EncodeToByte(),
+		}
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(old.EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, nil)
+		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), testutils.GetPoll().EncodeToByte(), opt).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Get(testutils.GetPollID())
+		require.NoError(t, err)
+		assert.Equal(t, testutils.GetPoll(), rpoll)
+	})
+	t.Run("votes stored together with the poll are moved to their own key", func(t *testing.T) {
+		legacy := []byte(`{"ID": "` + testutils.GetPollID() + `", "SchemaVersion": 1, "Settings": {"max_votes": 1}, "version": 3,
+			"AnswerOptions": [{"Answer": "Answer 1", "Voter": ["userID1", "userID2"]}, {"Answer": "Answer 2", "Voter": ["userID1"]}]}`)
+		expected := &poll.Poll{
+			ID:            testutils.GetPollID(),
+			SchemaVersion: poll.CurrentSchemaVersion,
+			Settings:      poll.Settings{MaxVotes: 1},
+			AnswerOptions: []*poll.AnswerOption{{Answer: "Answer 1"}, {Answer: "Answer 2"}},
+			Version:       3,
+			Ballots:       map[string][]int{"userID1": {0, 1}, "userID2": {0}},
+			BallotOrder:   []string{"userID1", "userID2"},
+			VoteCounts:    []int{2, 1},
+		}
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(legacy, nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, nil)
+		api.On("KVSetWithOptions", votesPrefix+testutils.GetPollID(), expected.EncodeVotesToByte(), model.PluginKVSetOptions{Atomic: true}).Return(true, nil)
+		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), expected.EncodeWithoutVotesToByte(), model.PluginKVSetOptions{Atomic: true, OldValue: legacy}).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Get(testutils.GetPollID())
+		require.NoError(t, err)
+		assert.Equal(t, expected, rpoll)
+		assert.True(t, rpoll.HasVoted("userID2"))
+		assert.Equal(t, []string{"Answer 1", "Answer 2"}, rpoll.GetVotedAnswers("userID1"))
+	})
+	t.Run("storing the migrated poll fails", func(t *testing.T) {
+		old := testutils.GetPoll()
+		old.SchemaVersion = 0
+
 		api := &plugintest.API{}
-		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return([]byte{}, nil)
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(old.EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, nil)
+		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), testutils.GetPoll().EncodeToByte(), mock.AnythingOfType("model.PluginKVSetOptions")).Return(false, &model.AppError{})
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
@@ -42,27 +124,88 @@ func TestPollStoreGet(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, rpoll)
 	})
+	t.Run("poll doesn't exist", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		s := setupTestStore(api)
+
+		rpoll, err := s.Poll().Get(testutils.GetPollID())
+		assert.Equal(t, store.ErrNotFound, err)
+		assert.Nil(t, rpoll)
+	})
+	t.Run("Decode fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return([]byte("invalid"), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Get(testutils.GetPollID())
+		assert.True(t, errors.Is(err, poll.ErrCorruptPoll))
+		assert.Nil(t, rpoll)
+	})
+	t.Run("Decode of the votes fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(testutils.GetPoll().EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return([]byte("invalid"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Get(testutils.GetPollID())
+		assert.True(t, errors.Is(err, poll.ErrCorruptPoll))
+		assert.Nil(t, rpoll)
+	})
 }
 
 func TestPollStoreInsert(t *testing.T) {
+	opt := model.PluginKVSetOptions{
+		Atomic:   true,
+		OldValue: nil,
+	}
+
 	t.Run("all fine", func(t *testing.T) {
-		opt := model.PluginKVSetOptions{
-			Atomic:   true,
-			OldValue: nil,
-		}
 		api := &plugintest.API{}
 		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), testutils.GetPoll().EncodeToByte(), opt).Return(true, nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, nil)
+		mockEmptyKeywordIndexes(api, testutils.GetPoll(), true)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
 		err := store.Poll().Insert(testutils.GetPoll())
 		require.NoError(t, err)
 	})
-	t.Run("KVSetWithOptions() fails", func(t *testing.T) {
-		opt := model.PluginKVSetOptions{
+	t.Run("poll with votes", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", votesPrefix+testutils.GetPollID(), p.EncodeVotesToByte(), opt).Return(true, nil)
+		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), p.EncodeWithoutVotesToByte(), opt).Return(true, nil)
+		mockEmptyKeywordIndexes(api, p, true)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Insert(p)
+		require.NoError(t, err)
+	})
+	t.Run("votes are removed if the poll already exists", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		restoreOpt := model.PluginKVSetOptions{
 			Atomic:   true,
-			OldValue: nil,
+			OldValue: p.EncodeVotesToByte(),
 		}
+
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", votesPrefix+testutils.GetPollID(), p.EncodeVotesToByte(), opt).Return(true, nil)
+		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), p.EncodeWithoutVotesToByte(), opt).Return(false, nil)
+		api.On("KVSetWithOptions", votesPrefix+testutils.GetPollID(), []byte(nil), restoreOpt).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Insert(p)
+		require.Error(t, err)
+	})
+	t.Run("KVSetWithOptions() fails", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), testutils.GetPoll().EncodeToByte(), opt).Return(false, &model.AppError{})
 		defer api.AssertExpectations(t)
@@ -72,10 +215,6 @@ func TestPollStoreInsert(t *testing.T) {
 		require.Error(t, err)
 	})
 	t.Run("Poll already exists", func(t *testing.T) {
-		opt := model.PluginKVSetOptions{
-			Atomic:   true,
-			OldValue: nil,
-		}
 		api := &plugintest.API{}
 		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), testutils.GetPoll().EncodeToByte(), opt).Return(false, nil)
 		defer api.AssertExpectations(t)
@@ -87,82 +226,462 @@ func TestPollStoreInsert(t *testing.T) {
 }
 
 func TestPollStoreUpdate(t *testing.T) {
+	oldPoll := testutils.GetPollWithVotes()
+	newPoll := oldPoll.Copy()
+	require.NoError(t, newPoll.UpdateVote("userID5", 0))
+	votesOpt := model.PluginKVSetOptions{
+		Atomic:   true,
+		OldValue: oldPoll.EncodeVotesToByte(),
+	}
+	pollOpt := model.PluginKVSetOptions{
+		Atomic:   true,
+		OldValue: oldPoll.EncodeWithoutVotesToByte(),
+	}
+
 	t.Run("all fine", func(t *testing.T) {
-		oldPoll := testutils.GetPoll()
-		newPoll := oldPoll.Copy()
-		msg, err := newPoll.UpdateVote(model.NewId(), 0)
-		require.Nil(t, msg)
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", votesPrefix+newPoll.ID, newPoll.EncodeVotesToByte(), votesOpt).Return(true, nil)
+		api.On("KVGet", pollPrefix+newPoll.ID).Return(oldPoll.EncodeWithoutVotesToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Update(oldPoll, newPoll)
 		require.NoError(t, err)
-		opt := model.PluginKVSetOptions{
+	})
+	t.Run("a vote doesn't rewrite the poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", votesPrefix+newPoll.ID, newPoll.EncodeVotesToByte(), votesOpt).Return(true, nil)
+		api.On("KVGet", pollPrefix+newPoll.ID).Return(oldPoll.EncodeWithoutVotesToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		require.NoError(t, store.Poll().Update(oldPoll, newPoll))
+		api.AssertNotCalled(t, "KVSetWithOptions", pollPrefix+newPoll.ID, mock.Anything, mock.Anything)
+	})
+	t.Run("poll and votes change", func(t *testing.T) {
+		changed := newPoll.Copy()
+		changed.Settings.Progress = true
+
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", votesPrefix+newPoll.ID, changed.EncodeVotesToByte(), votesOpt).Return(true, nil)
+		api.On("KVSetWithOptions", pollPrefix+newPoll.ID, changed.EncodeWithoutVotesToByte(), pollOpt).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Update(oldPoll, changed)
+		require.NoError(t, err)
+	})
+	t.Run("only the poll changes", func(t *testing.T) {
+		changed := oldPoll.Copy()
+		changed.Settings.Progress = true
+
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", pollPrefix+newPoll.ID, changed.EncodeWithoutVotesToByte(), pollOpt).Return(true, nil)
+		api.On("KVGet", votesPrefix+newPoll.ID).Return(oldPoll.EncodeVotesToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Update(oldPoll, changed)
+		require.NoError(t, err)
+	})
+	t.Run("KVSetWithOptions() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", votesPrefix+newPoll.ID, newPoll.EncodeVotesToByte(), votesOpt).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Update(oldPoll, newPoll)
+		require.Error(t, err)
+	})
+	t.Run("db compare fails fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", votesPrefix+newPoll.ID, newPoll.EncodeVotesToByte(), votesOpt).Return(false, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.Poll().Update(oldPoll, newPoll)
+		assert.Equal(t, store.ErrConflict, err)
+	})
+	t.Run("poll changed concurrently", func(t *testing.T) {
+		restoreOpt := model.PluginKVSetOptions{
 			Atomic:   true,
-			OldValue: oldPoll.EncodeToByte(),
+			OldValue: newPoll.EncodeVotesToByte(),
 		}
 
 		api := &plugintest.API{}
-		api.On("KVSetWithOptions", pollPrefix+newPoll.ID, newPoll.EncodeToByte(), opt).Return(true, nil)
+		api.On("KVSetWithOptions", votesPrefix+newPoll.ID, newPoll.EncodeVotesToByte(), votesOpt).Return(true, nil)
+		api.On("KVGet", pollPrefix+newPoll.ID).Return([]byte(`{"ID": "changed"}`), nil)
+		api.On("KVSetWithOptions", votesPrefix+newPoll.ID, oldPoll.EncodeVotesToByte(), restoreOpt).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.Poll().Update(oldPoll, newPoll)
+		assert.Equal(t, store.ErrConflict, err)
+	})
+	t.Run("votes changed concurrently", func(t *testing.T) {
+		changed := oldPoll.Copy()
+		changed.Settings.Progress = true
+		restoreOpt := model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: changed.EncodeWithoutVotesToByte(),
+		}
+
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", pollPrefix+newPoll.ID, changed.EncodeWithoutVotesToByte(), pollOpt).Return(true, nil)
+		api.On("KVGet", votesPrefix+newPoll.ID).Return(newPoll.EncodeVotesToByte(), nil)
+		api.On("KVSetWithOptions", pollPrefix+newPoll.ID, oldPoll.EncodeWithoutVotesToByte(), restoreOpt).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.Poll().Update(oldPoll, changed)
+		assert.Equal(t, store.ErrConflict, err)
+	})
+}
+
+func TestPollStoreDelete(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", pollPrefix+testutils.GetPollID()).Return(nil)
+		api.On("KVDelete", votesPrefix+testutils.GetPollID()).Return(nil)
+		mockEmptyKeywordIndexes(api, testutils.GetPoll(), false)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
-		err = store.Poll().Update(oldPoll, newPoll)
+		err := store.Poll().Delete(testutils.GetPoll())
 		require.NoError(t, err)
 	})
-	t.Run("KVSetWithOptions() fails", func(t *testing.T) {
-		oldPoll := testutils.GetPoll()
-		newPoll := oldPoll.Copy()
-		msg, err := newPoll.UpdateVote(model.NewId(), 0)
-		require.Nil(t, msg)
+	t.Run("KVDelete() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", pollPrefix+testutils.GetPollID()).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Delete(testutils.GetPoll())
+		require.Error(t, err)
+	})
+}
+
+func TestPollStoreListIDs(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + "1", versionKey, pollPrefix + "2"}, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Poll().ListIDs()
 		require.NoError(t, err)
-		opt := model.PluginKVSetOptions{
-			Atomic:   true,
-			OldValue: oldPoll.EncodeToByte(),
+		assert.Equal(t, []string{"1", "2"}, ids)
+	})
+	t.Run("multiple pages", func(t *testing.T) {
+		firstPage := make([]string, listPerPage)
+		for i := range firstPage {
+			firstPage[i] = versionKey
 		}
+		firstPage[0] = pollPrefix + "1"
 
 		api := &plugintest.API{}
-		api.On("KVSetWithOptions", pollPrefix+newPoll.ID, newPoll.EncodeToByte(), opt).Return(false, &model.AppError{})
+		api.On("KVList", 0, listPerPage).Return(firstPage, nil)
+		api.On("KVList", 1, listPerPage).Return([]string{pollPrefix + "2"}, nil)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
-		err = store.Poll().Update(oldPoll, newPoll)
+		ids, err := store.Poll().ListIDs()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "2"}, ids)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Poll().ListIDs()
 		require.Error(t, err)
+		assert.Nil(t, ids)
 	})
-	t.Run("db compare fails fails", func(t *testing.T) {
-		oldPoll := testutils.GetPoll()
-		newPoll := oldPoll.Copy()
-		msg, err := newPoll.UpdateVote(model.NewId(), 0)
-		require.Nil(t, msg)
+}
+
+func TestPollStoreListIDsByUser(t *testing.T) {
+	voted := testutils.GetPoll()
+	voted.ID = "1"
+	voted.Creator = "userID2"
+	voted.SetVoters(0, "userID1")
+	other := testutils.GetPoll()
+	other.ID = "2"
+	other.Creator = "userID2"
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + "1", versionKey, pollPrefix + "2"}, nil)
+		api.On("KVGet", pollPrefix+"1").Return(voted.EncodeWithoutVotesToByte(), nil)
+		api.On("KVGet", votesPrefix+"1").Return(voted.EncodeVotesToByte(), nil)
+		api.On("KVGet", pollPrefix+"2").Return(other.EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+"2").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Poll().ListIDsByUser("userID1", "")
 		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, ids)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Poll().ListIDsByUser("userID1", "")
+		require.Error(t, err)
+		assert.Nil(t, ids)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + "1"}, nil)
+		api.On("KVGet", pollPrefix+"1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Poll().ListIDsByUser("userID1", "")
+		require.Error(t, err)
+		assert.Nil(t, ids)
+	})
+}
+
+func TestPollStoreMigrateAll(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		old := testutils.GetPoll()
+		old.SchemaVersion = 0
 		opt := model.PluginKVSetOptions{
 			Atomic:   true,
-			OldValue: oldPoll.EncodeToByte(),
+			OldValue: old.EncodeToByte(),
 		}
 
 		api := &plugintest.API{}
-		api.On("KVSetWithOptions", pollPrefix+newPoll.ID, newPoll.EncodeToByte(), opt).Return(false, nil)
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + testutils.GetPollID(), versionKey, pollPrefix + "2"}, nil)
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(old.EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, nil)
+		api.On("KVSetWithOptions", pollPrefix+testutils.GetPollID(), testutils.GetPoll().EncodeToByte(), opt).Return(true, nil)
+		api.On("KVGet", pollPrefix+"2").Return(nil, &model.AppError{})
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+		mockEmptyKeywordIndexes(api, testutils.GetPoll(), true)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		complete, err := store.pollStore.migrateAll()
+		require.NoError(t, err)
+		assert.False(t, complete)
+	})
+	t.Run("corrupt polls don't need another attempt", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + testutils.GetPollID(), pollPrefix + "2"}, nil)
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(testutils.GetPoll().EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+testutils.GetPollID()).Return(nil, nil)
+		api.On("KVGet", pollPrefix+"2").Return([]byte("invalid"), nil)
+		api.On("KVGet", votesPrefix+"2").Return(nil, nil)
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+		mockEmptyKeywordIndexes(api, testutils.GetPoll(), true)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		complete, err := store.pollStore.migrateAll()
+		require.NoError(t, err)
+		assert.True(t, complete)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
-		err = store.Poll().Update(oldPoll, newPoll)
+		_, err := store.pollStore.migrateAll()
 		require.Error(t, err)
 	})
 }
 
-func TestPollStoreDelete(t *testing.T) {
+func TestPollStoreListIDsByChannel(t *testing.T) {
 	t.Run("all fine", func(t *testing.T) {
 		api := &plugintest.API{}
-		api.On("KVDelete", pollPrefix+testutils.GetPollID()).Return(nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["1","2"]`), nil)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
-		err := store.Poll().Delete(testutils.GetPoll())
+		ids, err := store.Poll().ListIDsByChannel("channelID1")
 		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "2"}, ids)
 	})
-	t.Run("KVDelete() fails", func(t *testing.T) {
+	t.Run("no polls", func(t *testing.T) {
 		api := &plugintest.API{}
-		api.On("KVDelete", pollPrefix+testutils.GetPollID()).Return(&model.AppError{})
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
-		err := store.Poll().Delete(testutils.GetPoll())
+		ids, err := store.Poll().ListIDsByChannel("channelID1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, ids)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Poll().ListIDsByChannel("channelID1")
+		require.Error(t, err)
+		assert.Nil(t, ids)
+	})
+}
+
+func TestPollStoreListIDsByKeywords(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", keywordIndexPrefix+"lunch").Return([]byte(`["1","2","3"]`), nil)
+		api.On("KVGet", keywordIndexPrefix+"pizza").Return([]byte(`["3","1"]`), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Poll().ListIDsByKeywords([]string{"lunch", "pizza"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "3"}, ids)
+	})
+	t.Run("no keywords", func(t *testing.T) {
+		store := setupTestStore(&plugintest.API{})
+
+		ids, err := store.Poll().ListIDsByKeywords([]string{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, ids)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", keywordIndexPrefix+"lunch").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Poll().ListIDsByKeywords([]string{"lunch"})
 		require.Error(t, err)
+		assert.Nil(t, ids)
+	})
+}
+
+func TestPollStoreKeywordIndex(t *testing.T) {
+	t.Run("Update() moves the poll to the indexes of changed keywords", func(t *testing.T) {
+		prev := testutils.GetPoll()
+		edited := prev.Copy()
+		edited.Question = "Question about lunch"
+		edited.AnswerOptions[0].Deleted = true
+		edited.AnswerOptions[1].Deleted = true
+		edited.AnswerOptions[2].Deleted = true
+		index := []byte(`["` + prev.ID + `"]`)
+
+		api := &plugintest.API{}
+		api.On("KVGet", votesPrefix+prev.ID).Return(nil, nil)
+		api.On("KVSetWithOptions", pollPrefix+prev.ID, edited.EncodeToByte(), model.PluginKVSetOptions{Atomic: true, OldValue: prev.EncodeToByte()}).Return(true, nil)
+		api.On("KVGet", keywordIndexPrefix+"about").Return(nil, nil)
+		api.On("KVSetWithOptions", keywordIndexPrefix+"about", index, model.PluginKVSetOptions{Atomic: true}).Return(true, nil)
+		api.On("KVGet", keywordIndexPrefix+"lunch").Return(nil, nil)
+		api.On("KVSetWithOptions", keywordIndexPrefix+"lunch", index, model.PluginKVSetOptions{Atomic: true}).Return(true, nil)
+		api.On("KVGet", keywordIndexPrefix+"answer").Return(index, nil)
+		api.On("KVSetWithOptions", keywordIndexPrefix+"answer", []byte(`[]`), model.PluginKVSetOptions{Atomic: true, OldValue: index}).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		require.NoError(t, store.Poll().Update(prev, edited))
 	})
+	t.Run("Update() doesn't touch the indexes if the keywords are unchanged", func(t *testing.T) {
+		prev := testutils.GetPoll()
+		voted := prev.Copy()
+		require.NoError(t, voted.UpdateVote("userID2", 0))
+
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", votesPrefix+prev.ID, voted.EncodeVotesToByte(), model.PluginKVSetOptions{Atomic: true}).Return(true, nil)
+		api.On("KVGet", pollPrefix+prev.ID).Return(prev.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		require.NoError(t, store.Poll().Update(prev, voted))
+	})
+}
+
+func TestPollStoreChannelIndex(t *testing.T) {
+	indexed := testutils.GetPoll()
+	indexed.ChannelID = "channelID1"
+	indexed.Broadcasts = []*poll.BroadcastPost{{ChannelID: "channelID2", PostID: "postID2"}}
+
+	t.Run("Insert() adds the poll to the index of every channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", pollPrefix+indexed.ID, indexed.EncodeToByte(), model.PluginKVSetOptions{Atomic: true}).Return(true, nil)
+		api.On("KVGet", votesPrefix+indexed.ID).Return(nil, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
+		api.On("KVSetWithOptions", channelIndexPrefix+"channelID1", []byte(`["`+indexed.ID+`"]`), model.PluginKVSetOptions{Atomic: true}).Return(true, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID2").Return([]byte(`["pollID2"]`), nil)
+		api.On("KVSetWithOptions", channelIndexPrefix+"channelID2", []byte(`["pollID2","`+indexed.ID+`"]`), model.PluginKVSetOptions{Atomic: true, OldValue: []byte(`["pollID2"]`)}).Return(true, nil)
+		mockEmptyKeywordIndexes(api, indexed, true)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		require.NoError(t, store.Poll().Insert(indexed))
+	})
+	t.Run("Delete() removes the poll from the index of every channel", func(t *testing.T) {
+		index := []byte(`["` + indexed.ID + `","pollID2"]`)
+
+		api := &plugintest.API{}
+		api.On("KVDelete", pollPrefix+indexed.ID).Return(nil)
+		api.On("KVDelete", votesPrefix+indexed.ID).Return(nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(index, nil)
+		api.On("KVSetWithOptions", channelIndexPrefix+"channelID1", []byte(`["pollID2"]`), model.PluginKVSetOptions{Atomic: true, OldValue: index}).Return(true, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID2").Return([]byte(`["pollID2"]`), nil)
+		mockEmptyKeywordIndexes(api, indexed, false)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		require.NoError(t, store.Poll().Delete(indexed))
+	})
+	t.Run("concurrent updates of the index are retried", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil).Once()
+		api.On("KVSetWithOptions", channelIndexPrefix+"channelID1", []byte(`["pollID1"]`), model.PluginKVSetOptions{Atomic: true}).Return(false, nil).Once()
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["pollID2"]`), nil).Once()
+		api.On("KVSetWithOptions", channelIndexPrefix+"channelID1", []byte(`["pollID2","pollID1"]`), mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil).Once()
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.pollStore.updateIndex(channelIndexPrefix+"channelID1", func(ids []string) []string { return addIDs(ids, "pollID1") })
+		require.NoError(t, err)
+	})
+	t.Run("updates of the index give up after some attempts", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil).Times(indexAttempts)
+		api.On("KVSetWithOptions", channelIndexPrefix+"channelID1", mock.Anything, mock.Anything).Return(false, nil).Times(indexAttempts)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.pollStore.updateIndex(channelIndexPrefix+"channelID1", func(ids []string) []string { return addIDs(ids, "pollID1") })
+		assert.Equal(t, store.ErrConflict, err)
+	})
+	t.Run("migrateAll() adds missing polls to the index", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + indexed.ID}, nil)
+		api.On("KVGet", pollPrefix+indexed.ID).Return(indexed.EncodeToByte(), nil)
+		api.On("KVGet", votesPrefix+indexed.ID).Return(nil, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["`+indexed.ID+`"]`), nil)
+		api.On("KVGet", channelIndexPrefix+"channelID2").Return(nil, nil)
+		api.On("KVSetWithOptions", channelIndexPrefix+"channelID2", []byte(`["`+indexed.ID+`"]`), model.PluginKVSetOptions{Atomic: true}).Return(true, nil)
+		mockEmptyKeywordIndexes(api, indexed, true)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		complete, err := store.pollStore.migrateAll()
+		require.NoError(t, err)
+		assert.True(t, complete)
+	})
+}
+
+// mockEmptyKeywordIndexes mocks the indexes of the keywords of a poll as empty.
+// If add is true, the poll is expected to be added to them.
+func mockEmptyKeywordIndexes(api *plugintest.API, p *poll.Poll, add bool) {
+	for _, keyword := range p.Keywords() {
+		api.On("KVGet", keywordIndexPrefix+keyword).Return(nil, nil)
+		if add {
+			api.On("KVSetWithOptions", keywordIndexPrefix+keyword, []byte(`["`+p.ID+`"]`), model.PluginKVSetOptions{Atomic: true}).Return(true, nil)
+		}
+	}
 }
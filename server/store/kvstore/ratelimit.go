@@ -0,0 +1,65 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin"
+
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+// RateLimitStore allows to count the actions of users in the KV Store.
+// The times of the actions counted under a key are stored together and expire with the time window.
+type RateLimitStore struct {
+	api plugin.API
+}
+
+const rateLimitPrefix = "ratelimit_"
+
+// Take counts an action under key using an atomic compare-and-set, if fewer than limit actions were counted within window.
+// It returns false, without counting the action, if the limit has been reached.
+// store.ErrConflict is returned if actions were counted concurrently.
+func (s *RateLimitStore) Take(key string, limit int, window time.Duration) (bool, error) {
+	b, appErr := s.api.KVGet(rateLimitPrefix + key)
+	if appErr != nil {
+		return false, appErr
+	}
+	var times []int64
+	if b != nil {
+		if err := json.Unmarshal(b, &times); err != nil {
+			return false, errors.New("failed to decode rate limit")
+		}
+	}
+
+	now := model.GetMillis()
+	since := now - int64(window/time.Millisecond)
+	recent := []int64{}
+	for _, t := range times {
+		if t > since {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= limit {
+		return false, nil
+	}
+
+	value, _ := json.Marshal(append(recent, now))
+	opt := model.PluginKVSetOptions{
+		Atomic:          true,
+		OldValue:        b,
+		ExpireInSeconds: int64(window / time.Second),
+	}
+	ok, appErr := s.api.KVSetWithOptions(rateLimitPrefix+key, value, opt)
+	if appErr != nil {
+		return false, appErr
+	}
+
+	if !ok {
+		return false, store.ErrConflict
+	}
+
+	return true, nil
+}
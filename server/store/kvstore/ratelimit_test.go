@@ -0,0 +1,98 @@
+package kvstore
+
+import (
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+func TestRateLimitStoreTake(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 7200000 })
+	defer patch.Unpatch()
+	opt := func(old []byte) model.PluginKVSetOptions {
+		return model.PluginKVSetOptions{Atomic: true, OldValue: old, ExpireInSeconds: 3600}
+	}
+
+	t.Run("first action", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key").Return(nil, nil)
+		api.On("KVSetWithOptions", rateLimitPrefix+"key", []byte("[7200000]"), opt(nil)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		ok, err := kvStore.RateLimit().Take("key", 2, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("expired actions are dropped", func(t *testing.T) {
+		old := []byte("[3600000,3600001]")
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key").Return(old, nil)
+		api.On("KVSetWithOptions", rateLimitPrefix+"key", []byte("[3600001,7200000]"), opt(old)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		ok, err := kvStore.RateLimit().Take("key", 2, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("limit reached", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key").Return([]byte("[3600001,7199999]"), nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		ok, err := kvStore.RateLimit().Take("key", 2, time.Hour)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("concurrent modification", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key").Return(nil, nil)
+		api.On("KVSetWithOptions", rateLimitPrefix+"key", []byte("[7200000]"), opt(nil)).Return(false, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		ok, err := kvStore.RateLimit().Take("key", 2, time.Hour)
+		assert.Equal(t, store.ErrConflict, err)
+		assert.False(t, ok)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		ok, err := kvStore.RateLimit().Take("key", 2, time.Hour)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key").Return([]byte("invalid"), nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		ok, err := kvStore.RateLimit().Take("key", 2, time.Hour)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("KVSetWithOptions() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key").Return(nil, nil)
+		api.On("KVSetWithOptions", rateLimitPrefix+"key", []byte("[7200000]"), opt(nil)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		ok, err := kvStore.RateLimit().Take("key", 2, time.Hour)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
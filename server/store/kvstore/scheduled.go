@@ -0,0 +1,81 @@
+package kvstore
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// ScheduledPollStore allows to access scheduled polls in the KV Store.
+type ScheduledPollStore struct {
+	api plugin.API
+}
+
+const scheduledPollPrefix = "scheduled_poll_"
+
+// Get returns the scheduled poll for a given id. Returns an error if the poll doesn't exist or a KV Store error occurred.
+func (s *ScheduledPollStore) Get(id string) (*poll.ScheduledPoll, error) {
+	b, err := s.api.KVGet(scheduledPollPrefix + id)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledPoll := poll.DecodeScheduledPollFromByte(b)
+	if scheduledPoll == nil {
+		return nil, errors.New("failed to decode scheduled poll")
+	}
+
+	return scheduledPoll, nil
+}
+
+// Insert stores a new scheduled poll in the KV Store.
+func (s *ScheduledPollStore) Insert(scheduledPoll *poll.ScheduledPoll) error {
+	opt := model.PluginKVSetOptions{
+		Atomic:   true,
+		OldValue: nil,
+	}
+	ok, err := s.api.KVSetWithOptions(scheduledPollPrefix+scheduledPoll.Poll.ID, scheduledPoll.EncodeToByte(), opt)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("scheduled poll already exists in database")
+	}
+
+	return nil
+}
+
+// Delete deletes a scheduled poll from the KV Store.
+func (s *ScheduledPollStore) Delete(id string) error {
+	if err := s.api.KVDelete(scheduledPollPrefix + id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListIDs returns the IDs of all scheduled polls in the KV Store.
+func (s *ScheduledPollStore) ListIDs() ([]string, error) {
+	ids := []string{}
+	for page := 0; ; page++ {
+		keys, err := s.api.KVList(page, listPerPage)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, scheduledPollPrefix) {
+				ids = append(ids, strings.TrimPrefix(key, scheduledPollPrefix))
+			}
+		}
+
+		if len(keys) < listPerPage {
+			return ids, nil
+		}
+	}
+}
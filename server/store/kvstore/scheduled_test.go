@@ -0,0 +1,96 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestScheduledPollStoreGet(t *testing.T) {
+	scheduledPoll := poll.NewScheduledPoll(testutils.GetPoll(), "channelID1", "")
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", scheduledPollPrefix+testutils.GetPollID()).Return(scheduledPoll.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rScheduledPoll, err := store.ScheduledPoll().Get(testutils.GetPollID())
+		require.NoError(t, err)
+		assert.Equal(t, scheduledPoll, rScheduledPoll)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", scheduledPollPrefix+testutils.GetPollID()).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rScheduledPoll, err := store.ScheduledPoll().Get(testutils.GetPollID())
+		assert.Error(t, err)
+		assert.Nil(t, rScheduledPoll)
+	})
+	t.Run("invalid data", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", scheduledPollPrefix+testutils.GetPollID()).Return([]byte("{}"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rScheduledPoll, err := store.ScheduledPoll().Get(testutils.GetPollID())
+		assert.Error(t, err)
+		assert.Nil(t, rScheduledPoll)
+	})
+}
+
+func TestScheduledPollStoreInsert(t *testing.T) {
+	scheduledPoll := poll.NewScheduledPoll(testutils.GetPoll(), "channelID1", "")
+	opt := model.PluginKVSetOptions{
+		Atomic:   true,
+		OldValue: nil,
+	}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", scheduledPollPrefix+testutils.GetPollID(), scheduledPoll.EncodeToByte(), opt).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.ScheduledPoll().Insert(scheduledPoll)
+		require.NoError(t, err)
+	})
+	t.Run("poll already exists", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", scheduledPollPrefix+testutils.GetPollID(), scheduledPoll.EncodeToByte(), opt).Return(false, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.ScheduledPoll().Insert(scheduledPoll)
+		require.Error(t, err)
+	})
+}
+
+func TestScheduledPollStoreDelete(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("KVDelete", scheduledPollPrefix+testutils.GetPollID()).Return(nil)
+	defer api.AssertExpectations(t)
+	store := setupTestStore(api)
+
+	err := store.ScheduledPoll().Delete(testutils.GetPollID())
+	require.NoError(t, err)
+}
+
+func TestScheduledPollStoreListIDs(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("KVList", 0, listPerPage).Return([]string{scheduledPollPrefix + "1", pollPrefix + "2", versionKey}, nil)
+	defer api.AssertExpectations(t)
+	store := setupTestStore(api)
+
+	ids, err := store.ScheduledPoll().ListIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, ids)
+}
@@ -3,35 +3,86 @@ package kvstore
 import (
 	"github.com/mattermost/mattermost-server/v5/plugin"
 
+	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store"
 )
 
 // Store is an interface to interact with the KV Store.
 type Store struct {
-	api         plugin.API
-	pollStore   PollStore
-	systemStore SystemStore
-	upgrades    []*upgrade
+	api                plugin.API
+	pollStore          PollStore
+	scheduledPollStore ScheduledPollStore
+	templateStore      TemplateStore
+	auditStore         AuditStore
+	archiveStore       ArchiveStore
+	rateLimitStore     RateLimitStore
+	systemStore        SystemStore
+	upgrades           []*upgrade
 }
 
-// NewStore returns a fresh store and upgrades the db from the given schema version.
+// NewStore returns a fresh store, upgrades the db from the given schema version and migrates polls stored in an older format.
 func NewStore(api plugin.API, pluginVersion string) (store.Store, error) {
 	store := Store{
-		api:         api,
-		pollStore:   PollStore{api: api},
-		systemStore: SystemStore{api: api},
-		upgrades:    getUpgrades(),
+		api:                api,
+		pollStore:          PollStore{api: api},
+		scheduledPollStore: ScheduledPollStore{api: api},
+		templateStore:      TemplateStore{api: api},
+		auditStore:         AuditStore{api: api},
+		archiveStore:       ArchiveStore{api: api},
+		rateLimitStore:     RateLimitStore{api: api},
+		systemStore:        SystemStore{api: api},
+		upgrades:           getUpgrades(),
 	}
 	err := store.UpdateDatabase(pluginVersion)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := store.migratePolls(); err != nil {
+		return nil, err
+	}
+
 	return &store, nil
 }
 
+// migratePolls migrates all polls, see PollStore.migrateAll, unless they have already been migrated
+// to poll.CurrentSchemaVersion. The version is only recorded once no poll is left to migrate.
+func (s *Store) migratePolls() error {
+	version, err := s.systemStore.getPollSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version >= poll.CurrentSchemaVersion {
+		return nil
+	}
+
+	complete, err := s.pollStore.migrateAll()
+	if err != nil {
+		return err
+	}
+	if !complete {
+		return nil
+	}
+	return s.systemStore.savePollSchemaVersion(poll.CurrentSchemaVersion)
+}
+
 // Poll returns the Poll Store
 func (s *Store) Poll() store.PollStore { return &s.pollStore }
 
+// ScheduledPoll returns the Scheduled Poll Store
+func (s *Store) ScheduledPoll() store.ScheduledPollStore { return &s.scheduledPollStore }
+
+// Template returns the Template Store
+func (s *Store) Template() store.TemplateStore { return &s.templateStore }
+
+// Audit returns the Audit Store
+func (s *Store) Audit() store.AuditStore { return &s.auditStore }
+
+// Archive returns the Archive Store
+func (s *Store) Archive() store.ArchiveStore { return &s.archiveStore }
+
+// RateLimit returns the Rate Limit Store
+func (s *Store) RateLimit() store.RateLimitStore { return &s.rateLimitStore }
+
 // System returns the System Store
 func (s *Store) System() store.SystemStore { return &s.systemStore }
@@ -1,12 +1,16 @@
 package kvstore
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
 )
 
 const latestVersion = "1.4.0"
@@ -17,6 +21,21 @@ func setupTestStore(api plugin.API) *Store {
 		pollStore: PollStore{
 			api: api,
 		},
+		scheduledPollStore: ScheduledPollStore{
+			api: api,
+		},
+		templateStore: TemplateStore{
+			api: api,
+		},
+		auditStore: AuditStore{
+			api: api,
+		},
+		archiveStore: ArchiveStore{
+			api: api,
+		},
+		rateLimitStore: RateLimitStore{
+			api: api,
+		},
 		systemStore: SystemStore{
 			api: api,
 		},
@@ -26,15 +45,78 @@ func setupTestStore(api plugin.API) *Store {
 }
 
 func TestNewStore(t *testing.T) {
+	migrated := []byte(strconv.Itoa(poll.CurrentSchemaVersion))
+
 	t.Run("all fine", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("KVGet", versionKey).Return([]byte(latestVersion), nil)
+		api.On("KVGet", pollSchemaVersionKey).Return(nil, nil)
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey}, nil)
+		api.On("KVSet", pollSchemaVersionKey, migrated).Return(nil)
+		defer api.AssertExpectations(t)
+
+		store, err := NewStore(api, latestVersion)
+		assert.Nil(t, err)
+		assert.NotNil(t, store)
+	})
+	t.Run("polls have already been migrated", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte(latestVersion), nil)
+		api.On("KVGet", pollSchemaVersionKey).Return(migrated, nil)
+		defer api.AssertExpectations(t)
+
+		store, err := NewStore(api, latestVersion)
+		assert.Nil(t, err)
+		assert.NotNil(t, store)
+		api.AssertNotCalled(t, "KVList", 0, listPerPage)
+	})
+	t.Run("polls of an older schema version are migrated", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte(latestVersion), nil)
+		api.On("KVGet", pollSchemaVersionKey).Return([]byte("1"), nil)
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey}, nil)
+		api.On("KVSet", pollSchemaVersionKey, migrated).Return(nil)
 		defer api.AssertExpectations(t)
 
 		store, err := NewStore(api, latestVersion)
 		assert.Nil(t, err)
 		assert.NotNil(t, store)
 	})
+	t.Run("version isn't recorded if a poll is left to migrate", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte(latestVersion), nil)
+		api.On("KVGet", pollSchemaVersionKey).Return(nil, nil)
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + "1"}, nil)
+		api.On("KVGet", pollPrefix+"1").Return(nil, &model.AppError{})
+		api.On("LogWarn", testutils.GetMockArgumentsWithType("string", 5)...).Return()
+		defer api.AssertExpectations(t)
+
+		store, err := NewStore(api, latestVersion)
+		assert.Nil(t, err)
+		assert.NotNil(t, store)
+		api.AssertNotCalled(t, "KVSet", pollSchemaVersionKey, migrated)
+	})
+	t.Run("reading the migrated schema version fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte(latestVersion), nil)
+		api.On("KVGet", pollSchemaVersionKey).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+
+		store, err := NewStore(api, latestVersion)
+		assert.NotNil(t, err)
+		assert.Nil(t, store)
+	})
+	t.Run("migrating polls fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte(latestVersion), nil)
+		api.On("KVGet", pollSchemaVersionKey).Return(nil, nil)
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+
+		store, err := NewStore(api, latestVersion)
+		assert.NotNil(t, err)
+		assert.Nil(t, store)
+	})
 	t.Run("UpdateDatabase() fails", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("KVGet", versionKey).Return([]byte{}, &model.AppError{})
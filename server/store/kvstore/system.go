@@ -1,7 +1,10 @@
 package kvstore
 
 import (
+	"strconv"
+
 	"github.com/mattermost/mattermost-server/v5/plugin"
+	"github.com/pkg/errors"
 )
 
 // SystemStore allows to access system informations in the KV Store.
@@ -9,7 +12,12 @@ type SystemStore struct {
 	api plugin.API
 }
 
-const versionKey = "version"
+const (
+	versionKey = "version"
+	// pollSchemaVersionKey is the key of the schema version all stored polls have been migrated to, see poll.CurrentSchemaVersion.
+	// It must not start with pollPrefix, see ListIDs.
+	pollSchemaVersionKey = "migrated_schema_version"
+)
 
 // GetVersion returns the db schema version.
 func (s *SystemStore) GetVersion() (string, error) {
@@ -28,3 +36,29 @@ func (s *SystemStore) SaveVersion(version string) error {
 	}
 	return nil
 }
+
+// getPollSchemaVersion returns the schema version all stored polls have been migrated to.
+// Zero means they haven't been migrated yet.
+func (s *SystemStore) getPollSchemaVersion() (int, error) {
+	b, err := s.api.KVGet(pollSchemaVersionKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	version, convErr := strconv.Atoi(string(b))
+	if convErr != nil {
+		return 0, errors.Wrap(convErr, "invalid poll schema version")
+	}
+	return version, nil
+}
+
+// savePollSchemaVersion sets the schema version all stored polls have been migrated to.
+func (s *SystemStore) savePollSchemaVersion(version int) error {
+	err := s.api.KVSet(pollSchemaVersionKey, []byte(strconv.Itoa(version)))
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -52,3 +52,67 @@ func TestSystemStoreSetVersion(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestSystemStoreGetPollSchemaVersion(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollSchemaVersionKey).Return([]byte("2"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		version, err := store.systemStore.getPollSchemaVersion()
+		require.Nil(t, err)
+		assert.Equal(t, 2, version)
+	})
+	t.Run("no version recorded", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollSchemaVersionKey).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		version, err := store.systemStore.getPollSchemaVersion()
+		require.Nil(t, err)
+		assert.Equal(t, 0, version)
+	})
+	t.Run("invalid version", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollSchemaVersionKey).Return([]byte("invalid"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		version, err := store.systemStore.getPollSchemaVersion()
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, version)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollSchemaVersionKey).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		version, err := store.systemStore.getPollSchemaVersion()
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, version)
+	})
+}
+
+func TestSystemStoreSavePollSchemaVersion(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", pollSchemaVersionKey, []byte("2")).Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.systemStore.savePollSchemaVersion(2)
+		assert.Nil(t, err)
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", pollSchemaVersionKey, []byte("2")).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.systemStore.savePollSchemaVersion(2)
+		assert.NotNil(t, err)
+	})
+}
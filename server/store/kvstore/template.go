@@ -0,0 +1,80 @@
+package kvstore
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+// TemplateStore allows to access poll templates in the KV Store.
+// All templates of a user or channel are stored together under a single key.
+type TemplateStore struct {
+	api plugin.API
+}
+
+const templatePrefix = "template_"
+
+// List returns the templates of a user or channel, ordered by name.
+func (s *TemplateStore) List(ownerID string) ([]*poll.Template, error) {
+	templates, _, err := s.get(ownerID)
+	return templates, err
+}
+
+// Save adds a template to a user or channel, replacing an existing template with the same name,
+// using an atomic compare-and-set. store.ErrConflict is returned if the templates were modified concurrently.
+func (s *TemplateStore) Save(ownerID string, template *poll.Template) error {
+	templates, b, err := s.get(ownerID)
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, t := range templates {
+		if t.Name == template.Name {
+			templates[i] = template
+			replaced = true
+		}
+	}
+	if !replaced {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+
+	opt := model.PluginKVSetOptions{
+		Atomic:   true,
+		OldValue: b,
+	}
+	ok, appErr := s.api.KVSetWithOptions(templatePrefix+ownerID, poll.EncodeTemplatesToByte(templates), opt)
+	if appErr != nil {
+		return appErr
+	}
+
+	if !ok {
+		return store.ErrConflict
+	}
+
+	return nil
+}
+
+// get returns the templates of an owner together with their raw value in the KV Store.
+func (s *TemplateStore) get(ownerID string) ([]*poll.Template, []byte, error) {
+	b, appErr := s.api.KVGet(templatePrefix + ownerID)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	if b == nil {
+		return []*poll.Template{}, nil, nil
+	}
+
+	templates := poll.DecodeTemplatesFromByte(b)
+	if templates == nil {
+		return nil, nil, errors.New("failed to decode templates")
+	}
+
+	return templates, b, nil
+}
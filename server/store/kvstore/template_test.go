@@ -0,0 +1,128 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+func TestTemplateStoreList(t *testing.T) {
+	templates := []*poll.Template{{Name: "a", Question: "Question", AnswerOptions: []string{"Answer 1", "Answer 2"}}}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return(poll.EncodeTemplatesToByte(templates), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rTemplates, err := store.Template().List("userID1")
+		require.NoError(t, err)
+		assert.Equal(t, templates, rTemplates)
+	})
+	t.Run("no templates", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rTemplates, err := store.Template().List("userID1")
+		require.NoError(t, err)
+		assert.Empty(t, rTemplates)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rTemplates, err := store.Template().List("userID1")
+		assert.Error(t, err)
+		assert.Nil(t, rTemplates)
+	})
+	t.Run("invalid data", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return([]byte("invalid"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rTemplates, err := store.Template().List("userID1")
+		assert.Error(t, err)
+		assert.Nil(t, rTemplates)
+	})
+}
+
+func TestTemplateStoreSave(t *testing.T) {
+	templateA := &poll.Template{Name: "a", Question: "Question A", AnswerOptions: []string{"Answer 1", "Answer 2"}}
+	templateB := &poll.Template{Name: "b", Question: "Question B", AnswerOptions: []string{"Answer 1", "Answer 2"}}
+	newTemplateA := &poll.Template{Name: "a", Question: "New Question", AnswerOptions: []string{"Answer 1", "Answer 2"}}
+
+	t.Run("first template", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return(nil, nil)
+		api.On("KVSetWithOptions", templatePrefix+"userID1", poll.EncodeTemplatesToByte([]*poll.Template{templateA}), model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: nil,
+		}).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.Template().Save("userID1", templateA)
+		require.NoError(t, err)
+	})
+	t.Run("templates get sorted by name", func(t *testing.T) {
+		old := poll.EncodeTemplatesToByte([]*poll.Template{templateB})
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return(old, nil)
+		api.On("KVSetWithOptions", templatePrefix+"userID1", poll.EncodeTemplatesToByte([]*poll.Template{templateA, templateB}), model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: old,
+		}).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.Template().Save("userID1", templateA)
+		require.NoError(t, err)
+	})
+	t.Run("replace template", func(t *testing.T) {
+		old := poll.EncodeTemplatesToByte([]*poll.Template{templateA, templateB})
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return(old, nil)
+		api.On("KVSetWithOptions", templatePrefix+"userID1", poll.EncodeTemplatesToByte([]*poll.Template{newTemplateA, templateB}), model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: old,
+		}).Return(true, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.Template().Save("userID1", newTemplateA)
+		require.NoError(t, err)
+	})
+	t.Run("modified concurrently", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return(nil, nil)
+		api.On("KVSetWithOptions", templatePrefix+"userID1", poll.EncodeTemplatesToByte([]*poll.Template{templateA}), model.PluginKVSetOptions{
+			Atomic:   true,
+			OldValue: nil,
+		}).Return(false, nil)
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.Template().Save("userID1", templateA)
+		assert.Equal(t, store.ErrConflict, err)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", templatePrefix+"userID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		kvStore := setupTestStore(api)
+
+		err := kvStore.Template().Save("userID1", templateA)
+		assert.Error(t, err)
+	})
+}
@@ -2,14 +2,8 @@ package kvstore
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/blang/semver/v4"
-	"github.com/pkg/errors"
-)
-
-const (
-	perPage = 50
 )
 
 type upgrade struct {
@@ -22,7 +16,8 @@ func getUpgrades() []*upgrade {
 		{toVersion: "1.1.0", upgradeFunc: nil},
 		{toVersion: "1.2.0", upgradeFunc: nil},
 		{toVersion: "1.3.0", upgradeFunc: nil},
-		{toVersion: "1.4.0", upgradeFunc: upgradeTo14},
+		// Polls stored before 1.4.0 are migrated by their schema version, see poll.CurrentSchemaVersion
+		{toVersion: "1.4.0", upgradeFunc: nil},
 	}
 }
 
@@ -71,49 +66,3 @@ func (s *Store) shouldPerformUpgrade(currentSchemaVersion, expectedSchemaVersion
 	}
 	return false
 }
-
-func upgradeTo14(s *Store) error {
-	var allKeys []string
-	i := 0
-	for {
-		keys, appErr := s.api.KVList(i, perPage)
-		if appErr != nil {
-			return errors.Wrap(appErr, "failed to list poll keys")
-		}
-
-		allKeys = append(allKeys, keys...)
-
-		if len(keys) < perPage {
-			break
-		}
-
-		i++
-	}
-
-	for _, k := range allKeys {
-		// Only migrate plugin keys
-		if strings.HasPrefix(k, pollPrefix) {
-			k = strings.TrimPrefix(k, pollPrefix)
-
-			poll, err := s.Poll().Get(k)
-			if err != nil {
-				s.api.LogError("Failed to get poll for migration", "error", err.Error(), "pollID", k)
-				continue
-			}
-
-			if poll.Settings.MaxVotes > 0 {
-				// Already migrated
-				continue
-			}
-
-			poll.Settings.MaxVotes = 1
-			err = s.Poll().Save(poll)
-			if err != nil {
-				s.api.LogError("Failed to save poll after migration", "error", err.Error(), "pollID", k)
-				continue
-			}
-		}
-	}
-
-	return nil
-}
@@ -9,10 +9,6 @@ import (
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"github.com/stretchr/testify/require"
-
-	"github.com/matterpoll/matterpoll/server/poll"
-	"github.com/matterpoll/matterpoll/server/utils/testutils"
 )
 
 func TestStoreShouldPerformUpgrade(t *testing.T) {
@@ -144,73 +140,3 @@ func TestStoreUpdateDatabase(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
-
-func TestUpgradeTo14(t *testing.T) {
-	t.Run("KVList succeeds", func(t *testing.T) {
-		oldPoll := poll.Poll{
-			ID:       model.NewId(),
-			Settings: poll.Settings{MaxVotes: 0},
-		}
-
-		migratedPoll := oldPoll
-		migratedPoll.Settings.MaxVotes = 1
-
-		newPoll := poll.Poll{
-			ID:       model.NewId(),
-			Settings: poll.Settings{MaxVotes: 2},
-		}
-
-		failGetPoll := poll.Poll{
-			ID:       model.NewId(),
-			Settings: poll.Settings{MaxVotes: 0},
-		}
-
-		failSavePoll := poll.Poll{
-			ID:       model.NewId(),
-			Settings: poll.Settings{MaxVotes: 0},
-		}
-
-		migratedFailSavePoll := failSavePoll
-		migratedFailSavePoll.Settings.MaxVotes = 1
-
-		keys := []string{
-			"foo",
-			pollPrefix + oldPoll.ID,
-			"bar",
-			pollPrefix + newPoll.ID,
-			pollPrefix + failGetPoll.ID,
-			pollPrefix + failSavePoll.ID,
-		}
-
-		api := &plugintest.API{}
-		api.On("KVList", 0, perPage).Return(keys, nil)
-
-		api.On("KVGet", pollPrefix+oldPoll.ID).Return(oldPoll.EncodeToByte(), nil)
-		api.On("KVGet", pollPrefix+newPoll.ID).Return(newPoll.EncodeToByte(), nil)
-		api.On("KVGet", pollPrefix+failGetPoll.ID).Return(nil, &model.AppError{})
-		api.On("KVGet", pollPrefix+failSavePoll.ID).Return(failSavePoll.EncodeToByte(), nil)
-
-		api.On("KVSet", pollPrefix+migratedPoll.ID, migratedPoll.EncodeToByte()).Return(nil)
-		api.On("KVSet", pollPrefix+failSavePoll.ID, migratedFailSavePoll.EncodeToByte()).Return(&model.AppError{})
-
-		api.On("LogError", testutils.GetMockArgumentsWithType("string", 5)...).Return(nil)
-
-		defer api.AssertExpectations(t)
-		store := setupTestStore(api)
-
-		err := upgradeTo14(store)
-
-		require.NoError(t, err)
-	})
-
-	t.Run("KVList fails", func(t *testing.T) {
-		api := &plugintest.API{}
-		api.On("KVList", 0, perPage).Return(nil, &model.AppError{})
-		defer api.AssertExpectations(t)
-		store := setupTestStore(api)
-
-		err := upgradeTo14(store)
-
-		require.Error(t, err)
-	})
-}
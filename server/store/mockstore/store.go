@@ -9,18 +9,43 @@ import (
 
 // Store is a mock store
 type Store struct {
-	PollStore   mocks.PollStore
-	SystemStore mocks.SystemStore
+	PollStore          mocks.PollStore
+	ScheduledPollStore mocks.ScheduledPollStore
+	TemplateStore      mocks.TemplateStore
+	AuditStore         mocks.AuditStore
+	ArchiveStore       mocks.ArchiveStore
+	RateLimitStore     mocks.RateLimitStore
+	SystemStore        mocks.SystemStore
 }
 
 // Poll returns the Poll Store
 func (s *Store) Poll() store.PollStore { return &s.PollStore }
 
+// ScheduledPoll returns the Scheduled Poll Store
+func (s *Store) ScheduledPoll() store.ScheduledPollStore { return &s.ScheduledPollStore }
+
+// Template returns the Template Store
+func (s *Store) Template() store.TemplateStore { return &s.TemplateStore }
+
+// Audit returns the Audit Store
+func (s *Store) Audit() store.AuditStore { return &s.AuditStore }
+
+// Archive returns the Archive Store
+func (s *Store) Archive() store.ArchiveStore { return &s.ArchiveStore }
+
+// RateLimit returns the Rate Limit Store
+func (s *Store) RateLimit() store.RateLimitStore { return &s.RateLimitStore }
+
 // System returns the System Store
 func (s *Store) System() store.SystemStore { return &s.SystemStore }
 
 // AssertExpectations makes sure the expectations of all stores are meet
 func (s *Store) AssertExpectations(t mock.TestingT) {
 	s.PollStore.AssertExpectations(t)
+	s.ScheduledPollStore.AssertExpectations(t)
+	s.TemplateStore.AssertExpectations(t)
+	s.AuditStore.AssertExpectations(t)
+	s.ArchiveStore.AssertExpectations(t)
+	s.RateLimitStore.AssertExpectations(t)
 	s.SystemStore.AssertExpectations(t)
 }
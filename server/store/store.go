@@ -1,22 +1,92 @@
 package store
 
 import (
+	"errors"
+	"time"
+
 	"github.com/matterpoll/matterpoll/server/poll"
 )
 
+var (
+	// ErrConflict is returned by PollStore.Update if the poll was modified since it has been read.
+	ErrConflict = errors.New("poll was modified concurrently")
+	// ErrNotFound is returned by PollStore.Get if the poll doesn't exist.
+	ErrNotFound = errors.New("poll not found")
+)
+
 // Store allows the interaction with some kind of store.
 type Store interface {
 	Poll() PollStore
+	ScheduledPoll() ScheduledPollStore
+	Template() TemplateStore
+	Audit() AuditStore
+	Archive() ArchiveStore
+	RateLimit() RateLimitStore
 	System() SystemStore
 }
 
 // PollStore allows the access polls in the store.
 type PollStore interface {
+	// Get returns ErrNotFound if the poll doesn't exist. Data that isn't a valid poll is reported
+	// with an error wrapping poll.ErrCorruptPoll, see poll.DecodePollFromByte.
 	Get(id string) (*poll.Poll, error)
 	Insert(*poll.Poll) error
 	Save(*poll.Poll) error
+	// Update replaces prev with new, if the stored poll still equals prev. Otherwise ErrConflict is returned.
 	Update(prev *poll.Poll, new *poll.Poll) error
 	Delete(*poll.Poll) error
+	ListIDs() ([]string, error)
+	// ListIDsByUser returns the IDs of all polls that contain the ID of a user, see poll.Poll.ContainsUser.
+	// voterKey is needed to find the hashed votes of anonymous polls, see poll.Poll.SetVoterKey.
+	ListIDsByUser(userID, voterKey string) ([]string, error)
+	// ListIDsByChannel returns the IDs of the polls posted in a channel, including polls broadcast to it.
+	// It may contain IDs of polls that have been deleted, which Get reports as ErrNotFound.
+	ListIDsByChannel(channelID string) ([]string, error)
+	// ListIDsByKeywords returns the IDs of the polls whose question or answer options contain all keywords,
+	// see poll.Keywords. Like ListIDsByChannel, it may contain IDs of deleted polls and of polls edited since,
+	// so callers check the polls with poll.Poll.MatchesKeywords.
+	ListIDsByKeywords(keywords []string) ([]string, error)
+}
+
+// ScheduledPollStore allows the access to polls that get posted later.
+type ScheduledPollStore interface {
+	Get(id string) (*poll.ScheduledPoll, error)
+	Insert(*poll.ScheduledPoll) error
+	Delete(id string) error
+	ListIDs() ([]string, error)
+}
+
+// TemplateStore allows the access to the poll templates of users and channels.
+type TemplateStore interface {
+	// List returns the templates of a user or channel, ordered by name.
+	List(ownerID string) ([]*poll.Template, error)
+	// Save adds a template to a user or channel. An existing template with the same name is replaced.
+	// ErrConflict is returned if the templates of the owner were modified concurrently.
+	Save(ownerID string, template *poll.Template) error
+}
+
+// AuditStore allows the access to the audit log of the lifecycle events of polls.
+type AuditStore interface {
+	// Append adds a record to the audit log. ErrConflict is returned if the audit log was modified concurrently.
+	Append(record *poll.AuditRecord) error
+	// List returns the records of a UTC day, see poll.AuditDayLayout, in the order they were added.
+	List(day string) ([]*poll.AuditRecord, error)
+}
+
+// ArchiveStore allows the access to the outcomes of ended polls.
+type ArchiveStore interface {
+	// Save archives the outcome of a poll. An existing outcome of the same poll is replaced.
+	Save(*poll.ArchivedPoll) error
+	Delete(channelID, pollID string) error
+	// ListByChannel returns the archived polls of a channel, most recently closed first.
+	ListByChannel(channelID string) ([]*poll.ArchivedPoll, error)
+}
+
+// RateLimitStore allows to count the actions of users within a time window.
+type RateLimitStore interface {
+	// Take counts an action under key, if fewer than limit actions were counted within window.
+	// It returns false if the limit has been reached. ErrConflict is returned if actions were counted concurrently.
+	Take(key string, limit int, window time.Duration) (bool, error)
 }
 
 // SystemStore allows to access system information in the store.
@@ -45,15 +45,13 @@ func GetPoll() *poll.Poll {
 		Question:  "Question",
 		AnswerOptions: []*poll.AnswerOption{{
 			Answer: "Answer 1",
-			Voter:  []string{},
 		}, {
 			Answer: "Answer 2",
-			Voter:  []string{},
 		}, {
 			Answer: "Answer 3",
-			Voter:  []string{},
 		}},
-		Settings: poll.Settings{MaxVotes: 1},
+		Settings:      poll.Settings{MaxVotes: 1},
+		SchemaVersion: poll.CurrentSchemaVersion,
 	}
 }
 
@@ -73,7 +71,7 @@ func GetPollWithSettings(settings poll.Settings) *poll.Poll {
 
 // GetPollWithVotes returns a Poll with three Options, some votes and no Poll Settings.
 func GetPollWithVotes() *poll.Poll {
-	return &poll.Poll{
+	return WithVoters(&poll.Poll{
 		ID:        GetPollID(),
 		PostID:    "postID1",
 		CreatedAt: 1234567890,
@@ -81,16 +79,14 @@ func GetPollWithVotes() *poll.Poll {
 		Question:  "Question",
 		AnswerOptions: []*poll.AnswerOption{{
 			Answer: "Answer 1",
-			Voter:  []string{"userID1", "userID2", "userID3"},
 		}, {
 			Answer: "Answer 2",
-			Voter:  []string{"userID4"},
 		}, {
 			Answer: "Answer 3",
-			Voter:  []string{},
 		}},
-		Settings: poll.Settings{MaxVotes: 1},
-	}
+		Settings:      poll.Settings{MaxVotes: 1},
+		SchemaVersion: poll.CurrentSchemaVersion,
+	}, []string{"userID1", "userID2", "userID3"}, []string{"userID4"})
 }
 
 // GetPollWithVotesAndSettings returns a Poll with three Options, some votes and given Poll Settings.
@@ -110,11 +106,18 @@ func GetPollTwoOptions() *poll.Poll {
 		Question:  "Question",
 		AnswerOptions: []*poll.AnswerOption{{
 			Answer: "Yes",
-			Voter:  []string{},
 		}, {
 			Answer: "No",
-			Voter:  []string{},
 		}},
-		Settings: poll.Settings{MaxVotes: 1},
+		Settings:      poll.Settings{MaxVotes: 1},
+		SchemaVersion: poll.CurrentSchemaVersion,
+	}
+}
+
+// WithVoters sets the voters of the answer options of a poll, in the order of the answer options, and returns the poll.
+func WithVoters(p *poll.Poll, voters ...[]string) *poll.Poll {
+	for i, v := range voters {
+		p.SetVoters(i, v...)
 	}
+	return p
 }
@@ -10,6 +10,7 @@ export const websocketHasVoted = (data) => async (dispatch) => {
             poll_id: data.poll_id,
             can_manage_poll: data.can_manage_poll,
             setting_public_add_option: data.setting_public_add_option,
+            option_order: data.option_order,
         },
     });
 };
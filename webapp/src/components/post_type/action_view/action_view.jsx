@@ -57,6 +57,30 @@ export default class ActionView extends React.PureComponent {
         return action && (action.id === 'addOption');
     }
 
+    /**
+     * return the actions with the vote buttons in the order the user sees the answer options.
+     * The vote button of the answer option with index `i` has the id `vote${i}`.
+     * Actions are returned unchanged if the poll doesn't shuffle its answer options.
+     * @param {array} actions actions of the poll
+     * @param {object} metadata metadata for poll
+     * @return {array} actions in display order
+     */
+    orderActions(actions, metadata) {
+        if (!metadata.option_order) {
+            return actions;
+        }
+
+        const position = {};
+        metadata.option_order.forEach((index, i) => {
+            position[`vote${index}`] = i;
+        });
+        const votes = actions.
+            filter((action) => action.id in position).
+            sort((a, b) => position[a.id] - position[b.id]);
+
+        return actions.map((action) => ((action.id in position) ? votes.shift() : action));
+    }
+
     render() {
         const actions = this.props.attachment.actions;
         if (!actions || !actions.length) {
@@ -68,7 +92,7 @@ export default class ActionView extends React.PureComponent {
         const metadataMap = this.props.pollMetadata || {};
         const metadata = metadataMap[this.props.post.props.poll_id] || {};
 
-        actions.
+        this.orderActions(actions, metadata).
             filter((action) => action.id && action.name).
             forEach((action) => {
                 switch (action.type) {
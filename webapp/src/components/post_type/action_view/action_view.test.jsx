@@ -126,4 +126,29 @@ describe('components/post_type/action_view/ActionView', () => {
         const wrapper = shallow(<ActionView {...newProps}/>);
         expect(wrapper).toMatchSnapshot();
     });
+    test('should show the vote buttons in the order of the metadata', () => {
+        const newProps = {
+            ...baseProps,
+            attachment: {
+                actions: [
+                    {id: 'vote0', name: 'answer1', type: ActionButtonType.BUTTON},
+                    {id: 'vote1', name: 'answer2', type: ActionButtonType.BUTTON},
+                    {id: 'vote2', name: 'answer3', type: ActionButtonType.BUTTON},
+                    {id: 'resetVote', name: 'Reset My Votes', type: ActionButtonType.BUTTON},
+                ],
+            },
+            pollMetadata: {
+                samplepollid1: {
+                    voted_answers: [],
+                    poll_id: samplePollId,
+                    user_id: 'user_id1',
+                    can_manage_poll: false,
+                    option_order: [2, 0, 1],
+                },
+            },
+        };
+        const wrapper = shallow(<ActionView {...newProps}/>);
+        const keys = wrapper.find('.attachment-actions').first().children().map((button) => button.key());
+        expect(keys).toEqual(['vote2', 'vote0', 'vote1', 'resetVote']);
+    });
 });